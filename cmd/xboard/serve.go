@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net"
 	"net/http"
 	"os/signal"
@@ -11,6 +12,8 @@ import (
 	"time"
 
 	"github.com/creamcroissant/xboard/internal/api"
+	"github.com/creamcroissant/xboard/internal/api/clientip"
+	"github.com/creamcroissant/xboard/internal/api/middleware"
 	"github.com/creamcroissant/xboard/internal/async"
 	"github.com/creamcroissant/xboard/internal/bootstrap"
 	"github.com/creamcroissant/xboard/internal/config"
@@ -19,12 +22,16 @@ import (
 	"github.com/creamcroissant/xboard/internal/grpc/interceptor"
 	"github.com/creamcroissant/xboard/internal/job"
 	"github.com/creamcroissant/xboard/internal/migrations"
+	"github.com/creamcroissant/xboard/internal/notifier"
 	"github.com/creamcroissant/xboard/internal/protocol"
 	"github.com/creamcroissant/xboard/internal/repository/sqlite"
 	"github.com/creamcroissant/xboard/internal/service"
+	"github.com/creamcroissant/xboard/internal/support/geoip"
 	"github.com/creamcroissant/xboard/internal/support/i18n"
 	"github.com/creamcroissant/xboard/internal/support/logging"
 	"github.com/creamcroissant/xboard/internal/template"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
 	"github.com/spf13/cobra"
 	"golang.org/x/net/http2"
 	"golang.org/x/net/http2/h2c"
@@ -72,6 +79,8 @@ func runServe(cmd *cobra.Command, args []string) error {
 		LogDir:    cfg.Log.LogDir,
 		MaxDays:   cfg.Log.MaxDays,
 	})
+	// handler 等处直接调用包级 slog，设为默认后同样按配置输出并带上 request_id
+	slog.SetDefault(logger)
 	logger.Info("database path resolved", "path", cfg.DB.Path)
 
 	db, err := bootstrap.OpenSQLite(cfg.DB.Path)
@@ -124,25 +133,38 @@ func runServe(cmd *cobra.Command, args []string) error {
 	planService := service.NewPlanService(store.Plans(), store.Users(), store.Settings(), store.ServerGroups())
 	i18nManager, err := i18n.NewManager(
 		i18n.WithLogger(logger),
-		i18n.WithDefaultLang("en-US"),
+		i18n.WithDefaultLang(cfg.I18n.DefaultLang),
+		i18n.WithFallbacks(cfg.I18n.Fallbacks),
+		i18n.WithMissingKeyReport(cfg.I18n.ReportMissing),
 	)
 	if err != nil {
 		return err
 	}
 
-	adminPlanService := service.NewAdminPlanService(store.Plans(), i18nManager)
+	auditLogService := service.NewAuditLogService(store.AuditLogs(), logger)
+	authService := service.NewAuthService(store.Users(), store.Settings(), store.LoginLogs(), store.LoginLockouts(), store.Tokens(), infra.Hasher, infra.Token, infra.RateLimiter, infra.Audit, infra.Cache)
+	authService.SetAuditLog(auditLogService)
+	adminPlanService := service.NewAdminPlanService(store.Plans(), store.ServerGroups(), i18nManager)
+	adminPlanService.SetAuditLog(auditLogService)
 	serverTelemetryService := service.NewServerTelemetryServiceWithLogger(infra.Cache, store.Settings(), store.Servers(), store.StatServers(), logger)
+	onlineDeviceService := service.NewOnlineDeviceService(store.UserOnlineDevices(), store.Settings())
 	adminUserService := service.NewAdminUserService(
 		store.Users(),
 		store.Plans(),
 		store.ServerGroups(),
 		store.Settings(),
 		serverTelemetryService,
+		onlineDeviceService,
 		infra.Hasher,
 		i18nManager,
 	)
-	adminServerService := service.NewAdminServerService(store.ServerGroups(), store.ServerRoutes(), store.Servers(), i18nManager)
-	adminStatService := service.NewAdminStatService(store.StatUsers(), store.Users())
+	adminUserService.SetAuditLog(auditLogService)
+	adminServerService := service.NewAdminServerService(store.ServerGroups(), store.ServerRoutes(), store.Servers(), store.Users(), store.Plans(), i18nManager)
+	adminServerService.SetAuditLog(auditLogService)
+	adminServerService.SetTelemetry(serverTelemetryService)
+	serverService := service.NewServerService(store.Users(), store.Servers(), store.Plans())
+	serverService.SetTelemetry(serverTelemetryService)
+	adminStatService := service.NewAdminStatService(store.StatUsers(), store.Users(), store.UserTraffic())
 	adminNodeStatService := service.NewAdminNodeStatService(store.StatServers())
 	adminNoticeService := service.NewAdminNoticeService(store.Notices(), i18nManager)
 	adminKnowledgeService := service.NewAdminKnowledgeService(store.Knowledge(), i18nManager)
@@ -152,6 +174,7 @@ func runServe(cmd *cobra.Command, args []string) error {
 	protocolManager := protocol.NewManager(
 		protocol.NewGeneralBuilder(),
 		protocol.NewClashBuilder(),
+		protocol.NewClashMetaBuilder(),
 		protocol.NewSurgeBuilder(),
 		protocol.NewSingboxBuilder(),
 	)
@@ -161,10 +184,21 @@ func runServe(cmd *cobra.Command, args []string) error {
 	// Multi-accumulator for multi-granularity statistics (hourly, daily, monthly)
 	multiAccumulator := job.NewMultiAccumulator(3) // 0=hourly, 1=daily, 2=monthly
 	serverTrafficService := service.NewServerTrafficService(store.Users(), multiAccumulator)
-	userTrafficService := service.NewUserTrafficServiceWithCollector(store.UserTraffic(), store.Users(), multiAccumulator, notificationQueue, store.Settings())
+	// User traffic metrics are only collected when Prometheus is enabled
+	var trafficMetrics service.TrafficMetricsRecorder
+	if cfg.Metrics.Enabled {
+		promTrafficMetrics := service.NewPrometheusTrafficMetrics(cfg.Metrics.Namespace, cfg.Metrics.TrafficPlanAllowlist)
+		if err := promTrafficMetrics.Register(prometheus.DefaultRegisterer); err != nil {
+			logger.Warn("register user traffic metrics failed", "error", err)
+		} else {
+			trafficMetrics = promTrafficMetrics
+		}
+	}
+	userTrafficService := service.NewUserTrafficServiceWithCollector(store.UserTraffic(), store.Users(), store.Plans(), store.Servers(), multiAccumulator, notificationQueue, store.Settings(), trafficMetrics)
 	userServerSelectionService := service.NewUserServerSelectionService(store.UserTraffic())
-	trafficQueue := async.NewTrafficQueue()
+	trafficQueue := async.NewTrafficQueueWithCapacity(cfg.Queue.TrafficCapacity)
 	subLogQueue := async.NewSubscriptionLogQueue(store.SubscriptionLogs(), logger)
+	shortLinkHitQueue := async.NewShortLinkHitQueue(store.ShortLinks(), logger)
 	installService := service.NewInstallService(store.Users(), infra.Hasher, i18nManager)
 
 	adminSystemService := service.NewAdminSystemService(service.AdminSystemOptions{
@@ -184,7 +218,8 @@ func runServe(cmd *cobra.Command, args []string) error {
 		Audit:             infra.Audit,
 	})
 
-	agentHostService := service.NewAgentHostServiceWithOptions(store.AgentHosts(), store.Servers(), store.ServerClientConfigs(), store.ConfigTemplates(), store.Users(), store.Settings(), service.AgentHostServiceOptions{Cache: infra.Cache, Logger: logger})
+	dashboardHub := service.NewDashboardHub(service.DefaultDashboardMaxClients)
+	agentHostService := service.NewAgentHostServiceWithOptions(store.AgentHosts(), store.Servers(), store.ServerClientConfigs(), store.ConfigTemplates(), store.Users(), store.Settings(), service.AgentHostServiceOptions{Cache: infra.Cache, Logger: logger, TemplateVersions: store.ConfigTemplateVersions(), Diagnostics: store.AgentHostDiagnostics(), PathUsage: store.AgentHostPathUsage(), Routes: store.ServerRoutes(), Notifications: notificationQueue, AuditLog: auditLogService, Dashboard: dashboardHub})
 	agentService := service.NewAgentService(store.Servers(), store.Users())
 	forwardingService := service.NewForwardingServiceWithLogger(store.ForwardingRules(), store.ForwardingRuleLogs(), store.AgentHosts(), logger)
 	converterRegistry := template.NewConverterRegistry(&template.SingBoxConverter{}, &template.XrayConverter{})
@@ -196,9 +231,17 @@ func runServe(cmd *cobra.Command, args []string) error {
 		store.ConfigTemplates(),
 		converterRegistry,
 		logger,
-		service.AgentCoreServiceOptions{Operations: store.CoreOperations(), OperationGuard: agentOperationGuard},
+		service.AgentCoreServiceOptions{Operations: store.CoreOperations(), OperationGuard: agentOperationGuard, TemplateVersions: store.ConfigTemplateVersions()},
 	)
-	accessLogService := service.NewAccessLogService(store)
+	configTemplateService := service.NewConfigTemplateService(store.ConfigTemplates(), store.ConfigTemplateVersions())
+	var geoResolver service.GeoResolver
+	if resolver, err := geoip.NewResolver(cfg.GeoIP.CountryDB, cfg.GeoIP.ASNDB); err != nil {
+		logger.Warn("geoip databases unavailable, access logs will not be enriched", "error", err)
+	} else if resolver != nil {
+		defer resolver.Close()
+		geoResolver = resolver
+	}
+	accessLogService := service.NewAccessLogServiceWithGeoResolver(store, geoResolver)
 	artifactCompilerService := service.NewArtifactCompilerService(store.InboundSpecs(), store.DesiredArtifacts())
 	inboundSpecService := service.NewInboundSpecService(store.InboundSpecs(), store.InboundSpecRevisions(), store.InboundIndexes(), artifactCompilerService)
 	driftAndDiffService := service.NewDriftAndDiffService(store.DesiredArtifacts(), store.AgentConfigInventories(), store.InboundIndexes(), store.DriftStates())
@@ -216,6 +259,9 @@ func runServe(cmd *cobra.Command, args []string) error {
 	)
 
 	inboundSpecService.SetCDNService(cdnService)
+	// 模板或用户变更后立即通知受影响的 Agent 重新同步
+	configTemplateService.SetResyncNotifier(agentHostService)
+	adminUserService.SetResyncNotifier(agentHostService)
 	agentTrafficLifecycleService := service.NewAgentTrafficLifecycleService(store.AgentTrafficStates(), operationLogService, service.AgentTrafficLifecycleOptions{
 		Policies:            store.AgentTrafficPolicies(),
 		AgentHosts:          store.AgentHosts(),
@@ -225,11 +271,13 @@ func runServe(cmd *cobra.Command, args []string) error {
 		Logger:              logger,
 	})
 	binaryVersionService := service.NewBinaryVersionService(store.BinaryVersionStates(), store.AgentHosts(), nil)
-	shortLinkService := service.NewShortLinkService(store.ShortLinks(), store.Users(), store.Settings())
+	shortLinkService := service.NewShortLinkService(store.ShortLinks(), store.Users(), store.Settings(), shortLinkHitQueue, protocolManager)
 	subscriptionSourceService := service.NewSubscriptionSourceService(store.SubscriptionSources(), service.SubscriptionSourceServiceOptions{})
 	subscriptionFilterService := service.NewSubscriptionFilterService(store.Servers(), store.SubscriptionSources(), store.SubscriptionFilterReasons(), store.Plans(), userServerSelectionService, serverTelemetryService)
 	coreOperationService := service.NewCoreOperationService(store.CoreOperations(), agentOperationGuard)
+	coreOperationService.SetDashboardEvents(dashboardHub)
 	coreSnapshotService := service.NewCoreSnapshotService(store.AgentHosts(), store.AgentCoreInstances())
+	coreSnapshotService.SetRestartAlerts(store.Settings(), notificationQueue, logger)
 
 	scheduler := job.NewScheduler(logger)
 
@@ -258,7 +306,10 @@ func runServe(cmd *cobra.Command, args []string) error {
 	if err := registerSchedulerJob(scheduler, "scheduler.email_notify", cfg.Scheduler.EmailNotify, emailJob); err != nil {
 		return err
 	}
-	telegramJob := job.NewSendTelegramJob(notificationQueue, infra.Notifier, logger)
+	// Telegram 机器人未配置令牌时为空操作，通知仍走原通知服务
+	telegramService := service.NewTelegramService(store.Users(), store.Settings(), infra.Cache, logger)
+	go telegramService.Run(ctx)
+	telegramJob := job.NewSendTelegramJob(notificationQueue, service.NewTelegramNotifier(infra.Notifier, telegramService), logger)
 	if err := registerSchedulerJob(scheduler, "scheduler.telegram_notify", cfg.Scheduler.TelegramNotify, telegramJob); err != nil {
 		return err
 	}
@@ -270,25 +321,58 @@ func runServe(cmd *cobra.Command, args []string) error {
 	if _, err := scheduler.Register("0 0 0 * * *", trafficPeriodResetJob); err != nil {
 		return err
 	}
+	// 每小时检查一次，重置边界为当天 0 点，整点后尽快处理
+	planTrafficResetJob := job.NewPlanTrafficResetJob(userTrafficService, logger)
+	if _, err := scheduler.Register("0 1 * * * *", planTrafficResetJob); err != nil {
+		return err
+	}
 	agentTrafficResetJob := job.NewAgentTrafficResetJob(agentTrafficLifecycleService, logger)
 	if _, err := scheduler.Register("@every 5m", agentTrafficResetJob); err != nil {
 		return err
 	}
+	trafficReportDedupCleanupJob := job.NewTrafficReportDedupCleanupJob(store.TrafficReportDedups(), logger)
+	if _, err := scheduler.Register("0 15 * * * *", trafficReportDedupCleanupJob); err != nil {
+		return err
+	}
 	accessLogCleanupJob := job.NewAccessLogCleanupJob(accessLogService, logger)
-	if _, err := scheduler.Register("@every 1h", accessLogCleanupJob); err != nil {
+	accessLogCleanupEntry, err := scheduler.Register("0 30 3 * * *", accessLogCleanupJob)
+	if err != nil {
 		return err
 	}
+	accessLogService.SetCleanupSchedule(func() time.Time { return scheduler.Next(accessLogCleanupEntry) })
 	agentHostMetricsFlushJob := job.NewAgentHostMetricsFlushJob(agentHostService)
 	if _, err := scheduler.Register("@every 3s", agentHostMetricsFlushJob); err != nil {
 		return err
 	}
+	notificationService := service.NewNotificationService(store.AgentHosts(), store.Settings(), notifier.NewWebhookClient(nil), notificationQueue, logger)
+	notificationService.SetDashboardEvents(dashboardHub)
+	agentHostStatusNotifyJob := job.NewAgentHostStatusNotifyJob(notificationService)
+	if _, err := scheduler.Register("@every 30s", agentHostStatusNotifyJob); err != nil {
+		return err
+	}
+	userReminderService := service.NewUserReminderService(store.Users(), store.UserReminders(), store.Settings(), notificationQueue)
+	userReminderJob := job.NewUserReminderJob(userReminderService, logger)
+	userReminderEntry, err := scheduler.Register("0 0 10 * * *", userReminderJob)
+	if err != nil {
+		return err
+	}
+	userReminderService.SetSchedule(func() time.Time { return scheduler.Next(userReminderEntry) })
 	scheduler.Start()
 
+	subscriptionService := service.NewSubscriptionService(store.Users(), store.Servers(), store.Settings(), store.Plans(), store.SubscriptionTemplates(), subscriptionSourceService, protocolManager, serverTelemetryService, subLogQueue, cfg.Security.SubscribeObfuscation, userServerSelectionService, i18nManager, subscriptionFilterService)
+	subscriptionService.SetAuditLog(auditLogService)
+
+	readinessService := service.NewReadinessService(service.DefaultReadinessTimeout,
+		service.DatabaseReadinessCheck(db),
+		service.RepositoryReadinessCheck(store.Settings(), store.Users(), store.AgentHosts()),
+		service.TemplateReadinessCheck(converterRegistry, protocolManager, "sing-box", "xray"),
+	)
+
 	services := api.Services{
 		Config:                  service.NewConfigService(store.Settings(), i18nManager),
 		User:                    service.NewUserService(store.Users(), store.Settings(), infra.Hasher),
 		UserStat:                userStatService,
-		Auth:                    service.NewAuthService(store.Users(), store.Settings(), store.LoginLogs(), store.Tokens(), infra.Hasher, infra.Token, infra.RateLimiter, infra.Audit, infra.Cache),
+		Auth:                    authService,
 		AdminPath:               service.NewAdminPathService(store.Settings()),
 		Install:                 installService,
 		AdminPlan:               adminPlanService,
@@ -313,12 +397,13 @@ func runServe(cmd *cobra.Command, args []string) error {
 		MailLink:                mailLinkService,
 		Comm:                    commService,
 		Plan:                    planService,
-		Server:                  service.NewServerService(store.Users(), store.Servers(), store.Plans()),
-		Subscription:            service.NewSubscriptionService(store.Users(), store.Servers(), store.Settings(), store.Plans(), store.SubscriptionTemplates(), subscriptionSourceService, protocolManager, serverTelemetryService, subLogQueue, cfg.Security.SubscribeObfuscation, userServerSelectionService, i18nManager, subscriptionFilterService),
+		Server:                  serverService,
+		Subscription:            subscriptionService,
 		SubscriptionFilter:      subscriptionFilterService,
 		SubscriptionSource:      subscriptionSourceService,
 		AgentHost:               agentHostService,
 		AgentCore:               agentCoreService,
+		ConfigTemplate:          configTemplateService,
 		Forwarding:              forwardingService,
 		AccessLog:               accessLogService,
 		InboundSpec:             inboundSpecService,
@@ -330,12 +415,39 @@ func runServe(cmd *cobra.Command, args []string) error {
 		BinaryVersion:           binaryVersionService,
 		UserSelection:           userServerSelectionService,
 		ShortLink:               shortLinkService,
+		LoginLockout:            service.NewLoginLockoutService(store.LoginLockouts()),
+		AuditLog:                auditLogService,
+		UserReminder:            userReminderService,
+		SubscriptionLog:         service.NewSubscriptionLogService(store.SubscriptionLogs(), store.Users()),
+		Telegram:                telegramService,
 		CDN:                     cdnService,
+		Dashboard:               dashboardHub,
+		Readiness:               readinessService,
 		TrafficQueue:            trafficQueue,
 		SubLogQueue:             subLogQueue,
 		I18n:                    i18nManager,
 	}
 
+	var rateLimitStore middleware.RateLimitStore
+	if cfg.RateLimit.Backend == "redis" {
+		redisClient := redis.NewClient(&redis.Options{
+			Addr:         cfg.RateLimit.Redis.Addr,
+			Password:     cfg.RateLimit.Redis.Password,
+			DB:           cfg.RateLimit.Redis.DB,
+			DialTimeout:  cfg.RateLimit.Redis.Timeout,
+			ReadTimeout:  cfg.RateLimit.Redis.Timeout,
+			WriteTimeout: cfg.RateLimit.Redis.Timeout,
+		})
+		defer redisClient.Close()
+		rateLimitStore = middleware.NewRedisRateLimitStore(redisClient, cfg.RateLimit.Redis.KeyPrefix)
+		logger.Info("rate limiting uses redis backend", "addr", cfg.RateLimit.Redis.Addr)
+	}
+
+	clientIPResolver, err := clientip.NewResolver(cfg.Security.TrustedProxies, cfg.Security.TrustedHops)
+	if err != nil {
+		return fmt.Errorf("init client ip resolver: %w", err)
+	}
+
 	router := api.NewRouter(
 		logger,
 		services,
@@ -360,6 +472,8 @@ func runServe(cmd *cobra.Command, args []string) error {
 			Enabled: cfg.UI.Install.Enabled,
 			Dir:     cfg.UI.Install.Dir,
 		}),
+		api.WithRateLimitStore(rateLimitStore),
+		api.WithClientIPResolver(clientIPResolver),
 	)
 
 	server := bootstrap.NewHTTPServer(legacyCfg, router)
@@ -374,7 +488,6 @@ func runServe(cmd *cobra.Command, args []string) error {
 			serverTelemetryService,
 			serverNodeService,
 			userTrafficService,
-			store.TrafficReportDedups(),
 			forwardingService,
 			accessLogService,
 			adminSystemSettingsService,
@@ -386,8 +499,11 @@ func runServe(cmd *cobra.Command, args []string) error {
 			agentLifecycleOperationService,
 			agentTrafficLifecycleService,
 			binaryVersionService,
+			onlineDeviceService,
+			trafficQueue,
 			logger,
 		)
+		agentHandler.SetConfigSigning(cfg.GRPC.ConfigSigning.KeyID, cfg.GRPC.ConfigSigning.Secret)
 
 		grpcCfg := internalgrpc.Config{
 			Address: cfg.GRPC.Addr,
@@ -468,6 +584,8 @@ func runServe(cmd *cobra.Command, args []string) error {
 	if err := server.Shutdown(shutdownCtx); err != nil {
 		logger.Error("server shutdown error", "error", err)
 	}
+	// 在 HTTP 停止后刷新剩余的短链接命中计数
+	shortLinkHitQueue.Stop()
 	logger.Info("server exited cleanly")
 	return nil
 }
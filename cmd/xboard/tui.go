@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
@@ -20,7 +21,14 @@ var tuiCmd = &cobra.Command{
 	RunE:  runTUI,
 }
 
+var (
+	tuiRefreshInterval time.Duration
+	tuiNoAutoRefresh   bool
+)
+
 func init() {
+	tuiCmd.Flags().DurationVar(&tuiRefreshInterval, "refresh-interval", tui.DefaultRefreshInterval, "Auto-refresh interval (toggle with 'a')")
+	tuiCmd.Flags().BoolVar(&tuiNoAutoRefresh, "no-auto-refresh", false, "Start with auto-refresh disabled")
 	rootCmd.AddCommand(tuiCmd)
 }
 
@@ -42,7 +50,7 @@ func runTUI(cmd *cobra.Command, args []string) error {
 
 	store := sqlite.NewStore(db)
 
-	model := tui.NewModel(store)
+	model := tui.NewModelWithRefresh(store, tuiRefreshInterval, !tuiNoAutoRefresh)
 
 	p := tea.NewProgram(
 		model,
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/creamcroissant/xboard/internal/agent/core"
@@ -19,6 +21,9 @@ type Manager struct {
 
 	collectors []Collector
 	stopCh     chan struct{}
+
+	aliveMu sync.Mutex
+	alive   map[string]AccessLogEntry // 自上次 TakeAliveDevices 以来出现的 (email, ip)
 }
 
 func NewManager(client *transport.GRPCClient, coreManager *core.Manager, logger *slog.Logger) *Manager {
@@ -83,12 +88,49 @@ func (m *Manager) collectAndReport() {
 	}
 
 	m.logger.Debug("collected access logs", "count", len(allEntries))
+	m.rememberAlive(allEntries)
 
 	if err := m.report(ctx, allEntries); err != nil {
 		m.logger.Error("failed to report access logs", "error", err)
 	}
 }
 
+// rememberAlive 记录带用户标识的来源 IP，供在线设备上报使用。
+func (m *Manager) rememberAlive(entries []AccessLogEntry) {
+	m.aliveMu.Lock()
+	defer m.aliveMu.Unlock()
+	for _, entry := range entries {
+		email := strings.ToLower(strings.TrimSpace(entry.UserEmail))
+		ip := strings.TrimSpace(entry.SourceIP)
+		if email == "" || ip == "" {
+			continue
+		}
+		if m.alive == nil {
+			m.alive = make(map[string]AccessLogEntry)
+		}
+		m.alive[email+"|"+ip] = AccessLogEntry{UserEmail: email, SourceIP: ip}
+	}
+}
+
+// TakeAliveDevices 返回并清空自上次调用以来观察到的在线设备（邮箱 + 来源 IP）。
+// 仅 Xray 访问日志带有用户邮箱，sing-box 的 clash_api 连接不含用户信息。
+func (m *Manager) TakeAliveDevices() []AccessLogEntry {
+	if m == nil {
+		return nil
+	}
+	m.aliveMu.Lock()
+	defer m.aliveMu.Unlock()
+	if len(m.alive) == 0 {
+		return nil
+	}
+	devices := make([]AccessLogEntry, 0, len(m.alive))
+	for _, entry := range m.alive {
+		devices = append(devices, entry)
+	}
+	m.alive = nil
+	return devices
+}
+
 func (m *Manager) report(ctx context.Context, entries []AccessLogEntry) error {
 	if !m.client.IsHealthy() {
 		return nil // skip if not connected
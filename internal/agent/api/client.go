@@ -18,20 +18,38 @@ type TrafficPayload struct {
 
 // StatusPayload carries node-level metrics.
 type StatusPayload struct {
-	CPU             float64 `json:"cpu"`
-	Mem             Stats   `json:"mem"`
-	Swap            Stats   `json:"swap"`
-	Disk            Stats   `json:"disk"`
-	Uptime          uint64  `json:"uptime"`
-	Load1           float64 `json:"load1"`
-	Load5           float64 `json:"load5"`
-	Load15          float64 `json:"load15"`
-	TcpCount        int     `json:"tcp_count"`
-	UdpCount        int     `json:"udp_count"`
-	ProcessCount    int     `json:"process_count"`
-	NetIO           NetIO   `json:"net_io"`
-	TrafficUpload   uint64  `json:"traffic_upload"`
-	TrafficDownload uint64  `json:"traffic_download"`
+	CPU             float64     `json:"cpu"`
+	Mem             Stats       `json:"mem"`
+	Swap            Stats       `json:"swap"`
+	Disk            Stats       `json:"disk"`
+	Uptime          uint64      `json:"uptime"`
+	Load1           float64     `json:"load1"`
+	Load5           float64     `json:"load5"`
+	Load15          float64     `json:"load15"`
+	TcpCount        int         `json:"tcp_count"`
+	UdpCount        int         `json:"udp_count"`
+	ProcessCount    int         `json:"process_count"`
+	NetIO           NetIO       `json:"net_io"`
+	TrafficUpload   uint64      `json:"traffic_upload"`
+	TrafficDownload uint64      `json:"traffic_download"`
+	PathUsage       []PathUsage `json:"path_usage,omitempty"`
+	// ConnectionSource 为空表示连接数不可用，此时 ConnectionCount 为 0 且不代表没有连接
+	ConnectionCount  int    `json:"connection_count"`
+	ConnectionSource string `json:"connection_source,omitempty"`
+	// LatencyMs 为到探测目标的 TCP 建连延迟，0 表示未探测
+	LatencyMs int64 `json:"latency_ms,omitempty"`
+}
+
+// PathUsage carries disk usage of a watched directory.
+// Exists is false when the path is missing; all sizes are zero in that case.
+type PathUsage struct {
+	Path      string `json:"path"`
+	Exists    bool   `json:"exists"`
+	UsedBytes uint64 `json:"used_bytes"`
+	Truncated bool   `json:"truncated"`
+	FSTotal   uint64 `json:"fs_total"`
+	FSUsed    uint64 `json:"fs_used"`
+	FSFree    uint64 `json:"fs_free"`
 }
 
 // Stats holds total/used values.
@@ -2,6 +2,8 @@ package capability
 
 import (
 	"context"
+	"fmt"
+	"os"
 	"os/exec"
 	"regexp"
 	"strings"
@@ -19,16 +21,17 @@ func (r *DefaultCommandRunner) Run(ctx context.Context, name string, args ...str
 	return exec.CommandContext(ctx, name, args...).Output()
 }
 
-// Detector detects the core capabilities of sing-box/xray.
+// Detector detects the core capabilities of sing-box/xray and the optional mieru server (mita).
 type Detector struct {
 	singBoxPath string
 	xrayPath    string
+	mieruPath   string
 	runner      CommandRunner
 }
 
 // DetectedCapabilities holds the detected core capabilities.
 type DetectedCapabilities struct {
-	CoreType     string   // sing-box, xray
+	CoreType     string   // sing-box, xray, mieru
 	CoreVersion  string   // e.g., "1.10.0"
 	Capabilities []string // e.g., ["reality", "multiplex", "brutal"]
 	BuildTags    []string // e.g., ["with_v2ray_api", "with_quic"]
@@ -45,10 +48,18 @@ func NewDetector(singBoxPath, xrayPath string) *Detector {
 	return &Detector{
 		singBoxPath: singBoxPath,
 		xrayPath:    xrayPath,
+		mieruPath:   "mita",
 		runner:      &DefaultCommandRunner{},
 	}
 }
 
+// SetMieruPath sets the mieru server (mita) binary path. An empty path keeps the default.
+func (d *Detector) SetMieruPath(path string) {
+	if path != "" {
+		d.mieruPath = path
+	}
+}
+
 // SetRunner sets a custom command runner for testing.
 func (d *Detector) SetRunner(runner CommandRunner) {
 	d.runner = runner
@@ -132,16 +143,52 @@ func (d *Detector) DetectXray(ctx context.Context) (*DetectedCapabilities, error
 	return caps, nil
 }
 
+// DetectMieru checks if the mieru server (mita) is installed and returns its capabilities.
+func (d *Detector) DetectMieru(ctx context.Context) (*DetectedCapabilities, error) {
+	path := d.mieruPath
+	if path == "" {
+		path = "mita"
+	}
+
+	// Run mita version (e.g., "3.11.2")
+	output, err := d.runner.Run(ctx, path, "version")
+	if err != nil {
+		return nil, err
+	}
+
+	caps := &DetectedCapabilities{
+		CoreType:     "mieru",
+		Capabilities: []string{"mieru"},
+		BuildTags:    []string{},
+	}
+
+	versionRegex := regexp.MustCompile(`(\d+\.\d+\.\d+)`)
+	if matches := versionRegex.FindStringSubmatch(string(output)); len(matches) > 1 {
+		caps.CoreVersion = matches[1]
+	}
+
+	return caps, nil
+}
+
 // Detect attempts to detect which core is installed.
+// mieru runs alongside the primary core, so its capability is merged into the result when present.
 func (d *Detector) Detect(ctx context.Context) (*DetectedCapabilities, error) {
+	caps := d.detectPrimary(ctx)
+	if mieru, err := d.DetectMieru(ctx); err == nil {
+		caps.Capabilities = d.uniqueStrings(append(caps.Capabilities, mieru.Capabilities...))
+	}
+	return caps, nil
+}
+
+func (d *Detector) detectPrimary(ctx context.Context) *DetectedCapabilities {
 	// Try sing-box first
 	if caps, err := d.DetectSingBox(ctx); err == nil {
-		return caps, nil
+		return caps
 	}
 
 	// Try xray
 	if caps, err := d.DetectXray(ctx); err == nil {
-		return caps, nil
+		return caps
 	}
 
 	// Return empty capabilities if neither is available
@@ -149,7 +196,28 @@ func (d *Detector) Detect(ctx context.Context) (*DetectedCapabilities, error) {
 		CoreType:     "unknown",
 		Capabilities: []string{},
 		BuildTags:    []string{},
-	}, nil
+	}
+}
+
+// BinaryFingerprint returns a cheap fingerprint (resolved path, size, mtime) of the core binaries.
+// It changes when a core is upgraded in place, so callers can drop cached capabilities
+// without running the binaries on every report.
+func (d *Detector) BinaryFingerprint() string {
+	parts := make([]string, 0, 3)
+	for _, path := range []string{d.singBoxPath, d.xrayPath, d.mieruPath} {
+		resolved, err := exec.LookPath(path)
+		if err != nil {
+			parts = append(parts, path+":missing")
+			continue
+		}
+		info, err := os.Stat(resolved)
+		if err != nil {
+			parts = append(parts, resolved+":missing")
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s:%d:%d", resolved, info.Size(), info.ModTime().UnixNano()))
+	}
+	return strings.Join(parts, "|")
 }
 
 // deriveSingBoxCapabilities derives capabilities from sing-box version and build tags.
@@ -157,6 +225,9 @@ func (d *Detector) deriveSingBoxCapabilities(version string, buildTags []string)
 	caps := []string{}
 
 	// Version-based capabilities
+	if d.compareVersions(version, "1.2.0") >= 0 {
+		caps = append(caps, "shadowtls")
+	}
 	if d.compareVersions(version, "1.3.0") >= 0 {
 		caps = append(caps, "reality", "multiplex")
 	}
@@ -164,8 +235,15 @@ func (d *Detector) deriveSingBoxCapabilities(version string, buildTags []string)
 		caps = append(caps, "brutal")
 	}
 	if d.compareVersions(version, "1.8.0") >= 0 {
+		caps = append(caps, "rule_set")
+	}
+	if d.compareVersions(version, "1.9.0") >= 0 {
+		// TLS 入站 ECH 自 1.9.0 起可用，与面板 SingBoxVersionRequirements 保持一致
 		caps = append(caps, "ech")
 	}
+	if d.compareVersions(version, "1.12.0") >= 0 {
+		caps = append(caps, "anytls")
+	}
 
 	// Build tag based capabilities
 	for _, tag := range buildTags {
@@ -185,6 +263,8 @@ func (d *Detector) deriveSingBoxCapabilities(version string, buildTags []string)
 			caps = append(caps, "wireguard")
 		case strings.Contains(tag, "ech"):
 			caps = append(caps, "ech")
+		case strings.Contains(tag, "naive"):
+			caps = append(caps, "naive")
 		}
 	}
 
@@ -197,7 +277,7 @@ func (d *Detector) deriveXrayCapabilities(version string) []string {
 
 	// Xray has Reality support from early versions
 	if d.compareVersions(version, "1.0.0") >= 0 {
-		caps = append(caps, "reality", "xtls")
+		caps = append(caps, "reality", "xtls", "geoip", "geosite")
 	}
 	if d.compareVersions(version, "1.8.0") >= 0 {
 		caps = append(caps, "splithttp")
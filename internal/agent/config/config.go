@@ -34,6 +34,8 @@ const (
 	defaultInstallScriptPath      = "/opt/xboard/deploy/agent.sh"
 	defaultSingBoxBinaryPath      = "/opt/xboard/bin/sing-box"
 	defaultXrayBinaryPath         = "/opt/xboard/bin/xray"
+	defaultMieruBinaryPath        = "/opt/xboard/bin/mita"
+	defaultNetIOStatePath         = "/var/lib/xboard/netio-state.json"
 	defaultUpdateHealthTimeout    = 2 * time.Minute
 	defaultUpdateMaxCrashCount    = 3
 	defaultUpdateJitterMax        = 30 * time.Second
@@ -41,6 +43,9 @@ const (
 )
 
 type Config struct {
+	// Path is the file the config was loaded from, used to persist panel-issued changes.
+	Path string `yaml:"-"`
+
 	Panel      PanelConfig      `yaml:"panel"`
 	Server     ServerConfig     `yaml:"server"`
 	GRPC       GRPCConfig       `yaml:"grpc"`
@@ -54,6 +59,21 @@ type Config struct {
 	Update     UpdateConfig     `yaml:"update"`
 	CDN        CDNConfig        `yaml:"cdn"`
 	Log        LogConfig        `yaml:"log"`
+	Monitor    MonitorConfig    `yaml:"monitor"`
+}
+
+// MonitorConfig holds system monitor settings.
+type MonitorConfig struct {
+	// WatchPaths lists directories whose disk usage is reported to the panel.
+	// Defaults to the protocol config dir and the log dir; set to an empty list to disable.
+	WatchPaths []string `yaml:"watch_paths"`
+	// ClashAPI is the sing-box Clash API address (e.g. "http://127.0.0.1:9090") used for the active
+	// connection count. Empty falls back to counting sockets in /proc/net.
+	ClashAPI    string `yaml:"clash_api"`
+	ClashSecret string `yaml:"clash_secret"`
+	// ProbeTarget is a "host:port" the agent dials each report cycle to measure node latency,
+	// which the panel uses to sort and annotate subscriptions. Empty disables the probe.
+	ProbeTarget string `yaml:"probe_target"`
 }
 
 // LogConfig holds agent log settings.
@@ -84,6 +104,24 @@ type GRPCConfig struct {
 
 	// Timeout configuration
 	Timeout TimeoutConfig `yaml:"timeout"`
+
+	// ConfigSigning verifies the panel's HMAC signature before applying synced config
+	ConfigSigning ConfigSigningConfig `yaml:"config_signing"`
+}
+
+// ConfigSigningConfig holds the shared secret used to verify config signatures
+type ConfigSigningConfig struct {
+	// Enabled rejects unsigned or mismatched config instead of applying it
+	Enabled bool `yaml:"enabled"`
+
+	// KeyID must match the panel's key id when set, allowing secret rotation
+	KeyID string `yaml:"key_id"`
+
+	// Secret is the HMAC-SHA256 key shared with the panel
+	Secret string `yaml:"secret"`
+
+	// AgentHostID pins the host signatures must name; when 0 the first verified host id is pinned
+	AgentHostID int64 `yaml:"agent_host_id"`
 }
 
 // TLSConfig holds TLS settings for gRPC
@@ -159,6 +197,15 @@ type ServerConfig struct {
 
 	// AuthToken for API authentication (uses host_token if empty)
 	AuthToken string `yaml:"auth_token"`
+
+	// authTokenFromHost records that AuthToken was defaulted from panel.host_token
+	authTokenFromHost bool
+}
+
+// InheritsHostToken reports whether AuthToken was defaulted from panel.host_token,
+// so a rotated host token must replace it as well.
+func (c ServerConfig) InheritsHostToken() bool {
+	return c.authTokenFromHost
 }
 
 type ProtocolConfig struct {
@@ -198,6 +245,20 @@ type ProtocolConfig struct {
 	// PostHook is executed after applying config changes (optional)
 	PostHook string `yaml:"post_hook"`
 
+	// SnapshotKeep is the number of previous config snapshots kept per core (default 3)
+	SnapshotKeep int `yaml:"snapshot_keep"`
+
+	// ApplyGracePeriod is how long the service must keep running after an apply
+	// before the new config is kept (default 5s, negative disables the check)
+	ApplyGracePeriod time.Duration `yaml:"apply_grace_period"`
+
+	// HealthCheckCmd probes the core after the grace period; failure rolls back (optional)
+	HealthCheckCmd string `yaml:"health_check_cmd"`
+
+	// DrainTimeout bounds how long a restart waits for existing connections to close
+	// when the core cannot reload in place (default 30s)
+	DrainTimeout time.Duration `yaml:"drain_timeout"`
+
 	// Custom commands for custom init system
 	CustomCommands CustomCommands `yaml:"custom_commands"`
 }
@@ -250,11 +311,32 @@ type ProxyConfig struct {
 	CgroupBasePath string        `yaml:"cgroup_base_path"`
 }
 
+// IntervalConfig controls how often the agent syncs from and reports to the panel.
+//
+// Jitter offsets each ticker by a fraction of its interval so that agents sharing an
+// interval do not hit the panel at the same instant. The offset only shifts the phase:
+// the period stays the same, but the first tick after start or an interval change is
+// delayed by up to Jitter*interval, so a larger jitter spreads load better at the cost of
+// data that may be up to that much staler right after a restart or interval change.
 type IntervalConfig struct {
 	Sync   int `yaml:"sync"`   // Seconds
 	Report int `yaml:"report"` // Seconds
+	// Jitter is the maximum offset as a fraction of the interval (0 disables, capped at 0.5).
+	Jitter float64 `yaml:"jitter"`
+	// JitterMode is "stable" (offset derived from the host token, identical across restarts)
+	// or "random" (offset re-drawn whenever the tickers are reset). Defaults to "stable".
+	JitterMode string `yaml:"jitter_mode"`
 }
 
+// Interval jitter modes.
+const (
+	IntervalJitterStable = "stable"
+	IntervalJitterRandom = "random"
+)
+
+// maxIntervalJitter caps the jitter fraction so reports never slip by more than half a period.
+const maxIntervalJitter = 0.5
+
 // CDNConfig holds Caddy CDN configuration.
 type CDNConfig struct {
 	// Enabled controls whether CDN management is active.
@@ -277,12 +359,24 @@ type CoreConfig struct {
 	InstallScriptPath string `yaml:"install_script_path"`
 	SingBoxBinaryPath string `yaml:"singbox_binary_path"`
 	XrayBinaryPath    string `yaml:"xray_binary_path"`
+	MieruBinaryPath   string `yaml:"mieru_binary_path"`  // mieru 服务端（mita），未安装时不提供 mieru 能力
+	MieruServiceName  string `yaml:"mieru_service_name"` // mita 守护进程的服务名
+	MieruConfigDir    string `yaml:"mieru_config_dir"`   // 下发给 mita 的配置目录
+	// 核心升级的发布包与校验清单地址，支持 {version}、{os}、{arch} 占位符；未配置时拒绝该核心的升级任务
+	SingBoxReleaseURL  string `yaml:"singbox_release_url"`
+	SingBoxChecksumURL string `yaml:"singbox_checksum_url"`
+	XrayReleaseURL     string `yaml:"xray_release_url"`
+	XrayChecksumURL    string `yaml:"xray_checksum_url"`
 }
 
 type TrafficConfig struct {
 	Type      string `yaml:"type"`      // "netio", "none", "dummy", "xray_api"
-	Interface string `yaml:"interface"` // Network interface name, e.g., "eth0"; empty for all
+	Interface string `yaml:"interface"` // Network interface name, e.g., "eth0"; empty or "auto" for the default-route interface, "all" for every non-loopback interface
 	Address   string `yaml:"address"`   // API address for xray_api type, e.g., "127.0.0.1:10085"
+	// Interfaces lists additional interfaces whose traffic is summed with Interface.
+	Interfaces []string `yaml:"interfaces"`
+	// StatePath persists netio counter baselines so an agent restart within the same boot keeps counting.
+	StatePath string `yaml:"state_path"`
 }
 
 type UpdateConfig struct {
@@ -320,10 +414,25 @@ func Load(path string) (*Config, error) {
 	if err := cfg.Validate(); err != nil {
 		return nil, err
 	}
+	cfg.Path = path
 
 	return cfg, nil
 }
 
+// SaveHostToken persists a rotated host token. It re-reads the file so defaults applied in
+// memory are not written back, and replaces it atomically.
+func SaveHostToken(path, hostToken string) error {
+	if strings.TrimSpace(hostToken) == "" {
+		return fmt.Errorf("host token is empty")
+	}
+	cfg, err := loadFromPath(path)
+	if err != nil {
+		return err
+	}
+	cfg.Panel.HostToken = hostToken
+	return save(path, cfg)
+}
+
 func loadFromPath(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -349,6 +458,19 @@ func applyDefaults(cfg *Config) error {
 	if cfg.Interval.Report <= 0 {
 		cfg.Interval.Report = 60
 	}
+	if cfg.Interval.Jitter < 0 {
+		cfg.Interval.Jitter = 0
+	}
+	if cfg.Interval.Jitter > maxIntervalJitter {
+		cfg.Interval.Jitter = maxIntervalJitter
+	}
+	switch cfg.Interval.JitterMode {
+	case IntervalJitterStable, IntervalJitterRandom:
+	case "":
+		cfg.Interval.JitterMode = IntervalJitterStable
+	default:
+		return fmt.Errorf("invalid interval.jitter_mode %q: must be %q or %q", cfg.Interval.JitterMode, IntervalJitterStable, IntervalJitterRandom)
+	}
 
 	// Server defaults
 	if cfg.Server.Listen == "" {
@@ -356,6 +478,7 @@ func applyDefaults(cfg *Config) error {
 	}
 	if cfg.Server.AuthToken == "" && cfg.Panel.HostToken != "" {
 		cfg.Server.AuthToken = cfg.Panel.HostToken
+		cfg.Server.authTokenFromHost = true
 	}
 
 	// gRPC server defaults are retired with agent-grpc-retirement; keep values untouched so legacy configs do not become required.
@@ -387,6 +510,10 @@ func applyDefaults(cfg *Config) error {
 		cfg.Protocol.ServiceName = "sing-box"
 	}
 
+	if strings.TrimSpace(cfg.Traffic.StatePath) == "" {
+		cfg.Traffic.StatePath = defaultNetIOStatePath
+	}
+
 	// Core defaults
 	if strings.TrimSpace(cfg.Core.InstallScriptPath) == "" {
 		cfg.Core.InstallScriptPath = defaultInstallScriptPath
@@ -397,6 +524,15 @@ func applyDefaults(cfg *Config) error {
 	if strings.TrimSpace(cfg.Core.XrayBinaryPath) == "" {
 		cfg.Core.XrayBinaryPath = defaultXrayBinaryPath
 	}
+	if strings.TrimSpace(cfg.Core.MieruBinaryPath) == "" {
+		cfg.Core.MieruBinaryPath = defaultMieruBinaryPath
+	}
+	if strings.TrimSpace(cfg.Core.MieruServiceName) == "" {
+		cfg.Core.MieruServiceName = "mita"
+	}
+	if strings.TrimSpace(cfg.Core.MieruConfigDir) == "" {
+		cfg.Core.MieruConfigDir = "/etc/mita"
+	}
 
 	if strings.TrimSpace(cfg.Update.ReleaseBaseURL) == "" {
 		cfg.Update.ReleaseBaseURL = updater.DefaultReleaseBaseURL
@@ -524,6 +660,15 @@ func applyDefaults(cfg *Config) error {
 		cfg.Log.MaxDays = 7
 	}
 
+	// Monitor defaults: 未配置时监控配置目录与日志目录，显式配置为空列表则关闭
+	if cfg.Monitor.WatchPaths == nil {
+		for _, path := range []string{cfg.Protocol.ConfigDir, cfg.Log.Dir} {
+			if strings.TrimSpace(path) != "" {
+				cfg.Monitor.WatchPaths = append(cfg.Monitor.WatchPaths, path)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -726,6 +871,9 @@ func (cfg *Config) Validate() error {
 	if err := cfg.validateUpdateConfig(); err != nil {
 		return err
 	}
+	if cfg.GRPC.ConfigSigning.Enabled && cfg.GRPC.ConfigSigning.Secret == "" {
+		return fmt.Errorf("grpc.config_signing.secret is required when config signing is enabled")
+	}
 	if cfg.Proxy.Enabled {
 		if cfg.Proxy.PortRangeStart <= 0 || cfg.Proxy.PortRangeEnd <= 0 || cfg.Proxy.PortRangeEnd < cfg.Proxy.PortRangeStart {
 			return fmt.Errorf("proxy port range is invalid")
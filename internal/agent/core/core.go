@@ -9,6 +9,7 @@ type Status string
 const (
 	CoreTypeSingBox CoreType = "sing-box"
 	CoreTypeXray    CoreType = "xray"
+	CoreTypeMieru   CoreType = "mieru"
 )
 
 const (
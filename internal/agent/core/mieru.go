@@ -0,0 +1,381 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/agent/capability"
+	"github.com/creamcroissant/xboard/internal/agent/initsys"
+	"github.com/creamcroissant/xboard/internal/agent/protocol/parser"
+)
+
+// MieruCore implements ProxyCore for the mieru server (mita).
+// mita runs as a single system daemon (no per-instance units); configs are pushed with "mita apply config"
+// and take effect after "mita start"/"mita reload". Only one instance can be active at a time.
+type MieruCore struct {
+	initSys     initsys.InitSystem
+	detector    *capability.Detector
+	runner      capability.CommandRunner
+	binaryPath  string
+	serviceName string
+	configDir   string
+
+	mu        sync.RWMutex
+	instances map[string]*CoreInstance
+	// active 为当前 mita 守护进程所承载配置对应的实例 ID
+	active string
+}
+
+// NewMieruCore creates a new mieru core adapter.
+func NewMieruCore(initSys initsys.InitSystem, detector *capability.Detector, binaryPath, serviceName, configDir string) *MieruCore {
+	if initSys == nil {
+		initSys = initsys.Detect()
+	}
+	if detector == nil {
+		detector = capability.NewDetector("", "")
+		detector.SetMieruPath(binaryPath)
+	}
+	if binaryPath == "" {
+		binaryPath = "mita"
+	}
+	if serviceName == "" {
+		serviceName = "mita"
+	}
+	if configDir == "" {
+		configDir = "/etc/mita"
+	}
+
+	return &MieruCore{
+		initSys:     initSys,
+		detector:    detector,
+		runner:      &capability.DefaultCommandRunner{},
+		binaryPath:  binaryPath,
+		serviceName: serviceName,
+		configDir:   configDir,
+		instances:   make(map[string]*CoreInstance),
+	}
+}
+
+func (c *MieruCore) Type() CoreType {
+	return CoreTypeMieru
+}
+
+func (c *MieruCore) Version(ctx context.Context) (string, error) {
+	caps, err := c.detector.DetectMieru(ctx)
+	if err != nil {
+		return "", err
+	}
+	return caps.CoreVersion, nil
+}
+
+func (c *MieruCore) Capabilities(ctx context.Context) ([]string, error) {
+	caps, err := c.detector.DetectMieru(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return append([]string(nil), caps.Capabilities...), nil
+}
+
+func (c *MieruCore) IsInstalled(ctx context.Context) bool {
+	_, err := c.detector.DetectMieru(ctx)
+	return err == nil
+}
+
+func (c *MieruCore) ValidateConfig(ctx context.Context, configPath string) error {
+	if configPath == "" {
+		return fmt.Errorf("config path is required")
+	}
+
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	if !json.Valid(content) {
+		return fmt.Errorf("invalid mieru config JSON")
+	}
+
+	details, err := parser.NewMieruParser().Parse(filepath.Base(configPath), content)
+	if err != nil {
+		return fmt.Errorf("parse config: %w", err)
+	}
+	if len(details) == 0 {
+		return fmt.Errorf("mieru config has no port bindings")
+	}
+
+	return nil
+}
+
+func (c *MieruCore) Start(ctx context.Context, instanceID, configPath string, listenPorts []int) error {
+	if instanceID == "" {
+		return fmt.Errorf("instance id is required")
+	}
+	if configPath == "" {
+		return fmt.Errorf("config path is required")
+	}
+
+	if err := c.ValidateConfig(ctx, configPath); err != nil {
+		c.updateInstanceError(instanceID, err.Error())
+		return err
+	}
+
+	hash, content, err := hashConfigFile(configPath)
+	if err != nil {
+		c.updateInstanceError(instanceID, err.Error())
+		return err
+	}
+
+	// 端口列表由调用方显式传入，避免解析配置推导端口
+	c.updateInstance(instanceID, func(inst *CoreInstance) {
+		inst.Status = StatusStarting
+		inst.ConfigPath = configPath
+		inst.ConfigHash = hash
+		inst.ListenPorts = listenPorts
+		inst.Error = ""
+	})
+
+	targetPath := c.instanceConfigPath(instanceID)
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
+		c.updateInstanceError(instanceID, fmt.Sprintf("create config dir: %v", err))
+		return err
+	}
+	if err := os.WriteFile(targetPath, content, 0644); err != nil {
+		c.updateInstanceError(instanceID, fmt.Sprintf("write instance config: %v", err))
+		return err
+	}
+
+	// 先确保唯一的 mita 守护进程运行，再下发配置并启动代理；已有代理运行时 apply 后需 reload 才会生效
+	if err := c.initSys.Start(ctx, c.serviceName); err != nil {
+		c.updateInstanceError(instanceID, err.Error())
+		return err
+	}
+	if err := c.applyConfig(ctx, targetPath); err != nil {
+		c.updateInstanceError(instanceID, err.Error())
+		return err
+	}
+	previous := c.activeInstance()
+	action := "start"
+	if previous != "" {
+		action = "reload"
+	}
+	if err := c.mita(ctx, action); err != nil {
+		c.updateInstanceError(instanceID, err.Error())
+		return err
+	}
+	c.setActive(instanceID)
+
+	c.updateInstance(instanceID, func(inst *CoreInstance) {
+		inst.Status = StatusRunning
+		inst.StartedAt = time.Now().Unix()
+		inst.Error = ""
+	})
+
+	return nil
+}
+
+func (c *MieruCore) Stop(ctx context.Context, instanceID string) error {
+	if instanceID == "" {
+		return fmt.Errorf("instance id is required")
+	}
+
+	c.updateInstance(instanceID, func(inst *CoreInstance) {
+		inst.Status = StatusStopping
+		inst.Error = ""
+	})
+
+	// 守护进程由所有实例共享，仅停止代理；非当前实例已被替换，无需操作 mita
+	if c.activeInstance() == instanceID {
+		if err := c.mita(ctx, "stop"); err != nil {
+			c.updateInstanceError(instanceID, err.Error())
+			return err
+		}
+		c.setActive("")
+	}
+
+	c.updateInstance(instanceID, func(inst *CoreInstance) {
+		inst.Status = StatusStopped
+		inst.Error = ""
+	})
+
+	return nil
+}
+
+func (c *MieruCore) Restart(ctx context.Context, instanceID string) error {
+	if instanceID == "" {
+		return fmt.Errorf("instance id is required")
+	}
+
+	c.updateInstance(instanceID, func(inst *CoreInstance) {
+		inst.Status = StatusStarting
+		inst.Error = ""
+	})
+
+	if err := c.initSys.Restart(ctx, c.serviceName); err != nil {
+		c.updateInstanceError(instanceID, err.Error())
+		return err
+	}
+	// 守护进程重启后需要重新下发该实例的配置并启动代理
+	if err := c.applyConfig(ctx, c.instanceConfigPath(instanceID)); err != nil {
+		c.updateInstanceError(instanceID, err.Error())
+		return err
+	}
+	if err := c.mita(ctx, "start"); err != nil {
+		c.updateInstanceError(instanceID, err.Error())
+		return err
+	}
+
+	c.setActive(instanceID)
+
+	c.updateInstance(instanceID, func(inst *CoreInstance) {
+		inst.Status = StatusRunning
+		inst.Error = ""
+	})
+
+	return nil
+}
+
+func (c *MieruCore) Reload(ctx context.Context, instanceID string) error {
+	if instanceID == "" {
+		return fmt.Errorf("instance id is required")
+	}
+
+	if err := c.applyConfig(ctx, c.instanceConfigPath(instanceID)); err != nil {
+		c.updateInstanceError(instanceID, err.Error())
+		return err
+	}
+	if err := c.mita(ctx, "reload"); err != nil {
+		c.updateInstanceError(instanceID, err.Error())
+		return err
+	}
+	c.setActive(instanceID)
+
+	c.updateInstance(instanceID, func(inst *CoreInstance) {
+		inst.Status = StatusRunning
+		inst.Error = ""
+	})
+
+	return nil
+}
+
+func (c *MieruCore) Status(ctx context.Context, instanceID string) (*CoreInstance, error) {
+	if instanceID == "" {
+		return nil, fmt.Errorf("instance id is required")
+	}
+
+	running, err := c.initSys.Status(ctx, c.serviceName)
+	if err != nil {
+		c.updateInstanceError(instanceID, err.Error())
+		return nil, err
+	}
+	// 守护进程运行只说明 mita 可用，实例仅在其配置为当前生效配置时视为运行
+	active := c.activeInstance() == instanceID
+
+	c.updateInstance(instanceID, func(inst *CoreInstance) {
+		if running && active {
+			inst.Status = StatusRunning
+		} else {
+			inst.Status = StatusStopped
+		}
+	})
+
+	c.mu.RLock()
+	inst := cloneInstance(c.instances[instanceID])
+	c.mu.RUnlock()
+
+	if inst == nil {
+		inst = &CoreInstance{
+			ID:       instanceID,
+			CoreType: CoreTypeMieru,
+			Status:   StatusStopped,
+		}
+	}
+
+	return inst, nil
+}
+
+func (c *MieruCore) ListInstances(ctx context.Context) ([]*CoreInstance, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	result := make([]*CoreInstance, 0, len(c.instances))
+	for _, inst := range c.instances {
+		result = append(result, cloneInstance(inst))
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+
+	return result, nil
+}
+
+// CollectTraffic mita 不提供按用户的流量统计接口，流量由 NetIO 采集。
+func (c *MieruCore) CollectTraffic(ctx context.Context, instanceID string) ([]TrafficSample, error) {
+	return nil, nil
+}
+
+func (c *MieruCore) applyConfig(ctx context.Context, path string) error {
+	return c.mita(ctx, "apply", "config", path)
+}
+
+func (c *MieruCore) mita(ctx context.Context, args ...string) error {
+	output, err := c.runner.Run(ctx, c.binaryPath, args...)
+	if err != nil {
+		return fmt.Errorf("mita %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// instanceConfigPath 返回实例配置的落盘路径，供 apply config 与重启后重新下发使用。
+func (c *MieruCore) instanceConfigPath(instanceID string) string {
+	return filepath.Join(c.configDir, "instance-"+instanceID+".json")
+}
+
+func (c *MieruCore) activeInstance() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.active
+}
+
+// setActive 记录当前生效的实例，被替换的旧实例标记为已停止。
+func (c *MieruCore) setActive(instanceID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.active != "" && c.active != instanceID {
+		if inst, ok := c.instances[c.active]; ok {
+			inst.Status = StatusStopped
+		}
+	}
+	c.active = instanceID
+}
+
+func (c *MieruCore) updateInstance(instanceID string, update func(*CoreInstance)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	inst, ok := c.instances[instanceID]
+	if !ok {
+		inst = &CoreInstance{
+			ID:       instanceID,
+			CoreType: CoreTypeMieru,
+			Status:   StatusStopped,
+		}
+		c.instances[instanceID] = inst
+	}
+
+	update(inst)
+}
+
+func (c *MieruCore) updateInstanceError(instanceID, message string) {
+	c.updateInstance(instanceID, func(inst *CoreInstance) {
+		inst.Status = StatusError
+		inst.Error = message
+	})
+}
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeInitSystem 记录被操作的服务名，用于断言 mita 只有一个守护进程。
+type fakeInitSystem struct {
+	calls   []string
+	running bool
+}
+
+func (f *fakeInitSystem) Type() string { return "fake" }
+func (f *fakeInitSystem) Start(_ context.Context, service string) error {
+	f.calls = append(f.calls, "start "+service)
+	f.running = true
+	return nil
+}
+func (f *fakeInitSystem) Stop(_ context.Context, service string) error {
+	f.calls = append(f.calls, "stop "+service)
+	f.running = false
+	return nil
+}
+func (f *fakeInitSystem) Restart(_ context.Context, service string) error {
+	f.calls = append(f.calls, "restart "+service)
+	f.running = true
+	return nil
+}
+func (f *fakeInitSystem) Reload(_ context.Context, service string) error {
+	f.calls = append(f.calls, "reload "+service)
+	return nil
+}
+func (f *fakeInitSystem) Status(_ context.Context, service string) (bool, error) {
+	return f.running, nil
+}
+func (f *fakeInitSystem) Enable(context.Context, string) error  { return nil }
+func (f *fakeInitSystem) Disable(context.Context, string) error { return nil }
+
+type fakeMitaRunner struct {
+	commands []string
+}
+
+func (r *fakeMitaRunner) Run(_ context.Context, name string, args ...string) ([]byte, error) {
+	r.commands = append(r.commands, strings.Join(append([]string{name}, args...), " "))
+	return nil, nil
+}
+
+func TestMieruCoreUsesSingleDaemon(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "mieru.json")
+	config := `{"portBindings":[{"port":2999,"protocol":"TCP"}],"users":[{"name":"u","password":"p"}],"mtu":1400}`
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	initSys := &fakeInitSystem{}
+	runner := &fakeMitaRunner{}
+	c := NewMieruCore(initSys, nil, "mita", "", filepath.Join(dir, "etc"))
+	c.runner = runner
+	ctx := context.Background()
+
+	if err := c.Start(ctx, "a", configPath, []int{2999}); err != nil {
+		t.Fatalf("start a: %v", err)
+	}
+	if err := c.Start(ctx, "b", configPath, []int{2999}); err != nil {
+		t.Fatalf("start b: %v", err)
+	}
+
+	if want := []string{"start mita", "start mita"}; !reflect.DeepEqual(initSys.calls, want) {
+		t.Fatalf("init calls = %v, want only the shared mita service", initSys.calls)
+	}
+	wantCommands := []string{
+		"mita apply config " + c.instanceConfigPath("a"),
+		"mita start",
+		"mita apply config " + c.instanceConfigPath("b"),
+		"mita reload",
+	}
+	if !reflect.DeepEqual(runner.commands, wantCommands) {
+		t.Fatalf("mita commands = %v, want %v", runner.commands, wantCommands)
+	}
+
+	// 被替换的实例不再视为运行，当前实例随守护进程状态运行
+	if inst, _ := c.Status(ctx, "a"); inst.Status != StatusStopped {
+		t.Fatalf("replaced instance status = %s, want stopped", inst.Status)
+	}
+	if inst, _ := c.Status(ctx, "b"); inst.Status != StatusRunning {
+		t.Fatalf("active instance status = %s, want running", inst.Status)
+	}
+
+	// 停止非当前实例不影响 mita；停止当前实例只停代理、不停守护进程
+	runner.commands = nil
+	if err := c.Stop(ctx, "a"); err != nil {
+		t.Fatalf("stop a: %v", err)
+	}
+	if err := c.Stop(ctx, "b"); err != nil {
+		t.Fatalf("stop b: %v", err)
+	}
+	if want := []string{"mita stop"}; !reflect.DeepEqual(runner.commands, want) {
+		t.Fatalf("stop commands = %v, want %v", runner.commands, want)
+	}
+	if len(initSys.calls) != 2 {
+		t.Fatalf("init calls after stop = %v, daemon must keep running", initSys.calls)
+	}
+}
@@ -0,0 +1,396 @@
+package core
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const defaultUpgradeMaxDownloadBytes = 200 * 1024 * 1024
+
+var ErrUpgradeChecksumMismatch = errors.New("core upgrade checksum mismatch")
+
+// UpgraderConfig 定义单个核心二进制的升级参数。
+// ReleaseURL 与 ChecksumURL 支持 {version}、{os}、{arch} 占位符，ChecksumURL 可以是单个哈希或 sha256sum 格式清单。
+type UpgraderConfig struct {
+	BinaryPath       string
+	ReleaseURL       string
+	ChecksumURL      string
+	MaxDownloadBytes int64
+	HTTPClient       *http.Client
+}
+
+// UpgradeHooks 由调用方注入：校验暂存二进制、以排空方式切换、切换后的健康检查与进度上报。
+type UpgradeHooks struct {
+	Progress    func(phase, message string, payload map[string]any)
+	Validate    func(ctx context.Context, stagedPath string) error
+	Activate    func(ctx context.Context) error
+	HealthCheck func(ctx context.Context) error
+}
+
+// UpgradeResult 描述一次核心二进制升级的结果。
+type UpgradeResult struct {
+	CoreType   string `json:"core_type"`
+	Version    string `json:"version"`
+	BinaryPath string `json:"binary_path"`
+	AssetURL   string `json:"asset_url"`
+	SHA256     string `json:"sha256"`
+	Activated  bool   `json:"activated"`
+	RolledBack bool   `json:"rolled_back"`
+}
+
+// Upgrader 下载指定版本的核心、校验 SHA256 后替换二进制；切换或健康检查失败时恢复旧二进制。
+type Upgrader struct {
+	cfg    UpgraderConfig
+	client *http.Client
+	logger *slog.Logger
+}
+
+func NewUpgrader(cfg UpgraderConfig, logger *slog.Logger) *Upgrader {
+	if cfg.MaxDownloadBytes <= 0 {
+		cfg.MaxDownloadBytes = defaultUpgradeMaxDownloadBytes
+	}
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Minute}
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Upgrader{cfg: cfg, client: client, logger: logger}
+}
+
+func (u *Upgrader) Upgrade(ctx context.Context, rawCoreType, version string, hooks UpgradeHooks) (*UpgradeResult, error) {
+	coreType, err := normalizeInstallCoreType(rawCoreType)
+	if err != nil {
+		return nil, err
+	}
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if version == "" {
+		return nil, fmt.Errorf("core upgrade version is required")
+	}
+	binaryPath := strings.TrimSpace(u.cfg.BinaryPath)
+	if binaryPath == "" {
+		return nil, fmt.Errorf("core binary path is empty")
+	}
+	if strings.TrimSpace(u.cfg.ReleaseURL) == "" {
+		return nil, fmt.Errorf("release url for %s is not configured", coreType)
+	}
+	if strings.TrimSpace(u.cfg.ChecksumURL) == "" {
+		return nil, fmt.Errorf("checksum url for %s is not configured", coreType)
+	}
+
+	assetURL := renderReleaseURL(u.cfg.ReleaseURL, version)
+	result := &UpgradeResult{CoreType: string(coreType), Version: version, BinaryPath: binaryPath, AssetURL: assetURL}
+	stagingDir, err := os.MkdirTemp(filepath.Dir(binaryPath), ".core-upgrade-*")
+	if err != nil {
+		return nil, fmt.Errorf("create staging dir: %w", err)
+	}
+	defer os.RemoveAll(stagingDir)
+
+	hooks.progress("downloading", "downloading core release", map[string]any{"url": assetURL})
+	assetPath := filepath.Join(stagingDir, assetFilename(assetURL))
+	if err := u.download(ctx, assetURL, assetPath); err != nil {
+		return nil, err
+	}
+
+	hooks.progress("verifying", "verifying core release checksum", nil)
+	expected, err := u.fetchExpectedChecksum(ctx, renderReleaseURL(u.cfg.ChecksumURL, version), filepath.Base(assetPath))
+	if err != nil {
+		return nil, err
+	}
+	actual, err := fileSHA256(assetPath)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(actual, expected) {
+		return nil, fmt.Errorf("%w: expected %s actual %s", ErrUpgradeChecksumMismatch, expected, actual)
+	}
+	result.SHA256 = actual
+
+	stagedPath := filepath.Join(stagingDir, filepath.Base(binaryPath))
+	if err := extractCoreBinary(assetPath, stagedPath, filepath.Base(binaryPath)); err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(stagedPath, 0o755); err != nil {
+		return nil, fmt.Errorf("mark staged core executable: %w", err)
+	}
+
+	hooks.progress("validating", "validating current config with staged core", map[string]any{"staged_path": stagedPath})
+	if hooks.Validate != nil {
+		if err := hooks.Validate(ctx, stagedPath); err != nil {
+			return nil, fmt.Errorf("staged core validation failed: %w", err)
+		}
+	}
+
+	backupPath := binaryPath + ".bak"
+	if err := copyExecutable(binaryPath, backupPath); err != nil {
+		return nil, fmt.Errorf("back up current core: %w", err)
+	}
+	if err := os.Rename(stagedPath, binaryPath); err != nil {
+		return nil, fmt.Errorf("replace core binary: %w", err)
+	}
+
+	hooks.progress("switching", "switching to upgraded core", nil)
+	if hooks.Activate != nil {
+		if err := hooks.Activate(ctx); err != nil {
+			return u.rollback(ctx, result, backupPath, hooks, fmt.Errorf("activate upgraded core: %w", err))
+		}
+	}
+	result.Activated = true
+
+	hooks.progress("health_checking", "checking upgraded core health", nil)
+	if hooks.HealthCheck != nil {
+		if err := hooks.HealthCheck(ctx); err != nil {
+			return u.rollback(ctx, result, backupPath, hooks, fmt.Errorf("post-switch health check failed: %w", err))
+		}
+	}
+	_ = os.Remove(backupPath)
+	return result, nil
+}
+
+// rollback 恢复备份的旧二进制并重新切换；result.RolledBack 只在旧版本重新生效后置位。
+func (u *Upgrader) rollback(ctx context.Context, result *UpgradeResult, backupPath string, hooks UpgradeHooks, cause error) (*UpgradeResult, error) {
+	hooks.progress("rolling_back", "restoring previous core binary", map[string]any{"error": cause.Error()})
+	u.logger.Error("core upgrade failed, restoring previous binary", "core_type", result.CoreType, "version", result.Version, "error", cause)
+	result.Activated = false
+	if err := os.Rename(backupPath, result.BinaryPath); err != nil {
+		return result, fmt.Errorf("%v; restore previous core failed: %w", cause, err)
+	}
+	if hooks.Activate != nil {
+		if err := hooks.Activate(ctx); err != nil {
+			return result, fmt.Errorf("%v; reactivate previous core failed: %w", cause, err)
+		}
+	}
+	result.RolledBack = true
+	return result, cause
+}
+
+func (h UpgradeHooks) progress(phase, message string, payload map[string]any) {
+	if h.Progress != nil {
+		h.Progress(phase, message, payload)
+	}
+}
+
+func renderReleaseURL(template, version string) string {
+	replacer := strings.NewReplacer("{version}", version, "{os}", runtime.GOOS, "{arch}", runtime.GOARCH)
+	return strings.TrimSpace(replacer.Replace(template))
+}
+
+func assetFilename(assetURL string) string {
+	name := assetURL
+	if idx := strings.IndexAny(name, "?#"); idx >= 0 {
+		name = name[:idx]
+	}
+	name = filepath.Base(name)
+	if name == "" || name == "." || name == "/" {
+		return "core-release"
+	}
+	return name
+}
+
+func (u *Upgrader) download(ctx context.Context, rawURL, dst string) error {
+	resp, err := u.get(ctx, rawURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("create staged asset: %w", err)
+	}
+	n, copyErr := io.Copy(out, io.LimitReader(resp.Body, u.cfg.MaxDownloadBytes+1))
+	closeErr := out.Close()
+	if copyErr != nil {
+		return fmt.Errorf("download core release: %w", copyErr)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("close staged asset: %w", closeErr)
+	}
+	if n == 0 {
+		return fmt.Errorf("downloaded core release is empty")
+	}
+	if n > u.cfg.MaxDownloadBytes {
+		return fmt.Errorf("core release exceeds maximum size")
+	}
+	return nil
+}
+
+func (u *Upgrader) fetchExpectedChecksum(ctx context.Context, rawURL, assetName string) (string, error) {
+	resp, err := u.get(ctx, rawURL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	manifest, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("read checksum manifest: %w", err)
+	}
+	checksum := lookupChecksum(string(manifest), assetName)
+	if checksum == "" {
+		return "", fmt.Errorf("checksum for %s not found", assetName)
+	}
+	return checksum, nil
+}
+
+func (u *Upgrader) get(ctx context.Context, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build request %s: %w", rawURL, err)
+	}
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request %s: %w", rawURL, err)
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		resp.Body.Close()
+		return nil, fmt.Errorf("request %s returned status %d", rawURL, resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// lookupChecksum 从 sha256sum 格式清单中按文件名查找哈希；清单只有一个哈希时直接使用。
+func lookupChecksum(manifest, assetName string) string {
+	lines := strings.Split(strings.TrimSpace(manifest), "\n")
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) == 1 && len(lines) == 1 && isSHA256Hex(fields[0]) {
+			return strings.ToLower(fields[0])
+		}
+		if len(fields) < 2 || !isSHA256Hex(fields[0]) {
+			continue
+		}
+		if strings.TrimPrefix(fields[len(fields)-1], "*") == assetName {
+			return strings.ToLower(fields[0])
+		}
+	}
+	return ""
+}
+
+func isSHA256Hex(value string) bool {
+	if len(value) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(value)
+	return err == nil
+}
+
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("open file for checksum: %w", err)
+	}
+	defer file.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", fmt.Errorf("hash file: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// extractCoreBinary 从 .tar.gz/.zip 发布包中取出与 binaryName 同名的文件；其他格式视为裸二进制。
+func extractCoreBinary(assetPath, dst, binaryName string) error {
+	lower := strings.ToLower(assetPath)
+	switch {
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return extractFromTarGz(assetPath, dst, binaryName)
+	case strings.HasSuffix(lower, ".zip"):
+		return extractFromZip(assetPath, dst, binaryName)
+	default:
+		return os.Rename(assetPath, dst)
+	}
+}
+
+func extractFromTarGz(assetPath, dst, binaryName string) error {
+	file, err := os.Open(assetPath)
+	if err != nil {
+		return fmt.Errorf("open core archive: %w", err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("open core archive: %w", err)
+	}
+	defer gz.Close()
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("core archive does not contain %s", binaryName)
+		}
+		if err != nil {
+			return fmt.Errorf("read core archive: %w", err)
+		}
+		if header.Typeflag == tar.TypeReg && filepath.Base(header.Name) == binaryName {
+			return writeStagedFile(dst, reader)
+		}
+	}
+}
+
+func extractFromZip(assetPath, dst, binaryName string) error {
+	archive, err := zip.OpenReader(assetPath)
+	if err != nil {
+		return fmt.Errorf("open core archive: %w", err)
+	}
+	defer archive.Close()
+	for _, entry := range archive.File {
+		if entry.FileInfo().IsDir() || filepath.Base(entry.Name) != binaryName {
+			continue
+		}
+		rc, err := entry.Open()
+		if err != nil {
+			return fmt.Errorf("read core archive: %w", err)
+		}
+		defer rc.Close()
+		return writeStagedFile(dst, rc)
+	}
+	return fmt.Errorf("core archive does not contain %s", binaryName)
+}
+
+func writeStagedFile(dst string, src io.Reader) error {
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o755)
+	if err != nil {
+		return fmt.Errorf("create staged core: %w", err)
+	}
+	_, copyErr := io.Copy(out, io.LimitReader(src, defaultUpgradeMaxDownloadBytes))
+	closeErr := out.Close()
+	if copyErr != nil {
+		return fmt.Errorf("extract staged core: %w", copyErr)
+	}
+	return closeErr
+}
+
+func copyExecutable(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	_, copyErr := io.Copy(out, in)
+	closeErr := out.Close()
+	if copyErr != nil {
+		return copyErr
+	}
+	return closeErr
+}
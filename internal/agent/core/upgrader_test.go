@@ -0,0 +1,117 @@
+package core
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func buildTarGz(t *testing.T, name string, content []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	if err := tw.WriteHeader(&tar.Header{Name: "release/" + name, Mode: 0o755, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatalf("tar header: %v", err)
+	}
+	if _, err := tw.Write(content); err != nil {
+		t.Fatalf("tar write: %v", err)
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func TestUpgraderChecksumAndRollback(t *testing.T) {
+	archive := buildTarGz(t, "sing-box", []byte("new-binary"))
+	sum := sha256.Sum256(archive)
+	checksum := hex.EncodeToString(sum[:])
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1.11.0/sing-box.tar.gz":
+			w.Write(archive)
+		case "/v1.11.0/good.sha256":
+			w.Write([]byte(checksum + "  sing-box.tar.gz\n"))
+		case "/v1.11.0/bad.sha256":
+			w.Write([]byte("0000000000000000000000000000000000000000000000000000000000000000  sing-box.tar.gz\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	setup := func(t *testing.T) string {
+		binaryPath := filepath.Join(t.TempDir(), "sing-box")
+		if err := os.WriteFile(binaryPath, []byte("old-binary"), 0o755); err != nil {
+			t.Fatalf("write binary: %v", err)
+		}
+		return binaryPath
+	}
+	readBinary := func(t *testing.T, path string) string {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read binary: %v", err)
+		}
+		return string(data)
+	}
+
+	t.Run("checksum mismatch keeps old binary", func(t *testing.T) {
+		binaryPath := setup(t)
+		activated := false
+		upgrader := NewUpgrader(UpgraderConfig{BinaryPath: binaryPath, ReleaseURL: server.URL + "/v{version}/sing-box.tar.gz", ChecksumURL: server.URL + "/v{version}/bad.sha256"}, nil)
+		_, err := upgrader.Upgrade(context.Background(), "sing-box", "1.11.0", UpgradeHooks{Activate: func(context.Context) error { activated = true; return nil }})
+		if !errors.Is(err, ErrUpgradeChecksumMismatch) {
+			t.Fatalf("err = %v, want checksum mismatch", err)
+		}
+		if activated || readBinary(t, binaryPath) != "old-binary" {
+			t.Fatal("checksum mismatch must not replace or activate the core")
+		}
+	})
+
+	t.Run("failed health check restores old binary", func(t *testing.T) {
+		binaryPath := setup(t)
+		activations := 0
+		upgrader := NewUpgrader(UpgraderConfig{BinaryPath: binaryPath, ReleaseURL: server.URL + "/v{version}/sing-box.tar.gz", ChecksumURL: server.URL + "/v{version}/good.sha256"}, nil)
+		result, err := upgrader.Upgrade(context.Background(), "sing-box", "v1.11.0", UpgradeHooks{
+			Validate: func(_ context.Context, staged string) error {
+				if readBinary(t, staged) != "new-binary" {
+					t.Fatalf("staged binary not extracted from archive")
+				}
+				return nil
+			},
+			Activate:    func(context.Context) error { activations++; return nil },
+			HealthCheck: func(context.Context) error { return errors.New("service not running") },
+		})
+		if err == nil || result == nil || !result.RolledBack {
+			t.Fatalf("result = %+v, err = %v, want rolled back", result, err)
+		}
+		if activations != 2 || readBinary(t, binaryPath) != "old-binary" {
+			t.Fatalf("activations = %d, binary = %q, want old binary reactivated", activations, readBinary(t, binaryPath))
+		}
+	})
+
+	t.Run("successful upgrade", func(t *testing.T) {
+		binaryPath := setup(t)
+		upgrader := NewUpgrader(UpgraderConfig{BinaryPath: binaryPath, ReleaseURL: server.URL + "/v{version}/sing-box.tar.gz", ChecksumURL: server.URL + "/v{version}/good.sha256"}, nil)
+		result, err := upgrader.Upgrade(context.Background(), "sing-box", "1.11.0", UpgradeHooks{})
+		if err != nil || result.SHA256 != checksum || !result.Activated {
+			t.Fatalf("result = %+v, err = %v", result, err)
+		}
+		if readBinary(t, binaryPath) != "new-binary" {
+			t.Fatal("core binary not replaced")
+		}
+		if _, err := os.Stat(binaryPath + ".bak"); !os.IsNotExist(err) {
+			t.Fatal("backup should be removed after a healthy upgrade")
+		}
+	})
+}
@@ -0,0 +1,271 @@
+package forwarding
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	agentv1 "github.com/creamcroissant/xboard/pkg/pb/agent/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// healthProbeTick 是检查哪些规则到期需要探测的节拍，实际探测间隔由规则自身决定。
+const healthProbeTick = time.Second
+
+// defaultHealthCheckTimeout 是规则未指定超时时单次探测的超时。
+const defaultHealthCheckTimeout = 3 * time.Second
+
+// targetHealth 记录单个目标最近一次探测结果。
+type targetHealth struct {
+	healthy bool
+	err     string
+}
+
+// healthTracker 按规则保存目标健康状态和下次探测时间，只在 Manager.Run 所在的 goroutine 中使用。
+type healthTracker struct {
+	states    map[int64]map[string]targetHealth
+	nextProbe map[int64]time.Time
+}
+
+func newHealthTracker() *healthTracker {
+	return &healthTracker{
+		states:    make(map[int64]map[string]targetHealth),
+		nextProbe: make(map[int64]time.Time),
+	}
+}
+
+// retain 丢弃已不存在或关闭健康检查的规则状态，并让变更过的规则尽快重新探测。
+func (t *healthTracker) retain(rules []*agentv1.ForwardingRule) {
+	keep := make(map[int64]map[string]struct{}, len(rules))
+	for _, rule := range rules {
+		if rule == nil || !rule.Enabled || rule.HealthCheckInterval <= 0 {
+			continue
+		}
+		keys := make(map[string]struct{})
+		for _, target := range effectiveTargets(rule) {
+			keys[targetKey(target)] = struct{}{}
+		}
+		keep[rule.Id] = keys
+	}
+	for ruleID, states := range t.states {
+		keys, ok := keep[ruleID]
+		if !ok {
+			delete(t.states, ruleID)
+			continue
+		}
+		for key := range states {
+			if _, ok := keys[key]; !ok {
+				delete(states, key)
+			}
+		}
+	}
+	for ruleID := range t.nextProbe {
+		if _, ok := keep[ruleID]; !ok {
+			delete(t.nextProbe, ruleID)
+		}
+	}
+}
+
+// update 写入探测结果，状态首次确定或发生翻转时返回 true。
+func (t *healthTracker) update(ruleID int64, key string, result targetHealth) bool {
+	states, ok := t.states[ruleID]
+	if !ok {
+		states = make(map[string]targetHealth)
+		t.states[ruleID] = states
+	}
+	prev, known := states[key]
+	states[key] = result
+	return !known || prev.healthy != result.healthy
+}
+
+func (t *healthTracker) unhealthy(ruleID int64, key string) bool {
+	state, ok := t.states[ruleID][key]
+	return ok && !state.healthy
+}
+
+// effectiveRules 返回剔除不健康目标后的规则；全部目标都不健康时保留原列表，避免规则整体消失。
+func (m *Manager) effectiveRules() []*agentv1.ForwardingRule {
+	result := make([]*agentv1.ForwardingRule, 0, len(m.rules))
+	for _, rule := range m.rules {
+		if rule == nil || rule.HealthCheckInterval <= 0 {
+			result = append(result, rule)
+			continue
+		}
+		targets := effectiveTargets(rule)
+		healthy := make([]*agentv1.ForwardingTarget, 0, len(targets))
+		for _, target := range targets {
+			if !m.health.unhealthy(rule.Id, targetKey(target)) {
+				healthy = append(healthy, target)
+			}
+		}
+		if len(healthy) == 0 || len(healthy) == len(targets) {
+			result = append(result, rule)
+			continue
+		}
+		filtered := proto.Clone(rule).(*agentv1.ForwardingRule)
+		filtered.Targets = healthy
+		result = append(result, filtered)
+	}
+	return result
+}
+
+// probeDue 探测到期规则的全部目标；有状态变化时重新应用 nftables 并上报面板。
+func (m *Manager) probeDue(ctx context.Context) {
+	if !m.available || len(m.rules) == 0 {
+		return
+	}
+
+	type probeJob struct {
+		ruleID   int64
+		target   *agentv1.ForwardingTarget
+		protocol string
+		timeout  time.Duration
+		result   targetHealth
+	}
+
+	now := time.Now()
+	var jobs []*probeJob
+	for _, rule := range m.rules {
+		if rule == nil || !rule.Enabled || rule.HealthCheckInterval <= 0 {
+			continue
+		}
+		if next, ok := m.health.nextProbe[rule.Id]; ok && now.Before(next) {
+			continue
+		}
+		m.health.nextProbe[rule.Id] = now.Add(time.Duration(rule.HealthCheckInterval) * time.Second)
+		timeout := defaultHealthCheckTimeout
+		if rule.HealthCheckTimeout > 0 {
+			timeout = time.Duration(rule.HealthCheckTimeout) * time.Second
+		}
+		for _, target := range effectiveTargets(rule) {
+			jobs = append(jobs, &probeJob{ruleID: rule.Id, target: target, protocol: rule.Protocol, timeout: timeout})
+		}
+	}
+	if len(jobs) == 0 {
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		wg.Add(1)
+		go func(job *probeJob) {
+			defer wg.Done()
+			if err := probeTarget(ctx, job.protocol, job.target, job.timeout); err != nil {
+				job.result = targetHealth{healthy: false, err: err.Error()}
+				return
+			}
+			job.result = targetHealth{healthy: true}
+		}(job)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return
+	}
+
+	checkedAt := time.Now().Unix()
+	var transitions []*agentv1.ForwardingTargetHealth
+	for _, job := range jobs {
+		if !m.health.update(job.ruleID, targetKey(job.target), job.result) {
+			continue
+		}
+		if !job.result.healthy {
+			m.logger.Warn("forwarding target unhealthy", "rule_id", job.ruleID, "target", targetKey(job.target), "error", job.result.err)
+		} else {
+			m.logger.Info("forwarding target healthy", "rule_id", job.ruleID, "target", targetKey(job.target))
+		}
+		transitions = append(transitions, &agentv1.ForwardingTargetHealth{
+			RuleId:    job.ruleID,
+			Address:   job.target.Address,
+			Port:      job.target.Port,
+			Healthy:   job.result.healthy,
+			Error:     job.result.err,
+			CheckedAt: checkedAt,
+		})
+	}
+	if len(transitions) == 0 {
+		return
+	}
+
+	if err := m.executor.Apply(ctx, m.effectiveRules()); err != nil {
+		m.logger.Error("apply forwarding rules after health change failed", "error", err)
+	}
+	if err := m.reportHealth(ctx, transitions); err != nil {
+		m.logger.Error("report forwarding target health failed", "error", err)
+	}
+}
+
+func (m *Manager) reportHealth(ctx context.Context, transitions []*agentv1.ForwardingTargetHealth) error {
+	if m.client == nil {
+		return errors.New("grpc client not set")
+	}
+	report := &agentv1.ForwardingStatusReport{
+		Version:      m.version,
+		Success:      true,
+		AppliedAt:    time.Now().Unix(),
+		TargetHealth: transitions,
+	}
+	_, err := m.client.ReportForwardingStatus(ctx, report)
+	return err
+}
+
+// probeTarget 探测目标可达性。TCP 要求能完成握手；UDP 无应答视为可达，
+// 只有收到 ICMP 端口不可达（connection refused）才判定失败。
+func probeTarget(ctx context.Context, protocol string, target *agentv1.ForwardingTarget, timeout time.Duration) error {
+	addr := net.JoinHostPort(strings.TrimSpace(target.Address), strconv.Itoa(int(target.Port)))
+	probeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	if strings.ToLower(strings.TrimSpace(protocol)) != "udp" {
+		conn, err := dialer.DialContext(probeCtx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+
+	conn, err := dialer.DialContext(probeCtx, "udp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	deadline, _ := probeCtx.Deadline()
+	_ = conn.SetDeadline(deadline)
+	if _, err := conn.Write([]byte{0}); err != nil {
+		return err
+	}
+	buf := make([]byte, 1)
+	if _, err := conn.Read(buf); err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// effectiveTargets 返回规则的目标列表，targets 为空时回退到 target_address/target_port。
+func effectiveTargets(rule *agentv1.ForwardingRule) []*agentv1.ForwardingTarget {
+	if len(rule.Targets) > 0 {
+		targets := make([]*agentv1.ForwardingTarget, 0, len(rule.Targets))
+		for _, target := range rule.Targets {
+			if target != nil {
+				targets = append(targets, target)
+			}
+		}
+		return targets
+	}
+	if strings.TrimSpace(rule.TargetAddress) == "" {
+		return nil
+	}
+	return []*agentv1.ForwardingTarget{{Address: rule.TargetAddress, Port: rule.TargetPort, Weight: 1}}
+}
+
+func targetKey(target *agentv1.ForwardingTarget) string {
+	return net.JoinHostPort(strings.TrimSpace(target.Address), strconv.Itoa(int(target.Port)))
+}
@@ -19,6 +19,8 @@ type Manager struct {
 	logger    *slog.Logger
 	conn      *transport.ConnectionManager
 	available bool
+	rules     []*agentv1.ForwardingRule // 最近一次同步到的规则，健康检查据此重新生成规则集
+	health    *healthTracker
 }
 
 // NewManager 创建转发规则管理器。
@@ -37,6 +39,7 @@ func NewManager(client *transport.GRPCClient, executor *NFTablesExecutor, interv
 		logger:    logger,
 		conn:      transport.NewConnectionManager(client, logger),
 		available: true,
+		health:    newHealthTracker(),
 	}
 
 	if err := executor.CheckAvailability(context.Background()); err != nil {
@@ -47,12 +50,14 @@ func NewManager(client *transport.GRPCClient, executor *NFTablesExecutor, interv
 	return m
 }
 
-// Run 启动转发规则同步循环。
+// Run 启动转发规则同步循环，并按规则配置探测目标健康状态。
 func (m *Manager) Run(ctx context.Context) {
 	m.syncOnce(ctx)
 
 	ticker := time.NewTicker(m.interval)
 	defer ticker.Stop()
+	probeTicker := time.NewTicker(healthProbeTick)
+	defer probeTicker.Stop()
 
 	for {
 		select {
@@ -61,6 +66,8 @@ func (m *Manager) Run(ctx context.Context) {
 			return
 		case <-ticker.C:
 			m.syncOnce(ctx)
+		case <-probeTicker.C:
+			m.probeDue(ctx)
 		}
 	}
 }
@@ -96,7 +103,9 @@ func (m *Manager) syncOnce(ctx context.Context) {
 		return
 	}
 
-	if err := m.executor.Apply(ctx, resp.GetRules()); err != nil {
+	m.rules = resp.GetRules()
+	m.health.retain(m.rules)
+	if err := m.executor.Apply(ctx, m.effectiveRules()); err != nil {
 		reportErr := m.reportStatus(ctx, resp.GetVersion(), false, err.Error())
 		if reportErr != nil {
 			m.logger.Error("report forwarding status failed", "error", reportErr)
@@ -8,6 +8,7 @@ import (
 	"net"
 	"os/exec"
 	"sort"
+	"strconv"
 	"strings"
 
 	agentv1 "github.com/creamcroissant/xboard/pkg/pb/agent/v1"
@@ -152,58 +153,156 @@ func (e *NFTablesExecutor) generateNFTScript(rules []*agentv1.ForwardingRule) (s
 	return b.String(), nil
 }
 
-func buildPreroutingRule(rule *agentv1.ForwardingRule) ([]string, error) {
-	addr := strings.TrimSpace(rule.TargetAddress)
-	if addr == "" {
-		return nil, errors.New("target address is empty")
+// nftTarget 是规则的一个转发目标；单目标规则视为权重为 1 的唯一目标。
+type nftTarget struct {
+	addr   string
+	port   int32
+	weight int32
+}
+
+// ruleTargets 返回规则的有效目标列表，targets 为空时回退到 target_address/target_port。
+func ruleTargets(rule *agentv1.ForwardingRule) ([]nftTarget, error) {
+	if len(rule.Targets) == 0 {
+		addr := strings.TrimSpace(rule.TargetAddress)
+		if addr == "" {
+			return nil, errors.New("target address is empty")
+		}
+		if rule.TargetPort <= 0 {
+			return nil, errors.New("target port is invalid")
+		}
+		return []nftTarget{{addr: addr, port: rule.TargetPort, weight: 1}}, nil
 	}
-	port := rule.TargetPort
-	if port <= 0 {
-		return nil, errors.New("target port is invalid")
+
+	targets := make([]nftTarget, 0, len(rule.Targets))
+	for _, target := range rule.Targets {
+		if target == nil {
+			continue
+		}
+		addr := strings.TrimSpace(target.Address)
+		if addr == "" {
+			return nil, errors.New("target address is empty")
+		}
+		if target.Port <= 0 {
+			return nil, errors.New("target port is invalid")
+		}
+		if target.Weight <= 0 {
+			return nil, fmt.Errorf("target weight is invalid: %s:%d", addr, target.Port)
+		}
+		targets = append(targets, nftTarget{addr: addr, port: target.Port, weight: target.Weight})
 	}
-	listen := rule.ListenPort
-	if listen <= 0 {
-		return nil, errors.New("listen port is invalid")
+	if len(targets) == 0 {
+		return nil, errors.New("no forwarding targets")
 	}
+	return targets, nil
+}
 
+// ruleProtocols 将 tcp/udp/both 展开为 nftables 的 L4 协议列表。
+func ruleProtocols(rule *agentv1.ForwardingRule) ([]string, error) {
 	switch strings.ToLower(strings.TrimSpace(rule.Protocol)) {
 	case "tcp":
-		return []string{fmt.Sprintf("%stcp dport %d dnat to %s:%d", nfprotoPrefix(addr), listen, addr, port)}, nil
+		return []string{"tcp"}, nil
 	case "udp":
-		return []string{fmt.Sprintf("%sudp dport %d dnat to %s:%d", nfprotoPrefix(addr), listen, addr, port)}, nil
+		return []string{"udp"}, nil
 	case "both":
-		return []string{
-			fmt.Sprintf("%stcp dport %d dnat to %s:%d", nfprotoPrefix(addr), listen, addr, port),
-			fmt.Sprintf("%sudp dport %d dnat to %s:%d", nfprotoPrefix(addr), listen, addr, port),
-		}, nil
+		return []string{"tcp", "udp"}, nil
 	default:
 		return nil, fmt.Errorf("unsupported protocol: %s", rule.Protocol)
 	}
 }
 
+// groupTargetsByFamily 按地址族分组（IPv4 在前），同一条 dnat 只能指向同族地址。
+func groupTargetsByFamily(targets []nftTarget) [][]nftTarget {
+	var v4, v6 []nftTarget
+	for _, target := range targets {
+		if isIPv6(target.addr) {
+			v6 = append(v6, target)
+		} else {
+			v4 = append(v4, target)
+		}
+	}
+	groups := make([][]nftTarget, 0, 2)
+	for _, group := range [][]nftTarget{v4, v6} {
+		if len(group) > 0 {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+func buildPreroutingRule(rule *agentv1.ForwardingRule) ([]string, error) {
+	targets, err := ruleTargets(rule)
+	if err != nil {
+		return nil, err
+	}
+	listen := rule.ListenPort
+	if listen <= 0 {
+		return nil, errors.New("listen port is invalid")
+	}
+	protocols, err := ruleProtocols(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	for _, group := range groupTargetsByFamily(targets) {
+		for _, proto := range protocols {
+			lines = append(lines, fmt.Sprintf("%s%s dport %d %s", nfprotoPrefix(group[0].addr), proto, listen, dnatStatement(group)))
+		}
+	}
+	return lines, nil
+}
+
+// dnatStatement 生成 dnat 语句：单目标直接 dnat，多目标用 numgen inc 按权重切分区间实现加权轮询。
+func dnatStatement(targets []nftTarget) string {
+	if len(targets) == 1 {
+		addr := targets[0].addr
+		if isIPv6(addr) {
+			addr = "[" + addr + "]"
+		}
+		return fmt.Sprintf("dnat to %s:%d", addr, targets[0].port)
+	}
+
+	family := "ip"
+	if isIPv6(targets[0].addr) {
+		family = "ip6"
+	}
+	var total int64
+	entries := make([]string, 0, len(targets))
+	for _, target := range targets {
+		from := total
+		total += int64(target.weight)
+		slot := strconv.FormatInt(from, 10)
+		if total-1 > from {
+			slot = fmt.Sprintf("%d-%d", from, total-1)
+		}
+		entries = append(entries, fmt.Sprintf("%s : %s . %d", slot, target.addr, target.port))
+	}
+	return fmt.Sprintf("dnat %s to numgen inc mod %d map { %s }", family, total, strings.Join(entries, ", "))
+}
+
 func buildPostroutingRule(rule *agentv1.ForwardingRule) ([]string, error) {
-	addr := strings.TrimSpace(rule.TargetAddress)
-	if addr == "" {
-		return nil, errors.New("target address is empty")
+	targets, err := ruleTargets(rule)
+	if err != nil {
+		return nil, err
 	}
-	port := rule.TargetPort
-	if port <= 0 {
-		return nil, errors.New("target port is invalid")
+	protocols, err := ruleProtocols(rule)
+	if err != nil {
+		return nil, err
 	}
 
-	switch strings.ToLower(strings.TrimSpace(rule.Protocol)) {
-	case "tcp":
-		return []string{fmt.Sprintf("%s%stcp dport %d masquerade", nfprotoPrefix(addr), nfprotoAddrLabel(addr), port)}, nil
-	case "udp":
-		return []string{fmt.Sprintf("%s%sudp dport %d masquerade", nfprotoPrefix(addr), nfprotoAddrLabel(addr), port)}, nil
-	case "both":
-		return []string{
-			fmt.Sprintf("%s%stcp dport %d masquerade", nfprotoPrefix(addr), nfprotoAddrLabel(addr), port),
-			fmt.Sprintf("%s%sudp dport %d masquerade", nfprotoPrefix(addr), nfprotoAddrLabel(addr), port),
-		}, nil
-	default:
-		return nil, fmt.Errorf("unsupported protocol: %s", rule.Protocol)
+	var lines []string
+	seen := make(map[string]struct{}, len(targets))
+	for _, target := range targets {
+		key := fmt.Sprintf("%s|%d", target.addr, target.port)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		for _, proto := range protocols {
+			lines = append(lines, fmt.Sprintf("%s%s%s dport %d masquerade", nfprotoPrefix(target.addr), nfprotoAddrLabel(target.addr), proto, target.port))
+		}
 	}
+	return lines, nil
 }
 
 func nfprotoPrefix(addr string) string {
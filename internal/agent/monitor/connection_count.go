@@ -0,0 +1,132 @@
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// 连接数来源。Xray 的 StatsService 只提供流量计数器、没有活跃连接数，Xray 节点走 /proc/net 回退。
+const (
+	ConnectionSourceClashAPI = "clash_api"
+	ConnectionSourceProcNet  = "proc_net"
+)
+
+// ConnectionCount 是一次连接数采集结果，Source 为空表示当前节点无法提供连接数，此时 Count 恒为 0。
+type ConnectionCount struct {
+	Count  int
+	Source string
+}
+
+// ConnectionCounter 依次尝试 sing-box Clash API 与 /proc/net 统计活跃连接数。
+type ConnectionCounter struct {
+	clashAPI    string
+	clashSecret string
+	procRoot    string
+	client      *http.Client
+}
+
+// NewConnectionCounter 创建连接数统计器；clashAPI 为空时跳过 Clash API，直接解析 /proc/net。
+func NewConnectionCounter(clashAPI, clashSecret string) *ConnectionCounter {
+	return &ConnectionCounter{
+		clashAPI:    strings.TrimRight(strings.TrimSpace(clashAPI), "/"),
+		clashSecret: clashSecret,
+		procRoot:    "/proc",
+		client:      &http.Client{Timeout: 3 * time.Second},
+	}
+}
+
+// Count 返回当前活跃连接数，所有来源都不可用时返回零值并由 Source 为空标记不可用。
+func (c *ConnectionCounter) Count(ctx context.Context) (ConnectionCount, error) {
+	var errs []string
+	if c.clashAPI != "" {
+		count, err := c.countClashAPI(ctx)
+		if err == nil {
+			return ConnectionCount{Count: count, Source: ConnectionSourceClashAPI}, nil
+		}
+		errs = append(errs, err.Error())
+	}
+	count, err := countProcNetConnections(c.procRoot)
+	if err == nil {
+		return ConnectionCount{Count: count, Source: ConnectionSourceProcNet}, nil
+	}
+	errs = append(errs, err.Error())
+	return ConnectionCount{}, fmt.Errorf("connection count unavailable: %s", strings.Join(errs, "; "))
+}
+
+// countClashAPI 读取 Clash API 的 /connections，返回当前连接列表长度。
+func (c *ConnectionCounter) countClashAPI(ctx context.Context) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.clashAPI+"/connections", nil)
+	if err != nil {
+		return 0, fmt.Errorf("build clash api request: %w", err)
+	}
+	if c.clashSecret != "" {
+		req.Header.Set("Authorization", "Bearer "+c.clashSecret)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("query clash api: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("query clash api: unexpected status %d", resp.StatusCode)
+	}
+	var payload struct {
+		Connections []json.RawMessage `json:"connections"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return 0, fmt.Errorf("decode clash api connections: %w", err)
+	}
+	return len(payload.Connections), nil
+}
+
+// countProcNetConnections 统计 /proc/net 中已建立的 TCP 连接与已 connect 的 UDP 套接字（状态 01）。
+func countProcNetConnections(procRoot string) (int, error) {
+	total := 0
+	found := false
+	for _, name := range []string{"tcp", "tcp6", "udp", "udp6"} {
+		count, err := countProcNetEstablished(filepath.Join(procRoot, "net", name))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return 0, err
+		}
+		found = true
+		total += count
+	}
+	if !found {
+		return 0, fmt.Errorf("no /proc/net socket tables under %s", procRoot)
+	}
+	return total, nil
+}
+
+func countProcNetEstablished(path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	count := 0
+	scanner := bufio.NewScanner(file)
+	// 跳过表头
+	scanner.Scan()
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// sl local_address rem_address st ...
+		if len(fields) > 3 && fields[3] == "01" {
+			count++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("read %s: %w", path, err)
+	}
+	return count, nil
+}
@@ -0,0 +1,54 @@
+package monitor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConnectionCounterSources(t *testing.T) {
+	procRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(procRoot, "net"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	tcp := "  sl  local_address rem_address   st tx_queue rx_queue\n" +
+		"   0: 00000000:01BB 00000000:0000 0A 00000000:00000000\n" +
+		"   1: 0100007F:01BB 0100007F:D431 01 00000000:00000000\n" +
+		"   2: 0100007F:01BB 0100007F:D432 01 00000000:00000000\n"
+	udp := "  sl  local_address rem_address   st tx_queue rx_queue\n" +
+		"   0: 00000000:0035 00000000:0000 07 00000000:00000000\n" +
+		"   1: 0100007F:9C40 08080808:0035 01 00000000:00000000\n"
+	os.WriteFile(filepath.Join(procRoot, "net", "tcp"), []byte(tcp), 0o644)
+	os.WriteFile(filepath.Join(procRoot, "net", "udp"), []byte(udp), 0o644)
+
+	clash := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/connections" || r.Header.Get("Authorization") != "Bearer s3cret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"downloadTotal":1,"connections":[{"id":"a"},{"id":"b"},{"id":"c"},{"id":"d"}]}`))
+	}))
+	defer clash.Close()
+
+	tests := []struct {
+		name    string
+		counter *ConnectionCounter
+		want    ConnectionCount
+		wantErr bool
+	}{
+		{name: "clash api", counter: &ConnectionCounter{clashAPI: clash.URL, clashSecret: "s3cret", procRoot: procRoot, client: clash.Client()}, want: ConnectionCount{Count: 4, Source: ConnectionSourceClashAPI}},
+		{name: "clash api failure falls back to proc", counter: &ConnectionCounter{clashAPI: clash.URL, procRoot: procRoot, client: clash.Client()}, want: ConnectionCount{Count: 3, Source: ConnectionSourceProcNet}},
+		{name: "unavailable", counter: &ConnectionCounter{procRoot: t.TempDir()}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.counter.Count(context.Background())
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Fatalf("Count() = %+v, %v; want %+v, err %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
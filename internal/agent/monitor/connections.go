@@ -0,0 +1,34 @@
+package monitor
+
+import (
+	"context"
+
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+// CountEstablishedTCP 统计本地端口属于 ports 的已建立 TCP 连接数。
+func CountEstablishedTCP(ctx context.Context, ports []int) (int, error) {
+	if len(ports) == 0 {
+		return 0, nil
+	}
+	wanted := make(map[uint32]struct{}, len(ports))
+	for _, port := range ports {
+		if port > 0 {
+			wanted[uint32(port)] = struct{}{}
+		}
+	}
+	conns, err := net.ConnectionsWithContext(ctx, "tcp")
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, conn := range conns {
+		if conn.Status != "ESTABLISHED" {
+			continue
+		}
+		if _, ok := wanted[conn.Laddr.Port]; ok {
+			count++
+		}
+	}
+	return count, nil
+}
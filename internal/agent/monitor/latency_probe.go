@@ -0,0 +1,63 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// latencyProbeAttempts 为每次探测的 TCP 建连次数，取最小值以减少偶发抖动。
+const latencyProbeAttempts = 3
+
+// LatencyProber 通过 TCP 建连耗时衡量节点到探测目标的延迟。
+type LatencyProber struct {
+	target  string
+	timeout time.Duration
+	dial    func(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// NewLatencyProber 创建延迟探测器；target 为 host:port，为空时返回 nil 表示不探测。
+func NewLatencyProber(target string, timeout time.Duration) *LatencyProber {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return nil
+	}
+	if timeout <= 0 {
+		timeout = 3 * time.Second
+	}
+	dialer := &net.Dialer{}
+	return &LatencyProber{target: target, timeout: timeout, dial: dialer.DialContext}
+}
+
+// Probe 返回多次建连中的最小耗时（毫秒，至少为 1）；全部失败时返回错误。
+func (p *LatencyProber) Probe(ctx context.Context) (int64, error) {
+	var (
+		best    time.Duration
+		lastErr error
+	)
+	for i := 0; i < latencyProbeAttempts; i++ {
+		dialCtx, cancel := context.WithTimeout(ctx, p.timeout)
+		start := time.Now()
+		conn, err := p.dial(dialCtx, "tcp", p.target)
+		elapsed := time.Since(start)
+		cancel()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		conn.Close()
+		if best == 0 || elapsed < best {
+			best = elapsed
+		}
+	}
+	if best == 0 {
+		return 0, fmt.Errorf("probe %s: %w", p.target, lastErr)
+	}
+	ms := best.Milliseconds()
+	if ms < 1 {
+		ms = 1
+	}
+	return ms, nil
+}
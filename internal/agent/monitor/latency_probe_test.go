@@ -0,0 +1,40 @@
+package monitor
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestLatencyProber(t *testing.T) {
+	if NewLatencyProber("  ", 0) != nil {
+		t.Fatal("expected empty target to disable the probe")
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	latency, err := NewLatencyProber(listener.Addr().String(), time.Second).Probe(context.Background())
+	if err != nil || latency < 1 {
+		t.Fatalf("Probe() = (%d, %v), want positive latency", latency, err)
+	}
+
+	closed := listener.Addr().String()
+	listener.Close()
+	if _, err := NewLatencyProber(closed, 200*time.Millisecond).Probe(context.Background()); err == nil {
+		t.Fatal("expected probe against a closed port to fail")
+	}
+}
@@ -28,6 +28,7 @@ type Monitor struct {
 	fetcher     SystemStatFetcher
 	lastNetStat []net.IOCountersStat
 	lastTime    time.Time
+	watchPaths  []string
 }
 
 func New() *Monitor {
@@ -120,6 +121,9 @@ func (m *Monitor) Collect() (api.StatusPayload, error) {
 		stat.ProcessCount = len(pids)
 	}
 
+	// Watched paths
+	stat.PathUsage = m.collectPathUsage()
+
 	// TODO: TCP/UDP Count (requires traversing /proc/net/tcp or similar)
 
 	return stat, nil
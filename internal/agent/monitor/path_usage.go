@@ -0,0 +1,98 @@
+package monitor
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/creamcroissant/xboard/internal/agent/api"
+)
+
+// maxPathUsageEntries 限制单个目录遍历的文件数，避免日志目录过大时拖慢上报。
+const maxPathUsageEntries = 20000
+
+var errPathUsageLimit = errors.New("path usage entry limit reached")
+
+// SetWatchPaths sets the directories whose disk usage is reported.
+func (m *Monitor) SetWatchPaths(paths []string) {
+	watch := make([]string, 0, len(paths))
+	seen := make(map[string]struct{}, len(paths))
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		if _, ok := seen[path]; ok {
+			continue
+		}
+		seen[path] = struct{}{}
+		watch = append(watch, path)
+	}
+	m.watchPaths = watch
+}
+
+// collectPathUsage 统计监控目录自身占用与所在文件系统容量。
+// 路径不存在时上报零值而非错误，便于面板区分"未创建"与"采集失败"。
+func (m *Monitor) collectPathUsage() []api.PathUsage {
+	if len(m.watchPaths) == 0 {
+		return nil
+	}
+	usages := make([]api.PathUsage, 0, len(m.watchPaths))
+	for _, path := range m.watchPaths {
+		usage := api.PathUsage{Path: path}
+		info, err := os.Stat(path)
+		if err != nil {
+			usages = append(usages, usage)
+			continue
+		}
+		usage.Exists = true
+		if info.IsDir() {
+			usage.UsedBytes, usage.Truncated = dirSize(path)
+		} else {
+			usage.UsedBytes = uint64(info.Size())
+		}
+		if m.fetcher.DiskUsage != nil {
+			if d, err := m.fetcher.DiskUsage(path); err == nil {
+				usage.FSTotal = d.Total
+				usage.FSUsed = d.Used
+				usage.FSFree = d.Free
+			}
+		}
+		usages = append(usages, usage)
+	}
+	return usages
+}
+
+// dirSize 累加目录下普通文件大小，不跟随符号链接；超过遍历上限时返回已统计部分并标记截断。
+func dirSize(root string) (uint64, bool) {
+	var (
+		total   uint64
+		entries int
+	)
+	err := filepath.WalkDir(root, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// 无权限或遍历期间被删除的条目直接跳过
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		entries++
+		if entries > maxPathUsageEntries {
+			return errPathUsageLimit
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			total += uint64(info.Size())
+		}
+		return nil
+	})
+	return total, errors.Is(err, errPathUsageLimit)
+}
@@ -0,0 +1,245 @@
+package protocol
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/agent/monitor"
+)
+
+const (
+	// defaultDrainTimeout 是重启前等待已有连接结束的默认时长。
+	defaultDrainTimeout = 30 * time.Second
+	// drainPollInterval 是排空期间统计活跃连接的间隔。
+	drainPollInterval = 500 * time.Millisecond
+)
+
+// 重载方式
+const (
+	DrainModeNone     = "none"     // service_action=none，未触发重载
+	DrainModeReload   = "reload"   // 核心原地重载（SIGHUP），连接不中断
+	DrainModeRestart  = "restart"  // 等待连接排空后重启服务
+	DrainModeSwitcher = "switcher" // 交由 proxy.Switcher 启动新实例并排空旧实例
+)
+
+// DrainResult 描述一次重载的排空结果。
+// 原地重载不会中断连接，因此 reload 模式只记录 ConnectionsBefore，Drained 与 Remaining 为 0。
+type DrainResult struct {
+	Mode              string
+	ConnectionsBefore int // 开始重载时的活跃连接数
+	Drained           int // 在重启前自然结束（或交由旧实例继续服务）的连接数
+	Remaining         int // 排空超时后仍被重启中断的连接数
+	FinishedAt        int64
+}
+
+// DrainRestartRequest 描述一次交给 DrainRestarter 的重启：新配置及其监听的外部端口。
+type DrainRestartRequest struct {
+	CoreType    string
+	ConfigJSON  []byte
+	ListenPorts []int
+}
+
+// DrainRestarter 以新配置执行保持端口的重启并排空旧连接，由 proxy.Switcher 实现。
+// handled 为 false 表示这些端口不由其管理，调用方应回退到重启 service_name。
+type DrainRestarter interface {
+	RestartWithDrain(ctx context.Context, req DrainRestartRequest, timeout time.Duration) (drained int, handled bool, err error)
+}
+
+// drainState 保存排空相关的可选依赖与最近一次结果。
+type drainState struct {
+	mu        sync.Mutex
+	restarter DrainRestarter
+	last      *DrainResult
+}
+
+// SetDrainRestarter 设置端口保持重启的实现；传 nil 表示只使用本地排空。
+func (m *Manager) SetDrainRestarter(r DrainRestarter) {
+	m.drain.mu.Lock()
+	defer m.drain.mu.Unlock()
+	m.drain.restarter = r
+}
+
+// TakeDrainResult 返回并清除最近一次重载的排空结果，用于随状态上报发送一次。
+func (m *Manager) TakeDrainResult() *DrainResult {
+	m.drain.mu.Lock()
+	defer m.drain.mu.Unlock()
+	result := m.drain.last
+	m.drain.last = nil
+	return result
+}
+
+// ReloadWithDrain 校验当前配置后重载服务且尽量不中断已有连接：
+// 核心支持 SIGHUP 式重载时原地重载；否则优先交由 proxy.Switcher 做端口保持的重启，
+// 再否则等待监听端口上的连接结束（最长 drain_timeout）后重启。
+func (m *Manager) ReloadWithDrain(ctx context.Context) (DrainResult, error) {
+	validateDir, err := m.dirForSource("current")
+	if err != nil {
+		return DrainResult{}, err
+	}
+	if err := m.ValidateConfigInDir(ctx, validateDir); err != nil {
+		return DrainResult{}, fmt.Errorf("config validation failed: %w", err)
+	}
+	action, err := normalizeServiceAction(m.cfg)
+	if err != nil {
+		return DrainResult{}, err
+	}
+	return m.reloadWithDrain(ctx, action, validateDir, true)
+}
+
+// RestartWithDrain 校验当前配置后强制重启服务并排空旧连接，不走原地重载；
+// 用于替换核心二进制后让新版本生效。
+func (m *Manager) RestartWithDrain(ctx context.Context) (DrainResult, error) {
+	validateDir, err := m.dirForSource("current")
+	if err != nil {
+		return DrainResult{}, err
+	}
+	if err := m.ValidateConfigInDir(ctx, validateDir); err != nil {
+		return DrainResult{}, fmt.Errorf("config validation failed: %w", err)
+	}
+	return m.reloadWithDrain(ctx, RestartServiceAction, validateDir, true)
+}
+
+// reloadWithDrain 按 service_action 执行重载并记录排空结果。
+// drain 为 false 时（常规用户注入）重启前不等待连接结束，避免每次同步都阻塞 drain_timeout。
+func (m *Manager) reloadWithDrain(ctx context.Context, action ServiceAction, configDir string, drain bool) (DrainResult, error) {
+	result := DrainResult{Mode: DrainModeNone}
+	defer func() {
+		if result.Mode == DrainModeNone {
+			return
+		}
+		result.FinishedAt = time.Now().Unix()
+		m.drain.mu.Lock()
+		saved := result
+		m.drain.last = &saved
+		m.drain.mu.Unlock()
+	}()
+
+	switch action {
+	case NoneServiceAction:
+		return result, nil
+	case ReloadServiceAction:
+		if m.supportsInPlaceReload() {
+			result.Mode = DrainModeReload
+			result.ConnectionsBefore, _ = m.countActiveConnections(ctx)
+			if err := m.init.Reload(ctx, m.cfg.ServiceName); err != nil {
+				return result, fmt.Errorf("reload service: %w", err)
+			}
+			return result, nil
+		}
+	case RestartServiceAction:
+	default:
+		return result, fmt.Errorf("invalid service_action %q", action)
+	}
+
+	m.drain.mu.Lock()
+	restarter := m.drain.restarter
+	m.drain.mu.Unlock()
+	// Switcher 在后台排空旧实例，不阻塞本次应用，因此常规同步也可以走这条路径
+	if req, ok := m.drainRestartRequest(configDir); ok && restarter != nil {
+		drained, handled, err := restarter.RestartWithDrain(ctx, req, m.cfg.DrainTimeout)
+		if handled {
+			result.Mode = DrainModeSwitcher
+			result.ConnectionsBefore = drained
+			result.Drained = drained
+			if err != nil {
+				return result, fmt.Errorf("restart with drain: %w", err)
+			}
+			return result, nil
+		}
+	}
+
+	result.Mode = DrainModeRestart
+	if drain {
+		result.ConnectionsBefore, result.Remaining = m.waitForDrain(ctx)
+	} else {
+		result.ConnectionsBefore, _ = m.countActiveConnections(ctx)
+		result.Remaining = result.ConnectionsBefore
+	}
+	result.Drained = result.ConnectionsBefore - result.Remaining
+	if err := m.init.Restart(ctx, m.cfg.ServiceName); err != nil {
+		return result, fmt.Errorf("restart service: %w", err)
+	}
+	return result, nil
+}
+
+// drainRestartRequest 读取刚生效的配置与其监听端口，供 DrainRestarter 判断是否由其接管；
+// 读取失败时返回 false，直接重启 service_name。
+func (m *Manager) drainRestartRequest(configDir string) (DrainRestartRequest, bool) {
+	if configDir == "" {
+		return DrainRestartRequest{}, false
+	}
+	content, err := os.ReadFile(filepath.Join(configDir, m.cfg.MergeOutputFile))
+	if err != nil || len(content) == 0 {
+		return DrainRestartRequest{}, false
+	}
+	ports, err := m.listenPorts()
+	if err != nil || len(ports) == 0 {
+		return DrainRestartRequest{}, false
+	}
+	return DrainRestartRequest{CoreType: detectCoreTypeFromContent(content), ConfigJSON: content, ListenPorts: ports}, true
+}
+
+// supportsInPlaceReload 判断核心能否在不断开连接的情况下重新加载配置；xray 不支持 SIGHUP 重载。
+func (m *Manager) supportsInPlaceReload() bool {
+	return m.DetectCoreType() != "xray"
+}
+
+// waitForDrain 轮询监听端口上的活跃连接，直到归零或超过 drain_timeout，返回起始与剩余连接数。
+func (m *Manager) waitForDrain(ctx context.Context) (before, remaining int) {
+	before, err := m.countActiveConnections(ctx)
+	if err != nil || before == 0 || m.cfg.DrainTimeout <= 0 {
+		return before, before
+	}
+	remaining = before
+	deadline := time.NewTimer(m.cfg.DrainTimeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return before, remaining
+		case <-deadline.C:
+			return before, remaining
+		case <-ticker.C:
+			count, err := m.countActiveConnections(ctx)
+			if err != nil {
+				return before, remaining
+			}
+			remaining = count
+			if remaining == 0 {
+				return before, 0
+			}
+		}
+	}
+}
+
+// countActiveConnections 统计当前配置中入站监听端口上已建立的 TCP 连接数。
+func (m *Manager) countActiveConnections(ctx context.Context) (int, error) {
+	ports, err := m.listenPorts()
+	if err != nil {
+		return 0, err
+	}
+	return monitor.CountEstablishedTCP(ctx, ports)
+}
+
+// listenPorts 返回当前配置中入站监听的端口。
+func (m *Manager) listenPorts() ([]int, error) {
+	configs, err := m.ListConfigsWithDetails()
+	if err != nil {
+		return nil, err
+	}
+	var ports []int
+	for _, cfg := range configs {
+		for _, p := range cfg.Protocols {
+			if p.Port > 0 {
+				ports = append(ports, p.Port)
+			}
+		}
+	}
+	return ports, nil
+}
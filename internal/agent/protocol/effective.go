@@ -0,0 +1,92 @@
+package protocol
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// EffectiveConfig 描述核心实际加载的合并后配置。
+type EffectiveConfig struct {
+	CoreType string         `json:"core_type"`
+	Dir      string         `json:"dir"`
+	Sources  []string       `json:"sources"`
+	Config   map[string]any `json:"config"`
+}
+
+// EffectiveConfig 读取核心当前实际加载的配置（用户注入之后的磁盘内容）。
+// xray 以单文件运行，直接读取 merged 输出文件；sing-box 以目录模式运行，
+// 按文件名顺序合并目录下全部 JSON：对象逐层合并、数组追加，与 sing-box -C 的行为一致。
+func (m *Manager) EffectiveConfig(coreType string) (*EffectiveConfig, error) {
+	coreType = normalizeCoreType(coreType)
+	if coreType == "" {
+		coreType = "sing-box"
+	}
+	dir, err := m.dirForCore(coreType)
+	if err != nil {
+		return nil, err
+	}
+	result := &EffectiveConfig{CoreType: coreType, Dir: dir, Sources: []string{}, Config: map[string]any{}}
+
+	if coreType == "xray" {
+		path := filepath.Join(dir, m.cfg.MergeOutputFile)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read merged config %s: %w", path, err)
+		}
+		if err := json.Unmarshal(content, &result.Config); err != nil {
+			return nil, fmt.Errorf("parse merged config %s: %w", path, err)
+		}
+		result.Sources = append(result.Sources, m.cfg.MergeOutputFile)
+		return result, nil
+	}
+
+	files, err := m.listConfigFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read config %s: %w", file, err)
+		}
+		var document map[string]any
+		if err := json.Unmarshal(content, &document); err != nil {
+			return nil, fmt.Errorf("parse config %s: %w", file, err)
+		}
+		result.Config = mergeConfigDocuments(result.Config, document)
+		result.Sources = append(result.Sources, filepath.Base(file))
+	}
+	return result, nil
+}
+
+// mergeConfigDocuments 将 overlay 合并进 base：对象递归合并，数组追加，其余类型以 overlay 为准。
+func mergeConfigDocuments(base, overlay map[string]any) map[string]any {
+	if base == nil {
+		base = map[string]any{}
+	}
+	for key, value := range overlay {
+		existing, ok := base[key]
+		if !ok {
+			base[key] = value
+			continue
+		}
+		switch next := value.(type) {
+		case map[string]any:
+			if current, ok := existing.(map[string]any); ok {
+				base[key] = mergeConfigDocuments(current, next)
+				continue
+			}
+		case []any:
+			if current, ok := existing.([]any); ok {
+				base[key] = append(current, next...)
+				continue
+			}
+		}
+		base[key] = value
+	}
+	return base
+}
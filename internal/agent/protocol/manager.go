@@ -19,6 +19,7 @@ import (
 
 	"github.com/creamcroissant/xboard/internal/agent/initsys"
 	"github.com/creamcroissant/xboard/internal/agent/protocol/parser"
+	"github.com/creamcroissant/xboard/internal/support/hash"
 )
 
 // Config 定义协议管理器的配置。
@@ -52,6 +53,18 @@ type Config struct {
 
 	// PostHook 是应用配置后执行的钩子命令
 	PostHook string `yaml:"post_hook"`
+
+	// SnapshotKeep 是每个核心保留的历史配置快照数量，0 表示使用默认值。
+	SnapshotKeep int `yaml:"snapshot_keep"`
+
+	// ApplyGracePeriod 是应用后观察服务是否存活的宽限期，0 使用默认值，负数关闭检查。
+	ApplyGracePeriod time.Duration `yaml:"apply_grace_period"`
+
+	// HealthCheckCmd 是宽限期结束后执行的健康探测命令（可选），失败会触发回滚。
+	HealthCheckCmd string `yaml:"health_check_cmd"`
+
+	// DrainTimeout 是无法原地重载时，重启前等待已有连接结束的最长时间。
+	DrainTimeout time.Duration `yaml:"drain_timeout"`
 }
 
 // DefaultConfig 返回默认配置。
@@ -65,6 +78,9 @@ func DefaultConfig() Config {
 		ValidateCmd:      "",
 		ServiceAction:    string(ReloadServiceAction),
 		AutoRestart:      true,
+		SnapshotKeep:     defaultSnapshotKeep,
+		ApplyGracePeriod: defaultApplyGracePeriod,
+		DrainTimeout:     defaultDrainTimeout,
 	}
 }
 
@@ -83,6 +99,7 @@ type Manager struct {
 	init     initsys.InitSystem
 	registry *parser.Registry
 	applyMu  sync.Mutex
+	drain    drainState
 }
 
 // NewManager 创建协议管理器实例。
@@ -112,6 +129,16 @@ func NewManager(cfg Config, initSys initsys.InitSystem) *Manager {
 		cfg.MergeOutputFile = "config.json"
 	}
 	cfg.MergeOutputFile = strings.TrimSpace(cfg.MergeOutputFile)
+	if cfg.SnapshotKeep <= 0 {
+		cfg.SnapshotKeep = defaultSnapshotKeep
+	}
+	if cfg.ApplyGracePeriod == 0 {
+		cfg.ApplyGracePeriod = defaultApplyGracePeriod
+	}
+	cfg.HealthCheckCmd = strings.TrimSpace(cfg.HealthCheckCmd)
+	if cfg.DrainTimeout == 0 {
+		cfg.DrainTimeout = defaultDrainTimeout
+	}
 	return &Manager{
 		cfg:      cfg,
 		init:     initSys,
@@ -124,6 +151,11 @@ func (m *Manager) InitSystemType() string {
 	return m.init.Type()
 }
 
+// CurrentConfigDir 返回当前生效配置所在目录。
+func (m *Manager) CurrentConfigDir() (string, error) {
+	return m.dirForSource("current")
+}
+
 // ListConfigs 返回所有协议配置文件。
 func (m *Manager) ListConfigs() ([]ConfigFile, error) {
 	dir, err := m.dirForSource("current")
@@ -273,15 +305,16 @@ func (m *Manager) ReloadService(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
-	return m.reloadServiceWithValidationDir(ctx, validateDir)
+	return m.reloadServiceWithValidationDir(ctx, validateDir, true)
 }
 
 // ReloadServiceWithValidationDir 在指定目录完成校验后重载或重启服务。
 func (m *Manager) ReloadServiceWithValidationDir(ctx context.Context, dir string) error {
-	return m.reloadServiceWithValidationDir(ctx, dir)
+	return m.reloadServiceWithValidationDir(ctx, dir, true)
 }
 
-func (m *Manager) reloadServiceWithValidationDir(ctx context.Context, validateDir string) error {
+// reloadServiceWithValidationDir 校验后重载服务；drain 为 false 时重启不等待连接排空。
+func (m *Manager) reloadServiceWithValidationDir(ctx context.Context, validateDir string, drain bool) error {
 	action, err := normalizeServiceAction(m.cfg)
 	if err != nil {
 		return err
@@ -298,20 +331,8 @@ func (m *Manager) reloadServiceWithValidationDir(ctx context.Context, validateDi
 		return fmt.Errorf("config validation failed: %w", err)
 	}
 
-	switch action {
-	case ReloadServiceAction:
-		if err := m.init.Reload(ctx, m.cfg.ServiceName); err != nil {
-			return fmt.Errorf("reload service: %w", err)
-		}
-	case RestartServiceAction:
-		if err := m.init.Restart(ctx, m.cfg.ServiceName); err != nil {
-			return fmt.Errorf("restart service: %w", err)
-		}
-	case NoneServiceAction:
-		// no-op
-		return m.runPostHook(ctx)
-	default:
-		return fmt.Errorf("invalid service_action %q", action)
+	if _, err := m.reloadWithDrain(ctx, action, validateDir, drain); err != nil {
+		return err
 	}
 
 	return m.runPostHook(ctx)
@@ -436,6 +457,15 @@ func patchFilenameForCore(_ Config, _ string, filename string) (string, error) {
 }
 
 func (m *Manager) applyPatchWithCore(ctx context.Context, coreType, filename string, content []byte) error {
+	return m.applyPatch(ctx, coreType, filename, content, false)
+}
+
+// applyUserPatch 写入用户注入后的配置；用户同步是常规操作，重启时不等待连接排空。
+func (m *Manager) applyUserPatch(ctx context.Context, filename string, content []byte) error {
+	return m.applyPatch(ctx, "", filename, content, true)
+}
+
+func (m *Manager) applyPatch(ctx context.Context, coreType, filename string, content []byte, skipDrain bool) error {
 	if err := m.validateServiceAction(); err != nil {
 		return err
 	}
@@ -452,8 +482,9 @@ func (m *Manager) applyPatchWithCore(ctx context.Context, coreType, filename str
 		return err
 	}
 	_, err = m.ExecuteStagedApply(ctx, StagedApplyRequest{
-		Mode:     StagedApplyModePatch,
-		CoreType: normalizedCore,
+		Mode:      StagedApplyModePatch,
+		CoreType:  normalizedCore,
+		SkipDrain: skipDrain,
 		PatchOperations: []StagedApplyPatchOperation{
 			{
 				Type:     StagedApplyPatchOperationUpsert,
@@ -588,16 +619,16 @@ func (m *Manager) buildConfigsWithDetails(files []string) []parser.ConfigFileWit
 			continue
 		}
 
-		hash := md5.Sum(content)
 		filename := filepath.Base(f)
 		protocols := m.registry.ParseAll(filename, content)
 		if len(protocols) == 0 {
 			continue
 		}
 
+		// 与面板使用同一套漂移哈希，忽略注入的用户列表
 		results = append(results, parser.ConfigFileWithDetails{
 			Filename:    filename,
-			ContentHash: hex.EncodeToString(hash[:]),
+			ContentHash: hash.ConfigContentHash(content),
 			Protocols:   protocols,
 		})
 	}
@@ -672,7 +703,7 @@ func (m *Manager) InjectUsers(ctx context.Context, users []UserConfig) error {
 		return fmt.Errorf("marshal updated config: %w", err)
 	}
 
-	if err := m.ApplyConfig(ctx, defaultFilename, updatedContent); err != nil {
+	if err := m.applyUserPatch(ctx, defaultFilename, updatedContent); err != nil {
 		return fmt.Errorf("write updated config: %w", err)
 	}
 
@@ -698,6 +729,9 @@ func (m *Manager) updateInboundUsers(config map[string]any, users []UserConfig)
 // injectUsersIntoSingboxInbound 注入 sing-box inbound 用户。
 func (m *Manager) injectUsersIntoSingboxInbound(inbound map[string]any, users []UserConfig) {
 	inboundType, _ := inbound["type"].(string)
+	if isOpenProxyInbound(inbound) {
+		return
+	}
 
 	// 按协议类型构建用户列表
 	usersList := make([]map[string]any, 0, len(users))
@@ -705,20 +739,8 @@ func (m *Manager) injectUsersIntoSingboxInbound(inbound map[string]any, users []
 		if !u.Enabled {
 			continue
 		}
-		user := map[string]any{
-			"name": u.Email,
-		}
-		switch inboundType {
-		case "vless":
-			user["uuid"] = u.UUID
-			user["flow"] = "xtls-rprx-vision"
-		case "vmess":
-			user["uuid"] = u.UUID
-		case "shadowsocks":
-			user["password"] = u.UUID
-		case "trojan", "hysteria2", "tuic":
-			user["password"] = u.UUID
-		default:
+		user, ok := singboxUserEntry(inboundType, u)
+		if !ok {
 			// 未知协议，跳过注入但记录日志
 			slog.Warn("unknown inbound type, skipping user injection", "type", inboundType)
 			return
@@ -731,6 +753,43 @@ func (m *Manager) injectUsersIntoSingboxInbound(inbound map[string]any, users []
 	}
 }
 
+// isOpenProxyInbound 判断 socks/http 入站是否按面板配置关闭了认证（未声明 users），
+// 此类入站多为仅监听回环地址的内部中转，注入用户会意外开启认证。
+func isOpenProxyInbound(inbound map[string]any) bool {
+	inboundType, _ := inbound["type"].(string)
+	if inboundType != "socks" && inboundType != "http" {
+		return false
+	}
+	_, hasUsers := inbound["users"]
+	return !hasUsers
+}
+
+// singboxUserEntry 按 sing-box inbound 类型构建单个用户条目，未知类型返回 false。
+func singboxUserEntry(inboundType string, u UserConfig) (map[string]any, bool) {
+	user := map[string]any{
+		"name": u.Email,
+	}
+	switch inboundType {
+	case "vless":
+		user["uuid"] = u.UUID
+		user["flow"] = "xtls-rprx-vision"
+	case "vmess":
+		user["uuid"] = u.UUID
+	case "shadowsocks":
+		user["password"] = u.UUID
+	case "trojan", "hysteria2", "tuic", "anytls":
+		user["password"] = u.UUID
+	case "naive", "socks", "http":
+		// naive/socks/http 使用 basic-auth 的 username/password
+		delete(user, "name")
+		user["username"] = u.Email
+		user["password"] = u.UUID
+	default:
+		return nil, false
+	}
+	return user, true
+}
+
 // InjectUsersXray 将用户注入 Xray 配置并重载服务。
 func (m *Manager) InjectUsersXray(ctx context.Context, users []UserConfig) error {
 	// 读取当前配置文件
@@ -757,7 +816,7 @@ func (m *Manager) InjectUsersXray(ctx context.Context, users []UserConfig) error
 		return fmt.Errorf("marshal updated config: %w", err)
 	}
 
-	if err := m.ApplyConfig(ctx, defaultFilename, updatedContent); err != nil {
+	if err := m.applyUserPatch(ctx, defaultFilename, updatedContent); err != nil {
 		return fmt.Errorf("write updated config: %w", err)
 	}
 
@@ -799,22 +858,8 @@ func (m *Manager) injectUsersIntoXrayInbound(inbound map[string]any, users []Use
 		if !u.Enabled {
 			continue
 		}
-		client := map[string]any{
-			"email": u.Email,
-			"level": 0,
-		}
-		switch protocol {
-		case "vless":
-			client["id"] = u.UUID
-			client["flow"] = "xtls-rprx-vision"
-		case "vmess":
-			client["id"] = u.UUID
-			client["alterId"] = 0
-		case "shadowsocks":
-			client["password"] = u.UUID
-		case "trojan":
-			client["password"] = u.UUID
-		default:
+		client, ok := xrayClientEntry(protocol, u)
+		if !ok {
 			// 未知协议，跳过注入但记录日志
 			slog.Warn("unknown inbound protocol, skipping user injection", "protocol", protocol)
 			return
@@ -827,6 +872,29 @@ func (m *Manager) injectUsersIntoXrayInbound(inbound map[string]any, users []Use
 	}
 }
 
+// xrayClientEntry 按 Xray inbound 协议构建单个 client 条目，未知协议返回 false。
+func xrayClientEntry(protocol string, u UserConfig) (map[string]any, bool) {
+	client := map[string]any{
+		"email": u.Email,
+		"level": 0,
+	}
+	switch protocol {
+	case "vless":
+		client["id"] = u.UUID
+		client["flow"] = "xtls-rprx-vision"
+	case "vmess":
+		client["id"] = u.UUID
+		client["alterId"] = 0
+	case "shadowsocks":
+		client["password"] = u.UUID
+	case "trojan":
+		client["password"] = u.UUID
+	default:
+		return nil, false
+	}
+	return client, true
+}
+
 // DetectCoreType 尝试判断配置属于 sing-box 还是 xray。
 func (m *Manager) DetectCoreType() string {
 	const defaultFilename = "config.json"
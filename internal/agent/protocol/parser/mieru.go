@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// MieruParser 解析 mieru 服务端（mita）配置文件。
+type MieruParser struct{}
+
+// NewMieruParser 创建 mieru 解析器。
+func NewMieruParser() *MieruParser {
+	return &MieruParser{}
+}
+
+// Name 返回解析器标识。
+func (p *MieruParser) Name() string {
+	return "mieru"
+}
+
+// CanParse 判断内容是否为 mita 配置（以 portBindings 为特征）。
+func (p *MieruParser) CanParse(content []byte) bool {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(content, &raw); err != nil {
+		return false
+	}
+	_, ok := raw["portBindings"]
+	return ok
+}
+
+// Parse 解析 mita 配置，每个端口绑定对应一条协议详情。
+func (p *MieruParser) Parse(filename string, content []byte) ([]ProtocolDetails, error) {
+	var config mieruConfig
+	if err := json.Unmarshal(content, &config); err != nil {
+		return nil, err
+	}
+
+	// 用户在所有端口绑定间共享；出于安全原因不保存密码
+	users := make([]UserInfo, 0, len(config.Users))
+	for _, u := range config.Users {
+		if u.Name == "" {
+			continue
+		}
+		users = append(users, UserInfo{UUID: "(password-auth)", Email: u.Name})
+	}
+
+	results := make([]ProtocolDetails, 0, len(config.PortBindings))
+	for i, binding := range config.PortBindings {
+		transport := strings.ToUpper(binding.Protocol)
+		if transport == "" {
+			transport = "TCP"
+		}
+		if binding.Port <= 0 && binding.PortRange == "" {
+			return nil, fmt.Errorf("portBindings[%d]: port or portRange is required", i)
+		}
+
+		opts := map[string]any{"transport": transport}
+		if binding.PortRange != "" {
+			opts["port_range"] = binding.PortRange
+		}
+		if config.MTU > 0 {
+			opts["mtu"] = config.MTU
+		}
+
+		port := binding.Port
+		if port <= 0 {
+			port = mieruRangeStart(binding.PortRange)
+		}
+		results = append(results, ProtocolDetails{
+			Protocol:   "mieru",
+			Tag:        fmt.Sprintf("mieru-%s-%d", strings.ToLower(transport), port),
+			Listen:     "::",
+			Port:       port,
+			Users:      users,
+			Options:    opts,
+			SourceFile: filename,
+			CoreType:   "mieru",
+		})
+	}
+
+	return results, nil
+}
+
+// mieruRangeStart 返回 "2012-2022" 形式端口范围的起始端口。
+func mieruRangeStart(portRange string) int {
+	var start int
+	if _, err := fmt.Sscanf(portRange, "%d", &start); err != nil {
+		return 0
+	}
+	return start
+}
+
+// mita 配置解析所需的内部结构体
+
+type mieruConfig struct {
+	PortBindings []mieruPortBinding `json:"portBindings"`
+	Users        []mieruUser        `json:"users"`
+	MTU          int                `json:"mtu"`
+}
+
+type mieruPortBinding struct {
+	Port      int    `json:"port"`
+	PortRange string `json:"portRange"`
+	Protocol  string `json:"protocol"` // TCP 或 UDP
+}
+
+type mieruUser struct {
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
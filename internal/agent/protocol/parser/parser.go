@@ -31,6 +31,7 @@ func NewRegistry() *Registry {
 		parsers: []Parser{
 			NewSingBoxParser(),
 			NewXrayParser(),
+			NewMieruParser(),
 		},
 	}
 }
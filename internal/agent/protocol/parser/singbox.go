@@ -114,11 +114,18 @@ type singBoxInbound struct {
 	CongestionControl     string `json:"congestion_control"`
 	ZeroRTTHandshake      bool   `json:"zero_rtt_handshake"`
 	IgnoreClientBandwidth bool   `json:"ignore_client_bandwidth"`
+
+	// AnyTLS 专用字段
+	PaddingScheme []string `json:"padding_scheme"`
+
+	// naive 专用字段
+	QUICCongestionControl string `json:"quic_congestion_control"`
 }
 
 type singBoxUser struct {
 	UUID     string `json:"uuid"`
 	Name     string `json:"name"`
+	Username string `json:"username"` // naive/socks/http basic-auth 用户名
 	Password string `json:"password"`
 	Flow     string `json:"flow"`
 }
@@ -128,6 +135,11 @@ type singBoxTLS struct {
 	ServerName string          `json:"server_name"`
 	ALPN       []string        `json:"alpn"`
 	Reality    *singBoxReality `json:"reality"`
+	ECH        *singBoxECH     `json:"ech"`
+}
+
+type singBoxECH struct {
+	Enabled bool `json:"enabled"`
 }
 
 type singBoxReality struct {
@@ -170,6 +182,7 @@ func (p *SingBoxParser) parseTLS(tls *singBoxTLS) *TLSConfig {
 		Enabled:    tls.Enabled,
 		ServerName: tls.ServerName,
 		ALPN:       tls.ALPN,
+		ECHEnabled: tls.ECH != nil && tls.ECH.Enabled,
 	}
 
 	if tls.Reality != nil && tls.Reality.Enabled {
@@ -221,8 +234,11 @@ func (p *SingBoxParser) parseUsers(inbound *singBoxInbound) []UserInfo {
 			Flow:  u.Flow,
 			Email: u.Name,
 		}
+		if user.Email == "" && u.Username != "" {
+			user.Email = u.Username // naive/socks/http 使用 username 标识用户
+		}
 
-		// 部分协议使用 password 代替 UUID（hysteria2, tuic, trojan, shadowtls, anytls）
+		// 部分协议使用 password 代替 UUID（hysteria2, tuic, trojan, shadowtls, anytls, naive, socks, http）
 		// 使用 password 作为标识，但不暴露实际值
 		if u.UUID == "" && u.Password != "" {
 			// 对密码认证的协议，仅标记存在用户并隐藏密码
@@ -271,6 +287,25 @@ func (p *SingBoxParser) parseProtocolOptions(inbound *singBoxInbound) map[string
 		if inbound.IgnoreClientBandwidth {
 			opts["ignore_client_bandwidth"] = true
 		}
+
+	case "anytls":
+		if len(inbound.PaddingScheme) > 0 {
+			opts["padding_scheme"] = inbound.PaddingScheme
+		}
+
+	case "socks", "http":
+		// 未声明 users 表示关闭认证（仅用于回环中转），空数组表示等待注入用户
+		if inbound.Users == nil {
+			opts["auth"] = "none"
+		}
+
+	case "naive":
+		if inbound.Network != "" {
+			opts["network"] = inbound.Network
+		}
+		if inbound.QUICCongestionControl != "" {
+			opts["quic_congestion_control"] = inbound.QUICCongestionControl
+		}
 	}
 
 	if len(opts) == 0 {
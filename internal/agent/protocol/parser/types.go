@@ -4,7 +4,7 @@ package parser
 // ProtocolDetails 描述解析后的协议配置详情。
 type ProtocolDetails struct {
 	// 基础信息
-	Protocol string `json:"protocol"` // vless, vmess, shadowsocks, trojan, hysteria, hysteria2, tuic, shadowtls, anytls
+	Protocol string `json:"protocol"` // vless, vmess, shadowsocks, trojan, hysteria, hysteria2, tuic, shadowtls, anytls, mieru
 	Tag      string `json:"tag"`      // Inbound 标识
 	Listen   string `json:"listen"`   // 监听地址
 	Port     int    `json:"port"`     // 监听端口
@@ -21,12 +21,15 @@ type ProtocolDetails struct {
 	// 用户信息
 	Users []UserInfo `json:"users,omitempty"`
 
+	// VLESS 加密（xray settings.decryption），空值表示 none
+	Encryption string `json:"encryption,omitempty"`
+
 	// 协议特有选项（congestion_control, version, detour 等）
 	Options map[string]any `json:"options,omitempty"`
 
 	// 元数据
 	SourceFile string `json:"source_file"` // 配置文件名
-	CoreType   string `json:"core_type"`   // sing-box 或 xray
+	CoreType   string `json:"core_type"`   // sing-box、xray 或 mieru
 }
 
 // TransportConfig 描述传输层设置。
@@ -51,6 +54,9 @@ type TLSConfig struct {
 
 	// Reality 相关
 	Reality *RealityConfig `json:"reality,omitempty"`
+
+	// ECHEnabled 表示开启了 Encrypted Client Hello
+	ECHEnabled bool `json:"ech_enabled,omitempty"`
 }
 
 // RealityConfig 描述 XTLS Reality 设置。
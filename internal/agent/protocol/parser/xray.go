@@ -80,6 +80,12 @@ func (p *XrayParser) Parse(filename string, content []byte) ([]ProtocolDetails,
 
 		// 解析协议相关设置
 		details.Users = p.parseSettings(inbound.Protocol, inbound.Settings)
+		if inbound.Protocol == "vless" {
+			details.Encryption = p.parseVLESSDecryption(inbound.Settings)
+		}
+		if (inbound.Protocol == "socks" || inbound.Protocol == "http") && len(details.Users) == 0 {
+			details.Options = map[string]any{"auth": "none"}
+		}
 
 		results = append(results, details)
 	}
@@ -312,6 +318,8 @@ func (p *XrayParser) parseSettings(protocol string, settings json.RawMessage) []
 		return p.parseShadowsocksSettings(settings)
 	case "trojan":
 		return p.parseTrojanSettings(settings)
+	case "socks", "http":
+		return p.parseAccountSettings(settings)
 	default:
 		return nil
 	}
@@ -340,6 +348,48 @@ func (p *XrayParser) parseVMessSettings(settings json.RawMessage) []UserInfo {
 	return users
 }
 
+// parseAccountSettings 解析 socks/http 的 accounts；socks 的 auth 为 noauth 时视为未启用认证。
+func (p *XrayParser) parseAccountSettings(settings json.RawMessage) []UserInfo {
+	var s struct {
+		Auth     string `json:"auth"`
+		Accounts []struct {
+			User string `json:"user"`
+			Pass string `json:"pass"`
+		} `json:"accounts"`
+	}
+	if err := json.Unmarshal(settings, &s); err != nil || s.Auth == "noauth" {
+		return nil
+	}
+
+	var users []UserInfo
+	for _, a := range s.Accounts {
+		if a.User == "" {
+			continue
+		}
+		// 出于安全原因不保存密码
+		users = append(users, UserInfo{UUID: "(password-auth)", Email: a.User})
+	}
+	return users
+}
+
+// parseVLESSDecryption 读取 VLESS 的 decryption 字段，"none" 视为未启用并返回空串。
+func (p *XrayParser) parseVLESSDecryption(settings json.RawMessage) string {
+	if settings == nil {
+		return ""
+	}
+	var s struct {
+		Decryption string `json:"decryption"`
+	}
+	if err := json.Unmarshal(settings, &s); err != nil {
+		return ""
+	}
+	value := strings.TrimSpace(s.Decryption)
+	if strings.EqualFold(value, "none") {
+		return ""
+	}
+	return value
+}
+
 func (p *XrayParser) parseShadowsocksSettings(settings json.RawMessage) []UserInfo {
 	var s struct {
 		Method   string `json:"method"`
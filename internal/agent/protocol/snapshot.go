@@ -0,0 +1,118 @@
+package protocol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultSnapshotKeep 是每个核心默认保留的历史配置快照数量。
+	defaultSnapshotKeep = 3
+	// defaultApplyGracePeriod 是应用配置后观察服务状态的默认宽限期。
+	defaultApplyGracePeriod = 5 * time.Second
+	// applyStatusPollInterval 是宽限期内轮询服务状态的间隔。
+	applyStatusPollInterval = time.Second
+
+	snapshotRootDirName = ".xboard_snapshots"
+)
+
+// ErrConfigRolledBack 表示新配置应用后校验失败，已自动回滚到上一版配置。
+var ErrConfigRolledBack = errors.New("config rolled back")
+
+// snapshotDir 返回指定核心的快照目录，与 stage/backup 目录位于同一父目录下。
+func snapshotDir(activeDir, coreType string) string {
+	label := strings.TrimSpace(coreType)
+	if label == "" {
+		label = "default"
+	}
+	return filepath.Join(filepath.Dir(activeDir), snapshotRootDirName, label)
+}
+
+// keepSnapshot 将切换前的配置目录移入快照区，并只保留最近 SnapshotKeep 份。
+func (m *Manager) keepSnapshot(activeDir, coreType, backupDir string) error {
+	root := snapshotDir(activeDir, coreType)
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return fmt.Errorf("create snapshot dir: %w", err)
+	}
+	target := filepath.Join(root, fmt.Sprintf("%d", time.Now().UnixNano()))
+	if err := os.Rename(backupDir, target); err != nil {
+		return fmt.Errorf("move backup to snapshot: %w", err)
+	}
+	return pruneSnapshots(root, m.cfg.SnapshotKeep)
+}
+
+// pruneSnapshots 按名称（纳秒时间戳）删除最旧的快照，保留 keep 份。
+func pruneSnapshots(root string, keep int) error {
+	if keep <= 0 {
+		keep = defaultSnapshotKeep
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return fmt.Errorf("read snapshot dir: %w", err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	if len(names) <= keep {
+		return nil
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if len(names[i]) != len(names[j]) {
+			return len(names[i]) < len(names[j])
+		}
+		return names[i] < names[j]
+	})
+	for _, name := range names[:len(names)-keep] {
+		if err := os.RemoveAll(filepath.Join(root, name)); err != nil {
+			return fmt.Errorf("remove snapshot %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// verifyAppliedConfig 在宽限期内轮询服务状态，随后执行可选的健康探测命令。
+// 仅当服务在应用前处于运行状态时才以运行状态作为判据，避免无状态命令的 init 系统误判。
+func (m *Manager) verifyAppliedConfig(ctx context.Context, wasRunning bool) error {
+	action, err := normalizeServiceAction(m.cfg)
+	if err != nil || action == NoneServiceAction {
+		return nil
+	}
+	grace := m.cfg.ApplyGracePeriod
+	if grace < 0 {
+		return nil
+	}
+
+	if wasRunning {
+		deadline := time.Now().Add(grace)
+		for {
+			running, err := m.ServiceStatus(ctx)
+			if err == nil && !running {
+				return fmt.Errorf("service %s stopped after apply", m.cfg.ServiceName)
+			}
+			if !time.Now().Before(deadline) {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(applyStatusPollInterval):
+			}
+		}
+	}
+
+	if m.cfg.HealthCheckCmd != "" {
+		if err := runCommand(ctx, m.cfg.HealthCheckCmd); err != nil {
+			return fmt.Errorf("health check failed: %w", err)
+		}
+	}
+	return nil
+}
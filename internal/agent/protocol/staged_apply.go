@@ -49,6 +49,8 @@ type StagedApplyRequest struct {
 	CoreType        string
 	SnapshotFiles   []StagedApplyFile
 	PatchOperations []StagedApplyPatchOperation
+	// SkipDrain 为 true 时重启不等待已有连接结束，用于频繁的用户同步。
+	SkipDrain bool
 }
 
 // StagedApplyResult describes the transaction execution outcome.
@@ -139,6 +141,8 @@ func (m *Manager) ExecuteStagedApply(ctx context.Context, req StagedApplyRequest
 		return result, fmt.Errorf("validate staged apply: %w", err)
 	}
 
+	wasRunning, _ := m.ServiceStatus(ctx)
+
 	backupDir, switched, err := switchManagedDir(activeDir, req.RunID, stageDir)
 	if err != nil {
 		return result, err
@@ -147,10 +151,16 @@ func (m *Manager) ExecuteStagedApply(ctx context.Context, req StagedApplyRequest
 	result.BackupDir = backupDir
 	result.Switched = switched
 
-	reloadErr := m.ReloadServiceWithValidationDir(ctx, activeDir)
+	reloadErr := m.reloadServiceWithValidationDir(ctx, activeDir, !req.SkipDrain)
+	if reloadErr == nil {
+		// reload 成功后仍需在宽限期内确认核心没有因新配置崩溃
+		reloadErr = m.verifyAppliedConfig(ctx, wasRunning)
+	}
 	if reloadErr == nil {
 		if switched {
-			_ = os.RemoveAll(backupDir)
+			if err := m.keepSnapshot(activeDir, coreType, backupDir); err != nil {
+				_ = os.RemoveAll(backupDir)
+			}
 		}
 		return result, nil
 	}
@@ -162,10 +172,10 @@ func (m *Manager) ExecuteStagedApply(ctx context.Context, req StagedApplyRequest
 		return result, fmt.Errorf("reload service after apply: %w (rollback switch failed: %v)", reloadErr, err)
 	}
 	result.RolledBack = true
-	if err := m.ReloadServiceWithValidationDir(ctx, activeDir); err != nil {
+	if err := m.reloadServiceWithValidationDir(ctx, activeDir, false); err != nil {
 		return result, fmt.Errorf("reload service after apply: %w (rollback reload failed: %v)", reloadErr, err)
 	}
-	return result, fmt.Errorf("reload service after apply: %w (rolled back)", reloadErr)
+	return result, fmt.Errorf("reload service after apply: %w (%w)", reloadErr, ErrConfigRolledBack)
 }
 
 func normalizeStagedApplyCoreType(raw string) (string, error) {
@@ -0,0 +1,122 @@
+package protocol
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ApplyUserDelta 在现有配置上增量更新用户：先按邮箱移除 removed 与 upserts 中的旧条目，
+// 再为启用的 upserts 追加新条目，避免每次同步都重建整个用户数组。
+func (m *Manager) ApplyUserDelta(ctx context.Context, removed, upserts []UserConfig) error {
+	if len(removed) == 0 && len(upserts) == 0 {
+		return nil
+	}
+
+	const defaultFilename = "config.json"
+	content, err := m.ReadConfig(defaultFilename)
+	if err != nil {
+		return fmt.Errorf("read config for user delta: %w", err)
+	}
+
+	var config map[string]any
+	if err := json.Unmarshal(content, &config); err != nil {
+		return fmt.Errorf("parse config JSON: %w", err)
+	}
+
+	// 变更用户的新旧邮箱都需要移除，再按新内容追加
+	drop := make(map[string]struct{}, len(removed)+len(upserts))
+	for _, u := range removed {
+		drop[strings.ToLower(u.Email)] = struct{}{}
+	}
+	for _, u := range upserts {
+		drop[strings.ToLower(u.Email)] = struct{}{}
+	}
+
+	isXray := detectCoreTypeFromObject(config) == "xray"
+	inbounds, _ := config["inbounds"].([]any)
+	for _, inbound := range inbounds {
+		inboundMap, ok := inbound.(map[string]any)
+		if !ok {
+			continue
+		}
+		if isXray {
+			applyXrayClientDelta(inboundMap, drop, upserts)
+		} else {
+			applySingboxUserDelta(inboundMap, drop, upserts)
+		}
+	}
+
+	updatedContent, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("marshal updated config: %w", err)
+	}
+	if err := m.applyUserPatch(ctx, defaultFilename, updatedContent); err != nil {
+		return fmt.Errorf("write updated config: %w", err)
+	}
+	return nil
+}
+
+// applySingboxUserDelta 对单个 sing-box inbound 的 users 数组做增量修改，未知类型保持不变。
+func applySingboxUserDelta(inbound map[string]any, drop map[string]struct{}, upserts []UserConfig) {
+	inboundType, _ := inbound["type"].(string)
+	entry, ok := singboxUserEntry(inboundType, UserConfig{})
+	if !ok || isOpenProxyInbound(inbound) {
+		return
+	}
+	existing, _ := inbound["users"].([]any)
+	// naive/socks/http 用户以 basic-auth 的 username 标识，其余类型使用 name
+	key := "name"
+	if _, ok := entry["username"]; ok {
+		key = "username"
+	}
+	users := filterUserEntries(existing, key, drop)
+	for _, u := range upserts {
+		if !u.Enabled {
+			continue
+		}
+		entry, _ := singboxUserEntry(inboundType, u)
+		users = append(users, entry)
+	}
+	inbound["users"] = users
+}
+
+// applyXrayClientDelta 对单个 Xray inbound 的 settings.clients 数组做增量修改，未知协议保持不变。
+func applyXrayClientDelta(inbound map[string]any, drop map[string]struct{}, upserts []UserConfig) {
+	protocol, _ := inbound["protocol"].(string)
+	if _, ok := xrayClientEntry(protocol, UserConfig{}); !ok {
+		return
+	}
+	settings, ok := inbound["settings"].(map[string]any)
+	if !ok {
+		settings = make(map[string]any)
+		inbound["settings"] = settings
+	}
+	existing, _ := settings["clients"].([]any)
+	clients := filterUserEntries(existing, "email", drop)
+	for _, u := range upserts {
+		if !u.Enabled {
+			continue
+		}
+		entry, _ := xrayClientEntry(protocol, u)
+		clients = append(clients, entry)
+	}
+	settings["clients"] = clients
+}
+
+// filterUserEntries 去掉 key 字段命中 drop 集合（忽略大小写）的条目。
+func filterUserEntries(entries []any, key string, drop map[string]struct{}) []any {
+	kept := make([]any, 0, len(entries))
+	for _, entry := range entries {
+		if entryMap, ok := entry.(map[string]any); ok {
+			if name, _ := entryMap[key].(string); name != "" {
+				if _, hit := drop[strings.ToLower(name)]; hit {
+					continue
+				}
+			}
+		}
+		kept = append(kept, entry)
+	}
+	return kept
+}
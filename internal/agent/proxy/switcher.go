@@ -13,6 +13,8 @@ import (
 	"time"
 
 	"github.com/creamcroissant/xboard/internal/agent/core"
+	"github.com/creamcroissant/xboard/internal/agent/monitor"
+	"github.com/creamcroissant/xboard/internal/agent/protocol"
 )
 
 const defaultDrainTimeout = 5 * time.Second
@@ -100,6 +102,7 @@ type InstanceGroup struct {
 	InternalPorts []int
 	CoreType      string
 	InstanceID    string
+	ConfigJSON    []byte // 未打补丁的原始配置，用于保持端口的重启
 }
 
 // SwitcherOptions configures Switcher dependencies.
@@ -226,6 +229,41 @@ func (s *Switcher) Initialize(ctx context.Context) error {
 
 // Switch executes a zero-downtime switch.
 func (s *Switcher) Switch(ctx context.Context, req SwitchRequest) (*SwitchResult, error) {
+	return s.switchWithDrain(ctx, req, s.config.DrainTimeout)
+}
+
+// RestartWithDrain 用新配置重新切换监听 req.ListenPorts 的实例组：新实例在内部端口就绪并接管 DNAT 后，
+// 旧实例继续服务已有连接直到 timeout 再停止。返回切换时旧实例上的活跃连接数；
+// 这些端口不属于任何由 Switcher 管理的实例组时 handled 为 false。
+func (s *Switcher) RestartWithDrain(ctx context.Context, req protocol.DrainRestartRequest, timeout time.Duration) (int, bool, error) {
+	if timeout <= 0 {
+		timeout = s.config.DrainTimeout
+	}
+	group := s.GetGroup(ComputeGroupID(req.ListenPorts))
+	if group == nil || group.InstanceID == "" {
+		return 0, false, nil
+	}
+	coreType := req.CoreType
+	if coreType != "xray" && coreType != "sing-box" {
+		coreType = group.CoreType
+	}
+	drained := 0
+	if count, err := monitor.CountEstablishedTCP(ctx, group.InternalPorts); err == nil {
+		drained = count
+	}
+	_, err := s.switchWithDrain(ctx, SwitchRequest{
+		FromInstanceID: group.InstanceID,
+		ToCoreType:     coreType,
+		ConfigJSON:     req.ConfigJSON,
+		ListenPorts:    group.ExternalPorts,
+	}, timeout)
+	if err != nil {
+		return drained, true, fmt.Errorf("restart group %s: %w", group.ID, err)
+	}
+	return drained, true, nil
+}
+
+func (s *Switcher) switchWithDrain(ctx context.Context, req SwitchRequest, drainTimeout time.Duration) (*SwitchResult, error) {
 	if req.ToCoreType == "" {
 		return nil, fmt.Errorf("to_core_type is required")
 	}
@@ -330,10 +368,11 @@ func (s *Switcher) Switch(ctx context.Context, req SwitchRequest) (*SwitchResult
 		InternalPorts: clonePorts(internalPorts),
 		CoreType:      req.ToCoreType,
 		InstanceID:    newInstanceID,
+		ConfigJSON:    append([]byte(nil), req.ConfigJSON...),
 	})
 
 	if req.FromInstanceID != "" && req.FromInstanceID != newInstanceID {
-		s.asyncCleanup(req.FromInstanceID)
+		s.asyncCleanup(req.FromInstanceID, drainTimeout)
 	}
 
 	return &SwitchResult{
@@ -388,7 +427,7 @@ func (s *Switcher) setGroup(group *InstanceGroup) {
 	s.groups[group.ID] = cloneGroup(group)
 }
 
-func (s *Switcher) asyncCleanup(instanceID string) {
+func (s *Switcher) asyncCleanup(instanceID string, drainTimeout time.Duration) {
 	if instanceID == "" {
 		return
 	}
@@ -399,7 +438,7 @@ func (s *Switcher) asyncCleanup(instanceID string) {
 			}
 		}
 
-		time.Sleep(s.config.DrainTimeout)
+		time.Sleep(drainTimeout)
 
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
@@ -647,6 +686,7 @@ func cloneGroup(group *InstanceGroup) *InstanceGroup {
 		InternalPorts: clonePorts(group.InternalPorts),
 		CoreType:      group.CoreType,
 		InstanceID:    group.InstanceID,
+		ConfigJSON:    append([]byte(nil), group.ConfigJSON...),
 	}
 }
 
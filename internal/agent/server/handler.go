@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"sync"
 
 	"github.com/creamcroissant/xboard/internal/agent/protocol"
 )
@@ -12,6 +14,7 @@ import (
 // Handler handles HTTP requests for the Agent API.
 type Handler struct {
 	protoMgr  *protocol.Manager
+	mu        sync.RWMutex
 	authToken string
 }
 
@@ -23,6 +26,19 @@ func NewHandler(protoMgr *protocol.Manager, authToken string) *Handler {
 	}
 }
 
+// SetAuthToken replaces the API token, e.g. after the panel rotates the host token it was derived from.
+func (h *Handler) SetAuthToken(token string) {
+	h.mu.Lock()
+	h.authToken = token
+	h.mu.Unlock()
+}
+
+func (h *Handler) currentAuthToken() string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.authToken
+}
+
 // jsonResponse writes a JSON response.
 func (h *Handler) jsonResponse(w http.ResponseWriter, status int, data any) {
 	w.Header().Set("Content-Type", "application/json")
@@ -42,7 +58,8 @@ func (h *Handler) errorResponse(w http.ResponseWriter, status int, message strin
 // AuthMiddleware validates the authorization token.
 func (h *Handler) AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if h.authToken == "" {
+		authToken := h.currentAuthToken()
+		if authToken == "" {
 			next.ServeHTTP(w, r)
 			return
 		}
@@ -51,7 +68,7 @@ func (h *Handler) AuthMiddleware(next http.Handler) http.Handler {
 		if token == "" {
 			token = r.Header.Get("X-Auth-Token")
 		}
-		if token != h.authToken && token != "Bearer "+h.authToken {
+		if token != authToken && token != "Bearer "+authToken {
 			h.errorResponse(w, http.StatusUnauthorized, "unauthorized")
 			return
 		}
@@ -225,6 +242,39 @@ func (h *Handler) ReloadService(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// EffectiveConfig returns the merged config the core is actually running.
+// Secrets are redacted by default; pass reveal=true to include them.
+// GET /debug/effective-config?core=sing-box|xray&reveal=true
+func (h *Handler) EffectiveConfig(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	effective, err := h.protoMgr.EffectiveConfig(query.Get("core"))
+	if err != nil {
+		h.errorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	reveal, _ := strconv.ParseBool(query.Get("reveal"))
+	if reveal && h.currentAuthToken() == "" {
+		// 未配置令牌时接口对任何人开放，此时拒绝输出明文凭据
+		h.errorResponse(w, http.StatusForbidden, "reveal requires server auth_token to be configured")
+		return
+	}
+	config := any(effective.Config)
+	if reveal {
+		slog.Warn("Effective config requested with secrets revealed", "remote_addr", r.RemoteAddr)
+	} else {
+		config = redactConfigSecrets(effective.Config)
+	}
+
+	h.jsonResponse(w, http.StatusOK, map[string]any{
+		"core_type": effective.CoreType,
+		"dir":       effective.Dir,
+		"sources":   effective.Sources,
+		"redacted":  !reveal,
+		"config":    config,
+	})
+}
+
 // HealthCheck returns the health status of the agent.
 // GET /health
 func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
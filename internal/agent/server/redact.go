@@ -0,0 +1,52 @@
+package server
+
+import "github.com/creamcroissant/xboard/internal/support/security"
+
+const redactedValue = "[REDACTED]"
+
+// redactConfigSecrets 递归复制配置并替换敏感字段。只替换字符串及字符串数组，
+// 数字等类型的同名字段（如数值 id）保持原样。
+func redactConfigSecrets(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			if security.IsSecretConfigKey(key) {
+				out[key] = redactSecretValue(item)
+				continue
+			}
+			out[key] = redactConfigSecrets(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = redactConfigSecrets(item)
+		}
+		return out
+	default:
+		return value
+	}
+}
+
+func redactSecretValue(value any) any {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return v
+		}
+		return redactedValue
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			if _, ok := item.(string); ok {
+				out[i] = redactedValue
+				continue
+			}
+			out[i] = redactConfigSecrets(item)
+		}
+		return out
+	default:
+		return redactConfigSecrets(value)
+	}
+}
@@ -46,6 +46,9 @@ func NewServer(cfg Config, protoMgr *protocol.Manager) *Server {
 	mux.Handle("GET /api/v1/service/status", handler.AuthMiddleware(http.HandlerFunc(handler.ServiceStatus)))
 	mux.Handle("POST /api/v1/service/reload", handler.AuthMiddleware(http.HandlerFunc(handler.ReloadService)))
 
+	// 调试接口（需要鉴权）
+	mux.Handle("GET /debug/effective-config", handler.AuthMiddleware(http.HandlerFunc(handler.EffectiveConfig)))
+
 	return &Server{
 		httpServer: &http.Server{
 			Addr:         cfg.Listen,
@@ -58,6 +61,11 @@ func NewServer(cfg Config, protoMgr *protocol.Manager) *Server {
 	}
 }
 
+// SetAuthToken 更新 API 认证令牌，新令牌对后续请求立即生效。
+func (s *Server) SetAuthToken(token string) {
+	s.handler.SetAuthToken(token)
+}
+
 // Start 启动 HTTP 服务。
 func (s *Server) Start() error {
 	slog.Info("Agent HTTP server starting", "addr", s.httpServer.Addr)
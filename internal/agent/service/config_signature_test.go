@@ -0,0 +1,52 @@
+package service
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/creamcroissant/xboard/internal/agent/config"
+	"github.com/creamcroissant/xboard/internal/support/security"
+	agentv1 "github.com/creamcroissant/xboard/pkg/pb/agent/v1"
+)
+
+func signedConfigResponse(hostID, version int64, etag string) *agentv1.ConfigResponse {
+	configJSON := []byte(`{"inbounds":[]}`)
+	message := security.ConfigSignatureMessage(security.ConfigSignatureScopeConfig, hostID, configJSON, etag, strconv.FormatInt(version, 10))
+	return &agentv1.ConfigResponse{ConfigJson: configJSON, Etag: etag, Version: version, AgentHostId: hostID, Signature: security.SignConfig("secret", message)}
+}
+
+func TestVerifyConfigSignatureRejectsReplays(t *testing.T) {
+	a := &Agent{cfg: &config.Config{GRPC: config.GRPCConfig{ConfigSigning: config.ConfigSigningConfig{Enabled: true, Secret: "secret"}}}}
+
+	if err := a.verifyConfigSignature(signedConfigResponse(7, 200, "b")); err != nil {
+		t.Fatalf("fresh config rejected: %v", err)
+	}
+	if err := a.verifyConfigSignature(signedConfigResponse(7, 100, "a")); err == nil {
+		t.Fatal("older signed config must be rejected")
+	}
+	if err := a.verifyConfigSignature(signedConfigResponse(8, 300, "c")); err == nil {
+		t.Fatal("config signed for another host must be rejected")
+	}
+	tampered := signedConfigResponse(7, 300, "c")
+	tampered.Version = 400
+	if err := a.verifyConfigSignature(tampered); err == nil {
+		t.Fatal("config with a rewritten version must be rejected")
+	}
+}
+
+func TestVerifyCoreOperationSignature(t *testing.T) {
+	a := &Agent{cfg: &config.Config{GRPC: config.GRPCConfig{ConfigSigning: config.ConfigSigningConfig{Enabled: true, Secret: "secret", AgentHostID: 7}}}}
+	op := &agentv1.CoreOperation{Id: "op-1", AgentHostId: 7, OperationType: "switch", CoreType: "xray", RequestPayload: []byte(`{"config_json":"e30="}`)}
+
+	if err := a.verifyCoreOperationSignature(op); err == nil {
+		t.Fatal("unsigned core operation must be rejected")
+	}
+	op.Signature = security.SignConfig("secret", security.ConfigSignatureMessage(security.ConfigSignatureScopeCoreOperation, 7, op.RequestPayload, op.Id, op.OperationType, op.CoreType))
+	if err := a.verifyCoreOperationSignature(op); err != nil {
+		t.Fatalf("signed core operation rejected: %v", err)
+	}
+	op.RequestPayload = []byte(`{"config_json":"e319"}`)
+	if err := a.verifyCoreOperationSignature(op); err == nil {
+		t.Fatal("core operation with a tampered payload must be rejected")
+	}
+}
@@ -6,17 +6,21 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"os/exec"
 	"strings"
 	"time"
 
+	"github.com/creamcroissant/xboard/internal/agent/capability"
 	"github.com/creamcroissant/xboard/internal/agent/command"
 	"github.com/creamcroissant/xboard/internal/agent/core"
 	"github.com/creamcroissant/xboard/internal/agent/protocol"
+	"github.com/creamcroissant/xboard/internal/support/security"
 	agentv1 "github.com/creamcroissant/xboard/pkg/pb/agent/v1"
 )
 
 const (
 	operationEventScopeCoreOperation = "core_operation"
+	operationEventScopeConfigSync    = "config_sync"
 
 	operationEventLevelInfo  = "info"
 	operationEventLevelWarn  = "warn"
@@ -155,6 +159,10 @@ func (a *Agent) executeCoreOperation(ctx context.Context, operation *agentv1.Cor
 	if operation == nil {
 		return "failed", nil, "empty core operation"
 	}
+	if err := a.verifyCoreOperationSignature(operation); err != nil {
+		slog.Error("Refusing unverified core operation", "operation_id", operation.GetId(), "type", operation.GetOperationType(), "error", err)
+		return "failed", nil, err.Error()
+	}
 	switch strings.TrimSpace(operation.GetOperationType()) {
 	case "install":
 		a.reportCoreOperationEvent(ctx, operation, "installing", operationEventLevelInfo, "core install started", nil)
@@ -168,11 +176,24 @@ func (a *Agent) executeCoreOperation(ctx context.Context, operation *agentv1.Cor
 	case "ensure":
 		a.reportCoreOperationEvent(ctx, operation, "ensuring", operationEventLevelInfo, "core ensure started", nil)
 		return a.executeEnsureCore(ctx, operation)
+	case "upgrade":
+		a.reportCoreOperationEvent(ctx, operation, "upgrading", operationEventLevelInfo, "core upgrade started", nil)
+		return a.executeUpgradeCore(ctx, operation)
 	default:
 		return "failed", nil, fmt.Sprintf("unsupported core operation type %q", operation.GetOperationType())
 	}
 }
 
+// verifyCoreOperationSignature 在启用 grpc.config_signing 时校验 core operation 的签名。
+// switch/create 的载荷带完整核心配置，install/upgrade 决定下载的版本，都不能绕过配置签名。
+func (a *Agent) verifyCoreOperationSignature(operation *agentv1.CoreOperation) error {
+	if !a.cfg.GRPC.ConfigSigning.Enabled {
+		return nil
+	}
+	message := security.ConfigSignatureMessage(security.ConfigSignatureScopeCoreOperation, operation.GetAgentHostId(), operation.GetRequestPayload(), operation.GetId(), operation.GetOperationType(), operation.GetCoreType())
+	return a.verifySignedPayload("core operation", operation.GetAgentHostId(), message, operation.GetSignature(), operation.GetKeyId())
+}
+
 func (a *Agent) executeInstallCore(ctx context.Context, operation *agentv1.CoreOperation) (string, []byte, string) {
 	var payload agentv1.InstallCorePayload
 	if err := json.Unmarshal(operation.GetRequestPayload(), &payload); err != nil {
@@ -259,6 +280,98 @@ func (a *Agent) executeEnsureCore(ctx context.Context, operation *agentv1.CoreOp
 	return "completed", data, ""
 }
 
+// executeUpgradeCore 下载并校验指定版本的核心，用暂存二进制校验当前配置后以排空方式切换；
+// 校验失败时保留旧二进制，切换或健康检查失败时恢复旧二进制并上报 rolled_back。
+func (a *Agent) executeUpgradeCore(ctx context.Context, operation *agentv1.CoreOperation) (string, []byte, string) {
+	var payload agentv1.UpgradeCorePayload
+	if err := json.Unmarshal(operation.GetRequestPayload(), &payload); err != nil {
+		return "failed", nil, err.Error()
+	}
+	if a.protoMgr == nil {
+		return "failed", nil, "protocol manager unavailable"
+	}
+	coreType := core.CoreType(protocol.NormalizeCoreType(operation.GetCoreType()))
+	var upgraderCfg core.UpgraderConfig
+	switch coreType {
+	case core.CoreTypeSingBox:
+		upgraderCfg = core.UpgraderConfig{BinaryPath: a.cfg.Core.SingBoxBinaryPath, ReleaseURL: a.cfg.Core.SingBoxReleaseURL, ChecksumURL: a.cfg.Core.SingBoxChecksumURL}
+	case core.CoreTypeXray:
+		upgraderCfg = core.UpgraderConfig{BinaryPath: a.cfg.Core.XrayBinaryPath, ReleaseURL: a.cfg.Core.XrayReleaseURL, ChecksumURL: a.cfg.Core.XrayChecksumURL}
+	default:
+		return "failed", nil, fmt.Sprintf("unsupported core_type %q for upgrade", operation.GetCoreType())
+	}
+	version := strings.TrimPrefix(strings.TrimSpace(payload.GetVersion()), "v")
+	hooks := core.UpgradeHooks{
+		Progress: func(phase, message string, eventPayload map[string]any) {
+			a.reportCoreOperationEvent(ctx, operation, phase, operationEventLevelInfo, message, eventPayload)
+		},
+		Validate: func(ctx context.Context, stagedPath string) error {
+			return a.validateWithStagedCore(ctx, coreType, stagedPath)
+		},
+		Activate: func(ctx context.Context) error {
+			_, err := a.protoMgr.RestartWithDrain(ctx)
+			return err
+		},
+		HealthCheck: func(ctx context.Context) error {
+			return a.checkUpgradedCore(ctx, coreType, version)
+		},
+	}
+	result, err := core.NewUpgrader(upgraderCfg, nil).Upgrade(ctx, string(coreType), version, hooks)
+	a.invalidateCapabilitiesCache()
+	data, _ := json.Marshal(result)
+	if err != nil {
+		if result != nil && result.RolledBack {
+			return "rolled_back", data, err.Error()
+		}
+		return "failed", data, err.Error()
+	}
+	return "completed", data, ""
+}
+
+// validateWithStagedCore 用暂存的新版本二进制检查当前生效配置。
+func (a *Agent) validateWithStagedCore(ctx context.Context, coreType core.CoreType, stagedPath string) error {
+	dir, err := a.protoMgr.CurrentConfigDir()
+	if err != nil {
+		return err
+	}
+	args := []string{"check", "-C", dir}
+	if coreType == core.CoreTypeXray {
+		args = []string{"run", "-test", "-confdir", dir}
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, stagedPath, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, protocol.SanitizeCommandOutput(output))
+	}
+	return nil
+}
+
+// checkUpgradedCore 确认切换后服务仍在运行且二进制报告的版本为目标版本。
+func (a *Agent) checkUpgradedCore(ctx context.Context, coreType core.CoreType, version string) error {
+	running, err := a.protoMgr.ServiceStatus(ctx)
+	if err != nil {
+		return fmt.Errorf("query service status: %w", err)
+	}
+	if !running {
+		return fmt.Errorf("service is not running after switch")
+	}
+	detector := capability.NewDetector(a.cfg.Core.SingBoxBinaryPath, a.cfg.Core.XrayBinaryPath)
+	var caps *capability.DetectedCapabilities
+	if coreType == core.CoreTypeXray {
+		caps, err = detector.DetectXray(ctx)
+	} else {
+		caps, err = detector.DetectSingBox(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("detect upgraded core version: %w", err)
+	}
+	if got := strings.TrimPrefix(strings.TrimSpace(caps.CoreVersion), "v"); got != version {
+		return fmt.Errorf("upgraded core reports version %q, want %q", got, version)
+	}
+	return nil
+}
+
 func (a *Agent) reportCoreOperationTerminalEvent(ctx context.Context, operation *agentv1.CoreOperation, statusValue, errMessage string) {
 	phase := "completed"
 	level := operationEventLevelInfo
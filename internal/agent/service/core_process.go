@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/agent/core"
+	agentv1 "github.com/creamcroissant/xboard/pkg/pb/agent/v1"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// coreProcessSampler 在每次状态上报时采样各核心实例进程的 CPU 与 RSS。
+// 保留进程句柄以便按上报间隔计算 CPU 增量，采样开销与上报频率一致。
+type coreProcessSampler struct {
+	mu    sync.Mutex
+	procs map[string]*process.Process // instance id -> process
+}
+
+// annotate 按实例 PID 采样并写入上报结构。进程在采样期间退出时对应字段保持零值，
+// 并丢弃缓存的句柄，下次以新 PID 重新建立基线。
+func (s *coreProcessSampler) annotate(ctx context.Context, instances []*core.CoreInstance, reports []*agentv1.CoreInstance) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.procs == nil {
+		s.procs = make(map[string]*process.Process)
+	}
+
+	seen := make(map[string]struct{}, len(instances))
+	now := time.Now().Unix()
+	for i, inst := range instances {
+		if inst == nil || i >= len(reports) {
+			continue
+		}
+		seen[inst.ID] = struct{}{}
+		if inst.PID <= 0 || inst.Status != core.StatusRunning {
+			delete(s.procs, inst.ID)
+			continue
+		}
+		cpuPercent, rss, ok := s.sample(ctx, inst.ID, int32(inst.PID))
+		if !ok {
+			delete(s.procs, inst.ID)
+			continue
+		}
+		reports[i].CpuPercent = cpuPercent
+		reports[i].MemoryRssBytes = rss
+		reports[i].ResourceSampledAt = now
+	}
+	for id := range s.procs {
+		if _, ok := seen[id]; !ok {
+			delete(s.procs, id)
+		}
+	}
+}
+
+func (s *coreProcessSampler) sample(ctx context.Context, instanceID string, pid int32) (float64, uint64, bool) {
+	proc, cached := s.procs[instanceID]
+	if cached && proc.Pid != pid {
+		cached = false
+	}
+	if !cached {
+		var err error
+		proc, err = process.NewProcessWithContext(ctx, pid)
+		if err != nil {
+			return 0, 0, false
+		}
+		s.procs[instanceID] = proc
+	}
+
+	mem, err := proc.MemoryInfoWithContext(ctx)
+	if err != nil {
+		return 0, 0, false
+	}
+	var cpuPercent float64
+	if cached {
+		cpuPercent, err = proc.PercentWithContext(ctx, 0)
+	} else {
+		// 首次采样没有上一次的 CPU 时间，先用进程生命周期内的平均值，同时为下次建立基线
+		if _, err = proc.PercentWithContext(ctx, 0); err == nil {
+			cpuPercent, err = proc.CPUPercentWithContext(ctx)
+		}
+	}
+	if err != nil {
+		return 0, 0, false
+	}
+	return cpuPercent, mem.RSS, true
+}
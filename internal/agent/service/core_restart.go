@@ -0,0 +1,80 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/agent/core"
+	agentv1 "github.com/creamcroissant/xboard/pkg/pb/agent/v1"
+)
+
+const (
+	// coreRestartRetention 为保留重启记录的时长，面板按自身配置的窗口统计次数。
+	coreRestartRetention = 7 * 24 * time.Hour
+	// coreRestartMaxRecords 限制单个实例上报的重启记录条数，防止崩溃循环时上报无限增长。
+	coreRestartMaxRecords = 256
+)
+
+// coreRestartTracker 通过比较相邻两次上报的 PID 与启动时间识别核心实例重启。
+// 只能观察到上报间隔内的最后一次重启，间隔内多次快速重启会被合并为一次。
+type coreRestartTracker struct {
+	mu       sync.Mutex
+	last     map[string]coreRestartObservation // instance id -> 上一次看到的运行进程
+	restarts map[string][]int64                // instance id -> 重启时间（旧到新）
+}
+
+type coreRestartObservation struct {
+	pid       int
+	startedAt int64
+}
+
+// annotate 更新重启记录并写入上报结构；首次观察到的实例只建立基线，不计为重启。
+func (t *coreRestartTracker) annotate(instances []*core.CoreInstance, reports []*agentv1.CoreInstance, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.last == nil {
+		t.last = make(map[string]coreRestartObservation)
+		t.restarts = make(map[string][]int64)
+	}
+
+	cutoff := now.Add(-coreRestartRetention).Unix()
+	seen := make(map[string]struct{}, len(instances))
+	for i, inst := range instances {
+		if inst == nil || i >= len(reports) {
+			continue
+		}
+		seen[inst.ID] = struct{}{}
+		if inst.Status == core.StatusRunning && inst.PID > 0 {
+			current := coreRestartObservation{pid: inst.PID, startedAt: inst.StartedAt}
+			if previous, ok := t.last[inst.ID]; ok && previous != current {
+				restartedAt := inst.StartedAt
+				if restartedAt <= 0 || restartedAt > now.Unix() {
+					restartedAt = now.Unix()
+				}
+				t.restarts[inst.ID] = append(t.restarts[inst.ID], restartedAt)
+			}
+			t.last[inst.ID] = current
+			if inst.StartedAt > 0 && inst.StartedAt <= now.Unix() {
+				reports[i].UptimeSeconds = now.Unix() - inst.StartedAt
+			}
+		}
+
+		history := t.restarts[inst.ID]
+		for len(history) > 0 && history[0] < cutoff {
+			history = history[1:]
+		}
+		if len(history) > coreRestartMaxRecords {
+			history = history[len(history)-coreRestartMaxRecords:]
+		}
+		t.restarts[inst.ID] = history
+		if len(history) > 0 {
+			reports[i].RestartTimes = append([]int64(nil), history...)
+		}
+	}
+	for id := range t.last {
+		if _, ok := seen[id]; !ok {
+			delete(t.last, id)
+			delete(t.restarts, id)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	agentv1 "github.com/creamcroissant/xboard/pkg/pb/agent/v1"
+)
+
+// defaultDeviceLimitSuspendDuration 面板未下发在线窗口时的暂停时长，与面板默认在线窗口一致。
+// 暂停期间旧连接随用户移除而断开，窗口过后在线记录过期、用户自动恢复。
+const defaultDeviceLimitSuspendDuration = 5 * time.Minute
+
+// reportAliveDevices 上报访问日志中观察到的在线设备，并对面板判定超限的用户执行限制。
+func (a *Agent) reportAliveDevices(ctx context.Context) {
+	if a.access == nil {
+		return
+	}
+	observed := a.access.TakeAliveDevices()
+	devices := make([]*agentv1.AliveDevice, 0, len(observed))
+	for _, entry := range observed {
+		userID, ok := a.resolveUserIDByUID(entry.UserEmail)
+		if !ok {
+			continue
+		}
+		devices = append(devices, &agentv1.AliveDevice{UserId: userID, Ip: entry.SourceIP})
+	}
+	if len(devices) == 0 {
+		a.enforceDeviceLimit(ctx, nil)
+		return
+	}
+	resp, err := a.grpc.ReportAlive(ctx, devices)
+	if err != nil {
+		slog.Error("Failed to report alive devices", "error", err, "count", len(devices))
+		return
+	}
+	if window := resp.GetDeviceOnlineWindowSeconds(); window > 0 {
+		a.suspendDuration = time.Duration(window) * time.Second
+	}
+	a.enforceDeviceLimit(ctx, resp.GetOverLimitUserIds())
+}
+
+// deviceLimitSuspendDuration 返回超限用户的暂停时长：优先使用面板的 device_online_window_seconds，
+// 保证暂停在面板的在线记录过期时同步解除。
+func (a *Agent) deviceLimitSuspendDuration() time.Duration {
+	if a.suspendDuration > 0 {
+		return a.suspendDuration
+	}
+	return defaultDeviceLimitSuspendDuration
+}
+
+// enforceDeviceLimit 暂停新增的超限用户并恢复暂停到期的用户；集合变化时重新注入用户列表，
+// 内核重载会断开被暂停用户的全部连接。suspendedUsers 与 syncedUsers 只由 Run 循环读写：
+// 上报与同步（包括重连后触发的那次）都在该循环中串行执行，因此无需加锁。
+func (a *Agent) enforceDeviceLimit(ctx context.Context, overLimit []int64) {
+	now := time.Now()
+	suspend := a.deviceLimitSuspendDuration()
+	changed := false
+	for userID, until := range a.suspendedUsers {
+		if now.After(until) {
+			delete(a.suspendedUsers, userID)
+			changed = true
+		}
+	}
+	for _, userID := range overLimit {
+		if userID <= 0 {
+			continue
+		}
+		if a.suspendedUsers == nil {
+			a.suspendedUsers = make(map[int64]time.Time)
+		}
+		if _, ok := a.suspendedUsers[userID]; !ok {
+			changed = true
+		}
+		a.suspendedUsers[userID] = now.Add(suspend)
+	}
+	if !changed || a.syncedUsers == nil {
+		return
+	}
+
+	users := make([]*agentv1.UserInfo, 0, len(a.syncedUsers))
+	for _, u := range a.syncedUsers {
+		users = append(users, u)
+	}
+	if err := a.applyUsers(ctx, users); err != nil {
+		slog.Error("Failed to apply device limit", "error", err)
+		return
+	}
+	slog.Info("Applied device limit", "suspended", len(a.suspendedUsers))
+}
+
+// isSuspended 判断用户是否因超出设备数限制而被暂停。
+func (a *Agent) isSuspended(userID int64) bool {
+	_, ok := a.suspendedUsers[userID]
+	return ok
+}
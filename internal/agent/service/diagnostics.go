@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"time"
+
+	agentv1 "github.com/creamcroissant/xboard/pkg/pb/agent/v1"
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+const (
+	// diagnosticsInterval 诊断包的上报间隔，远慢于常规指标上报。
+	diagnosticsInterval = 10 * time.Minute
+	// diagnosticsValidateTimeout 限制配置校验命令的耗时，避免拖慢状态上报。
+	diagnosticsValidateTimeout = 10 * time.Second
+	// diagnosticsMaxText 截断错误文本，防止超长输出撑大上报。
+	diagnosticsMaxText = 2048
+)
+
+var (
+	diagnosticsSecretPattern = regexp.MustCompile(`(?i)(\b(?:access[_-]?token|refresh[_-]?token|communication[_-]?key|private[_-]?key|privatekey|api[_-]?key|password|passwd|secret|token|key|uuid)\b"?\s*[:=]\s*)(["']?)[^"',\s;&}]+(["']?)`)
+	diagnosticsBearerPattern = regexp.MustCompile(`(?i)(authorization:?\s+(?:bearer|basic)\s+)[^\s,;]+`)
+	diagnosticsUUIDPattern   = regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`)
+)
+
+// diagnosticsDue 判断本次状态上报是否需要附带诊断包。
+func (a *Agent) diagnosticsDue(now time.Time) bool {
+	return a.diagnosticsAt == 0 || now.Unix()-a.diagnosticsAt >= int64(diagnosticsInterval/time.Second)
+}
+
+// collectDiagnostics 采集自诊断信息。除配置校验外均为本地读取，校验命令受超时限制。
+func (a *Agent) collectDiagnostics(ctx context.Context, now time.Time) *agentv1.AgentDiagnostics {
+	diag := &agentv1.AgentDiagnostics{
+		InitSystem:       a.initSysType,
+		LastApplyError:   redactDiagnosticText(a.lastApplyError),
+		LastApplyErrorAt: a.lastApplyErrorAt,
+		ConfigDir:        a.cfg.Protocol.ConfigDir,
+		OpenFds:          -1,
+		MaxFds:           -1,
+		CollectedAt:      now.Unix(),
+	}
+	if a.cachedCaps != nil {
+		diag.CoreType = a.cachedCaps.CoreType
+		diag.CoreVersion = a.cachedCaps.CoreVersion
+	}
+	if a.updater != nil {
+		diag.AgentVersion = a.updater.Status().CurrentVersion
+	}
+
+	if a.protoMgr != nil {
+		validateCtx, cancel := context.WithTimeout(ctx, diagnosticsValidateTimeout)
+		err := a.protoMgr.ValidateConfig(validateCtx)
+		cancel()
+		diag.ConfigValid = err == nil
+		if err != nil {
+			diag.ConfigValidationError = redactDiagnosticText(err.Error())
+		}
+	}
+
+	if diag.ConfigDir != "" {
+		if usage, err := disk.UsageWithContext(ctx, diag.ConfigDir); err == nil {
+			diag.ConfigDirFreeBytes = usage.Free
+			diag.ConfigDirTotalBytes = usage.Total
+		}
+	}
+
+	if proc, err := process.NewProcessWithContext(ctx, int32(os.Getpid())); err == nil {
+		if fds, err := proc.NumFDsWithContext(ctx); err == nil {
+			diag.OpenFds = int64(fds)
+		}
+		if limits, err := proc.RlimitWithContext(ctx); err == nil {
+			for _, limit := range limits {
+				if limit.Resource == process.RLIMIT_NOFILE {
+					diag.MaxFds = int64(limit.Soft)
+					break
+				}
+			}
+		}
+	}
+	return diag
+}
+
+// redactDiagnosticText 去除错误文本中的令牌、密钥与用户 UUID，并限制长度。
+func redactDiagnosticText(text string) string {
+	if text == "" {
+		return ""
+	}
+	text = diagnosticsBearerPattern.ReplaceAllString(text, `${1}[REDACTED]`)
+	text = diagnosticsSecretPattern.ReplaceAllString(text, `${1}${2}[REDACTED]${3}`)
+	text = diagnosticsUUIDPattern.ReplaceAllString(text, "[REDACTED]")
+	if len(text) > diagnosticsMaxText {
+		text = text[:diagnosticsMaxText] + "...(truncated)"
+	}
+	return text
+}
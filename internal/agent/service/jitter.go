@@ -0,0 +1,66 @@
+package service
+
+import (
+	"hash/fnv"
+	"math/rand/v2"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/agent/config"
+)
+
+// jitterTicker fires every period like time.Ticker, but the first tick after creation or
+// Reset is delayed by an extra phase offset so agents on the same interval spread out.
+type jitterTicker struct {
+	timer  *time.Timer
+	period time.Duration
+}
+
+func newJitterTicker(period, offset time.Duration) *jitterTicker {
+	return &jitterTicker{timer: time.NewTimer(period + offset), period: period}
+}
+
+// C returns the channel ticks are delivered on; call Next after each receive.
+func (t *jitterTicker) C() <-chan time.Time {
+	return t.timer.C
+}
+
+// Next re-arms the ticker for the following period. Re-arming right after the receive keeps
+// the phase offset, only drifting by scheduling latency.
+func (t *jitterTicker) Next() {
+	t.timer.Reset(t.period)
+}
+
+// Reset changes the period and applies a new phase offset to the next tick.
+func (t *jitterTicker) Reset(period, offset time.Duration) {
+	t.period = period
+	t.timer.Reset(period + offset)
+}
+
+func (t *jitterTicker) Stop() {
+	t.timer.Stop()
+}
+
+// intervalJitter returns the phase offset for a ticker of the given interval. In stable mode
+// the offset is derived from the host token and ticker name, so it is the same across restarts;
+// before the agent has registered (no token yet) it falls back to a random offset.
+func intervalJitter(cfg config.IntervalConfig, hostToken, name string, interval time.Duration) time.Duration {
+	if cfg.Jitter <= 0 || interval <= 0 {
+		return 0
+	}
+	var fraction float64
+	if cfg.JitterMode == config.IntervalJitterRandom || hostToken == "" {
+		fraction = rand.Float64()
+	} else {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(hostToken))
+		_, _ = h.Write([]byte{0})
+		_, _ = h.Write([]byte(name))
+		fraction = float64(h.Sum64()>>11) / (1 << 53)
+	}
+	return time.Duration(fraction * cfg.Jitter * float64(interval))
+}
+
+// tickerJitter computes the offset for one of the agent's sync/report tickers.
+func (a *Agent) tickerJitter(name string, interval time.Duration) time.Duration {
+	return intervalJitter(a.cfg.Interval, a.cfg.Panel.HostToken, name, interval)
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/agent/config"
+)
+
+func TestIntervalJitter(t *testing.T) {
+	interval := time.Minute
+	cfg := config.IntervalConfig{Jitter: 0.2, JitterMode: config.IntervalJitterStable}
+
+	first := intervalJitter(cfg, "host-token", "report", interval)
+	if again := intervalJitter(cfg, "host-token", "report", interval); again != first {
+		t.Fatalf("stable jitter changed between calls: %v != %v", first, again)
+	}
+	if first < 0 || first >= 12*time.Second {
+		t.Fatalf("stable jitter = %v, want within [0, 12s)", first)
+	}
+
+	cfg.JitterMode = config.IntervalJitterRandom
+	for i := 0; i < 100; i++ {
+		if offset := intervalJitter(cfg, "host-token", "report", interval); offset < 0 || offset >= 12*time.Second {
+			t.Fatalf("random jitter = %v, want within [0, 12s)", offset)
+		}
+	}
+
+	cfg.Jitter = 0
+	if offset := intervalJitter(cfg, "host-token", "report", interval); offset != 0 {
+		t.Fatalf("disabled jitter = %v, want 0", offset)
+	}
+}
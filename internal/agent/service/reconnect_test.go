@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/creamcroissant/xboard/internal/agent/transport"
+	agentv1 "github.com/creamcroissant/xboard/pkg/pb/agent/v1"
+)
+
+// TestReconnectDuringSyncOnlySignalsMainLoop 在同步修改用户快照期间触发重连，
+// 配合 go test -race 确认重连回调不会在 Run 循环之外读写用户状态。
+func TestReconnectDuringSyncOnlySignalsMainLoop(t *testing.T) {
+	a := &Agent{
+		syncedUsers:   map[int64]*agentv1.UserInfo{1: {UserId: 1, Email: "a@example.com"}},
+		userIDByEmail: make(map[string]int64),
+		reconnectCh:   make(chan struct{}, 1),
+	}
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		// 模拟 Run 循环中的用户增量同步，每次都会写入 syncedUsers
+		for i := int64(0); i < 200; i++ {
+			if err := a.applyUserDelta(ctx, &agentv1.UsersResponse{RemovedUserIds: []int64{i + 100}}); err != nil {
+				t.Errorf("apply user delta: %v", err)
+				return
+			}
+		}
+	}()
+	for i := 0; i < 200; i++ {
+		a.onConnectionStateChange(transport.StateConnected)
+	}
+	wg.Wait()
+
+	if got := len(a.reconnectCh); got != 1 {
+		t.Fatalf("pending reconnect signals = %d, want 1", got)
+	}
+}
@@ -3,16 +3,16 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
-	"github.com/google/uuid"
-
 	"github.com/creamcroissant/xboard/internal/agent/access"
 	"github.com/creamcroissant/xboard/internal/agent/api"
 	"github.com/creamcroissant/xboard/internal/agent/capability"
@@ -32,6 +32,7 @@ import (
 	"github.com/creamcroissant/xboard/internal/agent/syncer"
 	"github.com/creamcroissant/xboard/internal/agent/traffic"
 	"github.com/creamcroissant/xboard/internal/agent/transport"
+	"github.com/creamcroissant/xboard/internal/support/security"
 	agentv1 "github.com/creamcroissant/xboard/pkg/pb/agent/v1"
 )
 
@@ -47,6 +48,8 @@ type Agent struct {
 	forward         *forwarding.Manager
 	syncer          *syncer.Syncer
 	monitor         *monitor.Monitor
+	connCounter     *monitor.ConnectionCounter
+	latencyProber   *monitor.LatencyProber
 	traffic         traffic.Collector
 	netio           *traffic.NetIOCollector // Node-level network traffic
 	access          *access.Manager         // Access log manager
@@ -67,17 +70,38 @@ type Agent struct {
 	batchSyncInFlight         atomic.Bool
 	coreOperationSyncInFlight atomic.Bool
 
-	configETag     string
-	usersETag      string
-	userEmailMu    sync.RWMutex
-	userIDByEmail  map[string]int64
-	cachedCaps     *capability.DetectedCapabilities // Cached capabilities
-	capsDetectedAt int64                            // Last capability detection time
+	configETag       string
+	failedConfigETag string // 最近一次应用失败的配置版本，避免重复应用同一份坏配置
+	// signedConfigVersion 为最近一次通过签名校验的配置版本，拒绝更早版本的重放
+	signedConfigVersion int64
+	// signedHostID 为签名内容绑定的节点 ID，未在配置中指定时取首个通过校验的值
+	signedHostID     atomic.Int64
+	usersETag        string
+	syncedUsers      map[int64]*agentv1.UserInfo // Users last applied, keyed by user id; owned by the Run loop
+	userEmailMu      sync.RWMutex
+	userIDByEmail    map[string]int64
+	suspendedUsers   map[int64]time.Time              // Users over device limit, suspended until the given time
+	pendingTraffic   pendingTraffic                   // Traffic not yet acknowledged by the panel; owned by the Run loop
+	suspendDuration  time.Duration                    // Online-device window reported by the panel; 0 uses the default
+	cachedCaps       *capability.DetectedCapabilities // Cached capabilities
+	capsDetectedAt   int64                            // Last capability detection time
+	capsFingerprint  string                           // Core binary fingerprint at last detection
+	capsRedetect     atomic.Bool                      // Panel-requested capability re-detection
+	forceResync      atomic.Bool                      // Panel-requested resync that ignores cached ETags
+	capsChange       *agentv1.CoreVersionChange       // Version change waiting to be reported
+	initSysType      string                           // Detected init system, for diagnostics
+	lastApplyError   string                           // Last config apply error, cleared on success
+	lastApplyErrorAt int64                            // Time of the last config apply error
+	diagnosticsAt    int64                            // Last time diagnostics were reported
+	coreProcs        coreProcessSampler               // Per-core process CPU/RSS sampler
+	coreRestarts     coreRestartTracker               // Per-core restart history and uptime
 
 	// Dynamic intervals
 	currentSyncInterval   atomic.Int32
 	currentReportInterval atomic.Int32
 	updateTickerCh        chan struct{}
+	resyncCh              chan struct{} // Panel-requested immediate sync
+	reconnectCh           chan struct{} // Sync and report requested after the gRPC connection recovers
 }
 
 type applyBatchRunner interface {
@@ -95,7 +119,8 @@ func New(cfg *config.Config) (*Agent, error) {
 	// Initialize NetIO collector for node-level traffic
 	var netioCollector *traffic.NetIOCollector
 	if cfg.Traffic.Type == "netio" {
-		netioCollector = traffic.NewNetIOCollector(cfg.Traffic.Interface)
+		netioCollector = traffic.NewNetIOCollector(append([]string{cfg.Traffic.Interface}, cfg.Traffic.Interfaces...)...)
+		netioCollector.SetStatePath(cfg.Traffic.StatePath)
 	}
 
 	// Initialize init system
@@ -118,6 +143,7 @@ func New(cfg *config.Config) (*Agent, error) {
 	}
 	initSysSingBox := initSys
 	initSysXray := initSys
+	initSysMieru := initSys
 	if generic, ok := initSys.(*initsys.Generic); ok {
 		singInit := *generic
 		singInit.BinaryPath = cfg.Core.SingBoxBinaryPath
@@ -128,6 +154,12 @@ func New(cfg *config.Config) (*Agent, error) {
 		xrayInit.BinaryPath = cfg.Core.XrayBinaryPath
 		xrayInit.Args = []string{"run", "-config", filepath.Join(cfg.Protocol.ConfigDir, "config.json")}
 		initSysXray = &xrayInit
+
+		// mita 以守护进程运行，配置通过 "mita apply config" 下发
+		mieruInit := *generic
+		mieruInit.BinaryPath = cfg.Core.MieruBinaryPath
+		mieruInit.Args = []string{"run"}
+		initSysMieru = &mieruInit
 	}
 
 	// Initialize protocol manager
@@ -142,13 +174,19 @@ func New(cfg *config.Config) (*Agent, error) {
 		AutoRestart:      cfg.Protocol.AutoRestart,
 		PreHook:          cfg.Protocol.PreHook,
 		PostHook:         cfg.Protocol.PostHook,
+		SnapshotKeep:     cfg.Protocol.SnapshotKeep,
+		ApplyGracePeriod: cfg.Protocol.ApplyGracePeriod,
+		HealthCheckCmd:   cfg.Protocol.HealthCheckCmd,
+		DrainTimeout:     cfg.Protocol.DrainTimeout,
 	}
 	protoMgr := protocol.NewManager(protoCfg, initSys)
 
 	capDet := capability.NewDetector(cfg.Core.SingBoxBinaryPath, cfg.Core.XrayBinaryPath)
+	capDet.SetMieruPath(cfg.Core.MieruBinaryPath)
 	coreMgr := core.NewManager()
 	coreMgr.Register(core.NewSingBoxCore(initSysSingBox, capDet, cfg.Protocol.ServiceName, cfg.Protocol.ConfigDir))
 	coreMgr.Register(core.NewXrayCore(initSysXray, capDet, cfg.Protocol.ServiceName, cfg.Traffic.Address, cfg.Protocol.ConfigDir))
+	coreMgr.Register(core.NewMieruCore(initSysMieru, capDet, cfg.Core.MieruBinaryPath, cfg.Core.MieruServiceName, cfg.Core.MieruConfigDir))
 
 	var switcher *proxy.Switcher
 	if cfg.Proxy.Enabled {
@@ -178,6 +216,7 @@ func New(cfg *config.Config) (*Agent, error) {
 			return nil, err
 		}
 		switcher = created
+		protoMgr.SetDrainRestarter(switcher)
 	}
 
 	var srv *server.Server
@@ -211,9 +250,16 @@ func New(cfg *config.Config) (*Agent, error) {
 		capDet:   capDet,
 		updater:  agentUpdater,
 
+		initSysType: initSys.Type(),
+
 		userIDByEmail:  make(map[string]int64),
 		updateTickerCh: make(chan struct{}, 1),
+		resyncCh:       make(chan struct{}, 1),
+		reconnectCh:    make(chan struct{}, 1),
 	}
+	agent.monitor.SetWatchPaths(cfg.Monitor.WatchPaths)
+	agent.connCounter = monitor.NewConnectionCounter(cfg.Monitor.ClashAPI, cfg.Monitor.ClashSecret)
+	agent.latencyProber = monitor.NewLatencyProber(cfg.Monitor.ProbeTarget, 0)
 	agent.currentSyncInterval.Store(int32(cfg.Interval.Sync))
 	agent.currentReportInterval.Store(int32(cfg.Interval.Report))
 
@@ -277,18 +323,7 @@ func New(cfg *config.Config) (*Agent, error) {
 		slog.Info("CDN management enabled", "bin_path", cfg.CDN.BinPath, "config_dir", cfg.CDN.ConfigDir)
 	}
 	agent.conn = transport.NewConnectionManager(grpcClient, slog.Default())
-	agent.conn.SetOnStateChange(func(state transport.ConnectionState) {
-		slog.Info("grpc connection state changed", "state", state.String())
-		if state == transport.StateConnected {
-			// Trigger immediate sync and report when connected
-			go func() {
-				ctx, cancel := context.WithTimeout(context.Background(), 1*time.Minute)
-				defer cancel()
-				agent.sync(ctx)
-				agent.report(ctx)
-			}()
-		}
-	})
+	agent.conn.SetOnStateChange(agent.onConnectionStateChange)
 	if agent.cfg.Forwarding.Enabled {
 		interval := agent.cfg.Forwarding.SyncInterval
 		executor := forwarding.NewNFTablesExecutor(agent.cfg.Forwarding.TableName, agent.cfg.Forwarding.NftBin)
@@ -373,11 +408,16 @@ func (a *Agent) Run(ctx context.Context) {
 		a.commandQueue.Start(ctx)
 	}
 
+	// Listen for panel-pushed commands (e.g. resync)
+	go a.watchStatusCommands(ctx)
+
 	// Initial sync
 	a.sync(ctx)
 
-	syncTicker := time.NewTicker(time.Duration(a.currentSyncInterval.Load()) * time.Second)
-	reportTicker := time.NewTicker(time.Duration(a.currentReportInterval.Load()) * time.Second)
+	syncInterval := time.Duration(a.currentSyncInterval.Load()) * time.Second
+	reportInterval := time.Duration(a.currentReportInterval.Load()) * time.Second
+	syncTicker := newJitterTicker(syncInterval, a.tickerJitter("sync", syncInterval))
+	reportTicker := newJitterTicker(reportInterval, a.tickerJitter("report", reportInterval))
 
 	defer syncTicker.Stop()
 	defer reportTicker.Stop()
@@ -413,19 +453,41 @@ func (a *Agent) Run(ctx context.Context) {
 			}
 			return
 		case <-a.updateTickerCh:
-			syncInterval := a.currentSyncInterval.Load()
-			reportInterval := a.currentReportInterval.Load()
-			slog.Info("Updating intervals", "sync", syncInterval, "report", reportInterval)
-			syncTicker.Reset(time.Duration(syncInterval) * time.Second)
-			reportTicker.Reset(time.Duration(reportInterval) * time.Second)
-		case <-syncTicker.C:
+			syncInterval := time.Duration(a.currentSyncInterval.Load()) * time.Second
+			reportInterval := time.Duration(a.currentReportInterval.Load()) * time.Second
+			syncJitter := a.tickerJitter("sync", syncInterval)
+			reportJitter := a.tickerJitter("report", reportInterval)
+			slog.Info("Updating intervals", "sync", syncInterval, "report", reportInterval, "sync_jitter", syncJitter, "report_jitter", reportJitter)
+			syncTicker.Reset(syncInterval, syncJitter)
+			reportTicker.Reset(reportInterval, reportJitter)
+		case <-syncTicker.C():
+			syncTicker.Next()
+			a.sync(ctx)
+		case <-a.resyncCh:
+			a.sync(ctx)
+		case <-a.reconnectCh:
 			a.sync(ctx)
-		case <-reportTicker.C:
+			a.report(ctx)
+		case <-reportTicker.C():
+			reportTicker.Next()
 			a.report(ctx)
 		}
 	}
 }
 
+// onConnectionStateChange 在连接恢复后请求立即同步并上报。
+// 同步与上报读写用户快照等状态，只能由 Run 循环串行执行，这里仅投递信号。
+func (a *Agent) onConnectionStateChange(state transport.ConnectionState) {
+	slog.Info("grpc connection state changed", "state", state.String())
+	if state != transport.StateConnected {
+		return
+	}
+	select {
+	case a.reconnectCh <- struct{}{}:
+	default:
+	}
+}
+
 func (a *Agent) sync(ctx context.Context) {
 	if !a.beginSync() {
 		slog.Debug("sync already in flight, skip re-entry")
@@ -452,45 +514,74 @@ func (a *Agent) syncGRPC(ctx context.Context) {
 	// NodeID kept for compatibility; gRPC identifies agent host by token
 	nodeID := int32(a.cfg.Panel.NodeID)
 
-	// Fetch Config via gRPC
-	cfgResp, err := a.grpc.GetConfig(ctx, nodeID, a.configETag)
+	if a.forceResync.Swap(false) {
+		// 退出维护模式：清空缓存的版本号，强制重新应用面板配置与全量用户
+		a.configETag = ""
+		a.failedConfigETag = ""
+		a.usersETag = ""
+		a.syncedUsers = nil
+	}
+
+	// Fetch Config via gRPC; after a failed apply send the failed ETag so the
+	// panel answers NotModified until the config actually changes again
+	requestETag := a.configETag
+	if a.failedConfigETag != "" {
+		requestETag = a.failedConfigETag
+	}
+	cfgResp, err := a.grpc.GetConfig(ctx, nodeID, requestETag)
 	if err != nil {
 		slog.Error("Failed to fetch config via gRPC", "error", err)
 		return
 	}
 
-	if !cfgResp.NotModified {
-		a.configETag = cfgResp.Etag
-		slog.Info("Config updated via gRPC", "version", cfgResp.Version)
-		// Apply new config
-		if len(cfgResp.ConfigJson) > 0 {
-			if err := a.protoMgr.ApplyConfigWithCore(ctx, "", "config.json", cfgResp.ConfigJson); err != nil {
-				slog.Error("Failed to apply config", "error", err)
-			} else {
-				slog.Info("Successfully applied new config", "version", cfgResp.Version)
-			}
+	if !cfgResp.Success && len(cfgResp.ValidationIssues) > 0 {
+		// 面板生成的配置未通过校验，保留当前配置与 ETag，仅记录问题位置
+		for _, issue := range cfgResp.ValidationIssues {
+			slog.Error("Panel config failed validation", "path", issue.Path, "severity", issue.Severity, "message", issue.Message)
 		}
+	} else if !cfgResp.NotModified {
+		slog.Info("Config updated via gRPC", "version", cfgResp.Version)
+		a.applySyncedConfig(ctx, cfgResp)
 	}
 
-	// Fetch Users via gRPC
-	usersResp, err := a.grpc.GetUsers(ctx, nodeID, a.usersETag, 0)
+	// Fetch Users via gRPC; ask for the full list until we hold a local snapshot
+	usersResp, err := a.grpc.GetUsers(ctx, nodeID, a.usersETag, 0, a.syncedUsers == nil)
 	if err != nil {
 		slog.Error("Failed to fetch users via gRPC", "error", err)
 		return
 	}
 
-	if !usersResp.NotModified {
+	if usersResp.NotModified {
+		return
+	}
+	if usersResp.Incremental && a.syncedUsers != nil {
+		if err := a.applyUserDelta(ctx, usersResp); err != nil {
+			// 增量失败时清空快照，下次同步改拉全量
+			slog.Error("Failed to apply user delta", "error", err)
+			a.syncedUsers = nil
+			a.usersETag = ""
+			return
+		}
 		a.usersETag = usersResp.Etag
-		a.refreshUserEmailMapping(usersResp.Users)
-		slog.Info("Users updated via gRPC", "count", len(usersResp.Users))
+		slog.Info("Applied incremental user update",
+			"added", len(usersResp.Added),
+			"changed", len(usersResp.Changed),
+			"removed", len(usersResp.RemovedUserIds),
+		)
+		return
+	}
 
-		// Convert users to protocol.UserConfig and inject into config
-		if err := a.applyUsers(ctx, usersResp.Users); err != nil {
-			slog.Error("Failed to apply users", "error", err)
-		} else {
-			slog.Info("Successfully applied users to config", "count", len(usersResp.Users))
-		}
+	a.refreshUserEmailMapping(usersResp.Users)
+	slog.Info("Users updated via gRPC", "count", len(usersResp.Users))
+
+	// Convert users to protocol.UserConfig and inject into config
+	if err := a.applyUsers(ctx, usersResp.Users); err != nil {
+		slog.Error("Failed to apply users", "error", err)
+		return
 	}
+	a.usersETag = usersResp.Etag
+	a.syncedUsers = indexSyncedUsers(usersResp.Users)
+	slog.Info("Successfully applied users to config", "count", len(usersResp.Users))
 }
 
 func (a *Agent) report(ctx context.Context) {
@@ -519,6 +610,23 @@ func (a *Agent) report(ctx context.Context) {
 	stat.TrafficUpload = trafficUpload
 	stat.TrafficDownload = trafficDownload
 
+	if a.connCounter != nil {
+		if conns, err := a.connCounter.Count(ctx); err != nil {
+			slog.Debug("Connection count unavailable", "error", err)
+		} else {
+			stat.ConnectionCount = conns.Count
+			stat.ConnectionSource = conns.Source
+		}
+	}
+
+	if a.latencyProber != nil {
+		if latency, err := a.latencyProber.Probe(ctx); err != nil {
+			slog.Warn("Latency probe failed", "error", err)
+		} else {
+			stat.LatencyMs = latency
+		}
+	}
+
 	if a.conn != nil {
 		state := a.conn.CheckConnection(ctx)
 		if state != transport.StateConnected {
@@ -530,6 +638,9 @@ func (a *Agent) report(ctx context.Context) {
 
 	// 3. User-level Traffic (from traffic collector, e.g., xray_api)
 	a.reportUserTraffic(ctx)
+
+	// 4. Online devices (from access logs), enforcing device limits
+	a.reportAliveDevices(ctx)
 }
 
 func (a *Agent) reportGRPC(ctx context.Context, stat api.StatusPayload) {
@@ -548,13 +659,17 @@ func (a *Agent) reportGRPC(ctx context.Context, stat api.StatusPayload) {
 			DiskTotal:       float64(stat.Disk.Total),
 			DiskUsed:        float64(stat.Disk.Used),
 			UptimeSeconds:   int64(stat.Uptime),
-			ConnectionCount: 0, // TODO: Get connection count if available
+			ConnectionCount: int32(stat.ConnectionCount),
 			Load1:           stat.Load1,
 			Load5:           stat.Load5,
 			Load15:          stat.Load15,
 			ProcessCount:    int32(stat.ProcessCount),
 			TcpCount:        int32(stat.TcpCount),
 			UdpCount:        int32(stat.UdpCount),
+			PathUsage:       pathUsageProto(stat.PathUsage),
+			// 连接数来源为空时面板显示为不可用
+			ConnectionCountSource: stat.ConnectionSource,
+			ProbeLatencyMs:        int32(stat.LatencyMs),
 			// Core capabilities
 			CoreVersion:  caps.CoreVersion,
 			Capabilities: caps.Capabilities,
@@ -568,11 +683,21 @@ func (a *Agent) reportGRPC(ctx context.Context, stat api.StatusPayload) {
 		},
 		CommandQueue: a.commandQueueStatsProto(),
 		UpdateStatus: a.updateStatusProto(),
+		ReloadDrain:  reloadDrainProto(a.protoMgr.TakeDrainResult()),
+		// 版本变更只上报一次，发送失败时保留到下一次上报
+		CoreVersionChange: a.capsChange,
+	}
+
+	if now := time.Now(); a.diagnosticsDue(now) {
+		statusReport.Diagnostics = a.collectDiagnostics(ctx, now)
 	}
 
 	// Add core instances
 	if a.coreMgr != nil {
-		statusReport.Instances = buildCoreInstanceReport(a.coreMgr.ListInstances())
+		coreInstances := a.coreMgr.ListInstances()
+		statusReport.Instances = buildCoreInstanceReport(coreInstances)
+		a.coreProcs.annotate(ctx, coreInstances, statusReport.Instances)
+		a.coreRestarts.annotate(coreInstances, statusReport.Instances, time.Now())
 	}
 
 	if a.inventoryScanner != nil {
@@ -610,6 +735,7 @@ func (a *Agent) reportGRPC(ctx context.Context, stat api.StatusPayload) {
 						Port:       int32(p.Port),
 						SourceFile: p.SourceFile,
 						CoreType:   p.CoreType,
+						Encryption: p.Encryption,
 					}
 
 					// Transport config
@@ -628,6 +754,7 @@ func (a *Agent) reportGRPC(ctx context.Context, stat api.StatusPayload) {
 							Enabled:    p.TLS.Enabled,
 							ServerName: p.TLS.ServerName,
 							Alpn:       p.TLS.ALPN,
+							EchEnabled: p.TLS.ECHEnabled,
 						}
 						if p.TLS.Reality != nil {
 							detail.Tls.Reality = &agentv1.RealityConfig{
@@ -749,6 +876,12 @@ func (a *Agent) reportGRPC(ctx context.Context, stat api.StatusPayload) {
 			return
 		}
 		a.confirmUpdaterHealthy()
+		if statusReport.CoreVersionChange != nil && a.capsChange == statusReport.CoreVersionChange {
+			a.capsChange = nil
+		}
+		if statusReport.Diagnostics != nil {
+			a.diagnosticsAt = statusReport.Diagnostics.GetCollectedAt()
+		}
 		slog.Debug("Reported status via gRPC",
 			"traffic_up", stat.TrafficUpload,
 			"traffic_down", stat.TrafficDownload,
@@ -859,58 +992,6 @@ func (a *Agent) setApplyRevision(revision int64) {
 	a.applyRevision.Store(revision)
 }
 
-func (a *Agent) reportUserTraffic(ctx context.Context) {
-	// Use gRPC for traffic reporting
-	samples, err := a.traffic.Collect(ctx)
-	if err != nil {
-		slog.Error("Failed to collect traffic", "error", err)
-		return
-	}
-
-	if len(samples) == 0 {
-		return
-	}
-
-	// Convert to protobuf format
-	userTraffic := make([]*agentv1.UserTraffic, 0, len(samples))
-	unmapped := 0
-	for _, s := range samples {
-		userID := s.UserID
-		if userID <= 0 {
-			if mappedID, ok := a.resolveUserIDByUID(s.UID); ok {
-				userID = mappedID
-			}
-		}
-		if userID <= 0 {
-			unmapped++
-			continue
-		}
-		if s.Upload == 0 && s.Download == 0 {
-			continue
-		}
-
-		userTraffic = append(userTraffic, &agentv1.UserTraffic{
-			UserId:        userID,
-			UploadBytes:   s.Upload,
-			DownloadBytes: s.Download,
-		})
-	}
-
-	if len(userTraffic) == 0 {
-		if unmapped > 0 {
-			slog.Warn("Skip traffic samples due to unresolved user mapping", "unmapped", unmapped, "samples", len(samples))
-		}
-		return
-	}
-
-	reportID := strings.ToLower(strings.ReplaceAll(uuid.NewString(), "-", ""))
-	if _, err := a.grpc.ReportTraffic(ctx, userTraffic, reportID); err != nil {
-		slog.Error("Failed to push traffic via gRPC", "error", err, "report_id", reportID)
-	} else {
-		slog.Debug("Pushed traffic samples via gRPC", "count", len(userTraffic), "source_samples", len(samples), "unmapped", unmapped, "report_id", reportID)
-	}
-}
-
 func normalizeUserEmail(email string) string {
 	email = strings.TrimSpace(email)
 	if email == "" {
@@ -994,13 +1075,23 @@ func (a *Agent) determineListenPort() []int {
 }
 
 // getCapabilities returns cached or fresh capabilities
-// Capabilities are cached for 1 hour to avoid excessive command executions
+// Capabilities are cached for 1 hour to avoid excessive command executions.
+// The cache is dropped early when the core binary changes on disk or the panel
+// requests re-detection, so in-place upgrades show up on the next report.
 func (a *Agent) getCapabilities(ctx context.Context) *capability.DetectedCapabilities {
 	now := time.Now().Unix()
 	cacheExpiry := int64(3600) // 1 hour
 
+	fingerprint := a.capDet.BinaryFingerprint()
+	reason := ""
+	if a.capsRedetect.Swap(false) {
+		reason = "forced"
+	} else if a.cachedCaps != nil && fingerprint != a.capsFingerprint {
+		reason = "binary_changed"
+	}
+
 	// Return cached if still valid
-	if a.cachedCaps != nil && now-a.capsDetectedAt < cacheExpiry {
+	if reason == "" && a.cachedCaps != nil && now-a.capsDetectedAt < cacheExpiry {
 		return a.cachedCaps
 	}
 
@@ -1016,9 +1107,31 @@ func (a *Agent) getCapabilities(ctx context.Context) *capability.DetectedCapabil
 		}
 	}
 
+	// Record a version change for the panel before replacing the cache
+	if prev := a.cachedCaps; prev != nil && (prev.CoreType != caps.CoreType || prev.CoreVersion != caps.CoreVersion) {
+		if reason == "" {
+			reason = "binary_changed"
+		}
+		a.capsChange = &agentv1.CoreVersionChange{
+			PreviousCoreType: prev.CoreType,
+			PreviousVersion:  prev.CoreVersion,
+			CoreType:         caps.CoreType,
+			Version:          caps.CoreVersion,
+			Reason:           reason,
+			DetectedAt:       now,
+		}
+		slog.Info("Core version changed",
+			"reason", reason,
+			"previous_core_type", prev.CoreType,
+			"previous_version", prev.CoreVersion,
+			"core_type", caps.CoreType,
+			"version", caps.CoreVersion)
+	}
+
 	// Cache the result
 	a.cachedCaps = caps
 	a.capsDetectedAt = now
+	a.capsFingerprint = fingerprint
 
 	slog.Info("Detected core capabilities",
 		"core_type", caps.CoreType,
@@ -1030,6 +1143,75 @@ func (a *Agent) getCapabilities(ctx context.Context) *capability.DetectedCapabil
 }
 
 // applyUsers converts gRPC UserInfo to protocol.UserConfig and injects them into the config.
+// applyUserDelta 按面板下发的新增/变更/删除增量更新本地用户快照与配置。
+func (a *Agent) applyUserDelta(ctx context.Context, resp *agentv1.UsersResponse) error {
+	removed := make([]protocol.UserConfig, 0, len(resp.RemovedUserIds)+len(resp.Changed))
+	for _, id := range resp.RemovedUserIds {
+		if old, ok := a.syncedUsers[id]; ok {
+			removed = append(removed, toUserConfig(old))
+		}
+	}
+	upserts := make([]protocol.UserConfig, 0, len(resp.Added)+len(resp.Changed))
+	for _, u := range resp.Added {
+		upserts = append(upserts, a.userConfig(u))
+	}
+	for _, u := range resp.Changed {
+		// 变更可能改了邮箱，旧条目也要一并移除
+		if old, ok := a.syncedUsers[u.UserId]; ok {
+			removed = append(removed, toUserConfig(old))
+		}
+		upserts = append(upserts, a.userConfig(u))
+	}
+
+	if err := a.protoMgr.ApplyUserDelta(ctx, removed, upserts); err != nil {
+		return err
+	}
+
+	for _, id := range resp.RemovedUserIds {
+		delete(a.syncedUsers, id)
+	}
+	for _, u := range resp.Added {
+		a.syncedUsers[u.UserId] = u
+	}
+	for _, u := range resp.Changed {
+		a.syncedUsers[u.UserId] = u
+	}
+	users := make([]*agentv1.UserInfo, 0, len(a.syncedUsers))
+	for _, u := range a.syncedUsers {
+		users = append(users, u)
+	}
+	a.refreshUserEmailMapping(users)
+	return nil
+}
+
+func indexSyncedUsers(users []*agentv1.UserInfo) map[int64]*agentv1.UserInfo {
+	index := make(map[int64]*agentv1.UserInfo, len(users))
+	for _, u := range users {
+		if u == nil {
+			continue
+		}
+		index[u.UserId] = u
+	}
+	return index
+}
+
+func toUserConfig(u *agentv1.UserInfo) protocol.UserConfig {
+	return protocol.UserConfig{
+		UUID:    u.Uuid,
+		Email:   u.Email,
+		Enabled: u.Enabled,
+	}
+}
+
+// userConfig 转换用户并对超出设备数限制的用户禁用注入。
+func (a *Agent) userConfig(u *agentv1.UserInfo) protocol.UserConfig {
+	cfg := toUserConfig(u)
+	if a.isSuspended(u.UserId) {
+		cfg.Enabled = false
+	}
+	return cfg
+}
+
 func (a *Agent) applyUsers(ctx context.Context, users []*agentv1.UserInfo) error {
 	if len(users) == 0 {
 		return nil
@@ -1038,11 +1220,7 @@ func (a *Agent) applyUsers(ctx context.Context, users []*agentv1.UserInfo) error
 	// Convert gRPC UserInfo to protocol.UserConfig
 	userConfigs := make([]protocol.UserConfig, 0, len(users))
 	for _, u := range users {
-		userConfigs = append(userConfigs, protocol.UserConfig{
-			UUID:    u.Uuid,
-			Email:   u.Email,
-			Enabled: u.Enabled,
-		})
+		userConfigs = append(userConfigs, a.userConfig(u))
 	}
 
 	// Detect core type and use appropriate injection method
@@ -1063,3 +1241,136 @@ func (a *Agent) applyUsers(ctx context.Context, users []*agentv1.UserInfo) error
 		return nil
 	}
 }
+
+// applySyncedConfig 应用面板下发的配置；失败时记录失败 ETag 并上报，成功后才推进 configETag
+func (a *Agent) applySyncedConfig(ctx context.Context, cfgResp *agentv1.ConfigResponse) {
+	if len(cfgResp.ConfigJson) == 0 {
+		a.configETag = cfgResp.Etag
+		a.failedConfigETag = ""
+		return
+	}
+	if err := a.verifyConfigSignature(cfgResp); err != nil {
+		// 签名不通过时保留当前配置，不记录为失败 ETag，密钥修正后下次同步即可应用
+		slog.Error("Skip applying unverified config", "version", cfgResp.Version, "etag", cfgResp.Etag, "error", err)
+		a.reportConfigApplyFailure(ctx, cfgResp, err)
+		return
+	}
+	if err := a.protoMgr.ApplyConfigWithCore(ctx, "", "config.json", cfgResp.ConfigJson); err != nil {
+		a.failedConfigETag = cfgResp.Etag
+		a.lastApplyError = err.Error()
+		a.lastApplyErrorAt = time.Now().Unix()
+		slog.Error("Failed to apply config", "version", cfgResp.Version, "rolled_back", errors.Is(err, protocol.ErrConfigRolledBack), "error", err)
+		a.reportConfigApplyFailure(ctx, cfgResp, err)
+		return
+	}
+	a.configETag = cfgResp.Etag
+	a.failedConfigETag = ""
+	a.lastApplyError = ""
+	a.lastApplyErrorAt = 0
+	slog.Info("Successfully applied new config", "version", cfgResp.Version)
+}
+
+// verifyConfigSignature 在启用 grpc.config_signing 时校验面板签名；签名覆盖节点 ID、ETag、版本与配置内容，
+// 版本早于上次通过校验的配置时视为重放并拒绝。未启用时直接放行。
+func (a *Agent) verifyConfigSignature(cfgResp *agentv1.ConfigResponse) error {
+	if !a.cfg.GRPC.ConfigSigning.Enabled {
+		return nil
+	}
+	message := security.ConfigSignatureMessage(security.ConfigSignatureScopeConfig, cfgResp.GetAgentHostId(), cfgResp.GetConfigJson(), cfgResp.GetEtag(), strconv.FormatInt(cfgResp.GetVersion(), 10))
+	if err := a.verifySignedPayload("config", cfgResp.GetAgentHostId(), message, cfgResp.GetSignature(), cfgResp.GetKeyId()); err != nil {
+		return err
+	}
+	if cfgResp.GetVersion() < a.signedConfigVersion {
+		return fmt.Errorf("config version %d is older than verified version %d", cfgResp.GetVersion(), a.signedConfigVersion)
+	}
+	a.signedConfigVersion = cfgResp.GetVersion()
+	return nil
+}
+
+// verifySignedPayload 校验签名、密钥 ID 与节点 ID。config_signing.agent_host_id 未配置时，
+// 首个通过校验的节点 ID 会被记住，之后签给其他节点的内容一律拒绝。
+func (a *Agent) verifySignedPayload(kind string, agentHostID int64, message []byte, signature, keyID string) error {
+	signing := a.cfg.GRPC.ConfigSigning
+	if strings.TrimSpace(signature) == "" {
+		return fmt.Errorf("%s signature missing", kind)
+	}
+	if expected := strings.TrimSpace(signing.KeyID); expected != "" && expected != keyID {
+		return fmt.Errorf("%s signed with unexpected key id %q", kind, keyID)
+	}
+	if !security.VerifyConfigSignature(signing.Secret, message, signature) {
+		return fmt.Errorf("%s signature mismatch", kind)
+	}
+	if agentHostID <= 0 {
+		return fmt.Errorf("%s signature does not name an agent host", kind)
+	}
+	expectedHostID := signing.AgentHostID
+	if expectedHostID <= 0 && !a.signedHostID.CompareAndSwap(0, agentHostID) {
+		expectedHostID = a.signedHostID.Load()
+	}
+	if expectedHostID > 0 && expectedHostID != agentHostID {
+		return fmt.Errorf("%s signed for agent host %d, expected %d", kind, agentHostID, expectedHostID)
+	}
+	return nil
+}
+
+// reportConfigApplyFailure 以操作日志事件上报配置应用失败，target 为失败的配置 ETag
+func (a *Agent) reportConfigApplyFailure(ctx context.Context, cfgResp *agentv1.ConfigResponse, applyErr error) {
+	if a.operationEvents == nil || strings.TrimSpace(cfgResp.Etag) == "" {
+		return
+	}
+	phase, message := "failed", "config apply failed"
+	if errors.Is(applyErr, protocol.ErrConfigRolledBack) {
+		phase, message = "rolled_back", "config apply failed, rolled back to previous config"
+	}
+	resp, err := a.operationEvents.ReportOperationEvent(ctx, []*agentv1.OperationEvent{{
+		Scope:      operationEventScopeConfigSync,
+		TargetId:   cfgResp.Etag,
+		Phase:      phase,
+		Level:      operationEventLevelError,
+		Message:    message,
+		OccurredAt: time.Now().Unix(),
+		PayloadJson: encodeOperationEventPayload(map[string]any{
+			"version": cfgResp.Version,
+			"error":   applyErr.Error(),
+		}),
+	}})
+	if err != nil {
+		slog.Warn("failed to report config apply failure", "etag", cfgResp.Etag, "error", err)
+		return
+	}
+	if resp != nil && !resp.GetSuccess() {
+		slog.Warn("panel rejected config apply failure event", "etag", cfgResp.Etag, "message", resp.GetMessage())
+	}
+}
+
+func reloadDrainProto(result *protocol.DrainResult) *agentv1.ReloadDrainResult {
+	if result == nil {
+		return nil
+	}
+	return &agentv1.ReloadDrainResult{
+		Mode:              result.Mode,
+		ConnectionsBefore: int32(result.ConnectionsBefore),
+		Drained:           int32(result.Drained),
+		Remaining:         int32(result.Remaining),
+		FinishedAt:        result.FinishedAt,
+	}
+}
+
+func pathUsageProto(usages []api.PathUsage) []*agentv1.PathUsage {
+	if len(usages) == 0 {
+		return nil
+	}
+	out := make([]*agentv1.PathUsage, 0, len(usages))
+	for _, usage := range usages {
+		out = append(out, &agentv1.PathUsage{
+			Path:         usage.Path,
+			Exists:       usage.Exists,
+			UsedBytes:    usage.UsedBytes,
+			Truncated:    usage.Truncated,
+			FsTotalBytes: usage.FSTotal,
+			FsUsedBytes:  usage.FSUsed,
+			FsFreeBytes:  usage.FSFree,
+		})
+	}
+	return out
+}
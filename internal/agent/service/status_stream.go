@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/agent/config"
+)
+
+const (
+	statusStreamCommandResync     = "resync"
+	statusStreamCommandRedetect   = "redetect_capabilities"
+	statusStreamCommandForceSync  = "force_resync"
+	statusStreamCommandRotate     = "rotate_token"
+	statusStreamReconnectInitial  = 2 * time.Second
+	statusStreamReconnectMaxDelay = time.Minute
+)
+
+// watchStatusCommands 保持一条 StatusStream 连接接收面板下发的即时指令，断开后按指数退避重连。
+func (a *Agent) watchStatusCommands(ctx context.Context) {
+	if a.grpc == nil {
+		return
+	}
+	delay := statusStreamReconnectInitial
+	for {
+		if err := a.receiveStatusCommands(ctx); err != nil && ctx.Err() == nil {
+			slog.Debug("status stream closed, will reconnect", "error", err, "delay", delay)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > statusStreamReconnectMaxDelay {
+			delay = statusStreamReconnectMaxDelay
+		}
+	}
+}
+
+// receiveStatusCommands 建立一次流并持续读取指令，直到流出错或 ctx 结束。
+func (a *Agent) receiveStatusCommands(ctx context.Context) error {
+	stream, err := a.grpc.StatusStream(ctx)
+	if err != nil {
+		return err
+	}
+	for {
+		cmd, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		switch cmd.GetCommand() {
+		case statusStreamCommandResync:
+			slog.Info("Panel requested immediate resync")
+			// 只保留一个待处理的 resync，由 Run 循环串行执行
+			select {
+			case a.resyncCh <- struct{}{}:
+			default:
+			}
+		case statusStreamCommandForceSync:
+			slog.Info("Panel requested forced resync")
+			// 丢弃本地 ETag，确保面板配置覆盖维护期间的手工修改
+			a.forceResync.Store(true)
+			select {
+			case a.resyncCh <- struct{}{}:
+			default:
+			}
+		case statusStreamCommandRedetect:
+			slog.Info("Panel requested capability re-detection")
+			// 下一次状态上报时重新执行 capDet.Detect
+			a.capsRedetect.Store(true)
+		case statusStreamCommandRotate:
+			a.applyRotatedToken(cmd.GetPayload())
+		default:
+			slog.Warn("Ignoring unsupported status stream command", "command", cmd.GetCommand())
+		}
+	}
+}
+
+// applyRotatedToken 先把新令牌写入配置文件再切换使用：写入失败时继续使用旧令牌，
+// 面板会在宽限期内检测到旧令牌并重新下发。
+func (a *Agent) applyRotatedToken(payload []byte) {
+	var rotation struct {
+		HostToken string `json:"host_token"`
+	}
+	if err := json.Unmarshal(payload, &rotation); err != nil || strings.TrimSpace(rotation.HostToken) == "" {
+		slog.Warn("Ignoring invalid token rotation payload", "error", err)
+		return
+	}
+	if err := config.SaveHostToken(a.cfg.Path, rotation.HostToken); err != nil {
+		slog.Error("Failed to persist rotated host token, keeping current token", "error", err)
+		return
+	}
+	a.grpc.SetToken(rotation.HostToken)
+	// 本地 API 未单独配置 auth_token 时沿用 host_token，旧令牌吊销后也不能再访问
+	if a.cfg.Server.InheritsHostToken() {
+		a.cfg.Server.AuthToken = rotation.HostToken
+		if a.server != nil {
+			a.server.SetAuthToken(rotation.HostToken)
+		}
+	}
+	slog.Info("Host token rotated by panel")
+}
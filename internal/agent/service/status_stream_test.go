@@ -0,0 +1,50 @@
+package service
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/creamcroissant/xboard/internal/agent/config"
+	"github.com/creamcroissant/xboard/internal/agent/transport"
+)
+
+func TestApplyRotatedTokenUpdatesInheritedServerToken(t *testing.T) {
+	tests := []struct {
+		name       string
+		serverYAML string
+		wantServer string
+	}{
+		{name: "inherited from host token", wantServer: "new-token"},
+		{name: "explicit auth token", serverYAML: "server:\n  auth_token: local-secret\n", wantServer: "local-secret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "agent.yml")
+			raw := "panel:\n  url: https://panel.example.com\n  host_token: old-token\ngrpc:\n  enabled: true\n  address: panel.example.com:9090\n" + tt.serverYAML
+			if err := os.WriteFile(path, []byte(raw), 0o600); err != nil {
+				t.Fatalf("write config: %v", err)
+			}
+			cfg, err := config.Load(path)
+			if err != nil {
+				t.Fatalf("load config: %v", err)
+			}
+			a := &Agent{cfg: cfg, grpc: &transport.GRPCClient{}}
+
+			payload, _ := json.Marshal(map[string]string{"host_token": "new-token"})
+			a.applyRotatedToken(payload)
+
+			if got := a.cfg.Server.AuthToken; got != tt.wantServer {
+				t.Fatalf("server auth token = %q, want %q", got, tt.wantServer)
+			}
+			reloaded, err := config.Load(path)
+			if err != nil {
+				t.Fatalf("reload config: %v", err)
+			}
+			if reloaded.Panel.HostToken != "new-token" || reloaded.Server.AuthToken != tt.wantServer {
+				t.Fatalf("persisted host=%q server=%q", reloaded.Panel.HostToken, reloaded.Server.AuthToken)
+			}
+		})
+	}
+}
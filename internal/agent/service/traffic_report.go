@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	agentv1 "github.com/creamcroissant/xboard/pkg/pb/agent/v1"
+)
+
+const (
+	// trafficRetryBaseDelay/trafficRetryMaxDelay 为流量上报连续失败后的退避区间。
+	trafficRetryBaseDelay = 10 * time.Second
+	trafficRetryMaxDelay  = 5 * time.Minute
+)
+
+// pendingTraffic 保存尚未被面板确认的流量。Collect 读取后计数器即被清零，
+// 因此上报失败的增量必须留在这里，否则会永久丢失。只由 Run 循环读写。
+type pendingTraffic struct {
+	// queued 为尚未发送的增量，按用户合并
+	queued map[int64]*agentv1.UserTraffic
+	// batch/batchID 为已发送但结果未知的批次：面板可能已入账而应答丢失，
+	// 重试时必须原样使用同一 batch_id，不能再合并新增量
+	batch   []*agentv1.UserTraffic
+	batchID string
+	// failures/retryAt 控制连续失败后的退避
+	failures int
+	retryAt  time.Time
+}
+
+// add 把新采集的增量合并进待发送缓冲。
+func (p *pendingTraffic) add(traffic []*agentv1.UserTraffic) {
+	for _, item := range traffic {
+		if p.queued == nil {
+			p.queued = make(map[int64]*agentv1.UserTraffic)
+		}
+		merged, ok := p.queued[item.UserId]
+		if !ok {
+			merged = &agentv1.UserTraffic{UserId: item.UserId}
+			p.queued[item.UserId] = merged
+		}
+		merged.UploadBytes += item.UploadBytes
+		merged.DownloadBytes += item.DownloadBytes
+	}
+}
+
+// next 返回本次要发送的批次：优先重发结果未知的批次，否则把缓冲封装为新批次。
+func (p *pendingTraffic) next() ([]*agentv1.UserTraffic, string) {
+	if p.batch == nil && len(p.queued) > 0 {
+		p.batch = make([]*agentv1.UserTraffic, 0, len(p.queued))
+		for _, item := range p.queued {
+			p.batch = append(p.batch, item)
+		}
+		p.batchID = strings.ToLower(strings.ReplaceAll(uuid.NewString(), "-", ""))
+		p.queued = nil
+	}
+	return p.batch, p.batchID
+}
+
+// fail 记录一次失败并计算退避。ResourceExhausted 表示面板在去重标记前就拒绝了批次，
+// 可以安全地拆回缓冲与后续增量合并；其他错误下面板可能已入账，批次保持原样等待重发。
+func (p *pendingTraffic) fail(err error, now time.Time) {
+	if status.Code(err) == codes.ResourceExhausted {
+		batch := p.batch
+		p.batch, p.batchID = nil, ""
+		p.add(batch)
+	}
+	p.failures++
+	delay := trafficRetryMaxDelay
+	if p.failures <= 6 {
+		delay = min(trafficRetryBaseDelay<<(p.failures-1), trafficRetryMaxDelay)
+	}
+	p.retryAt = now.Add(delay)
+}
+
+// succeed 清除已确认的批次与退避状态。
+func (p *pendingTraffic) succeed() {
+	p.batch, p.batchID = nil, ""
+	p.failures = 0
+	p.retryAt = time.Time{}
+}
+
+// size 返回缓冲与待重发批次中的用户条目数，用于日志。
+func (p *pendingTraffic) size() int {
+	return len(p.queued) + len(p.batch)
+}
+
+// reportUserTraffic 采集用户流量并上报；失败的增量保留在 pendingTraffic 中，随下一次上报合并发送。
+func (a *Agent) reportUserTraffic(ctx context.Context) {
+	samples, err := a.traffic.Collect(ctx)
+	if err != nil {
+		slog.Error("Failed to collect traffic", "error", err)
+	}
+
+	// Convert to protobuf format
+	userTraffic := make([]*agentv1.UserTraffic, 0, len(samples))
+	unmapped := 0
+	for _, s := range samples {
+		userID := s.UserID
+		if userID <= 0 {
+			if mappedID, ok := a.resolveUserIDByUID(s.UID); ok {
+				userID = mappedID
+			}
+		}
+		if userID <= 0 {
+			unmapped++
+			continue
+		}
+		if s.Upload == 0 && s.Download == 0 {
+			continue
+		}
+
+		userTraffic = append(userTraffic, &agentv1.UserTraffic{
+			UserId:        userID,
+			UploadBytes:   s.Upload,
+			DownloadBytes: s.Download,
+		})
+	}
+	if unmapped > 0 {
+		slog.Warn("Skip traffic samples due to unresolved user mapping", "unmapped", unmapped, "samples", len(samples))
+	}
+	a.pendingTraffic.add(userTraffic)
+
+	now := time.Now()
+	if now.Before(a.pendingTraffic.retryAt) {
+		return
+	}
+	batch, batchID := a.pendingTraffic.next()
+	if len(batch) == 0 {
+		return
+	}
+	resp, err := a.grpc.ReportTraffic(ctx, batch, batchID)
+	if err != nil {
+		a.pendingTraffic.fail(err, now)
+		slog.Error("Failed to push traffic via gRPC, keeping it for the next report", "error", err, "batch_id", batchID,
+			"pending_users", a.pendingTraffic.size(), "failures", a.pendingTraffic.failures, "retry_at", a.pendingTraffic.retryAt)
+		return
+	}
+	a.pendingTraffic.succeed()
+	slog.Debug("Pushed traffic samples via gRPC", "count", len(batch), "source_samples", len(samples), "unmapped", unmapped, "batch_id", batchID)
+	if overLimit := resp.GetOverLimitUserIds(); len(overLimit) > 0 {
+		slog.Warn("Panel reported users over device limit", "user_ids", overLimit, "batch_id", batchID)
+		a.enforceDeviceLimit(ctx, overLimit)
+	}
+	if exceeded := resp.GetExceededUserIds(); len(exceeded) > 0 {
+		slog.Info("Panel reported users over traffic quota", "user_ids", exceeded, "batch_id", batchID)
+	}
+	// 软限额仅作提示，限速仍以面板下发的用户列表（硬限额）为准
+	if warning := resp.GetWarningUserIds(); len(warning) > 0 {
+		slog.Info("Panel reported users near traffic quota", "user_ids", warning, "batch_id", batchID)
+	}
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	agentv1 "github.com/creamcroissant/xboard/pkg/pb/agent/v1"
+)
+
+func TestPendingTrafficMergesRejectedBatch(t *testing.T) {
+	var p pendingTraffic
+	now := time.Now()
+	p.add([]*agentv1.UserTraffic{{UserId: 1, UploadBytes: 10, DownloadBytes: 20}})
+	batch, _ := p.next()
+	if len(batch) != 1 {
+		t.Fatalf("batch = %v, want one user", batch)
+	}
+
+	p.fail(status.Error(codes.ResourceExhausted, "busy"), now)
+	p.fail(status.Error(codes.ResourceExhausted, "busy"), now)
+	if !p.retryAt.Equal(now.Add(2 * trafficRetryBaseDelay)) {
+		t.Fatalf("retryAt = %v, want exponential backoff", p.retryAt)
+	}
+	p.add([]*agentv1.UserTraffic{{UserId: 1, UploadBytes: 5}, {UserId: 2, DownloadBytes: 7}})
+	batch, _ = p.next()
+	total := int64(0)
+	for _, item := range batch {
+		total += item.UploadBytes + item.DownloadBytes
+	}
+	if len(batch) != 2 || total != 42 {
+		t.Fatalf("batch = %v, want rejected deltas merged with new ones", batch)
+	}
+}
+
+func TestPendingTrafficResendsUnknownBatchUnchanged(t *testing.T) {
+	var p pendingTraffic
+	p.add([]*agentv1.UserTraffic{{UserId: 1, UploadBytes: 10}})
+	_, batchID := p.next()
+
+	p.fail(errors.New("connection reset"), time.Now())
+	p.add([]*agentv1.UserTraffic{{UserId: 1, UploadBytes: 5}})
+	batch, retryID := p.next()
+	if retryID != batchID || len(batch) != 1 || batch[0].UploadBytes != 10 {
+		t.Fatalf("retry = %s %v, want batch %s resent unchanged", retryID, batch, batchID)
+	}
+
+	p.succeed()
+	batch, nextID := p.next()
+	if nextID == batchID || len(batch) != 1 || batch[0].UploadBytes != 5 {
+		t.Fatalf("next = %s %v, want queued delta in a new batch", nextID, batch)
+	}
+}
@@ -2,33 +2,83 @@ package traffic
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/shirou/gopsutil/v3/net"
 )
 
+// bootIDPath 是 Linux 每次开机生成的唯一 ID，用于判断持久化的基线是否属于本次开机。
+const bootIDPath = "/proc/sys/kernel/random/boot_id"
+
 // NetIOCountersFetcher defines a function signature for fetching network IO counters.
 type NetIOCountersFetcher func(ctx context.Context, pernic bool) ([]net.IOCountersStat, error)
 
 // NetIOCollector 使用 gopsutil 采集节点网络流量。
-// 记录累计字节数，并计算每次采集的增量。
+// 按网卡记录累计字节数作为基线，并计算每次采集的增量。
+// 计数器变小（32 位计数器回绕、网卡重建或机器重启）视为计数器重置：该方向本次增量记 0 并以当前值重新建立基线，
+// 不会产生负数或巨大的无符号增量。
 type NetIOCollector struct {
-	iface       string
+	ifaces      []string // 显式配置的网卡，为空时按 mode 选择
+	mode        string
 	fetcher     NetIOCountersFetcher
 	mu          sync.Mutex
-	lastSent    uint64
-	lastRecv    uint64
+	selected    []string // 当前参与统计的网卡
+	baselines   map[string]netIOBaseline
 	initialized bool
+	statePath   string
+	bootID      func() string
+	defaultIfce func() string
+}
+
+// netIOBaseline 是单个网卡上次采集到的累计计数。
+type netIOBaseline struct {
+	Sent uint64 `json:"sent"`
+	Recv uint64 `json:"recv"`
 }
 
-// NewNetIOCollector 创建网络流量采集器。
-// 如果 iface 为空则汇总全部网卡流量。
-func NewNetIOCollector(iface string) *NetIOCollector {
-	return &NetIOCollector{
-		iface:   iface,
-		fetcher: net.IOCountersWithContext,
+// netIOState 是持久化到磁盘的基线，仅在同一次开机内有效。
+type netIOState struct {
+	BootID    string                   `json:"boot_id"`
+	Baselines map[string]netIOBaseline `json:"baselines"`
+	SavedAt   int64                    `json:"saved_at"`
+}
+
+// NewNetIOCollector 创建网络流量采集器，多个网卡的流量求和。
+// 未指定网卡或指定 "auto" 时自动选择默认路由所在网卡；指定 "all" 时汇总除回环外的全部网卡。
+func NewNetIOCollector(ifaces ...string) *NetIOCollector {
+	c := &NetIOCollector{
+		mode:        NetIOInterfaceAuto,
+		fetcher:     net.IOCountersWithContext,
+		bootID:      readBootID,
+		defaultIfce: func() string { return defaultRouteInterface("/proc") },
 	}
+	seen := make(map[string]struct{}, len(ifaces))
+	for _, iface := range ifaces {
+		iface = strings.TrimSpace(iface)
+		switch strings.ToLower(iface) {
+		case "":
+			continue
+		case NetIOInterfaceAuto, NetIOInterfaceAll:
+			c.mode = strings.ToLower(iface)
+			continue
+		}
+		if _, ok := seen[iface]; !ok {
+			seen[iface] = struct{}{}
+			c.ifaces = append(c.ifaces, iface)
+		}
+	}
+	if len(c.ifaces) > 0 {
+		c.mode = ""
+	}
+	return c
 }
 
 // SetFetcher sets a custom fetcher for testing purposes.
@@ -36,6 +86,14 @@ func (c *NetIOCollector) SetFetcher(fetcher NetIOCountersFetcher) {
 	c.fetcher = fetcher
 }
 
+// SetStatePath 设置基线持久化文件。Agent 重启后若仍是同一次开机，则从该文件恢复基线，
+// 停机期间产生的流量会计入重启后的第一次增量；路径为空或已重启机器时在启动时重新建立基线。
+func (c *NetIOCollector) SetStatePath(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.statePath = strings.TrimSpace(path)
+}
+
 // NetIODelta 描述相邻两次采集的流量增量。
 type NetIODelta struct {
 	Upload   uint64 // 上次采集以来的上传字节数
@@ -43,80 +101,140 @@ type NetIODelta struct {
 }
 
 // CollectDelta 返回自上次采集以来的流量增量。
+// 首次采集只建立基线、返回零增量；新出现的网卡同样先建立基线。
 func (c *NetIOCollector) CollectDelta(ctx context.Context) (*NetIODelta, error) {
-	var totalSent, totalRecv uint64
-
-	if c.iface != "" {
-		// 采集指定网卡的流量
-		counters, err := c.fetcher(ctx, true)
-		if err != nil {
-			return nil, err
-		}
-
-		for _, counter := range counters {
-			if counter.Name == c.iface {
-				totalSent = counter.BytesSent
-				totalRecv = counter.BytesRecv
-				break
-			}
-		}
-	} else {
-		// 汇总所有网卡的流量
-		counters, err := c.fetcher(ctx, false)
-		if err != nil {
-			return nil, err
-		}
-
-		if len(counters) > 0 {
-			totalSent = counters[0].BytesSent
-			totalRecv = counters[0].BytesRecv
-		}
+	counters, err := c.fetcher(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	available := make(map[string]netIOBaseline, len(counters))
+	for _, counter := range counters {
+		available[counter.Name] = netIOBaseline{Sent: counter.BytesSent, Recv: counter.BytesRecv}
 	}
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	names := c.selectInterfacesLocked(available)
+	if len(names) == 0 {
+		// 网卡暂时消失时保留原基线，避免重新出现后把累计值当作增量
+		return nil, fmt.Errorf("network interface not found (mode %s, configured %v)", c.modeLabel(), c.ifaces)
+	}
+	current := make(map[string]netIOBaseline, len(names))
+	for _, name := range names {
+		current[name] = available[name]
+	}
+
 	if !c.initialized {
-		c.lastSent = totalSent
-		c.lastRecv = totalRecv
+		c.baselines = c.restoreBaselinesLocked()
 		c.initialized = true
 		slog.Debug("NetIOCollector initialized",
-			"interface", c.iface,
-			"initial_sent", totalSent,
-			"initial_recv", totalRecv)
-		return &NetIODelta{Upload: 0, Download: 0}, nil
-	}
-
-	// 处理计数器回绕，确保增量不为负
-	var uploadDelta, downloadDelta uint64
-	if totalSent >= c.lastSent {
-		uploadDelta = totalSent - c.lastSent
-	} else {
-		slog.Warn("network counter wrapped or reset",
-			"interface", c.iface,
-			"last_sent", c.lastSent,
-			"current_sent", totalSent)
-		uploadDelta = 0
-	}
-	if totalRecv >= c.lastRecv {
-		downloadDelta = totalRecv - c.lastRecv
-	} else {
-		slog.Warn("network counter wrapped or reset",
-			"interface", c.iface,
-			"last_recv", c.lastRecv,
-			"current_recv", totalRecv)
-		downloadDelta = 0
-	}
-
-	c.lastSent = totalSent
-	c.lastRecv = totalRecv
+			"interfaces", names,
+			"restored", len(c.baselines) > 0)
+	}
+
+	var delta NetIODelta
+	for name, now := range current {
+		last, ok := c.baselines[name]
+		if !ok {
+			continue
+		}
+		if now.Sent >= last.Sent {
+			delta.Upload += now.Sent - last.Sent
+		} else {
+			slog.Warn("network counter reset detected, re-baselining",
+				"interface", name,
+				"last_sent", last.Sent,
+				"current_sent", now.Sent)
+		}
+		if now.Recv >= last.Recv {
+			delta.Download += now.Recv - last.Recv
+		} else {
+			slog.Warn("network counter reset detected, re-baselining",
+				"interface", name,
+				"last_recv", last.Recv,
+				"current_recv", now.Recv)
+		}
+	}
+	c.baselines = current
+	c.saveBaselinesLocked()
 
 	slog.Debug("NetIO traffic delta collected",
-		"upload", uploadDelta,
-		"download", downloadDelta)
+		"upload", delta.Upload,
+		"download", delta.Download)
+
+	return &delta, nil
+}
+
+// restoreBaselinesLocked 读取持久化基线；文件缺失、损坏或不属于本次开机时返回空基线。
+func (c *NetIOCollector) restoreBaselinesLocked() map[string]netIOBaseline {
+	empty := make(map[string]netIOBaseline)
+	if c.statePath == "" {
+		return empty
+	}
+	data, err := os.ReadFile(c.statePath)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			slog.Warn("Failed to read netio state, re-baselining", "path", c.statePath, "error", err)
+		}
+		return empty
+	}
+	var state netIOState
+	if err := json.Unmarshal(data, &state); err != nil {
+		slog.Warn("Failed to parse netio state, re-baselining", "path", c.statePath, "error", err)
+		return empty
+	}
+	bootID := c.bootID()
+	if bootID == "" || state.BootID != bootID || state.Baselines == nil {
+		slog.Info("NetIO state belongs to a previous boot, re-baselining", "path", c.statePath)
+		return empty
+	}
+	return state.Baselines
+}
 
-	return &NetIODelta{
-		Upload:   uploadDelta,
-		Download: downloadDelta,
-	}, nil
+// saveBaselinesLocked 写入当前基线，失败只记日志，下次启动会重新建立基线。
+func (c *NetIOCollector) saveBaselinesLocked() {
+	if c.statePath == "" {
+		return
+	}
+	bootID := c.bootID()
+	if bootID == "" {
+		return
+	}
+	data, err := json.Marshal(netIOState{BootID: bootID, Baselines: c.baselines, SavedAt: time.Now().Unix()})
+	if err != nil {
+		return
+	}
+	if err := writeFileAtomic(c.statePath, data); err != nil {
+		slog.Debug("Failed to persist netio state", "path", c.statePath, "error", err)
+	}
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".netio-state-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+func readBootID() string {
+	data, err := os.ReadFile(bootIDPath)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
 }
@@ -0,0 +1,172 @@
+package traffic
+
+import (
+	"bufio"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// NetIO 网卡选择模式。
+const (
+	// NetIOInterfaceAuto 选择默认路由所在网卡，找不到时汇总全部物理网卡。
+	NetIOInterfaceAuto = "auto"
+	// NetIOInterfaceAll 汇总除回环外的全部网卡。
+	NetIOInterfaceAll = "all"
+)
+
+// virtualInterfacePrefixes 是自动检测时排除的容器、网桥与隧道网卡前缀。
+var virtualInterfacePrefixes = []string{
+	"docker", "br-", "veth", "virbr", "cni", "flannel", "cali", "kube-", "vxlan", "lxcbr", "lxdbr", "tunl", "dummy",
+}
+
+// isVirtualInterface 判断网卡是否为回环或容器/网桥网卡。
+func isVirtualInterface(name string) bool {
+	if name == "lo" || strings.HasPrefix(name, "lo:") {
+		return true
+	}
+	for _, prefix := range virtualInterfacePrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// selectInterfacesLocked 返回本次参与统计的网卡。自动模式下已选网卡消失（如网络重配置）时重新检测，
+// 选择结果变化时记录日志，便于运维确认统计的是哪块网卡。
+func (c *NetIOCollector) selectInterfacesLocked(available map[string]netIOBaseline) []string {
+	var names []string
+	switch {
+	case len(c.ifaces) > 0:
+		for _, name := range c.ifaces {
+			if _, ok := available[name]; ok {
+				names = append(names, name)
+			}
+		}
+	case c.mode == NetIOInterfaceAll:
+		for name := range available {
+			if name != "lo" {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+	default:
+		names = c.selected
+		for _, name := range names {
+			if _, ok := available[name]; !ok {
+				names = nil
+				break
+			}
+		}
+		if len(names) == 0 {
+			names = c.detectInterfaces(available)
+		}
+	}
+	if !slices.Equal(names, c.selected) {
+		slog.Info("NetIO interfaces selected",
+			"mode", c.modeLabel(),
+			"interfaces", names,
+			"previous", c.selected)
+		c.selected = names
+	}
+	return names
+}
+
+// detectInterfaces 优先使用默认路由网卡，否则汇总全部非虚拟网卡。
+func (c *NetIOCollector) detectInterfaces(available map[string]netIOBaseline) []string {
+	if name := c.defaultIfce(); name != "" && !isVirtualInterface(name) {
+		if _, ok := available[name]; ok {
+			return []string{name}
+		}
+	}
+	var names []string
+	for name := range available {
+		if !isVirtualInterface(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c *NetIOCollector) modeLabel() string {
+	if len(c.ifaces) > 0 {
+		return "configured"
+	}
+	return c.mode
+}
+
+// defaultRouteInterface 从 /proc/net/route 读取 IPv4 默认路由网卡（取 metric 最小者），
+// 没有 IPv4 默认路由时再查 /proc/net/ipv6_route。
+func defaultRouteInterface(procRoot string) string {
+	if name := parseDefaultRoute(filepath.Join(procRoot, "net", "route"), parseIPv4RouteLine); name != "" {
+		return name
+	}
+	return parseDefaultRoute(filepath.Join(procRoot, "net", "ipv6_route"), parseIPv6RouteLine)
+}
+
+// routeEntry 是默认路由候选。
+type routeEntry struct {
+	iface  string
+	metric uint64
+}
+
+func parseDefaultRoute(path string, parse func(fields []string) (routeEntry, bool)) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	best := routeEntry{}
+	found := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		entry, ok := parse(strings.Fields(scanner.Text()))
+		if !ok {
+			continue
+		}
+		if !found || entry.metric < best.metric {
+			best = entry
+			found = true
+		}
+	}
+	return best.iface
+}
+
+// parseIPv4RouteLine 解析 /proc/net/route：Iface Destination Gateway Flags RefCnt Use Metric Mask ...
+func parseIPv4RouteLine(fields []string) (routeEntry, bool) {
+	if len(fields) < 8 || fields[1] != "00000000" || fields[7] != "00000000" {
+		return routeEntry{}, false
+	}
+	flags, err := strconv.ParseUint(fields[3], 16, 32)
+	if err != nil || flags&0x1 == 0 { // RTF_UP
+		return routeEntry{}, false
+	}
+	metric, err := strconv.ParseUint(fields[6], 10, 64)
+	if err != nil {
+		return routeEntry{}, false
+	}
+	return routeEntry{iface: fields[0], metric: metric}, true
+}
+
+// parseIPv6RouteLine 解析 /proc/net/ipv6_route：dest dest_len src src_len next_hop metric refcnt use flags iface
+func parseIPv6RouteLine(fields []string) (routeEntry, bool) {
+	if len(fields) < 10 || fields[1] != "00" || strings.Trim(fields[0], "0") != "" || fields[9] == "lo" {
+		return routeEntry{}, false
+	}
+	flags, err := strconv.ParseUint(fields[8], 16, 32)
+	if err != nil || flags&0x1 == 0 { // RTF_UP
+		return routeEntry{}, false
+	}
+	metric, err := strconv.ParseUint(fields[5], 16, 64)
+	if err != nil {
+		return routeEntry{}, false
+	}
+	return routeEntry{iface: fields[9], metric: metric}, true
+}
@@ -0,0 +1,89 @@
+package traffic
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+func TestDefaultRouteInterface(t *testing.T) {
+	procRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(procRoot, "net"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	route := "Iface\tDestination\tGateway \tFlags\tRefCnt\tUse\tMetric\tMask\t\tMTU\tWindow\tIRTT\n" +
+		"docker0\t000011AC\t00000000\t0001\t0\t0\t0\t0000FFFF\t0\t0\t0\n" +
+		"ens4\t00000000\t0101A8C0\t0003\t0\t0\t200\t00000000\t0\t0\t0\n" +
+		"ens3\t00000000\t0100000A\t0003\t0\t0\t100\t00000000\t0\t0\t0\n"
+	if err := os.WriteFile(filepath.Join(procRoot, "net", "route"), []byte(route), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := defaultRouteInterface(procRoot); got != "ens3" {
+		t.Fatalf("default route interface = %q, want ens3 (lowest metric)", got)
+	}
+
+	// 仅有 IPv6 默认路由的主机
+	if err := os.Remove(filepath.Join(procRoot, "net", "route")); err != nil {
+		t.Fatal(err)
+	}
+	ipv6 := "00000000000000000000000000000000 00 00000000000000000000000000000000 00 fe800000000000000000000000000001 00000400 00000001 00000000 00450003 enp1s0\n" +
+		"00000000000000000000000000000000 00 00000000000000000000000000000000 00 00000000000000000000000000000000 ffffffff 00000001 00000000 00200200 lo\n"
+	if err := os.WriteFile(filepath.Join(procRoot, "net", "ipv6_route"), []byte(ipv6), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := defaultRouteInterface(procRoot); got != "enp1s0" {
+		t.Fatalf("ipv6 default route interface = %q, want enp1s0", got)
+	}
+}
+
+func TestNetIOCollectorAutoDetectReevaluates(t *testing.T) {
+	fake := &fakeCounters{samples: [][]net.IOCountersStat{
+		{nic("lo", 9, 9), nic("docker0", 500, 500), nic("eth0", 100, 100)},
+		{nic("lo", 9, 9), nic("docker0", 900, 900), nic("eth0", 150, 120)},
+		// 网络重配置后 eth0 被重命名为 ens3
+		{nic("lo", 9, 9), nic("docker0", 900, 900), nic("ens3", 10, 10)},
+		{nic("lo", 9, 9), nic("docker0", 900, 900), nic("ens3", 40, 30)},
+	}}
+	defaultIface := "eth0"
+	c := NewNetIOCollector()
+	c.SetFetcher(fake.fetch)
+	c.defaultIfce = func() string { return defaultIface }
+
+	got := collectDeltas(t, c, 2)
+	if got[1] != (NetIODelta{50, 20}) || !slices.Equal(c.selected, []string{"eth0"}) {
+		t.Fatalf("delta = %+v, selected = %v, want eth0 only", got[1], c.selected)
+	}
+
+	defaultIface = "ens3"
+	got = collectDeltas(t, c, 2)
+	if got[0] != (NetIODelta{}) || got[1] != (NetIODelta{30, 20}) || !slices.Equal(c.selected, []string{"ens3"}) {
+		t.Fatalf("deltas = %+v, selected = %v, want re-detected ens3", got, c.selected)
+	}
+}
+
+func TestNetIOCollectorAutoDetectFallbackExcludesVirtual(t *testing.T) {
+	fake := &fakeCounters{samples: [][]net.IOCountersStat{
+		{nic("lo", 1, 1), nic("br-1a2b", 1, 1), nic("veth9", 1, 1), nic("eth1", 10, 10), nic("eth0", 10, 10)},
+	}}
+	c := NewNetIOCollector("auto")
+	c.SetFetcher(fake.fetch)
+	c.defaultIfce = func() string { return "" }
+
+	collectDeltas(t, c, 1)
+	if !slices.Equal(c.selected, []string{"eth0", "eth1"}) {
+		t.Fatalf("selected = %v, want physical interfaces only", c.selected)
+	}
+}
+
+func TestNewNetIOCollectorInterfaceList(t *testing.T) {
+	c := NewNetIOCollector("", "eth0", " eth1 ", "eth0")
+	if !slices.Equal(c.ifaces, []string{"eth0", "eth1"}) {
+		t.Fatalf("ifaces = %v, want [eth0 eth1]", c.ifaces)
+	}
+	if c := NewNetIOCollector(""); c.mode != NetIOInterfaceAuto || len(c.ifaces) != 0 {
+		t.Fatalf("empty interface should use auto detection, got mode %q ifaces %v", c.mode, c.ifaces)
+	}
+}
@@ -0,0 +1,127 @@
+package traffic
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+// fakeCounters 按顺序返回预设的网卡计数。
+type fakeCounters struct {
+	samples [][]net.IOCountersStat
+	next    int
+}
+
+func (f *fakeCounters) fetch(context.Context, bool) ([]net.IOCountersStat, error) {
+	sample := f.samples[f.next]
+	if f.next < len(f.samples)-1 {
+		f.next++
+	}
+	return sample, nil
+}
+
+func nic(name string, sent, recv uint64) net.IOCountersStat {
+	return net.IOCountersStat{Name: name, BytesSent: sent, BytesRecv: recv}
+}
+
+func collectDeltas(t *testing.T, c *NetIOCollector, n int) []NetIODelta {
+	t.Helper()
+	deltas := make([]NetIODelta, 0, n)
+	for i := 0; i < n; i++ {
+		delta, err := c.CollectDelta(context.Background())
+		if err != nil {
+			t.Fatalf("collect %d: %v", i, err)
+		}
+		deltas = append(deltas, *delta)
+	}
+	return deltas
+}
+
+func TestNetIOCollectorCounterWrap(t *testing.T) {
+	const max32 = 1<<32 - 1
+	fake := &fakeCounters{samples: [][]net.IOCountersStat{
+		{nic("eth0", max32-100, 1000)},
+		{nic("eth0", max32-50, 1500)},
+		{nic("eth0", 20, 2000)}, // 上传计数器回绕
+		{nic("eth0", 70, 2100)},
+	}}
+	c := NewNetIOCollector("eth0")
+	c.SetFetcher(fake.fetch)
+
+	got := collectDeltas(t, c, 4)
+	want := []NetIODelta{{0, 0}, {50, 500}, {0, 500}, {50, 100}}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("delta %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestNetIOCollectorMultipleInterfaces(t *testing.T) {
+	fake := &fakeCounters{samples: [][]net.IOCountersStat{
+		{nic("eth0", 100, 100), nic("veth1", 5000, 5000)},
+		{nic("eth0", 200, 300)}, // veth1 被删除，不应影响 eth0
+		{nic("eth0", 300, 400), nic("veth2", 9000, 9000)},
+		{nic("eth0", 400, 500), nic("veth2", 9010, 9020)},
+	}}
+	c := NewNetIOCollector(NetIOInterfaceAll)
+	c.SetFetcher(fake.fetch)
+
+	got := collectDeltas(t, c, 4)
+	want := []NetIODelta{{0, 0}, {100, 200}, {100, 100}, {110, 120}}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("delta %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestNetIOCollectorRestartAndReboot(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "netio-state.json")
+	newCollector := func(bootID string, samples ...[]net.IOCountersStat) *NetIOCollector {
+		c := NewNetIOCollector("eth0")
+		c.SetFetcher((&fakeCounters{samples: samples}).fetch)
+		c.SetStatePath(statePath)
+		c.bootID = func() string { return bootID }
+		return c
+	}
+
+	first := newCollector("boot-a", []net.IOCountersStat{nic("eth0", 1000, 1000)}, []net.IOCountersStat{nic("eth0", 1500, 1200)})
+	collectDeltas(t, first, 2)
+
+	// Agent 在同一次开机内重启：从持久化基线继续计数，停机期间的流量不丢失
+	restarted := newCollector("boot-a", []net.IOCountersStat{nic("eth0", 1800, 1300)})
+	if got := collectDeltas(t, restarted, 1)[0]; got != (NetIODelta{300, 100}) {
+		t.Fatalf("delta after agent restart = %+v, want {300 100}", got)
+	}
+
+	// 机器重启后计数器从零开始，旧基线作废，首个增量为 0
+	rebooted := newCollector("boot-b", []net.IOCountersStat{nic("eth0", 40, 60)}, []net.IOCountersStat{nic("eth0", 90, 160)})
+	got := collectDeltas(t, rebooted, 2)
+	if got[0] != (NetIODelta{}) || got[1] != (NetIODelta{50, 100}) {
+		t.Fatalf("deltas after reboot = %+v, want [{0 0} {50 100}]", got)
+	}
+}
+
+func TestNetIOCollectorMissingInterfaceKeepsBaseline(t *testing.T) {
+	fake := &fakeCounters{samples: [][]net.IOCountersStat{
+		{nic("eth0", 100, 100)},
+		{nic("lo", 1, 1)},
+		{nic("eth0", 150, 130)},
+	}}
+	c := NewNetIOCollector("eth0")
+	c.SetFetcher(fake.fetch)
+
+	if _, err := c.CollectDelta(context.Background()); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	if _, err := c.CollectDelta(context.Background()); err == nil {
+		t.Fatal("missing interface should return an error")
+	}
+	delta, err := c.CollectDelta(context.Background())
+	if err != nil || *delta != (NetIODelta{50, 30}) {
+		t.Fatalf("delta = %+v, err = %v, want {50 30}", delta, err)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/creamcroissant/xboard/internal/support/certreload"
 	agentv1 "github.com/creamcroissant/xboard/pkg/pb/agent/v1"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/connectivity"
@@ -30,6 +31,8 @@ type GRPCClient struct {
 
 	mu        sync.RWMutex
 	connected bool
+
+	stopReload context.CancelFunc
 }
 
 // Config holds gRPC client configuration
@@ -55,6 +58,9 @@ type TLSConfig struct {
 	KeyFile            string
 	CAFile             string
 	InsecureSkipVerify bool
+	// ReloadInterval is how often the client cert/key files are checked for rotation.
+	// Zero uses certreload.DefaultCheckInterval.
+	ReloadInterval time.Duration
 }
 
 // KeepaliveConfig holds keepalive settings
@@ -91,11 +97,13 @@ func NewGRPCClient(cfg Config) (*GRPCClient, error) {
 	}
 
 	// TLS configuration
+	var reloader *certreload.Reloader
 	if cfg.TLS != nil && cfg.TLS.Enabled {
-		tlsCfg, err := buildTLSConfig(cfg.TLS)
+		tlsCfg, certReloader, err := buildTLSConfig(cfg.TLS)
 		if err != nil {
 			return nil, fmt.Errorf("build TLS config: %w", err)
 		}
+		reloader = certReloader
 		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsCfg)))
 	} else {
 		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
@@ -120,10 +128,17 @@ func NewGRPCClient(cfg Config) (*GRPCClient, error) {
 		config: cfg,
 	}
 	client.connManager = NewConnectionManager(client, nil)
+	if reloader != nil {
+		var reloadCtx context.Context
+		reloadCtx, client.stopReload = context.WithCancel(context.Background())
+		go reloader.Watch(reloadCtx, cfg.TLS.ReloadInterval)
+	}
 	return client, nil
 }
 
-func buildTLSConfig(cfg *TLSConfig) (*tls.Config, error) {
+// buildTLSConfig returns the client TLS config; when a client certificate is configured the
+// returned reloader serves it via GetClientCertificate so rotated files are picked up live.
+func buildTLSConfig(cfg *TLSConfig) (*tls.Config, *certreload.Reloader, error) {
 	tlsCfg := &tls.Config{
 		InsecureSkipVerify: cfg.InsecureSkipVerify,
 	}
@@ -132,34 +147,50 @@ func buildTLSConfig(cfg *TLSConfig) (*tls.Config, error) {
 	if cfg.CAFile != "" {
 		caCert, err := os.ReadFile(cfg.CAFile)
 		if err != nil {
-			return nil, fmt.Errorf("read CA file: %w", err)
+			return nil, nil, fmt.Errorf("read CA file: %w", err)
 		}
 		caCertPool := x509.NewCertPool()
 		if !caCertPool.AppendCertsFromPEM(caCert) {
-			return nil, fmt.Errorf("failed to parse CA certificate")
+			return nil, nil, fmt.Errorf("failed to parse CA certificate")
 		}
 		tlsCfg.RootCAs = caCertPool
 	}
 
 	// Load client certificate if provided (for mutual TLS)
+	var reloader *certreload.Reloader
 	if cfg.CertFile != "" && cfg.KeyFile != "" {
-		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		var err error
+		reloader, err = certreload.New(cfg.CertFile, cfg.KeyFile, slog.Default())
 		if err != nil {
-			return nil, fmt.Errorf("load client certificate: %w", err)
+			return nil, nil, fmt.Errorf("load client certificate: %w", err)
 		}
-		tlsCfg.Certificates = []tls.Certificate{cert}
+		tlsCfg.GetClientCertificate = reloader.GetClientCertificate
 	}
 
-	return tlsCfg, nil
+	return tlsCfg, reloader, nil
 }
 
 // withAuth adds authentication metadata to the context
 func (c *GRPCClient) withAuth(ctx context.Context) context.Context {
-	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+c.token)
+	c.mu.RLock()
+	token := c.token
+	c.mu.RUnlock()
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+}
+
+// SetToken switches the host token used by subsequent calls, e.g. after the panel rotated it.
+// Streams that are already open keep the token they were opened with until they reconnect.
+func (c *GRPCClient) SetToken(token string) {
+	c.mu.Lock()
+	c.token = token
+	c.mu.Unlock()
 }
 
 // Close closes the gRPC connection
 func (c *GRPCClient) Close() error {
+	if c.stopReload != nil {
+		c.stopReload()
+	}
 	return c.conn.Close()
 }
 
@@ -306,19 +337,21 @@ func (c *GRPCClient) GetConfig(ctx context.Context, nodeID int32, etag string) (
 	})
 }
 
-// GetUsers fetches user list for the node
-func (c *GRPCClient) GetUsers(ctx context.Context, nodeID int32, etag string, sinceVersion int64) (*agentv1.UsersResponse, error) {
+// GetUsers fetches user list for the node; full forces the panel to send every user
+func (c *GRPCClient) GetUsers(ctx context.Context, nodeID int32, etag string, sinceVersion int64, full bool) (*agentv1.UsersResponse, error) {
 	return callUnary(ctx, c, CallConfig{}, func(ctx context.Context) (*agentv1.UsersResponse, error) {
 		return c.client.GetUsers(ctx, &agentv1.UsersRequest{
 			NodeId:       nodeID,
 			Etag:         etag,
 			SinceVersion: sinceVersion,
+			Full:         full,
 		})
 	})
 }
 
-// ReportTraffic reports user-level traffic data
-func (c *GRPCClient) ReportTraffic(ctx context.Context, traffic []*agentv1.UserTraffic, reportID string) (*agentv1.TrafficResponse, error) {
+// ReportTraffic reports user-level traffic data. batchID is sent unchanged on every retry so the
+// panel can acknowledge a batch it already applied without counting it twice.
+func (c *GRPCClient) ReportTraffic(ctx context.Context, traffic []*agentv1.UserTraffic, batchID string) (*agentv1.TrafficResponse, error) {
 	cfg := CallConfig{
 		Timeout: c.config.Timeout.Default,
 		Retry:   c.config.Retry,
@@ -327,17 +360,31 @@ func (c *GRPCClient) ReportTraffic(ctx context.Context, traffic []*agentv1.UserT
 		return c.client.ReportTraffic(ctx, &agentv1.TrafficReport{
 			Timestamp:   time.Now().Unix(),
 			UserTraffic: traffic,
-			ReportId:    reportID,
+			ReportId:    batchID,
+			BatchId:     batchID,
 		})
 	})
 }
 
-// ReportAlive reports active user IDs
-func (c *GRPCClient) ReportAlive(ctx context.Context, userIDs []int64) (*agentv1.AliveResponse, error) {
+// ReportAlive reports online (user_id, ip) devices; user_ids is filled for older panels
+func (c *GRPCClient) ReportAlive(ctx context.Context, devices []*agentv1.AliveDevice) (*agentv1.AliveResponse, error) {
+	userIDs := make([]int64, 0, len(devices))
+	seen := make(map[int64]struct{}, len(devices))
+	for _, device := range devices {
+		if device == nil {
+			continue
+		}
+		if _, ok := seen[device.UserId]; ok {
+			continue
+		}
+		seen[device.UserId] = struct{}{}
+		userIDs = append(userIDs, device.UserId)
+	}
 	return callUnary(ctx, c, CallConfig{}, func(ctx context.Context) (*agentv1.AliveResponse, error) {
 		return c.client.ReportAlive(ctx, &agentv1.AliveReport{
 			Timestamp: time.Now().Unix(),
 			UserIds:   userIDs,
+			Devices:   devices,
 		})
 	})
 }
@@ -0,0 +1,179 @@
+// 文件路径: internal/api/clientip/clientip.go
+// 模块说明: 解析请求的真实客户端 IP，按可信代理集合或固定跳数从右向左遍历 X-Forwarded-For。
+package clientip
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// DefaultTrustedProxies 是未配置时默认信任的代理网段：回环与私有地址。
+var DefaultTrustedProxies = []string{
+	"127.0.0.0/8",
+	"::1/128",
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"fc00::/7",
+}
+
+// Resolver 根据代理信任配置解析客户端 IP。
+//
+// 链路按 X-Forwarded-For 各项加上 TCP 对端地址组成，从右向左遍历：
+//   - hops > 0 时，认为最右侧 hops 个地址（含对端地址）都是自己的代理，取其左侧第一个地址；
+//   - 否则跳过属于可信网段的地址，返回第一个不可信地址。
+//
+// 攻击者只能在链路左侧追加伪造地址，因此从右向左取值不会被伪造的首项欺骗。
+type Resolver struct {
+	trusted []*net.IPNet
+	hops    int
+}
+
+// NewResolver 创建解析器。trustedProxies 支持 CIDR 或单个 IP，为 nil 时使用 DefaultTrustedProxies；
+// trustedHops 为 0 时按可信网段判断。
+func NewResolver(trustedProxies []string, trustedHops int) (*Resolver, error) {
+	if trustedHops < 0 {
+		return nil, fmt.Errorf("trusted hops must not be negative / 可信代理跳数不能为负数")
+	}
+	if trustedProxies == nil {
+		trustedProxies = DefaultTrustedProxies
+	}
+	resolver := &Resolver{hops: trustedHops}
+	for _, entry := range trustedProxies {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q / 无效的可信代理地址", entry)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 32
+			}
+			resolver.trusted = append(resolver.trusted, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q / 无效的可信代理网段: %w", entry, err)
+		}
+		resolver.trusted = append(resolver.trusted, network)
+	}
+	return resolver, nil
+}
+
+var defaultResolver, _ = NewResolver(nil, 0)
+
+// Default 返回使用默认可信网段的解析器。
+func Default() *Resolver {
+	return defaultResolver
+}
+
+// Resolve 返回请求的客户端 IP，对端地址无法解析时返回空串。
+func (r *Resolver) Resolve(req *http.Request) string {
+	if r == nil {
+		r = defaultResolver
+	}
+	remote := parseIP(req.RemoteAddr)
+	if remote == nil {
+		return ""
+	}
+
+	chain := forwardedChain(req.Header)
+	if len(chain) == 0 && r.isTrusted(remote) {
+		// 没有 X-Forwarded-For 时退回 X-Real-IP，仅在对端为可信代理时采信
+		if ip := net.ParseIP(strings.TrimSpace(req.Header.Get("X-Real-IP"))); ip != nil {
+			return ip.String()
+		}
+	}
+	chain = append(chain, remote.String())
+
+	if r.hops > 0 {
+		idx := len(chain) - 1 - r.hops
+		if idx < 0 {
+			idx = 0
+		}
+		if ip := parseIP(chain[idx]); ip != nil {
+			return ip.String()
+		}
+		// 该位置不是合法地址说明链路被篡改，退回到紧邻的下一跳
+		for i := idx + 1; i < len(chain); i++ {
+			if ip := parseIP(chain[i]); ip != nil {
+				return ip.String()
+			}
+		}
+		return remote.String()
+	}
+
+	client := remote
+	for i := len(chain) - 1; i >= 0; i-- {
+		ip := parseIP(chain[i])
+		if ip == nil {
+			// 非法条目之后的内容都不可信，返回最后一个确认过的可信代理
+			break
+		}
+		client = ip
+		if !r.isTrusted(ip) {
+			break
+		}
+	}
+	return client.String()
+}
+
+func (r *Resolver) isTrusted(ip net.IP) bool {
+	for _, network := range r.trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedChain 合并所有 X-Forwarded-For 头，按出现顺序返回各项。
+func forwardedChain(header http.Header) []string {
+	var chain []string
+	for _, value := range header.Values("X-Forwarded-For") {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				chain = append(chain, part)
+			}
+		}
+	}
+	return chain
+}
+
+func parseIP(addr string) net.IP {
+	trimmed := strings.TrimSpace(addr)
+	if trimmed == "" {
+		return nil
+	}
+	if host, _, err := net.SplitHostPort(trimmed); err == nil {
+		trimmed = host
+	}
+	return net.ParseIP(trimmed)
+}
+
+type contextKey struct{}
+
+// WithClientIP 把解析结果附加到 context，供下游处理器复用。
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, contextKey{}, ip)
+}
+
+// FromRequest 返回中间件解析好的客户端 IP；未经过中间件时使用默认解析器现场解析。
+func FromRequest(req *http.Request) string {
+	if req == nil {
+		return ""
+	}
+	if ip, ok := req.Context().Value(contextKey{}).(string); ok {
+		return ip
+	}
+	return defaultResolver.Resolve(req)
+}
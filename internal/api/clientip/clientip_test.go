@@ -0,0 +1,132 @@
+package clientip
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResolverTrustedProxies(t *testing.T) {
+	resolver, err := NewResolver([]string{"10.0.0.0/8", "203.0.113.7"}, 0)
+	if err != nil {
+		t.Fatalf("new resolver: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		xff        []string
+		realIP     string
+		want       string
+	}{
+		{
+			name:       "direct connection ignores headers",
+			remoteAddr: "198.51.100.10:5000",
+			xff:        []string{"1.1.1.1"},
+			realIP:     "2.2.2.2",
+			want:       "198.51.100.10",
+		},
+		{
+			name:       "single trusted proxy",
+			remoteAddr: "10.0.0.2:5000",
+			xff:        []string{"198.51.100.20"},
+			want:       "198.51.100.20",
+		},
+		{
+			name:       "spoofed leftmost entry is skipped",
+			remoteAddr: "10.0.0.2:5000",
+			xff:        []string{"6.6.6.6, 198.51.100.30"},
+			want:       "198.51.100.30",
+		},
+		{
+			name:       "multiple trusted proxies",
+			remoteAddr: "10.0.0.2:5000",
+			xff:        []string{"6.6.6.6, 198.51.100.40, 203.0.113.7, 10.0.0.9"},
+			want:       "198.51.100.40",
+		},
+		{
+			name:       "multiple headers are joined in order",
+			remoteAddr: "10.0.0.2:5000",
+			xff:        []string{"6.6.6.6", "198.51.100.50"},
+			want:       "198.51.100.50",
+		},
+		{
+			name:       "garbage entry stops the walk",
+			remoteAddr: "10.0.0.2:5000",
+			xff:        []string{"198.51.100.60, not-an-ip, 10.0.0.8"},
+			want:       "10.0.0.8",
+		},
+		{
+			name:       "all hops trusted returns leftmost",
+			remoteAddr: "10.0.0.2:5000",
+			xff:        []string{"10.1.1.1, 10.0.0.3"},
+			want:       "10.1.1.1",
+		},
+		{
+			name:       "x-real-ip from trusted proxy without xff",
+			remoteAddr: "10.0.0.2:5000",
+			realIP:     "198.51.100.70",
+			want:       "198.51.100.70",
+		},
+		{
+			name:       "entries with ports",
+			remoteAddr: "10.0.0.2:5000",
+			xff:        []string{"6.6.6.6, [2001:db8::1]:443"},
+			want:       "2001:db8::1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.xff {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-IP", tt.realIP)
+			}
+			if got := resolver.Resolve(req); got != tt.want {
+				t.Fatalf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolverTrustedHops(t *testing.T) {
+	resolver, err := NewResolver([]string{}, 2)
+	if err != nil {
+		t.Fatalf("new resolver: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		xff  string
+		want string
+	}{
+		{name: "exact hops", xff: "198.51.100.1, 172.67.1.1", want: "198.51.100.1"},
+		{name: "spoofed prefix", xff: "6.6.6.6, 7.7.7.7, 198.51.100.2, 172.67.1.1", want: "198.51.100.2"},
+		{name: "short chain returns leftmost", xff: "", want: "104.16.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = "104.16.0.1:443"
+			if tt.xff != "" {
+				req.Header.Set("X-Forwarded-For", tt.xff)
+			}
+			if got := resolver.Resolve(req); got != tt.want {
+				t.Fatalf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewResolverRejectsInvalidEntries(t *testing.T) {
+	if _, err := NewResolver([]string{"not-a-cidr/33"}, 0); err == nil {
+		t.Fatal("expected error for invalid cidr")
+	}
+	if _, err := NewResolver(nil, -1); err == nil {
+		t.Fatal("expected error for negative hops")
+	}
+}
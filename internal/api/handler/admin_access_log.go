@@ -1,8 +1,11 @@
 package handler
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/creamcroissant/xboard/internal/repository"
 	"github.com/creamcroissant/xboard/internal/service"
@@ -36,6 +39,9 @@ func (h *AdminAccessLogHandler) Fetch(w http.ResponseWriter, r *http.Request) {
 	if q := r.URL.Query().Get("source_ip"); q != "" {
 		filter.SourceIP = &q
 	}
+	if q := r.URL.Query().Get("source_country"); q != "" {
+		filter.SourceCountry = &q
+	}
 	if q := r.URL.Query().Get("protocol"); q != "" {
 		filter.Protocol = &q
 	}
@@ -71,6 +77,9 @@ func (h *AdminAccessLogHandler) GetStats(w http.ResponseWriter, r *http.Request)
 	if id := getInt64Query(r, "agent_host_id"); id > 0 {
 		filter.AgentHostID = &id
 	}
+	if q := r.URL.Query().Get("source_country"); q != "" {
+		filter.SourceCountry = &q
+	}
 	if start := getInt64Query(r, "start_at"); start > 0 {
 		filter.StartAt = &start
 	}
@@ -99,6 +108,52 @@ func (h *AdminAccessLogHandler) Cleanup(w http.ResponseWriter, r *http.Request)
 	})
 }
 
+// TopTargets handles GET /access-logs/top-targets?agent_host_id=&window=24h&limit=10.
+func (h *AdminAccessLogHandler) TopTargets(w http.ResponseWriter, r *http.Request) {
+	h.respondTop(w, r, "top_targets", h.accessLogService.TopTargets)
+}
+
+// TopSources handles GET /access-logs/top-sources?agent_host_id=&window=24h&limit=10.
+func (h *AdminAccessLogHandler) TopSources(w http.ResponseWriter, r *http.Request) {
+	h.respondTop(w, r, "top_sources", h.accessLogService.TopSources)
+}
+
+func (h *AdminAccessLogHandler) respondTop(w http.ResponseWriter, r *http.Request, action string, fetch func(ctx context.Context, agentHostID int64, window time.Duration, limit int) ([]*repository.AccessLogAggregate, error)) {
+	var window time.Duration
+	if raw := r.URL.Query().Get("window"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil || parsed <= 0 {
+			respondError(w, http.StatusBadRequest, action, fmt.Errorf("invalid window %q, expected a duration like 24h / 时间窗口无效，应为 24h 这样的时长", raw))
+			return
+		}
+		window = parsed
+	}
+
+	items, err := fetch(r.Context(), getInt64Query(r, "agent_host_id"), window, getIntQuery(r, "limit", 0))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, action, err)
+		return
+	}
+	if items == nil {
+		items = []*repository.AccessLogAggregate{}
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"data": items,
+	})
+}
+
+// GetRetention returns the retention policy and the scheduled cleanup state.
+func (h *AdminAccessLogHandler) GetRetention(w http.ResponseWriter, r *http.Request) {
+	retention, err := h.accessLogService.GetRetention(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "get_retention", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, retention)
+}
+
 func getIntQuery(r *http.Request, key string, def int) int {
 	str := r.URL.Query().Get(key)
 	if str == "" {
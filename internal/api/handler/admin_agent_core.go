@@ -82,12 +82,35 @@ func (h *AdminAgentCoreHandler) ListInstances(w http.ResponseWriter, r *http.Req
 	respondJSON(w, http.StatusOK, map[string]any{"data": instances})
 }
 
+// CoreTopology 返回主机上多核心实例的拓扑与端口冲突。
+func (h *AdminAgentCoreHandler) CoreTopology(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.requireAdmin(w, r); !ok {
+		return
+	}
+	if h.cores == nil {
+		RespondErrorI18nAction(r.Context(), w, http.StatusServiceUnavailable, "admin.agent_core.topology", "error.service_unavailable", h.i18n)
+		return
+	}
+	agentHostID, err := parseInt64(chi.URLParam(r, "id"))
+	if err != nil || agentHostID <= 0 {
+		RespondErrorI18nAction(r.Context(), w, http.StatusBadRequest, "admin.agent_core.topology", "error.bad_request", h.i18n)
+		return
+	}
+	topology, err := h.cores.GetCoreTopology(r.Context(), agentHostID)
+	if err != nil {
+		h.respondServiceError(r.Context(), w, "admin.agent_core.topology", err)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"data": topology})
+}
+
 // CreateInstanceRequest 定义核心实例创建请求体。
 type CreateInstanceRequest struct {
-	CoreType         string          `json:"core_type"`
-	InstanceID       string          `json:"instance_id"`
-	ConfigTemplateID int64           `json:"config_template_id"`
-	ConfigJSON       json.RawMessage `json:"config_json"`
+	CoreType              string          `json:"core_type"`
+	InstanceID            string          `json:"instance_id"`
+	ConfigTemplateID      int64           `json:"config_template_id"`
+	ConfigTemplateVersion int64           `json:"config_template_version"`
+	ConfigJSON            json.RawMessage `json:"config_json"`
 }
 
 // CreateInstance 处理 POST /api/v2/admin/agent-hosts/{id}/core-instances。
@@ -110,7 +133,7 @@ func (h *AdminAgentCoreHandler) CreateInstance(w http.ResponseWriter, r *http.Re
 		RespondErrorI18nAction(r.Context(), w, http.StatusBadRequest, "admin.agent_core.instance.create", "error.bad_request", h.i18n)
 		return
 	}
-	operation, err := h.cores.CreateInstance(r.Context(), service.CreateInstanceRequest{AgentHostID: agentHostID, CoreType: req.CoreType, InstanceID: req.InstanceID, ConfigTemplateID: req.ConfigTemplateID, ConfigJSON: req.ConfigJSON, OperatorID: &adminID})
+	operation, err := h.cores.CreateInstance(r.Context(), service.CreateInstanceRequest{AgentHostID: agentHostID, CoreType: req.CoreType, InstanceID: req.InstanceID, ConfigTemplateID: req.ConfigTemplateID, ConfigTemplateVersion: req.ConfigTemplateVersion, ConfigJSON: req.ConfigJSON, OperatorID: &adminID})
 	if err != nil {
 		h.respondServiceError(r.Context(), w, "admin.agent_core.instance.create", err)
 		return
@@ -146,13 +169,14 @@ func (h *AdminAgentCoreHandler) DeleteInstance(w http.ResponseWriter, r *http.Re
 
 // SwitchCoreRequest 定义核心切换请求体。
 type SwitchCoreRequest struct {
-	FromInstanceID   string          `json:"from_instance_id"`
-	ToCoreType       string          `json:"to_core_type"`
-	ConfigTemplateID int64           `json:"config_template_id"`
-	ConfigJSON       json.RawMessage `json:"config_json"`
-	SwitchID         string          `json:"switch_id"`
-	ListenPorts      []int           `json:"listen_ports"`
-	ZeroDowntime     *bool           `json:"zero_downtime"`
+	FromInstanceID        string          `json:"from_instance_id"`
+	ToCoreType            string          `json:"to_core_type"`
+	ConfigTemplateID      int64           `json:"config_template_id"`
+	ConfigTemplateVersion int64           `json:"config_template_version"`
+	ConfigJSON            json.RawMessage `json:"config_json"`
+	SwitchID              string          `json:"switch_id"`
+	ListenPorts           []int           `json:"listen_ports"`
+	ZeroDowntime          *bool           `json:"zero_downtime"`
 }
 
 // InstallCoreRequest 定义核心安装/升级请求体。
@@ -166,6 +190,12 @@ type InstallCoreRequest struct {
 	RequestID string `json:"request_id"`
 }
 
+// UpgradeCoreRequest 定义核心二进制升级请求体。
+type UpgradeCoreRequest struct {
+	CoreType string `json:"core_type"`
+	Version  string `json:"version"`
+}
+
 // SwitchCore 处理 POST /api/v2/admin/agent-hosts/{id}/core-switch。
 func (h *AdminAgentCoreHandler) SwitchCore(w http.ResponseWriter, r *http.Request) {
 	adminID, ok := h.requireAdmin(w, r)
@@ -186,7 +216,7 @@ func (h *AdminAgentCoreHandler) SwitchCore(w http.ResponseWriter, r *http.Reques
 		RespondErrorI18nAction(r.Context(), w, http.StatusBadRequest, "admin.agent_core.switch", "error.bad_request", h.i18n)
 		return
 	}
-	operation, err := h.cores.SwitchCore(r.Context(), service.SwitchCoreRequest{AgentHostID: agentHostID, FromInstanceID: req.FromInstanceID, ToCoreType: req.ToCoreType, ConfigTemplateID: req.ConfigTemplateID, ConfigJSON: req.ConfigJSON, SwitchID: req.SwitchID, ListenPorts: req.ListenPorts, ZeroDowntime: req.ZeroDowntime, OperatorID: &adminID})
+	operation, err := h.cores.SwitchCore(r.Context(), service.SwitchCoreRequest{AgentHostID: agentHostID, FromInstanceID: req.FromInstanceID, ToCoreType: req.ToCoreType, ConfigTemplateID: req.ConfigTemplateID, ConfigTemplateVersion: req.ConfigTemplateVersion, ConfigJSON: req.ConfigJSON, SwitchID: req.SwitchID, ListenPorts: req.ListenPorts, ZeroDowntime: req.ZeroDowntime, OperatorID: &adminID})
 	if err != nil {
 		h.respondServiceError(r.Context(), w, "admin.agent_core.switch", err)
 		return
@@ -222,6 +252,34 @@ func (h *AdminAgentCoreHandler) InstallCore(w http.ResponseWriter, r *http.Reque
 	respondJSON(w, http.StatusAccepted, map[string]any{"data": operation})
 }
 
+// UpgradeCore 处理 POST /api/v2/admin/agent-hosts/{id}/core-upgrade。
+func (h *AdminAgentCoreHandler) UpgradeCore(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := h.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+	if h.cores == nil {
+		RespondErrorI18nAction(r.Context(), w, http.StatusServiceUnavailable, "admin.agent_core.upgrade", "error.service_unavailable", h.i18n)
+		return
+	}
+	agentHostID, err := parseInt64(chi.URLParam(r, "id"))
+	if err != nil || agentHostID <= 0 {
+		RespondErrorI18nAction(r.Context(), w, http.StatusBadRequest, "admin.agent_core.upgrade", "error.bad_request", h.i18n)
+		return
+	}
+	var req UpgradeCoreRequest
+	if err := decodeJSON(r, &req); err != nil {
+		RespondErrorI18nAction(r.Context(), w, http.StatusBadRequest, "admin.agent_core.upgrade", "error.bad_request", h.i18n)
+		return
+	}
+	operation, err := h.cores.UpgradeCore(r.Context(), service.UpgradeCoreRequest{AgentHostID: agentHostID, CoreType: req.CoreType, Version: req.Version, OperatorID: &adminID})
+	if err != nil {
+		h.respondServiceError(r.Context(), w, "admin.agent_core.upgrade", err)
+		return
+	}
+	respondJSON(w, http.StatusAccepted, map[string]any{"data": operation})
+}
+
 // ConvertConfigRequest 定义配置转换请求体。
 type ConvertConfigRequest struct {
 	SourceCore string          `json:"source_core"`
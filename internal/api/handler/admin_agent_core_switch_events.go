@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// coreSwitchPollInterval 为读取切换状态的间隔；状态由 Agent 异步回报，秒级刷新足以反映排空进度。
+	coreSwitchPollInterval = time.Second
+	// coreSwitchKeepaliveInterval 为无状态变化时的 SSE 注释心跳间隔，防止反向代理断开空闲连接。
+	coreSwitchKeepaliveInterval = 15 * time.Second
+)
+
+// SwitchEvents 处理 GET /api/v2/admin/agent-hosts/{id}/core-switch/{switch_id}/events。
+// 以 SSE 推送切换状态变化，连接建立时先回放当前状态，因此断线重连无需 Last-Event-ID；到达终态后结束流。
+func (h *AdminAgentCoreHandler) SwitchEvents(w http.ResponseWriter, r *http.Request) {
+	const action = "admin.agent_core.switch_events"
+	if _, ok := h.requireAdmin(w, r); !ok {
+		return
+	}
+	if h.cores == nil {
+		RespondErrorI18nAction(r.Context(), w, http.StatusServiceUnavailable, action, "error.service_unavailable", h.i18n)
+		return
+	}
+	agentHostID, err := parseInt64(chi.URLParam(r, "id"))
+	switchID := strings.TrimSpace(chi.URLParam(r, "switch_id"))
+	if err != nil || agentHostID <= 0 || switchID == "" {
+		RespondErrorI18nAction(r.Context(), w, http.StatusBadRequest, action, "error.bad_request", h.i18n)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		RespondErrorI18nAction(r.Context(), w, http.StatusInternalServerError, action, "error.internal_server_error", h.i18n)
+		return
+	}
+	current, err := h.cores.GetSwitchStatus(r.Context(), agentHostID, switchID)
+	if err != nil {
+		h.respondServiceError(r.Context(), w, action, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if err := writeCoreSwitchSSE(w, current); err != nil {
+		return
+	}
+	flusher.Flush()
+	if current.Terminal() {
+		return
+	}
+
+	poll := time.NewTicker(coreSwitchPollInterval)
+	defer poll.Stop()
+	lastWrite := time.Now()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case now := <-poll.C:
+			next, err := h.cores.GetSwitchStatus(r.Context(), agentHostID, switchID)
+			if err != nil {
+				if r.Context().Err() == nil {
+					writeOperationLogSSEError(w, flusher, "status_unavailable")
+				}
+				return
+			}
+			if next.Status == current.Status {
+				if now.Sub(lastWrite) >= coreSwitchKeepaliveInterval {
+					if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+						return
+					}
+					flusher.Flush()
+					lastWrite = now
+				}
+				continue
+			}
+			if err := writeCoreSwitchSSE(w, next); err != nil {
+				return
+			}
+			flusher.Flush()
+			lastWrite = now
+			current = next
+			if current.Terminal() {
+				return
+			}
+		}
+	}
+}
+
+func writeCoreSwitchSSE(w http.ResponseWriter, status *service.CoreSwitchStatus) error {
+	payload, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: status\ndata: %s\n\n", status.Status, payload)
+	return err
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/creamcroissant/xboard/internal/service"
+	"github.com/creamcroissant/xboard/internal/support/i18n"
+)
+
+// AdminAuditLogHandler exposes the append-only admin mutation audit log.
+type AdminAuditLogHandler struct {
+	audit service.AuditLogService
+	i18n  *i18n.Manager
+}
+
+// NewAdminAuditLogHandler creates an admin audit log handler.
+func NewAdminAuditLogHandler(audit service.AuditLogService, i18nMgr *i18n.Manager) *AdminAuditLogHandler {
+	return &AdminAuditLogHandler{audit: audit, i18n: i18nMgr}
+}
+
+// Fetch handles GET /api/v2/{securePath}/audit-logs.
+// 支持 actor_id、target_type、target_id 以及 since/until（Unix 秒，左闭右开）过滤，按时间倒序返回。
+func (h *AdminAuditLogHandler) Fetch(w http.ResponseWriter, r *http.Request) {
+	const action = "admin.audit_log.fetch"
+	if h.audit == nil {
+		RespondErrorI18nAction(r.Context(), w, http.StatusServiceUnavailable, action, "error.service_unavailable", h.i18n)
+		return
+	}
+
+	query := r.URL.Query()
+	input := service.AuditLogListInput{
+		ActorID:    parsePositiveInt64(query.Get("actor_id"), 0),
+		TargetType: strings.TrimSpace(query.Get("target_type")),
+		TargetID:   parsePositiveInt64(query.Get("target_id"), 0),
+		Since:      parsePositiveInt64(query.Get("since"), 0),
+		Until:      parsePositiveInt64(query.Get("until"), 0),
+		Limit:      clampQueryInt(query.Get("limit"), 50),
+		Offset:     clampNonNegativeQueryInt(query.Get("offset"), 0),
+	}
+
+	logs, total, err := h.audit.List(r.Context(), input)
+	if err != nil {
+		if errors.Is(err, service.ErrBadRequest) {
+			RespondErrorI18nAction(r.Context(), w, http.StatusBadRequest, action, "error.bad_request", h.i18n)
+			return
+		}
+		RespondErrorI18nAction(r.Context(), w, http.StatusInternalServerError, action, "error.internal_server_error", h.i18n)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"data":  logs,
+		"total": total,
+	})
+}
@@ -0,0 +1,337 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/creamcroissant/xboard/internal/api/requestctx"
+	"github.com/creamcroissant/xboard/internal/service"
+	"github.com/creamcroissant/xboard/internal/support/i18n"
+	"github.com/creamcroissant/xboard/internal/template"
+	"github.com/go-chi/chi/v5"
+)
+
+// AdminConfigTemplateHandler exposes admin endpoints for config template history and linting.
+type AdminConfigTemplateHandler struct {
+	templates service.ConfigTemplateService
+	i18n      *i18n.Manager
+}
+
+// NewAdminConfigTemplateHandler creates a config template handler.
+func NewAdminConfigTemplateHandler(templates service.ConfigTemplateService, i18nMgr *i18n.Manager) *AdminConfigTemplateHandler {
+	return &AdminConfigTemplateHandler{templates: templates, i18n: i18nMgr}
+}
+
+type rollbackConfigTemplateRequest struct {
+	Version           int64  `json:"version"`
+	ChangeNote        string `json:"change_note,omitempty"`
+	ExpectedUpdatedAt int64  `json:"expected_updated_at,omitempty"`
+}
+
+type updateConfigTemplateRequest struct {
+	Name              *string  `json:"name,omitempty"`
+	Type              *string  `json:"type,omitempty"`
+	Content           *string  `json:"content,omitempty"`
+	Description       *string  `json:"description,omitempty"`
+	MinVersion        *string  `json:"min_version,omitempty"`
+	Capabilities      []string `json:"capabilities,omitempty"`
+	ChangeNote        string   `json:"change_note,omitempty"`
+	ExpectedUpdatedAt int64    `json:"expected_updated_at"`
+}
+
+type lintConfigTemplateRequest struct {
+	Content string `json:"content"`
+	Type    string `json:"type"`
+}
+
+func (h *AdminConfigTemplateHandler) requireAdmin(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	claims := requestctx.AdminFromContext(r.Context())
+	if claims.ID == "" {
+		RespondErrorI18nAction(r.Context(), w, http.StatusUnauthorized, "admin.config_template.auth", "error.unauthorized", h.i18n)
+		return 0, false
+	}
+	adminID, err := parseInt64(claims.ID)
+	if err != nil {
+		adminID = 0
+	}
+	return adminID, true
+}
+
+func (h *AdminConfigTemplateHandler) ensureService(w http.ResponseWriter, r *http.Request, action string) bool {
+	if h.templates != nil {
+		return true
+	}
+	RespondErrorI18nAction(r.Context(), w, http.StatusServiceUnavailable, action, "error.service_unavailable", h.i18n)
+	return false
+}
+
+// ListVersions handles GET /api/v2/{securePath}/agent-hosts/templates/{template_id}/versions.
+func (h *AdminConfigTemplateHandler) ListVersions(w http.ResponseWriter, r *http.Request) {
+	const action = "admin.config_template.versions"
+	if _, ok := h.requireAdmin(w, r); !ok {
+		return
+	}
+	if !h.ensureService(w, r, action) {
+		return
+	}
+
+	templateID, err := parseInt64(chi.URLParam(r, "template_id"))
+	if err != nil || templateID <= 0 {
+		RespondErrorI18nAction(r.Context(), w, http.StatusBadRequest, action, "error.bad_request", h.i18n)
+		return
+	}
+	limit := clampQueryInt(r.URL.Query().Get("limit"), 20)
+	offset := clampNonNegativeQueryInt(r.URL.Query().Get("offset"), 0)
+
+	items, err := h.templates.ListVersions(r.Context(), templateID, limit, offset)
+	if err != nil {
+		h.respondServiceError(r.Context(), w, action, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"data":  items,
+		"total": len(items),
+	})
+}
+
+// GetVersion handles GET /api/v2/{securePath}/agent-hosts/templates/{template_id}/versions/{version}.
+func (h *AdminConfigTemplateHandler) GetVersion(w http.ResponseWriter, r *http.Request) {
+	const action = "admin.config_template.version"
+	if _, ok := h.requireAdmin(w, r); !ok {
+		return
+	}
+	if !h.ensureService(w, r, action) {
+		return
+	}
+
+	templateID, err := parseInt64(chi.URLParam(r, "template_id"))
+	if err != nil || templateID <= 0 {
+		RespondErrorI18nAction(r.Context(), w, http.StatusBadRequest, action, "error.bad_request", h.i18n)
+		return
+	}
+	version, err := parseInt64(chi.URLParam(r, "version"))
+	if err != nil || version <= 0 {
+		RespondErrorI18nAction(r.Context(), w, http.StatusBadRequest, action, "error.bad_request", h.i18n)
+		return
+	}
+
+	item, err := h.templates.GetVersion(r.Context(), templateID, version)
+	if err != nil {
+		h.respondServiceError(r.Context(), w, action, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"data": item})
+}
+
+// Rollback handles POST /api/v2/{securePath}/agent-hosts/templates/{template_id}/rollback.
+// 回滚会生成一个内容与目标版本相同的新版本，已固定旧版本的节点不受影响。
+func (h *AdminConfigTemplateHandler) Rollback(w http.ResponseWriter, r *http.Request) {
+	const action = "admin.config_template.rollback"
+	adminID, ok := h.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+	if !h.ensureService(w, r, action) {
+		return
+	}
+
+	templateID, err := parseInt64(chi.URLParam(r, "template_id"))
+	if err != nil || templateID <= 0 {
+		RespondErrorI18nAction(r.Context(), w, http.StatusBadRequest, action, "error.bad_request", h.i18n)
+		return
+	}
+	var payload rollbackConfigTemplateRequest
+	if err := decodeJSON(r, &payload); err != nil || payload.Version <= 0 {
+		RespondErrorI18nAction(r.Context(), w, http.StatusBadRequest, action, "error.bad_request", h.i18n)
+		return
+	}
+
+	newVersion, err := h.templates.Rollback(r.Context(), service.RollbackConfigTemplateRequest{
+		TemplateID: templateID,
+		Version:    payload.Version,
+		ChangeNote: payload.ChangeNote,
+		OperatorID: adminID,
+		// 回滚同样会改写模板，传入读取时的 updated_at 防止覆盖他人的修改
+		ExpectedUpdatedAt: payload.ExpectedUpdatedAt,
+	})
+	if err != nil {
+		h.respondServiceError(r.Context(), w, action, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"data": map[string]any{
+			"template_id":   templateID,
+			"restored_from": payload.Version,
+			"version":       newVersion,
+		},
+	})
+}
+
+// Update handles PUT /api/v2/{securePath}/agent-hosts/templates/{template_id}.
+// expected_updated_at 必填，为编辑时读取到的 updated_at；模板已被他人修改时返回 409，前端应提示刷新。
+func (h *AdminConfigTemplateHandler) Update(w http.ResponseWriter, r *http.Request) {
+	const action = "admin.config_template.update"
+	adminID, ok := h.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+	if !h.ensureService(w, r, action) {
+		return
+	}
+
+	templateID, err := parseInt64(chi.URLParam(r, "template_id"))
+	if err != nil || templateID <= 0 {
+		RespondErrorI18nAction(r.Context(), w, http.StatusBadRequest, action, "error.bad_request", h.i18n)
+		return
+	}
+	var payload updateConfigTemplateRequest
+	if err := decodeJSON(r, &payload); err != nil || payload.ExpectedUpdatedAt <= 0 {
+		RespondErrorI18nAction(r.Context(), w, http.StatusBadRequest, action, "error.bad_request", h.i18n)
+		return
+	}
+
+	if err := h.templates.Update(r.Context(), templateID, service.UpdateConfigTemplateRequest{
+		Name:              payload.Name,
+		Type:              payload.Type,
+		Content:           payload.Content,
+		Description:       payload.Description,
+		MinVersion:        payload.MinVersion,
+		Capabilities:      payload.Capabilities,
+		ChangeNote:        payload.ChangeNote,
+		OperatorID:        adminID,
+		ExpectedUpdatedAt: payload.ExpectedUpdatedAt,
+	}); err != nil {
+		h.respondServiceError(r.Context(), w, action, err)
+		return
+	}
+	updated, err := h.templates.FindByID(r.Context(), templateID)
+	if err != nil {
+		h.respondServiceError(r.Context(), w, action, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"data": updated})
+}
+
+// Lint handles POST /api/v2/{securePath}/agent-hosts/templates/lint.
+// 对未保存的模板内容执行 lint，返回带规则标识与修复建议的问题列表。
+func (h *AdminConfigTemplateHandler) Lint(w http.ResponseWriter, r *http.Request) {
+	const action = "admin.config_template.lint"
+	if _, ok := h.requireAdmin(w, r); !ok {
+		return
+	}
+	if !h.ensureService(w, r, action) {
+		return
+	}
+
+	var payload lintConfigTemplateRequest
+	if err := decodeJSON(r, &payload); err != nil || payload.Content == "" {
+		RespondErrorI18nAction(r.Context(), w, http.StatusBadRequest, action, "error.bad_request", h.i18n)
+		return
+	}
+
+	issues, err := h.templates.LintTemplate(r.Context(), payload.Content, payload.Type)
+	if err != nil {
+		h.respondServiceError(r.Context(), w, action, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"data": map[string]any{
+			"valid":  !template.HasIssueErrors(issues),
+			"issues": issues,
+		},
+	})
+}
+
+// Export handles GET /api/v2/{securePath}/agent-hosts/templates/{template_id}/export.
+// 返回可移植的模板文件（内容 + 能力声明 + 最低版本 + 元数据），可直接保存后在其他实例导入。
+func (h *AdminConfigTemplateHandler) Export(w http.ResponseWriter, r *http.Request) {
+	const action = "admin.config_template.export"
+	if _, ok := h.requireAdmin(w, r); !ok {
+		return
+	}
+	if !h.ensureService(w, r, action) {
+		return
+	}
+
+	templateID, err := parseInt64(chi.URLParam(r, "template_id"))
+	if err != nil || templateID <= 0 {
+		RespondErrorI18nAction(r.Context(), w, http.StatusBadRequest, action, "error.bad_request", h.i18n)
+		return
+	}
+	bundle, err := h.templates.Export(r.Context(), templateID)
+	if err != nil {
+		h.respondServiceError(r.Context(), w, action, err)
+		return
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="config-template-%d.json"`, templateID))
+	respondJSON(w, http.StatusOK, bundle)
+}
+
+// Import handles POST /api/v2/{securePath}/agent-hosts/templates/import.
+// 请求体为导出的模板文件；on_conflict 查询参数指定同名冲突处理（reject/rename/overwrite），name 可覆盖模板名称。
+func (h *AdminConfigTemplateHandler) Import(w http.ResponseWriter, r *http.Request) {
+	const action = "admin.config_template.import"
+	adminID, ok := h.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+	if !h.ensureService(w, r, action) {
+		return
+	}
+
+	var bundle service.ConfigTemplateBundle
+	if err := decodeJSON(r, &bundle); err != nil {
+		RespondErrorI18nAction(r.Context(), w, http.StatusBadRequest, action, "error.bad_request", h.i18n)
+		return
+	}
+	query := r.URL.Query()
+	result, err := h.templates.Import(r.Context(), service.ImportConfigTemplateRequest{
+		Bundle:     bundle,
+		Name:       query.Get("name"),
+		OnConflict: query.Get("on_conflict"),
+		OperatorID: adminID,
+	})
+	if err != nil {
+		var importErr *service.ConfigTemplateImportError
+		if errors.As(err, &importErr) {
+			status := http.StatusUnprocessableEntity
+			if importErr.Conflict {
+				status = http.StatusConflict
+			}
+			respondJSON(w, status, map[string]any{
+				"error":    importErr.Message,
+				"errors":   importErr.Errors,
+				"conflict": importErr.Conflict,
+				"action":   action,
+			})
+			return
+		}
+		h.respondServiceError(r.Context(), w, action, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"data": result})
+}
+
+func (h *AdminConfigTemplateHandler) respondServiceError(ctx context.Context, w http.ResponseWriter, action string, err error) {
+	status := http.StatusInternalServerError
+	key := "error.internal_server_error"
+	if errors.Is(err, service.ErrNotFound) {
+		status = http.StatusNotFound
+		key = "error.not_found"
+	} else if errors.Is(err, service.ErrBadRequest) {
+		status = http.StatusBadRequest
+		key = "error.bad_request"
+	} else if errors.Is(err, service.ErrConflict) {
+		status = http.StatusConflict
+		key = "error.conflict"
+	} else if errors.Is(err, service.ErrNotImplemented) {
+		status = http.StatusNotImplemented
+		key = "error.service_unavailable"
+	}
+	RespondErrorI18nAction(ctx, w, status, action, key, h.i18n)
+}
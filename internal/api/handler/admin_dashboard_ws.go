@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/creamcroissant/xboard/internal/service"
+	"github.com/creamcroissant/xboard/internal/support/i18n"
+)
+
+const (
+	// dashboardPingInterval 为服务端心跳间隔；客户端超过两个间隔没有任何消息即视为断开。
+	dashboardPingInterval = 30 * time.Second
+	// dashboardWriteTimeout 限制单条消息的写入耗时，避免卡住的连接占用名额。
+	dashboardWriteTimeout = 10 * time.Second
+	// dashboardMaxMessageBytes 为客户端单条消息的大小上限。
+	dashboardMaxMessageBytes = 4 << 10
+)
+
+// dashboardClientMessage 是客户端发来的消息：pong 回应心跳，subscribe 替换关注的主机列表（空列表表示全部）。
+type dashboardClientMessage struct {
+	Type    string  `json:"type"`
+	HostIDs []int64 `json:"host_ids"`
+}
+
+type dashboardPing struct {
+	Type      string `json:"type"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// AdminDashboardWSHandler pushes agent-host status changes, metrics and core-switch results to the admin dashboard.
+type AdminDashboardWSHandler struct {
+	hub          *service.DashboardHub
+	i18n         *i18n.Manager
+	pingInterval time.Duration
+}
+
+// NewAdminDashboardWSHandler creates an admin dashboard WebSocket handler.
+func NewAdminDashboardWSHandler(hub *service.DashboardHub, i18nMgr *i18n.Manager) *AdminDashboardWSHandler {
+	return &AdminDashboardWSHandler{hub: hub, i18n: i18nMgr, pingInterval: dashboardPingInterval}
+}
+
+// Serve handles GET /api/v2/{securePath}/ws.
+// 浏览器无法设置 Authorization 头，可通过子协议 ["bearer", token] 传递管理员令牌；
+// hosts 查询参数为逗号分隔的主机 ID，用于初始过滤，连接建立后可发送 subscribe 消息修改。
+func (h *AdminDashboardWSHandler) Serve(w http.ResponseWriter, r *http.Request) {
+	const action = "admin.dashboard.ws"
+	if h.hub == nil {
+		RespondErrorI18nAction(r.Context(), w, http.StatusServiceUnavailable, action, "error.service_unavailable", h.i18n)
+		return
+	}
+	hostIDs, err := parseDashboardHostIDs(r.URL.Query().Get("hosts"))
+	if err != nil {
+		RespondErrorI18nAction(r.Context(), w, http.StatusBadRequest, action, "error.bad_request", h.i18n)
+		return
+	}
+	sub, err := h.hub.Subscribe(hostIDs)
+	if err != nil {
+		if errors.Is(err, service.ErrTooManyDashboardClients) {
+			RespondErrorI18nAction(r.Context(), w, http.StatusServiceUnavailable, action, "error.too_many_connections", h.i18n)
+			return
+		}
+		RespondErrorI18nAction(r.Context(), w, http.StatusInternalServerError, action, "error.internal_server_error", h.i18n)
+		return
+	}
+	defer sub.Close()
+
+	server := websocket.Server{
+		Handshake: selectBearerProtocol,
+		Handler: func(conn *websocket.Conn) {
+			h.serveConn(conn, sub)
+		},
+	}
+	server.ServeHTTP(w, r)
+}
+
+// selectBearerProtocol 在客户端通过子协议携带令牌时回选 bearer，否则不协商子协议。
+// 身份已由管理员鉴权中间件校验且令牌不来自 Cookie，因此不再额外校验 Origin。
+func selectBearerProtocol(config *websocket.Config, _ *http.Request) error {
+	for _, protocol := range config.Protocol {
+		if strings.EqualFold(protocol, "bearer") {
+			config.Protocol = []string{protocol}
+			return nil
+		}
+	}
+	config.Protocol = nil
+	return nil
+}
+
+func (h *AdminDashboardWSHandler) serveConn(conn *websocket.Conn, sub *service.DashboardSubscription) {
+	defer conn.Close()
+	conn.MaxPayloadBytes = dashboardMaxMessageBytes
+	// 劫持后的连接会沿用 HTTP 服务的读写超时，先清除再按心跳重新设置。
+	_ = conn.SetDeadline(time.Time{})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.readLoop(conn, sub)
+	}()
+
+	ticker := time.NewTicker(h.pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case event, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			if err := sendDashboardMessage(conn, event); err != nil {
+				return
+			}
+		case now := <-ticker.C:
+			if err := sendDashboardMessage(conn, dashboardPing{Type: "ping", Timestamp: now.Unix()}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// readLoop 读取客户端消息；任何消息都会刷新读超时，超时、连接关闭或消息非法时返回。
+func (h *AdminDashboardWSHandler) readLoop(conn *websocket.Conn, sub *service.DashboardSubscription) {
+	for {
+		if err := conn.SetReadDeadline(time.Now().Add(2 * h.pingInterval)); err != nil {
+			return
+		}
+		var msg dashboardClientMessage
+		if err := websocket.JSON.Receive(conn, &msg); err != nil {
+			return
+		}
+		if msg.Type == "subscribe" {
+			sub.SetHosts(msg.HostIDs)
+		}
+	}
+}
+
+func sendDashboardMessage(conn *websocket.Conn, payload any) error {
+	if err := conn.SetWriteDeadline(time.Now().Add(dashboardWriteTimeout)); err != nil {
+		return err
+	}
+	return websocket.JSON.Send(conn, payload)
+}
+
+func parseDashboardHostIDs(raw string) ([]int64, error) {
+	parts := splitCommaQuery(raw)
+	ids := make([]int64, 0, len(parts))
+	for _, part := range parts {
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, errors.New("invalid host id")
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
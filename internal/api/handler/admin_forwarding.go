@@ -40,7 +40,7 @@ func (h *AdminForwardingHandler) requireAdmin(w http.ResponseWriter, r *http.Req
 }
 
 // ListRules 处理 GET /api/v2/admin/forwarding/rules
-// 查询参数: agent_host_id (必填)
+// 查询参数: agent_host_id (必填)；每条规则的 TargetHealth 为 Agent 最近上报的目标健康状态
 func (h *AdminForwardingHandler) ListRules(w http.ResponseWriter, r *http.Request) {
 	if _, ok := h.requireAdmin(w, r); !ok {
 		return
@@ -79,15 +79,18 @@ func (h *AdminForwardingHandler) ListRules(w http.ResponseWriter, r *http.Reques
 
 // CreateRuleRequest 创建转发规则的请求体
 type CreateRuleRequest struct {
-	AgentHostID   int64  `json:"agent_host_id"`
-	Name          string `json:"name"`
-	Protocol      string `json:"protocol"`
-	ListenPort    int    `json:"listen_port"`
-	TargetAddress string `json:"target_address"`
-	TargetPort    int    `json:"target_port"`
-	Enabled       bool   `json:"enabled"`
-	Priority      int    `json:"priority"`
-	Remark        string `json:"remark"`
+	AgentHostID         int64                         `json:"agent_host_id"`
+	Name                string                        `json:"name"`
+	Protocol            string                        `json:"protocol"`
+	ListenPort          int                           `json:"listen_port"`
+	TargetAddress       string                        `json:"target_address"`
+	TargetPort          int                           `json:"target_port"`
+	Targets             []repository.ForwardingTarget `json:"targets"`
+	HealthCheckInterval int                           `json:"health_check_interval"`
+	HealthCheckTimeout  int                           `json:"health_check_timeout"`
+	Enabled             bool                          `json:"enabled"`
+	Priority            int                           `json:"priority"`
+	Remark              string                        `json:"remark"`
 }
 
 // CreateRule 处理 POST /api/v2/admin/forwarding/rules
@@ -105,16 +108,19 @@ func (h *AdminForwardingHandler) CreateRule(w http.ResponseWriter, r *http.Reque
 
 	ctx := r.Context()
 	rule, err := h.forwarding.CreateRule(ctx, service.CreateForwardingRuleRequest{
-		AgentHostID:   req.AgentHostID,
-		Name:          req.Name,
-		Protocol:      req.Protocol,
-		ListenPort:    req.ListenPort,
-		TargetAddress: req.TargetAddress,
-		TargetPort:    req.TargetPort,
-		Enabled:       req.Enabled,
-		Priority:      req.Priority,
-		Remark:        req.Remark,
-		OperatorID:    &adminID,
+		AgentHostID:         req.AgentHostID,
+		Name:                req.Name,
+		Protocol:            req.Protocol,
+		ListenPort:          req.ListenPort,
+		TargetAddress:       req.TargetAddress,
+		TargetPort:          req.TargetPort,
+		Targets:             req.Targets,
+		HealthCheckInterval: req.HealthCheckInterval,
+		HealthCheckTimeout:  req.HealthCheckTimeout,
+		Enabled:             req.Enabled,
+		Priority:            req.Priority,
+		Remark:              req.Remark,
+		OperatorID:          &adminID,
 	})
 	if err != nil {
 		// 根据错误类型返回不同的状态码
@@ -124,6 +130,9 @@ func (h *AdminForwardingHandler) CreateRule(w http.ResponseWriter, r *http.Reque
 			errors.Is(err, service.ErrInvalidListenPort) ||
 			errors.Is(err, service.ErrInvalidTargetPort) ||
 			errors.Is(err, service.ErrInvalidTargetAddress) ||
+			errors.Is(err, service.ErrInvalidTargetWeight) ||
+			errors.Is(err, service.ErrTargetRequired) ||
+			errors.Is(err, service.ErrInvalidHealthCheck) ||
 			errors.Is(err, service.ErrRuleNameRequired) ||
 			errors.Is(err, service.ErrAgentHostRequired) {
 			status = http.StatusBadRequest
@@ -148,14 +157,17 @@ func (h *AdminForwardingHandler) CreateRule(w http.ResponseWriter, r *http.Reque
 
 // UpdateRuleRequest 更新转发规则的请求体
 type UpdateRuleRequest struct {
-	Name          *string `json:"name,omitempty"`
-	Protocol      *string `json:"protocol,omitempty"`
-	ListenPort    *int    `json:"listen_port,omitempty"`
-	TargetAddress *string `json:"target_address,omitempty"`
-	TargetPort    *int    `json:"target_port,omitempty"`
-	Enabled       *bool   `json:"enabled,omitempty"`
-	Priority      *int    `json:"priority,omitempty"`
-	Remark        *string `json:"remark,omitempty"`
+	Name                *string                        `json:"name,omitempty"`
+	Protocol            *string                        `json:"protocol,omitempty"`
+	ListenPort          *int                           `json:"listen_port,omitempty"`
+	TargetAddress       *string                        `json:"target_address,omitempty"`
+	TargetPort          *int                           `json:"target_port,omitempty"`
+	Targets             *[]repository.ForwardingTarget `json:"targets,omitempty"`
+	HealthCheckInterval *int                           `json:"health_check_interval,omitempty"`
+	HealthCheckTimeout  *int                           `json:"health_check_timeout,omitempty"`
+	Enabled             *bool                          `json:"enabled,omitempty"`
+	Priority            *int                           `json:"priority,omitempty"`
+	Remark              *string                        `json:"remark,omitempty"`
 }
 
 // UpdateRule 处理 PUT /api/v2/admin/forwarding/rules/{id}
@@ -180,15 +192,18 @@ func (h *AdminForwardingHandler) UpdateRule(w http.ResponseWriter, r *http.Reque
 
 	ctx := r.Context()
 	rule, err := h.forwarding.UpdateRule(ctx, id, service.UpdateForwardingRuleRequest{
-		Name:          req.Name,
-		Protocol:      req.Protocol,
-		ListenPort:    req.ListenPort,
-		TargetAddress: req.TargetAddress,
-		TargetPort:    req.TargetPort,
-		Enabled:       req.Enabled,
-		Priority:      req.Priority,
-		Remark:        req.Remark,
-		OperatorID:    &adminID,
+		Name:                req.Name,
+		Protocol:            req.Protocol,
+		ListenPort:          req.ListenPort,
+		TargetAddress:       req.TargetAddress,
+		TargetPort:          req.TargetPort,
+		Targets:             req.Targets,
+		HealthCheckInterval: req.HealthCheckInterval,
+		HealthCheckTimeout:  req.HealthCheckTimeout,
+		Enabled:             req.Enabled,
+		Priority:            req.Priority,
+		Remark:              req.Remark,
+		OperatorID:          &adminID,
 	})
 	if err != nil {
 		status := http.StatusInternalServerError
@@ -196,7 +211,10 @@ func (h *AdminForwardingHandler) UpdateRule(w http.ResponseWriter, r *http.Reque
 		if errors.Is(err, service.ErrInvalidProtocol) ||
 			errors.Is(err, service.ErrInvalidListenPort) ||
 			errors.Is(err, service.ErrInvalidTargetPort) ||
-			errors.Is(err, service.ErrInvalidTargetAddress) {
+			errors.Is(err, service.ErrInvalidTargetAddress) ||
+			errors.Is(err, service.ErrInvalidTargetWeight) ||
+			errors.Is(err, service.ErrTargetRequired) ||
+			errors.Is(err, service.ErrInvalidHealthCheck) {
 			status = http.StatusBadRequest
 			key = "error.bad_request"
 		} else if errors.Is(err, service.ErrPortConflict) {
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+	"github.com/creamcroissant/xboard/internal/service"
+	"github.com/creamcroissant/xboard/internal/support/i18n"
+)
+
+// AdminLoginLockoutHandler lets admins inspect and clear login lockouts.
+type AdminLoginLockoutHandler struct {
+	lockouts service.LoginLockoutService
+	i18n     *i18n.Manager
+}
+
+// NewAdminLoginLockoutHandler creates an admin login lockout handler.
+func NewAdminLoginLockoutHandler(lockouts service.LoginLockoutService, i18nMgr *i18n.Manager) *AdminLoginLockoutHandler {
+	return &AdminLoginLockoutHandler{lockouts: lockouts, i18n: i18nMgr}
+}
+
+// AdminLoginLockoutResponse represents a login lockout row.
+type AdminLoginLockoutResponse struct {
+	ID               int64  `json:"id"`
+	Identifier       string `json:"identifier"`
+	IP               string `json:"ip"`
+	FailedCount      int    `json:"failed_count"`
+	LockCount        int    `json:"lock_count"`
+	LockedUntil      int64  `json:"locked_until"`
+	Locked           bool   `json:"locked"`
+	RemainingSeconds int64  `json:"remaining_seconds"`
+	LastFailedAt     int64  `json:"last_failed_at"`
+	CreatedAt        int64  `json:"created_at"`
+	UpdatedAt        int64  `json:"updated_at"`
+}
+
+type adminLoginLockoutClearRequest struct {
+	Identifier string `json:"identifier"`
+}
+
+// Fetch handles GET /api/v2/{securePath}/login-lockouts.
+// active=1 时仅返回仍在冷却中的记录。
+func (h *AdminLoginLockoutHandler) Fetch(w http.ResponseWriter, r *http.Request) {
+	const action = "admin.login_lockout.fetch"
+	if h.lockouts == nil {
+		RespondErrorI18nAction(r.Context(), w, http.StatusServiceUnavailable, action, "error.service_unavailable", h.i18n)
+		return
+	}
+
+	now := time.Now().Unix()
+	query := r.URL.Query()
+	filter := repository.LoginLockoutFilter{
+		Now:    now,
+		Limit:  clampQueryInt(query.Get("limit"), 20),
+		Offset: clampNonNegativeQueryInt(query.Get("offset"), 0),
+	}
+	if identifier := strings.TrimSpace(query.Get("identifier")); identifier != "" {
+		filter.Identifier = &identifier
+	}
+	switch strings.ToLower(strings.TrimSpace(query.Get("active"))) {
+	case "1", "true", "yes":
+		filter.ActiveOnly = true
+	}
+
+	lockouts, total, err := h.lockouts.List(r.Context(), filter)
+	if err != nil {
+		RespondErrorI18nAction(r.Context(), w, http.StatusInternalServerError, action, "error.internal_server_error", h.i18n)
+		return
+	}
+
+	items := make([]AdminLoginLockoutResponse, 0, len(lockouts))
+	for _, lockout := range lockouts {
+		item := AdminLoginLockoutResponse{
+			ID:           lockout.ID,
+			Identifier:   lockout.Identifier,
+			IP:           lockout.IP,
+			FailedCount:  lockout.FailedCount,
+			LockCount:    lockout.LockCount,
+			LockedUntil:  lockout.LockedUntil,
+			LastFailedAt: lockout.LastFailedAt,
+			CreatedAt:    lockout.CreatedAt,
+			UpdatedAt:    lockout.UpdatedAt,
+		}
+		if lockout.LockedUntil > now {
+			item.Locked = true
+			item.RemainingSeconds = lockout.LockedUntil - now
+		}
+		items = append(items, item)
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"data":  items,
+		"total": total,
+	})
+}
+
+// Clear handles POST /api/v2/{securePath}/login-lockouts/clear.
+// 清除账号在所有来源 IP 上的失败计数与锁定。
+func (h *AdminLoginLockoutHandler) Clear(w http.ResponseWriter, r *http.Request) {
+	const action = "admin.login_lockout.clear"
+	if h.lockouts == nil {
+		RespondErrorI18nAction(r.Context(), w, http.StatusServiceUnavailable, action, "error.service_unavailable", h.i18n)
+		return
+	}
+
+	var req adminLoginLockoutClearRequest
+	if err := decodeJSON(r, &req); err != nil {
+		RespondErrorI18nAction(r.Context(), w, http.StatusBadRequest, action, "error.bad_request", h.i18n)
+		return
+	}
+
+	cleared, err := h.lockouts.Clear(r.Context(), req.Identifier)
+	if err != nil {
+		if errors.Is(err, service.ErrBadRequest) {
+			RespondErrorI18nAction(r.Context(), w, http.StatusBadRequest, action, "error.bad_request", h.i18n)
+			return
+		}
+		RespondErrorI18nAction(r.Context(), w, http.StatusInternalServerError, action, "error.internal_server_error", h.i18n)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"data": map[string]any{"cleared": cleared},
+	})
+}
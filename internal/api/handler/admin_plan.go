@@ -254,3 +254,77 @@ func (h *AdminPlanHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	RespondSuccessI18n(r.Context(), w, "success.deleted", h.i18n, nil)
 }
+
+// Groups handles GET /plan/{id}/groups
+func (h *AdminPlanHandler) Groups(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		RespondErrorI18nAction(r.Context(), w, http.StatusBadRequest, "admin.plan.groups", "error.bad_request", h.i18n)
+		return
+	}
+	groups, err := h.admin.PlanGroups(r.Context(), id)
+	if err != nil {
+		h.respondGroupsError(w, r, "admin.plan.groups", err)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"data": groups, "count": len(groups)})
+}
+
+// AttachGroups handles POST /plan/{id}/groups
+func (h *AdminPlanHandler) AttachGroups(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		RespondErrorI18nAction(r.Context(), w, http.StatusBadRequest, "admin.plan.groups.attach", "error.bad_request", h.i18n)
+		return
+	}
+	var payload service.AdminPlanGroupsInput
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		RespondErrorI18nAction(r.Context(), w, http.StatusBadRequest, "admin.plan.groups.attach", "error.bad_request", h.i18n)
+		return
+	}
+	groups, err := h.admin.AttachGroups(r.Context(), id, payload.GroupIDs)
+	if err != nil {
+		h.respondGroupsError(w, r, "admin.plan.groups.attach", err)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"data": groups, "count": len(groups)})
+}
+
+// DetachGroup handles DELETE /plan/{id}/groups/{group_id}
+func (h *AdminPlanHandler) DetachGroup(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		RespondErrorI18nAction(r.Context(), w, http.StatusBadRequest, "admin.plan.groups.detach", "error.bad_request", h.i18n)
+		return
+	}
+	groupID, err := strconv.ParseInt(chi.URLParam(r, "group_id"), 10, 64)
+	if err != nil {
+		RespondErrorI18nAction(r.Context(), w, http.StatusBadRequest, "admin.plan.groups.detach", "error.bad_request", h.i18n)
+		return
+	}
+	groups, err := h.admin.DetachGroups(r.Context(), id, []int64{groupID})
+	if err != nil {
+		h.respondGroupsError(w, r, "admin.plan.groups.detach", err)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"data": groups, "count": len(groups)})
+}
+
+func (h *AdminPlanHandler) respondGroupsError(w http.ResponseWriter, r *http.Request, action string, err error) {
+	status := http.StatusBadRequest
+	key := "error.bad_request"
+	if errors.Is(err, service.ErrNotFound) {
+		status = http.StatusNotFound
+		key = "error.not_found"
+	}
+	RespondErrorI18nAction(r.Context(), w, status, action, key, h.i18n)
+}
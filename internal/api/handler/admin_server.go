@@ -3,11 +3,15 @@
 package handler
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/creamcroissant/xboard/internal/api/requestctx"
 	"github.com/creamcroissant/xboard/internal/service"
+	"github.com/go-chi/chi/v5"
 )
 
 // AdminServerHandler 提供管理端节点/分组/路由相关接口。
@@ -37,6 +41,10 @@ func (h *AdminServerHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		h.handleGroupFetch(w, r)
 	case strings.HasPrefix(action, "/server/route") && strings.HasSuffix(action, "/fetch") && r.Method == http.MethodGet:
 		h.handleRouteFetch(w, r)
+	case strings.HasPrefix(action, "/server/route/save") && r.Method == http.MethodPost:
+		h.handleRouteSave(w, r)
+	case strings.HasPrefix(action, "/server/route/drop") && r.Method == http.MethodPost:
+		h.handleRouteDrop(w, r)
 	case isAdminServerNodeFetch(action) && r.Method == http.MethodGet:
 		h.handleNodeFetch(w, r)
 	case strings.HasPrefix(action, "/server/manage/save") && r.Method == http.MethodPost:
@@ -47,6 +55,12 @@ func (h *AdminServerHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		h.handleNodeBatchDrop(w, r)
 	case strings.HasPrefix(action, "/server/manage/batchUpdate") && r.Method == http.MethodPost:
 		h.handleNodeBatchUpdate(w, r)
+	case strings.TrimSuffix(action, "/") == "/server/manage/tags" && r.Method == http.MethodGet:
+		h.handleTagFetch(w, r)
+	case strings.HasPrefix(action, "/server/manage/tags/assign") && r.Method == http.MethodPost:
+		h.handleTagUpdate(w, r, "admin.server.manage.tags.assign", h.servers.AssignTags)
+	case strings.HasPrefix(action, "/server/manage/tags/remove") && r.Method == http.MethodPost:
+		h.handleTagUpdate(w, r, "admin.server.manage.tags.remove", h.servers.RemoveTags)
 	default:
 		respondNotImplemented(w, "admin.server", r)
 	}
@@ -72,6 +86,48 @@ func (h *AdminServerHandler) handleRouteFetch(w http.ResponseWriter, r *http.Req
 	respondJSON(w, http.StatusOK, map[string]any{"data": routes, "count": len(routes)})
 }
 
+func (h *AdminServerHandler) handleRouteSave(w http.ResponseWriter, r *http.Request) {
+	// 新建或更新路由规则，保存前由服务层校验匹配值与动作。
+	var input service.AdminServerRouteSaveInput
+	if err := decodeJSON(r, &input); err != nil {
+		RespondErrorI18n(r.Context(), w, http.StatusBadRequest, "admin.server.route.save", h.servers.I18n())
+		return
+	}
+	route, err := h.servers.SaveRoute(r.Context(), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrBadRequest):
+			respondError(w, http.StatusUnprocessableEntity, "admin.server.route.save", err)
+		case errors.Is(err, service.ErrNotFound):
+			RespondErrorI18nAction(r.Context(), w, http.StatusNotFound, "admin.server.route.save", "error.not_found", h.servers.I18n())
+		default:
+			RespondErrorI18n(r.Context(), w, http.StatusInternalServerError, "admin.server.route.save", h.servers.I18n())
+		}
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"data": route})
+}
+
+func (h *AdminServerHandler) handleRouteDrop(w http.ResponseWriter, r *http.Request) {
+	// 删除单条路由规则。
+	var input struct {
+		ID int64 `json:"id"`
+	}
+	if err := decodeJSON(r, &input); err != nil {
+		RespondErrorI18n(r.Context(), w, http.StatusBadRequest, "admin.server.route.drop", h.servers.I18n())
+		return
+	}
+	if err := h.servers.DeleteRoute(r.Context(), input.ID); err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			RespondErrorI18nAction(r.Context(), w, http.StatusNotFound, "admin.server.route.drop", "error.not_found", h.servers.I18n())
+			return
+		}
+		RespondErrorI18n(r.Context(), w, http.StatusInternalServerError, "admin.server.route.drop", h.servers.I18n())
+		return
+	}
+	RespondSuccessI18n(r.Context(), w, "success.deleted", h.servers.I18n(), nil)
+}
+
 func (h *AdminServerHandler) handleNodeFetch(w http.ResponseWriter, r *http.Request) {
 	// 返回节点列表给管理端。
 	nodes, err := h.servers.Nodes(r.Context())
@@ -94,7 +150,11 @@ func (h *AdminServerHandler) handleNodeSave(w http.ResponseWriter, r *http.Reque
 		return
 	}
 	if err := h.servers.SaveNode(r.Context(), input); err != nil {
-		RespondErrorI18n(r.Context(), w, http.StatusInternalServerError, "admin.server.manage.save", h.servers.I18n())
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrBadRequest) {
+			status = http.StatusUnprocessableEntity
+		}
+		RespondErrorI18n(r.Context(), w, status, "admin.server.manage.save", h.servers.I18n())
 		return
 	}
 	RespondSuccessI18n(r.Context(), w, "success.updated", h.servers.I18n(), nil)
@@ -169,6 +229,66 @@ func (h *AdminServerHandler) handleNodeBatchUpdate(w http.ResponseWriter, r *htt
 	RespondSuccessI18n(r.Context(), w, "success.updated", h.servers.I18n(), nil)
 }
 
+func (h *AdminServerHandler) handleTagFetch(w http.ResponseWriter, r *http.Request) {
+	// 返回全部节点标签及每个标签的节点数。
+	tags, err := h.servers.Tags(r.Context())
+	if err != nil {
+		RespondErrorI18n(r.Context(), w, http.StatusInternalServerError, "admin.server.manage.tags", h.servers.I18n())
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"data": tags, "count": len(tags)})
+}
+
+func (h *AdminServerHandler) handleTagUpdate(w http.ResponseWriter, r *http.Request, action string, update func(context.Context, service.AdminServerTagsInput) (int, error)) {
+	// 批量给节点添加或移除标签，标签名统一转为小写。
+	var input service.AdminServerTagsInput
+	if err := decodeJSON(r, &input); err != nil {
+		RespondErrorI18n(r.Context(), w, http.StatusBadRequest, action, h.servers.I18n())
+		return
+	}
+	changed, err := update(r.Context(), input)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrBadRequest):
+			status = http.StatusUnprocessableEntity
+		case errors.Is(err, service.ErrNotFound):
+			status = http.StatusNotFound
+		}
+		RespondErrorI18n(r.Context(), w, status, action, h.servers.I18n())
+		return
+	}
+	RespondSuccessI18n(r.Context(), w, "success.updated", h.servers.I18n(), map[string]any{"updated": changed})
+}
+
+// UserEffectiveGroups handles GET /user/{id}/effective-groups，展示用户有效分组来源与可见节点。
+func (h *AdminServerHandler) UserEffectiveGroups(w http.ResponseWriter, r *http.Request) {
+	if h.servers == nil {
+		RespondErrorI18nAction(r.Context(), w, http.StatusServiceUnavailable, "admin.user.effective_groups", "error.service_unavailable", nil)
+		return
+	}
+	claims := requestctx.AdminFromContext(r.Context())
+	if claims.ID == "" {
+		RespondErrorI18n(r.Context(), w, http.StatusUnauthorized, "error.unauthorized", h.servers.I18n())
+		return
+	}
+	userID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		RespondErrorI18nAction(r.Context(), w, http.StatusBadRequest, "admin.user.effective_groups", "error.bad_request", h.servers.I18n())
+		return
+	}
+	view, err := h.servers.UserEffectiveGroups(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			RespondErrorI18nAction(r.Context(), w, http.StatusNotFound, "admin.user.effective_groups", "error.not_found", h.servers.I18n())
+			return
+		}
+		RespondErrorI18nAction(r.Context(), w, http.StatusInternalServerError, "admin.user.effective_groups", "error.internal_server_error", h.servers.I18n())
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"data": view})
+}
+
 func isAdminServerNodeFetch(action string) bool {
 	// 兼容不同路径写法的节点列表查询。
 	trimmed := strings.TrimSuffix(strings.TrimSpace(action), "/")
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+	"github.com/creamcroissant/xboard/internal/service"
+	"github.com/creamcroissant/xboard/internal/support/i18n"
+)
+
+// AdminShortLinkHandler exposes the admin short link list with hit analytics.
+type AdminShortLinkHandler struct {
+	shortLinks service.ShortLinkService
+	i18n       *i18n.Manager
+}
+
+// NewAdminShortLinkHandler creates an admin short link handler.
+func NewAdminShortLinkHandler(shortLinks service.ShortLinkService, i18nMgr *i18n.Manager) *AdminShortLinkHandler {
+	return &AdminShortLinkHandler{shortLinks: shortLinks, i18n: i18nMgr}
+}
+
+// AdminShortLinkResponse represents a short link row in the admin list.
+type AdminShortLinkResponse struct {
+	ID             int64  `json:"id"`
+	Code           string `json:"code"`
+	UserID         int64  `json:"user_id"`
+	TargetPath     string `json:"target_path"`
+	AccessCount    int64  `json:"access_count"`
+	LastAccessedAt int64  `json:"last_accessed_at"`
+	ExpiresAt      int64  `json:"expires_at"`
+	Expired        bool   `json:"expired"`
+	CreatedAt      int64  `json:"created_at"`
+}
+
+// Fetch handles GET /api/v2/{securePath}/short-links.
+// 命中次数由异步队列批量落库，列表中的 access_count 可能比实时访问滞后数秒。
+func (h *AdminShortLinkHandler) Fetch(w http.ResponseWriter, r *http.Request) {
+	const action = "admin.short_link.fetch"
+	if h.shortLinks == nil {
+		RespondErrorI18nAction(r.Context(), w, http.StatusServiceUnavailable, action, "error.service_unavailable", h.i18n)
+		return
+	}
+
+	filter := repository.ShortLinkFilter{
+		Limit:  clampQueryInt(r.URL.Query().Get("limit"), 20),
+		Offset: clampNonNegativeQueryInt(r.URL.Query().Get("offset"), 0),
+	}
+	if id := getInt64Query(r, "user_id"); id > 0 {
+		filter.UserID = &id
+	}
+	if q := r.URL.Query().Get("code"); q != "" {
+		filter.Code = &q
+	}
+
+	links, total, err := h.shortLinks.ListAll(r.Context(), filter)
+	if err != nil {
+		RespondErrorI18nAction(r.Context(), w, http.StatusInternalServerError, action, "error.internal_server_error", h.i18n)
+		return
+	}
+
+	now := time.Now().Unix()
+	items := make([]AdminShortLinkResponse, 0, len(links))
+	for _, link := range links {
+		items = append(items, AdminShortLinkResponse{
+			ID:             link.ID,
+			Code:           link.Code,
+			UserID:         link.UserID,
+			TargetPath:     link.TargetPath,
+			AccessCount:    link.AccessCount,
+			LastAccessedAt: link.LastAccessedAt,
+			ExpiresAt:      link.ExpiresAt,
+			Expired:        link.ExpiresAt > 0 && link.ExpiresAt < now,
+			CreatedAt:      link.CreatedAt,
+		})
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"data":  items,
+		"total": total,
+	})
+}
@@ -34,6 +34,8 @@ func (h *AdminStatHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		h.handleGetStats(w, r)
 	case action == "/getTrafficRank" && r.Method == http.MethodGet:
 		h.handleGetTrafficRank(w, r)
+	case action == "/getTrafficResets" && r.Method == http.MethodGet:
+		h.handleGetTrafficResets(w, r)
 	default:
 		respondNotImplemented(w, "admin.stat", r)
 	}
@@ -97,6 +99,50 @@ func (h *AdminStatHandler) handleGetTrafficRank(w http.ResponseWriter, r *http.R
 	respondJSON(w, http.StatusOK, result)
 }
 
+// handleGetTrafficResets 返回套餐周期重置时归档的用户流量。
+func (h *AdminStatHandler) handleGetTrafficResets(w http.ResponseWriter, r *http.Request) {
+	if h.stats == nil {
+		RespondErrorI18nAction(r.Context(), w, http.StatusServiceUnavailable, "admin.stat.traffic_reset", "error.service_unavailable", h.i18n)
+		return
+	}
+	claims := requestctx.AdminFromContext(r.Context())
+	if claims.ID == "" {
+		RespondErrorI18nAction(r.Context(), w, http.StatusUnauthorized, "admin.stat.traffic_reset", "error.unauthorized", h.i18n)
+		return
+	}
+	query := r.URL.Query()
+	startUnix := pickFirstNonEmpty(query.Get("start_time"), query.Get("startTime"))
+	startTime, err := parseDateOrUnix(startUnix, pickFirstNonEmpty(query.Get("start_date"), query.Get("startDate")), false)
+	if err != nil {
+		RespondErrorI18nAction(r.Context(), w, http.StatusBadRequest, "admin.stat.traffic_reset", "error.bad_request", h.i18n)
+		return
+	}
+	endUnix := pickFirstNonEmpty(query.Get("end_time"), query.Get("endTime"))
+	endTime, err := parseDateOrUnix(endUnix, pickFirstNonEmpty(query.Get("end_date"), query.Get("endDate")), strings.TrimSpace(endUnix) == "")
+	if err != nil {
+		RespondErrorI18nAction(r.Context(), w, http.StatusBadRequest, "admin.stat.traffic_reset", "error.bad_request", h.i18n)
+		return
+	}
+	limit := parsePositiveInt(query.Get("limit"), 20)
+	if limit > 200 {
+		limit = 200
+	}
+	page := parsePositiveInt(query.Get("page"), 1)
+	result, err := h.stats.GetTrafficResets(r.Context(), service.AdminStatTrafficResetInput{
+		UserID:    parsePositiveInt64(query.Get("user_id"), 0),
+		PlanID:    parsePositiveInt64(query.Get("plan_id"), 0),
+		StartTime: startTime,
+		EndTime:   endTime,
+		Limit:     limit,
+		Offset:    (page - 1) * limit,
+	})
+	if err != nil {
+		RespondErrorI18nAction(r.Context(), w, http.StatusInternalServerError, "admin.stat.traffic_reset", "error.internal_server_error", h.i18n)
+		return
+	}
+	respondJSON(w, http.StatusOK, result)
+}
+
 func (h *AdminStatHandler) handleGetStatUser(w http.ResponseWriter, r *http.Request) {
 	if h.stats == nil {
 		RespondErrorI18nAction(r.Context(), w, http.StatusServiceUnavailable, "admin.stat.user", "error.service_unavailable", h.i18n)
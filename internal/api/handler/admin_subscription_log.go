@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+// AdminSubscriptionLogHandler exposes subscription log analytics to admins.
+type AdminSubscriptionLogHandler struct {
+	logs service.SubscriptionLogService
+}
+
+// NewAdminSubscriptionLogHandler creates an admin subscription log handler.
+func NewAdminSubscriptionLogHandler(logs service.SubscriptionLogService) *AdminSubscriptionLogHandler {
+	return &AdminSubscriptionLogHandler{logs: logs}
+}
+
+// UniqueIPs handles GET /subscription-logs/unique-ips?window=24h&min_ips=3&limit=50.
+// 按不同 IP 数降序列出用户，用于排查账号共享。
+func (h *AdminSubscriptionLogHandler) UniqueIPs(w http.ResponseWriter, r *http.Request) {
+	const action = "unique_ips"
+	window, ok := parseSubscriptionLogWindow(w, r, action)
+	if !ok {
+		return
+	}
+	items, err := h.logs.UniqueIPsByUser(r.Context(), window, getIntQuery(r, "min_ips", 0), getIntQuery(r, "limit", 0))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, action, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"data": items})
+}
+
+// Clients handles GET /subscription-logs/clients?window=24h&user_id=.
+// 按客户端类型（clash、sing-box 等）统计拉取次数与用户数。
+func (h *AdminSubscriptionLogHandler) Clients(w http.ResponseWriter, r *http.Request) {
+	const action = "client_breakdown"
+	window, ok := parseSubscriptionLogWindow(w, r, action)
+	if !ok {
+		return
+	}
+	items, err := h.logs.ClientBreakdown(r.Context(), window, getInt64Query(r, "user_id"))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, action, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"data": items})
+}
+
+// UserIPs handles GET /subscription-logs/users/{id}?window=24h&limit=100.
+// 列出单个用户窗口内的不同 IP 及首末次出现时间。
+func (h *AdminSubscriptionLogHandler) UserIPs(w http.ResponseWriter, r *http.Request) {
+	const action = "user_ips"
+	userID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || userID <= 0 {
+		respondError(w, http.StatusBadRequest, action, fmt.Errorf("invalid user id / 用户 ID 无效"))
+		return
+	}
+	window, ok := parseSubscriptionLogWindow(w, r, action)
+	if !ok {
+		return
+	}
+	detail, err := h.logs.UserIPDetail(r.Context(), userID, window, getIntQuery(r, "limit", 0))
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, service.ErrBadRequest):
+			status = http.StatusBadRequest
+		}
+		respondError(w, status, action, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"data": detail})
+}
+
+// parseSubscriptionLogWindow 解析 window 参数，未传时由服务层使用默认窗口。
+func parseSubscriptionLogWindow(w http.ResponseWriter, r *http.Request, action string) (time.Duration, bool) {
+	raw := r.URL.Query().Get("window")
+	if raw == "" {
+		return 0, true
+	}
+	parsed, err := time.ParseDuration(raw)
+	if err != nil || parsed <= 0 {
+		respondError(w, http.StatusBadRequest, action, fmt.Errorf("invalid window %q, expected a duration like 24h / 时间窗口无效，应为 24h 这样的时长", raw))
+		return 0, false
+	}
+	return parsed, true
+}
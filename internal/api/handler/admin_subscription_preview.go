@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/creamcroissant/xboard/internal/api/requestctx"
+	"github.com/creamcroissant/xboard/internal/service"
+	"github.com/creamcroissant/xboard/internal/support/i18n"
+)
+
+// AdminSubscriptionPreviewHandler renders a user's subscription for support without the user's token.
+type AdminSubscriptionPreviewHandler struct {
+	subscription service.SubscriptionService
+	i18n         *i18n.Manager
+}
+
+// NewAdminSubscriptionPreviewHandler creates an admin subscription preview handler.
+func NewAdminSubscriptionPreviewHandler(subscription service.SubscriptionService, i18nMgr *i18n.Manager) *AdminSubscriptionPreviewHandler {
+	return &AdminSubscriptionPreviewHandler{subscription: subscription, i18n: i18nMgr}
+}
+
+// Preview handles GET /api/v2/{securePath}/user/{id}/subscription-preview.
+// 查询参数与 /client/subscribe 相同（flag、types、filter、tags、template_id、show_info、stack、sort），
+// 未传 flag 时按请求的 User-Agent 识别客户端；默认对凭据打码，reveal=true 返回原文。
+func (h *AdminSubscriptionPreviewHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	const action = "admin.user.subscription_preview"
+	if h.subscription == nil {
+		RespondErrorI18nAction(r.Context(), w, http.StatusServiceUnavailable, action, "error.service_unavailable", h.i18n)
+		return
+	}
+
+	userID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || userID <= 0 {
+		RespondErrorI18nAction(r.Context(), w, http.StatusBadRequest, action, "error.bad_request", h.i18n)
+		return
+	}
+	query := r.URL.Query()
+	reveal := false
+	if raw := query.Get("reveal"); raw != "" {
+		if reveal, err = strconv.ParseBool(raw); err != nil {
+			RespondErrorI18nAction(r.Context(), w, http.StatusBadRequest, action, "error.bad_request", h.i18n)
+			return
+		}
+	}
+	templateID, _ := strconv.ParseInt(query.Get("template_id"), 10, 64)
+
+	preview, err := h.subscription.Preview(r.Context(), userID, service.SubscriptionParams{
+		Lang:         requestctx.GetLanguage(r.Context()),
+		Types:        query.Get("types"),
+		Filter:       query.Get("filter"),
+		Flag:         query.Get("flag"),
+		UserAgent:    r.UserAgent(),
+		Host:         r.Host,
+		Scheme:       requestScheme(r),
+		Tags:         query.Get("tags"),
+		ShowUserInfo: query.Get("show_info") == "1" || query.Get("show_info") == "true",
+		TemplateID:   templateID,
+		Stack:        query.Get("stack"),
+		Sort:         query.Get("sort"),
+	}, reveal)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrBadRequest):
+			RespondErrorI18nAction(r.Context(), w, http.StatusBadRequest, action, "error.bad_request", h.i18n)
+		case errors.Is(err, service.ErrNotFound):
+			RespondErrorI18nAction(r.Context(), w, http.StatusNotFound, action, "error.not_found", h.i18n)
+		case errors.Is(err, service.ErrUserNotEligible):
+			RespondErrorI18nAction(r.Context(), w, http.StatusConflict, action, "error.account_disabled", h.i18n)
+		case errors.Is(err, service.ErrNoStackNodes):
+			RespondErrorI18nAction(r.Context(), w, http.StatusNotFound, action, "subscription.error.no_stack_nodes", h.i18n)
+		default:
+			slog.Error("preview user subscription failed", "error", err, "user_id", userID)
+			RespondErrorI18nAction(r.Context(), w, http.StatusInternalServerError, action, "error.internal_server_error", h.i18n)
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{"data": preview})
+}
@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	"github.com/creamcroissant/xboard/internal/api/requestctx"
+	"github.com/creamcroissant/xboard/internal/repository"
 	"github.com/creamcroissant/xboard/internal/service"
 	"github.com/go-chi/chi/v5"
 )
@@ -81,6 +82,10 @@ func (h *AdminUserHandler) handleUpdate(w http.ResponseWriter, r *http.Request)
 	}
 	user, err := h.users.Update(r.Context(), payload)
 	if err != nil {
+		if errors.Is(err, service.ErrConflict) {
+			RespondErrorI18nAction(r.Context(), w, http.StatusConflict, "admin.user.update", "error.conflict", h.users.I18n())
+			return
+		}
 		RespondErrorI18n(r.Context(), w, http.StatusBadRequest, "admin.user.update", h.users.I18n())
 		return
 	}
@@ -107,17 +112,47 @@ func (h *AdminUserHandler) handleExport(w http.ResponseWriter, r *http.Request)
 		RespondErrorI18n(r.Context(), w, http.StatusBadRequest, "admin.user.export", h.users.I18n())
 		return
 	}
-
-	csvData, err := h.users.Export(r.Context(), params.input)
+	format, err := service.NormalizeAdminUserExportFormat(params.format)
 	if err != nil {
+		RespondErrorI18n(r.Context(), w, http.StatusBadRequest, "admin.user.export", h.users.I18n())
+		return
+	}
+
+	out := &exportResponseWriter{w: w, contentType: "text/csv", filename: "users_export.csv"}
+	if format == service.AdminUserExportFormatJSON {
+		out.contentType = "application/json"
+		out.filename = "users_export.json"
+	}
+	err = h.users.Export(r.Context(), service.AdminUserExportInput{AdminUserFetchInput: params.input, Format: format}, out)
+	if err != nil && !out.wrote {
 		RespondErrorI18n(r.Context(), w, http.StatusInternalServerError, "admin.user.export", h.users.I18n())
 		return
 	}
+	// 没有任何数据写出时也要补齐响应头
+	out.writeHeader()
+}
 
-	w.Header().Set("Content-Type", "text/csv")
-	w.Header().Set("Content-Disposition", "attachment; filename=users_export.csv")
-	w.WriteHeader(http.StatusOK)
-	w.Write(csvData)
+// exportResponseWriter 在第一次写入时才发送下载响应头，便于出错时仍能返回 JSON 错误。
+type exportResponseWriter struct {
+	w           http.ResponseWriter
+	contentType string
+	filename    string
+	wrote       bool
+}
+
+func (e *exportResponseWriter) writeHeader() {
+	if e.wrote {
+		return
+	}
+	e.wrote = true
+	e.w.Header().Set("Content-Type", e.contentType)
+	e.w.Header().Set("Content-Disposition", "attachment; filename="+e.filename)
+	e.w.WriteHeader(http.StatusOK)
+}
+
+func (e *exportResponseWriter) Write(p []byte) (int, error) {
+	e.writeHeader()
+	return e.w.Write(p)
 }
 
 func (h *AdminUserHandler) handleImport(w http.ResponseWriter, r *http.Request) {
@@ -137,7 +172,11 @@ func (h *AdminUserHandler) handleImport(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	result, err := h.users.Import(r.Context(), data)
+	dryRun, ok := optionalQueryBool(w, r, "admin.user.import", "dry_run", h.users.I18n())
+	if !ok {
+		return
+	}
+	result, err := h.users.Import(r.Context(), data, dryRun != nil && *dryRun)
 	if err != nil {
 		RespondErrorI18n(r.Context(), w, http.StatusBadRequest, "admin.user.import", h.users.I18n())
 		return
@@ -150,6 +189,7 @@ type adminUserFetchParams struct {
 	input    service.AdminUserFetchInput
 	page     int
 	pageSize int
+	format   string
 }
 
 type adminUserFetchPayload struct {
@@ -160,6 +200,7 @@ type adminUserFetchPayload struct {
 	Status   *int                   `json:"status"`
 	Filter   []adminUserTableFilter `json:"filter"`
 	Sort     []adminUserTableSort   `json:"sort"`
+	Format   string                 `json:"format"`
 }
 
 type adminUserTableFilter struct {
@@ -196,6 +237,9 @@ func parseAdminUserFetchBody(r *http.Request) (adminUserFetchParams, error) {
 	keyword := strings.TrimSpace(payload.Keyword)
 	planID := payload.PlanID
 	status := payload.Status
+	var banned *bool
+	overQuota := false
+	expiringWithinDays := 0
 	for _, filter := range payload.Filter {
 		id := strings.ToLower(strings.TrimSpace(filter.ID))
 		if id == "" {
@@ -222,16 +266,42 @@ func parseAdminUserFetchBody(r *http.Request) (adminUserFetchParams, error) {
 			if parsed, ok := filter.firstInt(); ok {
 				status = &parsed
 			}
+		case "banned":
+			if parsed, ok := filter.firstBool(); ok {
+				banned = &parsed
+			}
+		case "over_quota":
+			if parsed, ok := filter.firstBool(); ok {
+				overQuota = parsed
+			}
+		case "expiring_within_days":
+			if parsed, ok := filter.firstInt(); ok && parsed > 0 {
+				expiringWithinDays = parsed
+			}
 		}
 	}
 	input := service.AdminUserFetchInput{
-		Query:  keyword,
-		Status: status,
-		PlanID: planID,
-		Limit:  pageSize,
-		Offset: offset,
+		Query:              keyword,
+		Status:             status,
+		PlanID:             planID,
+		Banned:             banned,
+		OverQuota:          overQuota,
+		ExpiringWithinDays: expiringWithinDays,
+		Limit:              pageSize,
+		Offset:             offset,
+	}
+	if len(payload.Sort) > 0 {
+		input.SortBy = normalizeAdminUserSortBy(payload.Sort[0].ID)
+		input.SortOrder = "asc"
+		if payload.Sort[0].Desc {
+			input.SortOrder = "desc"
+		}
+	}
+	format := payload.Format
+	if queryFormat := strings.TrimSpace(r.URL.Query().Get("format")); queryFormat != "" {
+		format = queryFormat
 	}
-	return adminUserFetchParams{input: input, page: page, pageSize: pageSize}, nil
+	return adminUserFetchParams{input: input, page: page, pageSize: pageSize, format: format}, nil
 }
 
 func parseAdminUserFetchQuery(r *http.Request) (adminUserFetchParams, error) {
@@ -248,6 +318,23 @@ func parseAdminUserFetchQuery(r *http.Request) (adminUserFetchParams, error) {
 			planID = &parsed
 		}
 	}
+	var banned *bool
+	if raw := strings.TrimSpace(query.Get("banned")); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return adminUserFetchParams{}, err
+		}
+		banned = &parsed
+	}
+	overQuota := false
+	if raw := strings.TrimSpace(query.Get("over_quota")); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return adminUserFetchParams{}, err
+		}
+		overQuota = parsed
+	}
+	expiringWithinDays := clampNonNegativeQueryInt(query.Get("expiring_within_days"), 0)
 	limit := clampQueryInt(query.Get("limit"), 20)
 	offset := clampQueryInt(query.Get("offset"), 0)
 	page := 1
@@ -255,13 +342,18 @@ func parseAdminUserFetchQuery(r *http.Request) (adminUserFetchParams, error) {
 		page = (offset / limit) + 1
 	}
 	input := service.AdminUserFetchInput{
-		Query:  query.Get("keyword"),
-		Status: status,
-		PlanID: planID,
-		Limit:  limit,
-		Offset: offset,
-	}
-	return adminUserFetchParams{input: input, page: page, pageSize: limit}, nil
+		Query:              query.Get("keyword"),
+		Status:             status,
+		PlanID:             planID,
+		Banned:             banned,
+		OverQuota:          overQuota,
+		ExpiringWithinDays: expiringWithinDays,
+		SortBy:             normalizeAdminUserSortBy(query.Get("sort_by")),
+		SortOrder:          strings.ToLower(strings.TrimSpace(query.Get("sort_order"))),
+		Limit:              limit,
+		Offset:             offset,
+	}
+	return adminUserFetchParams{input: input, page: page, pageSize: limit, format: query.Get("format")}, nil
 }
 
 func clampPageSize(value int) int {
@@ -322,6 +414,36 @@ func (f adminUserTableFilter) firstInt64() (int64, bool) {
 	return parsed, true
 }
 
+func (f adminUserTableFilter) firstBool() (bool, bool) {
+	var value bool
+	if err := json.Unmarshal(f.Value, &value); err == nil {
+		return value, true
+	}
+	parsed, err := strconv.ParseBool(strings.TrimSpace(f.firstString()))
+	if err != nil {
+		return false, false
+	}
+	return parsed, true
+}
+
+// normalizeAdminUserSortBy 将表格列名映射为可排序字段，未知列回退为默认的 id 排序。
+func normalizeAdminUserSortBy(raw string) string {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "traffic_used", "total_used", "used":
+		return repository.UserSortTrafficUsed
+	case "expired_at", "expiry":
+		return repository.UserSortExpiredAt
+	case "last_login_at", "last_login":
+		return repository.UserSortLastLoginAt
+	case "created_at":
+		return repository.UserSortCreatedAt
+	case "online_count":
+		return service.AdminUserSortOnlineCount
+	default:
+		return ""
+	}
+}
+
 func (f adminUserTableFilter) firstInt() (int, bool) {
 	value := strings.TrimSpace(f.firstString())
 	if value == "" {
@@ -433,6 +555,10 @@ func (h *AdminUserHandler) Update(w http.ResponseWriter, r *http.Request) {
 
 	user, err := h.users.Update(r.Context(), payload)
 	if err != nil {
+		if errors.Is(err, service.ErrConflict) {
+			RespondErrorI18nAction(r.Context(), w, http.StatusConflict, "admin.user.update", "error.conflict", h.users.I18n())
+			return
+		}
 		status := http.StatusBadRequest
 		if errors.Is(err, service.ErrNotFound) {
 			status = http.StatusNotFound
@@ -487,3 +613,50 @@ func (h *AdminUserHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 	h.handleGenerate(w, r)
 }
+
+// adminUserBulkRequest 是批量操作请求体，confirm 必须等于 ids 的数量以防误操作。
+type adminUserBulkRequest struct {
+	IDs     []int64            `json:"ids"`
+	Confirm int                `json:"confirm"`
+	Action  service.BulkAction `json:"action"`
+}
+
+// Bulk handles POST /user/bulk
+func (h *AdminUserHandler) Bulk(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	var payload adminUserBulkRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		RespondErrorI18n(r.Context(), w, http.StatusBadRequest, "admin.user.bulk", h.users.I18n())
+		return
+	}
+	if len(payload.IDs) == 0 || payload.Confirm != len(payload.IDs) {
+		RespondErrorI18n(r.Context(), w, http.StatusBadRequest, "admin.user.bulk", h.users.I18n())
+		return
+	}
+	// 与单个更新一致，不允许管理员封禁自己
+	if payload.Action.Type == service.BulkActionBan {
+		claims := requestctx.AdminFromContext(r.Context())
+		for _, id := range payload.IDs {
+			if isSelfAdminTarget(claims, id) {
+				RespondErrorI18n(r.Context(), w, http.StatusBadRequest, "admin.user.bulk", h.users.I18n())
+				return
+			}
+		}
+	}
+
+	result, err := h.users.BulkUpdate(r.Context(), payload.IDs, payload.Action)
+	if err != nil {
+		status := http.StatusBadRequest
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			status = http.StatusNotFound
+		case !errors.Is(err, service.ErrBadRequest):
+			status = http.StatusInternalServerError
+		}
+		RespondErrorI18n(r.Context(), w, status, "admin.user.bulk", h.users.I18n())
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"data": result})
+}
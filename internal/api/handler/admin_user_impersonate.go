@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/creamcroissant/xboard/internal/api/requestctx"
+	"github.com/creamcroissant/xboard/internal/service"
+	"github.com/creamcroissant/xboard/internal/support/i18n"
+)
+
+// AdminImpersonationHandler lets admins log in as a user for support purposes.
+type AdminImpersonationHandler struct {
+	auth service.AuthService
+	i18n *i18n.Manager
+}
+
+// NewAdminImpersonationHandler creates an admin impersonation handler.
+func NewAdminImpersonationHandler(auth service.AuthService, i18nMgr *i18n.Manager) *AdminImpersonationHandler {
+	return &AdminImpersonationHandler{auth: auth, i18n: i18nMgr}
+}
+
+// Impersonate handles POST /api/v2/{securePath}/user/{id}/impersonate.
+// 返回短时效的用户令牌，不附带刷新令牌，令牌内带有模拟登录标记。
+func (h *AdminImpersonationHandler) Impersonate(w http.ResponseWriter, r *http.Request) {
+	const action = "admin.user.impersonate"
+	if h.auth == nil {
+		RespondErrorI18nAction(r.Context(), w, http.StatusServiceUnavailable, action, "error.service_unavailable", h.i18n)
+		return
+	}
+
+	claims := requestctx.AdminFromContext(r.Context())
+	adminID, err := strconv.ParseInt(claims.ID, 10, 64)
+	if err != nil || adminID <= 0 {
+		RespondErrorI18nAction(r.Context(), w, http.StatusUnauthorized, action, "error.unauthorized", h.i18n)
+		return
+	}
+
+	userID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || userID <= 0 {
+		RespondErrorI18nAction(r.Context(), w, http.StatusBadRequest, action, "error.bad_request", h.i18n)
+		return
+	}
+
+	result, err := h.auth.Impersonate(r.Context(), adminID, userID, service.LoginInput{
+		IP:        clientIP(r),
+		UserAgent: r.UserAgent(),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUnauthorized), errors.Is(err, service.ErrNotFound):
+			RespondErrorI18nAction(r.Context(), w, http.StatusNotFound, action, "error.not_found", h.i18n)
+		case errors.Is(err, service.ErrAccountDisabled):
+			RespondErrorI18nAction(r.Context(), w, http.StatusConflict, action, "error.account_disabled", h.i18n)
+		default:
+			slog.Error("impersonate user failed", "error", err, "admin_id", adminID, "user_id", userID)
+			RespondErrorI18nAction(r.Context(), w, http.StatusInternalServerError, action, "error.internal_server_error", h.i18n)
+		}
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{"data": formatAuthResponse(result)})
+}
+
+// Stop handles POST /api/v1/user/impersonation/stop.
+// 以模拟登录令牌调用，记录管理员结束模拟登录的审计事件。
+func (h *AdminImpersonationHandler) Stop(w http.ResponseWriter, r *http.Request) {
+	const action = "user.impersonation.stop"
+	if h.auth == nil {
+		RespondErrorI18nAction(r.Context(), w, http.StatusServiceUnavailable, action, "error.service_unavailable", h.i18n)
+		return
+	}
+	claims := requestctx.UserFromContext(r.Context())
+	userID, err := strconv.ParseInt(claims.ID, 10, 64)
+	if err != nil || userID <= 0 {
+		RespondErrorI18nAction(r.Context(), w, http.StatusUnauthorized, action, "error.unauthorized", h.i18n)
+		return
+	}
+	if !claims.Impersonating() {
+		RespondErrorI18nAction(r.Context(), w, http.StatusBadRequest, action, "error.bad_request", h.i18n)
+		return
+	}
+	if err := h.auth.EndImpersonation(r.Context(), claims.ImpersonatorID, userID, service.LoginInput{
+		IP:        clientIP(r),
+		UserAgent: r.UserAgent(),
+	}); err != nil {
+		slog.Error("end impersonation failed", "error", err, "admin_id", claims.ImpersonatorID, "user_id", userID)
+		RespondErrorI18nAction(r.Context(), w, http.StatusInternalServerError, action, "error.internal_server_error", h.i18n)
+		return
+	}
+	RespondSuccessI18n(r.Context(), w, "success.updated", h.i18n, nil)
+}
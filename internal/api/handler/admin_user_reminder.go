@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/creamcroissant/xboard/internal/service"
+	"github.com/creamcroissant/xboard/internal/support/i18n"
+)
+
+// AdminUserReminderHandler exposes the reminder preview for admins.
+type AdminUserReminderHandler struct {
+	reminders service.UserReminderService
+	i18n      *i18n.Manager
+}
+
+// NewAdminUserReminderHandler creates an admin user reminder handler.
+func NewAdminUserReminderHandler(reminders service.UserReminderService, i18nMgr *i18n.Manager) *AdminUserReminderHandler {
+	return &AdminUserReminderHandler{reminders: reminders, i18n: i18nMgr}
+}
+
+// Preview handles GET /api/v2/{securePath}/user/reminders/preview.
+// 列出下一次提醒任务会通知的用户（已扣除冷却期内提醒过的用户）。
+func (h *AdminUserReminderHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	const action = "admin.user_reminder.preview"
+	if h.reminders == nil {
+		RespondErrorI18nAction(r.Context(), w, http.StatusServiceUnavailable, action, "error.service_unavailable", h.i18n)
+		return
+	}
+	preview, err := h.reminders.Preview(r.Context())
+	if err != nil {
+		RespondErrorI18nAction(r.Context(), w, http.StatusInternalServerError, action, "error.internal_server_error", h.i18n)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"data": preview})
+}
@@ -3,15 +3,19 @@ package handler
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/creamcroissant/xboard/internal/api/requestctx"
 	"github.com/creamcroissant/xboard/internal/repository"
 	"github.com/creamcroissant/xboard/internal/service"
 	"github.com/creamcroissant/xboard/internal/support/i18n"
+	"github.com/creamcroissant/xboard/internal/support/reality"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -33,6 +37,7 @@ type agentHostResponse struct {
 	Status                int     `json:"status"`
 	ProvisionStatus       int     `json:"provision_status"`
 	TemplateID            int64   `json:"template_id,omitempty"`
+	TemplateVersion       int64   `json:"template_version,omitempty"`
 	CoreVersion           string  `json:"core_version,omitempty"`
 	CPUTotal              float64 `json:"cpu_total"`
 	CPUUsed               float64 `json:"cpu_used"`
@@ -51,6 +56,14 @@ type agentHostResponse struct {
 	LastRestartAt         int64   `json:"last_restart_at"`
 	AgentVersion          string  `json:"agent_version,omitempty"`
 	CurrentCoreType       string  `json:"current_core_type,omitempty"`
+	ConnectionCount       int64   `json:"connection_count"`
+	ConnectionsAvailable  bool    `json:"connection_count_available"`
+	ConnectionCountSource string  `json:"connection_count_source,omitempty"`
+	Maintenance           bool    `json:"maintenance"`
+	DriftDetected         bool    `json:"drift_detected"`
+	SyncIntervalSeconds   int     `json:"sync_interval_seconds"`
+	ReportIntervalSeconds int     `json:"report_interval_seconds"`
+	State                 string  `json:"state"`
 	LastHeartbeatAt       int64   `json:"last_heartbeat_at"`
 	CreatedAt             int64   `json:"created_at"`
 	UpdatedAt             int64   `json:"updated_at"`
@@ -64,6 +77,7 @@ func newAgentHostResponse(host *repository.AgentHost) agentHostResponse {
 		Status:                host.Status,
 		ProvisionStatus:       host.ProvisionStatus,
 		TemplateID:            host.TemplateID,
+		TemplateVersion:       host.TemplateVersion,
 		CoreVersion:           host.CoreVersion,
 		CPUTotal:              host.CPUTotal,
 		CPUUsed:               host.CPUUsed,
@@ -82,6 +96,14 @@ func newAgentHostResponse(host *repository.AgentHost) agentHostResponse {
 		LastRestartAt:         host.LastRestartAt,
 		AgentVersion:          host.AgentVersion,
 		CurrentCoreType:       host.CurrentCoreType,
+		ConnectionCount:       host.ConnectionCount,
+		ConnectionsAvailable:  host.ConnectionCountSource != "",
+		ConnectionCountSource: host.ConnectionCountSource,
+		Maintenance:           host.Maintenance,
+		DriftDetected:         host.DriftDetected,
+		SyncIntervalSeconds:   host.SyncIntervalSeconds,
+		ReportIntervalSeconds: host.ReportIntervalSeconds,
+		State:                 host.DisplayState(time.Now().Unix()),
 		LastHeartbeatAt:       host.LastHeartbeatAt,
 		CreatedAt:             host.CreatedAt,
 		UpdatedAt:             host.UpdatedAt,
@@ -173,11 +195,31 @@ func (h *AgentHostHandler) Heartbeat(w http.ResponseWriter, r *http.Request) {
 }
 
 // List handles GET /api/v1/admin/agent-hosts
-// Returns all agent hosts for admin panel.
+// Returns a page of agent hosts filtered by limit/offset/q/status and ordered by sort_by/sort_order
+// (id, name, connection_count), with the total count.
 func (h *AgentHostHandler) List(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	hosts, err := h.service.List(ctx)
+	query := r.URL.Query()
+	// limit/offset 优先，兼容前端沿用的 page/page_size
+	limit := clampQueryInt(pickFirstNonEmpty(query.Get("limit"), query.Get("page_size")), 50)
+	if limit == 0 {
+		limit = 50
+	}
+	page := parsePositiveInt(query.Get("page"), 1)
+	filter := repository.AgentHostSearchFilter{
+		Keyword:   strings.TrimSpace(query.Get("q")),
+		State:     strings.TrimSpace(query.Get("status")),
+		SortBy:    strings.TrimSpace(query.Get("sort_by")),
+		SortOrder: strings.ToLower(strings.TrimSpace(query.Get("sort_order"))),
+		Limit:     limit,
+		Offset:    clampNonNegativeQueryInt(query.Get("offset"), (page-1)*limit),
+	}
+	hosts, total, err := h.service.Search(ctx, filter)
 	if err != nil {
+		if errors.Is(err, service.ErrBadRequest) {
+			RespondErrorI18nAction(ctx, w, http.StatusBadRequest, "agent_host.list", "error.bad_request", h.i18n)
+			return
+		}
 		slog.Error("agent_host.list failed", "error", err)
 		RespondErrorI18nAction(ctx, w, http.StatusInternalServerError, "agent_host.list", "error.internal_server_error", h.i18n)
 		return
@@ -190,7 +232,10 @@ func (h *AgentHostHandler) List(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]any{
-		"data": response,
+		"data":      response,
+		"total":     total,
+		"page":      filter.Offset/limit + 1,
+		"page_size": limit,
 	})
 }
 
@@ -438,32 +483,65 @@ func (h *AgentHostHandler) Delete(w http.ResponseWriter, r *http.Request) {
 }
 
 // RefreshAll handles POST /agent-hosts/refresh
-// Triggers a refresh of all agent hosts status.
+// Fans out a resync to every agent host with a bounded worker pool and reports per-host results.
+// Query: concurrency (workers), timeout (seconds). With "Accept: text/event-stream" each host result
+// is streamed as a "progress" event followed by a final "summary" event.
 func (h *AgentHostHandler) RefreshAll(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	if !h.requireAdmin(w, r) {
 		return
 	}
 
-	hosts, err := h.service.List(ctx)
+	query := r.URL.Query()
+	opts := service.AgentHostRefreshOptions{
+		Concurrency: int(parsePositiveInt64(query.Get("concurrency"), 0)),
+		Timeout:     time.Duration(parsePositiveInt64(query.Get("timeout"), 0)) * time.Second,
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			RespondErrorI18nAction(ctx, w, http.StatusInternalServerError, "agent_host.refresh_all", "error.internal_server_error", h.i18n)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		opts.Progress = func(result service.AgentHostRefreshResult) {
+			writeAgentHostRefreshSSE(w, "progress", result)
+			flusher.Flush()
+		}
+		summary, err := h.service.RefreshAll(ctx, opts)
+		if err != nil {
+			_, _ = fmt.Fprint(w, "event: error\ndata: {\"error\":\"refresh_failed\"}\n\n")
+			flusher.Flush()
+			return
+		}
+		writeAgentHostRefreshSSE(w, "summary", summary)
+		flusher.Flush()
+		return
+	}
+
+	summary, err := h.service.RefreshAll(ctx, opts)
 	if err != nil {
 		RespondErrorI18nAction(ctx, w, http.StatusInternalServerError, "agent_host.refresh_all", "error.internal_server_error", h.i18n)
 		return
 	}
+	respondJSON(w, http.StatusOK, map[string]any{
+		"data": summary,
+	})
+}
 
-	results := make([]map[string]any, 0, len(hosts))
-	for _, host := range hosts {
-		results = append(results, map[string]any{
-			"id":     host.ID,
-			"name":   host.Name,
-			"status": host.Status,
-		})
+func writeAgentHostRefreshSSE(w http.ResponseWriter, event string, payload any) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]any{
-		"data": results,
-	})
+	_, _ = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
 }
 
 // Refresh handles POST /agent-hosts/{id}/refresh
@@ -502,3 +580,522 @@ func (h *AgentHostHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 		},
 	})
 }
+
+// PreviewConfigRequest represents the request to preview a rendered config.
+type PreviewConfigRequest struct {
+	TemplateID int64 `json:"template_id"`
+}
+
+// PreviewConfig handles POST /agent-hosts/{id}/protocols/preview
+// Renders the given template against the agent without applying it.
+// Credentials are masked unless ?reveal=1 is provided.
+func (h *AgentHostHandler) PreviewConfig(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		RespondErrorI18nAction(ctx, w, http.StatusBadRequest, "agent_host.preview", "error.bad_request", h.i18n)
+		return
+	}
+
+	var req PreviewConfigRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.TemplateID <= 0 {
+		RespondErrorI18nAction(ctx, w, http.StatusBadRequest, "agent_host.preview", "error.bad_request", h.i18n)
+		return
+	}
+
+	reveal, ok := optionalQueryBool(w, r, "agent_host.preview", "reveal", h.i18n)
+	if !ok {
+		return
+	}
+
+	configJSON, compat, err := h.service.PreviewConfig(ctx, id, req.TemplateID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		key := "error.internal_server_error"
+		if errors.Is(err, service.ErrNotFound) {
+			status = http.StatusNotFound
+			key = "error.not_found"
+		} else if errors.Is(err, service.ErrBadRequest) {
+			status = http.StatusBadRequest
+			key = "error.bad_request"
+		}
+		RespondErrorI18nAction(ctx, w, status, "agent_host.preview", key, h.i18n)
+		return
+	}
+
+	var config json.RawMessage
+	if len(configJSON) > 0 {
+		config = configJSON
+		if reveal == nil || !*reveal {
+			masked, err := service.MaskPreviewConfig(configJSON)
+			if err != nil {
+				slog.Error("mask preview config failed", "agent_host_id", id, "error", err)
+				RespondErrorI18nAction(ctx, w, http.StatusInternalServerError, "agent_host.preview", "error.internal_server_error", h.i18n)
+				return
+			}
+			config = masked
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"data": map[string]any{
+			"config":        config,
+			"masked":        reveal == nil || !*reveal,
+			"compatibility": compat,
+		},
+	})
+}
+
+// AssignTemplateRequest represents the request to assign a config template.
+// Version 0 pins the template's latest version; template_id 0 clears the assignment.
+type AssignTemplateRequest struct {
+	TemplateID int64 `json:"template_id"`
+	Version    int64 `json:"version"`
+}
+
+// AssignTemplate handles PUT /agent-hosts/{id}/template
+// Assigns a config template and pins the chosen version to the agent host.
+func (h *AgentHostHandler) AssignTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		RespondErrorI18nAction(ctx, w, http.StatusBadRequest, "agent_host.assign_template", "error.bad_request", h.i18n)
+		return
+	}
+
+	var req AssignTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.TemplateID < 0 || req.Version < 0 {
+		RespondErrorI18nAction(ctx, w, http.StatusBadRequest, "agent_host.assign_template", "error.bad_request", h.i18n)
+		return
+	}
+
+	pinned, err := h.service.AssignTemplate(ctx, id, req.TemplateID, req.Version)
+	if err != nil {
+		status := http.StatusInternalServerError
+		key := "error.internal_server_error"
+		if errors.Is(err, service.ErrNotFound) || errors.Is(err, repository.ErrNotFound) {
+			status = http.StatusNotFound
+			key = "error.not_found"
+		} else if errors.Is(err, service.ErrBadRequest) {
+			status = http.StatusBadRequest
+			key = "error.bad_request"
+		}
+		RespondErrorI18nAction(ctx, w, status, "agent_host.assign_template", key, h.i18n)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"data": map[string]any{
+			"template_id":      req.TemplateID,
+			"template_version": pinned,
+		},
+	})
+}
+
+// TemplateCompatibility handles GET /agent-hosts/templates/{template_id}/compatibility
+// Lists the agents that report errors or warnings for the template.
+func (h *AgentHostHandler) TemplateCompatibility(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	templateID, err := strconv.ParseInt(chi.URLParam(r, "template_id"), 10, 64)
+	if err != nil || templateID <= 0 {
+		RespondErrorI18nAction(ctx, w, http.StatusBadRequest, "agent_host.template_compatibility", "error.bad_request", h.i18n)
+		return
+	}
+
+	agents, err := h.service.ListIncompatibleAgents(ctx, templateID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		key := "error.internal_server_error"
+		if errors.Is(err, service.ErrNotFound) {
+			status = http.StatusNotFound
+			key = "error.not_found"
+		} else if errors.Is(err, service.ErrBadRequest) {
+			status = http.StatusBadRequest
+			key = "error.bad_request"
+		}
+		RespondErrorI18nAction(ctx, w, status, "agent_host.template_compatibility", key, h.i18n)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"data": map[string]any{
+			"template_id":  templateID,
+			"incompatible": agents,
+			"total":        len(agents),
+		},
+	})
+}
+
+// RedetectCapabilities handles POST /agent-hosts/{id}/capabilities/redetect
+// Asks an online agent to re-detect core capabilities on its next report.
+func (h *AgentHostHandler) RedetectCapabilities(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		RespondErrorI18nAction(ctx, w, http.StatusBadRequest, "agent_host.redetect_capabilities", "error.bad_request", h.i18n)
+		return
+	}
+
+	if err := h.service.TriggerCapabilityRedetect(ctx, id); err != nil {
+		status := http.StatusInternalServerError
+		key := "error.internal_server_error"
+		if errors.Is(err, service.ErrNotFound) {
+			status = http.StatusNotFound
+			key = "error.not_found"
+		} else if errors.Is(err, service.ErrBadRequest) {
+			status = http.StatusBadRequest
+			key = "error.bad_request"
+		}
+		RespondErrorI18nAction(ctx, w, status, "agent_host.redetect_capabilities", key, h.i18n)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"data": true,
+	})
+}
+
+// EnableMaintenance handles POST /agent-hosts/{id}/maintenance
+// Pauses config and user pushes so an operator can debug the node by hand.
+func (h *AgentHostHandler) EnableMaintenance(w http.ResponseWriter, r *http.Request) {
+	h.setMaintenance(w, r, true)
+}
+
+// DisableMaintenance handles DELETE /agent-hosts/{id}/maintenance
+// Resumes pushes and forces the agent to re-apply the intended config.
+func (h *AgentHostHandler) DisableMaintenance(w http.ResponseWriter, r *http.Request) {
+	h.setMaintenance(w, r, false)
+}
+
+func (h *AgentHostHandler) setMaintenance(w http.ResponseWriter, r *http.Request, enabled bool) {
+	ctx := r.Context()
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		RespondErrorI18nAction(ctx, w, http.StatusBadRequest, "agent_host.maintenance", "error.bad_request", h.i18n)
+		return
+	}
+
+	if err := h.service.SetMaintenance(ctx, id, enabled); err != nil {
+		status := http.StatusInternalServerError
+		key := "error.internal_server_error"
+		if errors.Is(err, service.ErrNotFound) {
+			status = http.StatusNotFound
+			key = "error.not_found"
+		} else if errors.Is(err, service.ErrBadRequest) {
+			status = http.StatusBadRequest
+			key = "error.bad_request"
+		}
+		RespondErrorI18nAction(ctx, w, status, "agent_host.maintenance", key, h.i18n)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"data": map[string]any{
+			"id":          id,
+			"maintenance": enabled,
+		},
+	})
+}
+
+// UpdateIntervals handles PUT /agent-hosts/{id}/intervals
+// Overrides the global sync/report intervals for one host; 0 falls back to the global settings.
+func (h *AgentHostHandler) UpdateIntervals(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		RespondErrorI18nAction(ctx, w, http.StatusBadRequest, "agent_host.intervals", "error.bad_request", h.i18n)
+		return
+	}
+
+	var req struct {
+		SyncIntervalSeconds   int `json:"sync_interval_seconds"`
+		ReportIntervalSeconds int `json:"report_interval_seconds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondErrorI18nAction(ctx, w, http.StatusBadRequest, "agent_host.intervals", "error.bad_request", h.i18n)
+		return
+	}
+
+	if err := h.service.SetIntervals(ctx, id, req.SyncIntervalSeconds, req.ReportIntervalSeconds); err != nil {
+		status := http.StatusInternalServerError
+		key := "error.internal_server_error"
+		if errors.Is(err, service.ErrNotFound) {
+			status = http.StatusNotFound
+			key = "error.not_found"
+		} else if errors.Is(err, service.ErrBadRequest) {
+			status = http.StatusBadRequest
+			key = "error.bad_request"
+		}
+		RespondErrorI18nAction(ctx, w, status, "agent_host.intervals", key, h.i18n)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"data": map[string]any{
+			"id":                      id,
+			"sync_interval_seconds":   req.SyncIntervalSeconds,
+			"report_interval_seconds": req.ReportIntervalSeconds,
+		},
+	})
+}
+
+// RotateToken handles POST /agent-hosts/{id}/token/rotate
+// Issues a new agent token. The optional body {"previous_token_grace_seconds": n} sets how long the old
+// token keeps working; 0 revokes it immediately. The new token is pushed only to streams authenticated
+// before the rotation.
+func (h *AgentHostHandler) RotateToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		RespondErrorI18nAction(ctx, w, http.StatusBadRequest, "agent_host.rotate_token", "error.bad_request", h.i18n)
+		return
+	}
+
+	var req service.AgentTokenRotationInput
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			RespondErrorI18nAction(ctx, w, http.StatusBadRequest, "agent_host.rotate_token", "error.bad_request", h.i18n)
+			return
+		}
+	}
+
+	rotation, err := h.service.RotateToken(ctx, id, req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		key := "error.internal_server_error"
+		if errors.Is(err, service.ErrNotFound) {
+			status = http.StatusNotFound
+			key = "error.not_found"
+		} else if errors.Is(err, service.ErrBadRequest) {
+			status = http.StatusBadRequest
+			key = "error.bad_request"
+		}
+		RespondErrorI18nAction(ctx, w, status, "agent_host.rotate_token", key, h.i18n)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"data": rotation,
+	})
+}
+
+// Diagnostics handles GET /agent-hosts/{id}/diagnostics
+// Returns the latest self-diagnostics bundle reported by the agent.
+func (h *AgentHostHandler) Diagnostics(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		RespondErrorI18nAction(ctx, w, http.StatusBadRequest, "agent_host.diagnostics", "error.bad_request", h.i18n)
+		return
+	}
+
+	diag, err := h.service.GetDiagnostics(ctx, id)
+	if err != nil {
+		status := http.StatusInternalServerError
+		key := "error.internal_server_error"
+		if errors.Is(err, service.ErrNotFound) {
+			status = http.StatusNotFound
+			key = "error.not_found"
+		}
+		RespondErrorI18nAction(ctx, w, status, "agent_host.diagnostics", key, h.i18n)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"data": diag,
+	})
+}
+
+// PathUsage handles GET /agent-hosts/{id}/path-usage
+// Returns the latest disk usage of the directories watched by the agent.
+func (h *AgentHostHandler) PathUsage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		RespondErrorI18nAction(ctx, w, http.StatusBadRequest, "agent_host.path_usage", "error.bad_request", h.i18n)
+		return
+	}
+
+	usages, err := h.service.GetPathUsage(ctx, id)
+	if err != nil {
+		status := http.StatusInternalServerError
+		key := "error.internal_server_error"
+		if errors.Is(err, service.ErrNotFound) {
+			status = http.StatusNotFound
+			key = "error.not_found"
+		}
+		RespondErrorI18nAction(ctx, w, status, "agent_host.path_usage", key, h.i18n)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"data": usages,
+	})
+}
+
+// ClientConfigs 返回探针下各节点上报的原始客户端配置，支持 ?format= 过滤；默认打码，?reveal=true 返回原文。
+func (h *AgentHostHandler) ClientConfigs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		RespondErrorI18nAction(ctx, w, http.StatusBadRequest, "agent_host.client_configs", "error.bad_request", h.i18n)
+		return
+	}
+	reveal := false
+	if raw := r.URL.Query().Get("reveal"); raw != "" {
+		reveal, err = strconv.ParseBool(raw)
+		if err != nil {
+			RespondErrorI18nAction(ctx, w, http.StatusBadRequest, "agent_host.client_configs", "error.bad_request", h.i18n)
+			return
+		}
+	}
+
+	configs, err := h.service.ListClientConfigs(ctx, id, r.URL.Query().Get("format"), reveal)
+	if err != nil {
+		status := http.StatusInternalServerError
+		key := "error.internal_server_error"
+		if errors.Is(err, service.ErrNotFound) {
+			status = http.StatusNotFound
+			key = "error.not_found"
+		}
+		RespondErrorI18nAction(ctx, w, status, "agent_host.client_configs", key, h.i18n)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"data": configs,
+	})
+}
+
+// RealityKeyPairRequest represents the optional body for reality key generation.
+type RealityKeyPairRequest struct {
+	ShortIDCount int `json:"short_id_count"`
+}
+
+// GenerateRealityKeyPair handles POST /agent-hosts/reality/keypair
+// Returns a fresh x25519 key pair and random short IDs. The private key is
+// only returned to the caller and is never logged or stored.
+func (h *AgentHostHandler) GenerateRealityKeyPair(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	var req RealityKeyPairRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			RespondErrorI18nAction(ctx, w, http.StatusBadRequest, "agent_host.reality_keypair", "error.bad_request", h.i18n)
+			return
+		}
+	}
+	if req.ShortIDCount < 0 || req.ShortIDCount > reality.MaxShortIDCount {
+		RespondErrorI18nAction(ctx, w, http.StatusBadRequest, "agent_host.reality_keypair", "error.bad_request", h.i18n)
+		return
+	}
+
+	pair, err := reality.Generate(req.ShortIDCount)
+	if err != nil {
+		RespondErrorI18nAction(ctx, w, http.StatusInternalServerError, "agent_host.reality_keypair", "error.internal_server_error", h.i18n)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(map[string]any{
+		"data": pair,
+	})
+}
+
+// Resync handles POST /agent-hosts/{id}/resync
+// Asks an online agent to sync config and users immediately.
+func (h *AgentHostHandler) Resync(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		RespondErrorI18nAction(ctx, w, http.StatusBadRequest, "agent_host.resync", "error.bad_request", h.i18n)
+		return
+	}
+
+	if err := h.service.TriggerResync(ctx, id); err != nil {
+		status := http.StatusInternalServerError
+		key := "error.internal_server_error"
+		if errors.Is(err, service.ErrNotFound) {
+			status = http.StatusNotFound
+			key = "error.not_found"
+		} else if errors.Is(err, service.ErrBadRequest) {
+			status = http.StatusBadRequest
+			key = "error.bad_request"
+		}
+		RespondErrorI18nAction(ctx, w, status, "agent_host.resync", key, h.i18n)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"data": true,
+	})
+}
@@ -40,7 +40,6 @@ func (h *ClientHandler) handleSubscribe(w http.ResponseWriter, r *http.Request)
 	}
 	claims := requestctx.UserFromContext(r.Context())
 	tokenParam := strings.TrimSpace(r.URL.Query().Get("token"))
-	templateID, _ := strconv.ParseInt(r.URL.Query().Get("template_id"), 10, 64)
 	userRef := strings.TrimSpace(tokenParam)
 	if userRef == "" {
 		userRef = strings.TrimSpace(claims.ID)
@@ -49,19 +48,7 @@ func (h *ClientHandler) handleSubscribe(w http.ResponseWriter, r *http.Request)
 		RespondErrorI18nAction(r.Context(), w, http.StatusUnauthorized, "client.subscribe", "error.unauthorized", h.i18n)
 		return
 	}
-	params := service.SubscriptionParams{
-		Lang:         requestctx.GetLanguage(r.Context()),
-		Types:        r.URL.Query().Get("types"),
-		Filter:       r.URL.Query().Get("filter"),
-		Flag:         r.URL.Query().Get("flag"),
-		UserAgent:    r.UserAgent(),
-		Host:         r.Host,
-		Scheme:       requestScheme(r),
-		URL:          absoluteURL(r),
-		Tags:         r.URL.Query().Get("tags"),
-		ShowUserInfo: r.URL.Query().Get("show_info") == "1" || r.URL.Query().Get("show_info") == "true",
-		TemplateID:   templateID,
-	}
+	params := subscriptionParams(r, r.URL.Query().Get)
 	result, err := h.Subscription.Subscribe(r.Context(), userRef, params)
 	if err != nil {
 		status := http.StatusInternalServerError
@@ -73,6 +60,12 @@ func (h *ClientHandler) handleSubscribe(w http.ResponseWriter, r *http.Request)
 		case errors.Is(err, service.ErrUserNotEligible):
 			status = http.StatusForbidden
 			key = "error.forbidden"
+		case errors.Is(err, service.ErrBadRequest):
+			status = http.StatusBadRequest
+			key = "error.bad_request"
+		case errors.Is(err, service.ErrNoStackNodes):
+			status = http.StatusNotFound
+			key = "subscription.error.no_stack_nodes"
 		}
 		RespondErrorI18nAction(r.Context(), w, status, "client.subscribe", key, h.i18n)
 		return
@@ -99,7 +92,7 @@ func (h *ClientHandler) handleSubscribe(w http.ResponseWriter, r *http.Request)
 	if etag != "" {
 		w.Header().Set("ETag", etag)
 	}
-	if requestETag := r.Header.Get("If-None-Match"); etag != "" && strings.Contains(requestETag, result.ETag) {
+	if etag != "" && etagMatches(r.Header.Get("If-None-Match"), result.ETag) {
 		w.WriteHeader(http.StatusNotModified)
 		return
 	}
@@ -122,6 +115,29 @@ func clientActionPath(fullPath string) string {
 	return action
 }
 
+// subscriptionParams 从订阅参数与请求本身构造 SubscriptionParams。
+// 直接订阅与短链接直出共用此函数，get 分别读取 URL 查询参数和短链接合并后的参数。
+func subscriptionParams(r *http.Request, get func(string) string) service.SubscriptionParams {
+	templateID, _ := strconv.ParseInt(get("template_id"), 10, 64)
+	showInfo := get("show_info")
+	return service.SubscriptionParams{
+		Lang:         requestctx.GetLanguage(r.Context()),
+		Types:        get("types"),
+		Filter:       get("filter"),
+		Flag:         get("flag"),
+		UserAgent:    r.UserAgent(),
+		Host:         r.Host,
+		Scheme:       requestScheme(r),
+		URL:          absoluteURL(r),
+		Tags:         get("tags"),
+		ShowUserInfo: showInfo == "1" || showInfo == "true",
+		TemplateID:   templateID,
+		IP:           clientIP(r),
+		Stack:        get("stack"),
+		Sort:         get("sort"),
+	}
+}
+
 func requestScheme(r *http.Request) string {
 	if proto := strings.TrimSpace(r.Header.Get("X-Forwarded-Proto")); proto != "" {
 		return proto
@@ -11,3 +11,28 @@ func formatETag(raw string) string {
 	}
 	return "\"" + trimmed + "\""
 }
+
+// etagMatches 按 RFC 7232 弱比较判断 If-None-Match 是否命中当前 ETag（支持列表与 *）。
+func etagMatches(ifNoneMatch, etag string) bool {
+	current := normalizeETag(etag)
+	if current == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "" {
+			continue
+		}
+		if candidate == "*" || normalizeETag(candidate) == current {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeETag 去掉弱校验前缀与引号，便于比较。
+func normalizeETag(raw string) string {
+	trimmed := strings.TrimSpace(raw)
+	trimmed = strings.TrimPrefix(trimmed, "W/")
+	return strings.Trim(trimmed, "\"")
+}
@@ -6,11 +6,13 @@ import (
 	"encoding/json"
 	"errors"
 	"log/slog"
-	"net"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/creamcroissant/xboard/internal/api/clientip"
+	"github.com/creamcroissant/xboard/internal/api/requestctx"
 	"github.com/creamcroissant/xboard/internal/service"
 	"github.com/creamcroissant/xboard/internal/support/i18n"
 )
@@ -130,7 +132,20 @@ func (h *PassportHandler) handleLogin(w http.ResponseWriter, r *http.Request) {
 		UserAgent:  r.UserAgent(),
 	})
 	if err != nil {
+		var locked *service.LoginLockedError
 		switch {
+		case errors.As(err, &locked):
+			// 返回剩余冷却秒数，前端据此展示倒计时
+			retryAfter := int64(locked.RetryAfter() / time.Second)
+			message := "error.account_locked"
+			if h.i18n != nil {
+				message = h.i18n.Translate(requestctx.GetLanguage(r.Context()), message, retryAfter)
+			}
+			w.Header().Set("Retry-After", strconv.FormatInt(retryAfter, 10))
+			respondJSON(w, http.StatusTooManyRequests, map[string]any{
+				"error":       message,
+				"retry_after": retryAfter,
+			})
 		case errors.Is(err, service.ErrInvalidCredentials):
 			RespondErrorI18n(r.Context(), w, http.StatusUnauthorized, "error.invalid_credentials", h.i18n)
 		case errors.Is(err, service.ErrRateLimited):
@@ -349,6 +364,11 @@ func (h *PassportHandler) handleQuickLoginURL(w http.ResponseWriter, r *http.Req
 		}
 		return
 	}
+	// 快捷登录链接会换出普通令牌与刷新令牌，模拟登录令牌不得借此脱离 15 分钟时效与操作限制
+	if claims.Impersonating() {
+		RespondErrorI18n(r.Context(), w, http.StatusForbidden, "error.impersonation_forbidden", h.i18n)
+		return
+	}
 	url, err := h.mailLink.GenerateQuickLoginURL(r.Context(), claims.UserID, payload.Redirect)
 	if err != nil {
 		switch {
@@ -492,54 +512,9 @@ func firstNonEmpty(values ...string) string {
 	return ""
 }
 
+// clientIP 返回 RealIP 中间件按可信代理配置解析出的客户端 IP。
 func clientIP(r *http.Request) string {
-	remoteIP := parseIP(r.RemoteAddr)
-	if remoteIP == "" {
-		return ""
-	}
-	if !isTrustedProxy(remoteIP) {
-		return remoteIP
-	}
-	for _, header := range []string{"X-Forwarded-For", "X-Real-IP"} {
-		if value := strings.TrimSpace(r.Header.Get(header)); value != "" {
-			parts := strings.Split(value, ",")
-			if ip := strings.TrimSpace(parts[0]); ip != "" {
-				return ip
-			}
-		}
-	}
-	return remoteIP
-}
-
-func parseIP(addr string) string {
-	trimmed := strings.TrimSpace(addr)
-	if trimmed == "" {
-		return ""
-	}
-	if host, _, err := net.SplitHostPort(trimmed); err == nil {
-		return host
-	}
-	return trimmed
-}
-
-func isTrustedProxy(remoteIP string) bool {
-	if remoteIP == "127.0.0.1" || remoteIP == "::1" {
-		return true
-	}
-	if strings.HasPrefix(remoteIP, "10.") || strings.HasPrefix(remoteIP, "192.168.") {
-		return true
-	}
-	if strings.HasPrefix(remoteIP, "172.") {
-		parts := strings.Split(remoteIP, ".")
-		if len(parts) > 1 {
-			if second, err := strconv.Atoi(parts[1]); err == nil {
-				if second >= 16 && second <= 31 {
-					return true
-				}
-			}
-		}
-	}
-	return false
+	return clientip.FromRequest(r)
 }
 
 func formatAuthResponse(result *service.LoginResult) map[string]any {
@@ -562,6 +537,10 @@ func formatAuthResponse(result *service.LoginResult) map[string]any {
 		resp["refresh_token"] = result.RefreshToken
 		resp["refresh_expires_at"] = result.RefreshExpiresAt.Unix()
 	}
+	if result.Impersonation {
+		// 前端据此显示醒目的“正在模拟登录”横幅
+		resp["impersonation"] = true
+	}
 	return resp
 }
 
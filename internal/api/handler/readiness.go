@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/creamcroissant/xboard/internal/service"
+)
+
+// ReadinessHandler serves GET /_internal/ready for orchestrator readiness probes.
+type ReadinessHandler struct {
+	readiness service.ReadinessService
+}
+
+func NewReadinessHandler(readiness service.ReadinessService) *ReadinessHandler {
+	return &ReadinessHandler{readiness: readiness}
+}
+
+// Ready 返回各依赖的 ok/failed 状态；任一依赖不健康时返回 503，便于编排系统摘除该实例的流量。
+// 该接口无需鉴权，错误详情只写服务端日志，管理员可通过 Detail 查看。
+// /healthz 仍只反映进程存活，不做依赖检查。
+func (h *ReadinessHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	if h.readiness == nil {
+		respondJSON(w, http.StatusOK, map[string]string{"status": service.ReadinessStatusReady})
+		return
+	}
+	report := h.readiness.Check(r.Context())
+	h.respond(w, report, report.Redacted())
+}
+
+// Detail 为管理后台返回带错误详情的检查结果，挂在 AdminGuard 之后。
+func (h *ReadinessHandler) Detail(w http.ResponseWriter, r *http.Request) {
+	if h.readiness == nil {
+		respondJSON(w, http.StatusOK, map[string]string{"status": service.ReadinessStatusReady})
+		return
+	}
+	report := h.readiness.Check(r.Context())
+	h.respond(w, report, report)
+}
+
+func (h *ReadinessHandler) respond(w http.ResponseWriter, report, body *service.ReadinessReport) {
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	respondJSON(w, status, body)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/creamcroissant/xboard/internal/api/requestctx"
 	"github.com/creamcroissant/xboard/internal/service"
 	"github.com/creamcroissant/xboard/internal/support/i18n"
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
 )
 
 // Helper to respond with JSON
@@ -77,7 +78,7 @@ func RespondErrorI18nAction(ctx context.Context, w http.ResponseWriter, status i
 		key = action
 	}
 	if status >= 500 {
-		slog.Error("handler internal error", "action", action, "key", key, "status", status)
+		slog.ErrorContext(ctx, "handler internal error", "action", action, "key", key, "status", status)
 	}
 	lang := requestctx.GetLanguage(ctx)
 	var msg string
@@ -92,6 +93,7 @@ func RespondErrorI18nAction(ctx context.Context, w http.ResponseWriter, status i
 	if action != "" {
 		resp["action"] = action
 	}
+	withRequestID(ctx, resp)
 	respondJSON(w, status, resp)
 }
 
@@ -108,20 +110,28 @@ func respondAgentOperationBusy(ctx context.Context, w http.ResponseWriter, actio
 	if i18nMgr != nil {
 		message = i18nMgr.Translate(requestctx.GetLanguage(ctx), message)
 	}
-	respondJSON(w, http.StatusConflict, map[string]any{
+	respondJSON(w, http.StatusConflict, withRequestID(ctx, map[string]any{
 		"error":  message,
 		"action": action,
 		"details": map[string]any{
 			"blocker": blocker,
 		},
-	})
+	}))
 	return true
 }
 
+// withRequestID 在错误响应中附上请求 ID，用户反馈问题时可据此在日志中定位。
+func withRequestID(ctx context.Context, resp map[string]any) map[string]any {
+	if requestID := chiMiddleware.GetReqID(ctx); requestID != "" {
+		resp["request_id"] = requestID
+	}
+	return resp
+}
+
 // New helper for i18n error responses
 func RespondErrorI18n(ctx context.Context, w http.ResponseWriter, status int, key string, i18nMgr *i18n.Manager, args ...interface{}) {
 	if status >= 500 {
-		slog.Error("handler internal error", "key", key, "status", status)
+		slog.ErrorContext(ctx, "handler internal error", "key", key, "status", status)
 	}
 	lang := requestctx.GetLanguage(ctx)
 	var msg string
@@ -130,9 +140,9 @@ func RespondErrorI18n(ctx context.Context, w http.ResponseWriter, status int, ke
 	} else {
 		msg = key // Fallback if manager is missing (e.g. in tests)
 	}
-	respondJSON(w, status, map[string]any{
+	respondJSON(w, status, withRequestID(ctx, map[string]any{
 		"error": msg,
-	})
+	}))
 }
 
 // New helper for i18n success responses
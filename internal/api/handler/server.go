@@ -263,6 +263,11 @@ func decodeStatusPayload(r *http.Request) (service.ServerStatusReport, error) {
 		trafficDownload = *payload.TrafficDownload
 	}
 
+	var latencyMs int64
+	if payload.LatencyMs != nil {
+		latencyMs = *payload.LatencyMs
+	}
+
 	return service.ServerStatusReport{
 		CPU:             *payload.CPU,
 		Mem:             mem,
@@ -270,6 +275,7 @@ func decodeStatusPayload(r *http.Request) (service.ServerStatusReport, error) {
 		Disk:            disk,
 		TrafficUpload:   trafficUpload,
 		TrafficDownload: trafficDownload,
+		LatencyMs:       latencyMs,
 	}, nil
 }
 
@@ -280,6 +286,7 @@ type statusRequest struct {
 	Disk            *resourceBlock `json:"disk"`
 	TrafficUpload   *int64         `json:"traffic_upload"`
 	TrafficDownload *int64         `json:"traffic_download"`
+	LatencyMs       *int64         `json:"latency_ms"`
 }
 
 type resourceBlock struct {
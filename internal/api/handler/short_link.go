@@ -45,19 +45,33 @@ func (h *ShortLinkHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 // CreateShortLinkRequest represents a short link creation request.
 type CreateShortLinkRequest struct {
-	Code      string `json:"code,omitempty"`       // Optional custom code
-	ExpiresAt int64  `json:"expires_at,omitempty"` // Optional expiration timestamp
+	Code      string            `json:"code,omitempty"`       // Optional custom code
+	ExpiresAt int64             `json:"expires_at,omitempty"` // Optional expiration timestamp
+	Params    map[string]string `json:"params,omitempty"`     // Optional default subscription params (flag/types/...)
 }
 
 // ShortLinkResponse represents a short link in API responses.
 type ShortLinkResponse struct {
-	ID             int64  `json:"id"`
-	Code           string `json:"code"`
-	ShortURL       string `json:"short_url"`
-	AccessCount    int64  `json:"access_count"`
-	LastAccessedAt int64  `json:"last_accessed_at,omitempty"`
-	ExpiresAt      int64  `json:"expires_at,omitempty"`
-	CreatedAt      int64  `json:"created_at"`
+	ID             int64             `json:"id"`
+	Code           string            `json:"code"`
+	ShortURL       string            `json:"short_url"`
+	Params         map[string]string `json:"params,omitempty"`
+	AccessCount    int64             `json:"access_count"`
+	LastAccessedAt int64             `json:"last_accessed_at,omitempty"`
+	ExpiresAt      int64             `json:"expires_at,omitempty"`
+	CreatedAt      int64             `json:"created_at"`
+}
+
+// decodeShortLinkParams 解析短链接存储的默认参数，解析失败时返回 nil。
+func decodeShortLinkParams(raw string) map[string]string {
+	if strings.TrimSpace(raw) == "" {
+		return nil
+	}
+	var params map[string]string
+	if err := json.Unmarshal([]byte(raw), &params); err != nil {
+		return nil
+	}
+	return params
 }
 
 func (h *ShortLinkHandler) handleCreate(w http.ResponseWriter, r *http.Request) {
@@ -82,13 +96,16 @@ func (h *ShortLinkHandler) handleCreate(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
-	link, err := h.Service.Create(ctx, userID, req.Code, req.ExpiresAt)
+	link, err := h.Service.Create(ctx, userID, req.Code, req.ExpiresAt, req.Params)
 	if err != nil {
 		status := http.StatusInternalServerError
 		key := "error.internal_server_error"
 		if errors.Is(err, service.ErrNotFound) {
 			status = http.StatusNotFound
 			key = "error.not_found"
+		} else if errors.Is(err, service.ErrBadRequest) {
+			status = http.StatusBadRequest
+			key = "error.bad_request"
 		}
 		RespondErrorI18nAction(ctx, w, status, "shortlink.create", key, h.i18n)
 		return
@@ -113,6 +130,7 @@ func (h *ShortLinkHandler) handleCreate(w http.ResponseWriter, r *http.Request)
 			ID:             link.ID,
 			Code:           link.Code,
 			ShortURL:       shortURL,
+			Params:         decodeShortLinkParams(link.CustomParams),
 			AccessCount:    link.AccessCount,
 			LastAccessedAt: link.LastAccessedAt,
 			ExpiresAt:      link.ExpiresAt,
@@ -162,6 +180,7 @@ func (h *ShortLinkHandler) handleList(w http.ResponseWriter, r *http.Request) {
 			ID:             link.ID,
 			Code:           link.Code,
 			ShortURL:       shortURL,
+			Params:         decodeShortLinkParams(link.CustomParams),
 			AccessCount:    link.AccessCount,
 			LastAccessedAt: link.LastAccessedAt,
 			ExpiresAt:      link.ExpiresAt,
@@ -219,12 +238,17 @@ func (h *ShortLinkHandler) HandleRedirect(w http.ResponseWriter, r *http.Request
 	}
 
 	ctx := r.Context()
-	result, err := h.Service.Resolve(ctx, code)
+	// 请求中的 flag/types 等参数会覆盖短链接的默认参数，便于同一二维码适配不同客户端
+	result, err := h.Service.Resolve(ctx, code, r.URL.Query())
 	if err != nil {
 		if errors.Is(err, service.ErrNotFound) {
 			http.NotFound(w, r)
 			return
 		}
+		if errors.Is(err, service.ErrBadRequest) {
+			RespondErrorI18nAction(ctx, w, http.StatusBadRequest, "shortlink.redirect", "error.bad_request", h.i18n)
+			return
+		}
 		RespondErrorI18nAction(ctx, w, http.StatusInternalServerError, "shortlink.redirect", "error.internal_server_error", h.i18n)
 		return
 	}
@@ -237,13 +261,7 @@ func (h *ShortLinkHandler) HandleRedirect(w http.ResponseWriter, r *http.Request
 	// Instead of redirecting, we can serve the subscription directly
 	// This provides a better UX for proxy clients
 	if h.Subscription != nil && result.UserToken != "" {
-		params := service.SubscriptionParams{
-			Lang:      requestctx.GetLanguage(ctx),
-			UserAgent: r.UserAgent(),
-			Host:      r.Host,
-			Scheme:    requestScheme(r),
-			URL:       absoluteURL(r),
-		}
+		params := subscriptionParams(r, func(key string) string { return result.Params[key] })
 
 		subResult, err := h.Subscription.Subscribe(ctx, result.UserToken, params)
 		if err == nil && subResult != nil {
@@ -257,6 +275,10 @@ func (h *ShortLinkHandler) HandleRedirect(w http.ResponseWriter, r *http.Request
 			}
 			if subResult.ETag != "" {
 				w.Header().Set("ETag", formatETag(subResult.ETag))
+				if etagMatches(r.Header.Get("If-None-Match"), subResult.ETag) {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
 			}
 			w.WriteHeader(http.StatusOK)
 			_, _ = w.Write(subResult.Payload)
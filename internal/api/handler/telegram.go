@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/creamcroissant/xboard/internal/api/requestctx"
+	"github.com/creamcroissant/xboard/internal/notifier"
+	"github.com/creamcroissant/xboard/internal/service"
+	"github.com/creamcroissant/xboard/internal/support/i18n"
+)
+
+// telegramWebhookSecretHeader 是 Telegram 回调 webhook 时携带密钥的请求头。
+const telegramWebhookSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// TelegramHandler serves user Telegram binding endpoints and the bot webhook.
+type TelegramHandler struct {
+	Service service.TelegramService
+	i18n    *i18n.Manager
+}
+
+// NewTelegramHandler creates a Telegram handler.
+func NewTelegramHandler(telegram service.TelegramService, i18nMgr *i18n.Manager) *TelegramHandler {
+	return &TelegramHandler{Service: telegram, i18n: i18nMgr}
+}
+
+// ServeHTTP handles /api/v1/user/telegram/* actions.
+func (h *TelegramHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	action := userActionPath(r.URL.Path)
+	switch {
+	case action == "/telegram/getBotInfo" && r.Method == http.MethodGet:
+		h.handleBotInfo(w, r)
+	case action == "/telegram/bind" && r.Method == http.MethodPost:
+		h.handleBind(w, r)
+	case action == "/telegram/unbind" && r.Method == http.MethodPost:
+		h.handleUnbind(w, r)
+	default:
+		respondNotImplemented(w, "telegram", r)
+	}
+}
+
+func (h *TelegramHandler) handleBotInfo(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, ok := h.userID(w, r, "telegram.bot_info")
+	if !ok {
+		return
+	}
+	info, err := h.Service.BotInfo(ctx, userID)
+	if err != nil {
+		RespondErrorI18nAction(ctx, w, http.StatusInternalServerError, "telegram.bot_info", "error.internal_server_error", h.i18n)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"data": info})
+}
+
+func (h *TelegramHandler) handleBind(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, ok := h.userID(w, r, "telegram.bind")
+	if !ok {
+		return
+	}
+	if requestctx.UserFromContext(ctx).Impersonating() {
+		RespondErrorI18nAction(ctx, w, http.StatusForbidden, "telegram.bind", "error.impersonation_forbidden", h.i18n)
+		return
+	}
+	token, err := h.Service.CreateBindToken(ctx, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrFeatureDisabled):
+			RespondErrorI18nAction(ctx, w, http.StatusServiceUnavailable, "telegram.bind", "error.feature_disabled", h.i18n)
+		case errors.Is(err, service.ErrNotFound):
+			RespondErrorI18nAction(ctx, w, http.StatusNotFound, "telegram.bind", "error.user_not_found", h.i18n)
+		default:
+			RespondErrorI18nAction(ctx, w, http.StatusInternalServerError, "telegram.bind", "error.internal_server_error", h.i18n)
+		}
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"data": token})
+}
+
+func (h *TelegramHandler) handleUnbind(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, ok := h.userID(w, r, "telegram.unbind")
+	if !ok {
+		return
+	}
+	if requestctx.UserFromContext(ctx).Impersonating() {
+		RespondErrorI18nAction(ctx, w, http.StatusForbidden, "telegram.unbind", "error.impersonation_forbidden", h.i18n)
+		return
+	}
+	if err := h.Service.Unbind(ctx, userID); err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			RespondErrorI18nAction(ctx, w, http.StatusNotFound, "telegram.unbind", "error.user_not_found", h.i18n)
+			return
+		}
+		RespondErrorI18nAction(ctx, w, http.StatusInternalServerError, "telegram.unbind", "error.internal_server_error", h.i18n)
+		return
+	}
+	RespondSuccessI18n(ctx, w, "success.updated", h.i18n, nil)
+}
+
+// Webhook handles POST /api/v1/guest/telegram/webhook.
+// 处理失败时仍返回 200，避免 Telegram 反复重投同一条更新。
+func (h *TelegramHandler) Webhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if h.Service == nil {
+		RespondErrorI18nAction(ctx, w, http.StatusNotFound, "telegram.webhook", "error.not_found", h.i18n)
+		return
+	}
+	var update notifier.TelegramUpdate
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&update); err != nil {
+		RespondErrorI18nAction(ctx, w, http.StatusBadRequest, "telegram.webhook", "error.bad_request", h.i18n)
+		return
+	}
+	if err := h.Service.HandleWebhook(ctx, r.Header.Get(telegramWebhookSecretHeader), update); err != nil {
+		switch {
+		case errors.Is(err, service.ErrUnauthorized):
+			RespondErrorI18nAction(ctx, w, http.StatusUnauthorized, "telegram.webhook", "error.unauthorized", h.i18n)
+			return
+		case errors.Is(err, service.ErrFeatureDisabled):
+			RespondErrorI18nAction(ctx, w, http.StatusNotFound, "telegram.webhook", "error.not_found", h.i18n)
+			return
+		}
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"ok": true})
+}
+
+func (h *TelegramHandler) userID(w http.ResponseWriter, r *http.Request, action string) (int64, bool) {
+	ctx := r.Context()
+	if h.Service == nil {
+		RespondErrorI18nAction(ctx, w, http.StatusServiceUnavailable, action, "error.service_unavailable", h.i18n)
+		return 0, false
+	}
+	claims := requestctx.UserFromContext(ctx)
+	if claims.ID == "" {
+		RespondErrorI18nAction(ctx, w, http.StatusUnauthorized, action, "error.unauthorized", h.i18n)
+		return 0, false
+	}
+	userID, err := strconv.ParseInt(claims.ID, 10, 64)
+	if err != nil {
+		RespondErrorI18nAction(ctx, w, http.StatusBadRequest, action, "error.bad_request", h.i18n)
+		return 0, false
+	}
+	return userID, true
+}
@@ -98,6 +98,10 @@ func (h *UserHandler) handleProfileUpdate(w http.ResponseWriter, r *http.Request
 		RespondErrorI18n(ctx, w, http.StatusUnauthorized, "error.unauthorized", h.i18n)
 		return
 	}
+	if claims.Impersonating() {
+		RespondErrorI18n(ctx, w, http.StatusForbidden, "error.impersonation_forbidden", h.i18n)
+		return
+	}
 	// 更新用户资料字段。
 	var payload map[string]any
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
@@ -156,6 +160,10 @@ func (h *UserHandler) handleChangePassword(w http.ResponseWriter, r *http.Reques
 		RespondErrorI18n(ctx, w, http.StatusUnauthorized, "error.unauthorized", h.i18n)
 		return
 	}
+	if claims.Impersonating() {
+		RespondErrorI18n(ctx, w, http.StatusForbidden, "error.impersonation_forbidden", h.i18n)
+		return
+	}
 
 	// 修改密码，需校验旧密码。
 	var payload struct {
@@ -196,6 +204,10 @@ func (h *UserHandler) handleResetSecurity(w http.ResponseWriter, r *http.Request
 		RespondErrorI18n(ctx, w, http.StatusUnauthorized, "error.unauthorized", h.i18n)
 		return
 	}
+	if claims.Impersonating() {
+		RespondErrorI18n(ctx, w, http.StatusForbidden, "error.impersonation_forbidden", h.i18n)
+		return
+	}
 
 	// 重置订阅令牌并返回新 token。
 	newToken, err := h.Service.ResetSecurity(ctx, claims.ID)
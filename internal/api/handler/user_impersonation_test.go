@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/creamcroissant/xboard/internal/api/requestctx"
+	"github.com/creamcroissant/xboard/internal/service"
+)
+
+type telegramServiceStub struct {
+	service.TelegramService
+	calls int
+}
+
+func (s *telegramServiceStub) CreateBindToken(ctx context.Context, userID int64) (*service.TelegramBindToken, error) {
+	s.calls++
+	return &service.TelegramBindToken{}, nil
+}
+
+func (s *telegramServiceStub) Unbind(ctx context.Context, userID int64) error {
+	s.calls++
+	return nil
+}
+
+func TestImpersonationTokensRejectedOnAccountRoutes(t *testing.T) {
+	telegram := &telegramServiceStub{}
+	// UserService 为空：被拒绝的请求不应触达服务层
+	userHandler := NewUserHandler(nil, nil)
+	telegramHandler := NewTelegramHandler(telegram, nil)
+
+	cases := []struct {
+		name    string
+		handler http.Handler
+		path    string
+		body    string
+	}{
+		{name: "profile update", handler: userHandler, path: "/api/v1/user/profile", body: `{"remind_expire":true}`},
+		{name: "telegram bind", handler: telegramHandler, path: "/api/v1/user/telegram/bind"},
+		{name: "telegram unbind", handler: telegramHandler, path: "/api/v1/user/telegram/unbind"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body))
+			req = req.WithContext(requestctx.WithUserClaims(req.Context(), requestctx.UserClaims{ID: "7", ImpersonatorID: 1}))
+			rec := httptest.NewRecorder()
+			tc.handler.ServeHTTP(rec, req)
+			if rec.Code != http.StatusForbidden {
+				t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusForbidden, rec.Body.String())
+			}
+		})
+	}
+	if telegram.calls != 0 {
+		t.Fatalf("telegram service called %d times for impersonation tokens", telegram.calls)
+	}
+
+	// 普通令牌仍可绑定
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/user/telegram/bind", nil)
+	req = req.WithContext(requestctx.WithUserClaims(req.Context(), requestctx.UserClaims{ID: "7"}))
+	rec := httptest.NewRecorder()
+	telegramHandler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || telegram.calls != 1 {
+		t.Fatalf("status = %d, calls = %d; want 200 and 1 call", rec.Code, telegram.calls)
+	}
+}
+
+type impersonatingAuthStub struct {
+	service.AuthService
+}
+
+func (impersonatingAuthStub) Verify(ctx context.Context, rawToken string) (*service.Claims, error) {
+	return &service.Claims{UserID: 7, ImpersonatorID: 1}, nil
+}
+
+type mailLinkServiceStub struct {
+	service.MailLinkService
+	calls int
+}
+
+func (s *mailLinkServiceStub) GenerateQuickLoginURL(ctx context.Context, userID int64, redirect string) (string, error) {
+	s.calls++
+	return "https://panel.example.com/login", nil
+}
+
+func TestQuickLoginURLRejectsImpersonationTokens(t *testing.T) {
+	mailLink := &mailLinkServiceStub{}
+	h := NewPassportHandler(impersonatingAuthStub{}, nil, nil, nil, nil, mailLink, nil, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/passport/auth/getQuickLoginUrl", strings.NewReader(`{}`))
+	req.Header.Set("Authorization", "Bearer impersonation-token")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want %d; body=%s", rec.Code, http.StatusForbidden, rec.Body.String())
+	}
+	if mailLink.calls != 0 {
+		t.Fatal("quick login url must not be generated for impersonation tokens")
+	}
+}
@@ -32,6 +32,8 @@ func (h *UserServerHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		h.handleSaveSelection(w, r)
 	case action == "/selection" && r.Method == http.MethodGet:
 		h.handleGetSelection(w, r)
+	case action == "/tags" && r.Method == http.MethodGet:
+		h.handleTags(w, r)
 	default:
 		respondNotImplemented(w, "user.server", r)
 	}
@@ -133,6 +135,32 @@ func (h *UserServerHandler) handleFetch(w http.ResponseWriter, r *http.Request)
 	respondJSON(w, http.StatusOK, map[string]any{"data": result.Nodes})
 }
 
+// handleTags 返回用户可见节点上的标签及节点数，客户端可据此提供订阅 tags 过滤的选择器。
+func (h *UserServerHandler) handleTags(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if h.Servers == nil {
+		RespondErrorI18nAction(ctx, w, http.StatusServiceUnavailable, "user.server.tags", "error.service_unavailable", h.i18n)
+		return
+	}
+	claims := requestctx.UserFromContext(ctx)
+	if claims.ID == "" {
+		RespondErrorI18nAction(ctx, w, http.StatusUnauthorized, "user.server.tags", "error.unauthorized", h.i18n)
+		return
+	}
+	tags, err := h.Servers.ListTagsForUser(ctx, claims.ID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		key := "error.internal_server_error"
+		if errors.Is(err, service.ErrNotFound) {
+			status = http.StatusNotFound
+			key = "error.not_found"
+		}
+		RespondErrorI18nAction(ctx, w, status, "user.server.tags", key, h.i18n)
+		return
+	}
+	respondJSON(w, http.StatusOK, map[string]any{"data": tags})
+}
+
 func userServerActionPath(fullPath string) string {
 	idx := strings.Index(fullPath, "/user/server")
 	if idx == -1 {
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// agentHostScrapeTimeout bounds the repository query made on each scrape.
+const agentHostScrapeTimeout = 5 * time.Second
+
+// AgentHostLister lists agent hosts for the collector.
+type AgentHostLister interface {
+	List(ctx context.Context) ([]*repository.AgentHost, error)
+}
+
+// AgentHostCollector exports per-host online status and heartbeat age on each scrape.
+// Status uses the same thresholds as the TUI (repository.AgentHost.HeartbeatState).
+type AgentHostCollector struct {
+	hosts  AgentHostLister
+	logger *slog.Logger
+	now    func() time.Time
+
+	up            *prometheus.Desc
+	heartbeatAge  *prometheus.Desc
+	scrapeFailure *prometheus.Desc
+}
+
+// NewAgentHostCollector creates a collector under the given namespace (default "xboard").
+func NewAgentHostCollector(namespace string, hosts AgentHostLister, logger *slog.Logger) *AgentHostCollector {
+	if namespace == "" {
+		namespace = "xboard"
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &AgentHostCollector{
+		hosts:  hosts,
+		logger: logger,
+		now:    time.Now,
+		up: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "agent_host", "up"),
+			"Whether the agent host heartbeat is within the offline threshold (1) or not (0); status is online, warning or offline.",
+			[]string{"host", "id", "status"}, nil,
+		),
+		heartbeatAge: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "agent_host", "last_heartbeat_seconds"),
+			"Seconds since the agent host last sent a heartbeat. Absent if it never did.",
+			[]string{"host", "id"}, nil,
+		),
+		scrapeFailure: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "agent_host", "scrape_error"),
+			"Whether listing agent hosts failed during the last scrape.",
+			nil, nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *AgentHostCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.up
+	ch <- c.heartbeatAge
+	ch <- c.scrapeFailure
+}
+
+// Collect implements prometheus.Collector.
+func (c *AgentHostCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), agentHostScrapeTimeout)
+	defer cancel()
+
+	hosts, err := c.hosts.List(ctx)
+	if err != nil {
+		c.logger.Warn("agent host metrics scrape failed", "error", err)
+		ch <- prometheus.MustNewConstMetric(c.scrapeFailure, prometheus.GaugeValue, 1)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.scrapeFailure, prometheus.GaugeValue, 0)
+
+	now := c.now().Unix()
+	for _, host := range hosts {
+		if host == nil {
+			continue
+		}
+		name := host.Name
+		if name == "" {
+			name = host.Host
+		}
+		id := strconv.FormatInt(host.ID, 10)
+		state := host.HeartbeatState(now)
+		up := 0.0
+		if state != repository.AgentHostStateOffline {
+			up = 1
+		}
+		ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, up, name, id, state)
+		if host.LastHeartbeatAt > 0 {
+			ch <- prometheus.MustNewConstMetric(c.heartbeatAge, prometheus.GaugeValue, float64(now-host.LastHeartbeatAt), name, id)
+		}
+	}
+}
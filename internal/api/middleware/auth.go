@@ -37,11 +37,14 @@ func AdminGuard(auth service.AuthService, paths service.AdminPathService) func(h
 				return
 			}
 			token := extractBearer(r.Header.Get("Authorization"))
+			if token == "" {
+				token = WebSocketBearer(r)
+			}
 			if token == "" {
 				writeUnauthorized(w, "missing authorization header")
 				return
 			}
-			claims, err := auth.Verify(r.Context(), token)
+			claims, err := verifyBearer(r, auth, token)
 			if err != nil {
 				writeUnauthorized(w, err.Error())
 				return
@@ -65,17 +68,50 @@ func UserGuard(auth service.AuthService) func(http.Handler) http.Handler {
 				return
 			}
 			token := extractBearer(r.Header.Get("Authorization"))
-			claims, err := auth.Verify(r.Context(), token)
+			claims, err := verifyBearer(r, auth, token)
 			if err != nil {
 				writeUnauthorized(w, err.Error())
 				return
 			}
-			ctx := requestctx.WithUserClaims(r.Context(), requestctx.UserClaims{ID: strconv.FormatInt(claims.UserID, 10), Email: claims.Email})
+			ctx := requestctx.WithUserClaims(r.Context(), requestctx.UserClaims{ID: strconv.FormatInt(claims.UserID, 10), Email: claims.Email, ImpersonatorID: claims.ImpersonatorID})
+			if claims.Impersonating() {
+				// 让前端在任意用户接口响应上都能识别并展示模拟登录横幅
+				w.Header().Set("X-Impersonation", strconv.FormatInt(claims.ImpersonatorID, 10))
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// IdentifyBearer 在全局限流之前校验 Authorization 中的用户令牌，成功时把身份写入上下文，
+// 让 IP 限流器能识别已认证、将由按用户计数的限流器接管的请求。校验失败不拦截，交给后续 guard 处理。
+func IdentifyBearer(auth service.AuthService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := extractBearer(r.Header.Get("Authorization"))
+			if auth == nil || token == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			claims, err := auth.Verify(r.Context(), token)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ctx := requestctx.WithIdentity(r.Context(), requestctx.IdentityClaims{UserID: claims.UserID, Email: claims.Email, IsAdmin: claims.IsAdmin, ImpersonatorID: claims.ImpersonatorID})
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
+// verifyBearer 优先复用 IdentifyBearer 已校验的身份，避免同一请求重复查询用户。
+func verifyBearer(r *http.Request, auth service.AuthService, token string) (*service.Claims, error) {
+	if identity, ok := requestctx.IdentityFromContext(r.Context()); ok {
+		return &service.Claims{UserID: identity.UserID, Email: identity.Email, IsAdmin: identity.IsAdmin, ImpersonatorID: identity.ImpersonatorID}, nil
+	}
+	return auth.Verify(r.Context(), token)
+}
+
 // ServerGuard ensures requests originate from trusted nodes.
 func ServerGuard(auth service.ServerAuthService, defaultType string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -170,6 +206,26 @@ func extractBearer(header string) string {
 	return trimmed
 }
 
+// WebSocketBearer 从 WebSocket 升级请求的子协议中读取令牌。浏览器无法为 WebSocket 设置 Authorization 头，
+// 客户端以 new WebSocket(url, ["bearer", token]) 传递，服务端握手时回选 "bearer" 子协议。
+func WebSocketBearer(r *http.Request) string {
+	if !strings.EqualFold(strings.TrimSpace(r.Header.Get("Upgrade")), "websocket") {
+		return ""
+	}
+	var protocols []string
+	for _, header := range r.Header.Values("Sec-WebSocket-Protocol") {
+		for _, protocol := range strings.Split(header, ",") {
+			protocols = append(protocols, strings.TrimSpace(protocol))
+		}
+	}
+	for i := 0; i+1 < len(protocols); i++ {
+		if strings.EqualFold(protocols[i], "bearer") {
+			return protocols[i+1]
+		}
+	}
+	return ""
+}
+
 func writeUnauthorized(w http.ResponseWriter, message string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusUnauthorized)
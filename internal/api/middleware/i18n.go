@@ -31,11 +31,15 @@ func I18n(manager *i18n.Manager) func(next http.Handler) http.Handler {
 			}
 
 			if lang == "" {
-				// Finally check Accept-Language
-				accept := r.Header.Get("Accept-Language")
-				tags, _, err := language.ParseAcceptLanguage(accept)
-				if err == nil && len(tags) > 0 {
-					lang = tags[0].String()
+				// Finally check Accept-Language: pick the best loaded language by q-weight
+				// instead of blindly taking the first tag (e.g. "fr;q=0.9, zh-CN;q=0.8").
+				// The result is stored in the request context below, so it is parsed once per request.
+				if accept := r.Header.Get("Accept-Language"); accept != "" {
+					if manager != nil {
+						lang = manager.MatchAcceptLanguage(accept)
+					} else if tags, _, err := language.ParseAcceptLanguage(accept); err == nil && len(tags) > 0 {
+						lang = tags[0].String()
+					}
 				}
 			}
 
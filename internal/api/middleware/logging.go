@@ -58,12 +58,9 @@ func StructuredLogger(config LoggingConfig) func(http.Handler) http.Handler {
 				requestID = "unknown"
 			}
 
-			// 包装 ResponseWriter 以捕获状态码
+			// 包装 ResponseWriter 以捕获状态码（X-Request-ID 响应头由 RequestIDLogger 统一设置）
 			ww := chiMiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
 
-			// 在响应头中设置请求 ID
-			ww.Header().Set("X-Request-ID", requestID)
-
 			// 处理请求
 			next.ServeHTTP(ww, r)
 
@@ -82,6 +79,7 @@ func StructuredLogger(config LoggingConfig) func(http.Handler) http.Handler {
 				slog.Int("status", status),
 				slog.Duration("duration", duration),
 				slog.String("remote_addr", r.RemoteAddr),
+				slog.String("client_ip", getClientIP(r)),
 				slog.Int("bytes", ww.BytesWritten()),
 			}
 
@@ -118,7 +116,8 @@ func StructuredLogger(config LoggingConfig) func(http.Handler) http.Handler {
 }
 
 // RequestIDLogger 简单的请求 ID 日志中间件（不记录完整请求）
-// 仅将请求 ID 添加到响应头
+// 将请求 ID 添加到响应头；需挂在 chiMiddleware.RequestID 之后，
+// 这样健康检查等跳过访问日志的路径也会返回请求 ID。
 func RequestIDLogger() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -2,6 +2,9 @@
 package middleware
 
 import (
+	"bufio"
+	"errors"
+	"net"
 	"net/http"
 	"strconv"
 	"time"
@@ -111,6 +114,21 @@ func (w *metricsResponseWriter) Write(b []byte) (int, error) {
 	return n, err
 }
 
+// Hijack passes through to the underlying writer so WebSocket upgrades work behind the middleware.
+func (w *metricsResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("metrics: underlying ResponseWriter does not support hijacking")
+	}
+	w.status = http.StatusSwitchingProtocols
+	return hijacker.Hijack()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (w *metricsResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 // Middleware returns an HTTP middleware that collects metrics.
 func (m *Metrics) Middleware(cfg MetricsConfig) func(http.Handler) http.Handler {
 	skipSet := make(map[string]bool)
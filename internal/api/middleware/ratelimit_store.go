@@ -0,0 +1,92 @@
+// 文件路径: internal/api/middleware/ratelimit_store.go
+// 模块说明: 限流计数后端，单实例使用内存计数，多实例部署使用 Redis 共享计数
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RateLimitStore 限流计数后端
+type RateLimitStore interface {
+	// Take 在 window 内为 key 计一次请求，返回是否放行、剩余额度与窗口重置时间
+	Take(ctx context.Context, key string, limit int, window time.Duration) (bool, int, time.Time, error)
+}
+
+// Take 让内存限流器实现 RateLimitStore，limit/window 以调用参数为准
+func (rl *RateLimiter) Take(_ context.Context, key string, limit int, window time.Duration) (bool, int, time.Time, error) {
+	allowed, remaining, resetAt := rl.allow(key, limit, window)
+	return allowed, remaining, resetAt, nil
+}
+
+// redisSlidingWindowScript 基于有序集合的滑动窗口计数，时间取 Redis 服务端时钟以避免多副本时钟偏差。
+// 返回 {allowed, remaining, reset_at_ms}。
+// 脚本先调用 TIME 再写入，依赖 Redis 5.0 起默认的脚本效果复制（effects replication），
+// 更早的版本会拒绝执行，因此 Redis 后端要求 Redis >= 5.0。
+var redisSlidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local window = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+local member = ARGV[3]
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+redis.call('ZREMRANGEBYSCORE', key, 0, now - window)
+local count = redis.call('ZCARD', key)
+local allowed = 0
+if count < limit then
+  redis.call('ZADD', key, now, member)
+  count = count + 1
+  allowed = 1
+end
+redis.call('PEXPIRE', key, window)
+local reset = now + window
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+  reset = tonumber(oldest[2]) + window
+end
+return {allowed, limit - count, reset}
+`)
+
+// RedisRateLimitStore 使用 Redis 滑动窗口在多个面板副本之间共享限流计数
+type RedisRateLimitStore struct {
+	client redis.Scripter
+	prefix string
+	seq    atomic.Uint64
+}
+
+// NewRedisRateLimitStore 创建 Redis 限流后端，prefix 为空时使用 "xboard:ratelimit:"
+func NewRedisRateLimitStore(client redis.Scripter, prefix string) *RedisRateLimitStore {
+	if prefix == "" {
+		prefix = "xboard:ratelimit:"
+	}
+	return &RedisRateLimitStore{client: client, prefix: prefix}
+}
+
+// Take 执行滑动窗口脚本
+func (s *RedisRateLimitStore) Take(ctx context.Context, key string, limit int, window time.Duration) (bool, int, time.Time, error) {
+	if s == nil || s.client == nil {
+		return false, 0, time.Time{}, errors.New("redis rate limit store unavailable")
+	}
+	// 成员需唯一，否则同一毫秒内的请求会被 ZADD 合并
+	member := strconv.FormatInt(time.Now().UnixNano(), 36) + "-" + strconv.FormatUint(s.seq.Add(1), 36)
+	// Run 优先使用 EVALSHA，服务端未缓存脚本时自动回退到 EVAL
+	values, err := redisSlidingWindowScript.Run(ctx, s.client, []string{s.prefix + key},
+		window.Milliseconds(), limit, member).Int64Slice()
+	if err != nil {
+		return false, 0, time.Time{}, err
+	}
+	if len(values) != 3 {
+		return false, 0, time.Time{}, fmt.Errorf("unexpected rate limit script reply: %v", values)
+	}
+	allowed, remaining, resetAtMs := values[0], values[1], values[2]
+	if remaining < 0 {
+		remaining = 0
+	}
+	return allowed == 1, int(remaining), time.UnixMilli(resetAtMs), nil
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRedisRateLimitStoreSlidingWindow(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	store := NewRedisRateLimitStore(client, "")
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		allowed, remaining, resetAt, err := store.Take(ctx, "ip:1.2.3.4", 2, time.Minute)
+		if err != nil {
+			t.Fatalf("take %d: %v", i, err)
+		}
+		if !allowed || remaining != 1-i {
+			t.Fatalf("take %d: allowed=%v remaining=%d", i, allowed, remaining)
+		}
+		if resetAt.IsZero() {
+			t.Fatalf("take %d: reset time missing", i)
+		}
+	}
+	allowed, remaining, _, err := store.Take(ctx, "ip:1.2.3.4", 2, time.Minute)
+	if err != nil || allowed || remaining != 0 {
+		t.Fatalf("over limit: allowed=%v remaining=%d err=%v", allowed, remaining, err)
+	}
+
+	// 计数落在带前缀的 key 上，且不同 key 互不影响
+	if !server.Exists("xboard:ratelimit:ip:1.2.3.4") {
+		t.Fatal("expected counter under the default key prefix")
+	}
+	if allowed, _, _, err := store.Take(ctx, "ip:5.6.7.8", 2, time.Minute); err != nil || !allowed {
+		t.Fatalf("other key: allowed=%v err=%v", allowed, err)
+	}
+}
+
+func TestRedisRateLimitStoreReportsBackendErrors(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+	store := NewRedisRateLimitStore(client, "test:")
+	server.Close()
+
+	// 后端不可用时返回错误，由 RateLimit 中间件决定放行
+	if _, _, _, err := store.Take(context.Background(), "k", 1, time.Minute); err == nil {
+		t.Fatal("expected error when redis is unavailable")
+	}
+}
@@ -3,12 +3,22 @@
 package middleware
 
 import (
-	"net"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/creamcroissant/xboard/internal/api/clientip"
+	"github.com/creamcroissant/xboard/internal/api/requestctx"
+	"github.com/creamcroissant/xboard/internal/support/i18n"
+	"github.com/go-chi/chi/v5"
 )
 
 // RateLimiter 简单的内存限流器
@@ -38,6 +48,10 @@ func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
 
 // Allow 检查是否允许请求
 func (rl *RateLimiter) Allow(key string) (bool, int, time.Time) {
+	return rl.allow(key, rl.limit, rl.window)
+}
+
+func (rl *RateLimiter) allow(key string, limit int, window time.Duration) (bool, int, time.Time) {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
@@ -48,17 +62,17 @@ func (rl *RateLimiter) Allow(key string) (bool, int, time.Time) {
 		// 新窗口
 		rl.requests[key] = &rateLimitEntry{
 			count:   1,
-			resetAt: now.Add(rl.window),
+			resetAt: now.Add(window),
 		}
-		return true, rl.limit - 1, now.Add(rl.window)
+		return true, limit - 1, now.Add(window)
 	}
 
-	if entry.count >= rl.limit {
+	if entry.count >= limit {
 		return false, 0, entry.resetAt
 	}
 
 	entry.count++
-	return true, rl.limit - entry.count, entry.resetAt
+	return true, limit - entry.count, entry.resetAt
 }
 
 // cleanup 定期清理过期条目
@@ -78,12 +92,29 @@ func (rl *RateLimiter) cleanup() {
 	}
 }
 
+// RateLimitKeyStrategy 限流 key 的计算策略
+type RateLimitKeyStrategy string
+
+const (
+	// RateLimitKeyIP 按客户端 IP 限流（默认）
+	RateLimitKeyIP RateLimitKeyStrategy = "ip"
+	// RateLimitKeyUser 按认证上下文中的用户/管理员 ID 限流，匿名请求回退到 IP
+	RateLimitKeyUser RateLimitKeyStrategy = "user"
+	// RateLimitKeyToken 按订阅 token（或短链接 code）限流，缺失时回退到 IP
+	RateLimitKeyToken RateLimitKeyStrategy = "token"
+)
+
 // RateLimitConfig Rate Limit 配置
 type RateLimitConfig struct {
 	Limit      int           // 每个窗口的请求数
 	Window     time.Duration // 时间窗口
-	KeyFunc    func(*http.Request) string // 获取限流 key 的函数
+	KeyFunc    func(*http.Request) string // 获取限流 key 的函数，优先于 KeyStrategy
+	KeyStrategy RateLimitKeyStrategy      // KeyFunc 为空时使用的 key 策略
 	SkipPaths  []string      // 跳过限流的路径
+	Skip       func(*http.Request) bool   // 额外的跳过判断（如已由分路限流器接管的请求）
+	Name       string                     // 限流器名称，作为共享后端中的 key 前缀
+	Store      RateLimitStore             // 计数后端，为空时使用进程内内存计数
+	Logger     *slog.Logger               // 后端故障时记录告警
 }
 
 // DefaultRateLimitConfig 默认配置
@@ -108,12 +139,22 @@ func RateLimit(config RateLimitConfig) func(http.Handler) http.Handler {
 		config.Window = time.Minute
 	}
 	if config.KeyFunc == nil {
-		config.KeyFunc = func(r *http.Request) string {
-			return getClientIP(r)
-		}
+		config.KeyFunc = RateLimitKeyFunc(config.KeyStrategy)
 	}
 
-	limiter := NewRateLimiter(config.Limit, config.Window)
+	store := config.Store
+	if store == nil {
+		store = NewRateLimiter(config.Limit, config.Window)
+	}
+	logger := config.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	keyPrefix := ""
+	if config.Name != "" {
+		keyPrefix = config.Name + ":"
+	}
+	var lastWarnAt atomic.Int64
 	skipPaths := make(map[string]bool)
 	for _, p := range config.SkipPaths {
 		skipPaths[p] = true
@@ -122,13 +163,22 @@ func RateLimit(config RateLimitConfig) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// 跳过特定路径
-			if skipPaths[r.URL.Path] {
+			if skipPaths[r.URL.Path] || (config.Skip != nil && config.Skip(r)) {
 				next.ServeHTTP(w, r)
 				return
 			}
 
-			key := config.KeyFunc(r)
-			allowed, remaining, resetAt := limiter.Allow(key)
+			key := keyPrefix + config.KeyFunc(r)
+			allowed, remaining, resetAt, err := store.Take(r.Context(), key, config.Limit, config.Window)
+			if err != nil {
+				// 后端故障时放行（fail open），告警按分钟节流，避免刷屏
+				now := time.Now().Unix()
+				if last := lastWarnAt.Load(); now-last >= 60 && lastWarnAt.CompareAndSwap(last, now) {
+					logger.Warn("rate limit store unavailable, allowing request", "limiter", config.Name, "error", err)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
 
 			// 设置 Rate Limit 响应头
 			w.Header().Set("X-RateLimit-Limit", strconv.Itoa(config.Limit))
@@ -136,7 +186,7 @@ func RateLimit(config RateLimitConfig) func(http.Handler) http.Handler {
 			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(resetAt.Unix(), 10))
 
 			if !allowed {
-				w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(resetAt).Seconds())))
+				w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds(resetAt)))
 				http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
 				return
 			}
@@ -146,24 +196,85 @@ func RateLimit(config RateLimitConfig) func(http.Handler) http.Handler {
 	}
 }
 
+// RateLimitKeyFunc 返回指定策略对应的限流 key 函数，未知策略按 IP 处理
+func RateLimitKeyFunc(strategy RateLimitKeyStrategy) func(*http.Request) string {
+	switch strategy {
+	case RateLimitKeyUser:
+		return rateLimitKeyByUser
+	case RateLimitKeyToken:
+		return rateLimitKeyByToken
+	default:
+		return func(r *http.Request) string {
+			return getClientIP(r)
+		}
+	}
+}
+
+// rateLimitKeyByUser 需要挂在认证中间件之后，才能从上下文读取用户身份
+func rateLimitKeyByUser(r *http.Request) string {
+	if claims := requestctx.UserFromContext(r.Context()); claims.ID != "" {
+		return "user:" + claims.ID
+	}
+	if claims := requestctx.AdminFromContext(r.Context()); claims.ID != "" {
+		return "admin:" + claims.ID
+	}
+	return "ip:" + getClientIP(r)
+}
+
+// rateLimitKeyByToken 订阅请求按 token 计数，短链接按 code 计数，共享 NAT 的用户互不影响
+func rateLimitKeyByToken(r *http.Request) string {
+	if token := strings.TrimSpace(r.URL.Query().Get("token")); token != "" {
+		return "token:" + token
+	}
+	if code := strings.TrimSpace(chi.URLParam(r, "code")); code != "" {
+		return "link:" + code
+	}
+	return "ip:" + getClientIP(r)
+}
+
+// retryAfterSeconds 向上取整，保证 Retry-After 至少为 1 秒
+func retryAfterSeconds(resetAt time.Time) int {
+	seconds := int(math.Ceil(time.Until(resetAt).Seconds()))
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
+
+// DefaultBodyLimitBytes 为未命中任何分组规则时的全局请求体上限（10MB）。
+const DefaultBodyLimitBytes int64 = 10 * 1024 * 1024
+
+// BodyLimitRule 为一组路由单独设置请求体上限。
+// Pattern 按 "/" 分段匹配：单个 "*" 匹配任意一段，结尾的 "/*" 匹配其后任意层级（含零层），
+// 例如 "/api/v2/*/agent-hosts/*/protocols/*"。
+type BodyLimitRule struct {
+	Pattern  string
+	MaxBytes int64
+}
+
 // BodyLimitConfig 请求体大小限制配置
 type BodyLimitConfig struct {
-	MaxBytes  int64    // 最大字节数
-	SkipPaths []string // 跳过的路径
+	MaxBytes  int64           // 最大字节数（全局默认）
+	Rules     []BodyLimitRule // 分组覆盖，按顺序取第一条命中的规则
+	SkipPaths []string        // 跳过的路径
+	I18n      *i18n.Manager   // 用于翻译 413 错误信息，为空时返回英文
 }
 
 // DefaultBodyLimitConfig 默认配置（10MB）
 func DefaultBodyLimitConfig() BodyLimitConfig {
 	return BodyLimitConfig{
-		MaxBytes:  10 * 1024 * 1024, // 10MB
+		MaxBytes:  DefaultBodyLimitBytes,
 		SkipPaths: []string{},
 	}
 }
 
-// BodyLimit 请求体大小限制中间件
+// BodyLimit 请求体大小限制中间件。
+// 声明了 Content-Length 且超限的请求直接返回 413；未声明长度（分块传输）的请求由
+// http.MaxBytesReader 在读取超限时截断，处理器随后写出的 4xx 会被改写为同样的 413。
+// 需挂在 I18n 之后才能按请求语言返回错误信息。
 func BodyLimit(config BodyLimitConfig) func(http.Handler) http.Handler {
-	if config.MaxBytes == 0 {
-		config.MaxBytes = 10 * 1024 * 1024 // 10MB
+	if config.MaxBytes <= 0 {
+		config.MaxBytes = DefaultBodyLimitBytes
 	}
 
 	skipPaths := make(map[string]bool)
@@ -179,14 +290,120 @@ func BodyLimit(config BodyLimitConfig) func(http.Handler) http.Handler {
 				return
 			}
 
+			limit := config.limitFor(r.URL.Path)
+			if r.ContentLength > limit {
+				writeRequestTooLarge(w, r, config.I18n)
+				return
+			}
+
 			// 限制请求体大小
-			r.Body = http.MaxBytesReader(w, r.Body, config.MaxBytes)
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			if r.ContentLength >= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
 
-			next.ServeHTTP(w, r)
+			// 分块传输无法预检，读取超限后由处理器各自返回的 400 统一改写为 413
+			body := &limitedBody{ReadCloser: r.Body}
+			r.Body = body
+			next.ServeHTTP(&tooLargeWriter{ResponseWriter: w, r: r, body: body, i18n: config.I18n}, r)
 		})
 	}
 }
 
+// limitedBody 记录请求体读取是否触发了 MaxBytesReader 的上限。
+type limitedBody struct {
+	io.ReadCloser
+	exceeded bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	var maxErr *http.MaxBytesError
+	if err != nil && errors.As(err, &maxErr) {
+		b.exceeded = true
+	}
+	return n, err
+}
+
+// tooLargeWriter 在请求体超限后把处理器写出的 4xx 响应替换为 413。
+type tooLargeWriter struct {
+	http.ResponseWriter
+	r           *http.Request
+	body        *limitedBody
+	i18n        *i18n.Manager
+	wroteHeader bool
+	discard     bool
+}
+
+func (w *tooLargeWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if w.body.exceeded && code >= http.StatusBadRequest && code < http.StatusInternalServerError {
+		w.discard = true
+		writeRequestTooLarge(w.ResponseWriter, w.r, w.i18n)
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *tooLargeWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.discard {
+		return len(p), nil
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Unwrap 供 http.ResponseController 访问底层 Flush 等能力。
+func (w *tooLargeWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// limitFor 返回路径对应的上限，未命中规则时使用全局默认值。
+func (c BodyLimitConfig) limitFor(path string) int64 {
+	for _, rule := range c.Rules {
+		if rule.MaxBytes > 0 && matchRoutePattern(rule.Pattern, path) {
+			return rule.MaxBytes
+		}
+	}
+	return c.MaxBytes
+}
+
+func matchRoutePattern(pattern, path string) bool {
+	patternParts := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathParts := strings.Split(strings.Trim(path, "/"), "/")
+	for i, part := range patternParts {
+		if part == "*" && i == len(patternParts)-1 {
+			return len(pathParts) >= i
+		}
+		if i >= len(pathParts) {
+			return false
+		}
+		if part != "*" && part != pathParts[i] {
+			return false
+		}
+	}
+	return len(pathParts) == len(patternParts)
+}
+
+func writeRequestTooLarge(w http.ResponseWriter, r *http.Request, manager *i18n.Manager) {
+	msg := "Request body too large"
+	if manager != nil {
+		msg = manager.Translate(requestctx.GetLanguage(r.Context()), "error.request_too_large")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Connection", "close")
+	w.WriteHeader(http.StatusRequestEntityTooLarge)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"error": msg,
+	})
+}
+
 // CORSConfig CORS 配置
 type CORSConfig struct {
 	AllowedOrigins   []string // 允许的来源，"*" 表示所有
@@ -271,62 +488,21 @@ func CORS(config CORSConfig) func(http.Handler) http.Handler {
 	}
 }
 
-// getClientIP 获取客户端真实 IP
-func getClientIP(r *http.Request) string {
-	// Prefer RemoteAddr unless the connection is from a trusted proxy.
-	remoteIP := parseIP(r.RemoteAddr)
-	if remoteIP == "" {
-		return ""
-	}
-	if !isTrustedProxy(remoteIP) {
-		return remoteIP
-	}
-
-	// 检查 X-Forwarded-For
-	if xff := strings.TrimSpace(r.Header.Get("X-Forwarded-For")); xff != "" {
-		parts := strings.Split(xff, ",")
-		if len(parts) > 0 {
-			if ip := strings.TrimSpace(parts[0]); ip != "" {
-				return ip
-			}
-		}
-	}
-
-	// 检查 X-Real-IP
-	if xri := strings.TrimSpace(r.Header.Get("X-Real-IP")); xri != "" {
-		return xri
-	}
-
-	return remoteIP
-}
-
-func parseIP(addr string) string {
-	trimmed := strings.TrimSpace(addr)
-	if trimmed == "" {
-		return ""
+// RealIP 按代理信任配置解析客户端 IP 并写入 context，替代直接信任 X-Forwarded-For 首项的做法。
+// RemoteAddr 保持为 TCP 对端地址，便于日志区分代理与客户端。
+func RealIP(resolver *clientip.Resolver) func(http.Handler) http.Handler {
+	if resolver == nil {
+		resolver = clientip.Default()
 	}
-	if host, _, err := net.SplitHostPort(trimmed); err == nil {
-		return host
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := resolver.Resolve(r)
+			next.ServeHTTP(w, r.WithContext(clientip.WithClientIP(r.Context(), ip)))
+		})
 	}
-	return trimmed
 }
 
-func isTrustedProxy(remoteIP string) bool {
-	if remoteIP == "127.0.0.1" || remoteIP == "::1" {
-		return true
-	}
-	if strings.HasPrefix(remoteIP, "10.") || strings.HasPrefix(remoteIP, "192.168.") {
-		return true
-	}
-	if strings.HasPrefix(remoteIP, "172.") {
-		parts := strings.Split(remoteIP, ".")
-		if len(parts) > 1 {
-			if second, err := strconv.Atoi(parts[1]); err == nil {
-				if second >= 16 && second <= 31 {
-					return true
-				}
-			}
-		}
-	}
-	return false
+// getClientIP 获取客户端真实 IP
+func getClientIP(r *http.Request) string {
+	return clientip.FromRequest(r)
 }
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBodyLimitRules(t *testing.T) {
+	cfg := BodyLimitConfig{
+		MaxBytes: 100,
+		Rules: []BodyLimitRule{
+			{Pattern: "/api/v2/passport/*", MaxBytes: 10},
+			{Pattern: "/api/v2/*/agent-hosts/*/protocols/*", MaxBytes: 1000},
+		},
+	}
+	cases := map[string]int64{
+		"/api/v2/passport/auth/login":               10,
+		"/api/v2/passport":                          10,
+		"/api/v2/secret/agent-hosts/3/protocols":    1000,
+		"/api/v2/secret/agent-hosts/3/protocols/x":  1000,
+		"/api/v2/secret/agent-hosts/3/intervals":    100,
+		"/api/v2/secret/agent-hosts/templates/list": 100,
+	}
+	for path, want := range cases {
+		if got := cfg.limitFor(path); got != want {
+			t.Errorf("limitFor(%q) = %d, want %d", path, got, want)
+		}
+	}
+
+	handler := BodyLimit(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v2/passport/auth/login", strings.NewReader(strings.Repeat("x", 11))))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("oversized passport request status = %d, want 413", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v2/user/info", strings.NewReader(strings.Repeat("x", 11))))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("default-limit request status = %d, want 204", rec.Code)
+	}
+
+	// 分块传输没有 Content-Length，处理器解码失败返回的 400 应被改写为 413
+	decoding := BodyLimit(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	chunked := func(body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/passport/auth/login", strings.NewReader(body))
+		req.ContentLength = -1
+		req.TransferEncoding = []string{"chunked"}
+		return req
+	}
+	rec = httptest.NewRecorder()
+	decoding.ServeHTTP(rec, chunked(`{"email":"someone@example.com"}`))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("oversized chunked request status = %d, want 413", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), `"error"`) || strings.Contains(rec.Body.String(), "bad request") {
+		t.Fatalf("oversized chunked request body = %q", rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	decoding.ServeHTTP(rec, chunked(`{`))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("malformed small chunked request status = %d, want 400", rec.Code)
+	}
+}
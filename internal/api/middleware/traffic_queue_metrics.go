@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"github.com/creamcroissant/xboard/internal/async"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// TrafficQueueStatsSource provides traffic queue counters for the collector.
+type TrafficQueueStatsSource interface {
+	Stats() async.TrafficQueueStats
+}
+
+// TrafficQueueCollector exports traffic queue sample counters on each scrape.
+type TrafficQueueCollector struct {
+	queue TrafficQueueStatsSource
+
+	enqueued  *prometheus.Desc
+	processed *prometheus.Desc
+	dropped   *prometheus.Desc
+	rejected  *prometheus.Desc
+	retried   *prometheus.Desc
+	pending   *prometheus.Desc
+	capacity  *prometheus.Desc
+}
+
+// NewTrafficQueueCollector creates a collector under the given namespace (default "xboard").
+func NewTrafficQueueCollector(namespace string, queue TrafficQueueStatsSource) *TrafficQueueCollector {
+	if namespace == "" {
+		namespace = "xboard"
+	}
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "traffic_queue", name), help, nil, nil)
+	}
+	return &TrafficQueueCollector{
+		queue:     queue,
+		enqueued:  desc("enqueued_samples_total", "Traffic samples accepted into the queue."),
+		processed: desc("processed_samples_total", "Traffic samples drained or ingested for persistence."),
+		dropped:   desc("dropped_samples_total", "Traffic samples dropped because the queue was full."),
+		rejected:  desc("rejected_samples_total", "Agent traffic samples rejected for retry because the queue was near capacity."),
+		retried:   desc("retried_samples_total", "Traffic samples requeued after a failed persistence attempt."),
+		pending:   desc("pending_samples", "Traffic samples currently buffered or being ingested."),
+		capacity:  desc("capacity_samples", "Maximum number of traffic samples the queue buffers."),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *TrafficQueueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.enqueued
+	ch <- c.processed
+	ch <- c.dropped
+	ch <- c.rejected
+	ch <- c.retried
+	ch <- c.pending
+	ch <- c.capacity
+}
+
+// Collect implements prometheus.Collector.
+func (c *TrafficQueueCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.queue.Stats()
+	ch <- prometheus.MustNewConstMetric(c.enqueued, prometheus.CounterValue, float64(stats.Enqueued))
+	ch <- prometheus.MustNewConstMetric(c.processed, prometheus.CounterValue, float64(stats.Processed))
+	ch <- prometheus.MustNewConstMetric(c.dropped, prometheus.CounterValue, float64(stats.Dropped))
+	ch <- prometheus.MustNewConstMetric(c.rejected, prometheus.CounterValue, float64(stats.Rejected))
+	ch <- prometheus.MustNewConstMetric(c.retried, prometheus.CounterValue, float64(stats.Retried))
+	ch <- prometheus.MustNewConstMetric(c.pending, prometheus.GaugeValue, float64(stats.Pending))
+	ch <- prometheus.MustNewConstMetric(c.capacity, prometheus.GaugeValue, float64(stats.Capacity))
+}
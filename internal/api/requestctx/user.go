@@ -10,8 +10,14 @@ import (
 
 // UserClaims stores minimal auth info derived from middleware/user guard.
 type UserClaims struct {
-	ID    string
-	Email string
+	ID             string
+	Email          string
+	ImpersonatorID int64 // 管理员模拟登录时为管理员 ID
+}
+
+// Impersonating 表示当前请求来自管理员模拟登录，敏感操作应拒绝。
+func (c UserClaims) Impersonating() bool {
+	return c.ImpersonatorID > 0
 }
 
 // AdminClaims captures admin guard metadata.
@@ -27,12 +33,21 @@ type ServerClaims struct {
 	Server *repository.Server
 }
 
+// IdentityClaims 是在路由之前已校验过的 Bearer 令牌身份，供限流与 guard 复用。
+type IdentityClaims struct {
+	UserID         int64
+	Email          string
+	IsAdmin        bool
+	ImpersonatorID int64
+}
+
 type contextKey string
 
 const (
-	userContextKey   contextKey = "xboard-user"
-	adminContextKey  contextKey = "xboard-admin"
-	serverContextKey contextKey = "xboard-server"
+	userContextKey     contextKey = "xboard-user"
+	adminContextKey    contextKey = "xboard-admin"
+	serverContextKey   contextKey = "xboard-server"
+	identityContextKey contextKey = "xboard-identity"
 )
 
 // I18nKey 用于在 context 中存储语言标识的 key 类型。
@@ -95,3 +110,17 @@ func ServerFromContext(ctx context.Context) ServerClaims {
 	claims, _ := ctx.Value(serverContextKey).(ServerClaims)
 	return claims
 }
+
+// WithIdentity attaches a verified bearer identity to context.
+func WithIdentity(ctx context.Context, claims IdentityClaims) context.Context {
+	return context.WithValue(ctx, identityContextKey, claims)
+}
+
+// IdentityFromContext fetches the verified bearer identity, returning false if missing.
+func IdentityFromContext(ctx context.Context) (IdentityClaims, bool) {
+	if ctx == nil {
+		return IdentityClaims{}, false
+	}
+	claims, ok := ctx.Value(identityContextKey).(IdentityClaims)
+	return claims, ok && claims.UserID > 0
+}
@@ -12,18 +12,117 @@ import (
 	"time"
 
 	"github.com/creamcroissant/xboard/internal/api/handler"
+	"github.com/creamcroissant/xboard/internal/api/clientip"
 	"github.com/creamcroissant/xboard/internal/api/middleware"
+	"github.com/creamcroissant/xboard/internal/api/requestctx"
 	"github.com/creamcroissant/xboard/internal/async"
 	"github.com/creamcroissant/xboard/internal/service"
 	"github.com/creamcroissant/xboard/internal/support/i18n"
 	"github.com/go-chi/chi/v5"
 	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"github.com/creamcroissant/xboard/internal/config"
 )
 
-func resolveRateLimitConfig() (middleware.RateLimitConfig, bool) {
+// rateLimitSettings 汇总全局 IP 限流与按用户、按订阅 token 的分路限流配置。
+// SubscribeIP 是订阅拉取的按 IP 上限，防止轮换 token 或短链 code 绕过全局 IP 限流。
+type rateLimitSettings struct {
+	Global             middleware.RateLimitConfig
+	API                middleware.RateLimitConfig
+	Subscribe          middleware.RateLimitConfig
+	SubscribeIP        middleware.RateLimitConfig
+	Enabled            bool
+	APIEnabled         bool
+	SubscribeEnabled   bool
+	SubscribeIPEnabled bool
+}
+
+// routeLimiters 是挂在各路由组上的限流中间件；未启用时为直通。
+type routeLimiters struct {
+	api       func(http.Handler) http.Handler
+	subscribe func(http.Handler) http.Handler
+}
+
+// WithRateLimitStore 指定共享的限流计数后端（如 Redis），为空时各限流器使用进程内计数。
+func WithRateLimitStore(store middleware.RateLimitStore) RouterOption {
+	return func(ro *routerOptions) {
+		ro.rateLimitStore = store
+	}
+}
+
+// WithClientIPResolver 指定解析客户端 IP 时使用的可信代理配置，为空时信任回环与私有网段。
+func WithClientIPResolver(resolver *clientip.Resolver) RouterOption {
+	return func(ro *routerOptions) {
+		ro.clientIP = resolver
+	}
+}
+
+func passthroughMiddleware(next http.Handler) http.Handler {
+	return next
+}
+
+func newRouteLimiters(settings rateLimitSettings) routeLimiters {
+	limiters := routeLimiters{api: passthroughMiddleware, subscribe: passthroughMiddleware}
+	if !settings.Enabled {
+		return limiters
+	}
+	if settings.APIEnabled {
+		limiters.api = middleware.RateLimit(settings.API)
+	}
+	if settings.SubscribeEnabled {
+		limiters.subscribe = middleware.RateLimit(settings.Subscribe)
+		// 先按 IP 兜底再按 token 计数，单个 IP 换 token 刷订阅仍会被拦下
+		if settings.SubscribeIPEnabled {
+			byIP, byToken := middleware.RateLimit(settings.SubscribeIP), limiters.subscribe
+			limiters.subscribe = func(next http.Handler) http.Handler {
+				return byIP(byToken(next))
+			}
+		}
+	}
+	return limiters
+}
+
+// isPublicAPIRequest 判断请求是否为无需登录的接口（登录、注册、找回密码与游客接口），
+// 这些接口即使携带有效令牌也不挂按用户计数的限流器，必须保留 IP 限流。
+func isPublicAPIRequest(r *http.Request) bool {
+	for _, prefix := range []string{"/api/v1/passport/", "/api/v2/passport/", "/api/v1/guest/", "/api/v2/guest/"} {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSubscribeRequest 判断请求是否为订阅拉取（订阅接口或短链接）。
+func isSubscribeRequest(r *http.Request) bool {
+	return r.URL.Path == "/api/v1/client/subscribe" || strings.HasPrefix(r.URL.Path, "/s/")
+}
+
+func resolveRateLimitConfig() rateLimitSettings {
+	settings := rateLimitSettings{
+		Enabled:            true,
+		APIEnabled:         true,
+		SubscribeEnabled:   true,
+		SubscribeIPEnabled: true,
+		API: middleware.RateLimitConfig{
+			Limit:       300,
+			Window:      time.Minute,
+			KeyStrategy: middleware.RateLimitKeyUser,
+		},
+		Subscribe: middleware.RateLimitConfig{
+			Limit:       30,
+			Window:      time.Minute,
+			KeyStrategy: middleware.RateLimitKeyToken,
+		},
+		// 上限高于单 token 额度，留给共享 NAT/CGNAT 后的多个用户
+		SubscribeIP: middleware.RateLimitConfig{
+			Limit:       300,
+			Window:      time.Minute,
+			KeyStrategy: middleware.RateLimitKeyIP,
+		},
+	}
 	config := middleware.RateLimitConfig{
 		Limit:     100,
 		Window:    time.Minute,
@@ -50,10 +149,95 @@ func resolveRateLimitConfig() (middleware.RateLimitConfig, bool) {
 	if raw := strings.TrimSpace(os.Getenv("XBOARD_RATE_LIMIT_WINDOW_SECONDS")); raw != "" {
 		if value, err := strconv.Atoi(raw); err == nil && value > 0 {
 			config.Window = time.Duration(value) * time.Second
+			settings.API.Window = config.Window
+		}
+	}
+
+	// 已认证的 API 请求按用户 ID 计数，订阅拉取按 token 计数，二者的上限分别配置
+	if raw := strings.TrimSpace(os.Getenv("XBOARD_RATE_LIMIT_API_LIMIT")); raw != "" {
+		if value, err := strconv.Atoi(raw); err == nil {
+			if value <= 0 {
+				settings.APIEnabled = false
+			} else {
+				settings.API.Limit = value
+			}
+		}
+	}
+
+	if raw := strings.TrimSpace(os.Getenv("XBOARD_RATE_LIMIT_SUBSCRIBE_LIMIT")); raw != "" {
+		if value, err := strconv.Atoi(raw); err == nil {
+			if value <= 0 {
+				settings.SubscribeEnabled = false
+			} else {
+				settings.Subscribe.Limit = value
+			}
+		}
+	}
+
+	if raw := strings.TrimSpace(os.Getenv("XBOARD_RATE_LIMIT_SUBSCRIBE_WINDOW_SECONDS")); raw != "" {
+		if value, err := strconv.Atoi(raw); err == nil && value > 0 {
+			settings.Subscribe.Window = time.Duration(value) * time.Second
+			settings.SubscribeIP.Window = settings.Subscribe.Window
+		}
+	}
+
+	if raw := strings.TrimSpace(os.Getenv("XBOARD_RATE_LIMIT_SUBSCRIBE_IP_LIMIT")); raw != "" {
+		if value, err := strconv.Atoi(raw); err == nil {
+			if value <= 0 {
+				settings.SubscribeIPEnabled = false
+			} else {
+				settings.SubscribeIP.Limit = value
+			}
 		}
 	}
 
-	return config, enabled
+	// 由分路限流器接管的请求不再按全局 IP 额度计数，避免共享 NAT/CGNAT 的用户互相挤占额度；
+	// 订阅拉取另有 SubscribeIP 的按 IP 上限兜底。
+	// 只有 IdentifyBearer 已校验出真实用户、且目标不是登录注册等公开接口时才跳过，
+	// 伪造或失效的 Authorization 头仍按 IP 计数。
+	config.Skip = func(r *http.Request) bool {
+		if settings.SubscribeEnabled && isSubscribeRequest(r) {
+			return true
+		}
+		if !settings.APIEnabled || isPublicAPIRequest(r) {
+			return false
+		}
+		_, ok := requestctx.IdentityFromContext(r.Context())
+		return ok
+	}
+
+	settings.Global = config
+	settings.Enabled = enabled
+	return settings
+}
+
+// resolveBodyLimitConfig 读取请求体上限配置：全局默认值之外，登录注册等公开接口收紧，
+// 配置模板导入、协议下发与配置中心等接口放宽。各项均可用环境变量覆盖，值为字节数。
+func resolveBodyLimitConfig(i18nManager *i18n.Manager) middleware.BodyLimitConfig {
+	globalLimit := envBytes("XBOARD_BODY_LIMIT_BYTES", middleware.DefaultBodyLimitBytes)
+	authLimit := envBytes("XBOARD_BODY_LIMIT_AUTH_BYTES", 64*1024)
+	configLimit := envBytes("XBOARD_BODY_LIMIT_CONFIG_BYTES", 32*1024*1024)
+
+	return middleware.BodyLimitConfig{
+		MaxBytes: globalLimit,
+		Rules: []middleware.BodyLimitRule{
+			{Pattern: "/api/v1/passport/*", MaxBytes: authLimit},
+			{Pattern: "/api/v2/passport/*", MaxBytes: authLimit},
+			{Pattern: "/api/v2/*/agent-hosts/templates/*", MaxBytes: configLimit},
+			{Pattern: "/api/v2/*/agent-hosts/*/protocols/*", MaxBytes: configLimit},
+			{Pattern: "/api/v2/*/config-center/*", MaxBytes: configLimit},
+		},
+		I18n: i18nManager,
+	}
+}
+
+func envBytes(name string, def int64) int64 {
+	if raw := strings.TrimSpace(os.Getenv(name)); raw != "" {
+		if value, err := strconv.ParseInt(raw, 10, 64); err == nil && value > 0 {
+			return value
+		}
+	}
+	return def
 }
 
 type Services struct {
@@ -86,6 +270,7 @@ type Services struct {
 	AdminSystemSettings     service.AdminSystemSettingsService
 	AgentHost               service.AgentHostService
 	AgentCore               service.AgentCoreService
+	ConfigTemplate          service.ConfigTemplateService
 	Forwarding              service.ForwardingService
 	AccessLog               service.AccessLogService
 	InboundSpec             service.InboundSpecService
@@ -102,7 +287,14 @@ type Services struct {
 	SubscriptionSource      service.SubscriptionSourceService
 	UserSelection           service.UserServerSelectionService
 	ShortLink               service.ShortLinkService
+	LoginLockout            service.LoginLockoutService
+	AuditLog                service.AuditLogService
+	UserReminder            service.UserReminderService
+	SubscriptionLog         service.SubscriptionLogService
+	Telegram                service.TelegramService
 	CDN                     service.CDNService
+	Dashboard               *service.DashboardHub
+	Readiness               service.ReadinessService
 	TrafficQueue            *async.TrafficQueue
 	SubLogQueue             *async.SubscriptionLogQueue
 	I18n                    *i18n.Manager
@@ -224,13 +416,31 @@ func NewRouter(logger *slog.Logger, services Services, metricsCfg config.Metrics
 	var metrics *middleware.Metrics
 	if metricsCfg.Enabled {
 		metrics = middleware.NewMetrics(mCfg)
+		if services.AgentHost != nil {
+			if err := prometheus.Register(middleware.NewAgentHostCollector(mCfg.Namespace, services.AgentHost, logger)); err != nil {
+				logger.Warn("register agent host metrics failed", "error", err)
+			}
+		}
+		if services.TrafficQueue != nil {
+			if err := prometheus.Register(middleware.NewTrafficQueueCollector(mCfg.Namespace, services.TrafficQueue)); err != nil {
+				logger.Warn("register traffic queue metrics failed", "error", err)
+			}
+		}
 	}
 
-	rateLimitConfig, rateLimitEnabled := resolveRateLimitConfig()
+	rateLimits := resolveRateLimitConfig()
+	rateLimits.Global.Name, rateLimits.API.Name, rateLimits.Subscribe.Name = "global", "api", "subscribe"
+	rateLimits.SubscribeIP.Name = "subscribe_ip"
+	for _, cfg := range []*middleware.RateLimitConfig{&rateLimits.Global, &rateLimits.API, &rateLimits.Subscribe, &rateLimits.SubscribeIP} {
+		cfg.Store = options.rateLimitStore
+		cfg.Logger = logger
+	}
+	limiters := newRouteLimiters(rateLimits)
 
 	r.Use(
 		chiMiddleware.RequestID,
-		chiMiddleware.RealIP,
+		middleware.RequestIDLogger(),
+		middleware.RealIP(options.clientIP),
 	)
 
 	if metricsCfg.Enabled {
@@ -239,13 +449,13 @@ func NewRouter(logger *slog.Logger, services Services, metricsCfg config.Metrics
 
 	middlewares := []func(http.Handler) http.Handler{
 		middleware.CORS(middleware.DefaultCORSConfig()),
-		middleware.BodyLimit(middleware.BodyLimitConfig{
-			MaxBytes: 10 * 1024 * 1024, // 10MB
-		}),
 	}
 
-	if rateLimitEnabled {
-		middlewares = append(middlewares, middleware.RateLimit(rateLimitConfig))
+	if rateLimits.Enabled {
+		if rateLimits.APIEnabled {
+			middlewares = append(middlewares, middleware.IdentifyBearer(services.Auth))
+		}
+		middlewares = append(middlewares, middleware.RateLimit(rateLimits.Global))
 	}
 
 	middlewares = append(middlewares,
@@ -257,6 +467,7 @@ func NewRouter(logger *slog.Logger, services Services, metricsCfg config.Metrics
 		chiMiddleware.Recoverer,
 		chiMiddleware.Compress(5),
 		middleware.I18n(services.I18n),
+		middleware.BodyLimit(resolveBodyLimitConfig(services.I18n)),
 		middleware.InstallGuard(logger, services.Install),
 	)
 
@@ -277,9 +488,7 @@ func NewRouter(logger *slog.Logger, services Services, metricsCfg config.Metrics
 		})
 	})
 
-	r.Get("/_internal/ready", func(w http.ResponseWriter, _ *http.Request) {
-		respondJSON(w, http.StatusOK, map[string]string{"status": "ready"})
-	})
+	r.Get("/_internal/ready", handler.NewReadinessHandler(services.Readiness).Ready)
 
 	// Prometheus metrics endpoint
 	if metricsCfg.Enabled {
@@ -291,12 +500,12 @@ func NewRouter(logger *slog.Logger, services Services, metricsCfg config.Metrics
 		}
 	}
 
-	registerAPIRoutes(r, services)
+	registerAPIRoutes(r, services, limiters)
 
 	// Short link redirect route (public, no auth required)
 	if services.ShortLink != nil {
 		shortLinkHandler := handler.NewShortLinkHandler(services.ShortLink, services.Subscription, services.I18n)
-		r.Get("/s/{code}", shortLinkHandler.HandleRedirect)
+		r.With(limiters.subscribe).Get("/s/{code}", shortLinkHandler.HandleRedirect)
 	}
 
 	// 使用统一的 SPA 路由处理器，避免 chi 动态路由参数 /{securePath}
@@ -360,18 +569,18 @@ func registerInstallRoutes(root chi.Router, logger *slog.Logger, install service
 	root.Handle("/install/*", http.HandlerFunc(page.serveAssets))
 }
 
-func registerAPIRoutes(root chi.Router, services Services) {
+func registerAPIRoutes(root chi.Router, services Services, limiters routeLimiters) {
 	root.Route("/api", func(api chi.Router) {
 		// V1/V2 是历史遗留的版本号，两个同时保留，确保旧客户端还能访问。
-		registerV2Routes(api, services)
-		registerV1Routes(api, services)
+		registerV2Routes(api, services, limiters)
+		registerV1Routes(api, services, limiters)
 	})
 }
 
-func registerV2Routes(api chi.Router, services Services) {
+func registerV2Routes(api chi.Router, services Services, limiters routeLimiters) {
 	api.Route("/v2", func(v2 chi.Router) {
-		registerV2AdminRoutes(v2, services.Config, services.Auth, services.AdminPath, services.Plan, services.AdminPlan, services.AdminUser, services.AdminServer, services.AdminStat, services.AdminNodeStat, services.AdminSystem, services.AdminSystemSettings, services.AdminNotice, services.AdminKnowledge, services.Invite, services.AgentHost, services.AgentCore, services.AgentLifecycleOperation, services.AgentTrafficLifecycle, services.BinaryVersion, services.Forwarding, services.CDN, services.AccessLog, services.InboundSpec, services.DriftAndDiff, services.ApplyOrchestrator, services.OperationLog, services.SubscriptionFilter, services.SubscriptionSource, services.I18n)
-		registerV2UserRoutes(v2, services.User, services.Auth, services.I18n)
+		registerV2AdminRoutes(v2, services.Config, services.Auth, services.AdminPath, services.Plan, services.AdminPlan, services.AdminUser, services.AdminServer, services.AdminStat, services.AdminNodeStat, services.AdminSystem, services.AdminSystemSettings, services.AdminNotice, services.AdminKnowledge, services.Invite, services.AgentHost, services.AgentCore, services.ConfigTemplate, services.AgentLifecycleOperation, services.AgentTrafficLifecycle, services.BinaryVersion, services.Forwarding, services.CDN, services.AccessLog, services.InboundSpec, services.DriftAndDiff, services.ApplyOrchestrator, services.OperationLog, services.SubscriptionFilter, services.SubscriptionSource, services.ShortLink, services.LoginLockout, services.AuditLog, services.UserReminder, services.SubscriptionLog, services.Subscription, services.Dashboard, services.Readiness, limiters, services.I18n)
+		registerV2UserRoutes(v2, services.User, services.Auth, limiters, services.I18n)
 		registerV2PassportRoutes(v2, services.Auth, services.Verify, services.Invite, services.Password, services.Register, services.MailLink, services.Comm, services.I18n)
 		registerV2ServerRoutes(v2, services.ServerAuth, services.ServerNode, services.Telemetry, services.Traffic, services.TrafficQueue, services.I18n)
 		registerV2GuestRoutes(v2, services.I18n)
@@ -387,7 +596,7 @@ func registerV2GuestRoutes(v2 chi.Router, i18nManager *i18n.Manager) {
 	})
 }
 
-func registerV2AdminRoutes(v2 chi.Router, configService service.ConfigService, auth service.AuthService, adminPath service.AdminPathService, plan service.PlanService, adminPlan service.AdminPlanService, adminUser service.AdminUserService, adminServer service.AdminServerService, adminStat service.AdminStatService, adminNodeStat service.AdminNodeStatService, adminSystem service.AdminSystemService, adminSystemSettings service.AdminSystemSettingsService, adminNotice service.AdminNoticeService, adminKnowledge service.AdminKnowledgeService, inviteService service.InviteService, agentHost service.AgentHostService, agentCore service.AgentCoreService, agentLifecycleOperation service.AgentLifecycleOperationService, agentTrafficLifecycle service.AgentTrafficLifecycleService, binaryVersion service.BinaryVersionService, forwarding service.ForwardingService, cdn service.CDNService, accessLog service.AccessLogService, inboundSpec service.InboundSpecService, driftAndDiff service.DriftAndDiffService, applyOrchestrator service.ApplyOrchestratorService, operationLog service.OperationLogService, subscriptionFilter service.SubscriptionFilterService, subscriptionSource service.SubscriptionSourceService, i18nManager *i18n.Manager) {
+func registerV2AdminRoutes(v2 chi.Router, configService service.ConfigService, auth service.AuthService, adminPath service.AdminPathService, plan service.PlanService, adminPlan service.AdminPlanService, adminUser service.AdminUserService, adminServer service.AdminServerService, adminStat service.AdminStatService, adminNodeStat service.AdminNodeStatService, adminSystem service.AdminSystemService, adminSystemSettings service.AdminSystemSettingsService, adminNotice service.AdminNoticeService, adminKnowledge service.AdminKnowledgeService, inviteService service.InviteService, agentHost service.AgentHostService, agentCore service.AgentCoreService, configTemplate service.ConfigTemplateService, agentLifecycleOperation service.AgentLifecycleOperationService, agentTrafficLifecycle service.AgentTrafficLifecycleService, binaryVersion service.BinaryVersionService, forwarding service.ForwardingService, cdn service.CDNService, accessLog service.AccessLogService, inboundSpec service.InboundSpecService, driftAndDiff service.DriftAndDiffService, applyOrchestrator service.ApplyOrchestratorService, operationLog service.OperationLogService, subscriptionFilter service.SubscriptionFilterService, subscriptionSource service.SubscriptionSourceService, shortLink service.ShortLinkService, loginLockout service.LoginLockoutService, auditLog service.AuditLogService, userReminder service.UserReminderService, subscriptionLog service.SubscriptionLogService, subscription service.SubscriptionService, dashboard *service.DashboardHub, readiness service.ReadinessService, limiters routeLimiters, i18nManager *i18n.Manager) {
 	adminHandler := handler.NewAdminHandler(configService)
 	adminPlanHandler := handler.NewAdminPlanHandler(plan, adminPlan, i18nManager)
 	adminUserHandler := handler.NewAdminUserHandler(adminUser)
@@ -402,20 +611,31 @@ func registerV2AdminRoutes(v2 chi.Router, configService service.ConfigService, a
 	adminForwardingHandler := handler.NewAdminForwardingHandler(forwarding, i18nManager)
 	adminCDNHandler := handler.NewAdminCDNHandler(cdn, i18nManager)
 	adminAgentCoreHandler := handler.NewAdminAgentCoreHandler(agentCore, i18nManager)
+	adminConfigTemplateHandler := handler.NewAdminConfigTemplateHandler(configTemplate, i18nManager)
 	adminAgentLifecycleHandler := handler.NewAdminAgentLifecycleHandler(agentLifecycleOperation, binaryVersion, i18nManager)
 	adminAgentTrafficHandler := handler.NewAdminAgentTrafficHandler(agentTrafficLifecycle, i18nManager)
 	adminAgentVersionHandler := handler.NewAdminAgentVersionHandler(binaryVersion, i18nManager)
 	adminSubscriptionHandler := handler.NewAdminSubscriptionHandler(subscriptionFilter, subscriptionSource, i18nManager)
 	adminAccessLogHandler := handler.NewAdminAccessLogHandler(accessLog)
+	adminShortLinkHandler := handler.NewAdminShortLinkHandler(shortLink, i18nManager)
+	adminLoginLockoutHandler := handler.NewAdminLoginLockoutHandler(loginLockout, i18nManager)
+	adminAuditLogHandler := handler.NewAdminAuditLogHandler(auditLog, i18nManager)
+	adminUserReminderHandler := handler.NewAdminUserReminderHandler(userReminder, i18nManager)
+	adminImpersonationHandler := handler.NewAdminImpersonationHandler(auth, i18nManager)
+	adminSubscriptionLogHandler := handler.NewAdminSubscriptionLogHandler(subscriptionLog)
+	adminSubscriptionPreviewHandler := handler.NewAdminSubscriptionPreviewHandler(subscription, i18nManager)
 	adminConfigCenterSpecHandler := handler.NewAdminConfigCenterSpecHandler(inboundSpec, i18nManager)
 	adminConfigCenterDiffHandler := handler.NewAdminConfigCenterDiffHandler(driftAndDiff, i18nManager)
 	adminConfigCenterDriftHandler := handler.NewAdminConfigCenterDriftHandler(driftAndDiff, i18nManager)
 	adminConfigCenterApplyHandler := handler.NewAdminConfigCenterApplyHandler(applyOrchestrator, i18nManager)
 	operationLogHandler := handler.NewOperationLogHandler(operationLog, i18nManager)
+	adminDashboardWSHandler := handler.NewAdminDashboardWSHandler(dashboard, i18nManager)
+	readinessHandler := handler.NewReadinessHandler(readiness)
 
 	v2.Route("/{securePath}", func(admin chi.Router) {
-		admin.Use(middleware.AdminGuard(auth, adminPath))
+		admin.Use(middleware.AdminGuard(auth, adminPath), limiters.api)
 		mountHandler(admin, "/config", adminHandler)
+		admin.Get("/ws", adminDashboardWSHandler.Serve)
 		mountHandler(admin, "/invite", adminInviteHandler)
 		mountHandler(admin, "/plan", adminPlanHandler)
 		// Plan RESTful endpoints
@@ -424,6 +644,9 @@ func registerV2AdminRoutes(v2 chi.Router, configService service.ConfigService, a
 		admin.Get("/plan/{id:[0-9]+}", adminPlanHandler.Get)
 		admin.Put("/plan/{id:[0-9]+}", adminPlanHandler.Update)
 		admin.Delete("/plan/{id:[0-9]+}", adminPlanHandler.Delete)
+		admin.Get("/plan/{id:[0-9]+}/groups", adminPlanHandler.Groups)
+		admin.Post("/plan/{id:[0-9]+}/groups", adminPlanHandler.AttachGroups)
+		admin.Delete("/plan/{id:[0-9]+}/groups/{group_id:[0-9]+}", adminPlanHandler.DetachGroup)
 		mountHandler(admin, "/server/group", adminServerHandler)
 		mountHandler(admin, "/server/route", adminServerHandler)
 		mountHandler(admin, "/server/manage", adminServerHandler)
@@ -434,6 +657,11 @@ func registerV2AdminRoutes(v2 chi.Router, configService service.ConfigService, a
 		admin.Get("/user/{id:[0-9]+}", adminUserHandler.Get)
 		admin.Put("/user/{id:[0-9]+}", adminUserHandler.Update)
 		admin.Delete("/user/{id:[0-9]+}", adminUserHandler.Delete)
+		admin.Get("/user/{id:[0-9]+}/effective-groups", adminServerHandler.UserEffectiveGroups)
+		admin.Post("/user/{id:[0-9]+}/impersonate", adminImpersonationHandler.Impersonate)
+		admin.Get("/user/{id:[0-9]+}/subscription-preview", adminSubscriptionPreviewHandler.Preview)
+		admin.Post("/user/bulk", adminUserHandler.Bulk)
+		admin.Get("/user/reminders/preview", adminUserReminderHandler.Preview)
 		mountHandler(admin, "/stat", adminStatHandler)
 		// Node statistics endpoints
 		admin.Get("/nodes/stat/fetch", adminNodeStatHandler.GetServerStats)
@@ -442,6 +670,7 @@ func registerV2AdminRoutes(v2 chi.Router, configService service.ConfigService, a
 		mountHandler(admin, "/system", adminSystemHandler)
 		// System RESTful endpoints
 		admin.Get("/system/status", adminSystemHandler.Status)
+		admin.Get("/system/readiness", readinessHandler.Detail)
 		mountHandler(admin, "/notice", adminNoticeHandler)
 		// Notice RESTful endpoints
 		admin.Get("/notice", adminNoticeHandler.List)
@@ -455,19 +684,42 @@ func registerV2AdminRoutes(v2 chi.Router, configService service.ConfigService, a
 		admin.Get("/agent-hosts", agentHostHandler.List)
 		admin.Post("/agent-hosts", agentHostHandler.Create)
 		admin.Post("/agent-hosts/refresh", agentHostHandler.RefreshAll) // Must be before {id} routes
+		admin.Post("/agent-hosts/reality/keypair", agentHostHandler.GenerateRealityKeyPair)
+		admin.Post("/agent-hosts/templates/lint", adminConfigTemplateHandler.Lint)
+		admin.Post("/agent-hosts/templates/import", adminConfigTemplateHandler.Import)
+		admin.Put("/agent-hosts/templates/{template_id}", adminConfigTemplateHandler.Update)
+		admin.Get("/agent-hosts/templates/{template_id}/export", adminConfigTemplateHandler.Export)
+		admin.Get("/agent-hosts/templates/{template_id}/compatibility", agentHostHandler.TemplateCompatibility)
+		admin.Get("/agent-hosts/templates/{template_id}/versions", adminConfigTemplateHandler.ListVersions)
+		admin.Get("/agent-hosts/templates/{template_id}/versions/{version}", adminConfigTemplateHandler.GetVersion)
+		admin.Post("/agent-hosts/templates/{template_id}/rollback", adminConfigTemplateHandler.Rollback)
 		admin.Get("/agent-hosts/{id}", agentHostHandler.Get)
 		admin.Put("/agent-hosts/{id}", agentHostHandler.Update)
 		admin.Delete("/agent-hosts/{id}", agentHostHandler.Delete)
 		admin.Post("/agent-hosts/{id}/refresh", agentHostHandler.Refresh)
+		admin.Post("/agent-hosts/{id}/protocols/preview", agentHostHandler.PreviewConfig)
+		admin.Post("/agent-hosts/{id}/resync", agentHostHandler.Resync)
+		admin.Post("/agent-hosts/{id}/capabilities/redetect", agentHostHandler.RedetectCapabilities)
+		admin.Post("/agent-hosts/{id}/maintenance", agentHostHandler.EnableMaintenance)
+		admin.Delete("/agent-hosts/{id}/maintenance", agentHostHandler.DisableMaintenance)
+		admin.Put("/agent-hosts/{id}/intervals", agentHostHandler.UpdateIntervals)
+		admin.Post("/agent-hosts/{id}/token/rotate", agentHostHandler.RotateToken)
+		admin.Get("/agent-hosts/{id}/diagnostics", agentHostHandler.Diagnostics)
+		admin.Get("/agent-hosts/{id}/path-usage", agentHostHandler.PathUsage)
+		admin.Get("/agent-hosts/{id}/client-configs", agentHostHandler.ClientConfigs)
+		admin.Put("/agent-hosts/{id}/template", agentHostHandler.AssignTemplate)
 
 		// Agent core management endpoints
 		admin.Get("/agent-hosts/{id}/cores", adminAgentCoreHandler.ListCores)
 		admin.Get("/agent-hosts/{id}/core-instances", adminAgentCoreHandler.ListInstances)
+		admin.Get("/agent-hosts/{id}/core-topology", adminAgentCoreHandler.CoreTopology)
 		admin.Get("/agent-hosts/{id}/core-operations", adminAgentCoreHandler.ListOperations)
 		admin.Post("/agent-hosts/{id}/core-instances", adminAgentCoreHandler.CreateInstance)
 		admin.Delete("/agent-hosts/{id}/core-instances/{instance_id}", adminAgentCoreHandler.DeleteInstance)
 		admin.Post("/agent-hosts/{id}/core-switch", adminAgentCoreHandler.SwitchCore)
+		admin.Get("/agent-hosts/{id}/core-switch/{switch_id}/events", adminAgentCoreHandler.SwitchEvents)
 		admin.Post("/agent-hosts/{id}/core-install", adminAgentCoreHandler.InstallCore)
+		admin.Post("/agent-hosts/{id}/core-upgrade", adminAgentCoreHandler.UpgradeCore)
 		admin.Post("/agent-hosts/{id}/core-convert", adminAgentCoreHandler.ConvertConfig)
 		admin.Get("/agent-hosts/{id}/core-switch-logs", adminAgentCoreHandler.ListSwitchLogs)
 		admin.Get("/agent-hosts/{id}/versions", adminAgentVersionHandler.ListVersions)
@@ -535,9 +787,27 @@ func registerV2AdminRoutes(v2 chi.Router, configService service.ConfigService, a
 		admin.Route("/access-logs", func(logs chi.Router) {
 			logs.Get("/fetch", adminAccessLogHandler.Fetch)
 			logs.Get("/stats", adminAccessLogHandler.GetStats)
+			logs.Get("/top-targets", adminAccessLogHandler.TopTargets)
+			logs.Get("/top-sources", adminAccessLogHandler.TopSources)
 			logs.Post("/cleanup", adminAccessLogHandler.Cleanup)
+			logs.Get("/retention", adminAccessLogHandler.GetRetention)
 		})
 
+		// Subscription log analytics endpoints
+		admin.Route("/subscription-logs", func(logs chi.Router) {
+			logs.Get("/unique-ips", adminSubscriptionLogHandler.UniqueIPs)
+			logs.Get("/clients", adminSubscriptionLogHandler.Clients)
+			logs.Get("/users/{id:[0-9]+}", adminSubscriptionLogHandler.UserIPs)
+		})
+
+		// Short link analytics endpoints
+		admin.Get("/short-links", adminShortLinkHandler.Fetch)
+
+		// Login lockout endpoints
+		admin.Get("/login-lockouts", adminLoginLockoutHandler.Fetch)
+		admin.Post("/login-lockouts/clear", adminLoginLockoutHandler.Clear)
+		admin.Get("/audit-logs", adminAuditLogHandler.Fetch)
+
 		// Config center spec endpoints
 		admin.Route("/config-center/specs", func(specs chi.Router) {
 			specs.Get("/", adminConfigCenterSpecHandler.ListSpecs)
@@ -572,10 +842,10 @@ func registerV2AdminRoutes(v2 chi.Router, configService service.ConfigService, a
 	})
 }
 
-func registerV2UserRoutes(v2 chi.Router, userService service.UserService, auth service.AuthService, i18nManager *i18n.Manager) {
+func registerV2UserRoutes(v2 chi.Router, userService service.UserService, auth service.AuthService, limiters routeLimiters, i18nManager *i18n.Manager) {
 	userHandler := handler.NewUserHandler(userService, i18nManager)
 	v2.Route("/user", func(user chi.Router) {
-		user.Use(middleware.UserGuard(auth))
+		user.Use(middleware.UserGuard(auth), limiters.api)
 		mountHandler(user, "/", userHandler)
 	})
 }
@@ -601,37 +871,40 @@ func registerV2ServerRoutes(v2 chi.Router, serverAuth service.ServerAuthService,
 	})
 }
 
-func registerV1Routes(api chi.Router, services Services) {
+func registerV1Routes(api chi.Router, services Services, limiters routeLimiters) {
 	api.Route("/v1", func(v1 chi.Router) {
-		registerV1ClientRoutes(v1, services.User, services.Auth, services.Subscription, services.I18n)
-		registerV1GuestRoutes(v1, services.Comm, services.Plan, services.I18n)
+		registerV1ClientRoutes(v1, services.User, services.Auth, services.Subscription, limiters, services.I18n)
+		registerV1GuestRoutes(v1, services.Comm, services.Plan, services.Telegram, services.I18n)
 		registerV1PassportRoutes(v1, services.Auth, services.Verify, services.Invite, services.Password, services.Register, services.MailLink, services.Comm, services.I18n)
-		registerV1UserRoutes(v1, services.User, services.UserKnowledge, services.UserNotice, services.UserStat, services.Auth, services.Plan, services.Server, services.UserSelection, services.ShortLink, services.Subscription, services.I18n)
+		registerV1UserRoutes(v1, services.User, services.UserKnowledge, services.UserNotice, services.UserStat, services.Auth, services.Plan, services.Server, services.UserSelection, services.ShortLink, services.Subscription, services.Telegram, limiters, services.I18n)
 		registerV1AgentRoutes(v1, services.AgentHost, services.I18n)
 	})
 }
 
-func registerV1ClientRoutes(v1 chi.Router, userService service.UserService, auth service.AuthService, subscription service.SubscriptionService, i18nManager *i18n.Manager) {
+func registerV1ClientRoutes(v1 chi.Router, userService service.UserService, auth service.AuthService, subscription service.SubscriptionService, limiters routeLimiters, i18nManager *i18n.Manager) {
 	userHandler := handler.NewUserHandler(userService, i18nManager)
 	clientHandler := handler.NewClientHandler(subscription, i18nManager)
 	v1.Route("/client", func(client chi.Router) {
 		// subscribe endpoint uses token query param for auth, not JWT
-		mountHandler(client, "/subscribe", clientHandler)
+		mountHandler(client.With(limiters.subscribe), "/subscribe", clientHandler)
 
 		// other endpoints require JWT auth
 		client.Group(func(protected chi.Router) {
-			protected.Use(middleware.UserGuard(auth))
+			protected.Use(middleware.UserGuard(auth), limiters.api)
 			mountHandler(protected, "/", userHandler)
 			mountHandler(protected, "/app", userHandler)
 		})
 	})
 }
 
-func registerV1GuestRoutes(v1 chi.Router, comm service.CommService, plan service.PlanService, i18nManager *i18n.Manager) {
+func registerV1GuestRoutes(v1 chi.Router, comm service.CommService, plan service.PlanService, telegram service.TelegramService, i18nManager *i18n.Manager) {
 	guestHandler := handler.NewGuestHandler(comm, i18nManager)
 	guestPlanHandler := handler.NewGuestPlanHandler(plan, i18nManager)
+	telegramHandler := handler.NewTelegramHandler(telegram, i18nManager)
 	v1.Route("/guest", func(guest chi.Router) {
 		mountHandler(guest, "/plan", guestPlanHandler)
+		// Telegram 机器人 webhook 模式的回调入口，鉴权依赖 secret_token 请求头
+		guest.Post("/telegram/webhook", telegramHandler.Webhook)
 		mountHandler(guest, "/telegram", guestHandler)
 		mountHandler(guest, "/comm", guestHandler)
 	})
@@ -645,16 +918,18 @@ func registerV1PassportRoutes(v1 chi.Router, auth service.AuthService, verify se
 	})
 }
 
-func registerV1UserRoutes(v1 chi.Router, userService service.UserService, knowledgeService service.UserKnowledgeService, noticeService service.UserNoticeService, statService service.UserStatService, auth service.AuthService, planService service.PlanService, serverService service.ServerService, selectionService service.UserServerSelectionService, shortLinkService service.ShortLinkService, subscriptionService service.SubscriptionService, i18nManager *i18n.Manager) {
+func registerV1UserRoutes(v1 chi.Router, userService service.UserService, knowledgeService service.UserKnowledgeService, noticeService service.UserNoticeService, statService service.UserStatService, auth service.AuthService, planService service.PlanService, serverService service.ServerService, selectionService service.UserServerSelectionService, shortLinkService service.ShortLinkService, subscriptionService service.SubscriptionService, telegramService service.TelegramService, limiters routeLimiters, i18nManager *i18n.Manager) {
 	userHandler := handler.NewUserHandler(userService, i18nManager)
+	telegramHandler := handler.NewTelegramHandler(telegramService, i18nManager)
 	planHandler := handler.NewUserPlanHandler(planService, i18nManager)
 	userServerHandler := handler.NewUserServerHandler(serverService, selectionService, i18nManager)
 	userKnowledgeHandler := handler.NewUserKnowledgeHandler(knowledgeService, i18nManager)
 	userNoticeHandler := handler.NewUserNoticeHandler(noticeService, i18nManager)
 	userStatHandler := handler.NewUserStatHandler(statService, i18nManager)
 	shortLinkHandler := handler.NewShortLinkHandler(shortLinkService, subscriptionService, i18nManager)
+	impersonationHandler := handler.NewAdminImpersonationHandler(auth, i18nManager)
 	v1.Route("/user", func(user chi.Router) {
-		user.Use(middleware.UserGuard(auth))
+		user.Use(middleware.UserGuard(auth), limiters.api)
 		// 这里的 mountHandler 会同时绑定 /path 和 /path/*，避免重复写路由。
 		mountHandler(user, "/", userHandler)
 		mountHandler(user, "/invite", userHandler)
@@ -662,12 +937,13 @@ func registerV1UserRoutes(v1 chi.Router, userService service.UserService, knowle
 			// Explicitly register /notice/unread to avoid chi wildcard matching edge cases
 			user.Get("/notice/unread", userNoticeHandler.ServeHTTP)
 		mountHandler(user, "/server", userServerHandler)
-		mountHandler(user, "/telegram", userHandler)
+		mountHandler(user, "/telegram", telegramHandler)
 		mountHandler(user, "/comm", userHandler)
 		mountHandler(user, "/knowledge", userKnowledgeHandler)
 		mountHandler(user, "/plan", planHandler)
 		mountHandler(user, "/stat", userStatHandler)
 		mountHandler(user, "/shortlink", shortLinkHandler)
+		user.Post("/impersonation/stop", impersonationHandler.Stop)
 	})
 }
 
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/api/middleware"
+)
+
+func TestSubscribeLimiterKeepsIPCeiling(t *testing.T) {
+	settings := rateLimitSettings{
+		Enabled:            true,
+		SubscribeEnabled:   true,
+		SubscribeIPEnabled: true,
+		Subscribe:          middleware.RateLimitConfig{Limit: 10, Window: time.Minute, KeyStrategy: middleware.RateLimitKeyToken},
+		SubscribeIP:        middleware.RateLimitConfig{Limit: 2, Window: time.Minute, KeyStrategy: middleware.RateLimitKeyIP},
+	}
+	handler := newRouteLimiters(settings).subscribe(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// 同一 IP 每次换 token，按 token 计数不会触顶，只能由 IP 上限拦截
+	for i, token := range []string{"a", "b", "c"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/client/subscribe?token="+token, nil)
+		req.RemoteAddr = "203.0.113.7:1234"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		want := http.StatusOK
+		if i == 2 {
+			want = http.StatusTooManyRequests
+		}
+		if rec.Code != want {
+			t.Fatalf("request %d: status = %d, want %d", i, rec.Code, want)
+		}
+	}
+
+	// 其他 IP 不受影响
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/client/subscribe?token=d", nil)
+	req.RemoteAddr = "198.51.100.9:1234"
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("other ip: status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
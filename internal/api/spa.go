@@ -14,6 +14,8 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/creamcroissant/xboard/internal/api/clientip"
+	"github.com/creamcroissant/xboard/internal/api/middleware"
 	"github.com/creamcroissant/xboard/internal/service"
 	"github.com/go-chi/chi/v5"
 )
@@ -22,9 +24,11 @@ import (
 type RouterOption func(*routerOptions)
 
 type routerOptions struct {
-	adminUI   AdminUIOptions
-	userUI    UserUIOptions
-	installUI InstallUIOptions
+	adminUI        AdminUIOptions
+	userUI         UserUIOptions
+	installUI      InstallUIOptions
+	rateLimitStore middleware.RateLimitStore
+	clientIP       *clientip.Resolver
 }
 
 // AdminUIOptions 控制管理端前端资源的加载与品牌定制。
@@ -0,0 +1,117 @@
+package async
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+// ShortLinkHitQueue aggregates short link hits in memory and flushes them in batches,
+// keeping the redirect path free of database writes.
+type ShortLinkHitQueue struct {
+	mu     sync.Mutex
+	hits   map[int64]*shortLinkHit
+	repo   repository.ShortLinkRepository
+	logger *slog.Logger
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// shortLinkHit 是同一短链接在一个刷新周期内的累计访问。
+type shortLinkHit struct {
+	count        int64
+	lastAccessed int64
+}
+
+const (
+	shortLinkHitFlushInterval = 5 * time.Second
+	shortLinkHitWriteTimeout  = 3 * time.Second
+)
+
+// NewShortLinkHitQueue constructs a batching queue for short link hit counters.
+func NewShortLinkHitQueue(repo repository.ShortLinkRepository, logger *slog.Logger) *ShortLinkHitQueue {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &ShortLinkHitQueue{
+		hits:   make(map[int64]*shortLinkHit),
+		repo:   repo,
+		logger: logger,
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go q.worker()
+	return q
+}
+
+// Record counts one access of the short link; it never blocks on I/O.
+func (q *ShortLinkHitQueue) Record(linkID int64, accessedAt int64) {
+	if q == nil || linkID <= 0 {
+		return
+	}
+	q.mu.Lock()
+	hit, ok := q.hits[linkID]
+	if !ok {
+		hit = &shortLinkHit{}
+		q.hits[linkID] = hit
+	}
+	hit.count++
+	if accessedAt > hit.lastAccessed {
+		hit.lastAccessed = accessedAt
+	}
+	q.mu.Unlock()
+}
+
+// worker periodically flushes aggregated hits to the database.
+func (q *ShortLinkHitQueue) worker() {
+	defer close(q.done)
+	ticker := time.NewTicker(shortLinkHitFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-q.ctx.Done():
+			q.flush()
+			return
+		case <-ticker.C:
+			q.flush()
+		}
+	}
+}
+
+// flush writes all pending counters to the repository.
+func (q *ShortLinkHitQueue) flush() {
+	q.mu.Lock()
+	if len(q.hits) == 0 {
+		q.mu.Unlock()
+		return
+	}
+	pending := q.hits
+	q.hits = make(map[int64]*shortLinkHit)
+	q.mu.Unlock()
+
+	for linkID, hit := range pending {
+		// 使用独立上下文，保证 Stop 时最后一批计数仍能写入
+		writeCtx, cancel := context.WithTimeout(context.Background(), shortLinkHitWriteTimeout)
+		err := q.repo.AddAccessCount(writeCtx, linkID, hit.count, hit.lastAccessed)
+		cancel()
+		if err != nil {
+			q.logger.Error("failed to persist short link hits", "error", err, "link_id", linkID, "count", hit.count)
+		}
+	}
+}
+
+// Stop shuts down the worker after flushing pending hits.
+func (q *ShortLinkHitQueue) Stop() {
+	if q == nil {
+		return
+	}
+	q.cancel()
+	<-q.done
+}
@@ -22,25 +22,69 @@ type TrafficBatch struct {
 	Samples []UniProxyPushSample
 }
 
+// DefaultTrafficQueueCapacity 为未配置容量时可缓冲的最大样本数。
+const DefaultTrafficQueueCapacity = 100000
+
+// trafficQueueHighWatermark 为判定"接近满载"的占用比例，超过后上游应退避重试。
+const trafficQueueHighWatermark = 0.9
+
+// TrafficQueueStats 汇总队列的累计计数（单位为样本数）。
+// Pending 包含缓冲中的样本与同步写入路径正在处理的样本。
+type TrafficQueueStats struct {
+	Enqueued  uint64 `json:"enqueued"`
+	Processed uint64 `json:"processed"`
+	Dropped   uint64 `json:"dropped"`
+	Rejected  uint64 `json:"rejected"`
+	Retried   uint64 `json:"retried"`
+	Pending   int    `json:"pending"`
+	Capacity  int    `json:"capacity"`
+}
+
 // TrafficQueue buffers push reports before background ingestion.
+// 容量按样本数计算，超出容量的批次会被整体丢弃并计入 Dropped。
+// 同步写入的 Agent 上报通过 Reserve/Release 占用同一份容量，高水位时被拒绝并计入 Rejected。
 type TrafficQueue struct {
-	mu      sync.Mutex
-	batches []TrafficBatch
+	mu              sync.Mutex
+	batches         []TrafficBatch
+	capacity        int
+	pendingSamples  int
+	inflightSamples int
+	enqueued        uint64
+	processed       uint64
+	dropped         uint64
+	rejected        uint64
+	retried         uint64
 }
 
 // NewTrafficQueue constructs an empty buffer for traffic samples.
 func NewTrafficQueue() *TrafficQueue {
-	return &TrafficQueue{batches: make([]TrafficBatch, 0)}
+	return NewTrafficQueueWithCapacity(DefaultTrafficQueueCapacity)
+}
+
+// NewTrafficQueueWithCapacity constructs a buffer bounded to capacity samples.
+func NewTrafficQueueWithCapacity(capacity int) *TrafficQueue {
+	if capacity <= 0 {
+		capacity = DefaultTrafficQueueCapacity
+	}
+	return &TrafficQueue{batches: make([]TrafficBatch, 0), capacity: capacity}
 }
 
 // Enqueue appends a server+sample batch for asynchronous processing.
-func (q *TrafficQueue) Enqueue(server *repository.Server, samples []UniProxyPushSample) {
+// 返回 false 表示队列已满，批次被丢弃。
+func (q *TrafficQueue) Enqueue(server *repository.Server, samples []UniProxyPushSample) bool {
 	if q == nil || server == nil || len(samples) == 0 {
-		return
+		return false
 	}
 	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.pendingSamples+q.inflightSamples+len(samples) > q.capacity {
+		q.dropped += uint64(len(samples))
+		return false
+	}
 	q.batches = append(q.batches, TrafficBatch{Server: cloneServer(server), Samples: cloneSamples(samples)})
-	q.mu.Unlock()
+	q.pendingSamples += len(samples)
+	q.enqueued += uint64(len(samples))
+	return true
 }
 
 // Drain returns all pending batches and clears the queue.
@@ -52,6 +96,8 @@ func (q *TrafficQueue) Drain() []TrafficBatch {
 	defer q.mu.Unlock()
 	drained := q.batches
 	q.batches = make([]TrafficBatch, 0)
+	q.processed += uint64(q.pendingSamples)
+	q.pendingSamples = 0
 	return drained
 }
 
@@ -65,13 +111,82 @@ func (q *TrafficQueue) Pending() int {
 	return len(q.batches)
 }
 
+// NearCapacity 判断缓冲样本数是否已超过高水位，调用方应据此让上游退避。
+func (q *TrafficQueue) NearCapacity() bool {
+	if q == nil {
+		return false
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.nearCapacityLocked(0)
+}
+
+func (q *TrafficQueue) nearCapacityLocked(extra int) bool {
+	return float64(q.pendingSamples+q.inflightSamples+extra) >= float64(q.capacity)*trafficQueueHighWatermark
+}
+
+// Reserve 为同步写入路径占用 n 个样本的容量；占用后会越过高水位时拒绝并计入 Rejected，
+// 调用方应让上游退避重试。成功时必须调用 Release 归还。
+func (q *TrafficQueue) Reserve(n int) bool {
+	if q == nil || n <= 0 {
+		return true
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	// 空闲时总是放行，避免超大单批永远无法写入
+	if q.pendingSamples+q.inflightSamples > 0 && q.nearCapacityLocked(n) {
+		q.rejected += uint64(n)
+		return false
+	}
+	q.inflightSamples += n
+	q.enqueued += uint64(n)
+	return true
+}
+
+// Release 归还 Reserve 占用的容量；persisted 为 true 时计入 Processed。
+func (q *TrafficQueue) Release(n int, persisted bool) {
+	if q == nil || n <= 0 {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.inflightSamples -= n
+	if q.inflightSamples < 0 {
+		q.inflightSamples = 0
+	}
+	if persisted {
+		q.processed += uint64(n)
+	}
+}
+
+// Stats returns cumulative enqueued/processed/dropped sample counts.
+func (q *TrafficQueue) Stats() TrafficQueueStats {
+	if q == nil {
+		return TrafficQueueStats{}
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return TrafficQueueStats{
+		Enqueued:  q.enqueued,
+		Processed: q.processed,
+		Dropped:   q.dropped,
+		Rejected:  q.rejected,
+		Retried:   q.retried,
+		Pending:   q.pendingSamples + q.inflightSamples,
+		Capacity:  q.capacity,
+	}
+}
+
 // Requeue prepends a batch for retry handling.
+// 重试批次不受容量限制，避免已接收的数据在持久化失败后丢失；Processed 为单调计数，重试另计入 Retried。
 func (q *TrafficQueue) Requeue(batch TrafficBatch) {
 	if q == nil || batch.Server == nil || len(batch.Samples) == 0 {
 		return
 	}
 	q.mu.Lock()
 	q.batches = append([]TrafficBatch{batch.clone()}, q.batches...)
+	q.pendingSamples += len(batch.Samples)
+	q.retried += uint64(len(batch.Samples))
 	q.mu.Unlock()
 }
 
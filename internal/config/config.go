@@ -17,16 +17,27 @@ type Config struct {
 	Metrics   MetricsConfig   `mapstructure:"metrics"`
 	UI        UIConfig        `mapstructure:"ui"`
 	Scheduler SchedulerConfig `mapstructure:"scheduler"`
+	Queue     QueueConfig     `mapstructure:"queue"`
+	GeoIP     GeoIPConfig     `mapstructure:"geoip"`
+	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
+	I18n      I18nConfig      `mapstructure:"i18n"`
 	Cores     []CoreConfig    `mapstructure:"cores"`
 	Nodes     []NodeConfig    `mapstructure:"nodes"`
 }
 
 // GRPCConfig 定义 Agent 通信所需的 gRPC 服务配置。
 type GRPCConfig struct {
-	Enabled       bool          `mapstructure:"enabled"`
-	Addr          string        `mapstructure:"addr"`
-	ReuseHTTPPort bool          `mapstructure:"reuse_http_port"`
-	TLS           GRPCTLSConfig `mapstructure:"tls"`
+	Enabled       bool                    `mapstructure:"enabled"`
+	Addr          string                  `mapstructure:"addr"`
+	ReuseHTTPPort bool                    `mapstructure:"reuse_http_port"`
+	TLS           GRPCTLSConfig           `mapstructure:"tls"`
+	ConfigSigning GRPCConfigSigningConfig `mapstructure:"config_signing"`
+}
+
+// GRPCConfigSigningConfig 定义下发配置的 HMAC 签名；Secret 为空时不签名，需与 Agent 侧密钥一致。
+type GRPCConfigSigningConfig struct {
+	KeyID  string `mapstructure:"key_id"`
+	Secret string `mapstructure:"secret"`
 }
 
 // GRPCTLSConfig 定义 gRPC 服务的 TLS 配置。
@@ -39,6 +50,11 @@ type GRPCTLSConfig struct {
 // SecurityConfig 定义安全相关配置。
 type SecurityConfig struct {
 	SubscribeObfuscation bool `mapstructure:"subscribe_obfuscation"`
+	// TrustedProxies 列出可信反向代理的 IP 或 CIDR，解析 X-Forwarded-For 时从右向左跳过这些地址。
+	// 未配置时信任回环与私有网段。
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+	// TrustedHops 大于 0 时改为按固定跳数取值（含直连对端），适用于 CDN 等出口地址不固定的场景。
+	TrustedHops int `mapstructure:"trusted_hops"`
 }
 
 // MetricsConfig 定义 Prometheus 指标配置。
@@ -48,6 +64,8 @@ type MetricsConfig struct {
 	Subsystem string    `mapstructure:"subsystem"`
 	Token     string    `mapstructure:"token"`
 	Buckets   []float64 `mapstructure:"buckets"`
+	// TrafficPlanAllowlist 列出在流量指标中单独拆分的套餐 ID，其余套餐归入 plan_id="other"
+	TrafficPlanAllowlist []int64 `mapstructure:"traffic_plan_allowlist"`
 }
 
 // HTTPConfig 定义 HTTP 服务配置。
@@ -123,6 +141,45 @@ type SchedulerConfig struct {
 	TelegramNotify string `mapstructure:"telegram_notify"`
 }
 
+// QueueConfig 定义 Panel 内存队列的容量配置。
+type QueueConfig struct {
+	// TrafficCapacity 为流量队列可缓冲的最大样本数，<=0 时使用默认值
+	TrafficCapacity int `mapstructure:"traffic_capacity"`
+}
+
+// GeoIPConfig 定义访问日志来源 IP 归属地解析所用的 MaxMind mmdb 文件，均为空时不解析。
+type GeoIPConfig struct {
+	// CountryDB 为国家库路径（如 GeoLite2-Country.mmdb）
+	CountryDB string `mapstructure:"country_db"`
+	// ASNDB 为 ASN 库路径（如 GeoLite2-ASN.mmdb）
+	ASNDB string `mapstructure:"asn_db"`
+}
+
+// RateLimitConfig 定义 HTTP 限流的计数后端；限流阈值仍由 XBOARD_RATE_LIMIT_* 环境变量控制。
+type RateLimitConfig struct {
+	// Backend 为 memory（默认，仅单实例有效）或 redis（多个面板副本共享计数，需 Redis >= 5.0）
+	Backend string      `mapstructure:"backend"`
+	Redis   RedisConfig `mapstructure:"redis"`
+}
+
+// I18nConfig 定义翻译回退与缺失键报告。
+type I18nConfig struct {
+	DefaultLang string `mapstructure:"default_lang"`
+	// Fallbacks 为各语言的回退链，例如 zh-TW: [zh-CN]；链尾总会再回退到 DefaultLang
+	Fallbacks map[string][]string `mapstructure:"fallbacks"`
+	// ReportMissing 开启后记录缺失翻译的语言与键（去重），便于补全语言包
+	ReportMissing bool `mapstructure:"report_missing"`
+}
+
+// RedisConfig 定义 Redis 连接参数。
+type RedisConfig struct {
+	Addr      string        `mapstructure:"addr"`
+	Password  string        `mapstructure:"password"`
+	DB        int           `mapstructure:"db"`
+	KeyPrefix string        `mapstructure:"key_prefix"`
+	Timeout   time.Duration `mapstructure:"timeout"`
+}
+
 // CoreConfig 定义代理核心配置（Xray/Sing-box）。
 type CoreConfig struct {
 	Type         string        `mapstructure:"type"`
@@ -176,5 +233,14 @@ func (c *Config) Validate() error {
 	if c.GRPC.Enabled && c.GRPC.ReuseHTTPPort && c.GRPC.TLS.Enabled {
 		return fmt.Errorf("grpc.tls.enabled is not supported when grpc.reuse_http_port=true")
 	}
+	switch c.RateLimit.Backend {
+	case "", "memory":
+	case "redis":
+		if c.RateLimit.Redis.Addr == "" {
+			return fmt.Errorf("rate_limit.redis.addr is required when rate_limit.backend=redis")
+		}
+	default:
+		return fmt.Errorf("unsupported rate_limit.backend %q", c.RateLimit.Backend)
+	}
 	return nil
 }
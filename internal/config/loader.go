@@ -173,6 +173,8 @@ func bindEnv(v *viper.Viper) error {
 		"grpc.tls.enabled":           {"XBOARD_GRPC_TLS_ENABLED"},
 		"grpc.tls.cert_file":         {"XBOARD_GRPC_TLS_CERT_FILE"},
 		"grpc.tls.key_file":          {"XBOARD_GRPC_TLS_KEY_FILE"},
+		"grpc.config_signing.key_id": {"XBOARD_GRPC_CONFIG_SIGNING_KEY_ID"},
+		"grpc.config_signing.secret": {"XBOARD_GRPC_CONFIG_SIGNING_SECRET"},
 		"ui.install.enabled":         {"XBOARD_UI_INSTALL_ENABLED", "XBOARD_INSTALL_UI_ENABLED", "INSTALL_UI_ENABLED"},
 		"ui.install.dir":             {"XBOARD_UI_INSTALL_DIR", "XBOARD_INSTALL_UI_DIR", "INSTALL_UI_DIR"},
 		"ui.admin.logo":              {"XBOARD_UI_ADMIN_LOGO", "XBOARD_ADMIN_UI_LOGO", "ADMIN_UI_LOGO"},
@@ -181,6 +183,10 @@ func bindEnv(v *viper.Viper) error {
 		"scheduler.traffic_fetch":    {"XBOARD_SCHEDULER_TRAFFIC_FETCH"},
 		"scheduler.email_notify":     {"XBOARD_SCHEDULER_EMAIL_NOTIFY"},
 		"scheduler.telegram_notify":  {"XBOARD_SCHEDULER_TELEGRAM_NOTIFY"},
+		"rate_limit.backend":         {"XBOARD_RATE_LIMIT_BACKEND"},
+		"rate_limit.redis.addr":      {"XBOARD_RATE_LIMIT_REDIS_ADDR", "XBOARD_REDIS_ADDR"},
+		"rate_limit.redis.password":  {"XBOARD_RATE_LIMIT_REDIS_PASSWORD", "XBOARD_REDIS_PASSWORD"},
+		"rate_limit.redis.db":        {"XBOARD_RATE_LIMIT_REDIS_DB", "XBOARD_REDIS_DB"},
 	}
 	for key, envs := range bindings {
 		args := append([]string{key}, envs...)
@@ -199,6 +205,8 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("log.environment", "production")
 	v.SetDefault("log.log_dir", "logs")
 	v.SetDefault("log.max_days", 7)
+	v.SetDefault("i18n.default_lang", "en-US")
+	v.SetDefault("i18n.report_missing", false)
 	v.SetDefault("database.driver", "sqlite")
 	v.SetDefault("database.path", "data/xboard.db")
 	v.SetDefault("auth.signing_key", "change-me")
@@ -225,6 +233,10 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("scheduler.traffic_fetch", "@every 1m")
 	v.SetDefault("scheduler.email_notify", "@every 1m")
 	v.SetDefault("scheduler.telegram_notify", "@every 1m")
+	v.SetDefault("queue.traffic_capacity", 100000)
+	v.SetDefault("rate_limit.backend", "memory")
+	v.SetDefault("rate_limit.redis.key_prefix", "xboard:ratelimit:")
+	v.SetDefault("rate_limit.redis.timeout", "500ms")
 }
 
 func configuredDir(configPath string) string {
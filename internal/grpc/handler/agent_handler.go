@@ -11,9 +11,12 @@ import (
 	"strings"
 	"time"
 
+	"github.com/creamcroissant/xboard/internal/async"
 	"github.com/creamcroissant/xboard/internal/grpc/interceptor"
 	"github.com/creamcroissant/xboard/internal/repository"
 	"github.com/creamcroissant/xboard/internal/service"
+	"github.com/creamcroissant/xboard/internal/support/security"
+	"github.com/creamcroissant/xboard/internal/template"
 	agentv1 "github.com/creamcroissant/xboard/pkg/pb/agent/v1"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -29,7 +32,6 @@ type AgentHandler struct {
 	telemetryService    service.ServerTelemetryService
 	serverNodeService   service.ServerNodeService
 	userTrafficService  service.UserTrafficService
-	trafficDedupRepo    repository.TrafficReportDedupRepository
 	forwardingService   service.ForwardingService
 	accessLogService    service.AccessLogService
 	settingsService     service.AdminSystemSettingsService
@@ -41,8 +43,13 @@ type AgentHandler struct {
 	lifecycleOperations service.AgentLifecycleOperationService
 	trafficLifecycle    service.AgentTrafficLifecycleService
 	binaryVersions      service.BinaryVersionService
+	onlineDevices       service.OnlineDeviceService
+	trafficQueue        *async.TrafficQueue
+	userSnapshots       *agentUserSnapshots
 	logger              *slog.Logger
 	timeNow             func() time.Time
+	configSigningKeyID  string
+	configSigningSecret string
 }
 
 // NewAgentHandler 创建 Agent gRPC 处理器。
@@ -52,7 +59,6 @@ func NewAgentHandler(
 	telemetryService service.ServerTelemetryService,
 	serverNodeService service.ServerNodeService,
 	userTrafficService service.UserTrafficService,
-	trafficDedupRepo repository.TrafficReportDedupRepository,
 	forwardingService service.ForwardingService,
 	accessLogService service.AccessLogService,
 	settingsService service.AdminSystemSettingsService,
@@ -66,7 +72,6 @@ func NewAgentHandler(
 		telemetryService,
 		serverNodeService,
 		userTrafficService,
-		trafficDedupRepo,
 		forwardingService,
 		accessLogService,
 		settingsService,
@@ -78,6 +83,8 @@ func NewAgentHandler(
 		nil,
 		nil,
 		nil,
+		nil,
+		nil,
 		logger,
 	)
 }
@@ -89,7 +96,6 @@ func NewAgentHandlerWithCoreServices(
 	telemetryService service.ServerTelemetryService,
 	serverNodeService service.ServerNodeService,
 	userTrafficService service.UserTrafficService,
-	trafficDedupRepo repository.TrafficReportDedupRepository,
 	forwardingService service.ForwardingService,
 	accessLogService service.AccessLogService,
 	settingsService service.AdminSystemSettingsService,
@@ -101,6 +107,8 @@ func NewAgentHandlerWithCoreServices(
 	lifecycleOperations service.AgentLifecycleOperationService,
 	trafficLifecycle service.AgentTrafficLifecycleService,
 	binaryVersions service.BinaryVersionService,
+	onlineDevices service.OnlineDeviceService,
+	trafficQueue *async.TrafficQueue,
 	logger *slog.Logger,
 ) *AgentHandler {
 	return &AgentHandler{
@@ -109,7 +117,6 @@ func NewAgentHandlerWithCoreServices(
 		telemetryService:    telemetryService,
 		serverNodeService:   serverNodeService,
 		userTrafficService:  userTrafficService,
-		trafficDedupRepo:    trafficDedupRepo,
 		forwardingService:   forwardingService,
 		accessLogService:    accessLogService,
 		settingsService:     settingsService,
@@ -121,6 +128,9 @@ func NewAgentHandlerWithCoreServices(
 		lifecycleOperations: lifecycleOperations,
 		trafficLifecycle:    trafficLifecycle,
 		binaryVersions:      binaryVersions,
+		onlineDevices:       onlineDevices,
+		trafficQueue:        trafficQueue,
+		userSnapshots:       newAgentUserSnapshots(),
 		logger:              logger,
 		timeNow:             time.Now,
 	}
@@ -163,7 +173,7 @@ func (h *AgentHandler) ReportStatus(ctx context.Context, req *agentv1.StatusRepo
 	if len(req.Protocols) > 0 {
 		protocols := make([]service.ProtocolInfo, len(req.Protocols))
 		for i, p := range req.Protocols {
-			protocols[i] = service.ProtocolInfo{Name: p.Name, Type: p.Type, Running: p.Running, Details: convertProtocolDetails(p.Details)}
+			protocols[i] = service.ProtocolInfo{Name: p.Name, Type: p.Type, Running: p.Running, ContentHash: p.ContentHash, Details: convertProtocolDetails(p.Details)}
 		}
 		if err := h.agentHostService.UpdateProtocols(ctx, agentHost.Token, protocols); err != nil {
 			h.logger.Error("failed to update protocols", "agent_host_id", agentHost.ID, "error", err)
@@ -192,6 +202,12 @@ func (h *AgentHandler) ReportStatus(ctx context.Context, req *agentv1.StatusRepo
 	}
 
 	h.ingestInventoryReport(ctx, agentHost, req.GetTimestamp(), req.Inventory, req.InboundIndex, "unary")
+	h.recordAliveDevices(ctx, agentHost.ID, req.GetAliveDevices(), "unary")
+	h.recordReloadDrain(ctx, agentHost.ID, req.GetReloadDrain(), "unary")
+	h.recordCoreVersionChange(ctx, agentHost.ID, req.GetCoreVersionChange(), "unary")
+	h.recordDiagnostics(ctx, agentHost.ID, req.GetDiagnostics(), "unary")
+	h.recordPathUsage(ctx, agentHost.ID, req.GetSystem(), "unary")
+	h.recordProbeLatency(ctx, agentHost.ID, req.GetSystem(), "unary")
 
 	var syncInterval, reportInterval int
 	if h.settingsService != nil {
@@ -202,9 +218,22 @@ func (h *AgentHandler) ReportStatus(ctx context.Context, req *agentv1.StatusRepo
 			reportInterval, _ = strconv.Atoi(val)
 		}
 	}
+	// 节点级覆盖优先于全局设置
+	if agentHost.SyncIntervalSeconds > 0 {
+		syncInterval = agentHost.SyncIntervalSeconds
+	}
+	if agentHost.ReportIntervalSeconds > 0 {
+		reportInterval = agentHost.ReportIntervalSeconds
+	}
 	return &agentv1.StatusResponse{Success: true, Message: "status updated", SyncIntervalSeconds: int32(syncInterval), ReportIntervalSeconds: int32(reportInterval)}, nil
 }
 
+// SetConfigSigning 启用下发配置的 HMAC 签名；secret 为空时关闭签名，Agent 按自身配置决定是否强制校验。
+func (h *AgentHandler) SetConfigSigning(keyID, secret string) {
+	h.configSigningKeyID = strings.TrimSpace(keyID)
+	h.configSigningSecret = secret
+}
+
 // GetConfig 为 Agent 获取节点配置。
 func (h *AgentHandler) GetConfig(ctx context.Context, req *agentv1.ConfigRequest) (*agentv1.ConfigResponse, error) {
 	agentHost, ok := interceptor.GetAgentHostFromContext(ctx)
@@ -213,6 +242,12 @@ func (h *AgentHandler) GetConfig(ctx context.Context, req *agentv1.ConfigRequest
 	}
 	configJSON, err := h.agentHostService.GenerateConfig(ctx, agentHost.ID)
 	if err != nil {
+		// 配置校验失败时把结构化问题带回给 Agent，便于定位具体字段
+		var validationErr *service.ConfigValidationError
+		if errors.As(err, &validationErr) {
+			h.logger.Warn("generated config failed validation", "agent_host_id", agentHost.ID, "issues", len(validationErr.Issues))
+			return &agentv1.ConfigResponse{Success: false, ValidationIssues: convertValidationIssues(validationErr.Issues)}, nil
+		}
 		h.logger.Error("failed to generate config", "agent_host_id", agentHost.ID, "error", err)
 		return nil, status.Error(codes.Internal, "failed to generate config")
 	}
@@ -223,7 +258,37 @@ func (h *AgentHandler) GetConfig(ctx context.Context, req *agentv1.ConfigRequest
 	if req.Etag == newETag {
 		return &agentv1.ConfigResponse{NotModified: true, Etag: newETag}, nil
 	}
-	return &agentv1.ConfigResponse{Success: true, ConfigJson: configJSON, Version: 1, Etag: newETag}, nil
+	// Version 为签发时间，签名覆盖节点 ID、ETag 与版本，Agent 据此拒绝旧配置或其他节点配置的重放
+	resp := &agentv1.ConfigResponse{Success: true, ConfigJson: configJSON, Version: h.timeNow().UnixMilli(), Etag: newETag, AgentHostId: agentHost.ID}
+	if h.configSigningSecret != "" {
+		message := security.ConfigSignatureMessage(security.ConfigSignatureScopeConfig, agentHost.ID, configJSON, resp.Etag, strconv.FormatInt(resp.Version, 10))
+		resp.Signature = security.SignConfig(h.configSigningSecret, message)
+		resp.KeyId = h.configSigningKeyID
+	}
+	return resp, nil
+}
+
+// signCoreOperation 为 core operation 附上签名，覆盖操作 ID、节点、类型与请求载荷（其中可能含完整核心配置）。
+func (h *AgentHandler) signCoreOperation(op *agentv1.CoreOperation) {
+	if op == nil || h.configSigningSecret == "" {
+		return
+	}
+	message := security.ConfigSignatureMessage(security.ConfigSignatureScopeCoreOperation, op.AgentHostId, op.RequestPayload, op.Id, op.OperationType, op.CoreType)
+	op.Signature = security.SignConfig(h.configSigningSecret, message)
+	op.KeyId = h.configSigningKeyID
+}
+
+// convertValidationIssues 将模板校验问题转换为 protobuf 结构。
+func convertValidationIssues(issues []template.ValidationIssue) []*agentv1.ConfigValidationIssue {
+	result := make([]*agentv1.ConfigValidationIssue, 0, len(issues))
+	for _, issue := range issues {
+		result = append(result, &agentv1.ConfigValidationIssue{
+			Path:     issue.Path,
+			Message:  issue.Message,
+			Severity: issue.Severity,
+		})
+	}
+	return result
 }
 
 // GetUsers 为 Agent 获取用户列表。
@@ -232,6 +297,10 @@ func (h *AgentHandler) GetUsers(ctx context.Context, req *agentv1.UsersRequest)
 	if !ok {
 		return nil, status.Error(codes.Unauthenticated, "no agent host in context")
 	}
+	if agentHost.Maintenance {
+		// 维护模式下不注入用户，Agent 保持现有用户集合
+		return &agentv1.UsersResponse{Success: true, NotModified: true, Etag: req.GetEtag(), Version: 1}, nil
+	}
 	users, err := h.agentService.GetUsersForAgent(ctx, agentHost.ID)
 	if err != nil {
 		h.logger.Error("failed to get users for agent", "agent_host_id", agentHost.ID, "error", err)
@@ -249,7 +318,19 @@ func (h *AgentHandler) GetUsers(ctx context.Context, req *agentv1.UsersRequest)
 		}
 		pbUsers[i] = &agentv1.UserInfo{UserId: int64(u.ID), Uuid: u.UUID, Email: u.Email, Enabled: true, SpeedLimit: speedLimit, DeviceLimit: deviceLimit}
 	}
-	return &agentv1.UsersResponse{Success: true, Users: pbUsers, Version: 1}, nil
+	etag := userSetETag(pbUsers)
+	if !req.GetFull() && req.GetEtag() == etag {
+		return &agentv1.UsersResponse{Success: true, NotModified: true, Etag: etag, Version: 1}, nil
+	}
+	current := indexUsersByID(pbUsers)
+	previous, ok := h.userSnapshots.lookup(agentHost.ID, req.GetEtag())
+	h.userSnapshots.store(agentHost.ID, etag, current)
+	if req.GetFull() || !ok {
+		// 无可用快照（首次启动、面板重启或 Agent 主动要求）时下发全量
+		return &agentv1.UsersResponse{Success: true, Users: pbUsers, Etag: etag, Version: 1}, nil
+	}
+	added, removed, changed := diffUsers(previous, current)
+	return &agentv1.UsersResponse{Success: true, Etag: etag, Version: 1, Incremental: true, Added: added, RemovedUserIds: removed, Changed: changed}, nil
 }
 
 // ReportTraffic 处理用户维度流量上报。
@@ -258,24 +339,19 @@ func (h *AgentHandler) ReportTraffic(ctx context.Context, req *agentv1.TrafficRe
 	if !ok {
 		return nil, status.Error(codes.Unauthenticated, "no agent host in context")
 	}
-	reportID := strings.TrimSpace(req.GetReportId())
-	if reportID != "" && h.trafficDedupRepo != nil {
-		handledAt := h.timeNow().Unix()
-		inserted, err := h.trafficDedupRepo.MarkHandled(ctx, agentHost.ID, reportID, handledAt)
-		if err != nil {
-			h.logger.Error("failed to mark traffic report dedup", "agent_host_id", agentHost.ID, "report_id", reportID, "error", err)
-			return nil, status.Error(codes.Internal, "failed to process traffic batch")
-		}
-		if !inserted {
-			h.logger.Info("traffic report deduplicated",
-				"agent_host_id", agentHost.ID,
-				"report_id", reportID,
-				"dedup_hit", true,
-				"accepted", 0,
-				"skipped", len(req.UserTraffic),
-			)
-			return &agentv1.TrafficResponse{Success: true, AcceptedCount: 0, Message: "traffic accepted (deduplicated)"}, nil
-		}
+	// 同步写入占用流量队列容量，接近满载时让 Agent 退避重试；需在去重标记之前判断，否则重试会被当作重复上报
+	reserved := len(req.UserTraffic)
+	if !h.trafficQueue.Reserve(reserved) {
+		stats := h.trafficQueue.Stats()
+		h.logger.Warn("traffic queue near capacity, rejecting report", "agent_host_id", agentHost.ID, "pending", stats.Pending, "capacity", stats.Capacity)
+		return nil, status.Error(codes.ResourceExhausted, "traffic queue near capacity, retry later")
+	}
+	persisted := false
+	defer func() { h.trafficQueue.Release(reserved, persisted) }()
+	// batch_id 是幂等键，旧版 Agent 只带 report_id
+	batchID := strings.TrimSpace(req.GetBatchId())
+	if batchID == "" {
+		batchID = strings.TrimSpace(req.GetReportId())
 	}
 	traffic := make([]service.UserTrafficDelta, 0, len(req.UserTraffic))
 	skipped := 0
@@ -290,28 +366,259 @@ func (h *AgentHandler) ReportTraffic(ctx context.Context, req *agentv1.TrafficRe
 			skipped++
 			continue
 		}
-		traffic = append(traffic, service.UserTrafficDelta{UserID: u.UserId, Upload: upload, Download: download})
+		traffic = append(traffic, service.UserTrafficDelta{UserID: u.UserId, Upload: upload, Download: download, ServerID: u.ServerId})
 	}
 	acceptedCount := int32(len(traffic))
+	var exceededUserIDs, warningUserIDs []int64
+	var rawBytes, chargedBytes int64
 	if h.userTrafficService != nil && len(traffic) > 0 {
-		result, err := h.userTrafficService.ProcessTrafficBatch(ctx, agentHost.ID, traffic)
+		// 去重标记与入账在同一事务内提交：失败时两者都不落库，Agent 以相同 batch_id 重试即可
+		result, err := h.userTrafficService.ProcessTrafficBatch(ctx, agentHost.ID, batchID, traffic)
 		if err != nil {
-			h.logger.Error("failed to process traffic batch", "agent_host_id", agentHost.ID, "error", err)
+			h.logger.Error("failed to process traffic batch", "agent_host_id", agentHost.ID, "batch_id", batchID, "error", err)
 			return nil, status.Error(codes.Internal, "failed to process traffic batch")
 		}
+		if result != nil && result.Deduplicated {
+			// 已入账的批次（面板处理成功但响应丢失后的重试）直接确认，不再重复计费
+			h.logger.Info("traffic report deduplicated",
+				"agent_host_id", agentHost.ID,
+				"batch_id", batchID,
+				"dedup_hit", true,
+				"accepted", 0,
+				"skipped", len(req.UserTraffic),
+			)
+			return &agentv1.TrafficResponse{Success: true, AcceptedCount: 0, Message: "traffic accepted (deduplicated)"}, nil
+		}
 		if result != nil {
 			acceptedCount = result.AcceptedCount
+			exceededUserIDs = result.ExceededUserIDs
+			warningUserIDs = result.WarningUserIDs
+			rawBytes = result.RawBytes
+			chargedBytes = result.ChargedBytes
 		}
 	}
+	persisted = true
 	h.logger.Debug("traffic report processed",
 		"agent_host_id", agentHost.ID,
-		"report_id", reportID,
+		"batch_id", batchID,
 		"dedup_hit", false,
 		"received", len(req.UserTraffic),
 		"accepted", acceptedCount,
 		"skipped", skipped,
+		"raw_bytes", rawBytes,
+		"charged_bytes", chargedBytes,
+	)
+	userIDs := make([]int64, 0, len(traffic))
+	for _, delta := range traffic {
+		userIDs = append(userIDs, delta.UserID)
+	}
+	return &agentv1.TrafficResponse{
+		Success:          true,
+		AcceptedCount:    acceptedCount,
+		Message:          "traffic accepted",
+		OverLimitUserIds: h.overLimitUserIDs(ctx, agentHost.ID, userIDs),
+		ExceededUserIds:  exceededUserIDs,
+		WarningUserIds:   warningUserIDs,
+	}, nil
+}
+
+// ReportAlive 处理在线设备上报，返回超出设备数限制的用户供 Agent 断开多余连接。
+func (h *AgentHandler) ReportAlive(ctx context.Context, req *agentv1.AliveReport) (*agentv1.AliveResponse, error) {
+	agentHost, ok := interceptor.GetAgentHostFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "no agent host in context")
+	}
+	if h.onlineDevices == nil {
+		return &agentv1.AliveResponse{Success: true}, nil
+	}
+	overLimit, err := h.onlineDevices.RecordAlive(ctx, agentHost.ID, convertAliveDevices(req.GetDevices()))
+	if err != nil {
+		h.logger.Error("failed to record alive devices", "agent_host_id", agentHost.ID, "error", err)
+		return nil, status.Error(codes.Internal, "failed to record alive devices")
+	}
+	return &agentv1.AliveResponse{
+		Success:                   true,
+		OverLimitUserIds:          overLimit,
+		DeviceOnlineWindowSeconds: h.onlineDevices.WindowSeconds(ctx),
+	}, nil
+}
+
+// recordAliveDevices 记录状态上报中附带的在线设备，失败只记日志不影响主流程。
+func (h *AgentHandler) recordAliveDevices(ctx context.Context, agentHostID int64, devices []*agentv1.AliveDevice, source string) {
+	if h.onlineDevices == nil || len(devices) == 0 {
+		return
+	}
+	if _, err := h.onlineDevices.RecordAlive(ctx, agentHostID, convertAliveDevices(devices)); err != nil {
+		h.logger.Warn("failed to record alive devices", "source", source, "agent_host_id", agentHostID, "error", err)
+	}
+}
+
+// recordReloadDrain 将 Agent 上报的配置重载排空结果写入操作日志，失败只记日志。
+func (h *AgentHandler) recordReloadDrain(ctx context.Context, agentHostID int64, drain *agentv1.ReloadDrainResult, source string) {
+	if drain == nil {
+		return
+	}
+	h.logger.Info("agent config reload drained",
+		"source", source,
+		"agent_host_id", agentHostID,
+		"mode", drain.GetMode(),
+		"connections_before", drain.GetConnectionsBefore(),
+		"drained", drain.GetDrained(),
+		"remaining", drain.GetRemaining(),
 	)
-	return &agentv1.TrafficResponse{Success: true, AcceptedCount: acceptedCount, Message: "traffic accepted"}, nil
+	if h.operationLogs == nil {
+		return
+	}
+	level := service.OperationLogLevelInfo
+	if drain.GetRemaining() > 0 {
+		level = service.OperationLogLevelWarn
+	}
+	message := fmt.Sprintf("config reload (%s) drained %d of %d connections", drain.GetMode(), drain.GetDrained(), drain.GetConnectionsBefore())
+	if drain.GetMode() == "reload" {
+		// 原地重载不中断连接，没有排空数据
+		message = fmt.Sprintf("config reloaded in place with %d active connections", drain.GetConnectionsBefore())
+	}
+	payload, _ := json.Marshal(map[string]any{
+		"mode":               drain.GetMode(),
+		"connections_before": drain.GetConnectionsBefore(),
+		"drained":            drain.GetDrained(),
+		"remaining":          drain.GetRemaining(),
+	})
+	if _, err := h.operationLogs.Append(ctx, service.AppendOperationLogRequest{
+		Scope:       service.OperationLogScopeConfigSync,
+		TargetID:    "reload",
+		AgentHostID: agentHostID,
+		Phase:       "reload_drained",
+		Level:       level,
+		Message:     message,
+		Payload:     payload,
+		ReportedAt:  drain.GetFinishedAt(),
+	}); err != nil {
+		h.logger.Warn("failed to record reload drain", "source", source, "agent_host_id", agentHostID, "error", err)
+	}
+}
+
+// recordCoreVersionChange 将 Agent 检测到的核心版本变更写入操作日志，失败只记日志。
+func (h *AgentHandler) recordCoreVersionChange(ctx context.Context, agentHostID int64, change *agentv1.CoreVersionChange, source string) {
+	if change == nil {
+		return
+	}
+	h.logger.Info("agent core version changed",
+		"source", source,
+		"agent_host_id", agentHostID,
+		"reason", change.GetReason(),
+		"previous_core_type", change.GetPreviousCoreType(),
+		"previous_version", change.GetPreviousVersion(),
+		"core_type", change.GetCoreType(),
+		"version", change.GetVersion(),
+	)
+	if h.operationLogs == nil {
+		return
+	}
+	payload, _ := json.Marshal(map[string]any{
+		"previous_core_type": change.GetPreviousCoreType(),
+		"previous_version":   change.GetPreviousVersion(),
+		"core_type":          change.GetCoreType(),
+		"version":            change.GetVersion(),
+		"reason":             change.GetReason(),
+	})
+	if _, err := h.operationLogs.Append(ctx, service.AppendOperationLogRequest{
+		Scope:       service.OperationLogScopeCoreCapability,
+		TargetID:    "capabilities",
+		AgentHostID: agentHostID,
+		Phase:       "core_version_changed",
+		Level:       service.OperationLogLevelInfo,
+		Message:     fmt.Sprintf("core changed from %s %s to %s %s", change.GetPreviousCoreType(), change.GetPreviousVersion(), change.GetCoreType(), change.GetVersion()),
+		Payload:     payload,
+		ReportedAt:  change.GetDetectedAt(),
+	}); err != nil {
+		h.logger.Warn("failed to record core version change", "source", source, "agent_host_id", agentHostID, "error", err)
+	}
+}
+
+// recordDiagnostics 保存 Agent 上报的自诊断包，失败只记日志。
+func (h *AgentHandler) recordDiagnostics(ctx context.Context, agentHostID int64, diag *agentv1.AgentDiagnostics, source string) {
+	if diag == nil || h.agentHostService == nil {
+		return
+	}
+	if err := h.agentHostService.RecordDiagnostics(ctx, agentHostID, &service.AgentDiagnostics{
+		CoreType:              diag.GetCoreType(),
+		CoreVersion:           diag.GetCoreVersion(),
+		AgentVersion:          diag.GetAgentVersion(),
+		InitSystem:            diag.GetInitSystem(),
+		ConfigValid:           diag.GetConfigValid(),
+		ConfigValidationError: diag.GetConfigValidationError(),
+		LastApplyError:        diag.GetLastApplyError(),
+		LastApplyErrorAt:      diag.GetLastApplyErrorAt(),
+		ConfigDir:             diag.GetConfigDir(),
+		ConfigDirFreeBytes:    diag.GetConfigDirFreeBytes(),
+		ConfigDirTotalBytes:   diag.GetConfigDirTotalBytes(),
+		OpenFDs:               diag.GetOpenFds(),
+		MaxFDs:                diag.GetMaxFds(),
+		CollectedAt:           diag.GetCollectedAt(),
+	}); err != nil {
+		h.logger.Warn("failed to record agent diagnostics", "source", source, "agent_host_id", agentHostID, "error", err)
+	}
+}
+
+// recordPathUsage 保存 Agent 上报的监控目录磁盘占用并触发阈值告警，失败只记日志。
+// 未携带系统指标的上报不处理，避免误删已有记录。
+func (h *AgentHandler) recordPathUsage(ctx context.Context, agentHostID int64, system *agentv1.SystemMetrics, source string) {
+	if system == nil || h.agentHostService == nil {
+		return
+	}
+	usages := make([]service.AgentPathUsage, 0, len(system.GetPathUsage()))
+	for _, item := range system.GetPathUsage() {
+		if item == nil {
+			continue
+		}
+		usages = append(usages, service.AgentPathUsage{
+			Path:         item.GetPath(),
+			Exists:       item.GetExists(),
+			UsedBytes:    item.GetUsedBytes(),
+			Truncated:    item.GetTruncated(),
+			FSTotalBytes: item.GetFsTotalBytes(),
+			FSUsedBytes:  item.GetFsUsedBytes(),
+			FSFreeBytes:  item.GetFsFreeBytes(),
+		})
+	}
+	if err := h.agentHostService.RecordPathUsage(ctx, agentHostID, usages); err != nil {
+		h.logger.Warn("failed to record agent path usage", "source", source, "agent_host_id", agentHostID, "error", err)
+	}
+}
+
+// recordProbeLatency 把 Agent 探测到的延迟记录到该主机下的节点，未探测时不处理，失败只记日志。
+func (h *AgentHandler) recordProbeLatency(ctx context.Context, agentHostID int64, system *agentv1.SystemMetrics, source string) {
+	if h.telemetryService == nil || system.GetProbeLatencyMs() <= 0 {
+		return
+	}
+	if err := h.telemetryService.RecordHostLatency(ctx, agentHostID, int64(system.GetProbeLatencyMs())); err != nil {
+		h.logger.Warn("failed to record agent probe latency", "source", source, "agent_host_id", agentHostID, "error", err)
+	}
+}
+
+// overLimitUserIDs 查询本次流量涉及用户中已超出设备数限制的用户，失败时返回空列表。
+func (h *AgentHandler) overLimitUserIDs(ctx context.Context, agentHostID int64, userIDs []int64) []int64 {
+	if h.onlineDevices == nil || len(userIDs) == 0 {
+		return nil
+	}
+	overLimit, err := h.onlineDevices.OverLimitUserIDs(ctx, userIDs)
+	if err != nil {
+		h.logger.Warn("failed to check device limit", "agent_host_id", agentHostID, "error", err)
+		return nil
+	}
+	return overLimit
+}
+
+func convertAliveDevices(devices []*agentv1.AliveDevice) []service.OnlineDeviceEntry {
+	entries := make([]service.OnlineDeviceEntry, 0, len(devices))
+	for _, device := range devices {
+		if device == nil {
+			continue
+		}
+		entries = append(entries, service.OnlineDeviceEntry{UserID: device.GetUserId(), IP: device.GetIp()})
+	}
+	return entries
 }
 
 // ReportForwardingStatus 处理转发规则应用结果上报。
@@ -326,6 +633,17 @@ func (h *AgentHandler) ReportForwardingStatus(ctx context.Context, req *agentv1.
 	if req == nil {
 		return nil, status.Error(codes.InvalidArgument, "missing request")
 	}
+	if len(req.GetTargetHealth()) > 0 {
+		// 健康状态变化上报，不是一次规则应用结果
+		reports := make([]service.ForwardingTargetHealthReport, 0, len(req.GetTargetHealth()))
+		for _, item := range req.GetTargetHealth() {
+			reports = append(reports, service.ForwardingTargetHealthReport{RuleID: item.GetRuleId(), Health: repository.ForwardingTargetHealth{Address: item.GetAddress(), Port: int(item.GetPort()), Healthy: item.GetHealthy(), Error: item.GetError(), CheckedAt: item.GetCheckedAt()}})
+		}
+		if err := h.forwardingService.RecordTargetHealth(ctx, agentHost.ID, reports); err != nil {
+			return nil, status.Error(codes.Internal, "failed to record forwarding target health")
+		}
+		return &agentv1.StatusResponse{Success: true}, nil
+	}
 	detailPayload := map[string]any{"version": req.Version, "applied_at": req.AppliedAt, "success": req.Success, "error_message": req.ErrorMessage}
 	payload, err := json.Marshal(detailPayload)
 	if err != nil {
@@ -359,11 +677,23 @@ func (h *AgentHandler) GetForwardingRules(ctx context.Context, req *agentv1.Forw
 	}
 	pbRules := make([]*agentv1.ForwardingRule, 0, len(rules))
 	for _, rule := range rules {
-		pbRules = append(pbRules, &agentv1.ForwardingRule{Id: rule.ID, Name: rule.Name, Protocol: rule.Protocol, ListenPort: int32(rule.ListenPort), TargetAddress: rule.TargetAddress, TargetPort: int32(rule.TargetPort), Priority: int32(rule.Priority), Enabled: rule.Enabled})
+		pbRules = append(pbRules, &agentv1.ForwardingRule{Id: rule.ID, Name: rule.Name, Protocol: rule.Protocol, ListenPort: int32(rule.ListenPort), TargetAddress: rule.TargetAddress, TargetPort: int32(rule.TargetPort), Priority: int32(rule.Priority), Enabled: rule.Enabled, Targets: convertForwardingTargets(rule.Targets), HealthCheckInterval: int32(rule.HealthCheckInterval), HealthCheckTimeout: int32(rule.HealthCheckTimeout)})
 	}
 	return &agentv1.ForwardingRulesResponse{Success: true, Rules: pbRules, Version: currentVersion}, nil
 }
 
+// convertForwardingTargets 转换多目标列表；单目标规则返回 nil，Agent 回退到 target_address/target_port。
+func convertForwardingTargets(targets []repository.ForwardingTarget) []*agentv1.ForwardingTarget {
+	if len(targets) == 0 {
+		return nil
+	}
+	result := make([]*agentv1.ForwardingTarget, 0, len(targets))
+	for _, target := range targets {
+		result = append(result, &agentv1.ForwardingTarget{Address: target.Address, Port: int32(target.Port), Weight: int32(target.Weight)})
+	}
+	return result
+}
+
 // GetCoreOperations 为 Agent 拉取待执行的 core operation。
 func (h *AgentHandler) GetCoreOperations(ctx context.Context, req *agentv1.GetCoreOperationsRequest) (*agentv1.GetCoreOperationsResponse, error) {
 	agentHost, err := getAgentHost(ctx)
@@ -389,7 +719,9 @@ func (h *AgentHandler) GetCoreOperations(ctx context.Context, req *agentv1.GetCo
 			}
 			return nil, mapCoreOperationGRPCError(err)
 		}
-		operations = append(operations, convertCoreOperation(op))
+		pbOperation := convertCoreOperation(op)
+		h.signCoreOperation(pbOperation)
+		operations = append(operations, pbOperation)
 	}
 	return &agentv1.GetCoreOperationsResponse{Success: true, Operations: operations}, nil
 }
@@ -546,6 +878,12 @@ func (h *AgentHandler) validateOperationEventOwnership(ctx context.Context, agen
 			return status.Error(codes.InvalidArgument, service.ErrOperationLogInvalidRequest.Error())
 		}
 		return nil
+	case service.OperationLogScopeConfigSync:
+		// 目标为 Agent 自身拉取到的配置 ETag，日志按 agentHostID 归属，无需额外校验
+		if strings.TrimSpace(targetID) == "" {
+			return status.Error(codes.InvalidArgument, service.ErrOperationLogInvalidRequest.Error())
+		}
+		return nil
 	default:
 		return status.Error(codes.InvalidArgument, service.ErrOperationLogInvalidRequest.Error())
 	}
@@ -637,6 +975,10 @@ func (h *AgentHandler) StatusStream(stream grpc.BidiStreamingServer[agentv1.Stat
 	if !ok {
 		return status.Error(codes.Unauthenticated, "no agent host in context")
 	}
+	// 独立 goroutine 负责下发指令，保证 stream.Send 只在一个 goroutine 中调用
+	commands, unsubscribe := h.agentHostService.SubscribeStreamCommands(agentHost.ID, agentHost.Token)
+	defer unsubscribe()
+	go h.forwardStreamCommands(stream, agentHost.ID, commands)
 	for {
 		select {
 		case <-ctx.Done():
@@ -661,7 +1003,7 @@ func (h *AgentHandler) StatusStream(stream grpc.BidiStreamingServer[agentv1.Stat
 		if len(report.Protocols) > 0 {
 			protocols := make([]service.ProtocolInfo, len(report.Protocols))
 			for i, p := range report.Protocols {
-				protocols[i] = service.ProtocolInfo{Name: p.Name, Type: p.Type, Running: p.Running, Details: convertProtocolDetails(p.Details)}
+				protocols[i] = service.ProtocolInfo{Name: p.Name, Type: p.Type, Running: p.Running, ContentHash: p.ContentHash, Details: convertProtocolDetails(p.Details)}
 			}
 			if err := h.agentHostService.UpdateProtocols(ctx, agentHost.Token, protocols); err != nil {
 				h.logger.Error("failed to update protocols from stream", "agent_host_id", agentHost.ID, "error", err)
@@ -681,6 +1023,32 @@ func (h *AgentHandler) StatusStream(stream grpc.BidiStreamingServer[agentv1.Stat
 			}
 		}
 		h.ingestInventoryReport(ctx, agentHost, report.GetTimestamp(), report.Inventory, report.InboundIndex, "stream")
+		h.recordAliveDevices(ctx, agentHost.ID, report.GetAliveDevices(), "stream")
+		h.recordReloadDrain(ctx, agentHost.ID, report.GetReloadDrain(), "stream")
+		h.recordCoreVersionChange(ctx, agentHost.ID, report.GetCoreVersionChange(), "stream")
+		h.recordDiagnostics(ctx, agentHost.ID, report.GetDiagnostics(), "stream")
+		h.recordPathUsage(ctx, agentHost.ID, report.GetSystem(), "stream")
+		h.recordProbeLatency(ctx, agentHost.ID, report.GetSystem(), "stream")
+	}
+}
+
+// forwardStreamCommands 将面板侧排队的指令通过 StatusStream 推送给 Agent，订阅关闭或流结束时退出。
+func (h *AgentHandler) forwardStreamCommands(stream grpc.BidiStreamingServer[agentv1.StatusReport, agentv1.StatusCommand], agentHostID int64, commands <-chan service.AgentStreamCommand) {
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case cmd, ok := <-commands:
+			if !ok {
+				return
+			}
+			if err := stream.Send(&agentv1.StatusCommand{Command: cmd.Command, Payload: cmd.Payload}); err != nil {
+				h.logger.Warn("failed to send stream command", "agent_host_id", agentHostID, "command", cmd.Command, "error", err)
+				return
+			}
+			h.logger.Info("stream command sent", "agent_host_id", agentHostID, "command", cmd.Command, "request_id", cmd.RequestID)
+		}
 	}
 }
 
@@ -752,6 +1120,8 @@ func buildAgentHostMetricsReport(report *agentv1.StatusReport) service.AgentHost
 		metrics.BootID = systemMetrics.GetBootId()
 		metrics.AgentVersion = systemMetrics.GetAgentVersion()
 		metrics.CurrentCoreType = systemMetrics.GetCurrentCoreType()
+		metrics.ConnectionCount = int64(systemMetrics.GetConnectionCount())
+		metrics.ConnectionCountSource = systemMetrics.GetConnectionCountSource()
 	}
 	if networkMetrics := report.GetNetwork(); networkMetrics != nil {
 		if uploadRate := networkMetrics.GetUploadRateBps(); uploadRate != nil {
@@ -910,12 +1280,12 @@ func convertProtocolDetails(pbDetails []*agentv1.ProtocolDetails) []service.Prot
 	}
 	details := make([]service.ProtocolDetails, len(pbDetails))
 	for i, pb := range pbDetails {
-		detail := service.ProtocolDetails{Protocol: pb.Protocol, Tag: pb.Tag, Listen: pb.Listen, Port: int(pb.Port), CoreType: pb.CoreType}
+		detail := service.ProtocolDetails{Protocol: pb.Protocol, Tag: pb.Tag, Listen: pb.Listen, Port: int(pb.Port), CoreType: pb.CoreType, Encryption: pb.Encryption}
 		if pb.Transport != nil {
 			detail.Transport = &service.TransportInfo{Type: pb.Transport.Type, Path: pb.Transport.Path, Host: pb.Transport.Host, ServiceName: pb.Transport.ServiceName}
 		}
 		if pb.Tls != nil {
-			detail.TLS = &service.TLSInfo{Enabled: pb.Tls.Enabled, ServerName: pb.Tls.ServerName, ALPN: pb.Tls.Alpn}
+			detail.TLS = &service.TLSInfo{Enabled: pb.Tls.Enabled, ServerName: pb.Tls.ServerName, ALPN: pb.Tls.Alpn, ECHEnabled: pb.Tls.EchEnabled}
 			if pb.Tls.Reality != nil {
 				detail.TLS.Reality = &service.RealityInfo{Enabled: pb.Tls.Reality.Enabled, ShortIDs: pb.Tls.Reality.ShortIds, ServerName: pb.Tls.Reality.ServerName, Fingerprint: pb.Tls.Reality.Fingerprint, HandshakeAddr: pb.Tls.Reality.HandshakeAddr, HandshakePort: int(pb.Tls.Reality.HandshakePort), PublicKey: pb.Tls.Reality.PublicKey}
 			}
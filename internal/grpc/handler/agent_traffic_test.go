@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/creamcroissant/xboard/internal/grpc/interceptor"
+	"github.com/creamcroissant/xboard/internal/repository"
+	"github.com/creamcroissant/xboard/internal/service"
+	agentv1 "github.com/creamcroissant/xboard/pkg/pb/agent/v1"
+)
+
+// countingTrafficService 只实现 ProcessTrafficBatch，记录实际入账的字节数；
+// 与仓储一样，去重键只在入账成功时与流量一起记录。
+type countingTrafficService struct {
+	service.UserTrafficService
+	failNext bool
+	applied  map[int64]int64
+	batches  map[string]struct{}
+	calls    int
+}
+
+func (s *countingTrafficService) ProcessTrafficBatch(_ context.Context, _ int64, batchID string, traffic []service.UserTrafficDelta) (*service.TrafficProcessResult, error) {
+	s.calls++
+	if s.failNext {
+		s.failNext = false
+		return nil, errors.New("database is locked")
+	}
+	if _, ok := s.batches[batchID]; ok {
+		return &service.TrafficProcessResult{Deduplicated: true}, nil
+	}
+	if s.applied == nil {
+		s.applied = make(map[int64]int64)
+		s.batches = make(map[string]struct{})
+	}
+	s.batches[batchID] = struct{}{}
+	for _, item := range traffic {
+		s.applied[item.UserID] += item.Upload + item.Download
+	}
+	return &service.TrafficProcessResult{AcceptedCount: int32(len(traffic))}, nil
+}
+
+func newTrafficTestHandler(traffic *countingTrafficService) *AgentHandler {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewAgentHandler(nil, nil, nil, nil, traffic, nil, nil, nil, nil, nil, logger)
+}
+
+func TestReportTrafficRetryAfterLostAckIsNotCountedTwice(t *testing.T) {
+	traffic := &countingTrafficService{}
+	h := newTrafficTestHandler(traffic)
+	ctx := context.WithValue(context.Background(), interceptor.AgentHostKey, &repository.AgentHost{ID: 7})
+	req := &agentv1.TrafficReport{
+		BatchId:     "batch-1",
+		UserTraffic: []*agentv1.UserTraffic{{UserId: 1, UploadBytes: 100, DownloadBytes: 200}},
+	}
+
+	// 第一次上报已入账，但响应在返回途中丢失，Agent 以相同 batch_id 重试
+	if _, err := h.ReportTraffic(ctx, req); err != nil {
+		t.Fatalf("first report: %v", err)
+	}
+	resp, err := h.ReportTraffic(ctx, req)
+	if err != nil {
+		t.Fatalf("retried report: %v", err)
+	}
+	if !resp.GetSuccess() || resp.GetAcceptedCount() != 0 {
+		t.Fatalf("retry response = %+v, want success acknowledged without re-applying", resp)
+	}
+	if traffic.calls != 2 || traffic.applied[1] != 300 {
+		t.Fatalf("calls = %d, applied = %d, want the batch applied exactly once", traffic.calls, traffic.applied[1])
+	}
+}
+
+func TestReportTrafficRetryAfterFailedApplyIsProcessed(t *testing.T) {
+	traffic := &countingTrafficService{failNext: true}
+	h := newTrafficTestHandler(traffic)
+	ctx := context.WithValue(context.Background(), interceptor.AgentHostKey, &repository.AgentHost{ID: 7})
+	req := &agentv1.TrafficReport{
+		ReportId:    "legacy-batch",
+		UserTraffic: []*agentv1.UserTraffic{{UserId: 1, UploadBytes: 50}},
+	}
+
+	if _, err := h.ReportTraffic(ctx, req); err == nil {
+		t.Fatal("first report should fail")
+	}
+	resp, err := h.ReportTraffic(ctx, req)
+	if err != nil || resp.GetAcceptedCount() != 1 {
+		t.Fatalf("retry resp = %+v, err = %v, want the batch applied", resp, err)
+	}
+	if traffic.applied[1] != 50 {
+		t.Fatalf("applied = %d, want 50", traffic.applied[1])
+	}
+}
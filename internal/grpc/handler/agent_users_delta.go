@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"crypto/md5"
+	"fmt"
+	"sort"
+	"sync"
+
+	agentv1 "github.com/creamcroissant/xboard/pkg/pb/agent/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// agentUserSnapshot 记录最近一次下发给某个 Agent 的用户集合及其 ETag。
+type agentUserSnapshot struct {
+	etag  string
+	users map[int64]*agentv1.UserInfo
+}
+
+// agentUserSnapshots 在内存中按 Agent 保存用户快照，用于计算增量；缺失时回退为全量下发。
+type agentUserSnapshots struct {
+	mu        sync.Mutex
+	snapshots map[int64]agentUserSnapshot
+}
+
+func newAgentUserSnapshots() *agentUserSnapshots {
+	return &agentUserSnapshots{snapshots: make(map[int64]agentUserSnapshot)}
+}
+
+// lookup 仅在 ETag 与快照一致时返回快照。
+func (s *agentUserSnapshots) lookup(agentHostID int64, etag string) (map[int64]*agentv1.UserInfo, bool) {
+	if etag == "" {
+		return nil, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snapshot, ok := s.snapshots[agentHostID]
+	if !ok || snapshot.etag != etag {
+		return nil, false
+	}
+	return snapshot.users, true
+}
+
+func (s *agentUserSnapshots) store(agentHostID int64, etag string, users map[int64]*agentv1.UserInfo) {
+	s.mu.Lock()
+	s.snapshots[agentHostID] = agentUserSnapshot{etag: etag, users: users}
+	s.mu.Unlock()
+}
+
+// userSetETag 按用户 ID 排序后计算内容哈希，保证同一用户集合得到稳定的 ETag。
+func userSetETag(users []*agentv1.UserInfo) string {
+	sorted := make([]*agentv1.UserInfo, len(users))
+	copy(sorted, users)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].GetUserId() < sorted[j].GetUserId() })
+	h := md5.New()
+	for _, u := range sorted {
+		fmt.Fprintf(h, "%d|%s|%s|%d|%d|%t\n", u.GetUserId(), u.GetUuid(), u.GetEmail(), u.GetSpeedLimit(), u.GetDeviceLimit(), u.GetEnabled())
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// indexUsersByID 构建用户 ID 到用户的映射。
+func indexUsersByID(users []*agentv1.UserInfo) map[int64]*agentv1.UserInfo {
+	index := make(map[int64]*agentv1.UserInfo, len(users))
+	for _, u := range users {
+		if u == nil {
+			continue
+		}
+		index[u.GetUserId()] = u
+	}
+	return index
+}
+
+// diffUsers 计算从 previous 到 current 的新增、删除与变更，结果按用户 ID 升序。
+func diffUsers(previous, current map[int64]*agentv1.UserInfo) (added []*agentv1.UserInfo, removed []int64, changed []*agentv1.UserInfo) {
+	for id, u := range current {
+		old, ok := previous[id]
+		if !ok {
+			added = append(added, u)
+			continue
+		}
+		if !proto.Equal(old, u) {
+			changed = append(changed, u)
+		}
+	}
+	for id := range previous {
+		if _, ok := current[id]; !ok {
+			removed = append(removed, id)
+		}
+	}
+	sort.Slice(added, func(i, j int) bool { return added[i].GetUserId() < added[j].GetUserId() })
+	sort.Slice(changed, func(i, j int) bool { return changed[i].GetUserId() < changed[j].GetUserId() })
+	sort.Slice(removed, func(i, j int) bool { return removed[i] < removed[j] })
+	return added, removed, changed
+}
@@ -1,14 +1,17 @@
 package grpc
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
 	"log/slog"
 	"net"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/creamcroissant/xboard/internal/grpc/interceptor"
+	"github.com/creamcroissant/xboard/internal/support/certreload"
 	agentv1 "github.com/creamcroissant/xboard/pkg/pb/agent/v1"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
@@ -16,9 +19,10 @@ import (
 
 // Server 封装 gRPC 服务端。
 type Server struct {
-	server  *grpc.Server
-	logger  *slog.Logger
-	address string
+	server     *grpc.Server
+	logger     *slog.Logger
+	address    string
+	stopReload context.CancelFunc
 }
 
 // Config 保存 gRPC 服务端配置。
@@ -32,6 +36,8 @@ type TLSConfig struct {
 	Enabled  bool
 	CertFile string
 	KeyFile  string
+	// ReloadInterval 为证书文件检查间隔，文件变化时热加载新证书；为 0 时使用 certreload.DefaultCheckInterval。
+	ReloadInterval time.Duration
 }
 
 // NewServer 创建 gRPC 服务端。
@@ -54,26 +60,31 @@ func NewServer(
 		),
 	}
 
-	// TLS 配置
+	// TLS 配置：证书通过回调读取，轮换后无需重启
+	stopReload := func() {}
 	if cfg.TLS != nil && cfg.TLS.Enabled {
-		cert, err := tls.LoadX509KeyPair(cfg.TLS.CertFile, cfg.TLS.KeyFile)
+		reloader, err := certreload.New(cfg.TLS.CertFile, cfg.TLS.KeyFile, logger)
 		if err != nil {
-			return nil, fmt.Errorf("load TLS certificate: %w", err)
+			return nil, err
 		}
 		tlsCfg := &tls.Config{
-			Certificates: []tls.Certificate{cert},
-			MinVersion:   tls.VersionTLS12,
+			GetCertificate: reloader.GetCertificate,
+			MinVersion:     tls.VersionTLS12,
 		}
 		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsCfg)))
+		var reloadCtx context.Context
+		reloadCtx, stopReload = context.WithCancel(context.Background())
+		go reloader.Watch(reloadCtx, cfg.TLS.ReloadInterval)
 	}
 
 	server := grpc.NewServer(opts...)
 	agentv1.RegisterAgentServiceServer(server, agentHandler)
 
 	return &Server{
-		server:  server,
-		logger:  logger,
-		address: cfg.Address,
+		server:     server,
+		logger:     logger,
+		address:    cfg.Address,
+		stopReload: stopReload,
 	}, nil
 }
 
@@ -116,6 +127,7 @@ func IsGRPCRequest(r *http.Request) bool {
 func (s *Server) Stop() {
 	s.logger.Info("gRPC server stopping")
 	s.server.GracefulStop()
+	s.stopReload()
 }
 
 // GracefulStop 是 Stop 的别名。
@@ -8,7 +8,8 @@ import (
 	"github.com/creamcroissant/xboard/internal/service"
 )
 
-// AccessLogCleanupJob handles cleanup of old access logs.
+// AccessLogCleanupJob deletes access logs older than the configured retention.
+// It runs whether or not logging is currently enabled, so previously collected logs still expire.
 type AccessLogCleanupJob struct {
 	AccessLogService service.AccessLogService
 	Logger           *slog.Logger
@@ -36,18 +37,11 @@ func (j *AccessLogCleanupJob) Run(ctx context.Context) error {
 		return fmt.Errorf("access log cleanup job dependencies not configured / 访问日志清理任务依赖未配置")
 	}
 
-	if !j.AccessLogService.IsEnabled(ctx) {
-		return nil
-	}
-
 	deleted, err := j.AccessLogService.CleanupOldLogs(ctx)
+	j.Logger.Info("cleaned up old access logs", "deleted_rows", deleted)
 	if err != nil {
 		return fmt.Errorf("access log cleanup job: %w", err)
 	}
 
-	if deleted > 0 {
-		j.Logger.Info("cleaned up old access logs", "deleted_rows", deleted)
-	}
-
 	return nil
 }
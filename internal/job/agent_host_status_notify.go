@@ -0,0 +1,26 @@
+package job
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/creamcroissant/xboard/internal/service"
+)
+
+// AgentHostStatusNotifyJob 周期性检测主机上下线变化并触发 webhook 通知。
+type AgentHostStatusNotifyJob struct {
+	Notifications service.NotificationService
+}
+
+func NewAgentHostStatusNotifyJob(notifications service.NotificationService) *AgentHostStatusNotifyJob {
+	return &AgentHostStatusNotifyJob{Notifications: notifications}
+}
+
+func (j *AgentHostStatusNotifyJob) Name() string { return "agent_host.status.notify" }
+
+func (j *AgentHostStatusNotifyJob) Run(ctx context.Context) error {
+	if j == nil || j.Notifications == nil {
+		return fmt.Errorf("agent host status notify job dependencies not configured / agent host 状态通知任务依赖未配置")
+	}
+	return j.Notifications.CheckAgentHosts(ctx)
+}
@@ -0,0 +1,41 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/creamcroissant/xboard/internal/service"
+)
+
+// PlanTrafficResetJob 按套餐重置周期清零用户流量并归档上一周期用量。
+type PlanTrafficResetJob struct {
+	TrafficService service.UserTrafficService
+	Logger         *slog.Logger
+}
+
+// NewPlanTrafficResetJob 构造套餐周期流量重置任务。
+func NewPlanTrafficResetJob(trafficService service.UserTrafficService, logger *slog.Logger) *PlanTrafficResetJob {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &PlanTrafficResetJob{TrafficService: trafficService, Logger: logger}
+}
+
+// Name 返回任务标识。
+func (j *PlanTrafficResetJob) Name() string { return "traffic.plan_cycle.reset" }
+
+// Run 重置已跨过周期边界的用户流量。
+func (j *PlanTrafficResetJob) Run(ctx context.Context) error {
+	if j == nil || j.TrafficService == nil {
+		return fmt.Errorf("plan traffic reset job dependencies not configured / 套餐流量重置任务依赖未配置")
+	}
+	processed, err := j.TrafficService.ResetPlanCycles(ctx)
+	if processed > 0 {
+		j.Logger.Info("reset plan cycle traffic", "users_processed", processed)
+	}
+	if err != nil {
+		return fmt.Errorf("plan traffic reset job: %w", err)
+	}
+	return nil
+}
@@ -54,6 +54,11 @@ func (s *Scheduler) Register(spec string, runnable Runnable) (cron.EntryID, erro
 	return entryID, nil
 }
 
+// Next 返回任务的下一次执行时间；调度器未启动或任务不存在时返回零值。
+func (s *Scheduler) Next(id cron.EntryID) time.Time {
+	return s.cron.Entry(id).Next
+}
+
 // Start 启动调度器并执行任务。
 func (s *Scheduler) Start() {
 	s.mu.Lock()
@@ -0,0 +1,50 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+// TrafficReportDedupRetention 是流量批次幂等键的保留时长。
+// Agent 单次上报的重试预算只有几十秒，保留一天足以覆盖面板重启、网络中断后的重试。
+const TrafficReportDedupRetention = 24 * time.Hour
+
+// TrafficReportDedupCleanupJob 删除过期的流量批次幂等键，避免去重表无限增长。
+type TrafficReportDedupCleanupJob struct {
+	Dedups  repository.TrafficReportDedupRepository
+	Logger  *slog.Logger
+	timeNow func() time.Time
+}
+
+// NewTrafficReportDedupCleanupJob 创建幂等键清理任务。
+func NewTrafficReportDedupCleanupJob(dedups repository.TrafficReportDedupRepository, logger *slog.Logger) *TrafficReportDedupCleanupJob {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &TrafficReportDedupCleanupJob{Dedups: dedups, Logger: logger, timeNow: time.Now}
+}
+
+// Name implements Runnable interface.
+func (j *TrafficReportDedupCleanupJob) Name() string {
+	return "traffic_report_dedup.cleanup"
+}
+
+// Run implements Runnable interface.
+func (j *TrafficReportDedupCleanupJob) Run(ctx context.Context) error {
+	if j == nil || j.Dedups == nil {
+		return fmt.Errorf("traffic report dedup cleanup job dependencies not configured / 流量幂等键清理任务依赖未配置")
+	}
+	before := j.timeNow().Add(-TrafficReportDedupRetention).Unix()
+	deleted, err := j.Dedups.DeleteHandledBefore(ctx, before)
+	if err != nil {
+		return fmt.Errorf("traffic report dedup cleanup job: %w", err)
+	}
+	if deleted > 0 {
+		j.Logger.Info("cleaned up expired traffic report dedup keys", "deleted_rows", deleted)
+	}
+	return nil
+}
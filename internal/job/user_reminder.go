@@ -0,0 +1,41 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/creamcroissant/xboard/internal/service"
+)
+
+// UserReminderJob 发送到期与流量不足提醒。
+type UserReminderJob struct {
+	Reminders service.UserReminderService
+	Logger    *slog.Logger
+}
+
+// NewUserReminderJob 构造用户提醒任务。
+func NewUserReminderJob(reminders service.UserReminderService, logger *slog.Logger) *UserReminderJob {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &UserReminderJob{Reminders: reminders, Logger: logger}
+}
+
+// Name 返回任务标识。
+func (j *UserReminderJob) Name() string { return "user.reminder" }
+
+// Run 发送提醒。
+func (j *UserReminderJob) Run(ctx context.Context) error {
+	if j == nil || j.Reminders == nil {
+		return fmt.Errorf("user reminder job dependencies not configured / 用户提醒任务依赖未配置")
+	}
+	result, err := j.Reminders.Run(ctx)
+	if err != nil {
+		return fmt.Errorf("user reminder job: %w", err)
+	}
+	if result.Expiry > 0 || result.Traffic > 0 {
+		j.Logger.Info("user reminders queued", "expiry", result.Expiry, "traffic", result.Traffic)
+	}
+	return nil
+}
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Per-node weight used to order subscription output (0 behaves like 100).
+ALTER TABLE servers ADD COLUMN weight INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE servers DROP COLUMN weight;
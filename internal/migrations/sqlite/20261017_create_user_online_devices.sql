@@ -0,0 +1,17 @@
+-- +goose Up
+-- 创建在线设备表，记录 Agent 上报的 (agent_host_id, user_id, ip) 以及最后在线时间
+CREATE TABLE IF NOT EXISTS user_online_devices (
+    agent_host_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    ip TEXT NOT NULL,
+    last_seen_at INTEGER NOT NULL,
+    PRIMARY KEY(agent_host_id, user_id, ip)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_online_devices_user_seen ON user_online_devices(user_id, last_seen_at);
+CREATE INDEX IF NOT EXISTS idx_user_online_devices_last_seen ON user_online_devices(last_seen_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_user_online_devices_last_seen;
+DROP INDEX IF EXISTS idx_user_online_devices_user_seen;
+DROP TABLE IF EXISTS user_online_devices;
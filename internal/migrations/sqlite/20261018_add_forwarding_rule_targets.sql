@@ -0,0 +1,6 @@
+-- +goose Up
+-- 多目标加权轮询：JSON 数组 [{address, port, weight}]，为空时沿用 target_address/target_port。
+ALTER TABLE forwarding_rules ADD COLUMN targets TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE forwarding_rules DROP COLUMN targets;
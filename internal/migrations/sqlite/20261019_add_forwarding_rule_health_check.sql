@@ -0,0 +1,10 @@
+-- +goose Up
+-- 转发目标健康检查：间隔/超时（秒，0 表示不检查/使用默认值），target_health 为 Agent 上报的 JSON 状态。
+ALTER TABLE forwarding_rules ADD COLUMN health_check_interval INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE forwarding_rules ADD COLUMN health_check_timeout INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE forwarding_rules ADD COLUMN target_health TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE forwarding_rules DROP COLUMN target_health;
+ALTER TABLE forwarding_rules DROP COLUMN health_check_timeout;
+ALTER TABLE forwarding_rules DROP COLUMN health_check_interval;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- 支撑按 connection_start 时间窗口聚合 Top 目标/来源（可选按 agent_host_id 过滤）。
+CREATE INDEX IF NOT EXISTS idx_access_logs_agent_connection_start ON access_logs(agent_host_id, connection_start);
+CREATE INDEX IF NOT EXISTS idx_access_logs_connection_start ON access_logs(connection_start);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_access_logs_connection_start;
+DROP INDEX IF EXISTS idx_access_logs_agent_connection_start;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- 访问日志来源 IP 的归属地信息（尽力解析，失败时保持默认值）。
+ALTER TABLE access_logs ADD COLUMN source_country TEXT NOT NULL DEFAULT '';
+ALTER TABLE access_logs ADD COLUMN source_asn INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_access_logs_source_country ON access_logs(source_country);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_access_logs_source_country;
+ALTER TABLE access_logs DROP COLUMN source_asn;
+ALTER TABLE access_logs DROP COLUMN source_country;
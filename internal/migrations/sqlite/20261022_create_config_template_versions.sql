@@ -0,0 +1,37 @@
+-- +goose Up
+-- 配置模板历史版本：每次更新生成一条不可变快照，便于回滚。
+CREATE TABLE IF NOT EXISTS config_template_versions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    template_id INTEGER NOT NULL,
+    version INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    type TEXT NOT NULL,
+    content TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    min_version TEXT NOT NULL DEFAULT '',
+    capabilities TEXT NOT NULL DEFAULT '[]',   -- JSON array
+    change_note TEXT NOT NULL DEFAULT '',
+    operator_id INTEGER NOT NULL DEFAULT 0,
+    created_at INTEGER NOT NULL,
+    FOREIGN KEY (template_id) REFERENCES config_templates(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_config_template_versions_unique ON config_template_versions(template_id, version);
+
+-- 已有模板补一条初始版本
+INSERT INTO config_template_versions (
+    template_id, version, name, type, content, description, min_version, capabilities, change_note, operator_id, created_at
+)
+SELECT id, 1, name, type, content, description, min_version, capabilities, 'initial', 0, updated_at
+FROM config_templates;
+
+-- 节点固定的模板版本，0 表示跟随最新版本
+ALTER TABLE agent_hosts ADD COLUMN template_version INTEGER NOT NULL DEFAULT 0;
+
+-- 已分配模板的节点固定到上面补的初始版本，与新分配时固定到最新版本的行为一致
+UPDATE agent_hosts SET template_version = 1 WHERE template_id > 0;
+
+-- +goose Down
+ALTER TABLE agent_hosts DROP COLUMN template_version;
+DROP INDEX IF EXISTS idx_config_template_versions_unique;
+DROP TABLE IF EXISTS config_template_versions;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- 刷新令牌轮换：同一次登录派生的令牌共享 family_id，已轮换的令牌保留 rotated_at 以便识别重放
+ALTER TABLE tokens ADD COLUMN family_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE tokens ADD COLUMN rotated_at INTEGER NOT NULL DEFAULT 0;
+
+UPDATE tokens SET family_id = refresh_token WHERE family_id = '';
+
+CREATE INDEX IF NOT EXISTS idx_tokens_family_id ON tokens(family_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_tokens_family_id;
+ALTER TABLE tokens DROP COLUMN rotated_at;
+ALTER TABLE tokens DROP COLUMN family_id;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- 登录失败锁定：按 账号+IP 统计连续失败次数，锁定对账号生效（任意 IP 登录均被拒绝）
+CREATE TABLE IF NOT EXISTS login_lockouts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    identifier TEXT NOT NULL,
+    ip TEXT NOT NULL DEFAULT '',
+    failed_count INTEGER NOT NULL DEFAULT 0,
+    lock_count INTEGER NOT NULL DEFAULT 0,
+    locked_until INTEGER NOT NULL DEFAULT 0,
+    last_failed_at INTEGER NOT NULL DEFAULT 0,
+    created_at INTEGER NOT NULL DEFAULT (strftime('%s','now')),
+    updated_at INTEGER NOT NULL DEFAULT (strftime('%s','now'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_login_lockouts_identifier_ip ON login_lockouts(identifier, ip);
+CREATE INDEX IF NOT EXISTS idx_login_lockouts_locked_until ON login_lockouts(locked_until);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_login_lockouts_locked_until;
+DROP INDEX IF EXISTS idx_login_lockouts_identifier_ip;
+DROP TABLE IF EXISTS login_lockouts;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- 用户提醒记录：按 用户+提醒类型 记录最近一次提醒时间，避免到期/流量提醒每天重复发送
+CREATE TABLE IF NOT EXISTS user_reminders (
+    user_id INTEGER NOT NULL,
+    kind TEXT NOT NULL,
+    last_reminded_at INTEGER NOT NULL DEFAULT 0,
+    updated_at INTEGER NOT NULL DEFAULT (strftime('%s','now')),
+    PRIMARY KEY (user_id, kind)
+);
+
+CREATE INDEX IF NOT EXISTS idx_users_expired_at ON users(expired_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_users_expired_at;
+DROP TABLE IF EXISTS user_reminders;
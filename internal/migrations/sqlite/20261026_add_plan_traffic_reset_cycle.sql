@@ -0,0 +1,31 @@
+-- +goose Up
+-- 套餐流量重置周期：reset_cycle 为空表示不自动重置，reset_day 为每个周期的重置日（1-31，超过当月天数时取月末）
+ALTER TABLE plans ADD COLUMN reset_day INTEGER;
+ALTER TABLE plans ADD COLUMN reset_cycle TEXT NOT NULL DEFAULT '';
+
+-- 周期重置前的用户流量归档
+CREATE TABLE IF NOT EXISTS user_traffic_resets (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    plan_id INTEGER NOT NULL DEFAULT 0,
+    period_start INTEGER NOT NULL,
+    period_end INTEGER NOT NULL,
+    upload INTEGER NOT NULL DEFAULT 0,
+    download INTEGER NOT NULL DEFAULT 0,
+    quota_bytes INTEGER NOT NULL DEFAULT 0,
+    reset_at INTEGER NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE(user_id, period_end)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_traffic_resets_reset_at ON user_traffic_resets(reset_at);
+CREATE INDEX IF NOT EXISTS idx_user_traffic_resets_plan_id ON user_traffic_resets(plan_id);
+CREATE INDEX IF NOT EXISTS idx_users_plan_id ON users(plan_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_users_plan_id;
+DROP INDEX IF EXISTS idx_user_traffic_resets_plan_id;
+DROP INDEX IF EXISTS idx_user_traffic_resets_reset_at;
+DROP TABLE IF EXISTS user_traffic_resets;
+ALTER TABLE plans DROP COLUMN reset_cycle;
+ALTER TABLE plans DROP COLUMN reset_day;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- 软限额提醒：每个流量周期只在首次越过 traffic_warning_percent 时提醒一次
+ALTER TABLE user_traffic_periods ADD COLUMN warned INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE user_traffic_periods DROP COLUMN warned;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- 探针自诊断：每台主机只保留最近一次上报的诊断包（已脱敏的 JSON）
+CREATE TABLE IF NOT EXISTS agent_host_diagnostics (
+    agent_host_id INTEGER PRIMARY KEY,
+    payload TEXT NOT NULL DEFAULT '{}',
+    collected_at INTEGER NOT NULL DEFAULT 0,
+    updated_at INTEGER NOT NULL DEFAULT (strftime('%s','now'))
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS agent_host_diagnostics;
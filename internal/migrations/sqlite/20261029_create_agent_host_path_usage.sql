@@ -0,0 +1,18 @@
+-- +goose Up
+-- 探针监控目录磁盘占用：每台主机每个路径只保留最近一次上报
+CREATE TABLE IF NOT EXISTS agent_host_path_usage (
+    agent_host_id INTEGER NOT NULL,
+    path TEXT NOT NULL,
+    path_exists INTEGER NOT NULL DEFAULT 0,
+    used_bytes INTEGER NOT NULL DEFAULT 0,
+    truncated INTEGER NOT NULL DEFAULT 0,
+    fs_total_bytes INTEGER NOT NULL DEFAULT 0,
+    fs_used_bytes INTEGER NOT NULL DEFAULT 0,
+    fs_free_bytes INTEGER NOT NULL DEFAULT 0,
+    alerting INTEGER NOT NULL DEFAULT 0,
+    updated_at INTEGER NOT NULL DEFAULT (strftime('%s','now')),
+    PRIMARY KEY (agent_host_id, path)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS agent_host_path_usage;
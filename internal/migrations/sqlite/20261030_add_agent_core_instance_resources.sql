@@ -0,0 +1,10 @@
+-- +goose Up
+-- 核心实例进程资源占用：CPU 为单核百分比，resource_sampled_at 为 0 表示未采样到进程
+ALTER TABLE agent_core_instances ADD COLUMN cpu_percent REAL NOT NULL DEFAULT 0;
+ALTER TABLE agent_core_instances ADD COLUMN memory_rss_bytes INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE agent_core_instances ADD COLUMN resource_sampled_at INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE agent_core_instances DROP COLUMN resource_sampled_at;
+ALTER TABLE agent_core_instances DROP COLUMN memory_rss_bytes;
+ALTER TABLE agent_core_instances DROP COLUMN cpu_percent;
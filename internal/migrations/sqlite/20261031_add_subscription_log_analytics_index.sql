@@ -0,0 +1,6 @@
+-- +goose Up
+-- 订阅日志分析：按用户统计不同 IP 及查询单个 IP 最近客户端
+CREATE INDEX IF NOT EXISTS idx_subscription_logs_user_ip_created ON subscription_logs(user_id, client_ip, created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_subscription_logs_user_ip_created;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- 路由规则：显式匹配类型与优先级（数值越小越先匹配）
+ALTER TABLE server_routes ADD COLUMN match_type TEXT NOT NULL DEFAULT 'domain';
+ALTER TABLE server_routes ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_server_routes_priority ON server_routes(priority, id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_server_routes_priority;
+ALTER TABLE server_routes DROP COLUMN priority;
+ALTER TABLE server_routes DROP COLUMN match_type;
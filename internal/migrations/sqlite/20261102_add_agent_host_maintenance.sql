@@ -0,0 +1,6 @@
+-- +goose Up
+-- 维护模式：暂停向节点下发配置与用户
+ALTER TABLE agent_hosts ADD COLUMN maintenance INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE agent_hosts DROP COLUMN maintenance;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- 配置漂移：节点上报的配置哈希与面板期望不一致
+ALTER TABLE agent_hosts ADD COLUMN drift_detected INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE agent_hosts DROP COLUMN drift_detected;
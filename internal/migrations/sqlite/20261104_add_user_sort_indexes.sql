@@ -0,0 +1,8 @@
+-- +goose Up
+-- 管理端用户列表排序：已用流量（表达式索引）与最近登录时间；expired_at 已有 idx_users_expired_at
+CREATE INDEX IF NOT EXISTS idx_users_traffic_used ON users((u + d));
+CREATE INDEX IF NOT EXISTS idx_users_last_login_at ON users(last_login_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_users_last_login_at;
+DROP INDEX IF EXISTS idx_users_traffic_used;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- 活跃连接数：来源为空表示节点无法提供连接数，connection_count 此时不代表没有连接
+ALTER TABLE agent_hosts ADD COLUMN connection_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE agent_hosts ADD COLUMN connection_count_source TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE agent_hosts DROP COLUMN connection_count_source;
+ALTER TABLE agent_hosts DROP COLUMN connection_count;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- 核心实例重启统计：restart_count 为 restart_window_seconds 内的重启次数，flapping 表示短时间内频繁重启
+ALTER TABLE agent_core_instances ADD COLUMN restart_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE agent_core_instances ADD COLUMN restart_window_seconds INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE agent_core_instances ADD COLUMN last_restart_at INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE agent_core_instances ADD COLUMN uptime_seconds INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE agent_core_instances ADD COLUMN flapping INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE agent_core_instances DROP COLUMN flapping;
+ALTER TABLE agent_core_instances DROP COLUMN uptime_seconds;
+ALTER TABLE agent_core_instances DROP COLUMN last_restart_at;
+ALTER TABLE agent_core_instances DROP COLUMN restart_window_seconds;
+ALTER TABLE agent_core_instances DROP COLUMN restart_count;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Per-node multiplier applied to reported user traffic before it is charged against quota.
+-- It is the single billing multiplier for both agent and UniProxy reports. The legacy text
+-- column rate mirrors it: the admin service writes both from the same value on save.
+ALTER TABLE servers ADD COLUMN traffic_ratio REAL NOT NULL DEFAULT 1;
+-- Carry over the legacy rate where it is a valid positive number.
+UPDATE servers SET traffic_ratio = CAST(rate AS REAL) WHERE CAST(rate AS REAL) > 0;
+
+-- +goose Down
+ALTER TABLE servers DROP COLUMN traffic_ratio;
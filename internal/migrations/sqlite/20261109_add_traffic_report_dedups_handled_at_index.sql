@@ -0,0 +1,6 @@
+-- +goose Up
+-- 去重键只保留有限时间，按 handled_at 定期清理
+CREATE INDEX IF NOT EXISTS idx_traffic_report_dedups_handled_at ON traffic_report_dedups(handled_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_traffic_report_dedups_handled_at;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Default subscription template per plan and per user; NULL falls back to the system default.
+ALTER TABLE plans ADD COLUMN subscription_template_id INTEGER;
+ALTER TABLE users ADD COLUMN subscription_template_id INTEGER;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN subscription_template_id;
+ALTER TABLE plans DROP COLUMN subscription_template_id;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- 节点级同步/上报间隔（秒）：0 表示沿用全局 server_pull_interval / server_push_interval
+ALTER TABLE agent_hosts ADD COLUMN sync_interval_seconds INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE agent_hosts ADD COLUMN report_interval_seconds INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE agent_hosts DROP COLUMN report_interval_seconds;
+ALTER TABLE agent_hosts DROP COLUMN sync_interval_seconds;
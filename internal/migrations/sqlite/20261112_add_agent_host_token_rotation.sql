@@ -0,0 +1,10 @@
+-- +goose Up
+-- 令牌轮换：旧令牌在宽限期内仍可认证，供 Agent 收到并保存新令牌
+ALTER TABLE agent_hosts ADD COLUMN previous_token TEXT NOT NULL DEFAULT '';
+ALTER TABLE agent_hosts ADD COLUMN previous_token_expires_at INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_agent_hosts_previous_token ON agent_hosts(previous_token);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_agent_hosts_previous_token;
+ALTER TABLE agent_hosts DROP COLUMN previous_token_expires_at;
+ALTER TABLE agent_hosts DROP COLUMN previous_token;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- upload_bytes/download_bytes 为乘以节点倍率后的计费字节，raw_* 记录 Agent 上报的原始字节，便于核对倍率
+ALTER TABLE user_traffic_periods ADD COLUMN raw_upload_bytes INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE user_traffic_periods ADD COLUMN raw_download_bytes INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE user_traffic_periods DROP COLUMN raw_download_bytes;
+ALTER TABLE user_traffic_periods DROP COLUMN raw_upload_bytes;
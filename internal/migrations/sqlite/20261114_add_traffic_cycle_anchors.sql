@@ -0,0 +1,32 @@
+-- +goose Up
+-- 周期重置的起算时间：用户被分配到当前套餐的时间，以及套餐启用/切换重置周期的时间。
+-- 从未重置过的用户只重置这两个时间之后跨过的边界，避免启用周期或换套餐时在周期中途清零。
+ALTER TABLE users ADD COLUMN plan_assigned_at INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE plans ADD COLUMN reset_cycle_since INTEGER NOT NULL DEFAULT 0;
+-- 已启用周期的套餐从迁移时起算，此前未归档的边界不再补做
+UPDATE plans SET reset_cycle_since = strftime('%s','now') WHERE reset_cycle != '';
+
+-- plan_id 由多条路径写入（下单、管理端等），在数据库层统一记录分配时间
+-- +goose StatementBegin
+CREATE TRIGGER IF NOT EXISTS trg_users_plan_assigned
+AFTER UPDATE OF plan_id ON users
+WHEN NEW.plan_id IS NOT OLD.plan_id
+BEGIN
+    UPDATE users SET plan_assigned_at = strftime('%s','now') WHERE id = NEW.id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER IF NOT EXISTS trg_plans_reset_cycle_since
+AFTER UPDATE OF reset_cycle ON plans
+WHEN NEW.reset_cycle != '' AND NEW.reset_cycle IS NOT OLD.reset_cycle
+BEGIN
+    UPDATE plans SET reset_cycle_since = strftime('%s','now') WHERE id = NEW.id;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TRIGGER IF EXISTS trg_plans_reset_cycle_since;
+DROP TRIGGER IF EXISTS trg_users_plan_assigned;
+ALTER TABLE plans DROP COLUMN reset_cycle_since;
+ALTER TABLE users DROP COLUMN plan_assigned_at;
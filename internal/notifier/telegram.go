@@ -0,0 +1,173 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// TelegramAPIBase 是 Telegram Bot API 的默认地址。
+const TelegramAPIBase = "https://api.telegram.org"
+
+// ErrTelegramConflict 表示 getUpdates 与已设置的 webhook 冲突（HTTP 409）。
+var ErrTelegramConflict = errors.New("notifier: telegram getUpdates conflicts with active webhook")
+
+// TelegramBot 是 Telegram Bot API 的最小客户端，仅覆盖收发消息与 webhook 管理。
+type TelegramBot struct {
+	token      string
+	apiBase    string
+	httpClient *http.Client
+}
+
+// TelegramUpdate 对应 Bot API 的 Update 对象（仅保留用到的字段）。
+type TelegramUpdate struct {
+	UpdateID int64            `json:"update_id"`
+	Message  *TelegramMessage `json:"message,omitempty"`
+}
+
+// TelegramMessage 对应 Bot API 的 Message 对象。
+type TelegramMessage struct {
+	MessageID int64         `json:"message_id"`
+	From      *TelegramUser `json:"from,omitempty"`
+	Chat      TelegramChat  `json:"chat"`
+	Text      string        `json:"text"`
+}
+
+// TelegramUser 对应 Bot API 的 User 对象。
+type TelegramUser struct {
+	ID        int64  `json:"id"`
+	IsBot     bool   `json:"is_bot"`
+	Username  string `json:"username"`
+	FirstName string `json:"first_name"`
+}
+
+// TelegramChat 对应 Bot API 的 Chat 对象。
+type TelegramChat struct {
+	ID   int64  `json:"id"`
+	Type string `json:"type"`
+}
+
+// NewTelegramBot 创建机器人客户端；httpClient 的超时需大于长轮询时长，为空时使用 60 秒。
+func NewTelegramBot(token string, httpClient *http.Client) *TelegramBot {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 60 * time.Second}
+	}
+	return &TelegramBot{token: strings.TrimSpace(token), apiBase: TelegramAPIBase, httpClient: httpClient}
+}
+
+// WithAPIBase 覆盖 API 地址（用于自建 Bot API 服务或测试）。
+func (b *TelegramBot) WithAPIBase(base string) *TelegramBot {
+	if base = strings.TrimRight(strings.TrimSpace(base), "/"); base != "" {
+		b.apiBase = base
+	}
+	return b
+}
+
+// SendMessage 向 chatID 发送文本消息，parseMode 为空时按纯文本发送。
+func (b *TelegramBot) SendMessage(ctx context.Context, chatID, text, parseMode string) error {
+	params := map[string]any{
+		"chat_id":                  chatID,
+		"text":                     text,
+		"disable_web_page_preview": true,
+	}
+	if parseMode != "" {
+		params["parse_mode"] = parseMode
+	}
+	return b.call(ctx, "sendMessage", params, nil)
+}
+
+// GetUpdates 以长轮询方式拉取 offset 之后的更新。
+func (b *TelegramBot) GetUpdates(ctx context.Context, offset int64, timeout time.Duration) ([]TelegramUpdate, error) {
+	params := map[string]any{
+		"offset":          offset,
+		"timeout":         int(timeout / time.Second),
+		"allowed_updates": []string{"message"},
+	}
+	var updates []TelegramUpdate
+	if err := b.call(ctx, "getUpdates", params, &updates); err != nil {
+		return nil, err
+	}
+	return updates, nil
+}
+
+// GetMe 返回机器人自身信息，用于拼接绑定链接。
+func (b *TelegramBot) GetMe(ctx context.Context) (*TelegramUser, error) {
+	var me TelegramUser
+	if err := b.call(ctx, "getMe", map[string]any{}, &me); err != nil {
+		return nil, err
+	}
+	return &me, nil
+}
+
+// SetWebhook 注册 webhook 地址，secret 会在回调时通过 X-Telegram-Bot-Api-Secret-Token 头回传。
+func (b *TelegramBot) SetWebhook(ctx context.Context, url, secret string) error {
+	params := map[string]any{
+		"url":             url,
+		"allowed_updates": []string{"message"},
+	}
+	if secret != "" {
+		params["secret_token"] = secret
+	}
+	return b.call(ctx, "setWebhook", params, nil)
+}
+
+// DeleteWebhook 移除 webhook，使 getUpdates 可用。
+func (b *TelegramBot) DeleteWebhook(ctx context.Context) error {
+	return b.call(ctx, "deleteWebhook", map[string]any{}, nil)
+}
+
+type telegramAPIResponse struct {
+	OK          bool            `json:"ok"`
+	Result      json.RawMessage `json:"result"`
+	ErrorCode   int             `json:"error_code"`
+	Description string          `json:"description"`
+}
+
+func (b *TelegramBot) call(ctx context.Context, method string, params map[string]any, result any) error {
+	if b == nil || b.token == "" {
+		return fmt.Errorf("telegram bot token is required / Telegram 机器人令牌未配置")
+	}
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	endpoint := fmt.Sprintf("%s/bot%s/%s", b.apiBase, b.token, method)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		// 错误信息中的 URL 含有令牌，不直接透出
+		var urlErr interface{ Unwrap() error }
+		if errors.As(err, &urlErr) && urlErr.Unwrap() != nil {
+			return fmt.Errorf("telegram %s: %w", method, urlErr.Unwrap())
+		}
+		return fmt.Errorf("telegram %s: request failed", method)
+	}
+	defer resp.Body.Close()
+
+	var payload telegramAPIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return fmt.Errorf("telegram %s: decode response (status %d): %w", method, resp.StatusCode, err)
+	}
+	if !payload.OK {
+		if payload.ErrorCode == http.StatusConflict {
+			return fmt.Errorf("%w: %s", ErrTelegramConflict, payload.Description)
+		}
+		return fmt.Errorf("telegram %s: %d %s", method, payload.ErrorCode, payload.Description)
+	}
+	if result != nil && len(payload.Result) > 0 {
+		if err := json.Unmarshal(payload.Result, result); err != nil {
+			return fmt.Errorf("telegram %s: decode result: %w", method, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// WebhookClient 以 JSON POST 投递 webhook，失败时按指数退避重试。
+type WebhookClient struct {
+	httpClient  *http.Client
+	maxAttempts int
+	backoff     time.Duration
+}
+
+const (
+	defaultWebhookTimeout     = 10 * time.Second
+	defaultWebhookMaxAttempts = 4
+	defaultWebhookBackoff     = 2 * time.Second
+)
+
+// NewWebhookClient 创建 webhook 客户端，httpClient 为空时使用 10 秒超时的默认客户端。
+func NewWebhookClient(httpClient *http.Client) *WebhookClient {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultWebhookTimeout}
+	}
+	return &WebhookClient{
+		httpClient:  httpClient,
+		maxAttempts: defaultWebhookMaxAttempts,
+		backoff:     defaultWebhookBackoff,
+	}
+}
+
+// WithRetry 调整最大尝试次数与首次重试间隔。
+func (c *WebhookClient) WithRetry(maxAttempts int, backoff time.Duration) *WebhookClient {
+	if maxAttempts > 0 {
+		c.maxAttempts = maxAttempts
+	}
+	if backoff > 0 {
+		c.backoff = backoff
+	}
+	return c
+}
+
+// PostJSON 将 payload 编码为 JSON 后投递到 url；网络错误、429 与 5xx 会重试，其余 4xx 直接失败。
+func (c *WebhookClient) PostJSON(ctx context.Context, url string, payload any) error {
+	url = strings.TrimSpace(url)
+	if url == "" {
+		return fmt.Errorf("webhook url is required / webhook 地址不能为空")
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode webhook payload: %w", err)
+	}
+
+	wait := c.backoff
+	var lastErr error
+	for attempt := 1; attempt <= c.maxAttempts; attempt++ {
+		retry, err := c.post(ctx, url, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry || attempt == c.maxAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+	return fmt.Errorf("deliver webhook after %d attempts: %w", c.maxAttempts, lastErr)
+}
+
+// post 发送一次请求，返回值 retry 表示该错误是否值得重试。
+func (c *WebhookClient) post(ctx context.Context, url string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "XBoard-Webhook")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+}
@@ -31,7 +31,11 @@ func NewClashBuilder() *ClashBuilder {
 }
 
 func (b *ClashBuilder) Flags() []string {
-	return []string{"clash", "mihomo", "clash-meta", "clashmeta"}
+	return []string{"clash"}
+}
+
+func (b *ClashBuilder) TemplateKey() string {
+	return "clash"
 }
 
 func (b *ClashBuilder) Build(req BuildRequest) (*Result, error) {
@@ -430,7 +434,7 @@ func buildClashSocks(node Node) map[string]any {
 		"server":   node.Host,
 		"port":     node.Port,
 		"udp":      true,
-		"username": node.Password,
+		"username": basicAuthUsername(node),
 		"password": node.Password,
 	}
 	if settingBool(node.Settings, "tls") {
@@ -446,7 +450,7 @@ func buildClashHTTP(node Node) map[string]any {
 		"type":     "http",
 		"server":   node.Host,
 		"port":     node.Port,
-		"username": node.Password,
+		"username": basicAuthUsername(node),
 		"password": node.Password,
 	}
 	if settingBool(node.Settings, "tls") {
@@ -0,0 +1,319 @@
+// 文件路径: internal/protocol/clash_meta.go
+// 模块说明: 这是 internal 模块里的 clash_meta 逻辑，下面的注释会用非常通俗的中文帮你理解每一步。
+package protocol
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ClashMetaBuilder 为 Clash.Meta (mihomo) 客户端生成订阅，支持 hysteria2/tuic/vless-reality。
+type ClashMetaBuilder struct {
+	base *BaseBuilder
+	cdn  *CDNConfig
+}
+
+// NewClashMetaBuilder 创建 Clash.Meta 构建器。
+func NewClashMetaBuilder() *ClashMetaBuilder {
+	base := NewBaseBuilder()
+	base.Allow("shadowsocks", "vmess", "trojan", "vless", "hysteria", "hysteria2", "tuic", "anytls", "mieru", "socks", "http")
+	return &ClashMetaBuilder{base: base}
+}
+
+// Flags 返回 Clash.Meta 系列客户端标识，需在 ClashBuilder 之后注册以优先匹配。
+func (b *ClashMetaBuilder) Flags() []string {
+	return []string{"clash.meta", "clash-meta", "clashmeta", "clashmetaforandroid", "mihomo"}
+}
+
+// TemplateKey 返回 Clash.Meta 模板在 BuildRequest.Templates 中的键。
+func (b *ClashMetaBuilder) TemplateKey() string {
+	return "clash-meta"
+}
+
+// Build 生成 mihomo YAML 配置；模板为空时回退为内置默认配置。
+func (b *ClashMetaBuilder) Build(req BuildRequest) (*Result, error) {
+	nodes := req.Nodes
+	if b.base != nil {
+		nodes = b.base.FilterNodes(req)
+	}
+	b.cdn = req.CDN
+	proxies := make([]map[string]any, 0, len(nodes))
+	proxyNames := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		proxy := buildClashMetaProxy(node, b.cdn)
+		if proxy == nil {
+			continue
+		}
+		proxies = append(proxies, proxy)
+		proxyNames = append(proxyNames, node.Name)
+	}
+	profileTitle := strings.TrimSpace(req.AppName)
+	if profileTitle == "" {
+		profileTitle = defaultClashProfileName
+	}
+
+	// 复用 Clash 的分组合并与规则逻辑，仅模板来源与节点生成不同
+	clash := &ClashBuilder{}
+	config := b.loadTemplateConfig(req.Templates["clash-meta"], profileTitle)
+	config["proxies"] = append(cloneProxyMaps(config["proxies"]), proxies...)
+	clash.mergeProxyGroups(config, proxyNames, profileTitle)
+	clash.applyRules(config, req.Host, profileTitle)
+	payload, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	content := strings.ReplaceAll(string(payload), "$app_name", profileTitle)
+	content = clash.applyTemplateVariables(content, req)
+	headers := enrichClashHeaders(buildUserHeaders(req.User, req.Lang, req.I18n), profileTitle, req.AppURL)
+	return &Result{
+		Payload:     []byte(content),
+		ContentType: "text/yaml",
+		Headers:     headers,
+	}, nil
+}
+
+func (b *ClashMetaBuilder) loadTemplateConfig(rawTemplate string, profile string) map[string]any {
+	trimmed := strings.TrimSpace(rawTemplate)
+	if trimmed == "" {
+		return defaultClashMetaTemplate(profile)
+	}
+	var cfg map[string]any
+	if err := yaml.Unmarshal([]byte(trimmed), &cfg); err != nil || cfg == nil {
+		return defaultClashMetaTemplate(profile)
+	}
+	return cfg
+}
+
+// defaultClashMetaTemplate 返回默认 mihomo 配置：手动选择 + 自动测速两个分组。
+func defaultClashMetaTemplate(profile string) map[string]any {
+	if strings.TrimSpace(profile) == "" {
+		profile = defaultClashProfileName
+	}
+	return map[string]any{
+		"mixed-port":                7890,
+		"allow-lan":                 true,
+		"mode":                      "rule",
+		"log-level":                 "info",
+		"unified-delay":             true,
+		"tcp-concurrent":            true,
+		"find-process-mode":         "strict",
+		"global-client-fingerprint": "chrome",
+		"proxies":                   []map[string]any{},
+		"proxy-groups": []map[string]any{
+			{
+				"name":    profile,
+				"type":    "select",
+				"proxies": []string{"auto"},
+			},
+			{
+				"name":      "auto",
+				"type":      "url-test",
+				"proxies":   []string{},
+				"url":       "https://www.gstatic.com/generate_204",
+				"interval":  300,
+				"tolerance": 50,
+			},
+		},
+		"rules": []string{fmt.Sprintf("MATCH,%s", profile)},
+	}
+}
+
+func buildClashMetaProxy(node Node, cdn *CDNConfig) map[string]any {
+	switch strings.ToLower(node.Type) {
+	case "hysteria", "hysteria2":
+		return buildClashMetaHysteria(node)
+	case "tuic":
+		return buildClashMetaTuic(node)
+	case "vless":
+		return buildClashMetaVless(node, cdn)
+	case "anytls":
+		return buildClashMetaAnyTLS(node)
+	case "shadowsocks":
+		return buildClashMetaShadowsocks(node)
+	case "mieru":
+		return buildClashMetaMieru(node)
+	default:
+		return buildClashProxy(node, cdn)
+	}
+}
+
+// buildClashMetaVless 在通用 VLESS 基础上补充面板格式的 reality 配置（tls=2）。
+func buildClashMetaVless(node Node, cdn *CDNConfig) map[string]any {
+	proxy := buildClashVless(node, cdn)
+	if proxy == nil || settingString(node.Settings, "tls") != "2" {
+		return proxy
+	}
+	proxy["tls"] = true
+	proxy["reality-opts"] = map[string]any{
+		"public-key": settingString(node.Settings, "reality_settings.public_key"),
+		"short-id":   settingString(node.Settings, "reality_settings.short_id"),
+	}
+	if sni := settingString(node.Settings, "reality_settings.server_name"); sni != "" {
+		proxy["servername"] = sni
+	}
+	if _, ok := proxy["client-fingerprint"]; !ok {
+		proxy["client-fingerprint"] = "chrome"
+	}
+	return proxy
+}
+
+// buildClashMetaShadowsocks 在通用 shadowsocks 基础上将 shadow-tls 插件参数转换为 mihomo 格式。
+func buildClashMetaShadowsocks(node Node) map[string]any {
+	proxy := buildClashShadowsocks(node)
+	if proxy == nil || !isShadowTLSPlugin(settingString(node.Settings, "plugin")) {
+		return proxy
+	}
+	opts, version := shadowTLSPluginOptions(node)
+	pluginOpts := map[string]any{
+		"host":     opts["host"],
+		"password": opts["password"],
+		"version":  version,
+	}
+	proxy["plugin"] = "shadow-tls"
+	proxy["plugin-opts"] = pluginOpts
+	if version >= 3 {
+		proxy["client-fingerprint"] = "chrome"
+	}
+	return proxy
+}
+
+// buildClashMetaMieru 生成 mihomo mieru 节点，用户名与密码均为用户 UUID，与服务端配置一致。
+func buildClashMetaMieru(node Node) map[string]any {
+	transport := strings.ToUpper(settingString(node.Settings, "transport"))
+	if transport == "" {
+		transport = "TCP"
+	}
+	multiplexing := settingString(node.Settings, "multiplexing")
+	if multiplexing == "" {
+		multiplexing = "MULTIPLEXING_LOW"
+	}
+	proxy := map[string]any{
+		"name":         node.Name,
+		"type":         "mieru",
+		"server":       node.Host,
+		"port":         node.Port,
+		"username":     node.Password,
+		"password":     node.Password,
+		"transport":    transport,
+		"multiplexing": multiplexing,
+	}
+	if node.Ports != "" {
+		proxy["port-range"] = node.Ports
+	}
+	return proxy
+}
+
+// buildClashMetaAnyTLS 生成 mihomo anytls 节点，TLS 为协议必需项。
+func buildClashMetaAnyTLS(node Node) map[string]any {
+	proxy := map[string]any{
+		"name":               node.Name,
+		"type":               "anytls",
+		"server":             node.Host,
+		"port":               node.Port,
+		"password":           node.Password,
+		"udp":                true,
+		"client-fingerprint": "chrome",
+		"skip-cert-verify":   settingBool(node.Settings, "tls.allow_insecure"),
+	}
+	if sni := settingString(node.Settings, "tls.server_name"); sni != "" {
+		proxy["sni"] = sni
+	}
+	return proxy
+}
+
+// buildClashMetaHysteria 生成 hysteria/hysteria2 节点，version=1 时输出 v1 格式。
+func buildClashMetaHysteria(node Node) map[string]any {
+	proxy := map[string]any{
+		"name":   node.Name,
+		"server": node.Host,
+		"port":   node.Port,
+	}
+	if node.Ports != "" {
+		proxy["ports"] = node.Ports
+	}
+	if sni := settingString(node.Settings, "tls.server_name"); sni != "" {
+		proxy["sni"] = sni
+	}
+	proxy["skip-cert-verify"] = settingBool(node.Settings, "tls.allow_insecure")
+	up := settingString(node.Settings, "bandwidth.up")
+	down := settingString(node.Settings, "bandwidth.down")
+
+	if settingString(node.Settings, "version") == "1" && strings.ToLower(node.Type) != "hysteria2" {
+		proxy["type"] = "hysteria"
+		proxy["auth-str"] = node.Password
+		if up != "" {
+			proxy["up"] = up + " Mbps"
+		}
+		if down != "" {
+			proxy["down"] = down + " Mbps"
+		}
+		if obfs := clashMetaObfsPassword(node.Settings); obfs != "" {
+			proxy["obfs"] = obfs
+		}
+		return proxy
+	}
+
+	proxy["type"] = "hysteria2"
+	proxy["password"] = node.Password
+	if up != "" {
+		proxy["up"] = up
+	}
+	if down != "" {
+		proxy["down"] = down
+	}
+	if obfs := clashMetaObfsPassword(node.Settings); obfs != "" {
+		proxy["obfs"] = "salamander"
+		proxy["obfs-password"] = obfs
+	}
+	return proxy
+}
+
+// clashMetaObfsPassword 兼容 obfs 为字符串或 {open,type,password} 对象两种格式。
+func clashMetaObfsPassword(settings map[string]any) string {
+	if obfs := settingMap(settings, "obfs"); obfs != nil {
+		if _, ok := obfs["open"]; ok && !settingBool(obfs, "open") {
+			return ""
+		}
+		return settingString(obfs, "password")
+	}
+	return settingString(settings, "obfs")
+}
+
+func buildClashMetaTuic(node Node) map[string]any {
+	proxy := map[string]any{
+		"name":                  node.Name,
+		"type":                  "tuic",
+		"server":                node.Host,
+		"port":                  node.Port,
+		"uuid":                  node.Password,
+		"password":              node.Password,
+		"udp-relay-mode":        "native",
+		"reduce-rtt":            true,
+		"skip-cert-verify":      settingBool(node.Settings, "allow_insecure") || settingBool(node.Settings, "tls.allow_insecure"),
+		"congestion-controller": "bbr",
+	}
+	if cc := settingString(node.Settings, "congestion_control"); cc != "" {
+		proxy["congestion-controller"] = cc
+	}
+	sni := settingString(node.Settings, "server_name")
+	if sni == "" {
+		sni = settingString(node.Settings, "tls.server_name")
+	}
+	if sni != "" {
+		proxy["sni"] = sni
+	}
+	if alpn := settingString(node.Settings, "alpn"); alpn != "" {
+		proxy["alpn"] = []string{alpn}
+	} else {
+		proxy["alpn"] = []string{"h3"}
+	}
+	if settingString(node.Settings, "version") == "4" {
+		// TUIC v4 使用 token 认证
+		delete(proxy, "uuid")
+		delete(proxy, "password")
+		proxy["token"] = node.Password
+	}
+	return proxy
+}
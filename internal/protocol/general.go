@@ -19,7 +19,7 @@ type GeneralBuilder struct {
 // NewGeneralBuilder returns a ready-to-use general builder instance.
 func NewGeneralBuilder() *GeneralBuilder {
 	base := NewBaseBuilder()
-	base.Allow("shadowsocks", "vmess", "trojan", "vless", "hysteria", "hysteria2", "tuic", "socks", "http")
+	base.Allow("shadowsocks", "vmess", "trojan", "vless", "hysteria", "hysteria2", "tuic", "anytls", "naive", "socks", "http")
 	return &GeneralBuilder{base: base}
 }
 
@@ -72,6 +72,14 @@ func (b *GeneralBuilder) buildURI(node Node) string {
 		return b.buildHysteriaURI(node)
 	case "tuic":
 		return b.buildTuicURI(node)
+	case "anytls":
+		return b.buildAnyTLSURI(node)
+	case "naive":
+		return b.buildNaiveURI(node)
+	case "socks":
+		return b.buildSocksURI(node)
+	case "http":
+		return b.buildHTTPURI(node)
 	default:
 		return ""
 	}
@@ -91,6 +99,11 @@ func (b *GeneralBuilder) buildShadowsocksURI(node Node) string {
 	plugin := settingString(node.Settings, "plugin")
 	if plugin != "" {
 		pluginOpts := settingString(node.Settings, "plugin_opts")
+		if isShadowTLSPlugin(plugin) {
+			// 与 sing-box / mihomo 输出一致，v3 使用按用户派生的 ShadowTLS 密码
+			opts, _ := shadowTLSPluginOptions(node)
+			pluginOpts = formatPluginOptions(opts)
+		}
 		if pluginOpts != "" {
 			plugin = fmt.Sprintf("%s;%s", plugin, pluginOpts)
 		}
@@ -249,6 +262,73 @@ func (b *GeneralBuilder) buildVlessURI(node Node) string {
 	return u.String()
 }
 
+func (b *GeneralBuilder) buildAnyTLSURI(node Node) string {
+	u := url.URL{
+		Scheme:   "anytls",
+		User:     url.User(node.Password),
+		Host:     fmt.Sprintf("%s:%d", node.Host, node.Port),
+		Fragment: node.Name,
+	}
+	q := u.Query()
+
+	if sni := settingString(node.Settings, "tls.server_name"); sni != "" {
+		q.Set("sni", sni)
+	}
+	if settingBool(node.Settings, "tls.allow_insecure") {
+		q.Set("insecure", "1")
+	}
+
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// buildNaiveURI 生成 naive+https 链接，用户名/密码对应服务端 basic-auth。
+func (b *GeneralBuilder) buildNaiveURI(node Node) string {
+	u := url.URL{
+		Scheme:   "naive+https",
+		User:     url.UserPassword(basicAuthUsername(node), node.Password),
+		Host:     fmt.Sprintf("%s:%d", node.Host, node.Port),
+		Fragment: node.Name,
+	}
+	q := u.Query()
+
+	if sni := settingString(node.Settings, "tls_settings.server_name"); sni != "" {
+		q.Set("sni", sni)
+	}
+	if settingBool(node.Settings, "tls_settings.allow_insecure") {
+		q.Set("insecure", "1")
+	}
+
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// buildSocksURI 生成 v2rayN 格式的 socks 链接，userinfo 为 base64(user:pass)。
+func (b *GeneralBuilder) buildSocksURI(node Node) string {
+	userinfo := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", basicAuthUsername(node), node.Password)))
+	return fmt.Sprintf("socks://%s@%s:%d#%s", userinfo, node.Host, node.Port, url.QueryEscape(node.Name))
+}
+
+// buildHTTPURI 生成 http/https 代理链接，TLS 节点使用 https 方案。
+func (b *GeneralBuilder) buildHTTPURI(node Node) string {
+	scheme := "http"
+	if settingBool(node.Settings, "tls") {
+		scheme = "https"
+	}
+	u := url.URL{
+		Scheme:   scheme,
+		User:     url.UserPassword(basicAuthUsername(node), node.Password),
+		Host:     fmt.Sprintf("%s:%d", node.Host, node.Port),
+		Fragment: node.Name,
+	}
+	if sni := settingString(node.Settings, "tls_settings.server_name"); sni != "" && scheme == "https" {
+		q := u.Query()
+		q.Set("sni", sni)
+		u.RawQuery = q.Encode()
+	}
+	return u.String()
+}
+
 func (b *GeneralBuilder) buildHysteria2URI(node Node) string {
 	u := url.URL{
 		Scheme:   "hysteria2",
@@ -69,6 +69,18 @@ func (m *Manager) Build(req BuildRequest) (*Result, error) {
 	return builder.Build(req)
 }
 
+// TemplateKey 返回与客户端标识匹配的构建器所使用的模板键；构建器不使用模板时返回空串。
+func (m *Manager) TemplateKey(flag string, userAgent string) string {
+	builder := m.matchBuilder(flag, userAgent)
+	if builder == nil {
+		builder = m.defaultBuilder
+	}
+	if keyed, ok := builder.(TemplateKeyer); ok {
+		return keyed.TemplateKey()
+	}
+	return ""
+}
+
 func (m *Manager) matchBuilder(flag string, userAgent string) Builder {
 	combined := strings.ToLower(strings.TrimSpace(flag))
 	if combined == "" {
@@ -21,7 +21,7 @@ type SingboxBuilder struct {
 
 func NewSingboxBuilder() *SingboxBuilder {
 	base := NewBaseBuilder()
-	base.Allow("shadowsocks", "vmess", "trojan", "vless", "hysteria", "tuic", "socks", "http")
+	base.Allow("shadowsocks", "vmess", "trojan", "vless", "hysteria", "tuic", "anytls", "naive", "socks", "http")
 	return &SingboxBuilder{base: base}
 }
 
@@ -29,6 +29,10 @@ func (b *SingboxBuilder) Flags() []string {
 	return []string{"sing-box", "singbox"}
 }
 
+func (b *SingboxBuilder) TemplateKey() string {
+	return "sing-box"
+}
+
 func (b *SingboxBuilder) Build(req BuildRequest) (*Result, error) {
 	nodes := req.Nodes
 	if b.base != nil {
@@ -48,6 +52,10 @@ func (b *SingboxBuilder) Build(req BuildRequest) (*Result, error) {
 		if tag, ok := outbound["tag"].(string); ok {
 			proxyTags = append(proxyTags, tag)
 		}
+		// ShadowTLS 握手出站仅作为 detour，不加入选择器
+		if detour := buildSingboxShadowTLSOutbound(node); detour != nil {
+			outbounds = append(outbounds, detour)
+		}
 	}
 
 	config := b.loadTemplateConfig(req.Templates["sing-box"])
@@ -193,7 +201,10 @@ func buildSingboxOutbound(node Node) map[string]any {
 		base["server_port"] = node.Port
 		base["method"] = settingString(node.Settings, "cipher")
 		base["password"] = node.Password
-		if plugin := settingString(node.Settings, "plugin"); plugin != "" {
+		if isShadowTLSPlugin(settingString(node.Settings, "plugin")) {
+			// 经 shadowtls 出站完成握手后再承载 shadowsocks 流量
+			base["detour"] = shadowTLSDetourTag(node)
+		} else if plugin := settingString(node.Settings, "plugin"); plugin != "" {
 			base["plugin"] = plugin
 			base["plugin_opts"] = settingString(node.Settings, "plugin_opts") // Singbox might expect simpler opts
 		}
@@ -397,6 +408,58 @@ func buildSingboxOutbound(node Node) map[string]any {
 		}
 		base["tls"] = tls
 
+	case "anytls":
+		// AnyTLS 必须运行在 TLS 之上
+		base["type"] = "anytls"
+		base["server"] = node.Host
+		base["server_port"] = node.Port
+		base["password"] = node.Password
+
+		tls := map[string]any{
+			"enabled":  true,
+			"insecure": settingBool(node.Settings, "tls.allow_insecure"),
+		}
+		if sni := settingString(node.Settings, "tls.server_name"); sni != "" {
+			tls["server_name"] = sni
+		}
+		base["tls"] = tls
+
+	case "socks", "http":
+		base["type"] = strings.ToLower(node.Type)
+		base["server"] = node.Host
+		base["server_port"] = node.Port
+		base["username"] = basicAuthUsername(node)
+		base["password"] = node.Password
+		if base["type"] == "socks" {
+			base["version"] = "5"
+		} else if settingBool(node.Settings, "tls") {
+			tls := map[string]any{
+				"enabled":  true,
+				"insecure": settingBool(node.Settings, "tls_settings.allow_insecure"),
+			}
+			if sni := settingString(node.Settings, "tls_settings.server_name"); sni != "" {
+				tls["server_name"] = sni
+			}
+			base["tls"] = tls
+		}
+
+	case "naive":
+		// naive 出站需要 sing-box 以 with_naive_outbound 构建，服务端强制 TLS
+		base["type"] = "naive"
+		base["server"] = node.Host
+		base["server_port"] = node.Port
+		base["username"] = node.Username
+		base["password"] = node.Password
+
+		tls := map[string]any{
+			"enabled":  true,
+			"insecure": settingBool(node.Settings, "tls_settings.allow_insecure"),
+		}
+		if sni := settingString(node.Settings, "tls_settings.server_name"); sni != "" {
+			tls["server_name"] = sni
+		}
+		base["tls"] = tls
+
 	default:
 		return nil
 	}
@@ -404,6 +467,36 @@ func buildSingboxOutbound(node Node) map[string]any {
 	return base
 }
 
+// buildSingboxShadowTLSOutbound 为使用 shadow-tls 插件的 shadowsocks 节点生成握手出站。
+func buildSingboxShadowTLSOutbound(node Node) map[string]any {
+	if strings.ToLower(node.Type) != "shadowsocks" || !isShadowTLSPlugin(settingString(node.Settings, "plugin")) {
+		return nil
+	}
+	opts, version := shadowTLSPluginOptions(node)
+	outbound := map[string]any{
+		"type":        "shadowtls",
+		"tag":         shadowTLSDetourTag(node),
+		"server":      node.Host,
+		"server_port": node.Port,
+		"version":     version,
+		"password":    opts["password"],
+	}
+	tls := map[string]any{"enabled": true}
+	if host := opts["host"]; host != "" {
+		tls["server_name"] = host
+	}
+	if version >= 3 {
+		// v3 需要 uTLS 指纹以通过握手校验
+		tls["utls"] = map[string]any{"enabled": true, "fingerprint": "chrome"}
+	}
+	outbound["tls"] = tls
+	return outbound
+}
+
+func shadowTLSDetourTag(node Node) string {
+	return node.Name + "-shadowtls"
+}
+
 func cloneOutbounds(value any) []map[string]any {
 	var result []map[string]any
 	switch v := value.(type) {
@@ -33,6 +33,10 @@ func (b *SurgeBuilder) Flags() []string {
 	return []string{"surge"}
 }
 
+func (b *SurgeBuilder) TemplateKey() string {
+	return "surge"
+}
+
 func (b *SurgeBuilder) Build(req BuildRequest) (*Result, error) {
 	nodes := req.Nodes
 	if b.base != nil {
@@ -31,6 +31,9 @@ type Node struct {
 	Settings    map[string]any
 	RawSettings json.RawMessage
 	Password    string
+	Username    string // basic-auth 用户名（naive）
+	// ShadowTLSPassword 为 shadow-tls v3 的用户级密码（用户 UUID），与服务端 shadowtls 入站用户一致
+	ShadowTLSPassword string
 }
 
 // BuildRequest carries all contextual data for generating subscription payloads.
@@ -73,3 +76,9 @@ type Builder interface {
 	Flags() []string
 	Build(req BuildRequest) (*Result, error)
 }
+
+// TemplateKeyer is implemented by builders that render from a subscription template;
+// TemplateKey is the key they read from BuildRequest.Templates.
+type TemplateKeyer interface {
+	TemplateKey() string
+}
@@ -4,6 +4,7 @@ package protocol
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -134,3 +135,52 @@ func buildUserHeaders(user *repository.User, lang string, i18nMgr *i18n.Manager)
 
 	return headers
 }
+
+// basicAuthUsername 返回 naive/socks/http 的 basic-auth 用户名：面板生成的入站以用户邮箱为用户名，
+// 缺失时回退为密码（旧版节点以 UUID 同时作为用户名与密码）。
+func basicAuthUsername(node Node) string {
+	if node.Username != "" {
+		return node.Username
+	}
+	return node.Password
+}
+
+// isShadowTLSPlugin 判断 shadowsocks 插件是否为 shadow-tls。
+func isShadowTLSPlugin(plugin string) bool {
+	switch strings.ToLower(strings.TrimSpace(plugin)) {
+	case "shadow-tls", "shadowtls":
+		return true
+	}
+	return false
+}
+
+// shadowTLSPluginOptions 解析 shadow-tls 插件参数并返回协议版本（默认 v3）。
+// v3 为用户级密码，使用面板按用户派生的 ShadowTLSPassword 覆盖共享密码，与服务端 shadowtls 入站用户一致。
+func shadowTLSPluginOptions(node Node) (map[string]string, int) {
+	opts := parsePluginOptions(settingString(node.Settings, "plugin_opts"))
+	if opts == nil {
+		opts = map[string]string{}
+	}
+	version := 3
+	if v, err := strconv.Atoi(opts["version"]); err == nil && v > 0 {
+		version = v
+	}
+	if version >= 3 && node.ShadowTLSPassword != "" {
+		opts["password"] = node.ShadowTLSPassword
+	}
+	return opts, version
+}
+
+// formatPluginOptions 按键名排序拼接插件参数，格式与 parsePluginOptions 互逆。
+func formatPluginOptions(opts map[string]string) string {
+	keys := make([]string, 0, len(opts))
+	for key := range opts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+opts[key])
+	}
+	return strings.Join(pairs, ";")
+}
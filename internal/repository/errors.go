@@ -7,4 +7,6 @@ import "errors"
 var (
 	// ErrNotFound 表示查询未返回数据。
 	ErrNotFound = errors.New("not found / 未找到数据")
+	// ErrConflict 表示记录在读取后已被其他人修改，条件更新未生效。
+	ErrConflict = errors.New("conflict / 数据已被修改")
 )
@@ -4,12 +4,49 @@ package repository
 
 // UserSearchFilter constrains admin user listings.
 type UserSearchFilter struct {
-	Keyword string // Changed from Query to Keyword to match usage
-	Status  *int
-	PlanID  *int64
-	Limit   int
-	Offset  int
-}
+	Keyword            string // Changed from Query to Keyword to match usage
+	Status             *int
+	PlanID             *int64
+	Banned             *bool
+	OverQuota          bool  // 已用流量达到或超过配额
+	ExpiringWithinDays int   // 大于 0 时只返回该天数内到期的用户
+	Now                int64 // 计算到期窗口的基准时间，0 表示当前时间
+	SortBy             string
+	SortOrder          string // asc / desc，默认 desc
+	Limit              int
+	Offset             int
+}
+
+// Sort keys accepted by UserSearchFilter.SortBy; unknown keys fall back to id.
+const (
+	UserSortID          = "id"
+	UserSortTrafficUsed = "traffic_used"
+	UserSortExpiredAt   = "expired_at"
+	UserSortLastLoginAt = "last_login_at"
+	UserSortCreatedAt   = "created_at"
+
+	SortOrderAsc  = "asc"
+	SortOrderDesc = "desc"
+)
+
+// AgentHostSearchFilter constrains admin agent host listings.
+type AgentHostSearchFilter struct {
+	Keyword string // 匹配名称或地址
+	State   string // online / warning / offline / maintenance，空表示不过滤
+	Now     int64  // 计算心跳状态的基准时间
+	// SortBy 取 AgentHostSort* 之一，未知值按 id 排序；SortOrder 为 asc / desc，默认 asc
+	SortBy    string
+	SortOrder string
+	Limit     int
+	Offset    int
+}
+
+// Sort keys accepted by AgentHostSearchFilter.SortBy.
+const (
+	AgentHostSortID              = "id"
+	AgentHostSortName            = "name"
+	AgentHostSortConnectionCount = "connection_count"
+)
 
 // StatUserSumFilter constrains traffic summations.
 type StatUserSumFilter struct {
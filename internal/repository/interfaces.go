@@ -12,6 +12,8 @@ type Store interface {
 	CoreOperations() CoreOperationRepository
 	OperationLogs() OperationLogRepository
 	BinaryVersionStates() BinaryVersionStateRepository
+	AgentHostDiagnostics() AgentHostDiagnosticsRepository
+	AgentHostPathUsage() AgentHostPathUsageRepository
 	AgentLifecycleOperations() AgentLifecycleOperationRepository
 	AgentTrafficPolicies() AgentTrafficPolicyRepository
 	AgentTrafficStates() AgentTrafficStateRepository
@@ -23,6 +25,9 @@ type Store interface {
 	Plugins() PluginRepository
 	Plans() PlanRepository
 	LoginLogs() LoginLogRepository
+	LoginLockouts() LoginLockoutRepository
+	AuditLogs() AuditLogRepository
+	UserReminders() UserReminderRepository
 	Tokens() TokenRepository
 	Servers() ServerRepository
 	ServerGroups() ServerGroupRepository
@@ -34,6 +39,7 @@ type Store interface {
 	SubscriptionLogs() SubscriptionLogRepository
 	AgentHosts() AgentHostRepository
 	ConfigTemplates() ConfigTemplateRepository
+	ConfigTemplateVersions() ConfigTemplateVersionRepository
 	UserTraffic() UserTrafficRepository
 	ShortLinks() ShortLinkRepository
 	SubscriptionTemplates() SubscriptionTemplateRepository
@@ -47,6 +53,7 @@ type Store interface {
 	DesiredArtifacts() DesiredArtifactRepository
 	ApplyRuns() ApplyRunRepository
 	TrafficReportDedups() TrafficReportDedupRepository
+	UserOnlineDevices() UserOnlineDeviceRepository
 	AgentConfigInventories() AgentConfigInventoryRepository
 	InboundIndexes() InboundIndexRepository
 	DriftStates() DriftStateRepository
@@ -83,6 +90,19 @@ type BinaryVersionStateRepository interface {
 	UpdateCheckResult(ctx context.Context, agentHostID int64, component, remoteVersion, status, checkError string, checkedAt int64) error
 }
 
+// AgentHostDiagnosticsRepository stores the latest self-diagnostics bundle per agent host.
+type AgentHostDiagnosticsRepository interface {
+	Upsert(ctx context.Context, diag *AgentHostDiagnostics) error
+	FindByAgentHostID(ctx context.Context, agentHostID int64) (*AgentHostDiagnostics, error)
+}
+
+// AgentHostPathUsageRepository stores the latest disk usage of watched paths per agent host.
+type AgentHostPathUsageRepository interface {
+	// ReplaceForHost replaces all rows of a host so paths removed from the agent config disappear.
+	ReplaceForHost(ctx context.Context, agentHostID int64, usages []*AgentHostPathUsage) error
+	ListByAgentHostID(ctx context.Context, agentHostID int64) ([]*AgentHostPathUsage, error)
+}
+
 // AgentLifecycleOperationRepository manages panel-issued agent lifecycle commands.
 type AgentLifecycleOperationRepository interface {
 	Create(ctx context.Context, operation *AgentLifecycleOperation) error
@@ -136,7 +156,14 @@ type UserRepository interface {
 	FindByEmail(ctx context.Context, email string) (*User, error)
 	FindByUsername(ctx context.Context, username string) (*User, error)
 	FindByToken(ctx context.Context, token string) (*User, error)
+	FindByUUID(ctx context.Context, uuid string) (*User, error)
+	FindByTelegramID(ctx context.Context, telegramID int64) (*User, error)
+	ListExpiringBetween(ctx context.Context, startUnix, endUnix int64) ([]*User, error)
+	ListTrafficRemainingBelow(ctx context.Context, thresholdBytes int64, nowUnix int64) ([]*User, error)
 	Save(ctx context.Context, user *User) error
+	// SaveIfUnmodified 仅当库中 updated_at 仍等于 expectedUpdatedAt 时保存，否则返回 ErrConflict
+	SaveIfUnmodified(ctx context.Context, user *User, expectedUpdatedAt int64) error
+	SaveBatch(ctx context.Context, users []*User) error
 	Create(ctx context.Context, user *User) (*User, error)
 	HasAdmin(ctx context.Context) (bool, error)
 	ActiveCountByPlan(ctx context.Context, planID int64, nowUnix int64) (int64, error)
@@ -219,6 +246,10 @@ type ServerGroupRepository interface {
 type ServerRouteRepository interface {
 	List(ctx context.Context) ([]*ServerRoute, error)
 	FindByIDs(ctx context.Context, ids []int64) ([]*ServerRoute, error)
+	FindByID(ctx context.Context, id int64) (*ServerRoute, error)
+	Create(ctx context.Context, route *ServerRoute) (*ServerRoute, error)
+	Update(ctx context.Context, route *ServerRoute) error
+	Delete(ctx context.Context, id int64) error
 }
 
 // StatUserRepository 管理用户流量聚合统计。
@@ -274,18 +305,60 @@ type LoginLogRepository interface {
 	Create(ctx context.Context, log *LoginLog) error
 }
 
+// LoginLockoutRepository 记录按 账号+IP 统计的登录失败与锁定状态。
+type LoginLockoutRepository interface {
+	// Find 返回指定账号+IP 的记录，不存在时返回 ErrNotFound
+	Find(ctx context.Context, identifier, ip string) (*LoginLockout, error)
+	// FindActive 返回账号下锁定截止时间最晚且仍在锁定中的记录，不存在时返回 ErrNotFound
+	FindActive(ctx context.Context, identifier string, now int64) (*LoginLockout, error)
+	// Upsert 按 账号+IP 写入记录
+	Upsert(ctx context.Context, lockout *LoginLockout) error
+	// List 按条件分页查询（管理端）
+	List(ctx context.Context, filter LoginLockoutFilter) ([]*LoginLockout, error)
+	// Count 统计符合条件的记录数
+	Count(ctx context.Context, filter LoginLockoutFilter) (int64, error)
+	// DeleteByIdentifier 清除账号的全部失败与锁定记录，返回删除条数
+	DeleteByIdentifier(ctx context.Context, identifier string) (int64, error)
+}
+
+// AuditLogRepository 保存管理员变更审计日志，只提供追加与查询，不允许修改或删除。
+type AuditLogRepository interface {
+	Append(ctx context.Context, log *AuditLog) error
+	// List 按时间倒序分页查询
+	List(ctx context.Context, filter AuditLogFilter) ([]*AuditLog, error)
+	Count(ctx context.Context, filter AuditLogFilter) (int64, error)
+}
+
+// UserReminderRepository 记录到期/流量等提醒的最近发送时间。
+type UserReminderRepository interface {
+	// LastReminded 返回指定类型下各用户最近一次提醒时间，未提醒过的用户不在结果中
+	LastReminded(ctx context.Context, kind string, userIDs []int64) (map[int64]int64, error)
+	// MarkReminded 记录用户在该类型下的提醒时间
+	MarkReminded(ctx context.Context, userID int64, kind string, remindedAt int64) error
+}
+
 // TokenRepository 管理访问/刷新令牌。
 type TokenRepository interface {
 	Create(ctx context.Context, token *AccessToken) (*AccessToken, error)
 	FindByRefreshToken(ctx context.Context, refreshToken string) (*AccessToken, error)
 	DeleteByRefreshToken(ctx context.Context, refreshToken string) error
 	DeleteByUser(ctx context.Context, userID int64) error
+	// MarkRotated 原子地将未轮换、未吊销的令牌标记为已轮换；返回 false 表示令牌已被使用过
+	MarkRotated(ctx context.Context, id int64, rotatedAt int64) (bool, error)
+	// RevokeFamily 吊销同一族下的全部令牌
+	RevokeFamily(ctx context.Context, familyID string) error
 }
 
 // SubscriptionLogRepository 记录订阅访问日志。
 type SubscriptionLogRepository interface {
 	Log(ctx context.Context, log *SubscriptionLog) error
 	GetRecentLogs(ctx context.Context, userID int64, limit int) ([]*SubscriptionLog, error)
+	// CountUniqueIPsByUser 按用户统计窗口内的不同 IP 数，按 IP 数降序。
+	CountUniqueIPsByUser(ctx context.Context, filter SubscriptionLogFilter) ([]*SubscriptionUserIPCount, error)
+	// CountByClientType 按客户端类型统计窗口内的拉取次数与用户数。
+	CountByClientType(ctx context.Context, filter SubscriptionLogFilter) ([]*SubscriptionClientCount, error)
+	// ListDistinctIPs 列出单个用户窗口内的不同 IP 及首末次出现时间，按最近出现降序。
+	ListDistinctIPs(ctx context.Context, filter SubscriptionLogFilter) ([]*SubscriptionIPActivity, error)
 }
 
 // StatServerRepository 管理节点维度统计。
@@ -319,14 +392,21 @@ type AgentHostRepository interface {
 	FindByID(ctx context.Context, id int64) (*AgentHost, error)
 	FindByHost(ctx context.Context, host string) (*AgentHost, error)
 	FindByToken(ctx context.Context, token string) (*AgentHost, error)
+	// FindByPreviousToken 查找轮换后旧令牌仍在宽限期内（过期时间晚于 now）的节点。
+	FindByPreviousToken(ctx context.Context, token string, now int64) (*AgentHost, error)
 	Update(ctx context.Context, host *AgentHost) error
 	Delete(ctx context.Context, id int64) error
 	ListAll(ctx context.Context) ([]*AgentHost, error)
+	Search(ctx context.Context, filter AgentHostSearchFilter) ([]*AgentHost, error)
+	CountFiltered(ctx context.Context, filter AgentHostSearchFilter) (int64, error)
 
 	// 状态更新
 	UpdateStatus(ctx context.Context, id int64, status int, heartbeatAt int64) error
 	UpdateMetrics(ctx context.Context, id int64, metrics AgentHostMetrics) error
 	UpdateCapabilities(ctx context.Context, id int64, coreVersion string, capabilities, buildTags []string) error
+	UpdateDriftDetected(ctx context.Context, id int64, detected bool) error
+	UpdateIntervals(ctx context.Context, id int64, syncSeconds, reportSeconds int) error
+	RotateToken(ctx context.Context, id int64, token, previousToken string, previousExpiresAt int64) error
 
 	// 统计查询
 	Count(ctx context.Context) (int64, error)
@@ -336,12 +416,21 @@ type AgentHostRepository interface {
 // ConfigTemplateRepository 管理配置模板数据。
 type ConfigTemplateRepository interface {
 	Create(ctx context.Context, tpl *ConfigTemplate) error
-	Update(ctx context.Context, tpl *ConfigTemplate) error
+	// Update 保存模板；expectedUpdatedAt 大于 0 时仅当库中 updated_at 仍等于该值才写入，否则返回 ErrConflict
+	Update(ctx context.Context, tpl *ConfigTemplate, expectedUpdatedAt int64) error
 	Delete(ctx context.Context, id int64) error
 	FindByID(ctx context.Context, id int64) (*ConfigTemplate, error)
 	ListAll(ctx context.Context) ([]*ConfigTemplate, error)
 }
 
+// ConfigTemplateVersionRepository manages immutable config template versions.
+type ConfigTemplateVersionRepository interface {
+	Create(ctx context.Context, version *ConfigTemplateVersion) error
+	FindByTemplateAndVersion(ctx context.Context, templateID, version int64) (*ConfigTemplateVersion, error)
+	ListByTemplateID(ctx context.Context, templateID int64, limit, offset int) ([]*ConfigTemplateVersion, error)
+	GetMaxVersion(ctx context.Context, templateID int64) (int64, error)
+}
+
 // AgentHostMetrics contains real-time metrics reported by an agent.
 type AgentHostMetrics struct {
 	CPUTotal              float64
@@ -361,6 +450,8 @@ type AgentHostMetrics struct {
 	LastRestartAt         int64
 	AgentVersion          string
 	CurrentCoreType       string
+	ConnectionCount       int64
+	ConnectionCountSource string
 }
 
 // ServerClientConfigRepository 管理客户端订阅配置。
@@ -399,7 +490,17 @@ type UserTrafficRepository interface {
 	IncrementPeriodTraffic(ctx context.Context, userID int64, uploadDelta, downloadDelta int64) error
 	MarkPeriodExceeded(ctx context.Context, userID int64, periodStart int64) error
 	GetExpiredPeriodUserIDs(ctx context.Context, nowUnix int64) ([]int64, error)
-	ApplyTrafficBatchAtomic(ctx context.Context, traffic []UserTrafficDelta, nowUnix int64) ([]UserTrafficDelta, []int64, error)
+	// ApplyTrafficBatchAtomic 在同一事务内累加流量；warnPercent 为软限额百分比（0 表示关闭），每个周期只报告一次。
+	// key 非空时去重标记与入账在同一事务内提交，已标记的批次返回 Duplicate 且不做任何修改。
+	ApplyTrafficBatchAtomic(ctx context.Context, key *TrafficBatchKey, traffic []UserTrafficDelta, nowUnix int64, warnPercent int) (*TrafficBatchResult, error)
+
+	// 套餐周期重置相关操作
+	ListResetStates(ctx context.Context, planID int64) ([]UserTrafficResetState, error)
+	// ResetCycleTraffic 在同一事务内读取并归档 u/d、清零、解除超额，并以 reset.PeriodEnd 为起点开启新的流量周期。
+	// 归档值以事务内读取为准（回填到 reset），该套餐在此边界已归档过时不做任何修改。
+	ResetCycleTraffic(ctx context.Context, reset *UserTrafficReset, nextBoundary int64) error
+	ListResets(ctx context.Context, filter UserTrafficResetFilter) ([]UserTrafficReset, error)
+	CountResets(ctx context.Context, filter UserTrafficResetFilter) (int64, error)
 
 	// 查询相关操作
 	GetExceededUserIDs(ctx context.Context) ([]int64, error)
@@ -432,6 +533,15 @@ type ShortLinkRepository interface {
 	// IncrementAccessCount 增加访问次数并更新最近访问时间
 	IncrementAccessCount(ctx context.Context, id int64, accessTime int64) error
 
+	// AddAccessCount 批量累加访问次数，最近访问时间只前进不回退
+	AddAccessCount(ctx context.Context, id int64, delta int64, lastAccessedAt int64) error
+
+	// List 按条件分页查询短链接（管理端）
+	List(ctx context.Context, filter ShortLinkFilter) ([]*ShortLink, error)
+
+	// Count 统计符合条件的短链接数量
+	Count(ctx context.Context, filter ShortLinkFilter) (int64, error)
+
 	// CodeExists 判断短码是否已存在
 	CodeExists(ctx context.Context, code string) (bool, error)
 }
@@ -480,6 +590,9 @@ type ForwardingRuleRepository interface {
 
 	// 冲突检测
 	CheckPortConflict(ctx context.Context, agentHostID int64, listenPort int, protocol string, excludeID int64) (bool, error)
+
+	// 健康状态（不改变规则版本，避免触发 Agent 重新同步）
+	UpdateTargetHealth(ctx context.Context, id int64, health []ForwardingTargetHealth) error
 }
 
 // ForwardingRuleLogFilter 定义转发规则日志筛选条件。
@@ -544,7 +657,13 @@ type AccessLogRepository interface {
 	List(ctx context.Context, filter AccessLogFilter) ([]*AccessLog, error)
 	Count(ctx context.Context, filter AccessLogFilter) (int64, error)
 	DeleteByRetentionDays(ctx context.Context, days int) (int64, error)
+	// DeleteBeforeBatch removes at most limit logs created before the given unix time.
+	DeleteBeforeBatch(ctx context.Context, before int64, limit int) (int64, error)
 	GetStats(ctx context.Context, filter AccessLogFilter) (*AccessLogStats, error)
+	// TopTargets ranks target domains (falling back to target IP) by connection count.
+	TopTargets(ctx context.Context, filter AccessLogTopFilter) ([]*AccessLogAggregate, error)
+	// TopSources ranks source IPs by connection count.
+	TopSources(ctx context.Context, filter AccessLogTopFilter) ([]*AccessLogAggregate, error)
 }
 
 // InboundSpecRepository manages desired inbound specs.
@@ -589,6 +708,20 @@ type ApplyRunRepository interface {
 type TrafficReportDedupRepository interface {
 	// MarkHandled records report_id for an agent host. Returns false if already exists.
 	MarkHandled(ctx context.Context, agentHostID int64, reportID string, handledAt int64) (bool, error)
+	// DeleteHandledBefore removes keys handled before the given unix time and returns the number removed.
+	DeleteHandledBefore(ctx context.Context, before int64) (int64, error)
+}
+
+// UserOnlineDeviceRepository tracks online devices reported by agents.
+type UserOnlineDeviceRepository interface {
+	// UpsertBatch inserts tuples or refreshes last_seen_at for existing ones.
+	UpsertBatch(ctx context.Context, devices []*UserOnlineDevice) error
+	// CountDistinctIPs returns distinct IP count per user seen at or after since.
+	CountDistinctIPs(ctx context.Context, userIDs []int64, since int64) (map[int64]int, error)
+	// ListOverDeviceLimit returns users among userIDs whose distinct IP count since the given time exceeds their device_limit.
+	ListOverDeviceLimit(ctx context.Context, userIDs []int64, since int64) ([]int64, error)
+	// DeleteBefore removes tuples last seen before the given unix time.
+	DeleteBefore(ctx context.Context, before int64) (int64, error)
 }
 
 // AgentConfigInventoryRepository manages applied file inventory.
@@ -22,14 +22,14 @@ func (r *accessLogRepo) Create(ctx context.Context, log *repository.AccessLog) e
 
 	query := `
 		INSERT INTO access_logs (
-			user_id, user_email, agent_host_id, source_ip, target_domain,
-			target_ip, target_port, protocol, upload, download,
+			user_id, user_email, agent_host_id, source_ip, source_country, source_asn,
+			target_domain, target_ip, target_port, protocol, upload, download,
 			connection_start, connection_end, created_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	result, err := r.db.ExecContext(ctx, query,
-		log.UserID, log.UserEmail, log.AgentHostID, log.SourceIP, log.TargetDomain,
+		log.UserID, log.UserEmail, log.AgentHostID, log.SourceIP, log.SourceCountry, log.SourceASN, log.TargetDomain,
 		log.TargetIP, log.TargetPort, log.Protocol, log.Upload, log.Download,
 		log.ConnectionStart, log.ConnectionEnd, log.CreatedAt,
 	)
@@ -59,10 +59,10 @@ func (r *accessLogRepo) BatchCreate(ctx context.Context, logs []*repository.Acce
 	now := time.Now().Unix()
 	stmt, err := tx.PrepareContext(ctx, `
 		INSERT INTO access_logs (
-			user_id, user_email, agent_host_id, source_ip, target_domain,
-			target_ip, target_port, protocol, upload, download,
+			user_id, user_email, agent_host_id, source_ip, source_country, source_asn,
+			target_domain, target_ip, target_port, protocol, upload, download,
 			connection_start, connection_end, created_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		return err
@@ -72,7 +72,7 @@ func (r *accessLogRepo) BatchCreate(ctx context.Context, logs []*repository.Acce
 	for _, log := range logs {
 		log.CreatedAt = now
 		_, err := stmt.ExecContext(ctx,
-			log.UserID, log.UserEmail, log.AgentHostID, log.SourceIP, log.TargetDomain,
+			log.UserID, log.UserEmail, log.AgentHostID, log.SourceIP, log.SourceCountry, log.SourceASN, log.TargetDomain,
 			log.TargetIP, log.TargetPort, log.Protocol, log.Upload, log.Download,
 			log.ConnectionStart, log.ConnectionEnd, log.CreatedAt,
 		)
@@ -106,6 +106,10 @@ func (r *accessLogRepo) buildFilter(filter repository.AccessLogFilter) (string,
 		query.WriteString(" AND source_ip = ?")
 		args = append(args, *filter.SourceIP)
 	}
+	if filter.SourceCountry != nil && *filter.SourceCountry != "" {
+		query.WriteString(" AND source_country = ?")
+		args = append(args, strings.ToUpper(*filter.SourceCountry))
+	}
 	if filter.Protocol != nil && *filter.Protocol != "" {
 		query.WriteString(" AND protocol = ?")
 		args = append(args, *filter.Protocol)
@@ -126,7 +130,7 @@ func (r *accessLogRepo) List(ctx context.Context, filter repository.AccessLogFil
 	where, args := r.buildFilter(filter)
 
 	query := `
-		SELECT id, user_id, user_email, agent_host_id, source_ip, target_domain,
+		SELECT id, user_id, user_email, agent_host_id, source_ip, source_country, source_asn, target_domain,
 		       target_ip, target_port, protocol, upload, download,
 		       connection_start, connection_end, created_at
 		FROM access_logs
@@ -163,6 +167,19 @@ func (r *accessLogRepo) DeleteByRetentionDays(ctx context.Context, days int) (in
 	return result.RowsAffected()
 }
 
+func (r *accessLogRepo) DeleteBeforeBatch(ctx context.Context, before int64, limit int) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM access_logs WHERE id IN (
+			SELECT id FROM access_logs WHERE created_at < ? ORDER BY id LIMIT ?
+		)
+	`, before, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
 func (r *accessLogRepo) GetStats(ctx context.Context, filter repository.AccessLogFilter) (*repository.AccessLogStats, error) {
 	where, args := r.buildFilter(filter)
 
@@ -180,6 +197,51 @@ func (r *accessLogRepo) GetStats(ctx context.Context, filter repository.AccessLo
 	return stats, nil
 }
 
+func (r *accessLogRepo) TopTargets(ctx context.Context, filter repository.AccessLogTopFilter) ([]*repository.AccessLogAggregate, error) {
+	return r.top(ctx, "COALESCE(NULLIF(target_domain, ''), target_ip)", filter)
+}
+
+func (r *accessLogRepo) TopSources(ctx context.Context, filter repository.AccessLogTopFilter) ([]*repository.AccessLogAggregate, error) {
+	return r.top(ctx, "source_ip", filter)
+}
+
+// top groups logs by keyExpr within a connection_start range so the
+// (agent_host_id, connection_start) index can serve the scan.
+func (r *accessLogRepo) top(ctx context.Context, keyExpr string, filter repository.AccessLogTopFilter) ([]*repository.AccessLogAggregate, error) {
+	where := " WHERE connection_start >= ? AND connection_start < ?"
+	args := []interface{}{filter.Since, filter.Until}
+	if filter.AgentHostID != nil {
+		where += " AND agent_host_id = ?"
+		args = append(args, *filter.AgentHostID)
+	}
+	args = append(args, filter.Limit)
+
+	query := `
+		SELECT ` + keyExpr + ` AS agg_key, COUNT(*) AS connections,
+			COALESCE(SUM(upload), 0), COALESCE(SUM(download), 0)
+		FROM access_logs` + where + `
+		GROUP BY agg_key
+		HAVING agg_key IS NOT NULL AND agg_key != ''
+		ORDER BY connections DESC, SUM(upload) + SUM(download) DESC
+		LIMIT ?`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*repository.AccessLogAggregate
+	for rows.Next() {
+		var item repository.AccessLogAggregate
+		if err := rows.Scan(&item.Key, &item.Connections, &item.Upload, &item.Download); err != nil {
+			return nil, err
+		}
+		result = append(result, &item)
+	}
+	return result, rows.Err()
+}
+
 func (r *accessLogRepo) scanLogs(rows *sql.Rows) ([]*repository.AccessLog, error) {
 	var logs []*repository.AccessLog
 	for rows.Next() {
@@ -189,7 +251,7 @@ func (r *accessLogRepo) scanLogs(rows *sql.Rows) ([]*repository.AccessLog, error
 		var connEnd sql.NullInt64
 
 		err := rows.Scan(
-			&log.ID, &userID, &log.UserEmail, &log.AgentHostID, &log.SourceIP, &log.TargetDomain,
+			&log.ID, &userID, &log.UserEmail, &log.AgentHostID, &log.SourceIP, &log.SourceCountry, &log.SourceASN, &log.TargetDomain,
 			&log.TargetIP, &log.TargetPort, &log.Protocol, &log.Upload, &log.Download,
 			&connStart, &connEnd, &log.CreatedAt,
 		)
@@ -49,7 +49,9 @@ func (r *agentCoreInstanceRepo) Update(ctx context.Context, instance *repository
 		UPDATE agent_core_instances SET
 			agent_host_id = ?, instance_id = ?, core_type = ?, status = ?, listen_ports = ?,
 			config_template_id = ?, config_hash = ?, started_at = ?, last_heartbeat_at = ?,
-			error_message = ?, core_snapshot = ?, updated_at = ?
+			error_message = ?, core_snapshot = ?, cpu_percent = ?, memory_rss_bytes = ?,
+			resource_sampled_at = ?, restart_count = ?, restart_window_seconds = ?, last_restart_at = ?,
+			uptime_seconds = ?, flapping = ?, updated_at = ?
 		WHERE id = ?
 	`,
 		instance.AgentHostID,
@@ -63,6 +65,14 @@ func (r *agentCoreInstanceRepo) Update(ctx context.Context, instance *repository
 		optionalInt64(instance.LastHeartbeatAt),
 		instance.ErrorMessage,
 		snapshotJSON,
+		instance.CPUPercent,
+		instance.MemoryRSSBytes,
+		instance.ResourceSampledAt,
+		instance.RestartCount,
+		instance.RestartWindowSeconds,
+		instance.LastRestartAt,
+		instance.UptimeSeconds,
+		instance.Flapping,
 		instance.UpdatedAt,
 		instance.ID,
 	)
@@ -82,7 +92,8 @@ func (r *agentCoreInstanceRepo) ReplaceSnapshot(ctx context.Context, agentHostID
 	currentRows, err := tx.QueryContext(ctx, `
 		SELECT id, agent_host_id, instance_id, core_type, status, listen_ports,
 			config_template_id, config_hash, started_at, last_heartbeat_at,
-			error_message, core_snapshot, created_at, updated_at
+			error_message, core_snapshot, cpu_percent, memory_rss_bytes, resource_sampled_at,
+			restart_count, restart_window_seconds, last_restart_at, uptime_seconds, flapping, created_at, updated_at
 		FROM agent_core_instances WHERE agent_host_id = ?
 	`, agentHostID)
 	if err != nil {
@@ -159,7 +170,8 @@ func (r *agentCoreInstanceRepo) FindByID(ctx context.Context, id int64) (*reposi
 	row := r.db.QueryRowContext(ctx, `
 		SELECT id, agent_host_id, instance_id, core_type, status, listen_ports,
 			config_template_id, config_hash, started_at, last_heartbeat_at,
-			error_message, core_snapshot, created_at, updated_at
+			error_message, core_snapshot, cpu_percent, memory_rss_bytes, resource_sampled_at,
+			restart_count, restart_window_seconds, last_restart_at, uptime_seconds, flapping, created_at, updated_at
 		FROM agent_core_instances WHERE id = ?
 	`, id)
 	return r.scanInstance(row)
@@ -169,7 +181,8 @@ func (r *agentCoreInstanceRepo) FindByInstanceID(ctx context.Context, agentHostI
 	row := r.db.QueryRowContext(ctx, `
 		SELECT id, agent_host_id, instance_id, core_type, status, listen_ports,
 			config_template_id, config_hash, started_at, last_heartbeat_at,
-			error_message, core_snapshot, created_at, updated_at
+			error_message, core_snapshot, cpu_percent, memory_rss_bytes, resource_sampled_at,
+			restart_count, restart_window_seconds, last_restart_at, uptime_seconds, flapping, created_at, updated_at
 		FROM agent_core_instances WHERE agent_host_id = ? AND instance_id = ?
 		LIMIT 1
 	`, agentHostID, instanceID)
@@ -180,7 +193,8 @@ func (r *agentCoreInstanceRepo) ListByAgentHostID(ctx context.Context, agentHost
 	rows, err := r.db.QueryContext(ctx, `
 		SELECT id, agent_host_id, instance_id, core_type, status, listen_ports,
 			config_template_id, config_hash, started_at, last_heartbeat_at,
-			error_message, core_snapshot, created_at, updated_at
+			error_message, core_snapshot, cpu_percent, memory_rss_bytes, resource_sampled_at,
+			restart_count, restart_window_seconds, last_restart_at, uptime_seconds, flapping, created_at, updated_at
 		FROM agent_core_instances
 		WHERE agent_host_id = ?
 		ORDER BY id DESC
@@ -234,8 +248,9 @@ func (r *agentCoreInstanceRepo) insertInstance(ctx context.Context, execer sqlEx
 		INSERT INTO agent_core_instances (
 			agent_host_id, instance_id, core_type, status, listen_ports,
 			config_template_id, config_hash, started_at, last_heartbeat_at,
-			error_message, core_snapshot, created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			error_message, core_snapshot, cpu_percent, memory_rss_bytes, resource_sampled_at,
+			restart_count, restart_window_seconds, last_restart_at, uptime_seconds, flapping, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		instance.AgentHostID,
 		instance.InstanceID,
@@ -248,6 +263,14 @@ func (r *agentCoreInstanceRepo) insertInstance(ctx context.Context, execer sqlEx
 		optionalInt64(instance.LastHeartbeatAt),
 		instance.ErrorMessage,
 		snapshotJSON,
+		instance.CPUPercent,
+		instance.MemoryRSSBytes,
+		instance.ResourceSampledAt,
+		instance.RestartCount,
+		instance.RestartWindowSeconds,
+		instance.LastRestartAt,
+		instance.UptimeSeconds,
+		instance.Flapping,
 		instance.CreatedAt,
 		instance.UpdatedAt,
 	)
@@ -263,7 +286,9 @@ func (r *agentCoreInstanceRepo) updateInstanceWithExecutor(ctx context.Context,
 		UPDATE agent_core_instances SET
 			agent_host_id = ?, instance_id = ?, core_type = ?, status = ?, listen_ports = ?,
 			config_template_id = ?, config_hash = ?, started_at = ?, last_heartbeat_at = ?,
-			error_message = ?, core_snapshot = ?, updated_at = ?
+			error_message = ?, core_snapshot = ?, cpu_percent = ?, memory_rss_bytes = ?,
+			resource_sampled_at = ?, restart_count = ?, restart_window_seconds = ?, last_restart_at = ?,
+			uptime_seconds = ?, flapping = ?, updated_at = ?
 		WHERE id = ?
 	`,
 		instance.AgentHostID,
@@ -277,6 +302,14 @@ func (r *agentCoreInstanceRepo) updateInstanceWithExecutor(ctx context.Context,
 		optionalInt64(instance.LastHeartbeatAt),
 		instance.ErrorMessage,
 		snapshotJSON,
+		instance.CPUPercent,
+		instance.MemoryRSSBytes,
+		instance.ResourceSampledAt,
+		instance.RestartCount,
+		instance.RestartWindowSeconds,
+		instance.LastRestartAt,
+		instance.UptimeSeconds,
+		instance.Flapping,
 		instance.UpdatedAt,
 		instance.ID,
 	)
@@ -305,6 +338,14 @@ func (r *agentCoreInstanceRepo) scanInstance(scanner agentCoreInstanceScanner) (
 		&heartbeatAt,
 		&errorMessage,
 		&coreSnapshot,
+		&instance.CPUPercent,
+		&instance.MemoryRSSBytes,
+		&instance.ResourceSampledAt,
+		&instance.RestartCount,
+		&instance.RestartWindowSeconds,
+		&instance.LastRestartAt,
+		&instance.UptimeSeconds,
+		&instance.Flapping,
 		&instance.CreatedAt,
 		&instance.UpdatedAt,
 	)
@@ -368,6 +409,14 @@ func (r *agentCoreInstanceRepo) scanInstanceRow(rows *sql.Rows) (*repository.Age
 		&heartbeatAt,
 		&errorMessage,
 		&coreSnapshot,
+		&instance.CPUPercent,
+		&instance.MemoryRSSBytes,
+		&instance.ResourceSampledAt,
+		&instance.RestartCount,
+		&instance.RestartWindowSeconds,
+		&instance.LastRestartAt,
+		&instance.UptimeSeconds,
+		&instance.Flapping,
 		&instance.CreatedAt,
 		&instance.UpdatedAt,
 	); err != nil {
@@ -448,6 +497,12 @@ func snapshotsEqual(current, next *repository.AgentCoreInstance) bool {
 	if current.AgentHostID != next.AgentHostID || current.InstanceID != next.InstanceID || current.CoreType != next.CoreType || current.Status != next.Status || current.ConfigHash != next.ConfigHash || current.ErrorMessage != next.ErrorMessage {
 		return false
 	}
+	if current.CPUPercent != next.CPUPercent || current.MemoryRSSBytes != next.MemoryRSSBytes || current.ResourceSampledAt != next.ResourceSampledAt {
+		return false
+	}
+	if current.RestartCount != next.RestartCount || current.RestartWindowSeconds != next.RestartWindowSeconds || current.LastRestartAt != next.LastRestartAt || current.UptimeSeconds != next.UptimeSeconds || current.Flapping != next.Flapping {
+		return false
+	}
 	if !equalIntPtr(current.ConfigTemplateID, next.ConfigTemplateID) || !equalIntPtr(current.StartedAt, next.StartedAt) || !equalIntPtr(current.LastHeartbeatAt, next.LastHeartbeatAt) {
 		return false
 	}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/creamcroissant/xboard/internal/bootstrap"
@@ -41,21 +42,21 @@ func (r *agentHostRepo) Create(ctx context.Context, host *repository.AgentHost)
 
 	result, err := r.db.ExecContext(ctx, `
 		INSERT INTO agent_hosts (
-			name, host, token, status, provision_status, template_id, core_version, capabilities, build_tags,
+			name, host, token, status, provision_status, template_id, template_version, core_version, capabilities, build_tags,
 			cpu_total, cpu_used, mem_total, mem_used,
 			disk_total, disk_used, upload_total, download_total,
 			upload_rate_bps, download_rate_bps, raw_upload_total_bytes, raw_download_total_bytes,
 			boot_id, last_realtime_report_at, last_restart_at, agent_version, current_core_type,
-			last_heartbeat_at, created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			maintenance, last_heartbeat_at, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
-		host.Name, host.Host, host.Token, host.Status, host.ProvisionStatus, host.TemplateID,
+		host.Name, host.Host, host.Token, host.Status, host.ProvisionStatus, host.TemplateID, host.TemplateVersion,
 		host.CoreVersion, string(capsJSON), string(tagsJSON),
 		host.CPUTotal, host.CPUUsed, host.MemTotal, host.MemUsed,
 		host.DiskTotal, host.DiskUsed, host.UploadTotal, host.DownloadTotal,
 		host.UploadRateBps, host.DownloadRateBps, host.RawUploadTotalBytes, host.RawDownloadTotalBytes,
 		host.BootID, host.LastRealtimeReportAt, host.LastRestartAt, host.AgentVersion, host.CurrentCoreType,
-		host.LastHeartbeatAt, host.CreatedAt, host.UpdatedAt,
+		host.Maintenance, host.LastHeartbeatAt, host.CreatedAt, host.UpdatedAt,
 	)
 	if err != nil {
 		return err
@@ -71,12 +72,13 @@ func (r *agentHostRepo) Create(ctx context.Context, host *repository.AgentHost)
 
 func (r *agentHostRepo) FindByID(ctx context.Context, id int64) (*repository.AgentHost, error) {
 	row := r.db.QueryRowContext(ctx, `
-		SELECT id, name, host, token, status, provision_status, template_id, core_version, capabilities, build_tags,
+		SELECT id, name, host, token, status, provision_status, template_id, template_version, core_version, capabilities, build_tags,
 			cpu_total, cpu_used, mem_total, mem_used,
 			disk_total, disk_used, upload_total, download_total,
 			upload_rate_bps, download_rate_bps, raw_upload_total_bytes, raw_download_total_bytes,
 			boot_id, last_realtime_report_at, last_restart_at, agent_version, current_core_type,
-			last_heartbeat_at, created_at, updated_at
+			connection_count, connection_count_source,
+			maintenance, drift_detected, sync_interval_seconds, report_interval_seconds, last_heartbeat_at, created_at, updated_at
 		FROM agent_hosts WHERE id = ?
 	`, id)
 
@@ -85,12 +87,13 @@ func (r *agentHostRepo) FindByID(ctx context.Context, id int64) (*repository.Age
 
 func (r *agentHostRepo) FindByHost(ctx context.Context, host string) (*repository.AgentHost, error) {
 	row := r.db.QueryRowContext(ctx, `
-		SELECT id, name, host, token, status, provision_status, template_id, core_version, capabilities, build_tags,
+		SELECT id, name, host, token, status, provision_status, template_id, template_version, core_version, capabilities, build_tags,
 			cpu_total, cpu_used, mem_total, mem_used,
 			disk_total, disk_used, upload_total, download_total,
 			upload_rate_bps, download_rate_bps, raw_upload_total_bytes, raw_download_total_bytes,
 			boot_id, last_realtime_report_at, last_restart_at, agent_version, current_core_type,
-			last_heartbeat_at, created_at, updated_at
+			connection_count, connection_count_source,
+			maintenance, drift_detected, sync_interval_seconds, report_interval_seconds, last_heartbeat_at, created_at, updated_at
 		FROM agent_hosts WHERE host = ?
 	`, host)
 
@@ -99,18 +102,45 @@ func (r *agentHostRepo) FindByHost(ctx context.Context, host string) (*repositor
 
 func (r *agentHostRepo) FindByToken(ctx context.Context, token string) (*repository.AgentHost, error) {
 	row := r.db.QueryRowContext(ctx, `
-		SELECT id, name, host, token, status, provision_status, template_id, core_version, capabilities, build_tags,
+		SELECT id, name, host, token, status, provision_status, template_id, template_version, core_version, capabilities, build_tags,
 			cpu_total, cpu_used, mem_total, mem_used,
 			disk_total, disk_used, upload_total, download_total,
 			upload_rate_bps, download_rate_bps, raw_upload_total_bytes, raw_download_total_bytes,
 			boot_id, last_realtime_report_at, last_restart_at, agent_version, current_core_type,
-			last_heartbeat_at, created_at, updated_at
+			connection_count, connection_count_source,
+			maintenance, drift_detected, sync_interval_seconds, report_interval_seconds, last_heartbeat_at, created_at, updated_at
 		FROM agent_hosts WHERE token = ?
 	`, token)
 
 	return r.scanHost(row)
 }
 
+// FindByPreviousToken 查找轮换后仍处于宽限期的旧令牌对应的节点。
+func (r *agentHostRepo) FindByPreviousToken(ctx context.Context, token string, now int64) (*repository.AgentHost, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT id, name, host, token, status, provision_status, template_id, template_version, core_version, capabilities, build_tags,
+			cpu_total, cpu_used, mem_total, mem_used,
+			disk_total, disk_used, upload_total, download_total,
+			upload_rate_bps, download_rate_bps, raw_upload_total_bytes, raw_download_total_bytes,
+			boot_id, last_realtime_report_at, last_restart_at, agent_version, current_core_type,
+			connection_count, connection_count_source,
+			maintenance, drift_detected, sync_interval_seconds, report_interval_seconds, last_heartbeat_at, created_at, updated_at
+		FROM agent_hosts WHERE previous_token = ? AND previous_token <> '' AND previous_token_expires_at > ?
+	`, token, now)
+
+	return r.scanHost(row)
+}
+
+// RotateToken 替换节点令牌，并保留旧令牌直到 previousExpiresAt。
+func (r *agentHostRepo) RotateToken(ctx context.Context, id int64, token, previousToken string, previousExpiresAt int64) error {
+	return bootstrap.WithSQLiteBusyRetry(func() error {
+		_, err := r.db.ExecContext(ctx, `
+			UPDATE agent_hosts SET token = ?, previous_token = ?, previous_token_expires_at = ?, updated_at = ? WHERE id = ?
+		`, token, previousToken, previousExpiresAt, time.Now().Unix(), id)
+		return err
+	})
+}
+
 func (r *agentHostRepo) Update(ctx context.Context, host *repository.AgentHost) error {
 	host.UpdatedAt = time.Now().Unix()
 
@@ -131,18 +161,18 @@ func (r *agentHostRepo) Update(ctx context.Context, host *repository.AgentHost)
 
 	_, err = r.db.ExecContext(ctx, `
 		UPDATE agent_hosts SET
-			name = ?, host = ?, token = ?, status = ?, provision_status = ?, template_id = ?,
+			name = ?, host = ?, token = ?, status = ?, provision_status = ?, template_id = ?, template_version = ?,
 			core_version = ?, capabilities = ?, build_tags = ?,
 			cpu_total = ?, cpu_used = ?, mem_total = ?, mem_used = ?,
 			disk_total = ?, disk_used = ?, upload_total = ?, download_total = ?,
-			last_heartbeat_at = ?, updated_at = ?
+			maintenance = ?, last_heartbeat_at = ?, updated_at = ?
 		WHERE id = ?
 	`,
-		host.Name, host.Host, host.Token, host.Status, host.ProvisionStatus, host.TemplateID,
+		host.Name, host.Host, host.Token, host.Status, host.ProvisionStatus, host.TemplateID, host.TemplateVersion,
 		host.CoreVersion, string(capsJSON), string(tagsJSON),
 		host.CPUTotal, host.CPUUsed, host.MemTotal, host.MemUsed,
 		host.DiskTotal, host.DiskUsed, host.UploadTotal, host.DownloadTotal,
-		host.LastHeartbeatAt, host.UpdatedAt, host.ID,
+		host.Maintenance, host.LastHeartbeatAt, host.UpdatedAt, host.ID,
 	)
 	return err
 }
@@ -154,12 +184,13 @@ func (r *agentHostRepo) Delete(ctx context.Context, id int64) error {
 
 func (r *agentHostRepo) ListAll(ctx context.Context) ([]*repository.AgentHost, error) {
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT id, name, host, token, status, provision_status, template_id, core_version, capabilities, build_tags,
+		SELECT id, name, host, token, status, provision_status, template_id, template_version, core_version, capabilities, build_tags,
 			cpu_total, cpu_used, mem_total, mem_used,
 			disk_total, disk_used, upload_total, download_total,
 			upload_rate_bps, download_rate_bps, raw_upload_total_bytes, raw_download_total_bytes,
 			boot_id, last_realtime_report_at, last_restart_at, agent_version, current_core_type,
-			last_heartbeat_at, created_at, updated_at
+			connection_count, connection_count_source,
+			maintenance, drift_detected, sync_interval_seconds, report_interval_seconds, last_heartbeat_at, created_at, updated_at
 		FROM agent_hosts ORDER BY name ASC
 	`)
 	if err != nil {
@@ -181,6 +212,112 @@ func (r *agentHostRepo) ListAll(ctx context.Context) ([]*repository.AgentHost, e
 	return hosts, nil
 }
 
+// Search 按关键字与心跳状态分页查询，排序时追加 id 作为次序键保证翻页稳定。
+func (r *agentHostRepo) Search(ctx context.Context, filter repository.AgentHostSearchFilter) ([]*repository.AgentHost, error) {
+	where, args := agentHostSearchConditions(filter)
+	query := `
+		SELECT id, name, host, token, status, provision_status, template_id, template_version, core_version, capabilities, build_tags,
+			cpu_total, cpu_used, mem_total, mem_used,
+			disk_total, disk_used, upload_total, download_total,
+			upload_rate_bps, download_rate_bps, raw_upload_total_bytes, raw_download_total_bytes,
+			boot_id, last_realtime_report_at, last_restart_at, agent_version, current_core_type,
+			connection_count, connection_count_source,
+			maintenance, drift_detected, sync_interval_seconds, report_interval_seconds, last_heartbeat_at, created_at, updated_at
+		FROM agent_hosts` + where
+
+	limit := 20
+	if filter.Limit > 0 {
+		limit = filter.Limit
+	}
+	offset := 0
+	if filter.Offset > 0 {
+		offset = filter.Offset
+	}
+	query += agentHostSearchOrderBy(filter) + " LIMIT ? OFFSET ?"
+	args = append(args, limit, offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("agent_hosts search query: %w", err)
+	}
+	defer rows.Close()
+
+	hosts := make([]*repository.AgentHost, 0, limit)
+	for rows.Next() {
+		host, err := r.scanHostFromRows(rows)
+		if err != nil {
+			return nil, fmt.Errorf("agent_hosts scan row: %w", err)
+		}
+		hosts = append(hosts, host)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("agent_hosts rows iteration: %w", err)
+	}
+	return hosts, nil
+}
+
+// CountFiltered 返回与 Search 相同条件下的总数。
+func (r *agentHostRepo) CountFiltered(ctx context.Context, filter repository.AgentHostSearchFilter) (int64, error) {
+	where, args := agentHostSearchConditions(filter)
+	var count int64
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM agent_hosts"+where, args...).Scan(&count)
+	return count, err
+}
+
+// agentHostSearchConditions 把过滤条件翻译为 WHERE 子句，状态判定与 AgentHost.DisplayState 保持一致。
+func agentHostSearchConditions(filter repository.AgentHostSearchFilter) (string, []any) {
+	var conds []string
+	var args []any
+
+	if keyword := strings.TrimSpace(filter.Keyword); keyword != "" {
+		like := "%" + keyword + "%"
+		conds = append(conds, "(name LIKE ? OR host LIKE ?)")
+		args = append(args, like, like)
+	}
+
+	now := filter.Now
+	if now == 0 {
+		now = time.Now().Unix()
+	}
+	onlineSince := now - repository.AgentHostOnlineWindowSeconds
+	warningSince := now - repository.AgentHostWarningWindowSeconds
+	switch filter.State {
+	case repository.AgentHostStateOnline:
+		conds = append(conds, "maintenance = 0 AND last_heartbeat_at > 0 AND last_heartbeat_at >= ?")
+		args = append(args, onlineSince)
+	case repository.AgentHostStateWarning:
+		conds = append(conds, "maintenance = 0 AND last_heartbeat_at > 0 AND last_heartbeat_at < ? AND last_heartbeat_at >= ?")
+		args = append(args, onlineSince, warningSince)
+	case repository.AgentHostStateOffline:
+		conds = append(conds, "(last_heartbeat_at = 0 OR last_heartbeat_at < ?)")
+		args = append(args, warningSince)
+	case repository.AgentHostStateMaintenance:
+		conds = append(conds, "maintenance = 1 AND last_heartbeat_at > 0 AND last_heartbeat_at >= ?")
+		args = append(args, warningSince)
+	}
+
+	if len(conds) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// agentHostSearchOrderBy 生成排序子句，列名来自白名单；按连接数排序时不可用的节点始终排在最后。
+func agentHostSearchOrderBy(filter repository.AgentHostSearchFilter) string {
+	direction := "ASC"
+	if strings.EqualFold(filter.SortOrder, repository.SortOrderDesc) {
+		direction = "DESC"
+	}
+	switch filter.SortBy {
+	case repository.AgentHostSortName:
+		return " ORDER BY name " + direction + ", id " + direction
+	case repository.AgentHostSortConnectionCount:
+		return " ORDER BY (connection_count_source = '') ASC, connection_count " + direction + ", id " + direction
+	default:
+		return " ORDER BY id " + direction
+	}
+}
+
 func (r *agentHostRepo) UpdateStatus(ctx context.Context, id int64, status int, heartbeatAt int64) error {
 	return bootstrap.WithSQLiteBusyRetry(func() error {
 		_, err := r.db.ExecContext(ctx, `
@@ -207,6 +344,7 @@ func (r *agentHostRepo) UpdateMetrics(ctx context.Context, id int64, metrics rep
 				raw_upload_total_bytes = ?, raw_download_total_bytes = ?,
 				boot_id = ?, last_realtime_report_at = ?, last_restart_at = ?,
 				agent_version = ?, current_core_type = ?,
+				connection_count = ?, connection_count_source = ?,
 				last_heartbeat_at = ?,
 				status = 1,
 				updated_at = ?
@@ -220,6 +358,7 @@ func (r *agentHostRepo) UpdateMetrics(ctx context.Context, id int64, metrics rep
 			metrics.RawUploadTotalBytes, metrics.RawDownloadTotalBytes,
 			metrics.BootID, metrics.LastRealtimeReportAt, metrics.LastRestartAt,
 			metrics.AgentVersion, metrics.CurrentCoreType,
+			metrics.ConnectionCount, metrics.ConnectionCountSource,
 			updatedAt, updatedAt, id,
 		)
 		return err
@@ -231,13 +370,14 @@ func (r *agentHostRepo) scanHost(row *sql.Row) (*repository.AgentHost, error) {
 	var capsJSON, tagsJSON string
 
 	err := row.Scan(
-		&h.ID, &h.Name, &h.Host, &h.Token, &h.Status, &h.ProvisionStatus, &h.TemplateID,
+		&h.ID, &h.Name, &h.Host, &h.Token, &h.Status, &h.ProvisionStatus, &h.TemplateID, &h.TemplateVersion,
 		&h.CoreVersion, &capsJSON, &tagsJSON,
 		&h.CPUTotal, &h.CPUUsed, &h.MemTotal, &h.MemUsed,
 		&h.DiskTotal, &h.DiskUsed, &h.UploadTotal, &h.DownloadTotal,
 		&h.UploadRateBps, &h.DownloadRateBps, &h.RawUploadTotalBytes, &h.RawDownloadTotalBytes,
 		&h.BootID, &h.LastRealtimeReportAt, &h.LastRestartAt, &h.AgentVersion, &h.CurrentCoreType,
-		&h.LastHeartbeatAt, &h.CreatedAt, &h.UpdatedAt,
+		&h.ConnectionCount, &h.ConnectionCountSource,
+		&h.Maintenance, &h.DriftDetected, &h.SyncIntervalSeconds, &h.ReportIntervalSeconds, &h.LastHeartbeatAt, &h.CreatedAt, &h.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, repository.ErrNotFound
@@ -272,13 +412,14 @@ func (r *agentHostRepo) scanHostFromRows(rows *sql.Rows) (*repository.AgentHost,
 	var capsJSON, tagsJSON string
 
 	err := rows.Scan(
-		&h.ID, &h.Name, &h.Host, &h.Token, &h.Status, &h.ProvisionStatus, &h.TemplateID,
+		&h.ID, &h.Name, &h.Host, &h.Token, &h.Status, &h.ProvisionStatus, &h.TemplateID, &h.TemplateVersion,
 		&h.CoreVersion, &capsJSON, &tagsJSON,
 		&h.CPUTotal, &h.CPUUsed, &h.MemTotal, &h.MemUsed,
 		&h.DiskTotal, &h.DiskUsed, &h.UploadTotal, &h.DownloadTotal,
 		&h.UploadRateBps, &h.DownloadRateBps, &h.RawUploadTotalBytes, &h.RawDownloadTotalBytes,
 		&h.BootID, &h.LastRealtimeReportAt, &h.LastRestartAt, &h.AgentVersion, &h.CurrentCoreType,
-		&h.LastHeartbeatAt, &h.CreatedAt, &h.UpdatedAt,
+		&h.ConnectionCount, &h.ConnectionCountSource,
+		&h.Maintenance, &h.DriftDetected, &h.SyncIntervalSeconds, &h.ReportIntervalSeconds, &h.LastHeartbeatAt, &h.CreatedAt, &h.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -305,6 +446,26 @@ func (r *agentHostRepo) scanHostFromRows(rows *sql.Rows) (*repository.AgentHost,
 	return &h, nil
 }
 
+// UpdateDriftDetected 只更新配置漂移标记，避免覆盖并发写入的指标字段。
+func (r *agentHostRepo) UpdateDriftDetected(ctx context.Context, id int64, detected bool) error {
+	return bootstrap.WithSQLiteBusyRetry(func() error {
+		_, err := r.db.ExecContext(ctx, `
+			UPDATE agent_hosts SET drift_detected = ?, updated_at = ? WHERE id = ?
+		`, detected, time.Now().Unix(), id)
+		return err
+	})
+}
+
+// UpdateIntervals 只更新节点级同步/上报间隔，0 表示沿用全局设置。
+func (r *agentHostRepo) UpdateIntervals(ctx context.Context, id int64, syncSeconds, reportSeconds int) error {
+	return bootstrap.WithSQLiteBusyRetry(func() error {
+		_, err := r.db.ExecContext(ctx, `
+			UPDATE agent_hosts SET sync_interval_seconds = ?, report_interval_seconds = ?, updated_at = ? WHERE id = ?
+		`, syncSeconds, reportSeconds, time.Now().Unix(), id)
+		return err
+	})
+}
+
 // UpdateCapabilities updates agent capabilities.
 func (r *agentHostRepo) UpdateCapabilities(ctx context.Context, id int64, coreVersion string, capabilities, buildTags []string) error {
 	capsJSON, err := json.Marshal(capabilities)
@@ -0,0 +1,64 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+type agentHostDiagnosticsRepo struct {
+	db *sql.DB
+}
+
+func newAgentHostDiagnosticsRepo(db *sql.DB) *agentHostDiagnosticsRepo {
+	return &agentHostDiagnosticsRepo{db: db}
+}
+
+// Upsert 覆盖主机最近一次的诊断包。
+func (r *agentHostDiagnosticsRepo) Upsert(ctx context.Context, diag *repository.AgentHostDiagnostics) error {
+	if diag == nil {
+		return errors.New("agent host diagnostics is nil")
+	}
+	if diag.AgentHostID <= 0 {
+		return errors.New("agent host id is required")
+	}
+	payload := string(diag.Payload)
+	if payload == "" {
+		payload = "{}"
+	}
+	if diag.UpdatedAt == 0 {
+		diag.UpdatedAt = time.Now().Unix()
+	}
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO agent_host_diagnostics (agent_host_id, payload, collected_at, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(agent_host_id) DO UPDATE SET
+			payload = excluded.payload,
+			collected_at = excluded.collected_at,
+			updated_at = excluded.updated_at
+	`, diag.AgentHostID, payload, diag.CollectedAt, diag.UpdatedAt)
+	return err
+}
+
+func (r *agentHostDiagnosticsRepo) FindByAgentHostID(ctx context.Context, agentHostID int64) (*repository.AgentHostDiagnostics, error) {
+	var (
+		diag    repository.AgentHostDiagnostics
+		payload string
+	)
+	err := r.db.QueryRowContext(ctx, `
+		SELECT agent_host_id, payload, collected_at, updated_at
+		FROM agent_host_diagnostics
+		WHERE agent_host_id = ?
+	`, agentHostID).Scan(&diag.AgentHostID, &payload, &diag.CollectedAt, &diag.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	diag.Payload = []byte(payload)
+	return &diag, nil
+}
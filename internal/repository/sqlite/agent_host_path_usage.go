@@ -0,0 +1,131 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+type agentHostPathUsageRepo struct {
+	db *sql.DB
+}
+
+func newAgentHostPathUsageRepo(db *sql.DB) *agentHostPathUsageRepo {
+	return &agentHostPathUsageRepo{db: db}
+}
+
+// ReplaceForHost 以本次上报整体替换主机的监控路径记录。
+func (r *agentHostPathUsageRepo) ReplaceForHost(ctx context.Context, agentHostID int64, usages []*repository.AgentHostPathUsage) error {
+	if agentHostID <= 0 {
+		return errors.New("agent host id is required")
+	}
+	for _, item := range usages {
+		if item == nil {
+			return errors.New("agent host path usage item is nil")
+		}
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM agent_host_path_usage WHERE agent_host_id = ?`, agentHostID); err != nil {
+		return err
+	}
+	if len(usages) > 0 {
+		stmt, err := tx.PrepareContext(ctx, `
+			INSERT INTO agent_host_path_usage (
+				agent_host_id, path, path_exists, used_bytes, truncated,
+				fs_total_bytes, fs_used_bytes, fs_free_bytes, alerting, updated_at
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(agent_host_id, path) DO UPDATE SET
+				path_exists = excluded.path_exists,
+				used_bytes = excluded.used_bytes,
+				truncated = excluded.truncated,
+				fs_total_bytes = excluded.fs_total_bytes,
+				fs_used_bytes = excluded.fs_used_bytes,
+				fs_free_bytes = excluded.fs_free_bytes,
+				alerting = excluded.alerting,
+				updated_at = excluded.updated_at
+		`)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		now := time.Now().Unix()
+		for _, item := range usages {
+			if item.UpdatedAt == 0 {
+				item.UpdatedAt = now
+			}
+			_, err := stmt.ExecContext(ctx,
+				agentHostID,
+				item.Path,
+				boolToInt(item.Exists),
+				int64(item.UsedBytes),
+				boolToInt(item.Truncated),
+				int64(item.FSTotalBytes),
+				int64(item.FSUsedBytes),
+				int64(item.FSFreeBytes),
+				boolToInt(item.Alerting),
+				item.UpdatedAt,
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *agentHostPathUsageRepo) ListByAgentHostID(ctx context.Context, agentHostID int64) ([]*repository.AgentHostPathUsage, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT agent_host_id, path, path_exists, used_bytes, truncated,
+			fs_total_bytes, fs_used_bytes, fs_free_bytes, alerting, updated_at
+		FROM agent_host_path_usage
+		WHERE agent_host_id = ?
+		ORDER BY path ASC
+	`, agentHostID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var usages []*repository.AgentHostPathUsage
+	for rows.Next() {
+		var (
+			item                               repository.AgentHostPathUsage
+			exists, truncated, alerting        int
+			usedBytes, fsTotal, fsUsed, fsFree int64
+		)
+		if err := rows.Scan(
+			&item.AgentHostID,
+			&item.Path,
+			&exists,
+			&usedBytes,
+			&truncated,
+			&fsTotal,
+			&fsUsed,
+			&fsFree,
+			&alerting,
+			&item.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		item.Exists = exists != 0
+		item.Truncated = truncated != 0
+		item.Alerting = alerting != 0
+		item.UsedBytes = uint64(usedBytes)
+		item.FSTotalBytes = uint64(fsTotal)
+		item.FSUsedBytes = uint64(fsUsed)
+		item.FSFreeBytes = uint64(fsFree)
+		usages = append(usages, &item)
+	}
+	return usages, rows.Err()
+}
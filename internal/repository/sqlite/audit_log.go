@@ -0,0 +1,131 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+// auditLogRepo persists append-only admin mutation records.
+type auditLogRepo struct {
+	db *sql.DB
+}
+
+const auditLogColumns = `id, actor_id, action, target_type, target_id, changes, created_at`
+
+func (r *auditLogRepo) Append(ctx context.Context, log *repository.AuditLog) error {
+	if r == nil || r.db == nil {
+		return fmt.Errorf("audit log repository not configured / 审计日志仓储未配置")
+	}
+	if log == nil {
+		return fmt.Errorf("audit log is nil / 审计日志为空")
+	}
+	if log.CreatedAt == 0 {
+		log.CreatedAt = time.Now().Unix()
+	}
+	changes := log.Changes
+	if len(changes) == 0 {
+		changes = json.RawMessage(`{}`)
+	}
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO audit_logs (actor_id, action, target_type, target_id, changes, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, log.ActorID, log.Action, log.TargetType, log.TargetID, string(changes), log.CreatedAt)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	log.ID = id
+	return nil
+}
+
+func (r *auditLogRepo) List(ctx context.Context, filter repository.AuditLogFilter) ([]*repository.AuditLog, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("audit log repository not configured / 审计日志仓储未配置")
+	}
+	query := strings.Builder{}
+	args := make([]any, 0, 8)
+	query.WriteString(`SELECT ` + auditLogColumns + ` FROM audit_logs WHERE 1 = 1`)
+	appendAuditLogConditions(&query, &args, filter)
+	limit, offset := normalizePagination(filter.Limit, filter.Offset, 50)
+	query.WriteString(" ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?")
+	args = append(args, limit, offset)
+
+	rows, err := r.db.QueryContext(ctx, query.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	logs := make([]*repository.AuditLog, 0)
+	for rows.Next() {
+		log, err := scanAuditLog(rows)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, log)
+	}
+	return logs, rows.Err()
+}
+
+func (r *auditLogRepo) Count(ctx context.Context, filter repository.AuditLogFilter) (int64, error) {
+	if r == nil || r.db == nil {
+		return 0, fmt.Errorf("audit log repository not configured / 审计日志仓储未配置")
+	}
+	query := strings.Builder{}
+	args := make([]any, 0, 6)
+	query.WriteString(`SELECT COUNT(*) FROM audit_logs WHERE 1 = 1`)
+	appendAuditLogConditions(&query, &args, filter)
+
+	var total int64
+	err := r.db.QueryRowContext(ctx, query.String(), args...).Scan(&total)
+	return total, err
+}
+
+func appendAuditLogConditions(query *strings.Builder, args *[]any, filter repository.AuditLogFilter) {
+	if filter.ActorID != nil {
+		query.WriteString(" AND actor_id = ?")
+		*args = append(*args, *filter.ActorID)
+	}
+	if filter.TargetType != nil {
+		query.WriteString(" AND target_type = ?")
+		*args = append(*args, *filter.TargetType)
+	}
+	if filter.TargetID != nil {
+		query.WriteString(" AND target_id = ?")
+		*args = append(*args, *filter.TargetID)
+	}
+	if filter.Since > 0 {
+		query.WriteString(" AND created_at >= ?")
+		*args = append(*args, filter.Since)
+	}
+	if filter.Until > 0 {
+		query.WriteString(" AND created_at < ?")
+		*args = append(*args, filter.Until)
+	}
+}
+
+func scanAuditLog(scanner interface{ Scan(dest ...any) error }) (*repository.AuditLog, error) {
+	var (
+		log     repository.AuditLog
+		changes string
+	)
+	if err := scanner.Scan(&log.ID, &log.ActorID, &log.Action, &log.TargetType, &log.TargetID, &changes, &log.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	if changes != "" {
+		log.Changes = json.RawMessage(changes)
+	}
+	return &log, nil
+}
@@ -58,8 +58,8 @@ func (r *configTemplateRepo) Create(ctx context.Context, tpl *repository.ConfigT
 	return nil
 }
 
-func (r *configTemplateRepo) Update(ctx context.Context, tpl *repository.ConfigTemplate) error {
-	tpl.UpdatedAt = time.Now().Unix()
+func (r *configTemplateRepo) Update(ctx context.Context, tpl *repository.ConfigTemplate, expectedUpdatedAt int64) error {
+	tpl.UpdatedAt = nextUpdatedAt(time.Now().Unix(), expectedUpdatedAt)
 
 	capsJSON, err := json.Marshal(tpl.Capabilities)
 	if err != nil {
@@ -69,18 +69,26 @@ func (r *configTemplateRepo) Update(ctx context.Context, tpl *repository.ConfigT
 		capsJSON = []byte("[]")
 	}
 
-	_, err = r.db.ExecContext(ctx, `
+	query := `
 		UPDATE config_templates SET
 			name = ?, type = ?, content = ?, description = ?, min_version = ?,
 			capabilities = ?, schema_version = ?, is_valid = ?, validation_error = ?,
 			updated_at = ?
-		WHERE id = ?
-	`,
+		WHERE id = ?`
+	args := []any{
 		tpl.Name, tpl.Type, tpl.Content, tpl.Description, tpl.MinVersion,
 		string(capsJSON), tpl.SchemaVersion, boolToInt(tpl.IsValid), tpl.ValidationError,
 		tpl.UpdatedAt, tpl.ID,
-	)
-	return err
+	}
+	if expectedUpdatedAt <= 0 {
+		_, err = r.db.ExecContext(ctx, query, args...)
+		return err
+	}
+	result, err := r.db.ExecContext(ctx, query+` AND updated_at = ?`, append(args, expectedUpdatedAt)...)
+	if err != nil {
+		return err
+	}
+	return ensureUnmodified(result)
 }
 
 func (r *configTemplateRepo) Delete(ctx context.Context, id int64) error {
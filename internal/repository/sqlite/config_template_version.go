@@ -0,0 +1,154 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+type configTemplateVersionRepo struct {
+	db *sql.DB
+}
+
+func newConfigTemplateVersionRepo(db *sql.DB) *configTemplateVersionRepo {
+	return &configTemplateVersionRepo{db: db}
+}
+
+func (r *configTemplateVersionRepo) Create(ctx context.Context, version *repository.ConfigTemplateVersion) error {
+	if version == nil {
+		return errors.New("config template version is nil")
+	}
+
+	if version.CreatedAt == 0 {
+		version.CreatedAt = time.Now().Unix()
+	}
+	if version.Capabilities == nil {
+		version.Capabilities = []string{}
+	}
+	capsJSON, err := json.Marshal(version.Capabilities)
+	if err != nil {
+		return fmt.Errorf("encode template capabilities: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO config_template_versions (
+			template_id, version, name, type, content, description, min_version,
+			capabilities, change_note, operator_id, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		version.TemplateID, version.Version, version.Name, version.Type, version.Content,
+		version.Description, version.MinVersion, string(capsJSON), version.ChangeNote,
+		version.OperatorID, version.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	version.ID = id
+	return nil
+}
+
+func (r *configTemplateVersionRepo) FindByTemplateAndVersion(ctx context.Context, templateID, version int64) (*repository.ConfigTemplateVersion, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT id, template_id, version, name, type, content, description, min_version,
+		       capabilities, change_note, operator_id, created_at
+		FROM config_template_versions
+		WHERE template_id = ? AND version = ?
+		LIMIT 1
+	`, templateID, version)
+
+	return r.scanConfigTemplateVersion(row)
+}
+
+func (r *configTemplateVersionRepo) ListByTemplateID(ctx context.Context, templateID int64, limit, offset int) ([]*repository.ConfigTemplateVersion, error) {
+	limit, offset = normalizePagination(limit, offset, 100)
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, template_id, version, name, type, content, description, min_version,
+		       capabilities, change_note, operator_id, created_at
+		FROM config_template_versions
+		WHERE template_id = ?
+		ORDER BY version DESC, id DESC
+		LIMIT ? OFFSET ?
+	`, templateID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []*repository.ConfigTemplateVersion
+	for rows.Next() {
+		version, err := r.scanConfigTemplateVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+	return versions, rows.Err()
+}
+
+func (r *configTemplateVersionRepo) GetMaxVersion(ctx context.Context, templateID int64) (int64, error) {
+	var maxVersion sql.NullInt64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT MAX(version)
+		FROM config_template_versions
+		WHERE template_id = ?
+	`, templateID).Scan(&maxVersion)
+	if err != nil {
+		return 0, err
+	}
+	if !maxVersion.Valid {
+		return 0, nil
+	}
+	return maxVersion.Int64, nil
+}
+
+type configTemplateVersionScanner interface {
+	Scan(dest ...any) error
+}
+
+func (r *configTemplateVersionRepo) scanConfigTemplateVersion(scanner configTemplateVersionScanner) (*repository.ConfigTemplateVersion, error) {
+	var version repository.ConfigTemplateVersion
+	var capsJSON string
+
+	err := scanner.Scan(
+		&version.ID,
+		&version.TemplateID,
+		&version.Version,
+		&version.Name,
+		&version.Type,
+		&version.Content,
+		&version.Description,
+		&version.MinVersion,
+		&capsJSON,
+		&version.ChangeNote,
+		&version.OperatorID,
+		&version.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if capsJSON != "" {
+		if err := json.Unmarshal([]byte(capsJSON), &version.Capabilities); err != nil {
+			return nil, fmt.Errorf("decode template capabilities: %w", err)
+		}
+	}
+	if version.Capabilities == nil {
+		version.Capabilities = []string{}
+	}
+
+	return &version, nil
+}
@@ -3,6 +3,8 @@ package sqlite
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/creamcroissant/xboard/internal/repository"
@@ -23,15 +25,20 @@ func (r *forwardingRuleRepo) Create(ctx context.Context, rule *repository.Forwar
 	if rule.Version == 0 {
 		rule.Version = time.Now().UnixNano()
 	}
+	targetsJSON, err := encodeForwardingTargets(rule.Targets)
+	if err != nil {
+		return err
+	}
 
 	result, err := r.db.ExecContext(ctx, `
 		INSERT INTO forwarding_rules (
 			agent_host_id, name, protocol, listen_port, target_address,
-			target_port, enabled, priority, remark, version, created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			target_port, targets, health_check_interval, health_check_timeout,
+			enabled, priority, remark, version, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		rule.AgentHostID, rule.Name, rule.Protocol, rule.ListenPort, rule.TargetAddress,
-		rule.TargetPort, boolToInt(rule.Enabled), rule.Priority, rule.Remark,
+		rule.TargetPort, targetsJSON, rule.HealthCheckInterval, rule.HealthCheckTimeout, boolToInt(rule.Enabled), rule.Priority, rule.Remark,
 		rule.Version, rule.CreatedAt, rule.UpdatedAt,
 	)
 	if err != nil {
@@ -49,16 +56,21 @@ func (r *forwardingRuleRepo) Create(ctx context.Context, rule *repository.Forwar
 func (r *forwardingRuleRepo) Update(ctx context.Context, rule *repository.ForwardingRule) error {
 	rule.UpdatedAt = time.Now().Unix()
 	rule.Version = time.Now().UnixNano()
+	targetsJSON, err := encodeForwardingTargets(rule.Targets)
+	if err != nil {
+		return err
+	}
 
-	_, err := r.db.ExecContext(ctx, `
+	_, err = r.db.ExecContext(ctx, `
 		UPDATE forwarding_rules SET
 			name = ?, protocol = ?, listen_port = ?, target_address = ?,
-			target_port = ?, enabled = ?, priority = ?, remark = ?,
+			target_port = ?, targets = ?, health_check_interval = ?, health_check_timeout = ?,
+			enabled = ?, priority = ?, remark = ?,
 			version = ?, updated_at = ?
 		WHERE id = ?
 	`,
 		rule.Name, rule.Protocol, rule.ListenPort, rule.TargetAddress,
-		rule.TargetPort, boolToInt(rule.Enabled), rule.Priority, rule.Remark,
+		rule.TargetPort, targetsJSON, rule.HealthCheckInterval, rule.HealthCheckTimeout, boolToInt(rule.Enabled), rule.Priority, rule.Remark,
 		rule.Version, rule.UpdatedAt, rule.ID,
 	)
 	return err
@@ -72,7 +84,8 @@ func (r *forwardingRuleRepo) Delete(ctx context.Context, id int64) error {
 func (r *forwardingRuleRepo) FindByID(ctx context.Context, id int64) (*repository.ForwardingRule, error) {
 	row := r.db.QueryRowContext(ctx, `
 		SELECT id, agent_host_id, name, protocol, listen_port, target_address,
-			target_port, enabled, priority, remark, version, created_at, updated_at
+			target_port, targets, health_check_interval, health_check_timeout, target_health,
+			enabled, priority, remark, version, created_at, updated_at
 		FROM forwarding_rules WHERE id = ?
 	`, id)
 
@@ -82,7 +95,8 @@ func (r *forwardingRuleRepo) FindByID(ctx context.Context, id int64) (*repositor
 func (r *forwardingRuleRepo) ListByAgentHostID(ctx context.Context, agentHostID int64) ([]*repository.ForwardingRule, error) {
 	rows, err := r.db.QueryContext(ctx, `
 		SELECT id, agent_host_id, name, protocol, listen_port, target_address,
-			target_port, enabled, priority, remark, version, created_at, updated_at
+			target_port, targets, health_check_interval, health_check_timeout, target_health,
+			enabled, priority, remark, version, created_at, updated_at
 		FROM forwarding_rules
 		WHERE agent_host_id = ?
 		ORDER BY priority ASC, id ASC
@@ -98,7 +112,8 @@ func (r *forwardingRuleRepo) ListByAgentHostID(ctx context.Context, agentHostID
 func (r *forwardingRuleRepo) ListEnabledByAgentHostID(ctx context.Context, agentHostID int64) ([]*repository.ForwardingRule, error) {
 	rows, err := r.db.QueryContext(ctx, `
 		SELECT id, agent_host_id, name, protocol, listen_port, target_address,
-			target_port, enabled, priority, remark, version, created_at, updated_at
+			target_port, targets, health_check_interval, health_check_timeout, target_health,
+			enabled, priority, remark, version, created_at, updated_at
 		FROM forwarding_rules
 		WHERE agent_host_id = ? AND enabled = 1
 		ORDER BY priority ASC, id ASC
@@ -155,13 +170,28 @@ func (r *forwardingRuleRepo) CheckPortConflict(ctx context.Context, agentHostID
 	return count > 0, nil
 }
 
+func (r *forwardingRuleRepo) UpdateTargetHealth(ctx context.Context, id int64, health []repository.ForwardingTargetHealth) error {
+	payload := ""
+	if len(health) > 0 {
+		encoded, err := json.Marshal(health)
+		if err != nil {
+			return fmt.Errorf("encode forwarding target health: %w", err)
+		}
+		payload = string(encoded)
+	}
+	_, err := r.db.ExecContext(ctx, `UPDATE forwarding_rules SET target_health = ? WHERE id = ?`, payload, id)
+	return err
+}
+
 func (r *forwardingRuleRepo) scanRule(row *sql.Row) (*repository.ForwardingRule, error) {
 	var rule repository.ForwardingRule
 	var enabled int
+	var targetsJSON, healthJSON string
 
 	err := row.Scan(
 		&rule.ID, &rule.AgentHostID, &rule.Name, &rule.Protocol,
-		&rule.ListenPort, &rule.TargetAddress, &rule.TargetPort,
+		&rule.ListenPort, &rule.TargetAddress, &rule.TargetPort, &targetsJSON,
+		&rule.HealthCheckInterval, &rule.HealthCheckTimeout, &healthJSON,
 		&enabled, &rule.Priority, &rule.Remark, &rule.Version,
 		&rule.CreatedAt, &rule.UpdatedAt,
 	)
@@ -173,6 +203,12 @@ func (r *forwardingRuleRepo) scanRule(row *sql.Row) (*repository.ForwardingRule,
 	}
 
 	rule.Enabled = enabled == 1
+	if rule.Targets, err = decodeForwardingTargets(targetsJSON); err != nil {
+		return nil, err
+	}
+	if rule.TargetHealth, err = decodeForwardingTargetHealth(healthJSON); err != nil {
+		return nil, err
+	}
 	return &rule, nil
 }
 
@@ -181,10 +217,12 @@ func (r *forwardingRuleRepo) scanRules(rows *sql.Rows) ([]*repository.Forwarding
 	for rows.Next() {
 		var rule repository.ForwardingRule
 		var enabled int
+		var targetsJSON, healthJSON string
 
 		err := rows.Scan(
 			&rule.ID, &rule.AgentHostID, &rule.Name, &rule.Protocol,
-			&rule.ListenPort, &rule.TargetAddress, &rule.TargetPort,
+			&rule.ListenPort, &rule.TargetAddress, &rule.TargetPort, &targetsJSON,
+			&rule.HealthCheckInterval, &rule.HealthCheckTimeout, &healthJSON,
 			&enabled, &rule.Priority, &rule.Remark, &rule.Version,
 			&rule.CreatedAt, &rule.UpdatedAt,
 		)
@@ -193,8 +231,47 @@ func (r *forwardingRuleRepo) scanRules(rows *sql.Rows) ([]*repository.Forwarding
 		}
 
 		rule.Enabled = enabled == 1
+		if rule.Targets, err = decodeForwardingTargets(targetsJSON); err != nil {
+			return nil, err
+		}
+		if rule.TargetHealth, err = decodeForwardingTargetHealth(healthJSON); err != nil {
+			return nil, err
+		}
 		rules = append(rules, &rule)
 	}
 	return rules, rows.Err()
 }
 
+// encodeForwardingTargets 将多目标列表编码为 JSON；为空时存空串，表示沿用单目标字段。
+func encodeForwardingTargets(targets []repository.ForwardingTarget) (string, error) {
+	if len(targets) == 0 {
+		return "", nil
+	}
+	payload, err := json.Marshal(targets)
+	if err != nil {
+		return "", fmt.Errorf("encode forwarding targets: %w", err)
+	}
+	return string(payload), nil
+}
+
+func decodeForwardingTargets(raw string) ([]repository.ForwardingTarget, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	var targets []repository.ForwardingTarget
+	if err := json.Unmarshal([]byte(raw), &targets); err != nil {
+		return nil, fmt.Errorf("decode forwarding targets: %w", err)
+	}
+	return targets, nil
+}
+
+func decodeForwardingTargetHealth(raw string) ([]repository.ForwardingTargetHealth, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	var health []repository.ForwardingTargetHealth
+	if err := json.Unmarshal([]byte(raw), &health); err != nil {
+		return nil, fmt.Errorf("decode forwarding target health: %w", err)
+	}
+	return health, nil
+}
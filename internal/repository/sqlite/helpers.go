@@ -70,6 +70,26 @@ func normalizePagination(limit, offset, defaultLimit int) (int, int) {
 	return limit, offset
 }
 
+// nextUpdatedAt 返回新的 updated_at，保证严格大于 expected，避免同一秒内的两次修改无法区分。
+func nextUpdatedAt(now, expected int64) int64 {
+	if expected > 0 && now <= expected {
+		return expected + 1
+	}
+	return now
+}
+
+// ensureUnmodified 把条件更新未命中转换为 ErrConflict。
+func ensureUnmodified(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return repository.ErrConflict
+	}
+	return nil
+}
+
 func ensureRowsAffected(result sql.Result) error {
 	affected, err := result.RowsAffected()
 	if err != nil {
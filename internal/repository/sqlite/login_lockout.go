@@ -0,0 +1,179 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+// loginLockoutRepo persists per identifier+IP login failure counters and lockouts.
+type loginLockoutRepo struct {
+	db *sql.DB
+}
+
+const loginLockoutColumns = `id, identifier, ip, failed_count, lock_count, locked_until, last_failed_at, created_at, updated_at`
+
+func (r *loginLockoutRepo) Find(ctx context.Context, identifier, ip string) (*repository.LoginLockout, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("login lockout repository not configured / 登录锁定仓储未配置")
+	}
+	row := r.db.QueryRowContext(ctx, `SELECT `+loginLockoutColumns+` FROM login_lockouts WHERE identifier = ? AND ip = ? LIMIT 1`, identifier, ip)
+	return scanLoginLockout(row)
+}
+
+func (r *loginLockoutRepo) FindActive(ctx context.Context, identifier string, now int64) (*repository.LoginLockout, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("login lockout repository not configured / 登录锁定仓储未配置")
+	}
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+loginLockoutColumns+`
+		FROM login_lockouts
+		WHERE identifier = ? AND locked_until > ?
+		ORDER BY locked_until DESC
+		LIMIT 1
+	`, identifier, now)
+	return scanLoginLockout(row)
+}
+
+func (r *loginLockoutRepo) Upsert(ctx context.Context, lockout *repository.LoginLockout) error {
+	if r == nil || r.db == nil {
+		return fmt.Errorf("login lockout repository not configured / 登录锁定仓储未配置")
+	}
+	if lockout == nil || strings.TrimSpace(lockout.Identifier) == "" {
+		return fmt.Errorf("login lockout identifier is required / 登录锁定账号不能为空")
+	}
+	now := time.Now().Unix()
+	if lockout.CreatedAt == 0 {
+		lockout.CreatedAt = now
+	}
+	lockout.UpdatedAt = now
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO login_lockouts(identifier, ip, failed_count, lock_count, locked_until, last_failed_at, created_at, updated_at)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(identifier, ip) DO UPDATE SET
+			failed_count = excluded.failed_count,
+			lock_count = excluded.lock_count,
+			locked_until = excluded.locked_until,
+			last_failed_at = excluded.last_failed_at,
+			updated_at = excluded.updated_at
+	`,
+		lockout.Identifier,
+		lockout.IP,
+		lockout.FailedCount,
+		lockout.LockCount,
+		lockout.LockedUntil,
+		lockout.LastFailedAt,
+		lockout.CreatedAt,
+		lockout.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if lockout.ID == 0 {
+		if id, err := res.LastInsertId(); err == nil {
+			lockout.ID = id
+		}
+	}
+	return nil
+}
+
+func (r *loginLockoutRepo) List(ctx context.Context, filter repository.LoginLockoutFilter) ([]*repository.LoginLockout, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("login lockout repository not configured / 登录锁定仓储未配置")
+	}
+	where, args := buildLoginLockoutFilter(filter)
+	limit, offset := normalizePagination(filter.Limit, filter.Offset, 20)
+	args = append(args, limit, offset)
+	rows, err := r.db.QueryContext(ctx, `SELECT `+loginLockoutColumns+` FROM login_lockouts`+where+`
+		ORDER BY locked_until DESC, updated_at DESC, id DESC
+		LIMIT ? OFFSET ?`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lockouts []*repository.LoginLockout
+	for rows.Next() {
+		lockout, err := scanLoginLockout(rows)
+		if err != nil {
+			return nil, err
+		}
+		lockouts = append(lockouts, lockout)
+	}
+	return lockouts, rows.Err()
+}
+
+func (r *loginLockoutRepo) Count(ctx context.Context, filter repository.LoginLockoutFilter) (int64, error) {
+	if r == nil || r.db == nil {
+		return 0, fmt.Errorf("login lockout repository not configured / 登录锁定仓储未配置")
+	}
+	where, args := buildLoginLockoutFilter(filter)
+	var count int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM login_lockouts`+where, args...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *loginLockoutRepo) DeleteByIdentifier(ctx context.Context, identifier string) (int64, error) {
+	if r == nil || r.db == nil {
+		return 0, fmt.Errorf("login lockout repository not configured / 登录锁定仓储未配置")
+	}
+	res, err := r.db.ExecContext(ctx, `DELETE FROM login_lockouts WHERE identifier = ?`, identifier)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func buildLoginLockoutFilter(filter repository.LoginLockoutFilter) (string, []any) {
+	var conditions []string
+	var args []any
+	if filter.Identifier != nil && strings.TrimSpace(*filter.Identifier) != "" {
+		conditions = append(conditions, "identifier LIKE ?")
+		args = append(args, "%"+strings.ToLower(strings.TrimSpace(*filter.Identifier))+"%")
+	}
+	if filter.ActiveOnly {
+		now := filter.Now
+		if now == 0 {
+			now = time.Now().Unix()
+		}
+		conditions = append(conditions, "locked_until > ?")
+		args = append(args, now)
+	}
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+type loginLockoutScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanLoginLockout(scanner loginLockoutScanner) (*repository.LoginLockout, error) {
+	var lockout repository.LoginLockout
+	err := scanner.Scan(
+		&lockout.ID,
+		&lockout.Identifier,
+		&lockout.IP,
+		&lockout.FailedCount,
+		&lockout.LockCount,
+		&lockout.LockedUntil,
+		&lockout.LastFailedAt,
+		&lockout.CreatedAt,
+		&lockout.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &lockout, nil
+}
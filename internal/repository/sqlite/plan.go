@@ -79,8 +79,8 @@ func (r *planRepo) Create(ctx context.Context, plan *repository.Plan) (*reposito
 	}
 	const stmt = `INSERT INTO plans (
 		group_id, name, prices, sell, transfer_enable, speed_limit, device_limit,
-		show, renew, content, tags, reset_traffic_method, capacity_limit, invite_limit, sort, created_at, updated_at
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+		show, renew, content, tags, reset_traffic_method, reset_day, reset_cycle, capacity_limit, invite_limit, sort, subscription_template_id, created_at, updated_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	tags, err := encodeStringSlice(plan.Tags)
 	if err != nil {
@@ -103,9 +103,12 @@ func (r *planRepo) Create(ctx context.Context, plan *repository.Plan) (*reposito
 		plan.Content,
 		tags,
 		optionalInt64(plan.ResetTrafficMethod),
+		optionalInt64(plan.ResetDay),
+		plan.ResetCycle,
 		optionalInt64(plan.CapacityLimit),
 		optionalInt64(plan.InviteLimit),
 		plan.Sort,
+		optionalInt64(plan.SubscriptionTemplateID),
 		plan.CreatedAt,
 		plan.UpdatedAt,
 	)
@@ -139,9 +142,12 @@ func (r *planRepo) Update(ctx context.Context, plan *repository.Plan) error {
 		content = ?,
 		tags = ?,
 		reset_traffic_method = ?,
+		reset_day = ?,
+		reset_cycle = ?,
 		capacity_limit = ?,
 		invite_limit = ?,
 		sort = ?,
+		subscription_template_id = ?,
 		updated_at = ?
 	WHERE id = ?`
 	tags, err := encodeStringSlice(plan.Tags)
@@ -165,9 +171,12 @@ func (r *planRepo) Update(ctx context.Context, plan *repository.Plan) error {
 		plan.Content,
 		tags,
 		optionalInt64(plan.ResetTrafficMethod),
+		optionalInt64(plan.ResetDay),
+		plan.ResetCycle,
 		optionalInt64(plan.CapacityLimit),
 		optionalInt64(plan.InviteLimit),
 		plan.Sort,
+		optionalInt64(plan.SubscriptionTemplateID),
 		plan.UpdatedAt,
 		plan.ID,
 	)
@@ -286,9 +295,12 @@ func updatePlanTx(ctx context.Context, tx *sql.Tx, plan *repository.Plan) error
 		content = ?,
 		tags = ?,
 		reset_traffic_method = ?,
+		reset_day = ?,
+		reset_cycle = ?,
 		capacity_limit = ?,
 		invite_limit = ?,
 		sort = ?,
+		subscription_template_id = ?,
 		updated_at = ?
 	WHERE id = ?`
 
@@ -314,9 +326,12 @@ func updatePlanTx(ctx context.Context, tx *sql.Tx, plan *repository.Plan) error
 		plan.Content,
 		tags,
 		optionalInt64(plan.ResetTrafficMethod),
+		optionalInt64(plan.ResetDay),
+		plan.ResetCycle,
 		optionalInt64(plan.CapacityLimit),
 		optionalInt64(plan.InviteLimit),
 		plan.Sort,
+		optionalInt64(plan.SubscriptionTemplateID),
 		plan.UpdatedAt,
 		plan.ID,
 	)
@@ -382,12 +397,15 @@ func scanPlan(scanner planScanner) (*repository.Plan, error) {
 		content        sql.NullString
 		tags           sql.NullString
 		resetMethod    sql.NullInt64
+		resetDay       sql.NullInt64
+		resetCycle     string
 		capacityLimit  sql.NullInt64
 		inviteLimit    sql.NullInt64
 		sort           int64
 		createdAt      int64
 		updatedAt      int64
 	)
+	var subscriptionTemplateID sql.NullInt64
 
 	if err := scanner.Scan(
 		&id,
@@ -403,9 +421,12 @@ func scanPlan(scanner planScanner) (*repository.Plan, error) {
 		&content,
 		&tags,
 		&resetMethod,
+		&resetDay,
+		&resetCycle,
 		&capacityLimit,
 		&inviteLimit,
 		&sort,
+		&subscriptionTemplateID,
 		&createdAt,
 		&updatedAt,
 	); err != nil {
@@ -422,7 +443,7 @@ func scanPlan(scanner planScanner) (*repository.Plan, error) {
 		return nil, fmt.Errorf("decode plan prices: %w", err)
 	}
 
-	return &repository.Plan{
+	plan := &repository.Plan{
 		ID:                 id,
 		GroupID:            nullableIntPtr(groupID),
 		Name:               name,
@@ -436,12 +457,16 @@ func scanPlan(scanner planScanner) (*repository.Plan, error) {
 		Content:            content.String,
 		Tags:               decodedTags,
 		ResetTrafficMethod: nullableIntPtr(resetMethod),
+		ResetDay:           nullableIntPtr(resetDay),
+		ResetCycle:         resetCycle,
 		CapacityLimit:      nullableIntPtr(capacityLimit),
 		InviteLimit:        nullableIntPtr(inviteLimit),
 		Sort:               sort,
 		CreatedAt:          createdAt,
 		UpdatedAt:          updatedAt,
-	}, nil
+	}
+	plan.SubscriptionTemplateID = nullableIntPtr(subscriptionTemplateID)
+	return plan, nil
 }
 
 const (
@@ -458,9 +483,12 @@ const (
 	       content,
 	       tags,
 	       reset_traffic_method,
+	       reset_day,
+	       reset_cycle,
 	       capacity_limit,
 	       invite_limit,
 	       sort,
+	       subscription_template_id,
 	       created_at,
 	       updated_at`
 	listVisibleQuery = "SELECT " + planColumns + " FROM plans WHERE show = 1 AND sell = 1 ORDER BY sort ASC, id ASC"
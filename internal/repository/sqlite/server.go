@@ -19,7 +19,7 @@ type serverRepo struct {
 
 func (r *serverRepo) FindAllVisible(ctx context.Context) ([]*repository.Server, error) {
 	const query = `SELECT id, code, group_id, route_id, parent_id, agent_host_id, tags, name, rate, host, port, server_port,
-		cipher, obfs, obfs_settings, "show", sort, status, type, settings, last_heartbeat_at, created_at, updated_at
+		cipher, obfs, obfs_settings, "show", sort, status, type, settings, weight, traffic_ratio, last_heartbeat_at, created_at, updated_at
         FROM servers
         WHERE "show" = 1
         ORDER BY sort DESC, id ASC`
@@ -45,7 +45,7 @@ func (r *serverRepo) FindAllVisible(ctx context.Context) ([]*repository.Server,
 
 func (r *serverRepo) ListAll(ctx context.Context) ([]*repository.Server, error) {
 	const query = `SELECT id, code, group_id, route_id, parent_id, agent_host_id, tags, name, rate, host, port, server_port,
-		cipher, obfs, obfs_settings, "show", sort, status, type, settings, last_heartbeat_at, created_at, updated_at
+		cipher, obfs, obfs_settings, "show", sort, status, type, settings, weight, traffic_ratio, last_heartbeat_at, created_at, updated_at
         FROM servers
         ORDER BY sort DESC, id ASC`
 	rows, err := r.db.QueryContext(ctx, query)
@@ -70,7 +70,7 @@ func (r *serverRepo) ListAll(ctx context.Context) ([]*repository.Server, error)
 
 func (r *serverRepo) FindByID(ctx context.Context, id int64) (*repository.Server, error) {
 	const query = `SELECT id, code, group_id, route_id, parent_id, agent_host_id, tags, name, rate, host, port, server_port,
-		cipher, obfs, obfs_settings, "show", sort, status, type, settings, last_heartbeat_at, created_at, updated_at
+		cipher, obfs, obfs_settings, "show", sort, status, type, settings, weight, traffic_ratio, last_heartbeat_at, created_at, updated_at
         FROM servers
         WHERE id = ?`
 	row := r.db.QueryRowContext(ctx, query, id)
@@ -95,7 +95,7 @@ func (r *serverRepo) FindByGroupIDs(ctx context.Context, groupIDs []int64) ([]*r
 		args[i] = id
 	}
 	query := `SELECT id, code, group_id, route_id, parent_id, agent_host_id, tags, name, rate, host, port, server_port,
-		cipher, obfs, obfs_settings, "show", sort, status, type, settings, last_heartbeat_at, created_at, updated_at
+		cipher, obfs, obfs_settings, "show", sort, status, type, settings, weight, traffic_ratio, last_heartbeat_at, created_at, updated_at
         FROM servers
         WHERE group_id IN (` + strings.Join(placeholders, ",") + `) AND "show" = 1
         ORDER BY sort DESC, id ASC`
@@ -122,8 +122,8 @@ func (r *serverRepo) FindByGroupIDs(ctx context.Context, groupIDs []int64) ([]*r
 func (r *serverRepo) Create(ctx context.Context, server *repository.Server) error {
 	const query = `INSERT INTO servers (
 		code, group_id, route_id, parent_id, agent_host_id, tags, name, rate, host, port, server_port,
-		cipher, obfs, obfs_settings, "show", sort, status, type, settings, last_heartbeat_at, created_at, updated_at
-	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+		cipher, obfs, obfs_settings, "show", sort, status, type, settings, weight, traffic_ratio, last_heartbeat_at, created_at, updated_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	now := time.Now().Unix()
 	server.CreatedAt = now
@@ -149,6 +149,8 @@ func (r *serverRepo) Create(ctx context.Context, server *repository.Server) erro
 		server.Status,
 		server.Type,
 		server.Settings,
+		server.Weight,
+		server.TrafficRatio,
 		server.LastHeartbeatAt,
 		server.CreatedAt,
 		server.UpdatedAt,
@@ -167,7 +169,7 @@ func (r *serverRepo) Create(ctx context.Context, server *repository.Server) erro
 func (r *serverRepo) Update(ctx context.Context, server *repository.Server) error {
 	const query = `UPDATE servers SET
 		code=?, group_id=?, route_id=?, parent_id=?, agent_host_id=?, tags=?, name=?, rate=?, host=?, port=?, server_port=?,
-		cipher=?, obfs=?, obfs_settings=?, "show"=?, sort=?, status=?, type=?, settings=?, last_heartbeat_at=?, updated_at=?
+		cipher=?, obfs=?, obfs_settings=?, "show"=?, sort=?, status=?, type=?, settings=?, weight=?, traffic_ratio=?, last_heartbeat_at=?, updated_at=?
 		WHERE id = ?`
 
 	server.UpdatedAt = time.Now().Unix()
@@ -192,6 +194,8 @@ func (r *serverRepo) Update(ctx context.Context, server *repository.Server) erro
 		server.Status,
 		server.Type,
 		server.Settings,
+		server.Weight,
+		server.TrafficRatio,
 		server.LastHeartbeatAt,
 		server.UpdatedAt,
 		server.ID,
@@ -213,7 +217,7 @@ func (r *serverRepo) Delete(ctx context.Context, id int64) error {
 
 func (r *serverRepo) FindByAgentHostID(ctx context.Context, agentHostID int64) ([]*repository.Server, error) {
 	const query = `SELECT id, code, group_id, route_id, parent_id, agent_host_id, tags, name, rate, host, port, server_port,
-		cipher, obfs, obfs_settings, "show", sort, status, type, settings, last_heartbeat_at, created_at, updated_at
+		cipher, obfs, obfs_settings, "show", sort, status, type, settings, weight, traffic_ratio, last_heartbeat_at, created_at, updated_at
         FROM servers
         WHERE agent_host_id = ?
         ORDER BY sort DESC, id ASC`
@@ -275,26 +279,16 @@ type serverRouteRepo struct {
 	db *sql.DB
 }
 
+const serverRouteColumns = `id, COALESCE(remarks, ''), match_type, COALESCE(match, ''), COALESCE(action, ''), COALESCE(action_value, ''), priority, created_at, updated_at`
+
 func (r *serverRouteRepo) List(ctx context.Context) ([]*repository.ServerRoute, error) {
-	const query = `SELECT id, remarks, match, action, action_value, created_at, updated_at FROM server_routes ORDER BY id ASC`
+	query := `SELECT ` + serverRouteColumns + ` FROM server_routes ORDER BY priority ASC, id ASC`
 	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-
-	var routes []*repository.ServerRoute
-	for rows.Next() {
-		route := new(repository.ServerRoute)
-		if err := rows.Scan(&route.ID, &route.Remarks, &route.Match, &route.Action, &route.ActionValue, &route.CreatedAt, &route.UpdatedAt); err != nil {
-			return nil, err
-		}
-		routes = append(routes, route)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return routes, nil
+	return scanServerRoutes(rows)
 }
 
 func (r *serverRouteRepo) FindByIDs(ctx context.Context, ids []int64) ([]*repository.ServerRoute, error) {
@@ -307,20 +301,101 @@ func (r *serverRouteRepo) FindByIDs(ctx context.Context, ids []int64) ([]*reposi
 		placeholders[i] = "?"
 		args[i] = id
 	}
-	query := `SELECT id, remarks, match, action, action_value, created_at, updated_at
+	query := `SELECT ` + serverRouteColumns + `
 		       FROM server_routes
 		       WHERE id IN (` + strings.Join(placeholders, ",") + `)
-		       ORDER BY id ASC`
+		       ORDER BY priority ASC, id ASC`
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
+	return scanServerRoutes(rows)
+}
+
+func (r *serverRouteRepo) FindByID(ctx context.Context, id int64) (*repository.ServerRoute, error) {
+	query := `SELECT ` + serverRouteColumns + ` FROM server_routes WHERE id = ?`
+	route, err := scanServerRoute(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return route, nil
+}
+
+func (r *serverRouteRepo) Create(ctx context.Context, route *repository.ServerRoute) (*repository.ServerRoute, error) {
+	const query = `INSERT INTO server_routes (remarks, match_type, match, action, action_value, priority, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	now := time.Now().Unix()
+	if route.CreatedAt == 0 {
+		route.CreatedAt = now
+	}
+	if route.UpdatedAt == 0 {
+		route.UpdatedAt = now
+	}
+	res, err := r.db.ExecContext(ctx, query,
+		route.Remarks,
+		route.MatchType,
+		string(route.Match),
+		route.Action,
+		route.ActionValue,
+		route.Priority,
+		route.CreatedAt,
+		route.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	route.ID = id
+	return route, nil
+}
 
+func (r *serverRouteRepo) Update(ctx context.Context, route *repository.ServerRoute) error {
+	const query = `UPDATE server_routes
+		SET remarks = ?, match_type = ?, match = ?, action = ?, action_value = ?, priority = ?, updated_at = ?
+		WHERE id = ?`
+	route.UpdatedAt = time.Now().Unix()
+	res, err := r.db.ExecContext(ctx, query,
+		route.Remarks,
+		route.MatchType,
+		string(route.Match),
+		route.Action,
+		route.ActionValue,
+		route.Priority,
+		route.UpdatedAt,
+		route.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *serverRouteRepo) Delete(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM server_routes WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func scanServerRoutes(rows *sql.Rows) ([]*repository.ServerRoute, error) {
 	var routes []*repository.ServerRoute
 	for rows.Next() {
-		route := new(repository.ServerRoute)
-		if err := rows.Scan(&route.ID, &route.Remarks, &route.Match, &route.Action, &route.ActionValue, &route.CreatedAt, &route.UpdatedAt); err != nil {
+		route, err := scanServerRoute(rows)
+		if err != nil {
 			return nil, err
 		}
 		routes = append(routes, route)
@@ -331,6 +406,18 @@ func (r *serverRouteRepo) FindByIDs(ctx context.Context, ids []int64) ([]*reposi
 	return routes, nil
 }
 
+func scanServerRoute(scanner serverScanner) (*repository.ServerRoute, error) {
+	route := new(repository.ServerRoute)
+	var match string
+	if err := scanner.Scan(&route.ID, &route.Remarks, &route.MatchType, &match, &route.Action, &route.ActionValue, &route.Priority, &route.CreatedAt, &route.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if match != "" {
+		route.Match = json.RawMessage(match)
+	}
+	return route, nil
+}
+
 type serverScanner interface {
 	Scan(dest ...any) error
 }
@@ -368,6 +455,8 @@ func scanServer(scanner serverScanner) (*repository.Server, error) {
 		&server.Status,
 		&server.Type,
 		&settings,
+		&server.Weight,
+		&server.TrafficRatio,
 		&server.LastHeartbeatAt,
 		&server.CreatedAt,
 		&server.UpdatedAt,
@@ -406,7 +495,7 @@ func (r *serverRepo) FindByIdentifier(ctx context.Context, identifier string, no
 		return nil, repository.ErrNotFound
 	}
 	const baseQuery = `SELECT id, code, group_id, route_id, parent_id, agent_host_id, tags, name, rate, host, port, server_port,
-		cipher, obfs, obfs_settings, "show", sort, status, type, settings, last_heartbeat_at, created_at, updated_at FROM servers`
+		cipher, obfs, obfs_settings, "show", sort, status, type, settings, weight, traffic_ratio, last_heartbeat_at, created_at, updated_at FROM servers`
 	conditions := make([]string, 0, 3)
 	args := make([]any, 0, 4)
 	if id, err := strconv.ParseInt(trimmed, 10, 64); err == nil {
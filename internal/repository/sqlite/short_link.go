@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
 
 	"github.com/creamcroissant/xboard/internal/repository"
 )
@@ -217,6 +218,98 @@ func (r *shortLinkRepo) IncrementAccessCount(ctx context.Context, id int64, acce
 	return err
 }
 
+func (r *shortLinkRepo) AddAccessCount(ctx context.Context, id int64, delta int64, lastAccessedAt int64) error {
+	query := `
+		UPDATE short_links
+		SET access_count = access_count + ?,
+			last_accessed_at = MAX(COALESCE(last_accessed_at, 0), ?),
+			updated_at = ?
+		WHERE id = ?
+	`
+	_, err := r.db.ExecContext(ctx, query, delta, lastAccessedAt, lastAccessedAt, id)
+	return err
+}
+
+func (r *shortLinkRepo) List(ctx context.Context, filter repository.ShortLinkFilter) ([]*repository.ShortLink, error) {
+	where, args := buildShortLinkFilter(filter)
+	limit, offset := normalizePagination(filter.Limit, filter.Offset, 20)
+	query := `
+		SELECT id, code, user_id, target_path, custom_params, expires_at, access_count, last_accessed_at, created_at, updated_at
+		FROM short_links` + where + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT ? OFFSET ?
+	`
+	args = append(args, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var links []*repository.ShortLink
+	for rows.Next() {
+		link := &repository.ShortLink{}
+		var customParams sql.NullString
+		var expiresAtInt, lastAccessedAtInt sql.NullInt64
+
+		err := rows.Scan(
+			&link.ID,
+			&link.Code,
+			&link.UserID,
+			&link.TargetPath,
+			&customParams,
+			&expiresAtInt,
+			&link.AccessCount,
+			&lastAccessedAtInt,
+			&link.CreatedAt,
+			&link.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if customParams.Valid {
+			link.CustomParams = customParams.String
+		}
+		if expiresAtInt.Valid {
+			link.ExpiresAt = expiresAtInt.Int64
+		}
+		if lastAccessedAtInt.Valid {
+			link.LastAccessedAt = lastAccessedAtInt.Int64
+		}
+
+		links = append(links, link)
+	}
+
+	return links, rows.Err()
+}
+
+func (r *shortLinkRepo) Count(ctx context.Context, filter repository.ShortLinkFilter) (int64, error) {
+	where, args := buildShortLinkFilter(filter)
+	var count int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM short_links`+where, args...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func buildShortLinkFilter(filter repository.ShortLinkFilter) (string, []any) {
+	var conditions []string
+	var args []any
+	if filter.UserID != nil {
+		conditions = append(conditions, "user_id = ?")
+		args = append(args, *filter.UserID)
+	}
+	if filter.Code != nil && strings.TrimSpace(*filter.Code) != "" {
+		conditions = append(conditions, "code LIKE ?")
+		args = append(args, "%"+strings.TrimSpace(*filter.Code)+"%")
+	}
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
 func (r *shortLinkRepo) CodeExists(ctx context.Context, code string) (bool, error) {
 	query := `SELECT 1 FROM short_links WHERE code = ? LIMIT 1`
 	var exists int
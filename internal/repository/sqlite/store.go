@@ -14,6 +14,8 @@ type Store struct {
 	coreOperations         repository.CoreOperationRepository
 	operationLogs          repository.OperationLogRepository
 	binaryVersionStates    repository.BinaryVersionStateRepository
+	agentHostDiagnostics   repository.AgentHostDiagnosticsRepository
+	agentHostPathUsage     repository.AgentHostPathUsageRepository
 	agentLifecycleOps      repository.AgentLifecycleOperationRepository
 	agentTrafficPolicies   repository.AgentTrafficPolicyRepository
 	agentTrafficStates     repository.AgentTrafficStateRepository
@@ -25,6 +27,9 @@ type Store struct {
 	plugins                repository.PluginRepository
 	plans                  repository.PlanRepository
 	loginLogs              repository.LoginLogRepository
+	loginLockouts          repository.LoginLockoutRepository
+	auditLogs              repository.AuditLogRepository
+	userReminders          repository.UserReminderRepository
 	tokens                 repository.TokenRepository
 	servers                repository.ServerRepository
 	groups                 repository.ServerGroupRepository
@@ -36,6 +41,7 @@ type Store struct {
 	subLogs                repository.SubscriptionLogRepository
 	agentHosts             repository.AgentHostRepository
 	configTemplates        repository.ConfigTemplateRepository
+	configTemplateVersions repository.ConfigTemplateVersionRepository
 	serverClientConfigs    repository.ServerClientConfigRepository
 	userTraffic            repository.UserTrafficRepository
 	shortLinks             repository.ShortLinkRepository
@@ -51,6 +57,7 @@ type Store struct {
 	desiredArtifacts       repository.DesiredArtifactRepository
 	applyRuns              repository.ApplyRunRepository
 	trafficReportDedups    repository.TrafficReportDedupRepository
+	userOnlineDevices      repository.UserOnlineDeviceRepository
 	agentConfigInventories repository.AgentConfigInventoryRepository
 	inboundIndexes         repository.InboundIndexRepository
 	driftStates            repository.DriftStateRepository
@@ -69,6 +76,8 @@ func NewStore(db *sql.DB) *Store {
 		coreOperations:         newCoreOperationRepo(db),
 		operationLogs:          newOperationLogRepo(db),
 		binaryVersionStates:    newBinaryVersionStateRepo(db),
+		agentHostDiagnostics:   newAgentHostDiagnosticsRepo(db),
+		agentHostPathUsage:     newAgentHostPathUsageRepo(db),
 		agentLifecycleOps:      newAgentLifecycleOperationRepo(db),
 		agentTrafficPolicies:   newAgentTrafficPolicyRepo(db),
 		agentTrafficStates:     newAgentTrafficStateRepo(db),
@@ -80,6 +89,9 @@ func NewStore(db *sql.DB) *Store {
 		plugins:                &pluginRepo{db: db},
 		plans:                  &planRepo{db: db},
 		loginLogs:              &loginLogRepo{db: db},
+		loginLockouts:          &loginLockoutRepo{db: db},
+		auditLogs:              &auditLogRepo{db: db},
+		userReminders:          &userReminderRepo{db: db},
 		tokens:                 &tokenRepo{db: db},
 		servers:                &serverRepo{db: db},
 		groups:                 &serverGroupRepo{db: db},
@@ -91,6 +103,7 @@ func NewStore(db *sql.DB) *Store {
 		subLogs:                &subscriptionLogRepo{db: db},
 		agentHosts:             newAgentHostRepo(db),
 		configTemplates:        newConfigTemplateRepo(db),
+		configTemplateVersions: newConfigTemplateVersionRepo(db),
 		serverClientConfigs:    newServerClientConfigRepo(db),
 		userTraffic:            newUserTrafficRepo(db),
 		shortLinks:             NewShortLinkRepository(db),
@@ -106,6 +119,7 @@ func NewStore(db *sql.DB) *Store {
 		desiredArtifacts:       newDesiredArtifactRepo(db),
 		applyRuns:              newApplyRunRepo(db),
 		trafficReportDedups:    newTrafficReportDedupRepo(db),
+		userOnlineDevices:      newUserOnlineDeviceRepo(db),
 		agentConfigInventories: newAgentConfigInventoryRepo(db),
 		inboundIndexes:         newInboundIndexRepo(db),
 		driftStates:            newDriftStateRepo(db),
@@ -130,6 +144,14 @@ func (s *Store) BinaryVersionStates() repository.BinaryVersionStateRepository {
 	return s.binaryVersionStates
 }
 
+func (s *Store) AgentHostDiagnostics() repository.AgentHostDiagnosticsRepository {
+	return s.agentHostDiagnostics
+}
+
+func (s *Store) AgentHostPathUsage() repository.AgentHostPathUsageRepository {
+	return s.agentHostPathUsage
+}
+
 func (s *Store) AgentLifecycleOperations() repository.AgentLifecycleOperationRepository {
 	return s.agentLifecycleOps
 }
@@ -174,6 +196,18 @@ func (s *Store) LoginLogs() repository.LoginLogRepository {
 	return s.loginLogs
 }
 
+func (s *Store) LoginLockouts() repository.LoginLockoutRepository {
+	return s.loginLockouts
+}
+
+func (s *Store) AuditLogs() repository.AuditLogRepository {
+	return s.auditLogs
+}
+
+func (s *Store) UserReminders() repository.UserReminderRepository {
+	return s.userReminders
+}
+
 func (s *Store) Tokens() repository.TokenRepository {
 	return s.tokens
 }
@@ -218,6 +252,10 @@ func (s *Store) ConfigTemplates() repository.ConfigTemplateRepository {
 	return s.configTemplates
 }
 
+func (s *Store) ConfigTemplateVersions() repository.ConfigTemplateVersionRepository {
+	return s.configTemplateVersions
+}
+
 func (s *Store) ServerClientConfigs() repository.ServerClientConfigRepository {
 	return s.serverClientConfigs
 }
@@ -278,6 +316,10 @@ func (s *Store) TrafficReportDedups() repository.TrafficReportDedupRepository {
 	return s.trafficReportDedups
 }
 
+func (s *Store) UserOnlineDevices() repository.UserOnlineDeviceRepository {
+	return s.userOnlineDevices
+}
+
 func (s *Store) AgentConfigInventories() repository.AgentConfigInventoryRepository {
 	return s.agentConfigInventories
 }
@@ -3,6 +3,8 @@ package sqlite
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"strings"
 	"time"
 
 	"github.com/creamcroissant/xboard/internal/repository"
@@ -71,3 +73,107 @@ func (r *subscriptionLogRepo) GetRecentLogs(ctx context.Context, userID int64, l
 	}
 	return logs, rows.Err()
 }
+
+func (r *subscriptionLogRepo) CountUniqueIPsByUser(ctx context.Context, filter repository.SubscriptionLogFilter) ([]*repository.SubscriptionUserIPCount, error) {
+	query := strings.Builder{}
+	args := make([]any, 0, 5)
+	query.WriteString(`SELECT
+		l.user_id, COALESCE(u.email, ''), COUNT(DISTINCT l.client_ip), COUNT(*), MAX(l.created_at)
+		FROM subscription_logs l
+		LEFT JOIN users u ON u.id = l.user_id
+		WHERE l.client_ip != '' AND l.created_at >= ? AND l.created_at < ?`)
+	args = append(args, filter.Since, filter.Until)
+	if filter.UserID != nil {
+		query.WriteString(" AND l.user_id = ?")
+		args = append(args, *filter.UserID)
+	}
+	query.WriteString(" GROUP BY l.user_id")
+	if filter.MinUniqueIPs > 0 {
+		query.WriteString(" HAVING COUNT(DISTINCT l.client_ip) >= ?")
+		args = append(args, filter.MinUniqueIPs)
+	}
+	query.WriteString(" ORDER BY COUNT(DISTINCT l.client_ip) DESC, l.user_id ASC LIMIT ?")
+	args = append(args, filter.Limit)
+
+	rows, err := r.db.QueryContext(ctx, query.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*repository.SubscriptionUserIPCount
+	for rows.Next() {
+		var item repository.SubscriptionUserIPCount
+		if err := rows.Scan(&item.UserID, &item.UserEmail, &item.UniqueIPs, &item.Requests, &item.LastSeenAt); err != nil {
+			return nil, err
+		}
+		items = append(items, &item)
+	}
+	return items, rows.Err()
+}
+
+func (r *subscriptionLogRepo) CountByClientType(ctx context.Context, filter repository.SubscriptionLogFilter) ([]*repository.SubscriptionClientCount, error) {
+	query := strings.Builder{}
+	args := make([]any, 0, 3)
+	query.WriteString(`SELECT
+		request_type, COUNT(*), COUNT(DISTINCT user_id)
+		FROM subscription_logs
+		WHERE created_at >= ? AND created_at < ?`)
+	args = append(args, filter.Since, filter.Until)
+	if filter.UserID != nil {
+		query.WriteString(" AND user_id = ?")
+		args = append(args, *filter.UserID)
+	}
+	query.WriteString(" GROUP BY request_type ORDER BY COUNT(*) DESC, request_type ASC")
+
+	rows, err := r.db.QueryContext(ctx, query.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*repository.SubscriptionClientCount
+	for rows.Next() {
+		var item repository.SubscriptionClientCount
+		if err := rows.Scan(&item.Client, &item.Requests, &item.Users); err != nil {
+			return nil, err
+		}
+		items = append(items, &item)
+	}
+	return items, rows.Err()
+}
+
+func (r *subscriptionLogRepo) ListDistinctIPs(ctx context.Context, filter repository.SubscriptionLogFilter) ([]*repository.SubscriptionIPActivity, error) {
+	if filter.UserID == nil {
+		return nil, errors.New("user id is required")
+	}
+	// 最近一次使用的客户端取同一 IP 下 created_at 最大的记录
+	const query = `SELECT
+		l.client_ip, COUNT(*), MIN(l.created_at), MAX(l.created_at),
+		COALESCE((
+			SELECT s.request_type FROM subscription_logs s
+			WHERE s.user_id = l.user_id AND s.client_ip = l.client_ip
+			ORDER BY s.created_at DESC, s.id DESC LIMIT 1
+		), '')
+		FROM subscription_logs l
+		WHERE l.user_id = ? AND l.client_ip != '' AND l.created_at >= ? AND l.created_at < ?
+		GROUP BY l.client_ip
+		ORDER BY MAX(l.created_at) DESC
+		LIMIT ?`
+
+	rows, err := r.db.QueryContext(ctx, query, *filter.UserID, filter.Since, filter.Until, filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*repository.SubscriptionIPActivity
+	for rows.Next() {
+		var item repository.SubscriptionIPActivity
+		if err := rows.Scan(&item.IP, &item.Requests, &item.FirstSeenAt, &item.LastSeenAt, &item.LastClient); err != nil {
+			return nil, err
+		}
+		items = append(items, &item)
+	}
+	return items, rows.Err()
+}
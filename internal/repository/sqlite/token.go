@@ -35,8 +35,11 @@ func (r *tokenRepo) Create(ctx context.Context, token *repository.AccessToken) (
 	if token.UpdatedAt == 0 {
 		token.UpdatedAt = token.CreatedAt
 	}
-	const stmt = `INSERT INTO tokens(user_id, token, refresh_token, expires_at, refresh_expires_at, ip, user_agent, revoked, created_at, updated_at)
-                  VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	if strings.TrimSpace(token.FamilyID) == "" {
+		token.FamilyID = token.RefreshToken
+	}
+	const stmt = `INSERT INTO tokens(user_id, token, refresh_token, expires_at, refresh_expires_at, ip, user_agent, revoked, family_id, rotated_at, created_at, updated_at)
+                  VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 	res, err := r.db.ExecContext(
 		ctx,
 		stmt,
@@ -48,6 +51,8 @@ func (r *tokenRepo) Create(ctx context.Context, token *repository.AccessToken) (
 		nullableString(token.IP),
 		nullableString(token.UserAgent),
 		boolToInt(token.Revoked),
+		token.FamilyID,
+		token.RotatedAt,
 		token.CreatedAt,
 		token.UpdatedAt,
 	)
@@ -68,7 +73,7 @@ func (r *tokenRepo) FindByRefreshToken(ctx context.Context, refreshToken string)
 	if trimmed == "" {
 		return nil, repository.ErrNotFound
 	}
-	const query = `SELECT id, user_id, token, refresh_token, expires_at, refresh_expires_at, ip, user_agent, revoked, created_at, updated_at
+	const query = `SELECT id, user_id, token, refresh_token, expires_at, refresh_expires_at, ip, user_agent, revoked, family_id, rotated_at, created_at, updated_at
                    FROM tokens WHERE refresh_token = ? LIMIT 1`
 	row := r.db.QueryRowContext(ctx, query, trimmed)
 	var (
@@ -87,6 +92,8 @@ func (r *tokenRepo) FindByRefreshToken(ctx context.Context, refreshToken string)
 		&ip,
 		&ua,
 		&revoked,
+		&rec.FamilyID,
+		&rec.RotatedAt,
 		&rec.CreatedAt,
 		&rec.UpdatedAt,
 	); err != nil {
@@ -119,6 +126,34 @@ func (r *tokenRepo) DeleteByRefreshToken(ctx context.Context, refreshToken strin
 	return err
 }
 
+func (r *tokenRepo) MarkRotated(ctx context.Context, id int64, rotatedAt int64) (bool, error) {
+	if r == nil || r.db == nil {
+		return false, fmt.Errorf("tokenRepo is not configured")
+	}
+	// 条件更新保证并发刷新时只有一个请求能轮换成功
+	res, err := r.db.ExecContext(ctx, `UPDATE tokens SET rotated_at = ?, updated_at = ? WHERE id = ? AND rotated_at = 0 AND revoked = 0`, rotatedAt, rotatedAt, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+func (r *tokenRepo) RevokeFamily(ctx context.Context, familyID string) error {
+	if r == nil || r.db == nil {
+		return fmt.Errorf("tokenRepo is not configured")
+	}
+	trimmed := strings.TrimSpace(familyID)
+	if trimmed == "" {
+		return nil
+	}
+	_, err := r.db.ExecContext(ctx, `UPDATE tokens SET revoked = 1, updated_at = ? WHERE family_id = ?`, time.Now().Unix(), trimmed)
+	return err
+}
+
 func (r *tokenRepo) DeleteByUser(ctx context.Context, userID int64) error {
 	if r == nil || r.db == nil {
 		return fmt.Errorf("tokenRepo is not configured")
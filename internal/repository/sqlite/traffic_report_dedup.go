@@ -25,7 +25,12 @@ func (r *trafficReportDedupRepo) MarkHandled(ctx context.Context, agentHostID in
 	if reportID == "" {
 		return false, errors.New("report_id is required")
 	}
-	res, err := r.db.ExecContext(ctx, `
+	return markTrafficReportHandled(ctx, r.db, agentHostID, reportID, handledAt)
+}
+
+// markTrafficReportHandled 写入去重键；exec 可以是事务，以便与流量入账一起提交。
+func markTrafficReportHandled(ctx context.Context, exec sqlExecutor, agentHostID int64, reportID string, handledAt int64) (bool, error) {
+	res, err := exec.ExecContext(ctx, `
 		INSERT OR IGNORE INTO traffic_report_dedups (agent_host_id, report_id, handled_at)
 		VALUES (?, ?, ?)
 	`, agentHostID, reportID, handledAt)
@@ -38,3 +43,11 @@ func (r *trafficReportDedupRepo) MarkHandled(ctx context.Context, agentHostID in
 	}
 	return affected > 0, nil
 }
+
+func (r *trafficReportDedupRepo) DeleteHandledBefore(ctx context.Context, before int64) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM traffic_report_dedups WHERE handled_at < ?`, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
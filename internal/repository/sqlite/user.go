@@ -7,6 +7,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -42,7 +43,101 @@ func (r *userRepo) FindByToken(ctx context.Context, token string) (*repository.U
 	return scanUser(row)
 }
 
+func (r *userRepo) FindByUUID(ctx context.Context, uuid string) (*repository.User, error) {
+	// 按 UUID 查询用户。
+	row := r.db.QueryRowContext(ctx, userSelectBy("uuid"), uuid)
+	return scanUser(row)
+}
+
+func (r *userRepo) FindByTelegramID(ctx context.Context, telegramID int64) (*repository.User, error) {
+	// 按绑定的 Telegram 账号查询用户。
+	row := r.db.QueryRowContext(ctx, userSelectBy("telegram_id"), strconv.FormatInt(telegramID, 10))
+	return scanUser(row)
+}
+
+func (r *userRepo) ListExpiringBetween(ctx context.Context, startUnix, endUnix int64) ([]*repository.User, error) {
+	// 查询在 (start, end] 区间内到期的正常用户，用于到期提醒。
+	query := userSelectBy("status") + ` AND banned = 0 AND expired_at > ? AND expired_at <= ? ORDER BY expired_at ASC`
+	return r.queryUsers(ctx, query, 1, startUnix, endUnix)
+}
+
+func (r *userRepo) ListTrafficRemainingBelow(ctx context.Context, thresholdBytes int64, nowUnix int64) ([]*repository.User, error) {
+	// 查询剩余流量低于阈值但尚未用尽的正常用户，已过期用户不参与。
+	query := userSelectBy("status") + ` AND banned = 0 AND transfer_enable > 0
+		AND u + d < transfer_enable AND transfer_enable - (u + d) < ?
+		AND (expired_at = 0 OR expired_at > ?) ORDER BY id ASC`
+	return r.queryUsers(ctx, query, 1, thresholdBytes, nowUnix)
+}
+
+func (r *userRepo) queryUsers(ctx context.Context, query string, args ...any) ([]*repository.User, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*repository.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
 func (r *userRepo) Save(ctx context.Context, user *repository.User) error {
+	return saveUser(ctx, r.db, user)
+}
+
+// SaveBatch 在同一事务内保存多名用户，任一失败则整体回滚。
+func (r *userRepo) SaveBatch(ctx context.Context, users []*repository.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, user := range users {
+		if err := saveUser(ctx, tx, user); err != nil {
+			return fmt.Errorf("save user %d: %w", user.ID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// SaveIfUnmodified 在事务内比对 updated_at 后保存，防止两个管理员同时编辑时后写覆盖先写。
+func (r *userRepo) SaveIfUnmodified(ctx context.Context, user *repository.User, expectedUpdatedAt int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	var current int64
+	if err := tx.QueryRowContext(ctx, `SELECT updated_at FROM users WHERE id = ?`, user.ID).Scan(&current); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.ErrNotFound
+		}
+		return err
+	}
+	if current != expectedUpdatedAt {
+		return repository.ErrConflict
+	}
+	if err := saveUserAt(ctx, tx, user, nextUpdatedAt(time.Now().Unix(), expectedUpdatedAt)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func saveUser(ctx context.Context, execer sqlExecutor, user *repository.User) error {
+	return saveUserAt(ctx, execer, user, time.Now().Unix())
+}
+
+// saveUserAt 以指定时间作为 updated_at 写入用户。
+func saveUserAt(ctx context.Context, execer sqlExecutor, user *repository.User, now int64) error {
 	// Upsert 用户记录，维护更新时间。
 	const stmt = `INSERT INTO users(
 		id,
@@ -73,9 +168,10 @@ func (r *userRepo) Save(ctx context.Context, user *repository.User) error {
 		last_login_at,
 		remarks,
 		tags,
+		subscription_template_id,
 		created_at,
 		updated_at)
-		              VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		              VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	              ON CONFLICT(id) DO UPDATE SET
 	                uuid = excluded.uuid,
 	                is_admin = excluded.is_admin,
@@ -104,9 +200,9 @@ func (r *userRepo) Save(ctx context.Context, user *repository.User) error {
 	                last_login_at = excluded.last_login_at,
 					remarks = excluded.remarks,
 					tags = excluded.tags,
+					subscription_template_id = excluded.subscription_template_id,
 	                updated_at = excluded.updated_at`
 
-	now := time.Now().Unix()
 	if user.CreatedAt == 0 {
 		user.CreatedAt = now
 	}
@@ -116,7 +212,7 @@ func (r *userRepo) Save(ctx context.Context, user *repository.User) error {
 	if err != nil {
 		return fmt.Errorf("encode user tags: %w", err)
 	}
-	_, err = r.db.ExecContext(ctx, stmt,
+	_, err = execer.ExecContext(ctx, stmt,
 		user.ID,
 		user.UUID,
 		user.Token,
@@ -145,6 +241,7 @@ func (r *userRepo) Save(ctx context.Context, user *repository.User) error {
 		user.LastLoginAt,
 		user.Remarks,
 		tags,
+		nullableInt(user.SubscriptionTemplateID),
 		user.CreatedAt,
 		user.UpdatedAt,
 	)
@@ -179,9 +276,10 @@ func (r *userRepo) Create(ctx context.Context, user *repository.User) (*reposito
 		last_login_at,
 		remarks,
 		tags,
+		subscription_template_id,
 		created_at,
 		updated_at)
-		              VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+		              VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 	now := time.Now().Unix()
 	user.CreatedAt = now
 	user.UpdatedAt = now
@@ -216,6 +314,7 @@ func (r *userRepo) Create(ctx context.Context, user *repository.User) (*reposito
 		user.LastLoginAt,
 		user.Remarks,
 		tags,
+		nullableInt(user.SubscriptionTemplateID),
 		user.CreatedAt,
 		user.UpdatedAt,
 	)
@@ -371,28 +470,9 @@ func (r *userRepo) PlanCounts(ctx context.Context, planIDs []int64, nowUnix int6
 func (r *userRepo) Search(ctx context.Context, filter repository.UserSearchFilter) ([]*repository.User, error) {
 	baseQuery := `SELECT id, uuid, token, username, email, password, password_algo, password_salt, balance, plan_id,
 		group_id, expired_at, u, d, transfer_enable, speed_limit, device_limit, commission_balance, is_admin, status,
-		banned, traffic_exceeded, invite_user_id, invite_limit, last_login_at, remarks, tags, created_at, updated_at FROM users`
-	var conds []string
-	var args []any
-
-	if filter.Keyword != "" {
-		like := "%" + filter.Keyword + "%"
-		conds = append(conds, "(email LIKE ? OR username LIKE ? OR remarks LIKE ?)")
-		args = append(args, like, like, like)
-	}
-	if filter.Status != nil {
-		conds = append(conds, "status = ?")
-		args = append(args, *filter.Status)
-	}
-	if filter.PlanID != nil {
-		conds = append(conds, "plan_id = ?")
-		args = append(args, *filter.PlanID)
-	}
-
-	query := baseQuery
-	if len(conds) > 0 {
-		query += " WHERE " + strings.Join(conds, " AND ")
-	}
+		banned, traffic_exceeded, telegram_id, invite_user_id, invite_limit, last_login_at, remarks, tags, subscription_template_id, created_at, updated_at FROM users`
+	where, args := userSearchConditions(filter)
+	query := baseQuery + where
 
 	// Pagination
 	limit := 20
@@ -403,7 +483,7 @@ func (r *userRepo) Search(ctx context.Context, filter repository.UserSearchFilte
 	if filter.Offset > 0 {
 		offset = filter.Offset
 	}
-	query += " ORDER BY id DESC LIMIT ? OFFSET ?"
+	query += userSearchOrderBy(filter) + " LIMIT ? OFFSET ?"
 	args = append(args, limit, offset)
 
 	rows, err := r.db.QueryContext(ctx, query, args...)
@@ -424,7 +504,14 @@ func (r *userRepo) Search(ctx context.Context, filter repository.UserSearchFilte
 }
 
 func (r *userRepo) CountFiltered(ctx context.Context, filter repository.UserSearchFilter) (int64, error) {
-	query := "SELECT COUNT(*) FROM users"
+	where, args := userSearchConditions(filter)
+	var count int64
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users"+where, args...).Scan(&count)
+	return count, err
+}
+
+// userSearchConditions 把管理端筛选条件翻译为 WHERE 子句，Search 与 CountFiltered 共用。
+func userSearchConditions(filter repository.UserSearchFilter) (string, []any) {
 	var conds []string
 	var args []any
 
@@ -441,14 +528,48 @@ func (r *userRepo) CountFiltered(ctx context.Context, filter repository.UserSear
 		conds = append(conds, "plan_id = ?")
 		args = append(args, *filter.PlanID)
 	}
+	if filter.Banned != nil {
+		conds = append(conds, "banned = ?")
+		args = append(args, *filter.Banned)
+	}
+	if filter.OverQuota {
+		conds = append(conds, "transfer_enable > 0 AND (u + d) >= transfer_enable")
+	}
+	if filter.ExpiringWithinDays > 0 {
+		now := filter.Now
+		if now == 0 {
+			now = time.Now().Unix()
+		}
+		// 已过期与永不过期（0）的用户都不算即将到期
+		conds = append(conds, "expired_at > ? AND expired_at <= ?")
+		args = append(args, now, now+int64(filter.ExpiringWithinDays)*86400)
+	}
 
-	if len(conds) > 0 {
-		query += " WHERE " + strings.Join(conds, " AND ")
+	if len(conds) == 0 {
+		return "", args
 	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
 
-	var count int64
-	err := r.db.QueryRowContext(ctx, query, args...).Scan(&count)
-	return count, err
+// userSearchOrderBy 生成排序子句，列名来自白名单；追加 id 作为次序键保证翻页稳定。
+func userSearchOrderBy(filter repository.UserSearchFilter) string {
+	direction := "DESC"
+	if strings.EqualFold(filter.SortOrder, repository.SortOrderAsc) {
+		direction = "ASC"
+	}
+	switch filter.SortBy {
+	case repository.UserSortTrafficUsed:
+		// 与 idx_users_traffic_used 的表达式保持一致才能走索引
+		return " ORDER BY (u + d) " + direction + ", id " + direction
+	case repository.UserSortExpiredAt:
+		return " ORDER BY expired_at " + direction + ", id " + direction
+	case repository.UserSortLastLoginAt:
+		return " ORDER BY last_login_at " + direction + ", id " + direction
+	case repository.UserSortCreatedAt:
+		return " ORDER BY created_at " + direction + ", id " + direction
+	default:
+		return " ORDER BY id " + direction
+	}
 }
 
 type userScanner interface {
@@ -457,11 +578,12 @@ type userScanner interface {
 
 func scanUser(row userScanner) (*repository.User, error) {
 	var user repository.User
-	var speedLimit, deviceLimit sql.NullInt64
+	var speedLimit, deviceLimit, subscriptionTemplateID sql.NullInt64
 	var remarks, tags sql.NullString
 	var uuid, token, username, algo, salt string
 	var lastLogin int64
 	var trafficExceeded int
+	var telegramID sql.NullString
 
 	var u = &user
 	if err := row.Scan(
@@ -487,11 +609,13 @@ func scanUser(row userScanner) (*repository.User, error) {
 		&u.Status,
 		&u.Banned,
 		&trafficExceeded,
+		&telegramID,
 		&u.InviteUserID,
 		&u.InviteLimit,
 		&lastLogin,
 		&remarks,
 		&tags,
+		&subscriptionTemplateID,
 		&u.CreatedAt,
 		&u.UpdatedAt,
 	); err != nil {
@@ -507,8 +631,13 @@ func scanUser(row userScanner) (*repository.User, error) {
 	u.PasswordSalt = salt
 	u.LastLoginAt = lastLogin
 	u.TrafficExceeded = trafficExceeded == 1
+	// telegram_id 列为 TEXT，历史数据可能为 NULL 或空串
+	if telegramID.Valid {
+		u.TelegramID, _ = strconv.ParseInt(strings.TrimSpace(telegramID.String), 10, 64)
+	}
 	user.SpeedLimit = nullableIntPtr(speedLimit)
 	user.DeviceLimit = nullableIntPtr(deviceLimit)
+	user.SubscriptionTemplateID = nullableIntPtr(subscriptionTemplateID)
 	if remarks.Valid {
 		user.Remarks = remarks.String
 	}
@@ -523,11 +652,11 @@ func scanUser(row userScanner) (*repository.User, error) {
 func userSelectBy(field string) string {
 	const cols = `id, uuid, token, username, email, password, password_algo, password_salt, balance, plan_id,
 		group_id, expired_at, u, d, transfer_enable, speed_limit, device_limit, commission_balance, is_admin, status,
-		banned, traffic_exceeded, invite_user_id, invite_limit, last_login_at, remarks, tags, created_at, updated_at`
+		banned, traffic_exceeded, telegram_id, invite_user_id, invite_limit, last_login_at, remarks, tags, subscription_template_id, created_at, updated_at`
 	return fmt.Sprintf("SELECT %s FROM users WHERE %s = ?", cols, field)
 }
 
-const userSelectColumns = `id, uuid, token, username, email, password, password_algo, password_salt, balance, plan_id, group_id, expired_at, u, d, transfer_enable, speed_limit, device_limit, commission_balance, is_admin, status, banned, traffic_exceeded, invite_user_id, invite_limit, last_login_at, remarks, tags, created_at, updated_at`
+const userSelectColumns = `id, uuid, token, username, email, password, password_algo, password_salt, balance, plan_id, group_id, expired_at, u, d, transfer_enable, speed_limit, device_limit, commission_balance, is_admin, status, banned, traffic_exceeded, telegram_id, invite_user_id, invite_limit, last_login_at, remarks, tags, subscription_template_id, created_at, updated_at`
 
 // SetTrafficExceeded updates the traffic_exceeded flag for a user.
 func (r *userRepo) SetTrafficExceeded(ctx context.Context, userID int64, exceeded bool) error {
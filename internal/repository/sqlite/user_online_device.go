@@ -0,0 +1,140 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+type userOnlineDeviceRepo struct {
+	db *sql.DB
+}
+
+func newUserOnlineDeviceRepo(db *sql.DB) repository.UserOnlineDeviceRepository {
+	return &userOnlineDeviceRepo{db: db}
+}
+
+func (r *userOnlineDeviceRepo) UpsertBatch(ctx context.Context, devices []*repository.UserOnlineDevice) error {
+	if len(devices) == 0 {
+		return nil
+	}
+	for _, item := range devices {
+		if item == nil {
+			return errors.New("user online device item is nil")
+		}
+		if item.AgentHostID <= 0 || item.UserID <= 0 {
+			return errors.New("agent_host_id and user_id must be positive")
+		}
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO user_online_devices (agent_host_id, user_id, ip, last_seen_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(agent_host_id, user_id, ip)
+		DO UPDATE SET last_seen_at = MAX(user_online_devices.last_seen_at, excluded.last_seen_at)
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, item := range devices {
+		if _, err := stmt.ExecContext(ctx, item.AgentHostID, item.UserID, strings.TrimSpace(item.IP), item.LastSeenAt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *userOnlineDeviceRepo) CountDistinctIPs(ctx context.Context, userIDs []int64, since int64) (map[int64]int, error) {
+	counts := make(map[int64]int, len(userIDs))
+	if len(userIDs) == 0 {
+		return counts, nil
+	}
+	placeholders := make([]string, len(userIDs))
+	args := make([]any, 0, len(userIDs)+1)
+	for i, id := range userIDs {
+		placeholders[i] = "?"
+		args = append(args, id)
+	}
+	args = append(args, since)
+	query := `SELECT user_id, COUNT(DISTINCT ip)
+		FROM user_online_devices
+		WHERE user_id IN (` + strings.Join(placeholders, ",") + `) AND last_seen_at >= ?
+		GROUP BY user_id`
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID int64
+		var count int
+		if err := rows.Scan(&userID, &count); err != nil {
+			return nil, err
+		}
+		counts[userID] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+func (r *userOnlineDeviceRepo) ListOverDeviceLimit(ctx context.Context, userIDs []int64, since int64) ([]int64, error) {
+	result := make([]int64, 0)
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+	placeholders := make([]string, len(userIDs))
+	args := make([]any, 0, len(userIDs)+1)
+	for i, id := range userIDs {
+		placeholders[i] = "?"
+		args = append(args, id)
+	}
+	args = append(args, since)
+	// device_limit 为空或 <=0 表示不限制
+	query := `SELECT d.user_id
+		FROM user_online_devices d
+		JOIN users u ON u.id = d.user_id
+		WHERE d.user_id IN (` + strings.Join(placeholders, ",") + `) AND d.last_seen_at >= ?
+			AND u.device_limit IS NOT NULL AND u.device_limit > 0
+		GROUP BY d.user_id, u.device_limit
+		HAVING COUNT(DISTINCT d.ip) > u.device_limit
+		ORDER BY d.user_id`
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID int64
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		result = append(result, userID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *userOnlineDeviceRepo) DeleteBefore(ctx context.Context, before int64) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM user_online_devices WHERE last_seen_at < ?`, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,68 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// userReminderRepo 记录用户提醒的最近发送时间。
+type userReminderRepo struct {
+	db *sql.DB
+}
+
+func (r *userReminderRepo) LastReminded(ctx context.Context, kind string, userIDs []int64) (map[int64]int64, error) {
+	if r == nil || r.db == nil {
+		return nil, fmt.Errorf("user reminder repository not configured / 用户提醒仓储未配置")
+	}
+	result := make(map[int64]int64, len(userIDs))
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+	// 分批查询，避免超出 SQLite 的参数数量上限
+	const batchSize = 500
+	for start := 0; start < len(userIDs); start += batchSize {
+		end := min(start+batchSize, len(userIDs))
+		batch := userIDs[start:end]
+		args := make([]any, 0, len(batch)+1)
+		args = append(args, kind)
+		for _, id := range batch {
+			args = append(args, id)
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(batch)), ",")
+		rows, err := r.db.QueryContext(ctx, `SELECT user_id, last_reminded_at FROM user_reminders WHERE kind = ? AND user_id IN (`+placeholders+`)`, args...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var userID, remindedAt int64
+			if err := rows.Scan(&userID, &remindedAt); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			result[userID] = remindedAt
+		}
+		if err := rows.Err(); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		rows.Close()
+	}
+	return result, nil
+}
+
+func (r *userReminderRepo) MarkReminded(ctx context.Context, userID int64, kind string, remindedAt int64) error {
+	if r == nil || r.db == nil {
+		return fmt.Errorf("user reminder repository not configured / 用户提醒仓储未配置")
+	}
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO user_reminders(user_id, kind, last_reminded_at, updated_at)
+		VALUES(?, ?, ?, ?)
+		ON CONFLICT(user_id, kind) DO UPDATE SET
+			last_reminded_at = excluded.last_reminded_at,
+			updated_at = excluded.updated_at
+	`, userID, kind, remindedAt, time.Now().Unix())
+	return err
+}
@@ -106,7 +106,7 @@ func (r *userTrafficRepo) ReplaceUserSelections(ctx context.Context, userID int6
 func (r *userTrafficRepo) GetCurrentPeriod(ctx context.Context, userID int64) (*repository.UserTrafficPeriod, error) {
 	now := time.Now().Unix()
 	row := r.db.QueryRowContext(ctx, `
-		SELECT id, user_id, period_start, period_end, upload_bytes, download_bytes, quota_bytes, exceeded, created_at, updated_at
+		SELECT id, user_id, period_start, period_end, upload_bytes, download_bytes, raw_upload_bytes, raw_download_bytes, quota_bytes, exceeded, warned, created_at, updated_at
 		FROM user_traffic_periods
 		WHERE user_id = ? AND period_start <= ? AND period_end > ?
 		ORDER BY period_start DESC
@@ -114,8 +114,8 @@ func (r *userTrafficRepo) GetCurrentPeriod(ctx context.Context, userID int64) (*
 	`, userID, now, now)
 
 	var p repository.UserTrafficPeriod
-	var exceeded int
-	err := row.Scan(&p.ID, &p.UserID, &p.PeriodStart, &p.PeriodEnd, &p.UploadBytes, &p.DownloadBytes, &p.QuotaBytes, &exceeded, &p.CreatedAt, &p.UpdatedAt)
+	var exceeded, warned int
+	err := row.Scan(&p.ID, &p.UserID, &p.PeriodStart, &p.PeriodEnd, &p.UploadBytes, &p.DownloadBytes, &p.RawUploadBytes, &p.RawDownloadBytes, &p.QuotaBytes, &exceeded, &warned, &p.CreatedAt, &p.UpdatedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -123,6 +123,7 @@ func (r *userTrafficRepo) GetCurrentPeriod(ctx context.Context, userID int64) (*
 		return nil, err
 	}
 	p.Exceeded = exceeded == 1
+	p.Warned = warned == 1
 	return &p, nil
 }
 
@@ -179,20 +180,33 @@ func (r *userTrafficRepo) MarkPeriodExceeded(ctx context.Context, userID int64,
 	return err
 }
 
-// ApplyTrafficBatchAtomic applies batch traffic updates in one transaction and returns accepted items plus exceeded user IDs.
-func (r *userTrafficRepo) ApplyTrafficBatchAtomic(ctx context.Context, traffic []repository.UserTrafficDelta, nowUnix int64) ([]repository.UserTrafficDelta, []int64, error) {
+// ApplyTrafficBatchAtomic applies batch traffic updates in one transaction and returns accepted items plus
+// exceeded and soft-limit warning user IDs. When key is set the batch is marked handled in the same
+// transaction, so a batch is either applied and marked or neither; a batch marked before is skipped.
+func (r *userTrafficRepo) ApplyTrafficBatchAtomic(ctx context.Context, key *repository.TrafficBatchKey, traffic []repository.UserTrafficDelta, nowUnix int64, warnPercent int) (*repository.TrafficBatchResult, error) {
 	if len(traffic) == 0 {
-		return nil, nil, nil
+		return &repository.TrafficBatchResult{}, nil
 	}
 
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	defer tx.Rollback()
 
+	if key != nil && key.BatchID != "" {
+		marked, err := markTrafficReportHandled(ctx, tx, key.AgentHostID, key.BatchID, nowUnix)
+		if err != nil {
+			return nil, err
+		}
+		if !marked {
+			return &repository.TrafficBatchResult{Duplicate: true}, nil
+		}
+	}
+
 	accepted := make([]repository.UserTrafficDelta, 0, len(traffic))
 	exceededSet := make(map[int64]struct{})
+	warningSet := make(map[int64]struct{})
 
 	for _, sample := range traffic {
 		if sample.UserID <= 0 || (sample.Upload == 0 && sample.Download == 0) {
@@ -201,52 +215,69 @@ func (r *userTrafficRepo) ApplyTrafficBatchAtomic(ctx context.Context, traffic [
 
 		period, err := r.getCurrentPeriodTx(ctx, tx, sample.UserID, nowUnix)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 		if period == nil {
 			period, err = r.createPeriodFromUserTx(ctx, tx, sample.UserID, nowUnix)
 			if err != nil {
-				return nil, nil, err
+				return nil, err
 			}
 			if period == nil {
 				continue
 			}
 		}
 
-		if err := r.incrementPeriodTrafficTx(ctx, tx, sample.UserID, sample.Upload, sample.Download, nowUnix); err != nil {
-			return nil, nil, err
+		if err := r.incrementPeriodTrafficTx(ctx, tx, sample, nowUnix); err != nil {
+			return nil, err
 		}
 		if err := r.incrementLegacyTrafficTx(ctx, tx, sample.UserID, sample.Upload, sample.Download); err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 
-		if !period.Exceeded && period.QuotaBytes > 0 && period.UploadBytes+period.DownloadBytes+sample.Upload+sample.Download >= period.QuotaBytes {
+		used := period.UploadBytes + period.DownloadBytes + sample.Upload + sample.Download
+		if !period.Exceeded && period.QuotaBytes > 0 && used >= period.QuotaBytes {
 			if err := r.markPeriodExceededTx(ctx, tx, sample.UserID, period.PeriodStart, nowUnix); err != nil {
-				return nil, nil, err
+				return nil, err
 			}
 			if err := r.setUserTrafficExceededTx(ctx, tx, sample.UserID, true); err != nil {
-				return nil, nil, err
+				return nil, err
 			}
 			exceededSet[sample.UserID] = struct{}{}
 		}
+		// 软限额只提醒不限速；与硬限额同批越过时只报告超额
+		if warnPercent > 0 && warnPercent < 100 && !period.Warned && period.QuotaBytes > 0 && used*100 >= period.QuotaBytes*int64(warnPercent) {
+			if err := r.markPeriodWarnedTx(ctx, tx, sample.UserID, period.PeriodStart, nowUnix); err != nil {
+				return nil, err
+			}
+			if _, exceeded := exceededSet[sample.UserID]; !exceeded && !period.Exceeded {
+				warningSet[sample.UserID] = struct{}{}
+			}
+		}
 
 		accepted = append(accepted, sample)
 	}
 
 	if err := tx.Commit(); err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	exceededUserIDs := make([]int64, 0, len(exceededSet))
+	result := &repository.TrafficBatchResult{
+		Accepted:        accepted,
+		ExceededUserIDs: make([]int64, 0, len(exceededSet)),
+		WarningUserIDs:  make([]int64, 0, len(warningSet)),
+	}
 	for userID := range exceededSet {
-		exceededUserIDs = append(exceededUserIDs, userID)
+		result.ExceededUserIDs = append(result.ExceededUserIDs, userID)
 	}
-	return accepted, exceededUserIDs, nil
+	for userID := range warningSet {
+		result.WarningUserIDs = append(result.WarningUserIDs, userID)
+	}
+	return result, nil
 }
 
 func (r *userTrafficRepo) getCurrentPeriodTx(ctx context.Context, tx *sql.Tx, userID int64, nowUnix int64) (*repository.UserTrafficPeriod, error) {
 	row := tx.QueryRowContext(ctx, `
-		SELECT id, user_id, period_start, period_end, upload_bytes, download_bytes, quota_bytes, exceeded, created_at, updated_at
+		SELECT id, user_id, period_start, period_end, upload_bytes, download_bytes, raw_upload_bytes, raw_download_bytes, quota_bytes, exceeded, warned, created_at, updated_at
 		FROM user_traffic_periods
 		WHERE user_id = ? AND period_start <= ? AND period_end > ?
 		ORDER BY period_start DESC
@@ -254,14 +285,15 @@ func (r *userTrafficRepo) getCurrentPeriodTx(ctx context.Context, tx *sql.Tx, us
 	`, userID, nowUnix, nowUnix)
 
 	var p repository.UserTrafficPeriod
-	var exceeded int
-	if err := row.Scan(&p.ID, &p.UserID, &p.PeriodStart, &p.PeriodEnd, &p.UploadBytes, &p.DownloadBytes, &p.QuotaBytes, &exceeded, &p.CreatedAt, &p.UpdatedAt); err != nil {
+	var exceeded, warned int
+	if err := row.Scan(&p.ID, &p.UserID, &p.PeriodStart, &p.PeriodEnd, &p.UploadBytes, &p.DownloadBytes, &p.RawUploadBytes, &p.RawDownloadBytes, &p.QuotaBytes, &exceeded, &warned, &p.CreatedAt, &p.UpdatedAt); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
 		return nil, err
 	}
 	p.Exceeded = exceeded == 1
+	p.Warned = warned == 1
 	return &p, nil
 }
 
@@ -308,14 +340,17 @@ func (r *userTrafficRepo) createPeriodFromUserTx(ctx context.Context, tx *sql.Tx
 	}, nil
 }
 
-func (r *userTrafficRepo) incrementPeriodTrafficTx(ctx context.Context, tx *sql.Tx, userID int64, uploadDelta, downloadDelta, nowUnix int64) error {
+// incrementPeriodTrafficTx 同时累加计费字节与原始字节。
+func (r *userTrafficRepo) incrementPeriodTrafficTx(ctx context.Context, tx *sql.Tx, sample repository.UserTrafficDelta, nowUnix int64) error {
 	_, err := tx.ExecContext(ctx, `
 		UPDATE user_traffic_periods
 		SET upload_bytes = upload_bytes + ?,
 		    download_bytes = download_bytes + ?,
+		    raw_upload_bytes = raw_upload_bytes + ?,
+		    raw_download_bytes = raw_download_bytes + ?,
 		    updated_at = ?
 		WHERE user_id = ? AND period_start <= ? AND period_end > ?
-	`, uploadDelta, downloadDelta, nowUnix, userID, nowUnix, nowUnix)
+	`, sample.Upload, sample.Download, sample.RawUpload, sample.RawDownload, nowUnix, sample.UserID, nowUnix, nowUnix)
 	return err
 }
 
@@ -328,6 +363,15 @@ func (r *userTrafficRepo) markPeriodExceededTx(ctx context.Context, tx *sql.Tx,
 	return err
 }
 
+func (r *userTrafficRepo) markPeriodWarnedTx(ctx context.Context, tx *sql.Tx, userID int64, periodStart int64, nowUnix int64) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE user_traffic_periods
+		SET warned = 1, updated_at = ?
+		WHERE user_id = ? AND period_start = ?
+	`, nowUnix, userID, periodStart)
+	return err
+}
+
 func (r *userTrafficRepo) setUserTrafficExceededTx(ctx context.Context, tx *sql.Tx, userID int64, exceeded bool) error {
 	val := 0
 	if exceeded {
@@ -404,13 +448,15 @@ func (r *userTrafficRepo) GetUserTrafficStats(ctx context.Context, userID int64)
 	}
 
 	return &repository.UserTrafficStats{
-		PeriodStart:   period.PeriodStart,
-		PeriodEnd:     period.PeriodEnd,
-		UploadBytes:   period.UploadBytes,
-		DownloadBytes: period.DownloadBytes,
-		TotalBytes:    total,
-		QuotaBytes:    period.QuotaBytes,
-		UsedPercent:   usedPercent,
-		Exceeded:      period.Exceeded,
+		PeriodStart:      period.PeriodStart,
+		PeriodEnd:        period.PeriodEnd,
+		UploadBytes:      period.UploadBytes,
+		DownloadBytes:    period.DownloadBytes,
+		RawUploadBytes:   period.RawUploadBytes,
+		RawDownloadBytes: period.RawDownloadBytes,
+		TotalBytes:       total,
+		QuotaBytes:       period.QuotaBytes,
+		UsedPercent:      usedPercent,
+		Exceeded:         period.Exceeded,
 	}, nil
 }
@@ -0,0 +1,175 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+const userTrafficResetColumns = `id, user_id, plan_id, period_start, period_end, upload, download, quota_bytes, reset_at`
+
+// ListResetStates 返回套餐下所有用户的流量快照、最近一次周期重置时间及周期起算时间。
+func (r *userTrafficRepo) ListResetStates(ctx context.Context, planID int64) ([]repository.UserTrafficResetState, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT u.id, u.u, u.d, u.transfer_enable, u.created_at,
+		       COALESCE((SELECT MAX(period_end) FROM user_traffic_resets r WHERE r.user_id = u.id AND r.plan_id = u.plan_id), 0),
+		       MAX(u.plan_assigned_at, p.reset_cycle_since)
+		FROM users u
+		JOIN plans p ON p.id = u.plan_id
+		WHERE u.plan_id = ?
+		ORDER BY u.id ASC
+	`, planID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var states []repository.UserTrafficResetState
+	for rows.Next() {
+		var state repository.UserTrafficResetState
+		if err := rows.Scan(&state.UserID, &state.Upload, &state.Download, &state.TransferEnable, &state.CreatedAt, &state.LastResetAt, &state.CycleAnchorAt); err != nil {
+			return nil, err
+		}
+		states = append(states, state)
+	}
+	return states, rows.Err()
+}
+
+// ResetCycleTraffic 归档上一周期流量并清零计数。
+// 归档的 u/d 在事务内由 INSERT ... SELECT 读取，首条写语句即取得写锁，
+// 因此快照之后、清零之前到达的流量不会丢失；reset.Upload/Download 会回填为实际归档的值。
+// 同一套餐同一边界已归档过时直接返回，避免并发的重置任务重复清零。
+// 跨越边界的流量周期会在边界处截断，边界之后的周期被替换为 [boundary, nextBoundary)，
+// 使 ApplyTrafficBatchAtomic 的超额判断从新周期的零用量开始。
+func (r *userTrafficRepo) ResetCycleTraffic(ctx context.Context, reset *repository.UserTrafficReset, nextBoundary int64) error {
+	if reset == nil || reset.UserID <= 0 {
+		return fmt.Errorf("traffic reset is required / 需要流量重置记录")
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	boundary := reset.PeriodEnd
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO user_traffic_resets (user_id, plan_id, period_start, period_end, upload, download, quota_bytes, reset_at)
+		SELECT id, ?, ?, ?, u, d, transfer_enable, ? FROM users
+		WHERE id = ? AND NOT EXISTS (
+			SELECT 1 FROM user_traffic_resets WHERE user_id = ? AND plan_id = ? AND period_end >= ?
+		)
+	`, reset.PlanID, reset.PeriodStart, boundary, reset.ResetAt, reset.UserID, reset.UserID, reset.PlanID, boundary)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return nil
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	if err := tx.QueryRowContext(ctx, `
+		SELECT upload, download, quota_bytes FROM user_traffic_resets WHERE id = ?
+	`, id).Scan(&reset.Upload, &reset.Download, &reset.QuotaBytes); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE users SET u = 0, d = 0, traffic_exceeded = 0, updated_at = ? WHERE id = ?
+	`, reset.ResetAt, reset.UserID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE user_traffic_periods SET period_end = ?, updated_at = ?
+		WHERE user_id = ? AND period_start < ? AND period_end > ?
+	`, boundary, reset.ResetAt, reset.UserID, boundary, boundary); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM user_traffic_periods WHERE user_id = ? AND period_start >= ?
+	`, reset.UserID, boundary); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO user_traffic_periods (user_id, period_start, period_end, upload_bytes, download_bytes, quota_bytes, exceeded, created_at, updated_at)
+		SELECT id, ?, ?, 0, 0, transfer_enable, 0, ?, ? FROM users WHERE id = ?
+	`, boundary, nextBoundary, reset.ResetAt, reset.ResetAt, reset.UserID); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	reset.ID = id
+	return nil
+}
+
+func (r *userTrafficRepo) ListResets(ctx context.Context, filter repository.UserTrafficResetFilter) ([]repository.UserTrafficReset, error) {
+	where, args := buildUserTrafficResetFilter(filter)
+	limit, offset := normalizePagination(filter.Limit, filter.Offset, 20)
+	args = append(args, limit, offset)
+	rows, err := r.db.QueryContext(ctx, `SELECT `+userTrafficResetColumns+` FROM user_traffic_resets`+where+`
+		ORDER BY reset_at DESC, id DESC
+		LIMIT ? OFFSET ?`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var resets []repository.UserTrafficReset
+	for rows.Next() {
+		var reset repository.UserTrafficReset
+		if err := rows.Scan(
+			&reset.ID,
+			&reset.UserID,
+			&reset.PlanID,
+			&reset.PeriodStart,
+			&reset.PeriodEnd,
+			&reset.Upload,
+			&reset.Download,
+			&reset.QuotaBytes,
+			&reset.ResetAt,
+		); err != nil {
+			return nil, err
+		}
+		resets = append(resets, reset)
+	}
+	return resets, rows.Err()
+}
+
+func (r *userTrafficRepo) CountResets(ctx context.Context, filter repository.UserTrafficResetFilter) (int64, error) {
+	where, args := buildUserTrafficResetFilter(filter)
+	var count int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM user_traffic_resets`+where, args...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func buildUserTrafficResetFilter(filter repository.UserTrafficResetFilter) (string, []any) {
+	var conditions []string
+	var args []any
+	if filter.UserID != nil {
+		conditions = append(conditions, "user_id = ?")
+		args = append(args, *filter.UserID)
+	}
+	if filter.PlanID != nil {
+		conditions = append(conditions, "plan_id = ?")
+		args = append(args, *filter.PlanID)
+	}
+	if filter.StartAt > 0 {
+		conditions = append(conditions, "reset_at >= ?")
+		args = append(args, filter.StartAt)
+	}
+	if filter.EndAt > 0 {
+		conditions = append(conditions, "reset_at < ?")
+		args = append(args, filter.EndAt)
+	}
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
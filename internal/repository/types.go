@@ -36,6 +36,8 @@ type User struct {
 	Tags              []string
 	CreatedAt         int64
 	UpdatedAt         int64
+	// SubscriptionTemplateID 为用户默认订阅模板，nil 表示沿用套餐或系统默认模板
+	SubscriptionTemplateID *int64
 }
 
 // NodeUser represents the limited subset of user columns shared with nodes.
@@ -64,10 +66,56 @@ type AccessToken struct {
 	IP               string
 	UserAgent        string
 	Revoked          bool
+	FamilyID         string // 同一次登录轮换出的刷新令牌共享的族 ID
+	RotatedAt        int64  // 已被轮换（使用过）的时间，0 表示仍可用
 	CreatedAt        int64
 	UpdatedAt        int64
 }
 
+// LoginLockout 记录某账号在某 IP 上的连续登录失败与锁定状态。
+type LoginLockout struct {
+	ID           int64
+	Identifier   string // 小写的邮箱或用户名
+	IP           string
+	FailedCount  int   // 自上次锁定/成功登录以来的连续失败次数
+	LockCount    int   // 已触发的锁定次数，用于计算指数退避
+	LockedUntil  int64 // 锁定截止时间，0 或早于当前时间表示未锁定
+	LastFailedAt int64
+	CreatedAt    int64
+	UpdatedAt    int64
+}
+
+// LoginLockoutFilter 定义登录锁定的查询条件。
+type LoginLockoutFilter struct {
+	Identifier *string // Use LIKE match
+	ActiveOnly bool    // 仅返回仍在锁定中的记录
+	Now        int64   // ActiveOnly 的参照时间
+	Limit      int
+	Offset     int
+}
+
+// AuditLog 记录一次管理员发起的创建/更新/删除操作，只追加不修改。
+type AuditLog struct {
+	ID         int64           `json:"id"`
+	ActorID    int64           `json:"actor_id"`    // 操作管理员 ID，0 表示无法识别（如系统任务）
+	Action     string          `json:"action"`      // create / update / delete
+	TargetType string          `json:"target_type"` // user / plan / server / server_route / agent_host
+	TargetID   int64           `json:"target_id"`
+	Changes    json.RawMessage `json:"changes,omitempty"` // {"字段": {"old": 旧值, "new": 新值}}
+	CreatedAt  int64           `json:"created_at"`
+}
+
+// AuditLogFilter 定义审计日志的查询条件。
+type AuditLogFilter struct {
+	ActorID    *int64
+	TargetType *string
+	TargetID   *int64
+	Since      int64 // created_at >= Since，0 表示不限
+	Until      int64 // created_at < Until，0 表示不限
+	Limit      int
+	Offset     int
+}
+
 // LoginLog captures a single login attempt for auditing purposes.
 type LoginLog struct {
 	ID        int64
@@ -126,11 +174,15 @@ type Plan struct {
 	Content            string
 	Tags               []string
 	ResetTrafficMethod *int64
+	ResetDay           *int64 // 周期重置日（1-31），超过当月天数时取月末
+	ResetCycle         string // 流量重置周期，见 PlanResetCycle* 常量；为空表示不自动重置
 	CapacityLimit      *int64
 	InviteLimit        *int64
 	Sort               int64
 	CreatedAt          int64
 	UpdatedAt          int64
+	// SubscriptionTemplateID 为套餐默认订阅模板，nil 表示使用系统默认模板
+	SubscriptionTemplateID *int64
 }
 
 // ServerGroup represents a logical grouping of servers.
@@ -147,9 +199,11 @@ type ServerGroup struct {
 type ServerRoute struct {
 	ID          int64
 	Remarks     string
+	MatchType   string // domain/domain_suffix/domain_keyword/domain_regex/ip/geosite/geoip
 	Match       json.RawMessage
-	Action      string
+	Action      string // block/direct/proxy，proxy 时 ActionValue 为出站标签
 	ActionValue string
+	Priority    int64 // 数值越小越先匹配
 	CreatedAt   int64
 	UpdatedAt   int64
 }
@@ -176,6 +230,8 @@ type Server struct {
 	Status          int
 	Type            string
 	Settings        json.RawMessage
+	Weight          int     // 订阅排序权重，0 视为 100
+	TrafficRatio    float64 // 计费倍率，默认 1.0；Agent 上报的原始字节乘以该倍率后计入用户流量
 	LastHeartbeatAt int64
 	CreatedAt       int64
 	UpdatedAt       int64
@@ -190,6 +246,7 @@ type AgentHost struct {
 	Status                int      // 0: 离线, 1: 在线, 2: 警告
 	ProvisionStatus       int      // 0: active, 1: pending
 	TemplateID            int64    // Config Template ID
+	TemplateVersion       int64    // 固定的模板版本，0 表示跟随最新版本
 	CoreVersion           string   // 核心版本 (如 "1.10.0")
 	Capabilities          []string // 支持的能力 (如 ["reality", "multiplex"])
 	BuildTags             []string // 构建标签 (如 ["with_v2ray_api"])
@@ -210,11 +267,55 @@ type AgentHost struct {
 	LastRestartAt         int64    // 最近一次检测到重启的时间
 	AgentVersion          string   // Agent 二进制版本
 	CurrentCoreType       string   // 当前运行核心类型
+	ConnectionCount       int64    // 活跃连接数
+	ConnectionCountSource string   // 连接数来源 (clash_api / proc_net)，为空表示不可用
+	Maintenance           bool     // 维护模式：暂停配置与用户下发，仍接收心跳与指标
+	DriftDetected         bool     // 上报的配置哈希与模板期望不一致
+	SyncIntervalSeconds   int      // 节点级同步间隔，0 表示沿用全局 server_pull_interval
+	ReportIntervalSeconds int      // 节点级上报间隔，0 表示沿用全局 server_push_interval
 	LastHeartbeatAt       int64    // 最后心跳时间
 	CreatedAt             int64
 	UpdatedAt             int64
 }
 
+// Agent host heartbeat thresholds shared by the TUI and Prometheus collector.
+const (
+	AgentHostOnlineWindowSeconds  int64 = 120 // 2 分钟内有心跳视为在线
+	AgentHostWarningWindowSeconds int64 = 300 // 5 分钟内有心跳视为告警，超过即离线
+
+	AgentHostStateOnline  = "online"
+	AgentHostStateWarning = "warning"
+	AgentHostStateOffline = "offline"
+
+	// AgentHostStateMaintenance 表示节点在线但处于维护模式，配置下发已暂停。
+	AgentHostStateMaintenance = "maintenance"
+)
+
+// HeartbeatState classifies the host by the age of its last heartbeat.
+func (h *AgentHost) HeartbeatState(now int64) string {
+	if h == nil || h.LastHeartbeatAt == 0 {
+		return AgentHostStateOffline
+	}
+	diff := now - h.LastHeartbeatAt
+	switch {
+	case diff <= AgentHostOnlineWindowSeconds:
+		return AgentHostStateOnline
+	case diff <= AgentHostWarningWindowSeconds:
+		return AgentHostStateWarning
+	default:
+		return AgentHostStateOffline
+	}
+}
+
+// DisplayState 在心跳状态基础上叠加维护模式：在线或告警的维护节点显示为 maintenance。
+func (h *AgentHost) DisplayState(now int64) string {
+	state := h.HeartbeatState(now)
+	if h != nil && h.Maintenance && state != AgentHostStateOffline {
+		return AgentHostStateMaintenance
+	}
+	return state
+}
+
 // AgentLifecycleOperation represents a panel-issued agent lifecycle command.
 type AgentLifecycleOperation struct {
 	ID             string          `json:"id"`
@@ -305,6 +406,29 @@ type BinaryVersionState struct {
 	UpdatedAt        int64  `json:"updated_at"`
 }
 
+// AgentHostDiagnostics is the latest redacted self-diagnostics bundle reported by an agent.
+type AgentHostDiagnostics struct {
+	AgentHostID int64           `json:"agent_host_id"`
+	Payload     json.RawMessage `json:"payload"`
+	CollectedAt int64           `json:"collected_at"`
+	UpdatedAt   int64           `json:"updated_at"`
+}
+
+// AgentHostPathUsage is the latest disk usage of a watched path on an agent host.
+// Alerting records whether an over-threshold alert has been sent for the current crossing.
+type AgentHostPathUsage struct {
+	AgentHostID  int64  `json:"agent_host_id"`
+	Path         string `json:"path"`
+	Exists       bool   `json:"exists"`
+	UsedBytes    uint64 `json:"used_bytes"`
+	Truncated    bool   `json:"truncated"`
+	FSTotalBytes uint64 `json:"fs_total_bytes"`
+	FSUsedBytes  uint64 `json:"fs_used_bytes"`
+	FSFreeBytes  uint64 `json:"fs_free_bytes"`
+	Alerting     bool   `json:"alerting"`
+	UpdatedAt    int64  `json:"updated_at"`
+}
+
 // ConfigTemplate defines a configuration template for agents.
 type ConfigTemplate struct {
 	ID              int64
@@ -321,6 +445,22 @@ type ConfigTemplate struct {
 	UpdatedAt       int64
 }
 
+// ConfigTemplateVersion is an immutable snapshot of a config template.
+type ConfigTemplateVersion struct {
+	ID           int64
+	TemplateID   int64
+	Version      int64
+	Name         string
+	Type         string
+	Content      string
+	Description  string
+	MinVersion   string
+	Capabilities []string
+	ChangeNote   string
+	OperatorID   int64
+	CreatedAt    int64
+}
+
 // Notice mirrors announcements shown to users/admins.
 type Notice struct {
 	ID        int64
@@ -391,6 +531,41 @@ type SubscriptionLog struct {
 	CreatedAt int64
 }
 
+// SubscriptionLogFilter scopes subscription log aggregations.
+// The window applies to created_at as a half-open range [Since, Until).
+type SubscriptionLogFilter struct {
+	UserID       *int64
+	Since        int64
+	Until        int64
+	MinUniqueIPs int
+	Limit        int
+}
+
+// SubscriptionUserIPCount is the number of distinct IPs a user fetched the subscription from.
+type SubscriptionUserIPCount struct {
+	UserID     int64  `json:"user_id"`
+	UserEmail  string `json:"user_email"`
+	UniqueIPs  int64  `json:"unique_ips"`
+	Requests   int64  `json:"requests"`
+	LastSeenAt int64  `json:"last_seen_at"`
+}
+
+// SubscriptionClientCount is the number of fetches and users per client type.
+type SubscriptionClientCount struct {
+	Client   string `json:"client"`
+	Requests int64  `json:"requests"`
+	Users    int64  `json:"users"`
+}
+
+// SubscriptionIPActivity describes one distinct IP seen for a user.
+type SubscriptionIPActivity struct {
+	IP          string `json:"ip"`
+	Requests    int64  `json:"requests"`
+	FirstSeenAt int64  `json:"first_seen_at"`
+	LastSeenAt  int64  `json:"last_seen_at"`
+	LastClient  string `json:"last_client"`
+}
+
 // StatServerRecord captures aggregated node-level statistics per interval.
 type StatServerRecord struct {
 	ID          int64
@@ -447,31 +622,97 @@ type UserTrafficPeriod struct {
 	UserID        int64
 	PeriodStart   int64 // Unix timestamp of period start (1st of month)
 	PeriodEnd     int64 // Unix timestamp of period end (1st of next month)
-	UploadBytes   int64
+	UploadBytes   int64 // Charged bytes, after the node traffic ratio
 	DownloadBytes int64
-	QuotaBytes    int64 // Traffic quota for this period
-	Exceeded      bool  // True if user exceeded quota
-	CreatedAt     int64
-	UpdatedAt     int64
+	// RawUploadBytes/RawDownloadBytes are the bytes reported by agents before the traffic ratio
+	RawUploadBytes   int64
+	RawDownloadBytes int64
+	QuotaBytes       int64 // Traffic quota for this period
+	Exceeded         bool  // True if user exceeded quota
+	Warned           bool  // True once the soft-limit warning fired for this period
+	CreatedAt        int64
+	UpdatedAt        int64
+}
+
+// 套餐流量重置周期。
+const (
+	PlanResetCycleMonthly   = "monthly"
+	PlanResetCycleQuarterly = "quarterly"
+	PlanResetCycleYearly    = "yearly"
+)
+
+// UserTrafficReset archives a user's legacy u/d counters for one plan billing cycle.
+type UserTrafficReset struct {
+	ID          int64
+	UserID      int64
+	PlanID      int64
+	PeriodStart int64 // 上次重置时间（无记录时为用户创建时间）
+	PeriodEnd   int64 // 本次重置的周期边界
+	Upload      int64
+	Download    int64
+	QuotaBytes  int64
+	ResetAt     int64
+}
+
+// UserTrafficResetState is the per-user snapshot used to decide whether a cycle reset is due.
+type UserTrafficResetState struct {
+	UserID         int64
+	Upload         int64
+	Download       int64
+	TransferEnable int64
+	CreatedAt      int64
+	LastResetAt    int64 // 最近一次归档的 period_end，从未重置时为 0
+	CycleAnchorAt  int64 // 分配到当前套餐与套餐启用重置周期中较晚的时间，未知时为 0
+}
+
+// UserTrafficResetFilter controls archived reset queries.
+type UserTrafficResetFilter struct {
+	UserID  *int64
+	PlanID  *int64
+	StartAt int64 // reset_at 下限（含）
+	EndAt   int64 // reset_at 上限（不含）
+	Limit   int
+	Offset  int
 }
 
 // UserTrafficDelta represents a single traffic delta sample for batch processing.
 type UserTrafficDelta struct {
 	UserID   int64
-	Upload   int64
+	Upload   int64 // 计费字节（已乘节点倍率）
 	Download int64
+	// ServerID 为流量来源节点，0 表示 Agent 无法区分节点
+	ServerID int64
+	// RawUpload/RawDownload 为倍率换算前 Agent 上报的原始字节
+	RawUpload   int64
+	RawDownload int64
+}
+
+// TrafficBatchKey is the idempotency key of an agent traffic batch.
+type TrafficBatchKey struct {
+	AgentHostID int64
+	BatchID     string
+}
+
+// TrafficBatchResult is the outcome of ApplyTrafficBatchAtomic.
+type TrafficBatchResult struct {
+	Accepted        []UserTrafficDelta
+	ExceededUserIDs []int64 // 本批次越过硬限额的用户
+	WarningUserIDs  []int64 // 本批次首次越过软限额的用户
+	Duplicate       bool    // 批次已入账过，本次未做任何修改
 }
 
 // UserTrafficStats provides a summary of user's traffic usage.
 type UserTrafficStats struct {
-	PeriodStart   int64
-	PeriodEnd     int64
-	UploadBytes   int64
-	DownloadBytes int64
-	TotalBytes    int64
-	QuotaBytes    int64
-	UsedPercent   float64
-	Exceeded      bool
+	PeriodStart      int64
+	PeriodEnd        int64
+	UploadBytes      int64
+	DownloadBytes    int64
+	RawUploadBytes   int64
+	RawDownloadBytes int64
+	TotalBytes       int64
+	QuotaBytes       int64
+	UsedPercent      float64
+	Exceeded         bool
 }
 
 // ShortLink represents a short URL mapping for subscription links.
@@ -488,6 +729,14 @@ type ShortLink struct {
 	UpdatedAt      int64
 }
 
+// ShortLinkFilter defines admin listing filters for short links.
+type ShortLinkFilter struct {
+	UserID *int64
+	Code   *string // Use LIKE match
+	Limit  int
+	Offset int
+}
+
 // SubscriptionTemplate represents a customizable template for subscription output.
 type SubscriptionTemplate struct {
 	ID          int64
@@ -504,19 +753,50 @@ type SubscriptionTemplate struct {
 
 // ForwardingRule represents a nftables port forwarding rule.
 type ForwardingRule struct {
-	ID            int64
-	AgentHostID   int64  // 关联的 Agent 主机 ID
-	Name          string // 规则名称
-	Protocol      string // tcp/udp/both
-	ListenPort    int    // 本地监听端口
-	TargetAddress string // 目标地址 (仅 IP)
-	TargetPort    int    // 目标端口
-	Enabled       bool   // 是否启用
-	Priority      int    // 优先级（越小越优先）
-	Remark        string // 备注
-	Version       int64  // 规则版本
-	CreatedAt     int64
-	UpdatedAt     int64
+	ID                  int64
+	AgentHostID         int64                    // 关联的 Agent 主机 ID
+	Name                string                   // 规则名称
+	Protocol            string                   // tcp/udp/both
+	ListenPort          int                      // 本地监听端口
+	TargetAddress       string                   // 目标地址 (仅 IP)
+	TargetPort          int                      // 目标端口
+	Targets             []ForwardingTarget       // 多目标加权轮询；为空时使用 TargetAddress/TargetPort
+	HealthCheckInterval int                      // 目标健康检查间隔（秒），0 表示不检查
+	HealthCheckTimeout  int                      // 单次探测超时（秒），0 表示使用 Agent 默认值
+	TargetHealth        []ForwardingTargetHealth // Agent 上报的目标健康状态
+	Enabled             bool                     // 是否启用
+	Priority            int                      // 优先级（越小越优先）
+	Remark              string                   // 备注
+	Version             int64                    // 规则版本
+	CreatedAt           int64
+	UpdatedAt           int64
+}
+
+// ForwardingTarget is one weighted backend of a forwarding rule.
+type ForwardingTarget struct {
+	Address string `json:"address"` // 目标地址 (仅 IP)
+	Port    int    `json:"port"`    // 目标端口
+	Weight  int    `json:"weight"`  // 轮询权重，必须为正数
+}
+
+// ForwardingTargetHealth is the last health state an agent reported for one target.
+type ForwardingTargetHealth struct {
+	Address   string `json:"address"`
+	Port      int    `json:"port"`
+	Healthy   bool   `json:"healthy"`
+	Error     string `json:"error,omitempty"` // 最近一次探测失败原因
+	CheckedAt int64  `json:"checked_at"`
+}
+
+// EffectiveTargets returns Targets, or the legacy single target as a one-element list.
+func (r *ForwardingRule) EffectiveTargets() []ForwardingTarget {
+	if len(r.Targets) > 0 {
+		return r.Targets
+	}
+	if r.TargetAddress == "" && r.TargetPort == 0 {
+		return nil
+	}
+	return []ForwardingTarget{{Address: r.TargetAddress, Port: r.TargetPort, Weight: 1}}
 }
 
 // ForwardingRuleLog records audit logs for forwarding rule changes.
@@ -585,20 +865,31 @@ type OperationBlocker struct {
 
 // AgentCoreInstance represents a persisted core instance on an agent host.
 type AgentCoreInstance struct {
-	ID               int64               `json:"id"`
-	AgentHostID      int64               `json:"agent_host_id"`
-	InstanceID       string              `json:"instance_id"`
-	CoreType         string              `json:"core_type"`
-	Status           string              `json:"status"`
-	ListenPorts      []int               `json:"listen_ports"`
-	ConfigTemplateID *int64              `json:"config_template_id"`
-	ConfigHash       string              `json:"config_hash"`
-	StartedAt        *int64              `json:"started_at"`
-	LastHeartbeatAt  *int64              `json:"last_heartbeat_at"`
-	ErrorMessage     string              `json:"error_message"`
-	CreatedAt        int64               `json:"created_at"`
-	UpdatedAt        int64               `json:"updated_at"`
-	CoreSnapshot     *CoreStatusSnapshot `json:"core_snapshot,omitempty"`
+	ID               int64  `json:"id"`
+	AgentHostID      int64  `json:"agent_host_id"`
+	InstanceID       string `json:"instance_id"`
+	CoreType         string `json:"core_type"`
+	Status           string `json:"status"`
+	ListenPorts      []int  `json:"listen_ports"`
+	ConfigTemplateID *int64 `json:"config_template_id"`
+	ConfigHash       string `json:"config_hash"`
+	StartedAt        *int64 `json:"started_at"`
+	LastHeartbeatAt  *int64 `json:"last_heartbeat_at"`
+	ErrorMessage     string `json:"error_message"`
+	// CPUPercent is relative to one CPU core; ResourceSampledAt is 0 when the process was not sampled.
+	CPUPercent        float64             `json:"cpu_percent"`
+	MemoryRSSBytes    int64               `json:"memory_rss_bytes"`
+	ResourceSampledAt int64               `json:"resource_sampled_at"`
+	CreatedAt         int64               `json:"created_at"`
+	UpdatedAt         int64               `json:"updated_at"`
+	CoreSnapshot      *CoreStatusSnapshot `json:"core_snapshot,omitempty"`
+	// RestartCount counts restarts within RestartWindowSeconds; Flapping is set when the
+	// restarts within the panel's flapping window reach the configured threshold.
+	RestartCount         int   `json:"restart_count"`
+	RestartWindowSeconds int64 `json:"restart_window_seconds"`
+	LastRestartAt        int64 `json:"last_restart_at"`
+	UptimeSeconds        int64 `json:"uptime_seconds"`
+	Flapping             bool  `json:"flapping"`
 }
 
 // AgentCoreSwitchLog captures core switching audit logs.
@@ -623,6 +914,8 @@ type AccessLog struct {
 	UserEmail       string
 	AgentHostID     int64
 	SourceIP        string
+	SourceCountry   string // ISO 3166 国家代码，未解析时为空
+	SourceASN       int64  // 来源 ASN，未解析时为 0
 	TargetDomain    string
 	TargetIP        string
 	TargetPort      int
@@ -636,15 +929,16 @@ type AccessLog struct {
 
 // AccessLogFilter defines filter conditions for querying access logs.
 type AccessLogFilter struct {
-	UserID       *int64
-	AgentHostID  *int64
-	TargetDomain *string // Use LIKE match
-	SourceIP     *string
-	Protocol     *string
-	StartAt      *int64
-	EndAt        *int64
-	Limit        int
-	Offset       int
+	UserID        *int64
+	AgentHostID   *int64
+	TargetDomain  *string // Use LIKE match
+	SourceIP      *string
+	SourceCountry *string
+	Protocol      *string
+	StartAt       *int64
+	EndAt         *int64
+	Limit         int
+	Offset        int
 }
 
 // AccessLogStats provides aggregated statistics of access logs.
@@ -654,6 +948,23 @@ type AccessLogStats struct {
 	TotalDownload int64
 }
 
+// AccessLogTopFilter scopes a top-N aggregation over access logs.
+// The window applies to connection_start as a half-open range [Since, Until).
+type AccessLogTopFilter struct {
+	AgentHostID *int64
+	Since       int64
+	Until       int64
+	Limit       int
+}
+
+// AccessLogAggregate is one ranked row of a top-N aggregation (target domain/IP or source IP).
+type AccessLogAggregate struct {
+	Key         string
+	Connections int64
+	Upload      int64
+	Download    int64
+}
+
 // InboundSpec represents desired inbound configuration at tag granularity.
 type InboundSpec struct {
 	ID              int64
@@ -722,6 +1033,14 @@ type AgentConfigInventory struct {
 	LastSeenAt  int64
 }
 
+// UserOnlineDevice is an online (agent_host, user, ip) tuple reported by agents.
+type UserOnlineDevice struct {
+	AgentHostID int64
+	UserID      int64
+	IP          string
+	LastSeenAt  int64
+}
+
 // InboundIndex is semantic inbound index parsed from applied files.
 type InboundIndex struct {
 	ID          int64
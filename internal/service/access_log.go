@@ -3,30 +3,80 @@ package service
 import (
 	"context"
 	"strconv"
+	"sync"
+	"time"
 
 	"github.com/creamcroissant/xboard/internal/repository"
 )
 
+const (
+	// accessLogRetentionSetting is the setting key holding the retention in days.
+	accessLogRetentionSetting = "access_log.retention_days"
+	// defaultAccessLogRetentionDays applies when the setting is missing or invalid.
+	defaultAccessLogRetentionDays = 7
+	// accessLogCleanupBatchSize bounds each DELETE so large tables are not locked for long.
+	accessLogCleanupBatchSize = 5000
+
+	// Defaults and bounds for top target/source aggregation.
+	defaultAccessLogTopWindow = 24 * time.Hour
+	maxAccessLogTopWindow     = 30 * 24 * time.Hour
+	defaultAccessLogTopLimit  = 10
+	maxAccessLogTopLimit      = 100
+)
+
+// GeoResolver resolves an IP to an ISO country code and ASN for access log enrichment.
+// Unknown addresses should return empty values rather than an error.
+type GeoResolver interface {
+	ResolveIP(ip string) (country string, asn int64, err error)
+}
+
+// AccessLogRetention describes the retention policy and scheduled cleanup state.
+type AccessLogRetention struct {
+	RetentionDays int   `json:"retention_days"`
+	NextRunAt     int64 `json:"next_run_at"`
+	LastRunAt     int64 `json:"last_run_at"`
+	LastDeleted   int64 `json:"last_deleted"`
+}
+
 // AccessLogService manages access logging logic.
 type AccessLogService interface {
 	LogAccessRecords(ctx context.Context, agentHostID int64, records []*repository.AccessLog) error
 	ListAccessLogs(ctx context.Context, filter repository.AccessLogFilter) ([]*repository.AccessLog, int64, error)
 	GetStats(ctx context.Context, filter repository.AccessLogFilter) (*repository.AccessLogStats, error)
+	// TopTargets ranks target domains/IPs seen in the last window; agentHostID 0 means all hosts.
+	TopTargets(ctx context.Context, agentHostID int64, window time.Duration, limit int) ([]*repository.AccessLogAggregate, error)
+	// TopSources ranks source IPs seen in the last window; agentHostID 0 means all hosts.
+	TopSources(ctx context.Context, agentHostID int64, window time.Duration, limit int) ([]*repository.AccessLogAggregate, error)
 	CleanupOldLogs(ctx context.Context) (int64, error)
 	IsEnabled(ctx context.Context) bool
+	GetRetention(ctx context.Context) (*AccessLogRetention, error)
+	// SetCleanupSchedule lets the bootstrap report when the scheduled cleanup runs next.
+	SetCleanupSchedule(next func() time.Time)
 }
 
 type accessLogService struct {
 	logs     repository.AccessLogRepository
 	users    repository.UserRepository
 	settings repository.SettingRepository
+	geo      GeoResolver
+
+	mu          sync.Mutex
+	nextRun     func() time.Time
+	lastRunAt   int64
+	lastDeleted int64
 }
 
 func NewAccessLogService(store repository.Store) AccessLogService {
+	return NewAccessLogServiceWithGeoResolver(store, nil)
+}
+
+// NewAccessLogServiceWithGeoResolver enriches ingested logs with source country/ASN when geo is non-nil.
+func NewAccessLogServiceWithGeoResolver(store repository.Store, geo GeoResolver) AccessLogService {
 	return &accessLogService{
 		logs:     store.AccessLogs(),
 		users:    store.Users(),
 		settings: store.Settings(),
+		geo:      geo,
 	}
 }
 
@@ -65,9 +115,39 @@ func (s *accessLogService) LogAccessRecords(ctx context.Context, agentHostID int
 		}
 	}
 
+	s.enrichGeo(records)
+
 	return s.logs.BatchCreate(ctx, records)
 }
 
+// enrichGeo fills source country/ASN on a best-effort basis; resolver errors
+// leave the fields empty and never block ingestion.
+func (s *accessLogService) enrichGeo(records []*repository.AccessLog) {
+	if s.geo == nil {
+		return
+	}
+	type geoResult struct {
+		country string
+		asn     int64
+	}
+	cache := make(map[string]geoResult)
+	for _, record := range records {
+		if record.SourceIP == "" {
+			continue
+		}
+		result, ok := cache[record.SourceIP]
+		if !ok {
+			country, asn, err := s.geo.ResolveIP(record.SourceIP)
+			if err == nil {
+				result = geoResult{country: country, asn: asn}
+			}
+			cache[record.SourceIP] = result
+		}
+		record.SourceCountry = result.country
+		record.SourceASN = result.asn
+	}
+}
+
 func (s *accessLogService) ListAccessLogs(ctx context.Context, filter repository.AccessLogFilter) ([]*repository.AccessLog, int64, error) {
 	logs, err := s.logs.List(ctx, filter)
 	if err != nil {
@@ -84,15 +164,95 @@ func (s *accessLogService) GetStats(ctx context.Context, filter repository.Acces
 	return s.logs.GetStats(ctx, filter)
 }
 
+func (s *accessLogService) TopTargets(ctx context.Context, agentHostID int64, window time.Duration, limit int) ([]*repository.AccessLogAggregate, error) {
+	return s.logs.TopTargets(ctx, accessLogTopFilter(agentHostID, window, limit))
+}
+
+func (s *accessLogService) TopSources(ctx context.Context, agentHostID int64, window time.Duration, limit int) ([]*repository.AccessLogAggregate, error) {
+	return s.logs.TopSources(ctx, accessLogTopFilter(agentHostID, window, limit))
+}
+
+// accessLogTopFilter clamps window and limit to sane bounds and builds the repository filter.
+func accessLogTopFilter(agentHostID int64, window time.Duration, limit int) repository.AccessLogTopFilter {
+	if window <= 0 {
+		window = defaultAccessLogTopWindow
+	}
+	if window > maxAccessLogTopWindow {
+		window = maxAccessLogTopWindow
+	}
+	if limit <= 0 {
+		limit = defaultAccessLogTopLimit
+	}
+	if limit > maxAccessLogTopLimit {
+		limit = maxAccessLogTopLimit
+	}
+	now := time.Now()
+	filter := repository.AccessLogTopFilter{
+		Since: now.Add(-window).Unix(),
+		Until: now.Unix() + 1,
+		Limit: limit,
+	}
+	if agentHostID > 0 {
+		filter.AgentHostID = &agentHostID
+	}
+	return filter
+}
+
+// CleanupOldLogs deletes logs older than the retention in batches and returns the rows removed,
+// including a partial count when the context expires midway.
 func (s *accessLogService) CleanupOldLogs(ctx context.Context) (int64, error) {
-	setting, err := s.settings.Get(ctx, "access_log.retention_days")
-	days := 7 // Default
+	before := time.Now().AddDate(0, 0, -s.retentionDays(ctx)).Unix()
+
+	var total int64
+	var err error
+	for {
+		var deleted int64
+		deleted, err = s.logs.DeleteBeforeBatch(ctx, before, accessLogCleanupBatchSize)
+		total += deleted
+		if err != nil || deleted < accessLogCleanupBatchSize {
+			break
+		}
+		if err = ctx.Err(); err != nil {
+			break
+		}
+	}
+
+	s.mu.Lock()
+	s.lastRunAt = time.Now().Unix()
+	s.lastDeleted = total
+	s.mu.Unlock()
+	return total, err
+}
+
+func (s *accessLogService) GetRetention(ctx context.Context) (*AccessLogRetention, error) {
+	retention := &AccessLogRetention{RetentionDays: s.retentionDays(ctx)}
+	s.mu.Lock()
+	retention.LastRunAt = s.lastRunAt
+	retention.LastDeleted = s.lastDeleted
+	nextRun := s.nextRun
+	s.mu.Unlock()
+	if nextRun != nil {
+		if next := nextRun(); !next.IsZero() {
+			retention.NextRunAt = next.Unix()
+		}
+	}
+	return retention, nil
+}
+
+func (s *accessLogService) SetCleanupSchedule(next func() time.Time) {
+	s.mu.Lock()
+	s.nextRun = next
+	s.mu.Unlock()
+}
+
+func (s *accessLogService) retentionDays(ctx context.Context) int {
+	setting, err := s.settings.Get(ctx, accessLogRetentionSetting)
 	if err == nil && setting != nil {
 		if d, err := strconv.Atoi(setting.Value); err == nil && d > 0 {
-			days = d
+			return d
 		}
 	}
-	return s.logs.DeleteByRetentionDays(ctx, days)
+	return defaultAccessLogRetentionDays
 }
 
 func (s *accessLogService) IsEnabled(ctx context.Context) bool {
@@ -17,7 +17,12 @@ type AdminPlanService interface {
 	Save(ctx context.Context, input AdminPlanSaveInput) error
 	Delete(ctx context.Context, id int64) error
 	Sort(ctx context.Context, input AdminPlanSortInput) error
+	PlanGroups(ctx context.Context, planID int64) ([]AdminServerGroupView, error)
+	AttachGroups(ctx context.Context, planID int64, groupIDs []int64) ([]AdminServerGroupView, error)
+	DetachGroups(ctx context.Context, planID int64, groupIDs []int64) ([]AdminServerGroupView, error)
 	I18n() *i18n.Manager
+	// SetAuditLog 注入审计日志，记录套餐的创建、修改与删除。
+	SetAuditLog(audit AuditLogService)
 }
 
 // AdminPlanSaveInput captures fields admins can mutate.
@@ -32,12 +37,16 @@ type AdminPlanSaveInput struct {
 	DeviceLimit    *int64             `json:"device_limit,omitempty"`
 	CapacityLimit  *int64             `json:"capacity_limit,omitempty"`
 	ResetMethod    *int64             `json:"reset_traffic_method,omitempty"`
+	ResetDay       *int64             `json:"reset_day,omitempty"`
+	ResetCycle     *string            `json:"reset_cycle,omitempty"`
 	Sort           *int64             `json:"sort,omitempty"`
 	Content        *string            `json:"content,omitempty"`
 	Prices         map[string]float64 `json:"prices,omitempty"`
 	Tags           []string           `json:"tags,omitempty"`
 	GroupID        *int64             `json:"group_id,omitempty"`
 	ServerGroupIDs []int64            `json:"server_group_ids,omitempty"`
+	// SubscriptionTemplateID 设置套餐默认订阅模板，0 表示清除并使用系统默认模板
+	SubscriptionTemplateID *int64 `json:"subscription_template_id,omitempty"`
 }
 
 // AdminPlanSortInput reorders plan sort values according to provided ids.
@@ -45,21 +54,32 @@ type AdminPlanSortInput struct {
 	IDs []int64 `json:"ids"`
 }
 
+// AdminPlanGroupsInput lists server group ids to attach to or detach from a plan.
+type AdminPlanGroupsInput struct {
+	GroupIDs []int64 `json:"group_ids"`
+}
+
 type adminPlanService struct {
-	plans repository.PlanRepository
-	now   func() time.Time
-	i18n  *i18n.Manager
+	plans  repository.PlanRepository
+	groups repository.ServerGroupRepository
+	now    func() time.Time
+	i18n   *i18n.Manager
+	audit  AuditLogService
 }
 
 // NewAdminPlanService wires admin plan mutations.
-func NewAdminPlanService(plans repository.PlanRepository, i18n *i18n.Manager) AdminPlanService {
-	return &adminPlanService{plans: plans, now: time.Now, i18n: i18n}
+func NewAdminPlanService(plans repository.PlanRepository, groups repository.ServerGroupRepository, i18n *i18n.Manager) AdminPlanService {
+	return &adminPlanService{plans: plans, groups: groups, now: time.Now, i18n: i18n}
 }
 
 func (s *adminPlanService) I18n() *i18n.Manager {
 	return s.i18n
 }
 
+func (s *adminPlanService) SetAuditLog(audit AuditLogService) {
+	s.audit = audit
+}
+
 func (s *adminPlanService) Save(ctx context.Context, input AdminPlanSaveInput) error {
 	if s == nil || s.plans == nil {
 		return fmt.Errorf("admin plan service not configured / 套餐管理服务未配置")
@@ -74,6 +94,7 @@ func (s *adminPlanService) Save(ctx context.Context, input AdminPlanSaveInput) e
 	if err != nil {
 		return err
 	}
+	before := *plan
 	if input.Name != nil {
 		plan.Name = *input.Name
 	}
@@ -101,6 +122,15 @@ func (s *adminPlanService) Save(ctx context.Context, input AdminPlanSaveInput) e
 	if input.ResetMethod != nil {
 		plan.ResetTrafficMethod = optionalPtr(input.ResetMethod)
 	}
+	if input.ResetDay != nil {
+		plan.ResetDay = optionalPtr(input.ResetDay)
+	}
+	if input.ResetCycle != nil {
+		plan.ResetCycle = *input.ResetCycle
+	}
+	if err := ValidatePlanResetCycle(plan.ResetCycle, plan.ResetDay); err != nil {
+		return err
+	}
 	if input.Sort != nil {
 		plan.Sort = *input.Sort
 	}
@@ -116,11 +146,20 @@ func (s *adminPlanService) Save(ctx context.Context, input AdminPlanSaveInput) e
 	if input.GroupID != nil {
 		plan.GroupID = optionalPtr(input.GroupID)
 	}
+	if input.SubscriptionTemplateID != nil {
+		plan.SubscriptionTemplateID = optionalPositiveID(input.SubscriptionTemplateID)
+	}
 	plan.UpdatedAt = s.now().Unix()
 	if input.ServerGroupIDs != nil {
-		return s.plans.UpdateWithGroups(ctx, plan, input.ServerGroupIDs)
+		err = s.plans.UpdateWithGroups(ctx, plan, input.ServerGroupIDs)
+	} else {
+		err = s.plans.Update(ctx, plan)
 	}
-	return s.plans.Update(ctx, plan)
+	if err != nil {
+		return err
+	}
+	recordAudit(ctx, s.audit, AuditEntry{Action: AuditActionUpdate, TargetType: AuditTargetPlan, TargetID: plan.ID, Before: &before, After: plan})
+	return nil
 }
 
 func (s *adminPlanService) Sort(ctx context.Context, input AdminPlanSortInput) error {
@@ -180,6 +219,15 @@ func (s *adminPlanService) create(ctx context.Context, input AdminPlanSaveInput)
 	if input.ResetMethod != nil {
 		plan.ResetTrafficMethod = optionalPtr(input.ResetMethod)
 	}
+	if input.ResetDay != nil {
+		plan.ResetDay = optionalPtr(input.ResetDay)
+	}
+	if input.ResetCycle != nil {
+		plan.ResetCycle = *input.ResetCycle
+	}
+	if err := ValidatePlanResetCycle(plan.ResetCycle, plan.ResetDay); err != nil {
+		return err
+	}
 	if input.Sort != nil {
 		plan.Sort = *input.Sort
 	}
@@ -195,6 +243,9 @@ func (s *adminPlanService) create(ctx context.Context, input AdminPlanSaveInput)
 	if input.GroupID != nil {
 		plan.GroupID = optionalPtr(input.GroupID)
 	}
+	if input.SubscriptionTemplateID != nil {
+		plan.SubscriptionTemplateID = optionalPositiveID(input.SubscriptionTemplateID)
+	}
 
 	created, err := s.plans.Create(ctx, plan)
 	if err != nil {
@@ -207,6 +258,7 @@ func (s *adminPlanService) create(ctx context.Context, input AdminPlanSaveInput)
 		}
 	}
 
+	recordAudit(ctx, s.audit, AuditEntry{Action: AuditActionCreate, TargetType: AuditTargetPlan, TargetID: created.ID, After: created})
 	return nil
 }
 
@@ -219,7 +271,7 @@ func (s *adminPlanService) Delete(ctx context.Context, id int64) error {
 	}
 
 	// Check if plan exists
-	_, err := s.plans.FindByID(ctx, id)
+	plan, err := s.plans.FindByID(ctx, id)
 	if err != nil {
 		if err == repository.ErrNotFound {
 			return ErrNotFound
@@ -232,7 +284,11 @@ func (s *adminPlanService) Delete(ctx context.Context, id int64) error {
 		return err
 	}
 
-	return s.plans.Delete(ctx, id)
+	if err := s.plans.Delete(ctx, id); err != nil {
+		return err
+	}
+	recordAudit(ctx, s.audit, AuditEntry{Action: AuditActionDelete, TargetType: AuditTargetPlan, TargetID: id, Before: plan})
+	return nil
 }
 
 func optionalPtr(src *int64) *int64 {
@@ -243,6 +299,15 @@ func optionalPtr(src *int64) *int64 {
 	return &value
 }
 
+// optionalPositiveID 复制可选 ID，非正数视为清除。
+func optionalPositiveID(src *int64) *int64 {
+	if src == nil || *src <= 0 {
+		return nil
+	}
+	value := *src
+	return &value
+}
+
 func uniquePositive(ids []int64) []int64 {
 	seen := make(map[int64]struct{}, len(ids))
 	result := make([]int64, 0, len(ids))
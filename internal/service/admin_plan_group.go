@@ -0,0 +1,153 @@
+// 文件路径: internal/service/admin_plan_group.go
+// 模块说明: 这是 internal 模块里的 admin_plan_group 逻辑，管理套餐与节点分组的绑定关系。
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+// PlanGroups 返回套餐当前绑定的节点分组。
+func (s *adminPlanService) PlanGroups(ctx context.Context, planID int64) ([]AdminServerGroupView, error) {
+	if err := s.ensurePlanGroupsConfigured(); err != nil {
+		return nil, err
+	}
+	if err := s.ensurePlanExists(ctx, planID); err != nil {
+		return nil, err
+	}
+	current, err := s.plans.GetGroups(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	return s.planGroupViews(ctx, current)
+}
+
+// AttachGroups 为套餐追加绑定节点分组，已绑定的分组保持不变。
+func (s *adminPlanService) AttachGroups(ctx context.Context, planID int64, groupIDs []int64) ([]AdminServerGroupView, error) {
+	if err := s.ensurePlanGroupsConfigured(); err != nil {
+		return nil, err
+	}
+	ids := uniquePositive(groupIDs)
+	if len(ids) == 0 {
+		return nil, errors.New("group_ids cannot be empty / group_ids 不能为空")
+	}
+	if err := s.ensurePlanExists(ctx, planID); err != nil {
+		return nil, err
+	}
+	known, err := s.groupIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		if _, ok := known[id]; !ok {
+			return nil, fmt.Errorf("server group %d not found / 节点分组 %d 不存在", id, id)
+		}
+	}
+	current, err := s.plans.GetGroups(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	next := uniquePositive(append(append([]int64{}, current...), ids...))
+	if len(next) != len(uniquePositive(current)) {
+		if err := s.plans.ReplaceGroups(ctx, planID, next); err != nil {
+			return nil, err
+		}
+	}
+	return s.planGroupViews(ctx, next)
+}
+
+// DetachGroups 解除套餐与指定节点分组的绑定，未绑定的分组忽略。
+func (s *adminPlanService) DetachGroups(ctx context.Context, planID int64, groupIDs []int64) ([]AdminServerGroupView, error) {
+	if err := s.ensurePlanGroupsConfigured(); err != nil {
+		return nil, err
+	}
+	ids := uniquePositive(groupIDs)
+	if len(ids) == 0 {
+		return nil, errors.New("group_ids cannot be empty / group_ids 不能为空")
+	}
+	if err := s.ensurePlanExists(ctx, planID); err != nil {
+		return nil, err
+	}
+	current, err := s.plans.GetGroups(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	remaining := make([]int64, 0, len(current))
+	for _, id := range uniquePositive(current) {
+		if !containsGroupID(ids, id) {
+			remaining = append(remaining, id)
+		}
+	}
+	if len(remaining) != len(uniquePositive(current)) {
+		if err := s.plans.ReplaceGroups(ctx, planID, remaining); err != nil {
+			return nil, err
+		}
+	}
+	return s.planGroupViews(ctx, remaining)
+}
+
+func (s *adminPlanService) ensurePlanGroupsConfigured() error {
+	if s == nil || s.plans == nil || s.groups == nil {
+		return fmt.Errorf("admin plan service not configured / 套餐管理服务未配置")
+	}
+	return nil
+}
+
+func (s *adminPlanService) ensurePlanExists(ctx context.Context, planID int64) error {
+	if planID <= 0 {
+		return ErrNotFound
+	}
+	if _, err := s.plans.FindByID(ctx, planID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *adminPlanService) groupIndex(ctx context.Context) (map[int64]*repository.ServerGroup, error) {
+	groups, err := s.groups.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[int64]*repository.ServerGroup, len(groups))
+	for _, group := range groups {
+		if group != nil {
+			index[group.ID] = group
+		}
+	}
+	return index, nil
+}
+
+// planGroupViews 按绑定顺序输出分组视图；分组已被删除但绑定残留时仅返回 ID。
+func (s *adminPlanService) planGroupViews(ctx context.Context, groupIDs []int64) ([]AdminServerGroupView, error) {
+	index, err := s.groupIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return serverGroupViews(index, groupIDs), nil
+}
+
+func serverGroupViews(index map[int64]*repository.ServerGroup, groupIDs []int64) []AdminServerGroupView {
+	views := make([]AdminServerGroupView, 0, len(groupIDs))
+	for _, id := range uniquePositive(groupIDs) {
+		group, ok := index[id]
+		if !ok {
+			views = append(views, AdminServerGroupView{ID: id})
+			continue
+		}
+		views = append(views, AdminServerGroupView{
+			ID:        group.ID,
+			Name:      group.Name,
+			Type:      group.Type,
+			Sort:      group.Sort,
+			CreatedAt: group.CreatedAt,
+			UpdatedAt: group.UpdatedAt,
+		})
+	}
+	return views
+}
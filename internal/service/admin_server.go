@@ -6,6 +6,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/creamcroissant/xboard/internal/repository"
 	"github.com/creamcroissant/xboard/internal/support/i18n"
@@ -18,28 +20,43 @@ type AdminServerService interface {
 	Nodes(ctx context.Context) ([]AdminServerNodeView, error)
 	SaveNode(ctx context.Context, input AdminServerNodeSaveInput) error
 	DeleteNode(ctx context.Context, id int64) error
+	SaveRoute(ctx context.Context, input AdminServerRouteSaveInput) (*AdminServerRouteView, error)
+	DeleteRoute(ctx context.Context, id int64) error
+	UserEffectiveGroups(ctx context.Context, userID int64) (*AdminUserEffectiveGroupsView, error)
+	// Tags 返回全部节点标签及节点数；AssignTags/RemoveTags 批量增删标签并返回变化的节点数。
+	Tags(ctx context.Context) ([]ServerTagCount, error)
+	AssignTags(ctx context.Context, input AdminServerTagsInput) (int, error)
+	RemoveTags(ctx context.Context, input AdminServerTagsInput) (int, error)
 	I18n() *i18n.Manager
+	// SetAuditLog 注入审计日志，记录节点与路由规则的变更。
+	SetAuditLog(audit AuditLogService)
+	// SetTelemetry 注入节点遥测，节点列表据此返回最近延迟。
+	SetTelemetry(telemetry ServerTelemetryService)
 }
 
 // AdminServerNodeSaveInput 定义保存节点的请求参数。
+// TrafficRatio 为计费倍率，必须为正数；省略时取 Rate，两者都省略时新建节点取 1.0，更新节点保持原值。
+// 保存后 rate 与 traffic_ratio 始终一致，两条上报路径只按这一个倍率计费。
 type AdminServerNodeSaveInput struct {
-	ID         int64           `json:"id"`
-	Name       string          `json:"name"`
-	GroupID    int64           `json:"group_id"`
-	RouteID    int64           `json:"route_id"`
-	ParentID   int64           `json:"parent_id"`
-	Rate       string          `json:"rate"`
-	Host       string          `json:"host"`
-	Port       int             `json:"port"`
-	ServerPort int             `json:"server_port"`
-	Cipher     string          `json:"cipher"`
-	Obfs       string          `json:"obfs"`
-	Show       int             `json:"show"`
-	Sort       int64           `json:"sort"`
-	Status     int             `json:"status"`
-	Type       string          `json:"type"`
-	Tags       json.RawMessage `json:"tags"`
-	Settings   json.RawMessage `json:"settings"`
+	ID           int64           `json:"id"`
+	Name         string          `json:"name"`
+	GroupID      int64           `json:"group_id"`
+	RouteID      int64           `json:"route_id"`
+	ParentID     int64           `json:"parent_id"`
+	Rate         string          `json:"rate"`
+	Host         string          `json:"host"`
+	Port         int             `json:"port"`
+	ServerPort   int             `json:"server_port"`
+	Cipher       string          `json:"cipher"`
+	Obfs         string          `json:"obfs"`
+	Show         int             `json:"show"`
+	Sort         int64           `json:"sort"`
+	Weight       int             `json:"weight"`
+	TrafficRatio *float64        `json:"traffic_ratio,omitempty"`
+	Status       int             `json:"status"`
+	Type         string          `json:"type"`
+	Tags         json.RawMessage `json:"tags"`
+	Settings     json.RawMessage `json:"settings"`
 }
 
 // AdminServerGroupView 对齐管理端期望的服务器分组响应。
@@ -56,52 +73,70 @@ type AdminServerGroupView struct {
 type AdminServerRouteView struct {
 	ID          int64           `json:"id"`
 	Remarks     string          `json:"remarks"`
+	MatchType   string          `json:"match_type"`
 	Match       json.RawMessage `json:"match"`
 	Action      string          `json:"action"`
 	ActionValue string          `json:"action_value"`
+	Priority    int64           `json:"priority"`
 	CreatedAt   int64           `json:"created_at"`
 	UpdatedAt   int64           `json:"updated_at"`
 }
 
 // AdminServerNodeView 表示 /server/manage/fetch 返回的节点数据。
 type AdminServerNodeView struct {
-	ID         int64           `json:"id"`
-	Name       string          `json:"name"`
-	GroupID    int64           `json:"group_id"`
-	RouteID    int64           `json:"route_id"`
-	ParentID   int64           `json:"parent_id"`
-	Rate       string          `json:"rate"`
-	Host       string          `json:"host"`
-	Port       int             `json:"port"`
-	ServerPort int             `json:"server_port"`
-	Cipher     string          `json:"cipher"`
-	Obfs       string          `json:"obfs"`
-	Show       int             `json:"show"`
-	Sort       int64           `json:"sort"`
-	Status     int             `json:"status"`
-	Type       string          `json:"type"`
-	Tags       json.RawMessage `json:"tags"`
-	Settings   json.RawMessage `json:"settings"`
-	CreatedAt  int64           `json:"created_at"`
-	UpdatedAt  int64           `json:"updated_at"`
+	ID           int64           `json:"id"`
+	Name         string          `json:"name"`
+	GroupID      int64           `json:"group_id"`
+	RouteID      int64           `json:"route_id"`
+	ParentID     int64           `json:"parent_id"`
+	Rate         string          `json:"rate"`
+	Host         string          `json:"host"`
+	Port         int             `json:"port"`
+	ServerPort   int             `json:"server_port"`
+	Cipher       string          `json:"cipher"`
+	Obfs         string          `json:"obfs"`
+	Show         int             `json:"show"`
+	Sort         int64           `json:"sort"`
+	Weight       int             `json:"weight"`
+	TrafficRatio float64         `json:"traffic_ratio"`
+	Status       int             `json:"status"`
+	Type         string          `json:"type"`
+	Tags         json.RawMessage `json:"tags"`
+	Settings     json.RawMessage `json:"settings"`
+	CreatedAt    int64           `json:"created_at"`
+	UpdatedAt    int64           `json:"updated_at"`
+	// LatencyMs 为节点最近上报的延迟（毫秒），没有数据或已过期时为 null。
+	LatencyMs *int64 `json:"latency_ms"`
 }
 
 type adminServerService struct {
-	groups  repository.ServerGroupRepository
-	routes  repository.ServerRouteRepository
-	servers repository.ServerRepository
-	i18n    *i18n.Manager
+	groups    repository.ServerGroupRepository
+	routes    repository.ServerRouteRepository
+	servers   repository.ServerRepository
+	users     repository.UserRepository
+	plans     repository.PlanRepository
+	i18n      *i18n.Manager
+	audit     AuditLogService
+	telemetry ServerTelemetryService
 }
 
 // NewAdminServerService 组装管理端节点管理所需仓储。
-func NewAdminServerService(groups repository.ServerGroupRepository, routes repository.ServerRouteRepository, servers repository.ServerRepository, i18nMgr *i18n.Manager) AdminServerService {
-	return &adminServerService{groups: groups, routes: routes, servers: servers, i18n: i18nMgr}
+func NewAdminServerService(groups repository.ServerGroupRepository, routes repository.ServerRouteRepository, servers repository.ServerRepository, users repository.UserRepository, plans repository.PlanRepository, i18nMgr *i18n.Manager) AdminServerService {
+	return &adminServerService{groups: groups, routes: routes, servers: servers, users: users, plans: plans, i18n: i18nMgr}
 }
 
 func (s *adminServerService) I18n() *i18n.Manager {
 	return s.i18n
 }
 
+func (s *adminServerService) SetAuditLog(audit AuditLogService) {
+	s.audit = audit
+}
+
+func (s *adminServerService) SetTelemetry(telemetry ServerTelemetryService) {
+	s.telemetry = telemetry
+}
+
 func (s *adminServerService) Groups(ctx context.Context) ([]AdminServerGroupView, error) {
 	if s == nil || s.groups == nil {
 		return nil, fmt.Errorf("admin server service not configured / 管理节点服务未配置")
@@ -140,15 +175,7 @@ func (s *adminServerService) Routes(ctx context.Context) ([]AdminServerRouteView
 		if route == nil {
 			continue
 		}
-		views = append(views, AdminServerRouteView{
-			ID:          route.ID,
-			Remarks:     route.Remarks,
-			Match:       route.Match,
-			Action:      route.Action,
-			ActionValue: route.ActionValue,
-			CreatedAt:   route.CreatedAt,
-			UpdatedAt:   route.UpdatedAt,
-		})
+		views = append(views, toAdminServerRouteView(route))
 	}
 	return views, nil
 }
@@ -161,9 +188,14 @@ func (s *adminServerService) Nodes(ctx context.Context) ([]AdminServerNodeView,
 	if err != nil {
 		return nil, err
 	}
+	latencies := nodeLatencies(ctx, s.telemetry, servers)
 	views := make([]AdminServerNodeView, 0, len(servers))
 	for _, node := range servers {
-		views = append(views, toAdminServerNodeView(node))
+		view := toAdminServerNodeView(node)
+		if latency, ok := serverLatency(node, latencies); ok {
+			view.LatencyMs = &latency
+		}
+		views = append(views, view)
 	}
 	return views, nil
 }
@@ -187,23 +219,121 @@ func (s *adminServerService) SaveNode(ctx context.Context, input AdminServerNode
 		Obfs:       input.Obfs,
 		Show:       input.Show,
 		Sort:       input.Sort,
+		Weight:     input.Weight,
 		Status:     input.Status,
 		Type:       input.Type,
 		Tags:       input.Tags,
 		Settings:   input.Settings,
 	}
 
+	if input.Weight < 0 {
+		return ErrBadRequest
+	}
+	tags, err := normalizeServerTagsJSON(input.Tags)
+	if err != nil {
+		return err
+	}
+	server.Tags = tags
+	ratio, ratioSet, err := nodeSaveTrafficRatio(input)
+	if err != nil {
+		return err
+	}
+	server.TrafficRatio = 1
+
 	if input.ID > 0 {
-		return s.servers.Update(ctx, server)
+		// 审计只比较管理端可编辑的字段，先取出旧值
+		var before *AdminServerNodeSaveInput
+		if existing, err := s.servers.FindByID(ctx, input.ID); err == nil {
+			snapshot := auditServerNode(existing)
+			before = &snapshot
+			server.TrafficRatio = serverTrafficRatio(existing)
+		}
+		if ratioSet {
+			server.TrafficRatio = ratio
+		}
+		server.Rate = formatTrafficRatio(server.TrafficRatio)
+		if err := s.servers.Update(ctx, server); err != nil {
+			return err
+		}
+		recordAudit(ctx, s.audit, AuditEntry{Action: AuditActionUpdate, TargetType: AuditTargetServer, TargetID: server.ID, Before: before, After: auditServerNode(server)})
+		return nil
 	}
-	return s.servers.Create(ctx, server)
+	if ratioSet {
+		server.TrafficRatio = ratio
+	}
+	server.Rate = formatTrafficRatio(server.TrafficRatio)
+	if err := s.servers.Create(ctx, server); err != nil {
+		return err
+	}
+	recordAudit(ctx, s.audit, AuditEntry{Action: AuditActionCreate, TargetType: AuditTargetServer, TargetID: server.ID, After: auditServerNode(server)})
+	return nil
+}
+
+// nodeSaveTrafficRatio 解析保存请求中的计费倍率：traffic_ratio 优先，其次兼容旧客户端提交的 rate。
+// 第二个返回值表示请求是否指定了倍率。
+func nodeSaveTrafficRatio(input AdminServerNodeSaveInput) (float64, bool, error) {
+	if input.TrafficRatio != nil {
+		if !validTrafficRatio(*input.TrafficRatio) {
+			return 0, false, fmt.Errorf("%w: traffic_ratio must be positive / 计费倍率必须为正数", ErrBadRequest)
+		}
+		return *input.TrafficRatio, true, nil
+	}
+	rate := strings.TrimSpace(input.Rate)
+	if rate == "" {
+		return 0, false, nil
+	}
+	parsed, err := strconv.ParseFloat(rate, 64)
+	if err != nil || !validTrafficRatio(parsed) {
+		return 0, false, fmt.Errorf("%w: rate must be a positive number / 倍率必须为正数", ErrBadRequest)
+	}
+	return parsed, true, nil
+}
+
+// formatTrafficRatio 把计费倍率写回 rate 文本列，供面板与订阅展示。
+func formatTrafficRatio(ratio float64) string {
+	return strconv.FormatFloat(ratio, 'f', -1, 64)
 }
 
 func (s *adminServerService) DeleteNode(ctx context.Context, id int64) error {
 	if s == nil || s.servers == nil {
 		return fmt.Errorf("admin server service not configured / 管理节点服务未配置")
 	}
-	return s.servers.Delete(ctx, id)
+	existing, _ := s.servers.FindByID(ctx, id)
+	if err := s.servers.Delete(ctx, id); err != nil {
+		return err
+	}
+	var before any
+	if existing != nil {
+		before = auditServerNode(existing)
+	}
+	recordAudit(ctx, s.audit, AuditEntry{Action: AuditActionDelete, TargetType: AuditTargetServer, TargetID: id, Before: before})
+	return nil
+}
+
+// auditServerNode 把节点转换为 SaveNode 可编辑的字段集合，供审计比较。
+func auditServerNode(server *repository.Server) AdminServerNodeSaveInput {
+	ratio := serverTrafficRatio(server)
+	return AdminServerNodeSaveInput{
+		ID:           server.ID,
+		Name:         server.Name,
+		GroupID:      server.GroupID,
+		RouteID:      server.RouteID,
+		ParentID:     server.ParentID,
+		Rate:         server.Rate,
+		Host:         server.Host,
+		Port:         server.Port,
+		ServerPort:   server.ServerPort,
+		Cipher:       server.Cipher,
+		Obfs:         server.Obfs,
+		Show:         server.Show,
+		Sort:         server.Sort,
+		Weight:       server.Weight,
+		TrafficRatio: &ratio,
+		Status:       server.Status,
+		Type:         server.Type,
+		Tags:         server.Tags,
+		Settings:     server.Settings,
+	}
 }
 
 func toAdminServerNodeView(node *repository.Server) AdminServerNodeView {
@@ -211,24 +341,26 @@ func toAdminServerNodeView(node *repository.Server) AdminServerNodeView {
 		return AdminServerNodeView{}
 	}
 	return AdminServerNodeView{
-		ID:         node.ID,
-		Name:       node.Name,
-		GroupID:    node.GroupID,
-		RouteID:    node.RouteID,
-		ParentID:   node.ParentID,
-		Rate:       node.Rate,
-		Host:       node.Host,
-		Port:       node.Port,
-		ServerPort: node.ServerPort,
-		Cipher:     node.Cipher,
-		Obfs:       node.Obfs,
-		Show:       node.Show,
-		Sort:       node.Sort,
-		Status:     node.Status,
-		Type:       node.Type,
-		Tags:       node.Tags,
-		Settings:   node.Settings,
-		CreatedAt:  node.CreatedAt,
-		UpdatedAt:  node.UpdatedAt,
+		ID:           node.ID,
+		Name:         node.Name,
+		GroupID:      node.GroupID,
+		RouteID:      node.RouteID,
+		ParentID:     node.ParentID,
+		Rate:         node.Rate,
+		Host:         node.Host,
+		Port:         node.Port,
+		ServerPort:   node.ServerPort,
+		Cipher:       node.Cipher,
+		Obfs:         node.Obfs,
+		Show:         node.Show,
+		Sort:         node.Sort,
+		Weight:       node.Weight,
+		TrafficRatio: serverTrafficRatio(node),
+		Status:       node.Status,
+		Type:         node.Type,
+		Tags:         node.Tags,
+		Settings:     node.Settings,
+		CreatedAt:    node.CreatedAt,
+		UpdatedAt:    node.UpdatedAt,
 	}
 }
@@ -0,0 +1,102 @@
+// 文件路径: internal/service/admin_server_access.go
+// 模块说明: 这是 internal 模块里的 admin_server_access 逻辑，向管理端解释用户为何能看到某些节点。
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+// AdminUserEffectiveGroupsView 展示用户的有效节点分组及其来源，与订阅下发判定一致。
+type AdminUserEffectiveGroupsView struct {
+	UserID          int64                    `json:"user_id"`
+	PlanID          int64                    `json:"plan_id"`
+	DirectGroup     *AdminServerGroupView    `json:"direct_group,omitempty"`
+	PlanGroups      []AdminServerGroupView   `json:"plan_groups"`
+	EffectiveGroups []AdminServerGroupView   `json:"effective_groups"`
+	Unrestricted    bool                     `json:"unrestricted"`
+	VisibleServers  []AdminVisibleServerView `json:"visible_servers"`
+}
+
+// AdminVisibleServerView 是用户可见节点的精简信息。
+type AdminVisibleServerView struct {
+	ID      int64  `json:"id"`
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	GroupID int64  `json:"group_id"`
+}
+
+// UserEffectiveGroups 计算用户的有效分组（直属分组 + 套餐分组）及可见节点。
+// 无任何分组时与订阅逻辑一致，回退为全部可见节点并标记 unrestricted。
+func (s *adminServerService) UserEffectiveGroups(ctx context.Context, userID int64) (*AdminUserEffectiveGroupsView, error) {
+	if s == nil || s.groups == nil || s.servers == nil || s.users == nil {
+		return nil, fmt.Errorf("admin server service not configured / 管理节点服务未配置")
+	}
+	if userID <= 0 {
+		return nil, ErrNotFound
+	}
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	groups, err := s.groups.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[int64]*repository.ServerGroup, len(groups))
+	for _, group := range groups {
+		if group != nil {
+			index[group.ID] = group
+		}
+	}
+
+	view := &AdminUserEffectiveGroupsView{UserID: user.ID, PlanID: user.PlanID, PlanGroups: []AdminServerGroupView{}}
+	if user.GroupID > 0 {
+		direct := serverGroupViews(index, []int64{user.GroupID})
+		view.DirectGroup = &direct[0]
+	}
+	if user.PlanID > 0 && s.plans != nil {
+		planGroups, err := s.plans.GetGroups(ctx, user.PlanID)
+		if err != nil {
+			return nil, err
+		}
+		view.PlanGroups = serverGroupViews(index, planGroups)
+	}
+
+	effective, err := effectiveGroupIDs(ctx, s.plans, user)
+	if err != nil {
+		return nil, err
+	}
+	view.EffectiveGroups = serverGroupViews(index, effective)
+	view.Unrestricted = len(effective) == 0
+
+	var servers []*repository.Server
+	if view.Unrestricted {
+		servers, err = s.servers.FindAllVisible(ctx)
+	} else {
+		servers, err = s.servers.FindByGroupIDs(ctx, effective)
+	}
+	if err != nil {
+		return nil, err
+	}
+	view.VisibleServers = make([]AdminVisibleServerView, 0, len(servers))
+	for _, server := range servers {
+		if server == nil {
+			continue
+		}
+		view.VisibleServers = append(view.VisibleServers, AdminVisibleServerView{
+			ID:      server.ID,
+			Name:    server.Name,
+			Type:    server.Type,
+			GroupID: server.GroupID,
+		})
+	}
+	return view, nil
+}
@@ -0,0 +1,245 @@
+// 文件路径: internal/service/admin_server_route.go
+// 模块说明: 这是 internal 模块里的 admin_server_route 逻辑，负责路由规则的增删改与保存前校验。
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+// 路由规则匹配类型。
+const (
+	RouteMatchDomain        = "domain"
+	RouteMatchDomainSuffix  = "domain_suffix"
+	RouteMatchDomainKeyword = "domain_keyword"
+	RouteMatchDomainRegex   = "domain_regex"
+	RouteMatchIP            = "ip"
+	RouteMatchGeosite       = "geosite"
+	RouteMatchGeoIP         = "geoip"
+)
+
+// 路由规则动作：block 拒绝、direct 直连、proxy 转发到 action_value 指定的出站。
+const (
+	RouteActionBlock  = "block"
+	RouteActionDirect = "direct"
+	RouteActionProxy  = "proxy"
+)
+
+var (
+	routeDomainLabelPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+	routeGeoCodePattern     = regexp.MustCompile(`^[a-z0-9][a-z0-9@!:._-]*$`)
+	routeOutboundTagPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+)
+
+// AdminServerRouteSaveInput 定义保存路由规则的请求参数，ID 为 0 时新建。
+type AdminServerRouteSaveInput struct {
+	ID          int64           `json:"id"`
+	Remarks     string          `json:"remarks"`
+	MatchType   string          `json:"match_type"`
+	Match       json.RawMessage `json:"match"`
+	Action      string          `json:"action"`
+	ActionValue string          `json:"action_value"`
+	Priority    int64           `json:"priority"`
+}
+
+// SaveRoute 校验并保存路由规则，返回保存后的视图。
+func (s *adminServerService) SaveRoute(ctx context.Context, input AdminServerRouteSaveInput) (*AdminServerRouteView, error) {
+	if s == nil || s.routes == nil {
+		return nil, fmt.Errorf("admin server service not configured / 管理节点服务未配置")
+	}
+	route, err := normalizeServerRouteInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.ID <= 0 {
+		created, err := s.routes.Create(ctx, route)
+		if err != nil {
+			return nil, err
+		}
+		recordAudit(ctx, s.audit, AuditEntry{Action: AuditActionCreate, TargetType: AuditTargetServerRoute, TargetID: created.ID, After: created})
+		view := toAdminServerRouteView(created)
+		return &view, nil
+	}
+
+	existing, err := s.routes.FindByID(ctx, input.ID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	route.ID = existing.ID
+	route.CreatedAt = existing.CreatedAt
+	if err := s.routes.Update(ctx, route); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	recordAudit(ctx, s.audit, AuditEntry{Action: AuditActionUpdate, TargetType: AuditTargetServerRoute, TargetID: route.ID, Before: existing, After: route})
+	view := toAdminServerRouteView(route)
+	return &view, nil
+}
+
+// DeleteRoute 删除路由规则；仍引用该规则的节点在下发时会忽略失效的 route_id。
+func (s *adminServerService) DeleteRoute(ctx context.Context, id int64) error {
+	if s == nil || s.routes == nil {
+		return fmt.Errorf("admin server service not configured / 管理节点服务未配置")
+	}
+	if id <= 0 {
+		return ErrNotFound
+	}
+	existing, _ := s.routes.FindByID(ctx, id)
+	if err := s.routes.Delete(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	recordAudit(ctx, s.audit, AuditEntry{Action: AuditActionDelete, TargetType: AuditTargetServerRoute, TargetID: id, Before: existing})
+	return nil
+}
+
+func toAdminServerRouteView(route *repository.ServerRoute) AdminServerRouteView {
+	if route == nil {
+		return AdminServerRouteView{}
+	}
+	return AdminServerRouteView{
+		ID:          route.ID,
+		Remarks:     route.Remarks,
+		MatchType:   route.MatchType,
+		Match:       route.Match,
+		Action:      route.Action,
+		ActionValue: route.ActionValue,
+		Priority:    route.Priority,
+		CreatedAt:   route.CreatedAt,
+		UpdatedAt:   route.UpdatedAt,
+	}
+}
+
+// normalizeServerRouteInput 校验匹配类型、匹配值与动作，并规范化匹配值（小写域名、IP 转 CIDR、去重）。
+func normalizeServerRouteInput(input AdminServerRouteSaveInput) (*repository.ServerRoute, error) {
+	matchType := strings.ToLower(strings.TrimSpace(input.MatchType))
+	if matchType == "" {
+		matchType = RouteMatchDomain
+	}
+
+	var raw []string
+	if err := json.Unmarshal(input.Match, &raw); err != nil {
+		return nil, fmt.Errorf("%w: match must be a JSON string array / match 必须为字符串数组", ErrBadRequest)
+	}
+	values := make([]string, 0, len(raw))
+	seen := make(map[string]struct{}, len(raw))
+	for _, item := range raw {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		value, err := normalizeRouteMatchValue(matchType, item)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := seen[value]; ok {
+			continue
+		}
+		seen[value] = struct{}{}
+		values = append(values, value)
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("%w: match cannot be empty / match 不能为空", ErrBadRequest)
+	}
+
+	action := strings.ToLower(strings.TrimSpace(input.Action))
+	actionValue := strings.TrimSpace(input.ActionValue)
+	switch action {
+	case RouteActionBlock, RouteActionDirect:
+		actionValue = ""
+	case RouteActionProxy:
+		if !routeOutboundTagPattern.MatchString(actionValue) {
+			return nil, fmt.Errorf("%w: proxy action requires a valid outbound tag in action_value / proxy 动作需要在 action_value 中填写有效的出站标签", ErrBadRequest)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported route action %q / 不支持的路由动作", ErrBadRequest, input.Action)
+	}
+
+	match, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	return &repository.ServerRoute{
+		Remarks:     strings.TrimSpace(input.Remarks),
+		MatchType:   matchType,
+		Match:       match,
+		Action:      action,
+		ActionValue: actionValue,
+		Priority:    input.Priority,
+	}, nil
+}
+
+func normalizeRouteMatchValue(matchType, value string) (string, error) {
+	switch matchType {
+	case RouteMatchDomain:
+		value = strings.ToLower(value)
+		if !isValidRouteDomain(value) {
+			return "", fmt.Errorf("%w: invalid domain %q / 域名格式无效", ErrBadRequest, value)
+		}
+	case RouteMatchDomainSuffix:
+		value = strings.ToLower(value)
+		if !isValidRouteDomain(strings.TrimPrefix(value, ".")) {
+			return "", fmt.Errorf("%w: invalid domain suffix %q / 域名后缀格式无效", ErrBadRequest, value)
+		}
+	case RouteMatchDomainKeyword:
+		value = strings.ToLower(value)
+		if strings.ContainsAny(value, " \t/") {
+			return "", fmt.Errorf("%w: invalid domain keyword %q / 域名关键字格式无效", ErrBadRequest, value)
+		}
+	case RouteMatchDomainRegex:
+		if _, err := regexp.Compile(value); err != nil {
+			return "", fmt.Errorf("%w: invalid domain regex %q: %v / 域名正则无效", ErrBadRequest, value, err)
+		}
+	case RouteMatchIP:
+		if strings.Contains(value, "/") {
+			_, network, err := net.ParseCIDR(value)
+			if err != nil {
+				return "", fmt.Errorf("%w: invalid CIDR %q / CIDR 格式无效", ErrBadRequest, value)
+			}
+			return network.String(), nil
+		}
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return "", fmt.Errorf("%w: invalid IP %q / IP 格式无效", ErrBadRequest, value)
+		}
+		if ip.To4() != nil {
+			return ip.String() + "/32", nil
+		}
+		return ip.String() + "/128", nil
+	case RouteMatchGeosite, RouteMatchGeoIP:
+		value = strings.ToLower(value)
+		if !routeGeoCodePattern.MatchString(value) {
+			return "", fmt.Errorf("%w: invalid %s code %q / %s 代码格式无效", ErrBadRequest, matchType, value, matchType)
+		}
+	default:
+		return "", fmt.Errorf("%w: unsupported match type %q / 不支持的匹配类型", ErrBadRequest, matchType)
+	}
+	return value, nil
+}
+
+func isValidRouteDomain(domain string) bool {
+	if domain == "" || len(domain) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(domain, ".") {
+		if !routeDomainLabelPattern.MatchString(label) {
+			return false
+		}
+	}
+	return true
+}
@@ -25,6 +25,7 @@ type AdminStatService interface {
 	GetUserStats(ctx context.Context, input AdminStatUserInput) ([]AdminStatUserView, error)
 	GetDashboardStats(ctx context.Context) (*AdminDashboardStats, error)
 	GetTrafficRank(ctx context.Context, input AdminStatTrafficInput) (*AdminStatTrafficResult, error)
+	GetTrafficResets(ctx context.Context, input AdminStatTrafficResetInput) (*AdminStatTrafficResetResult, error)
 }
 
 // AdminStatUserInput controls stat_users queries.
@@ -95,16 +96,48 @@ type AdminStatTrafficEntry struct {
 	Timestamp     string  `json:"timestamp"`
 }
 
+// AdminStatTrafficResetInput filters archived plan-cycle traffic totals.
+type AdminStatTrafficResetInput struct {
+	UserID    int64
+	PlanID    int64
+	StartTime int64
+	EndTime   int64
+	Limit     int
+	Offset    int
+}
+
+// AdminStatTrafficResetView is one archived billing cycle for a user.
+type AdminStatTrafficResetView struct {
+	ID          int64  `json:"id"`
+	UserID      int64  `json:"user_id"`
+	Email       string `json:"email"`
+	PlanID      int64  `json:"plan_id"`
+	PeriodStart int64  `json:"period_start"`
+	PeriodEnd   int64  `json:"period_end"`
+	Upload      int64  `json:"u"`
+	Download    int64  `json:"d"`
+	Total       int64  `json:"total"`
+	QuotaBytes  int64  `json:"quota_bytes"`
+	ResetAt     int64  `json:"reset_at"`
+}
+
+// AdminStatTrafficResetResult pages archived cycle totals.
+type AdminStatTrafficResetResult struct {
+	Data  []AdminStatTrafficResetView `json:"data"`
+	Total int64                       `json:"total"`
+}
+
 type adminStatService struct {
-	stats repository.StatUserRepository
-	users repository.UserRepository
-	now   func() time.Time
+	stats   repository.StatUserRepository
+	users   repository.UserRepository
+	traffic repository.UserTrafficRepository
+	now     func() time.Time
 }
 
 // NewAdminStatService wires repositories for admin statistics endpoints.
 // Order-dependent metrics are disabled because the Order/Coupon tables were removed.
-func NewAdminStatService(stats repository.StatUserRepository, users repository.UserRepository) AdminStatService {
-	return &adminStatService{stats: stats, users: users, now: func() time.Time { return time.Now().UTC() }}
+func NewAdminStatService(stats repository.StatUserRepository, users repository.UserRepository, traffic repository.UserTrafficRepository) AdminStatService {
+	return &adminStatService{stats: stats, users: users, traffic: traffic, now: func() time.Time { return time.Now().UTC() }}
 }
 
 func (s *adminStatService) GetUserStats(ctx context.Context, input AdminStatUserInput) ([]AdminStatUserView, error) {
@@ -306,6 +339,61 @@ func (s *adminStatService) GetTrafficRank(ctx context.Context, input AdminStatTr
 	return result, nil
 }
 
+// GetTrafficResets 查询套餐周期重置时归档的用户流量。
+func (s *adminStatService) GetTrafficResets(ctx context.Context, input AdminStatTrafficResetInput) (*AdminStatTrafficResetResult, error) {
+	if s == nil || s.traffic == nil {
+		return nil, fmt.Errorf("admin stat service not configured / 管理统计服务未配置")
+	}
+	filter := repository.UserTrafficResetFilter{
+		StartAt: input.StartTime,
+		EndAt:   input.EndTime,
+		Limit:   clampStatLimit(input.Limit),
+		Offset:  input.Offset,
+	}
+	if input.UserID > 0 {
+		filter.UserID = &input.UserID
+	}
+	if input.PlanID > 0 {
+		filter.PlanID = &input.PlanID
+	}
+	resets, err := s.traffic.ListResets(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.traffic.CountResets(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	result := &AdminStatTrafficResetResult{Data: make([]AdminStatTrafficResetView, 0, len(resets)), Total: total}
+	emails := make(map[int64]string)
+	for _, reset := range resets {
+		email, ok := emails[reset.UserID]
+		if !ok {
+			email = fmt.Sprintf("user-%d", reset.UserID)
+			if s.users != nil {
+				if user, err := s.users.FindByID(ctx, reset.UserID); err == nil && user != nil {
+					email = strings.TrimSpace(user.Email)
+				}
+			}
+			emails[reset.UserID] = email
+		}
+		result.Data = append(result.Data, AdminStatTrafficResetView{
+			ID:          reset.ID,
+			UserID:      reset.UserID,
+			Email:       email,
+			PlanID:      reset.PlanID,
+			PeriodStart: reset.PeriodStart,
+			PeriodEnd:   reset.PeriodEnd,
+			Upload:      reset.Upload,
+			Download:    reset.Download,
+			Total:       reset.Upload + reset.Download,
+			QuotaBytes:  reset.QuotaBytes,
+			ResetAt:     reset.ResetAt,
+		})
+	}
+	return result, nil
+}
+
 func normalizeRecordType(value string) string {
 	trimmed := strings.ToLower(strings.TrimSpace(value))
 	switch trimmed {
@@ -5,8 +5,13 @@ package service
 import (
 	"context"
 	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/url"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -23,25 +28,80 @@ type AdminUserService interface {
 	Update(ctx context.Context, input AdminUserUpdateInput) (*AdminUserView, error)
 	Delete(ctx context.Context, id int64) error
 	Generate(ctx context.Context, input AdminUserGenerateInput) (*AdminUserView, error)
-	Export(ctx context.Context, input AdminUserFetchInput) ([]byte, error)
-	Import(ctx context.Context, data []byte) (*AdminUserImportResult, error)
+	Export(ctx context.Context, input AdminUserExportInput, w io.Writer) error
+	Import(ctx context.Context, data []byte, dryRun bool) (*AdminUserImportResult, error)
+	BulkUpdate(ctx context.Context, ids []int64, action BulkAction) (*AdminUserBulkResult, error)
 	I18n() *i18n.Manager
+	// SetResyncNotifier 注入 Agent 重同步通知，用户变更后推送给承载其权限组的节点。
+	SetResyncNotifier(notifier AgentResyncNotifier)
+	// SetAuditLog 注入审计日志，记录用户的创建、修改与删除。
+	SetAuditLog(audit AuditLogService)
 }
 
-// AdminUserImportResult 返回批量导入的结果状态。
+// AdminUserImportResult 返回批量导入的结果状态；DryRun 时 SuccessCount 表示可成功导入的行数。
 type AdminUserImportResult struct {
 	SuccessCount int      `json:"success_count"`
 	FailureCount int      `json:"failure_count"`
 	Errors       []string `json:"errors"`
+	DryRun       bool     `json:"dry_run"`
 }
 
 // AdminUserFetchInput 控制列表分页与过滤条件。
 type AdminUserFetchInput struct {
-	Query  string
-	Status *int
-	PlanID *int64
-	Limit  int
-	Offset int
+	Query              string
+	Status             *int
+	PlanID             *int64
+	Banned             *bool
+	OverQuota          bool
+	ExpiringWithinDays int
+	SortBy             string // traffic_used / expired_at / last_login_at / created_at / online_count
+	SortOrder          string // asc / desc
+	Limit              int
+	Offset             int
+}
+
+// AdminUserSortOnlineCount 按在线设备数排序。在线数来自遥测而非 users 表，无法在 SQL 中排序：
+// 仅在显式请求时先取出全部匹配用户、查询在线数后在内存中排序再分页，用户量大时开销明显，应配合筛选条件使用。
+const AdminUserSortOnlineCount = "online_count"
+
+// searchFilter 把管理端输入转换为仓储过滤条件，Fetch 与 Export 共用。
+func (input AdminUserFetchInput) searchFilter() repository.UserSearchFilter {
+	return repository.UserSearchFilter{
+		Keyword:            strings.TrimSpace(input.Query),
+		Status:             input.Status,
+		PlanID:             input.PlanID,
+		Banned:             input.Banned,
+		OverQuota:          input.OverQuota,
+		ExpiringWithinDays: input.ExpiringWithinDays,
+		SortBy:             input.SortBy,
+		SortOrder:          input.SortOrder,
+		Limit:              input.Limit,
+		Offset:             input.Offset,
+	}
+}
+
+// 导出格式，CSV 为默认值。
+const (
+	AdminUserExportFormatCSV  = "csv"
+	AdminUserExportFormatJSON = "json"
+)
+
+// AdminUserExportInput 控制导出的筛选条件与格式。
+type AdminUserExportInput struct {
+	AdminUserFetchInput
+	Format string
+}
+
+// NormalizeAdminUserExportFormat 规范化导出格式，空值回退为 CSV，不支持的格式返回 ErrBadRequest。
+func NormalizeAdminUserExportFormat(format string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", AdminUserExportFormatCSV:
+		return AdminUserExportFormatCSV, nil
+	case AdminUserExportFormatJSON:
+		return AdminUserExportFormatJSON, nil
+	default:
+		return "", fmt.Errorf("%w: unsupported export format %q / 不支持的导出格式", ErrBadRequest, format)
+	}
 }
 
 // AdminUserFetchResult 包装分页用户列表。
@@ -64,6 +124,10 @@ type AdminUserUpdateInput struct {
 	Remarks        *string `json:"remarks,omitempty"`
 	Tags           []string `json:"tags,omitempty"`
 	InviteLimit    *int64   `json:"invite_limit,omitempty"`
+	// ExpectedUpdatedAt 为编辑时读取到的 updated_at，大于 0 时启用乐观锁，记录已被他人修改则返回 ErrConflict
+	ExpectedUpdatedAt int64 `json:"expected_updated_at,omitempty"`
+	// SubscriptionTemplateID 设置用户默认订阅模板，0 表示清除并沿用套餐或系统默认模板
+	SubscriptionTemplateID *int64 `json:"subscription_template_id,omitempty"`
 }
 
 // AdminUserGenerateInput 用于创建新用户。
@@ -114,6 +178,8 @@ type AdminUserView struct {
 	T                 int64                   `json:"t"`
 	OnlineCount       int                     `json:"online_count"`
 	SubscribeURL      string                  `json:"subscribe_url"`
+	// SubscriptionTemplateID 为用户默认订阅模板，null 表示未设置
+	SubscriptionTemplateID *int64 `json:"subscription_template_id"`
 }
 
 // AdminUserPlanSummary 提供管理端所需的最小套餐信息。
@@ -140,8 +206,11 @@ type adminUserService struct {
 	groups    repository.ServerGroupRepository
 	settings  repository.SettingRepository
 	telemetry ServerTelemetryService
+	devices   OnlineDeviceService
 	hasher    hash.Hasher
 	i18n      *i18n.Manager
+	resync    AgentResyncNotifier
+	audit     AuditLogService
 }
 
 // NewAdminUserService 组装管理员用户流程所需仓储。
@@ -151,6 +220,7 @@ func NewAdminUserService(
 	groups repository.ServerGroupRepository,
 	settings repository.SettingRepository,
 	telemetry ServerTelemetryService,
+	devices OnlineDeviceService,
 	hasher hash.Hasher,
 	i18n *i18n.Manager,
 ) AdminUserService {
@@ -160,6 +230,7 @@ func NewAdminUserService(
 		groups:    groups,
 		settings:  settings,
 		telemetry: telemetry,
+		devices:   devices,
 		hasher:    hasher,
 		i18n:      i18n,
 	}
@@ -169,28 +240,47 @@ func (s *adminUserService) I18n() *i18n.Manager {
 	return s.i18n
 }
 
+func (s *adminUserService) SetResyncNotifier(notifier AgentResyncNotifier) {
+	s.resync = notifier
+}
+
+// notifyGroupsChanged 通知承载这些权限组节点的 Agent 立即拉取用户。
+func (s *adminUserService) SetAuditLog(audit AuditLogService) {
+	s.audit = audit
+}
+
+func (s *adminUserService) notifyGroupsChanged(ctx context.Context, groupIDs ...int64) {
+	if s.resync != nil && len(groupIDs) > 0 {
+		s.resync.NotifyGroupsChanged(ctx, groupIDs)
+	}
+}
+
 func (s *adminUserService) Fetch(ctx context.Context, input AdminUserFetchInput) (*AdminUserFetchResult, error) {
 	if s == nil || s.users == nil {
 		return nil, fmt.Errorf("admin user service not configured / 管理用户服务未配置")
 	}
-	filter := repository.UserSearchFilter{
-		Keyword: strings.TrimSpace(input.Query),
-		Status:  input.Status,
-		PlanID:  input.PlanID,
-		Limit:   input.Limit,
-		Offset:  input.Offset,
-	}
-	users, err := s.users.Search(ctx, filter)
+	filter := input.searchFilter()
+	total, err := s.users.CountFiltered(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
-	total, err := s.users.CountFiltered(ctx, filter)
+	sortByOnline := input.SortBy == AdminUserSortOnlineCount
+	if sortByOnline {
+		// 在线数只能在内存中排序：取出全部匹配用户，排序后再分页
+		filter.SortBy = repository.UserSortID
+		filter.Limit = int(total)
+		filter.Offset = 0
+	}
+	users, err := s.users.Search(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
+	counts := s.aliveCounts(ctx, users)
+	if sortByOnline {
+		users = sortUsersByOnlineCount(users, counts, input.SortOrder, input.Limit, input.Offset)
+	}
 	planMap := s.planLookup(ctx)
 	groupMap := s.groupLookup(ctx)
-	counts := s.aliveCounts(ctx, users)
 	subscribeBase := s.subscribeBase(ctx)
 	views := make([]AdminUserView, 0, len(users))
 	for _, user := range users {
@@ -208,6 +298,29 @@ func (s *adminUserService) Fetch(ctx context.Context, input AdminUserFetchInput)
 	return &AdminUserFetchResult{Users: views, Total: total}, nil
 }
 
+// sortUsersByOnlineCount 按在线设备数稳定排序后截取当前页，在线数相同时保持 id 顺序。
+func sortUsersByOnlineCount(users []*repository.User, counts map[int64]int, order string, limit, offset int) []*repository.User {
+	asc := strings.EqualFold(order, repository.SortOrderAsc)
+	sort.SliceStable(users, func(i, j int) bool {
+		ci, cj := counts[users[i].ID], counts[users[j].ID]
+		if asc {
+			return ci < cj
+		}
+		return ci > cj
+	})
+	if offset >= len(users) {
+		return nil
+	}
+	users = users[offset:]
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit < len(users) {
+		users = users[:limit]
+	}
+	return users
+}
+
 func (s *adminUserService) GetByID(ctx context.Context, id int64) (*AdminUserView, error) {
 	if s == nil || s.users == nil {
 		return nil, fmt.Errorf("admin user service not configured / 管理用户服务未配置")
@@ -244,7 +357,12 @@ func (s *adminUserService) Delete(ctx context.Context, id int64) error {
 		}
 		return err
 	}
-	return s.users.Delete(ctx, user.ID)
+	if err := s.users.Delete(ctx, user.ID); err != nil {
+		return err
+	}
+	recordAudit(ctx, s.audit, AuditEntry{Action: AuditActionDelete, TargetType: AuditTargetUser, TargetID: user.ID, Before: user})
+	s.notifyGroupsChanged(ctx, user.GroupID)
+	return nil
 }
 
 func (s *adminUserService) Update(ctx context.Context, input AdminUserUpdateInput) (*AdminUserView, error) {
@@ -258,6 +376,11 @@ func (s *adminUserService) Update(ctx context.Context, input AdminUserUpdateInpu
 	if err != nil {
 		return nil, err
 	}
+	if input.ExpectedUpdatedAt > 0 && user.UpdatedAt != input.ExpectedUpdatedAt {
+		return nil, ErrConflict
+	}
+	before := *user
+	previousGroupID := user.GroupID
 	if input.Email != nil {
 		email := normalizeEmail(*input.Email)
 		if email == "" {
@@ -305,6 +428,9 @@ func (s *adminUserService) Update(ctx context.Context, input AdminUserUpdateInpu
 	if input.Tags != nil {
 		user.Tags = input.Tags
 	}
+	if input.SubscriptionTemplateID != nil {
+		user.SubscriptionTemplateID = optionalPositiveID(input.SubscriptionTemplateID)
+	}
 	if planUpdated && s.plans != nil {
 		plan, err := s.plans.FindByID(ctx, user.PlanID)
 		if err != nil {
@@ -322,9 +448,20 @@ func (s *adminUserService) Update(ctx context.Context, input AdminUserUpdateInpu
 		}
 	}
 	user.UpdatedAt = time.Now().Unix()
-	if err := s.users.Save(ctx, user); err != nil {
+	if input.ExpectedUpdatedAt > 0 {
+		err = s.users.SaveIfUnmodified(ctx, user, input.ExpectedUpdatedAt)
+	} else {
+		err = s.users.Save(ctx, user)
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return nil, ErrConflict
+		}
 		return nil, err
 	}
+	recordAudit(ctx, s.audit, AuditEntry{Action: AuditActionUpdate, TargetType: AuditTargetUser, TargetID: user.ID, Before: &before, After: user})
+	// 换组时新旧分组的节点都需要更新用户列表
+	s.notifyGroupsChanged(ctx, previousGroupID, user.GroupID)
 	view := s.buildView(user, adminUserViewMeta{
 		plan:          s.planByID(ctx, user.PlanID),
 		group:         s.groupByID(ctx, user.GroupID),
@@ -398,6 +535,8 @@ func (s *adminUserService) Generate(ctx context.Context, input AdminUserGenerate
 	if err != nil {
 		return nil, err
 	}
+	recordAudit(ctx, s.audit, AuditEntry{Action: AuditActionCreate, TargetType: AuditTargetUser, TargetID: created.ID, After: created})
+	s.notifyGroupsChanged(ctx, created.GroupID)
 	view := s.buildView(created, adminUserViewMeta{
 		plan:          plan,
 		group:         s.groupByID(ctx, created.GroupID),
@@ -406,34 +545,42 @@ func (s *adminUserService) Generate(ctx context.Context, input AdminUserGenerate
 	return &view, nil
 }
 
-func (s *adminUserService) Export(ctx context.Context, input AdminUserFetchInput) ([]byte, error) {
+func (s *adminUserService) Export(ctx context.Context, input AdminUserExportInput, w io.Writer) error {
 	if s == nil || s.users == nil {
-		return nil, fmt.Errorf("admin user service not configured / 管理用户服务未配置")
+		return fmt.Errorf("admin user service not configured / 管理用户服务未配置")
+	}
+	format, err := NormalizeAdminUserExportFormat(input.Format)
+	if err != nil {
+		return err
 	}
 	// 导出时不限制数量
-	input.Limit = 0
-	input.Offset = 0
-	
-	filter := repository.UserSearchFilter{
-		Keyword: strings.TrimSpace(input.Query),
-		Status:  input.Status,
-		PlanID:  input.PlanID,
+	filter := input.searchFilter()
+	filter.Limit = 0
+	filter.Offset = 0
+	if filter.SortBy == AdminUserSortOnlineCount {
+		filter.SortBy = repository.UserSortID
 	}
 	// 获取符合筛选条件的全部用户
 	users, err := s.users.Search(ctx, filter)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	if format == AdminUserExportFormatJSON {
+		return s.exportJSON(ctx, users, w)
+	}
+	return exportCSV(users, w)
+}
 
-	var sb strings.Builder
-	// CSV Header
-	sb.WriteString("Email,Balance,CommissionBalance,TransferEnable,Status,CreatedAt,ExpiredAt\n")
-
+// exportCSV 输出固定表头的 CSV，逐行写入避免拼接大字符串。
+func exportCSV(users []*repository.User, w io.Writer) error {
+	if _, err := io.WriteString(w, "Email,Balance,CommissionBalance,TransferEnable,Status,CreatedAt,ExpiredAt\n"); err != nil {
+		return err
+	}
 	for _, u := range users {
 		if u == nil {
 			continue
 		}
-		line := fmt.Sprintf("%s,%.2f,%.2f,%d,%d,%d,%d\n",
+		if _, err := fmt.Fprintf(w, "%s,%.2f,%.2f,%d,%d,%d,%d\n",
 			csvEscape(u.Email),
 			currencyFromCents(u.BalanceCents),
 			currencyFromCents(int64(u.CommissionBalance)),
@@ -441,11 +588,44 @@ func (s *adminUserService) Export(ctx context.Context, input AdminUserFetchInput
 			u.Status,
 			u.CreatedAt,
 			u.ExpiredAt,
-		)
-		sb.WriteString(line)
+		); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	return []byte(sb.String()), nil
+// exportJSON 以 JSON 数组流式输出 AdminUserView，包含套餐、分组与订阅地址。
+func (s *adminUserService) exportJSON(ctx context.Context, users []*repository.User, w io.Writer) error {
+	plans := s.planLookup(ctx)
+	groups := s.groupLookup(ctx)
+	subscribeBase := s.subscribeBase(ctx)
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	first := true
+	for _, u := range users {
+		if u == nil {
+			continue
+		}
+		if !first {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		first = false
+		view := s.buildView(u, adminUserViewMeta{
+			plan:          plans[u.PlanID],
+			group:         groups[u.GroupID],
+			subscribeBase: subscribeBase,
+		})
+		if err := encoder.Encode(view); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "]\n")
+	return err
 }
 
 func csvEscape(value string) string {
@@ -473,29 +653,42 @@ func parseCSVLine(line string) ([]string, error) {
 	return reader.Read()
 }
 
-func (s *adminUserService) Import(ctx context.Context, data []byte) (*AdminUserImportResult, error) {
+// Import 批量导入用户；dryRun 为 true 时只做校验与查重，不写入数据库。
+func (s *adminUserService) Import(ctx context.Context, data []byte, dryRun bool) (*AdminUserImportResult, error) {
 	if s == nil || s.users == nil {
 		return nil, fmt.Errorf("admin user service not configured / 管理用户服务未配置")
 	}
 
 	lines := strings.Split(string(data), "\n")
 	if len(lines) == 0 {
-		return &AdminUserImportResult{}, nil
+		return &AdminUserImportResult{DryRun: dryRun}, nil
 	}
 
 	result := &AdminUserImportResult{
 		Errors: []string{},
+		DryRun: dryRun,
 	}
 
-	// Simple CSV parsing: expected format "email,password" (minimal) or more fields
-	// Let's assume a simple format for import: email,password
-	// Skip header if present (heuristic: check if first line contains "email")
+	// 首行若能识别出列名则按表头映射，否则沿用 email,password 位置格式
+	columns := positionalImportColumns()
 	startIndex := 0
-	if len(lines) > 0 && strings.Contains(strings.ToLower(lines[0]), "email") {
-		startIndex = 1
+	if header, err := parseCSVLine(strings.TrimSpace(lines[0])); err == nil {
+		if mapped, ok := detectImportColumns(header); ok {
+			if mapped.email < 0 || mapped.password < 0 {
+				return nil, fmt.Errorf("%w: header must contain email and password columns / 表头必须包含 email 和 password 列", ErrBadRequest)
+			}
+			columns = mapped
+			startIndex = 1
+		} else if strings.Contains(strings.ToLower(lines[0]), "email") {
+			startIndex = 1
+		}
 	}
 
 	now := time.Now().Unix()
+	plans := make(map[int64]*repository.Plan)
+	importedGroups := make([]int64, 0)
+	// 记录文件内已出现的邮箱，重复行直接判失败
+	seenEmails := make(map[string]int)
 
 	for i := startIndex; i < len(lines); i++ {
 		line := strings.TrimSpace(lines[i])
@@ -508,33 +701,32 @@ func (s *adminUserService) Import(ctx context.Context, data []byte) (*AdminUserI
 			result.Errors = append(result.Errors, fmt.Sprintf("Line %d: %v", i+1, err))
 			continue
 		}
-		if len(record) < 2 {
+		row, err := s.parseImportRow(ctx, record, columns, plans)
+		if err != nil {
 			result.FailureCount++
-			result.Errors = append(result.Errors, fmt.Sprintf("Line %d: invalid format (expected email,password,...)", i+1))
+			result.Errors = append(result.Errors, fmt.Sprintf("Line %d: %v", i+1, err))
 			continue
 		}
 
-		email := normalizeEmail(record[0])
-		if email == "" {
-			result.FailureCount++
-			result.Errors = append(result.Errors, fmt.Sprintf("Line %d: invalid email", i+1))
-			continue
-		}
-		password := strings.TrimSpace(record[1])
-		if len(password) < 8 || !hasLetterAndNumber(password) {
+		if firstLine, ok := seenEmails[row.email]; ok {
 			result.FailureCount++
-			result.Errors = append(result.Errors, fmt.Sprintf("Line %d: invalid password", i+1))
+			result.Errors = append(result.Errors, fmt.Sprintf("Line %d: email %s duplicates line %d", i+1, row.email, firstLine))
 			continue
 		}
+		seenEmails[row.email] = i + 1
 
 		// Check existence
-		if existing, err := s.users.FindByEmail(ctx, email); err == nil && existing != nil {
+		if existing, err := s.users.FindByEmail(ctx, row.email); err == nil && existing != nil {
 			result.FailureCount++
-			result.Errors = append(result.Errors, fmt.Sprintf("Line %d: email %s already exists", i+1, email))
+			result.Errors = append(result.Errors, fmt.Sprintf("Line %d: email %s already exists", i+1, row.email))
+			continue
+		}
+		if dryRun {
+			result.SuccessCount++
 			continue
 		}
 
-		hashed, err := s.hasher.Hash(password)
+		hashed, err := s.hasher.Hash(row.password)
 		if err != nil {
 			result.FailureCount++
 			result.Errors = append(result.Errors, fmt.Sprintf("Line %d: hashing error", i+1))
@@ -544,25 +736,178 @@ func (s *adminUserService) Import(ctx context.Context, data []byte) (*AdminUserI
 		user := &repository.User{
 			UUID:           makeUUID(),
 			Token:          makeUUID(),
-			Email:          email,
+			Email:          row.email,
 			Password:       hashed,
+			PlanID:         row.planID,
+			GroupID:        row.groupID,
+			ExpiredAt:      row.expiredAt,
 			Status:         1, // Active by default
 			CreatedAt:      now,
 			UpdatedAt:      now,
-			TransferEnable: 0,
+			TransferEnable: row.transferEnable,
 		}
-	
-		if _, err := s.users.Create(ctx, user); err != nil {
+
+		created, err := s.users.Create(ctx, user)
+		if err != nil {
 			result.FailureCount++
 			result.Errors = append(result.Errors, fmt.Sprintf("Line %d: db error: %v", i+1, err))
 		} else {
 			result.SuccessCount++
+			importedGroups = append(importedGroups, user.GroupID)
+			recordAudit(ctx, s.audit, AuditEntry{Action: AuditActionCreate, TargetType: AuditTargetUser, TargetID: created.ID, After: created})
 		}
 	}
 
+	s.notifyGroupsChanged(ctx, importedGroups...)
 	return result, nil
 }
 
+// adminUserImportColumns 记录各字段在 CSV 中的列下标，-1 表示该列不存在。
+type adminUserImportColumns struct {
+	email          int
+	password       int
+	planID         int
+	expiredAt      int
+	transferEnable int
+	groupID        int
+}
+
+// adminUserImportRow 是单行校验通过后的导入数据。
+type adminUserImportRow struct {
+	email          string
+	password       string
+	planID         int64
+	groupID        int64
+	expiredAt      int64
+	transferEnable int64
+}
+
+func positionalImportColumns() adminUserImportColumns {
+	return adminUserImportColumns{email: 0, password: 1, planID: -1, expiredAt: -1, transferEnable: -1, groupID: -1}
+}
+
+// detectImportColumns 根据表头映射列名，未知列忽略；没有任何可识别列时返回 false。
+func detectImportColumns(header []string) (adminUserImportColumns, bool) {
+	columns := adminUserImportColumns{email: -1, password: -1, planID: -1, expiredAt: -1, transferEnable: -1, groupID: -1}
+	recognized := false
+	for idx, name := range header {
+		var target *int
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "email":
+			target = &columns.email
+		case "password":
+			target = &columns.password
+		case "plan_id":
+			target = &columns.planID
+		case "expired_at":
+			target = &columns.expiredAt
+		case "transfer_enable":
+			target = &columns.transferEnable
+		case "group_id":
+			target = &columns.groupID
+		}
+		if target != nil && *target < 0 {
+			*target = idx
+			recognized = true
+		}
+	}
+	return columns, recognized
+}
+
+// parseImportRow 校验单行数据：套餐决定默认分组和流量，显式列优先覆盖。
+func (s *adminUserService) parseImportRow(ctx context.Context, record []string, columns adminUserImportColumns, plans map[int64]*repository.Plan) (*adminUserImportRow, error) {
+	if len(record) <= columns.email || len(record) <= columns.password {
+		return nil, fmt.Errorf("invalid format (expected email,password,...)")
+	}
+	row := &adminUserImportRow{
+		email:    normalizeEmail(record[columns.email]),
+		password: strings.TrimSpace(record[columns.password]),
+	}
+	if row.email == "" {
+		return nil, fmt.Errorf("invalid email")
+	}
+	if len(row.password) < 8 || !hasLetterAndNumber(row.password) {
+		return nil, fmt.Errorf("invalid password")
+	}
+
+	planID, err := importInt64Column(record, columns.planID, "plan_id")
+	if err != nil {
+		return nil, err
+	}
+	if planID != nil && *planID > 0 {
+		plan, err := s.importPlan(ctx, *planID, plans)
+		if err != nil {
+			return nil, err
+		}
+		row.planID = plan.ID
+		if plan.GroupID != nil {
+			row.groupID = *plan.GroupID
+		}
+		row.transferEnable = plan.TransferEnable
+	}
+	groupID, err := importInt64Column(record, columns.groupID, "group_id")
+	if err != nil {
+		return nil, err
+	}
+	if groupID != nil {
+		row.groupID = *groupID
+	}
+	transferEnable, err := importInt64Column(record, columns.transferEnable, "transfer_enable")
+	if err != nil {
+		return nil, err
+	}
+	if transferEnable != nil {
+		row.transferEnable = max64(*transferEnable, 0)
+	}
+	expiredAt, err := importInt64Column(record, columns.expiredAt, "expired_at")
+	if err != nil {
+		return nil, err
+	}
+	if expiredAt != nil {
+		row.expiredAt = max64(*expiredAt, 0)
+	}
+	return row, nil
+}
+
+// importPlan 查询并缓存套餐，避免同一批次重复查库。
+func (s *adminUserService) importPlan(ctx context.Context, planID int64, cache map[int64]*repository.Plan) (*repository.Plan, error) {
+	if plan, ok := cache[planID]; ok {
+		if plan == nil {
+			return nil, fmt.Errorf("plan %d not found", planID)
+		}
+		return plan, nil
+	}
+	if s.plans == nil {
+		return nil, fmt.Errorf("plan %d not found", planID)
+	}
+	plan, err := s.plans.FindByID(ctx, planID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	if plan == nil || err != nil {
+		cache[planID] = nil
+		return nil, fmt.Errorf("plan %d not found", planID)
+	}
+	cache[planID] = plan
+	return plan, nil
+}
+
+// importInt64Column 读取可选整数列，列不存在或为空时返回 nil。
+func importInt64Column(record []string, idx int, name string) (*int64, error) {
+	if idx < 0 || idx >= len(record) {
+		return nil, nil
+	}
+	raw := strings.TrimSpace(record[idx])
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q", name, raw)
+	}
+	return &value, nil
+}
+
 type adminUserViewMeta struct {
 	plan          *repository.Plan
 	group         *repository.ServerGroup
@@ -609,6 +954,7 @@ func (s *adminUserService) buildView(user *repository.User, meta adminUserViewMe
 		OnlineCount:       meta.onlineCount,
 		SubscribeURL:      buildSubscribeURL(meta.subscribeBase, user.Token),
 	}
+	view.SubscriptionTemplateID = user.SubscriptionTemplateID
 	if meta.plan != nil {
 		view.Plan = &AdminUserPlanSummary{ID: meta.plan.ID, Name: meta.plan.Name}
 	}
@@ -681,8 +1027,9 @@ func (s *adminUserService) groupByID(ctx context.Context, id int64) *repository.
 	return lookup[id]
 }
 
+// aliveCounts 合并 UniProxy 遥测与 Agent 在线设备两路数据，同一用户取较大值避免重复计数。
 func (s *adminUserService) aliveCounts(ctx context.Context, users []*repository.User) map[int64]int {
-	if s == nil || s.telemetry == nil {
+	if s == nil || (s.telemetry == nil && s.devices == nil) {
 		return map[int64]int{}
 	}
 	ids := make([]int64, 0, len(users))
@@ -700,9 +1047,22 @@ func (s *adminUserService) aliveCounts(ctx context.Context, users []*repository.
 	if len(ids) == 0 {
 		return map[int64]int{}
 	}
-	counts, err := s.telemetry.AliveCounts(ctx, ids)
-	if err != nil {
-		return map[int64]int{}
+	counts := map[int64]int{}
+	if s.telemetry != nil {
+		if telemetryCounts, err := s.telemetry.AliveCounts(ctx, ids); err == nil {
+			for id, count := range telemetryCounts {
+				counts[id] = count
+			}
+		}
+	}
+	if s.devices != nil {
+		if deviceCounts, err := s.devices.OnlineCounts(ctx, ids); err == nil {
+			for id, count := range deviceCounts {
+				if count > counts[id] {
+					counts[id] = count
+				}
+			}
+		}
 	}
 	return counts
 }
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+// 批量操作类型。
+const (
+	BulkActionBan               = "ban"
+	BulkActionUnban             = "unban"
+	BulkActionExtendExpiry      = "extend_expiry"
+	BulkActionSetTransferEnable = "set_transfer_enable"
+	BulkActionResetTraffic      = "reset_traffic"
+	BulkActionAssignPlan        = "assign_plan"
+)
+
+const (
+	// maxBulkUserIDs 限制单次请求的用户数量。
+	maxBulkUserIDs = 1000
+	// bulkUserBatchSize 是每个事务保存的用户数量。
+	bulkUserBatchSize = 200
+)
+
+// BulkAction 描述一次批量用户操作，仅读取与 Type 对应的参数字段。
+type BulkAction struct {
+	Type           string `json:"type"`
+	Days           int    `json:"days,omitempty"`
+	TransferEnable *int64 `json:"transfer_enable,omitempty"`
+	PlanID         int64  `json:"plan_id,omitempty"`
+}
+
+// AdminUserBulkItem 是单个用户的处理结果。
+type AdminUserBulkItem struct {
+	ID      int64  `json:"id"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// AdminUserBulkResult 汇总批量操作结果，部分失败时 Failed > 0。
+type AdminUserBulkResult struct {
+	Action    string              `json:"action"`
+	Total     int                 `json:"total"`
+	Succeeded int                 `json:"succeeded"`
+	Failed    int                 `json:"failed"`
+	Results   []AdminUserBulkItem `json:"results"`
+}
+
+// BulkUpdate 对多名用户执行同一操作。每批用户在一个事务内保存，整批失败时该批所有用户都记为失败；
+// 用户不存在等单条错误只影响对应用户。
+func (s *adminUserService) BulkUpdate(ctx context.Context, ids []int64, action BulkAction) (*AdminUserBulkResult, error) {
+	if s == nil || s.users == nil {
+		return nil, fmt.Errorf("admin user service not configured / 管理用户服务未配置")
+	}
+	ids = uniqueBulkIDs(ids)
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: ids are required / 需要用户 id", ErrBadRequest)
+	}
+	if len(ids) > maxBulkUserIDs {
+		return nil, fmt.Errorf("%w: at most %d users per request / 单次最多操作 %d 个用户", ErrBadRequest, maxBulkUserIDs, maxBulkUserIDs)
+	}
+	apply, err := s.bulkApplier(ctx, action)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AdminUserBulkResult{
+		Action:  action.Type,
+		Total:   len(ids),
+		Results: make([]AdminUserBulkItem, 0, len(ids)),
+	}
+	now := time.Now()
+	changedGroups := make([]int64, 0)
+	for start := 0; start < len(ids); start += bulkUserBatchSize {
+		end := start + bulkUserBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		pending := make([]*repository.User, 0, end-start)
+		snapshots := make(map[int64]repository.User, end-start)
+		for _, id := range ids[start:end] {
+			user, err := s.users.FindByID(ctx, id)
+			if err != nil {
+				if errors.Is(err, repository.ErrNotFound) {
+					err = ErrNotFound
+				}
+				result.Results = append(result.Results, AdminUserBulkItem{ID: id, Error: err.Error()})
+				continue
+			}
+			snapshots[user.ID] = *user
+			if err := apply(user, now); err != nil {
+				result.Results = append(result.Results, AdminUserBulkItem{ID: id, Error: err.Error()})
+				continue
+			}
+			user.UpdatedAt = now.Unix()
+			pending = append(pending, user)
+		}
+		saveErr := s.users.SaveBatch(ctx, pending)
+		if saveErr == nil {
+			for _, user := range pending {
+				changedGroups = append(changedGroups, user.GroupID)
+				before := snapshots[user.ID]
+				recordAudit(ctx, s.audit, AuditEntry{Action: AuditActionUpdate, TargetType: AuditTargetUser, TargetID: user.ID, Before: &before, After: user})
+			}
+		}
+		for _, user := range pending {
+			item := AdminUserBulkItem{ID: user.ID, Success: saveErr == nil}
+			if saveErr != nil {
+				item.Error = saveErr.Error()
+			}
+			result.Results = append(result.Results, item)
+		}
+	}
+	for _, item := range result.Results {
+		if item.Success {
+			result.Succeeded++
+		} else {
+			result.Failed++
+		}
+	}
+	s.notifyGroupsChanged(ctx, changedGroups...)
+	return result, nil
+}
+
+// bulkApplier 校验操作参数并返回对单个用户的修改函数。
+func (s *adminUserService) bulkApplier(ctx context.Context, action BulkAction) (func(user *repository.User, now time.Time) error, error) {
+	switch action.Type {
+	case BulkActionBan, BulkActionUnban:
+		banned := action.Type == BulkActionBan
+		return func(user *repository.User, _ time.Time) error {
+			user.Banned = banned
+			return nil
+		}, nil
+	case BulkActionExtendExpiry:
+		if action.Days <= 0 {
+			return nil, fmt.Errorf("%w: days must be positive / 天数必须大于 0", ErrBadRequest)
+		}
+		return func(user *repository.User, now time.Time) error {
+			if user.ExpiredAt <= 0 {
+				return fmt.Errorf("user never expires / 用户永不过期")
+			}
+			// 已过期的用户从当前时间开始顺延
+			base := time.Unix(user.ExpiredAt, 0)
+			if base.Before(now) {
+				base = now
+			}
+			user.ExpiredAt = base.AddDate(0, 0, action.Days).Unix()
+			return nil
+		}, nil
+	case BulkActionSetTransferEnable:
+		if action.TransferEnable == nil || *action.TransferEnable < 0 {
+			return nil, fmt.Errorf("%w: transfer_enable must be non-negative / 流量必须为非负数", ErrBadRequest)
+		}
+		transferEnable := *action.TransferEnable
+		return func(user *repository.User, _ time.Time) error {
+			user.TransferEnable = transferEnable
+			return nil
+		}, nil
+	case BulkActionResetTraffic:
+		return func(user *repository.User, _ time.Time) error {
+			user.U = 0
+			user.D = 0
+			user.TrafficExceeded = false
+			return nil
+		}, nil
+	case BulkActionAssignPlan:
+		if action.PlanID <= 0 || s.plans == nil {
+			return nil, fmt.Errorf("%w: plan_id is required / 需要套餐 id", ErrBadRequest)
+		}
+		plan, err := s.plans.FindByID(ctx, action.PlanID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return nil, ErrNotFound
+			}
+			return nil, err
+		}
+		// 与单个更新一致：分组与流量随套餐变化
+		return func(user *repository.User, _ time.Time) error {
+			user.PlanID = plan.ID
+			user.GroupID = 0
+			if plan.GroupID != nil {
+				user.GroupID = *plan.GroupID
+			}
+			user.TransferEnable = plan.TransferEnable
+			return nil
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported bulk action %q / 不支持的批量操作", ErrBadRequest, action.Type)
+	}
+}
+
+func uniqueBulkIDs(ids []int64) []int64 {
+	seen := make(map[int64]struct{}, len(ids))
+	out := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if id <= 0 {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+	return out
+}
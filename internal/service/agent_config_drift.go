@@ -0,0 +1,52 @@
+// 文件路径: internal/service/agent_config_drift.go
+// 模块说明: 这是 internal 模块里的 agent_config_drift 逻辑，下面的注释会用非常通俗的中文帮你理解每一步。
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+	"github.com/creamcroissant/xboard/internal/support/hash"
+)
+
+// syncedConfigFilename 是 Agent 写入面板下发配置的文件名，漂移检测只比对这一份文件。
+const syncedConfigFilename = "config.json"
+
+// detectConfigDrift 比较 Agent 上报的配置哈希与按模板生成的期望哈希，不一致时标记漂移。
+// 维护模式、未分配模板或本次上报不含下发文件时不做判断，保留原有标记。
+func (s *agentHostService) detectConfigDrift(ctx context.Context, host *repository.AgentHost, protocols []ProtocolInfo) {
+	if host == nil || host.Maintenance || host.TemplateID == 0 {
+		return
+	}
+	reported := ""
+	for _, p := range protocols {
+		if p.Name == syncedConfigFilename {
+			reported = p.ContentHash
+			break
+		}
+	}
+	if reported == "" {
+		return
+	}
+
+	configJSON, err := s.GenerateConfig(ctx, host.ID)
+	if err != nil || configJSON == nil {
+		// 模板本身有问题时由配置下发流程上报，这里不把它误判为漂移
+		slog.Debug("skip config drift check", "agent_host_id", host.ID, "error", err)
+		return
+	}
+
+	drift := hash.ConfigContentHash(configJSON) != reported
+	if drift == host.DriftDetected {
+		return
+	}
+	if err := s.agentHosts.UpdateDriftDetected(ctx, host.ID, drift); err != nil {
+		slog.Error("failed to update config drift flag", "agent_host_id", host.ID, "error", err)
+		return
+	}
+	host.DriftDetected = drift
+	if drift {
+		slog.Warn("config drift detected", "agent_host_id", host.ID, "reported_hash", reported)
+	}
+}
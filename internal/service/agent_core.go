@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"regexp"
 	"strings"
 
 	"github.com/creamcroissant/xboard/internal/grpc/client"
@@ -28,37 +29,43 @@ const (
 type AgentCoreService interface {
 	GetCores(ctx context.Context, agentHostID int64) ([]*agentv1.CoreInfo, error)
 	GetInstances(ctx context.Context, agentHostID int64) ([]*repository.AgentCoreInstance, error)
+	GetCoreTopology(ctx context.Context, agentHostID int64) (*CoreTopology, error)
 	CreateInstance(ctx context.Context, req CreateInstanceRequest) (*repository.CoreOperation, error)
 	DeleteInstance(ctx context.Context, agentHostID int64, instanceID string) error
 	SwitchCore(ctx context.Context, req SwitchCoreRequest) (*repository.CoreOperation, error)
 	InstallCore(ctx context.Context, req InstallCoreRequest) (*repository.CoreOperation, error)
+	UpgradeCore(ctx context.Context, req UpgradeCoreRequest) (*repository.CoreOperation, error)
 	GetSwitchLogs(ctx context.Context, filter SwitchLogFilter) ([]*repository.AgentCoreSwitchLog, int64, error)
 	ConvertConfig(ctx context.Context, req ConvertRequest) (*ConvertResult, error)
 	ListOperations(ctx context.Context, req ListCoreOperationsRequest) ([]*repository.CoreOperation, int64, error)
 	GetOperation(ctx context.Context, operationID string) (*repository.CoreOperation, error)
+	// GetSwitchStatus 返回一次核心切换的当前进度，switchID 为 SwitchCore 返回的任务 ID。
+	GetSwitchStatus(ctx context.Context, agentHostID int64, switchID string) (*CoreSwitchStatus, error)
 }
 
 // CreateInstanceRequest 定义创建核心实例的请求参数。
 type CreateInstanceRequest struct {
-	AgentHostID      int64
-	CoreType         string
-	InstanceID       string
-	ConfigTemplateID int64
-	ConfigJSON       json.RawMessage
-	OperatorID       *int64
+	AgentHostID           int64
+	CoreType              string
+	InstanceID            string
+	ConfigTemplateID      int64
+	ConfigTemplateVersion int64
+	ConfigJSON            json.RawMessage
+	OperatorID            *int64
 }
 
 // SwitchCoreRequest 定义核心切换请求参数。
 type SwitchCoreRequest struct {
-	AgentHostID      int64
-	FromInstanceID   string
-	ToCoreType       string
-	ConfigTemplateID int64
-	ConfigJSON       json.RawMessage
-	SwitchID         string
-	ListenPorts      []int
-	ZeroDowntime     *bool
-	OperatorID       *int64
+	AgentHostID           int64
+	FromInstanceID        string
+	ToCoreType            string
+	ConfigTemplateID      int64
+	ConfigTemplateVersion int64
+	ConfigJSON            json.RawMessage
+	SwitchID              string
+	ListenPorts           []int
+	ZeroDowntime          *bool
+	OperatorID            *int64
 }
 
 // InstallCoreRequest 定义核心安装/升级请求参数。
@@ -74,6 +81,18 @@ type InstallCoreRequest struct {
 	OperatorID  *int64
 }
 
+// UpgradeCoreRequest 定义核心二进制升级请求参数。
+type UpgradeCoreRequest struct {
+	AgentHostID int64
+	CoreType    string
+	Version     string
+	OperatorID  *int64
+}
+
+// coreUpgradeVersionPattern 限定升级版本为 semver 形式（可带 v 前缀与预发布/构建标识），
+// 版本号会被 Agent 拼入下载与校验和 URL，不能包含路径或查询字符。
+var coreUpgradeVersionPattern = regexp.MustCompile(`^v?[0-9]+\.[0-9]+\.[0-9]+(-[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*)?(\+[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*)?$`)
+
 // SwitchResult 返回切换结果与日志信息。
 type SwitchResult struct {
 	Success        bool   `json:"success"`
@@ -122,21 +141,38 @@ type ConvertResult struct {
 	Warnings   []string        `json:"warnings,omitempty"`
 }
 
+// CoreSwitchStatus 描述核心切换的当前进度，Status 依次为 pending→in_progress→completed/failed。
+type CoreSwitchStatus struct {
+	SwitchID    string `json:"switch_id"`
+	AgentHostID int64  `json:"agent_host_id"`
+	ToCoreType  string `json:"to_core_type"`
+	Status      string `json:"status"`
+	Detail      string `json:"detail,omitempty"`
+	UpdatedAt   int64  `json:"updated_at"`
+	CompletedAt *int64 `json:"completed_at,omitempty"`
+}
+
+// Terminal 判断切换是否已结束。
+func (s *CoreSwitchStatus) Terminal() bool {
+	return s != nil && (s.Status == switchStatusCompleted || s.Status == switchStatusFailed)
+}
+
 // agentCoreService 组合核心管理相关依赖与配置。
 type agentCoreService struct {
-	agentHosts     repository.AgentHostRepository
-	instances      repository.AgentCoreInstanceRepository
-	switchLogs     repository.AgentCoreSwitchLogRepository
-	templates      repository.ConfigTemplateRepository
-	converters     *template.ConverterRegistry
-	logger         *slog.Logger
-	grpcTLS        *client.TLSConfig
-	grpcTimeout    client.TimeoutConfig
-	grpcKeepalive  *client.KeepaliveConfig
-	grpcPort       string
-	grpcClientFunc func(cfg client.Config) (*client.AgentClient, error)
-	operations     CoreOperationService
-	snapshots      CoreSnapshotService
+	agentHosts       repository.AgentHostRepository
+	instances        repository.AgentCoreInstanceRepository
+	switchLogs       repository.AgentCoreSwitchLogRepository
+	templates        repository.ConfigTemplateRepository
+	templateVersions repository.ConfigTemplateVersionRepository
+	converters       *template.ConverterRegistry
+	logger           *slog.Logger
+	grpcTLS          *client.TLSConfig
+	grpcTimeout      client.TimeoutConfig
+	grpcKeepalive    *client.KeepaliveConfig
+	grpcPort         string
+	grpcClientFunc   func(cfg client.Config) (*client.AgentClient, error)
+	operations       CoreOperationService
+	snapshots        CoreSnapshotService
 }
 
 // NewAgentCoreService 组装核心管理服务。
@@ -160,6 +196,8 @@ type AgentCoreServiceOptions struct {
 	ClientFactory  func(cfg client.Config) (*client.AgentClient, error)
 	Operations     repository.CoreOperationRepository
 	OperationGuard AgentOperationGuard
+	// TemplateVersions 用于按指定版本解析配置模板，为空时只能使用模板最新内容。
+	TemplateVersions repository.ConfigTemplateVersionRepository
 }
 
 // NewAgentCoreServiceWithOptions 构造可定制的核心管理服务。
@@ -184,19 +222,20 @@ func NewAgentCoreServiceWithOptions(
 		factory = client.NewAgentClient
 	}
 	return &agentCoreService{
-		agentHosts:     agentHosts,
-		instances:      instances,
-		switchLogs:     switchLogs,
-		templates:      templates,
-		converters:     converters,
-		logger:         logger,
-		grpcTLS:        opts.GRPCTLS,
-		grpcTimeout:    opts.Timeout,
-		grpcKeepalive:  opts.Keepalive,
-		grpcPort:       grpcPort,
-		grpcClientFunc: factory,
-		operations:     NewCoreOperationService(opts.Operations, opts.OperationGuard),
-		snapshots:      NewCoreSnapshotService(agentHosts, instances),
+		agentHosts:       agentHosts,
+		instances:        instances,
+		switchLogs:       switchLogs,
+		templates:        templates,
+		templateVersions: opts.TemplateVersions,
+		converters:       converters,
+		logger:           logger,
+		grpcTLS:          opts.GRPCTLS,
+		grpcTimeout:      opts.Timeout,
+		grpcKeepalive:    opts.Keepalive,
+		grpcPort:         grpcPort,
+		grpcClientFunc:   factory,
+		operations:       NewCoreOperationService(opts.Operations, opts.OperationGuard),
+		snapshots:        NewCoreSnapshotService(agentHosts, instances),
 	}
 }
 
@@ -249,7 +288,7 @@ func (s *agentCoreService) CreateInstance(ctx context.Context, req CreateInstanc
 	if req.AgentHostID == 0 || strings.TrimSpace(req.CoreType) == "" || strings.TrimSpace(req.InstanceID) == "" {
 		return nil, ErrBadRequest
 	}
-	configJSON, _, _, err := s.resolveConfigJSON(ctx, req.ConfigTemplateID, req.ConfigJSON)
+	configJSON, _, _, err := s.resolveConfigJSON(ctx, req.ConfigTemplateID, req.ConfigTemplateVersion, req.ConfigJSON)
 	if err != nil {
 		return nil, err
 	}
@@ -281,7 +320,7 @@ func (s *agentCoreService) SwitchCore(ctx context.Context, req SwitchCoreRequest
 	if req.AgentHostID == 0 || strings.TrimSpace(req.FromInstanceID) == "" || strings.TrimSpace(req.ToCoreType) == "" {
 		return nil, ErrBadRequest
 	}
-	configJSON, _, _, err := s.resolveConfigJSON(ctx, req.ConfigTemplateID, req.ConfigJSON)
+	configJSON, _, _, err := s.resolveConfigJSON(ctx, req.ConfigTemplateID, req.ConfigTemplateVersion, req.ConfigJSON)
 	if err != nil {
 		return nil, err
 	}
@@ -303,6 +342,27 @@ func (s *agentCoreService) InstallCore(ctx context.Context, req InstallCoreReque
 	return s.operations.Create(ctx, CreateCoreOperationRequest{AgentHostID: req.AgentHostID, OperationType: coreOperationTypeInstall, CoreType: strings.TrimSpace(req.CoreType), RequestPayload: payload, OperatorID: req.OperatorID})
 }
 
+// UpgradeCore 下发核心二进制升级任务：Agent 从其配置的发布地址下载指定版本，校验 SHA256 与当前配置后以排空方式切换，
+// 校验或切换后健康检查失败时保留旧二进制。
+func (s *agentCoreService) UpgradeCore(ctx context.Context, req UpgradeCoreRequest) (*repository.CoreOperation, error) {
+	coreType := strings.ToLower(strings.TrimSpace(req.CoreType))
+	version := strings.TrimSpace(req.Version)
+	if req.AgentHostID == 0 || version == "" {
+		return nil, ErrBadRequest
+	}
+	if len(version) > 64 || !coreUpgradeVersionPattern.MatchString(version) {
+		return nil, fmt.Errorf("invalid core version %q / 核心版本格式无效: %w", version, ErrBadRequest)
+	}
+	if coreType != "sing-box" && coreType != "xray" {
+		return nil, fmt.Errorf("unsupported core type %q / 不支持升级的核心类型: %w", coreType, ErrBadRequest)
+	}
+	payload, err := json.Marshal(&agentv1.UpgradeCorePayload{Version: version})
+	if err != nil {
+		return nil, err
+	}
+	return s.operations.Create(ctx, CreateCoreOperationRequest{AgentHostID: req.AgentHostID, OperationType: coreOperationTypeUpgrade, CoreType: coreType, RequestPayload: payload, OperatorID: req.OperatorID})
+}
+
 func (s *agentCoreService) ListOperations(ctx context.Context, req ListCoreOperationsRequest) ([]*repository.CoreOperation, int64, error) {
 	return s.operations.List(ctx, req)
 }
@@ -311,6 +371,45 @@ func (s *agentCoreService) GetOperation(ctx context.Context, operationID string)
 	return s.operations.Get(ctx, operationID)
 }
 
+func (s *agentCoreService) GetSwitchStatus(ctx context.Context, agentHostID int64, switchID string) (*CoreSwitchStatus, error) {
+	if agentHostID <= 0 || strings.TrimSpace(switchID) == "" {
+		return nil, ErrBadRequest
+	}
+	op, err := s.operations.Get(ctx, switchID)
+	if err != nil {
+		if errors.Is(err, ErrCoreOperationNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if op.AgentHostID != agentHostID || op.OperationType != coreOperationTypeSwitch {
+		return nil, ErrNotFound
+	}
+	return &CoreSwitchStatus{
+		SwitchID:    op.ID,
+		AgentHostID: op.AgentHostID,
+		ToCoreType:  op.CoreType,
+		Status:      switchStatusFromOperation(op.Status),
+		Detail:      strings.TrimSpace(op.ErrorMessage),
+		UpdatedAt:   op.UpdatedAt,
+		CompletedAt: op.FinishedAt,
+	}, nil
+}
+
+// switchStatusFromOperation 把 core 任务状态折叠为切换日志使用的四种状态：Agent 领取即视为进行中，回滚视为失败。
+func switchStatusFromOperation(status string) string {
+	switch strings.TrimSpace(status) {
+	case coreOperationStatusClaimed, coreOperationStatusInProgress:
+		return switchStatusRunning
+	case coreOperationStatusCompleted:
+		return switchStatusCompleted
+	case coreOperationStatusFailed, coreOperationStatusRolledBack:
+		return switchStatusFailed
+	default:
+		return switchStatusPending
+	}
+}
+
 func (s *agentCoreService) GetSwitchLogs(ctx context.Context, filter SwitchLogFilter) ([]*repository.AgentCoreSwitchLog, int64, error) {
 	if filter.AgentHostID == 0 {
 		return nil, 0, fmt.Errorf("agent host id required / 需要节点 ID")
@@ -401,11 +500,11 @@ func (s *agentCoreService) ConvertConfig(ctx context.Context, req ConvertRequest
 	if err != nil {
 		return nil, err
 	}
-	converted, err := s.converters.Convert(inbounds, req.TargetCore)
+	converted, warnings, err := s.converters.ConvertWithWarnings(inbounds, req.TargetCore)
 	if err != nil {
 		return nil, err
 	}
-	return &ConvertResult{ConfigJSON: converted}, nil
+	return &ConvertResult{ConfigJSON: converted, Warnings: warnings}, nil
 }
 
 func normalizeRawConfigJSON(payload []byte) ([]byte, error) {
@@ -427,7 +526,7 @@ func normalizeRawConfigJSON(payload []byte) ([]byte, error) {
 	return normalized, nil
 }
 
-func (s *agentCoreService) resolveConfigJSON(ctx context.Context, templateID int64, configJSON json.RawMessage) ([]byte, string, *int64, error) {
+func (s *agentCoreService) resolveConfigJSON(ctx context.Context, templateID, templateVersion int64, configJSON json.RawMessage) ([]byte, string, *int64, error) {
 	var payload []byte
 	var tplID *int64
 	if len(configJSON) > 0 {
@@ -445,6 +544,19 @@ func (s *agentCoreService) resolveConfigJSON(ctx context.Context, templateID int
 		}
 		payload = []byte(tpl.Content)
 		tplID = &tpl.ID
+		if templateVersion > 0 {
+			if s.templateVersions == nil {
+				return nil, "", nil, fmt.Errorf("config template version repository unavailable / 配置模板版本仓库不可用")
+			}
+			version, err := s.templateVersions.FindByTemplateAndVersion(ctx, templateID, templateVersion)
+			if err != nil {
+				if errors.Is(err, repository.ErrNotFound) {
+					return nil, "", nil, ErrNotFound
+				}
+				return nil, "", nil, err
+			}
+			payload = []byte(version.Content)
+		}
 	} else {
 		return nil, "", nil, fmt.Errorf("config json required / 需要配置 JSON")
 	}
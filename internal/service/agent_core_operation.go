@@ -7,9 +7,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"strings"
 	"time"
 
+	"github.com/creamcroissant/xboard/internal/async"
 	"github.com/creamcroissant/xboard/internal/repository"
 	agentv1 "github.com/creamcroissant/xboard/pkg/pb/agent/v1"
 )
@@ -26,6 +28,7 @@ const (
 	coreOperationTypeSwitch  = "switch"
 	coreOperationTypeInstall = "install"
 	coreOperationTypeEnsure  = "ensure"
+	coreOperationTypeUpgrade = "upgrade"
 
 	coreOperationStatusPending    = "pending"
 	coreOperationStatusClaimed    = "claimed"
@@ -43,6 +46,8 @@ type CoreOperationService interface {
 	Get(ctx context.Context, operationID string) (*repository.CoreOperation, error)
 	ClaimNext(ctx context.Context, req ClaimCoreOperationRequest) (*repository.CoreOperation, error)
 	ReportResult(ctx context.Context, req ReportCoreOperationResultRequest) error
+	// SetDashboardEvents 注入实时面板事件中心，核心切换结束时推送结果。
+	SetDashboardEvents(hub *DashboardHub)
 }
 
 type CreateCoreOperationRequest struct {
@@ -82,6 +87,7 @@ type ReportCoreOperationResultRequest struct {
 type coreOperationService struct {
 	operations repository.CoreOperationRepository
 	guard      AgentOperationGuard
+	dashboard  *DashboardHub
 }
 
 func NewCoreOperationService(operations repository.CoreOperationRepository, guards ...AgentOperationGuard) CoreOperationService {
@@ -92,6 +98,10 @@ func NewCoreOperationService(operations repository.CoreOperationRepository, guar
 	return &coreOperationService{operations: operations, guard: guard}
 }
 
+func (s *coreOperationService) SetDashboardEvents(hub *DashboardHub) {
+	s.dashboard = hub
+}
+
 func (s *coreOperationService) Create(ctx context.Context, req CreateCoreOperationRequest) (*repository.CoreOperation, error) {
 	if s == nil || s.operations == nil {
 		return nil, ErrCoreOperationNotConfigured
@@ -242,6 +252,20 @@ func (s *coreOperationService) ReportResult(ctx context.Context, req ReportCoreO
 		}
 		return err
 	}
+	if op.OperationType == coreOperationTypeSwitch {
+		s.dashboard.Publish(DashboardEvent{
+			Type:        DashboardEventCoreSwitch,
+			AgentHostID: op.AgentHostID,
+			Timestamp:   finishedAt,
+			Data: DashboardCoreSwitchEvent{
+				OperationID:  op.ID,
+				CoreType:     op.CoreType,
+				Status:       statusValue,
+				ErrorMessage: strings.TrimSpace(req.ErrorMessage),
+				FinishedAt:   finishedAt,
+			},
+		})
+	}
 	return nil
 }
 
@@ -268,11 +292,16 @@ func canTransitionCoreOperationStatus(current, next string) bool {
 type CoreSnapshotService interface {
 	BuildCoreSnapshots(ctx context.Context, agentHostID int64, instances []*repository.AgentCoreInstance) ([]*repository.CoreStatusSnapshot, error)
 	ReplaceInstanceSnapshot(ctx context.Context, agentHostID int64, instances []*agentv1.CoreInstance, snapshots []*repository.CoreStatusSnapshot) error
+	// SetRestartAlerts 注入重启统计配置与频繁重启告警通道，未注入时使用默认窗口且不发送告警。
+	SetRestartAlerts(settings repository.SettingRepository, notifications *async.NotificationQueue, logger *slog.Logger)
 }
 
 type coreSnapshotService struct {
-	agentHosts repository.AgentHostRepository
-	instances  repository.AgentCoreInstanceRepository
+	agentHosts    repository.AgentHostRepository
+	instances     repository.AgentCoreInstanceRepository
+	settings      repository.SettingRepository
+	notifications *async.NotificationQueue
+	logger        *slog.Logger
 }
 
 func NewCoreSnapshotService(agentHosts repository.AgentHostRepository, instances repository.AgentCoreInstanceRepository) CoreSnapshotService {
@@ -314,6 +343,8 @@ func (s *coreSnapshotService) ReplaceInstanceSnapshot(ctx context.Context, agent
 	if s == nil || s.instances == nil {
 		return ErrCoreOperationNotConfigured
 	}
+	restartPolicy := s.coreRestartPolicy(ctx)
+	now := time.Now().Unix()
 	mapped := make([]*repository.AgentCoreInstance, 0, len(instances))
 	snapshotByType := make(map[string]*repository.CoreStatusSnapshot, len(snapshots))
 	for _, snapshot := range snapshots {
@@ -332,6 +363,7 @@ func (s *coreSnapshotService) ReplaceInstanceSnapshot(ctx context.Context, agent
 			listenPorts = append(listenPorts, int(port))
 		}
 		configHash := strings.TrimSpace(inst.GetConfigHash())
+		restarts := summarizeCoreRestarts(inst.GetRestartTimes(), now, restartPolicy)
 		mapped = append(mapped, &repository.AgentCoreInstance{
 			AgentHostID:     agentHostID,
 			InstanceID:      strings.TrimSpace(inst.GetId()),
@@ -343,9 +375,24 @@ func (s *coreSnapshotService) ReplaceInstanceSnapshot(ctx context.Context, agent
 			LastHeartbeatAt: unixNowPtr(),
 			ErrorMessage:    strings.TrimSpace(inst.GetError()),
 			CoreSnapshot:    snapshotByType[strings.TrimSpace(inst.GetCoreType())],
+
+			CPUPercent:        inst.GetCpuPercent(),
+			MemoryRSSBytes:    int64(inst.GetMemoryRssBytes()),
+			ResourceSampledAt: inst.GetResourceSampledAt(),
+
+			RestartCount:         restarts.count,
+			RestartWindowSeconds: restartPolicy.windowSeconds,
+			LastRestartAt:        restarts.lastAt,
+			UptimeSeconds:        inst.GetUptimeSeconds(),
+			Flapping:             restarts.flapping,
 		})
 	}
-	return s.instances.ReplaceSnapshot(ctx, agentHostID, mapped)
+	previous := s.previousFlapping(ctx, agentHostID)
+	if err := s.instances.ReplaceSnapshot(ctx, agentHostID, mapped); err != nil {
+		return err
+	}
+	s.notifyCoreFlapping(ctx, agentHostID, mapped, previous, restartPolicy)
+	return nil
 }
 
 func generateCoreOperationID(agentHostID int64) (string, error) {
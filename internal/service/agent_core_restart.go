@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/creamcroissant/xboard/internal/async"
+	"github.com/creamcroissant/xboard/internal/notifier"
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+// Core restart settings. The agent reports restart timestamps; the panel decides which
+// window to count them in. A flapping threshold of 0 disables the flapping alert.
+const (
+	coreRestartWindowSetting     = "core_restart_window_seconds"
+	coreFlappingWindowSetting    = "core_flapping_window_seconds"
+	coreFlappingThresholdSetting = "core_flapping_threshold"
+
+	defaultCoreRestartWindowSeconds  = 24 * 60 * 60
+	defaultCoreFlappingWindowSeconds = 15 * 60
+	defaultCoreFlappingThreshold     = 3
+)
+
+// coreRestartPolicy is the restart counting and flapping configuration read once per report.
+type coreRestartPolicy struct {
+	windowSeconds         int64
+	flappingWindowSeconds int64
+	flappingThreshold     int
+}
+
+// coreRestartSummary is the panel-side view of the restart timestamps of one instance.
+type coreRestartSummary struct {
+	count    int
+	lastAt   int64
+	flapping bool
+}
+
+// SetRestartAlerts wires the settings used for restart counting and the queue used for
+// flapping alerts.
+func (s *coreSnapshotService) SetRestartAlerts(settings repository.SettingRepository, notifications *async.NotificationQueue, logger *slog.Logger) {
+	if s == nil {
+		return
+	}
+	s.settings = settings
+	s.notifications = notifications
+	s.logger = logger
+}
+
+// summarizeCoreRestarts counts the restarts within the configured window and marks the
+// instance as flapping when the restarts within the flapping window reach the threshold.
+func summarizeCoreRestarts(restartTimes []int64, now int64, policy coreRestartPolicy) coreRestartSummary {
+	var summary coreRestartSummary
+	flappingCount := 0
+	for _, at := range restartTimes {
+		if at <= 0 || at > now {
+			continue
+		}
+		if at > summary.lastAt {
+			summary.lastAt = at
+		}
+		if now-at <= policy.windowSeconds {
+			summary.count++
+		}
+		if now-at <= policy.flappingWindowSeconds {
+			flappingCount++
+		}
+	}
+	summary.flapping = policy.flappingThreshold > 0 && flappingCount >= policy.flappingThreshold
+	return summary
+}
+
+func (s *coreSnapshotService) coreRestartPolicy(ctx context.Context) coreRestartPolicy {
+	policy := coreRestartPolicy{
+		windowSeconds:         int64(s.coreRestartIntSetting(ctx, coreRestartWindowSetting, defaultCoreRestartWindowSeconds)),
+		flappingWindowSeconds: int64(s.coreRestartIntSetting(ctx, coreFlappingWindowSetting, defaultCoreFlappingWindowSeconds)),
+		flappingThreshold:     s.coreRestartIntSetting(ctx, coreFlappingThresholdSetting, defaultCoreFlappingThreshold),
+	}
+	// A zero-length window would never count anything, so fall back to the defaults.
+	if policy.windowSeconds == 0 {
+		policy.windowSeconds = defaultCoreRestartWindowSeconds
+	}
+	if policy.flappingWindowSeconds == 0 {
+		policy.flappingWindowSeconds = defaultCoreFlappingWindowSeconds
+	}
+	return policy
+}
+
+// previousFlapping returns the instance ids that were already flapping before this
+// report, so the alert only fires when an instance starts flapping.
+func (s *coreSnapshotService) previousFlapping(ctx context.Context, agentHostID int64) map[string]bool {
+	flapping := make(map[string]bool)
+	existing, err := s.instances.ListByAgentHostID(ctx, agentHostID)
+	if err != nil {
+		return flapping
+	}
+	for _, instance := range existing {
+		if instance != nil && instance.Flapping {
+			flapping[instance.InstanceID] = true
+		}
+	}
+	return flapping
+}
+
+// notifyCoreFlapping sends a Telegram alert to the admin chat for each instance that
+// started flapping with this report. The alert is not repeated while it keeps flapping.
+func (s *coreSnapshotService) notifyCoreFlapping(ctx context.Context, agentHostID int64, instances []*repository.AgentCoreInstance, previous map[string]bool, policy coreRestartPolicy) {
+	var started []*repository.AgentCoreInstance
+	for _, instance := range instances {
+		if instance.Flapping && !previous[instance.InstanceID] {
+			started = append(started, instance)
+		}
+	}
+	if len(started) == 0 {
+		return
+	}
+
+	name := strconv.FormatInt(agentHostID, 10)
+	if s.agentHosts != nil {
+		if host, err := s.agentHosts.FindByID(ctx, agentHostID); err == nil && host != nil {
+			if host.Name != "" {
+				name = host.Name
+			} else if host.Host != "" {
+				name = host.Host
+			}
+		}
+	}
+	lines := make([]string, 0, len(started))
+	for _, instance := range started {
+		lines = append(lines, fmt.Sprintf("%s (%s): restarted %d times in last %s", instance.InstanceID, instance.CoreType, instance.RestartCount, formatCoreRestartWindow(instance.RestartWindowSeconds)))
+		if s.logger != nil {
+			s.logger.Warn("core instance flapping", "agent_host_id", agentHostID, "instance_id", instance.InstanceID, "restart_count", instance.RestartCount)
+		}
+	}
+	if s.notifications == nil || s.settings == nil {
+		return
+	}
+	adminChat := ""
+	if setting, err := s.settings.Get(ctx, telegramAdminIDSetting); err == nil && setting != nil {
+		adminChat = setting.Value
+	}
+	message := fmt.Sprintf("Core flapping alert\n\nNode: %s (ID: %d)\nRestarts within %s reached %d:\n%s", name, agentHostID, formatCoreRestartWindow(policy.flappingWindowSeconds), policy.flappingThreshold, strings.Join(lines, "\n"))
+	for _, chatID := range strings.Split(adminChat, ",") {
+		if chatID = strings.TrimSpace(chatID); chatID != "" {
+			s.notifications.EnqueueTelegram(notifier.TelegramRequest{ChatID: chatID, Message: message})
+		}
+	}
+}
+
+// formatCoreRestartWindow renders a window such as 86400 as "24h" and 900 as "15m".
+func formatCoreRestartWindow(seconds int64) string {
+	switch {
+	case seconds > 0 && seconds%3600 == 0:
+		return fmt.Sprintf("%dh", seconds/3600)
+	case seconds > 0 && seconds%60 == 0:
+		return fmt.Sprintf("%dm", seconds/60)
+	default:
+		return fmt.Sprintf("%ds", seconds)
+	}
+}
+
+func (s *coreSnapshotService) coreRestartIntSetting(ctx context.Context, key string, def int) int {
+	if s.settings == nil {
+		return def
+	}
+	setting, err := s.settings.Get(ctx, key)
+	if err != nil || setting == nil {
+		return def
+	}
+	value, err := strconv.Atoi(strings.TrimSpace(setting.Value))
+	if err != nil || value < 0 {
+		return def
+	}
+	return value
+}
@@ -0,0 +1,23 @@
+package service
+
+import "testing"
+
+func TestSummarizeCoreRestarts(t *testing.T) {
+	now := int64(100000)
+	policy := coreRestartPolicy{windowSeconds: 3600, flappingWindowSeconds: 300, flappingThreshold: 3}
+
+	summary := summarizeCoreRestarts([]int64{now - 7200, now - 1800, now - 200, now - 100}, now, policy)
+	if summary.count != 3 || summary.lastAt != now-100 || summary.flapping {
+		t.Fatalf("summary = %+v, want 3 restarts in window, not flapping", summary)
+	}
+
+	summary = summarizeCoreRestarts([]int64{now - 250, now - 120, now - 10, now + 60}, now, policy)
+	if summary.count != 3 || summary.lastAt != now-10 || !summary.flapping {
+		t.Fatalf("summary = %+v, want flapping with future timestamp ignored", summary)
+	}
+
+	policy.flappingThreshold = 0
+	if summary = summarizeCoreRestarts([]int64{now - 3, now - 2, now - 1}, now, policy); summary.flapping {
+		t.Fatal("threshold 0 must disable flapping detection")
+	}
+}
@@ -0,0 +1,23 @@
+package service
+
+import "testing"
+
+func TestSwitchStatusFromOperation(t *testing.T) {
+	cases := map[string]string{
+		coreOperationStatusPending:    switchStatusPending,
+		coreOperationStatusClaimed:    switchStatusRunning,
+		coreOperationStatusInProgress: switchStatusRunning,
+		coreOperationStatusCompleted:  switchStatusCompleted,
+		coreOperationStatusFailed:     switchStatusFailed,
+		coreOperationStatusRolledBack: switchStatusFailed,
+	}
+	for operationStatus, want := range cases {
+		if got := switchStatusFromOperation(operationStatus); got != want {
+			t.Fatalf("switchStatusFromOperation(%q) = %q, want %q", operationStatus, got, want)
+		}
+		status := &CoreSwitchStatus{Status: want}
+		if terminal := want == switchStatusCompleted || want == switchStatusFailed; status.Terminal() != terminal {
+			t.Fatalf("Terminal() for %q = %v, want %v", want, status.Terminal(), terminal)
+		}
+	}
+}
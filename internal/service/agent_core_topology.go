@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+// CoreTopologyInstance 描述主机上一个核心实例的运行状态、资源占用与端口冲突情况。
+type CoreTopologyInstance struct {
+	InstanceID      string                 `json:"instance_id"`
+	CoreType        string                 `json:"core_type"`
+	Status          string                 `json:"status"`
+	ListenPorts     []int                  `json:"listen_ports"`
+	ConfigHash      string                 `json:"config_hash"`
+	LastHeartbeatAt *int64                 `json:"last_heartbeat_at,omitempty"`
+	Resources       *CoreInstanceResources `json:"resources,omitempty"`
+	Restarts        CoreInstanceRestarts   `json:"restarts"`
+	PortConflict    bool                   `json:"port_conflict"`
+	ConflictPorts   []int                  `json:"conflict_ports,omitempty"`
+	ConflictsWith   []string               `json:"conflicts_with,omitempty"`
+}
+
+// CoreInstanceResources 是实例进程最近一次采样的资源占用，CPU 为单核百分比。
+type CoreInstanceResources struct {
+	CPUPercent     float64 `json:"cpu_percent"`
+	MemoryRSSBytes int64   `json:"memory_rss_bytes"`
+	SampledAt      int64   `json:"sampled_at"`
+}
+
+// CoreInstanceRestarts 是实例在统计窗口内的重启次数与当前运行时长，Summary 供面板直接展示。
+type CoreInstanceRestarts struct {
+	Count         int    `json:"count"`
+	WindowSeconds int64  `json:"window_seconds"`
+	LastRestartAt int64  `json:"last_restart_at,omitempty"`
+	UptimeSeconds int64  `json:"uptime_seconds"`
+	Flapping      bool   `json:"flapping"`
+	Summary       string `json:"summary"`
+}
+
+// CorePortConflict 描述被多个实例同时监听的端口。
+// CrossCore 为 true 表示冲突发生在不同核心之间（如 sing-box 与 xray）。
+type CorePortConflict struct {
+	Port        int      `json:"port"`
+	InstanceIDs []string `json:"instance_ids"`
+	CoreTypes   []string `json:"core_types"`
+	CrossCore   bool     `json:"cross_core"`
+}
+
+// CoreTopology 汇总主机上多核心并行运行的拓扑。
+type CoreTopology struct {
+	AgentHostID  int64                  `json:"agent_host_id"`
+	Instances    []CoreTopologyInstance `json:"instances"`
+	Conflicts    []CorePortConflict     `json:"conflicts"`
+	HasConflicts bool                   `json:"has_conflicts"`
+}
+
+// GetCoreTopology 返回主机上各核心实例的状态、监听端口与配置哈希，并标记端口冲突。
+// 已停止的实例同样参与检测，因为它们再次启动时会与占用同一端口的实例冲突。
+func (s *agentCoreService) GetCoreTopology(ctx context.Context, agentHostID int64) (*CoreTopology, error) {
+	instances, err := s.GetInstances(ctx, agentHostID)
+	if err != nil {
+		return nil, err
+	}
+
+	topology := &CoreTopology{
+		AgentHostID: agentHostID,
+		Instances:   make([]CoreTopologyInstance, 0, len(instances)),
+		Conflicts:   []CorePortConflict{},
+	}
+	owners := make(map[int][]int)
+	for _, inst := range instances {
+		if inst == nil {
+			continue
+		}
+		idx := len(topology.Instances)
+		ports := uniquePorts(inst.ListenPorts)
+		topology.Instances = append(topology.Instances, CoreTopologyInstance{
+			InstanceID:      inst.InstanceID,
+			CoreType:        inst.CoreType,
+			Status:          inst.Status,
+			ListenPorts:     ports,
+			ConfigHash:      inst.ConfigHash,
+			LastHeartbeatAt: inst.LastHeartbeatAt,
+			Resources:       coreInstanceResources(inst),
+			Restarts:        coreInstanceRestarts(inst),
+		})
+		for _, port := range ports {
+			owners[port] = append(owners[port], idx)
+		}
+	}
+
+	ports := make([]int, 0, len(owners))
+	for port, idxs := range owners {
+		if len(idxs) > 1 {
+			ports = append(ports, port)
+		}
+	}
+	sort.Ints(ports)
+
+	for _, port := range ports {
+		idxs := owners[port]
+		conflict := CorePortConflict{Port: port}
+		coreTypes := make(map[string]struct{})
+		for _, idx := range idxs {
+			item := &topology.Instances[idx]
+			conflict.InstanceIDs = append(conflict.InstanceIDs, item.InstanceID)
+			coreType := strings.ToLower(strings.TrimSpace(item.CoreType))
+			if _, ok := coreTypes[coreType]; !ok {
+				coreTypes[coreType] = struct{}{}
+				conflict.CoreTypes = append(conflict.CoreTypes, item.CoreType)
+			}
+			item.PortConflict = true
+			item.ConflictPorts = append(item.ConflictPorts, port)
+			for _, other := range idxs {
+				if other == idx {
+					continue
+				}
+				item.ConflictsWith = appendUniqueString(item.ConflictsWith, topology.Instances[other].InstanceID)
+			}
+		}
+		conflict.CrossCore = len(coreTypes) > 1
+		topology.Conflicts = append(topology.Conflicts, conflict)
+	}
+	topology.HasConflicts = len(topology.Conflicts) > 0
+	return topology, nil
+}
+
+// coreInstanceResources 仅对运行中且已采样到进程的实例返回资源占用，
+// 已停止实例残留的旧采样值不展示，避免误导。
+func coreInstanceResources(inst *repository.AgentCoreInstance) *CoreInstanceResources {
+	if inst.ResourceSampledAt <= 0 || inst.Status != "running" {
+		return nil
+	}
+	return &CoreInstanceResources{
+		CPUPercent:     inst.CPUPercent,
+		MemoryRSSBytes: inst.MemoryRSSBytes,
+		SampledAt:      inst.ResourceSampledAt,
+	}
+}
+
+// coreInstanceRestarts 汇总实例重启统计；已停止实例的运行时长记为 0。
+func coreInstanceRestarts(inst *repository.AgentCoreInstance) CoreInstanceRestarts {
+	restarts := CoreInstanceRestarts{
+		Count:         inst.RestartCount,
+		WindowSeconds: inst.RestartWindowSeconds,
+		LastRestartAt: inst.LastRestartAt,
+		Flapping:      inst.Flapping,
+	}
+	// 迁移前写入的实例没有窗口信息，按默认窗口展示
+	if restarts.WindowSeconds <= 0 {
+		restarts.WindowSeconds = defaultCoreRestartWindowSeconds
+	}
+	if inst.Status == "running" {
+		restarts.UptimeSeconds = inst.UptimeSeconds
+	}
+	restarts.Summary = fmt.Sprintf("restarted %d times in last %s", restarts.Count, formatCoreRestartWindow(restarts.WindowSeconds))
+	return restarts
+}
+
+func uniquePorts(ports []int) []int {
+	out := make([]int, 0, len(ports))
+	seen := make(map[int]struct{}, len(ports))
+	for _, port := range ports {
+		if port <= 0 {
+			continue
+		}
+		if _, ok := seen[port]; ok {
+			continue
+		}
+		seen[port] = struct{}{}
+		out = append(out, port)
+	}
+	sort.Ints(out)
+	return out
+}
+
+func appendUniqueString(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -0,0 +1,22 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestUpgradeCoreRejectsUnsafeVersions(t *testing.T) {
+	svc := &agentCoreService{}
+	for _, version := range []string{"latest", "1.10", "1.10.0/../../evil", "1.10.0?x=1", "1.10.0-..", "1.10.0%2f.."} {
+		_, err := svc.UpgradeCore(context.Background(), UpgradeCoreRequest{AgentHostID: 1, CoreType: "sing-box", Version: version})
+		if !errors.Is(err, ErrBadRequest) {
+			t.Fatalf("UpgradeCore(%q) err = %v, want ErrBadRequest", version, err)
+		}
+	}
+	for _, version := range []string{"1.10.0", "v1.8.24", "1.11.0-beta.3", "1.2.3+build.7"} {
+		if !coreUpgradeVersionPattern.MatchString(version) {
+			t.Fatalf("version %q should be accepted", version)
+		}
+	}
+}
@@ -7,11 +7,13 @@ import (
 	"crypto/subtle"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
 	"time"
 
+	"github.com/creamcroissant/xboard/internal/async"
 	"github.com/creamcroissant/xboard/internal/cache"
 	"github.com/creamcroissant/xboard/internal/repository"
 	"github.com/creamcroissant/xboard/internal/template"
@@ -27,6 +29,7 @@ type AgentHostService interface {
 	Update(ctx context.Context, id int64, req UpdateAgentHostRequest) error
 	Delete(ctx context.Context, id int64) error
 	List(ctx context.Context) ([]*repository.AgentHost, error)
+	Search(ctx context.Context, filter repository.AgentHostSearchFilter) ([]*repository.AgentHost, int64, error)
 
 	// Status updates from agent
 	UpdateMetrics(ctx context.Context, token string, metrics AgentHostMetricsReport) error
@@ -34,10 +37,27 @@ type AgentHostService interface {
 	UpdateProtocols(ctx context.Context, token string, protocols []ProtocolInfo) error
 	UpdateClientConfigs(ctx context.Context, token string, configs []ClientConfigInfo) error
 	UpdateCapabilities(ctx context.Context, token string, coreVersion string, capabilities, buildTags []string) error
+	RecordDiagnostics(ctx context.Context, agentHostID int64, diag *AgentDiagnostics) error
+	GetDiagnostics(ctx context.Context, agentHostID int64) (*AgentDiagnostics, error)
+	RecordPathUsage(ctx context.Context, agentHostID int64, usages []AgentPathUsage) error
+	GetPathUsage(ctx context.Context, agentHostID int64) ([]AgentPathUsage, error)
+	ListClientConfigs(ctx context.Context, agentHostID int64, format string, reveal bool) ([]AgentHostClientConfig, error)
 
 	// Template management
-	AssignTemplate(ctx context.Context, agentID, templateID int64) error
+	AssignTemplate(ctx context.Context, agentID, templateID, version int64) (int64, error)
 	CheckTemplateCompatibility(ctx context.Context, agentID, templateID int64) (*TemplateCompatibilityResult, error)
+	ListIncompatibleAgents(ctx context.Context, templateID int64) ([]AgentCompatInfo, error)
+	PreviewConfig(ctx context.Context, agentID, templateID int64) ([]byte, *TemplateCompatibilityResult, error)
+
+	// Stream commands pushed to online agents
+	TriggerResync(ctx context.Context, agentID int64) error
+	TriggerCapabilityRedetect(ctx context.Context, agentID int64) error
+	RefreshAll(ctx context.Context, opts AgentHostRefreshOptions) (*AgentHostRefreshSummary, error)
+	SetMaintenance(ctx context.Context, agentID int64, enabled bool) error
+	SetIntervals(ctx context.Context, agentID int64, syncSeconds, reportSeconds int) error
+	RotateToken(ctx context.Context, agentID int64, input AgentTokenRotationInput) (*AgentTokenRotation, error)
+	SubscribeStreamCommands(agentID int64, token string) (<-chan AgentStreamCommand, func())
+	AgentResyncNotifier
 
 	GenerateConfig(ctx context.Context, agentID int64) ([]byte, error)
 	FlushMetrics(ctx context.Context) error
@@ -45,17 +65,32 @@ type AgentHostService interface {
 
 // TemplateCompatibilityResult contains the result of a template compatibility check.
 type TemplateCompatibilityResult struct {
-	Compatible bool     `json:"compatible"`
-	Warnings   []string `json:"warnings,omitempty"`
-	Errors     []string `json:"errors,omitempty"`
+	Compatible bool                       `json:"compatible"`
+	Warnings   []string                   `json:"warnings,omitempty"`
+	Errors     []string                   `json:"errors,omitempty"`
+	Issues     []template.ValidationIssue `json:"issues,omitempty"`
+}
+
+// ConfigValidationError is returned by GenerateConfig when the rendered config fails validation.
+type ConfigValidationError struct {
+	Issues []template.ValidationIssue `json:"issues"`
+}
+
+func (e *ConfigValidationError) Error() string {
+	if e == nil {
+		return "generated config validation failed / 生成配置校验失败"
+	}
+	errs := template.FlattenIssues(e.Issues, template.SeverityError)
+	return fmt.Sprintf("generated config validation failed: %v / 生成配置校验失败: %v", errs, errs)
 }
 
 // ProtocolInfo represents a protocol reported by the agent
 type ProtocolInfo struct {
-	Name    string
-	Type    string
-	Running bool
-	Details []ProtocolDetails // Parsed protocol details
+	Name        string
+	Type        string
+	Running     bool
+	ContentHash string            // Drift hash reported by the agent
+	Details     []ProtocolDetails // Parsed protocol details
 }
 
 // ProtocolDetails contains detailed protocol configuration
@@ -69,6 +104,10 @@ type ProtocolDetails struct {
 	Multiplex *MultiplexInfo `json:"multiplex,omitempty"`
 	Users     []UserInfoData `json:"users,omitempty"`
 	CoreType  string         `json:"core_type"`
+	// Encryption 为 VLESS 加密参数（xray decryption），空值表示 none
+	Encryption string `json:"encryption,omitempty"`
+	// Options 为协议特有选项（shadowtls version/handshake、anytls padding_scheme 等），键名与 Agent 解析结果一致
+	Options map[string]any `json:"options,omitempty"`
 }
 
 // TransportInfo describes transport layer settings
@@ -85,6 +124,7 @@ type TLSInfo struct {
 	ServerName string       `json:"server_name,omitempty"`
 	ALPN       []string     `json:"alpn,omitempty"`
 	Reality    *RealityInfo `json:"reality,omitempty"`
+	ECHEnabled bool         `json:"ech_enabled,omitempty"`
 }
 
 // RealityInfo describes XTLS Reality settings
@@ -157,6 +197,9 @@ type AgentHostMetricsReport struct {
 	ReportedAt            int64
 	AgentVersion          string
 	CurrentCoreType       string
+	// ConnectionCountSource 为空表示 Agent 无法统计连接数（或版本过旧），ConnectionCount 此时为 0
+	ConnectionCount       int64
+	ConnectionCountSource string
 }
 
 // ClientConfigInfo represents a client configuration reported by the agent.
@@ -168,8 +211,19 @@ type ClientConfigInfo struct {
 }
 
 type AgentHostServiceOptions struct {
-	Cache  cache.Store
-	Logger *slog.Logger
+	Cache            cache.Store
+	Logger           *slog.Logger
+	TemplateVersions repository.ConfigTemplateVersionRepository
+	Diagnostics      repository.AgentHostDiagnosticsRepository
+	PathUsage        repository.AgentHostPathUsageRepository
+	// Routes supplies server route rules rendered into the template route section.
+	Routes repository.ServerRouteRepository
+	// Notifications delivers watched path alerts to telegram_admin_id; nil disables them.
+	Notifications *async.NotificationQueue
+	// AuditLog records admin create/update/delete actions on hosts; nil disables auditing.
+	AuditLog AuditLogService
+	// Dashboard receives metrics updates for live admin dashboards; nil disables pushing.
+	Dashboard *DashboardHub
 }
 
 type agentHostService struct {
@@ -177,9 +231,18 @@ type agentHostService struct {
 	servers             repository.ServerRepository
 	serverClientConfigs repository.ServerClientConfigRepository
 	configTemplates     repository.ConfigTemplateRepository
+	templateVersions    repository.ConfigTemplateVersionRepository
 	users               repository.UserRepository
 	settings            repository.SettingRepository
 	metricsBuffer       *agentHostMetricsBuffer
+	streamCommands      *agentStreamCommandBroker
+	diagnostics         repository.AgentHostDiagnosticsRepository
+	pathUsage           repository.AgentHostPathUsageRepository
+	routes              repository.ServerRouteRepository
+	notifications       *async.NotificationQueue
+	audit               AuditLogService
+	logger              *slog.Logger
+	dashboard           *DashboardHub
 }
 
 func NewAgentHostServiceWithOptions(
@@ -196,9 +259,18 @@ func NewAgentHostServiceWithOptions(
 		servers:             servers,
 		serverClientConfigs: serverClientConfigs,
 		configTemplates:     configTemplates,
+		templateVersions:    opts.TemplateVersions,
 		users:               users,
 		settings:            settings,
 		metricsBuffer:       newAgentHostMetricsBuffer(opts.Cache, agentHosts, opts.Logger),
+		streamCommands:      newAgentStreamCommandBroker(),
+		diagnostics:         opts.Diagnostics,
+		pathUsage:           opts.PathUsage,
+		routes:              opts.Routes,
+		notifications:       opts.Notifications,
+		audit:               opts.AuditLog,
+		logger:              opts.Logger,
+		dashboard:           opts.Dashboard,
 	}
 }
 
@@ -235,6 +307,7 @@ func (s *agentHostService) Create(ctx context.Context, req CreateAgentHostReques
 	if err := s.agentHosts.Create(ctx, host); err != nil {
 		return nil, err
 	}
+	recordAudit(ctx, s.audit, AuditEntry{Action: AuditActionCreate, TargetType: AuditTargetAgentHost, TargetID: host.ID, After: host})
 
 	return host, nil
 }
@@ -297,15 +370,12 @@ func (s *agentHostService) GetByID(ctx context.Context, id int64) (*repository.A
 	return s.agentHosts.FindByID(ctx, id)
 }
 
-func (s *agentHostService) GetByToken(ctx context.Context, token string) (*repository.AgentHost, error) {
-	return s.agentHosts.FindByToken(ctx, token)
-}
-
 func (s *agentHostService) Update(ctx context.Context, id int64, req UpdateAgentHostRequest) error {
 	host, err := s.agentHosts.FindByID(ctx, id)
 	if err != nil {
 		return err
 	}
+	before := *host
 
 	if req.Name != nil {
 		host.Name = strings.TrimSpace(*req.Name)
@@ -320,17 +390,47 @@ func (s *agentHostService) Update(ctx context.Context, id int64, req UpdateAgent
 		host.ProvisionStatus = 0
 	}
 
-	return s.agentHosts.Update(ctx, host)
+	if err := s.agentHosts.Update(ctx, host); err != nil {
+		return err
+	}
+	recordAudit(ctx, s.audit, AuditEntry{Action: AuditActionUpdate, TargetType: AuditTargetAgentHost, TargetID: host.ID, Before: &before, After: host})
+	return nil
 }
 
 func (s *agentHostService) Delete(ctx context.Context, id int64) error {
-	return s.agentHosts.Delete(ctx, id)
+	existing, _ := s.agentHosts.FindByID(ctx, id)
+	if err := s.agentHosts.Delete(ctx, id); err != nil {
+		return err
+	}
+	recordAudit(ctx, s.audit, AuditEntry{Action: AuditActionDelete, TargetType: AuditTargetAgentHost, TargetID: id, Before: existing})
+	return nil
 }
 
 func (s *agentHostService) List(ctx context.Context) ([]*repository.AgentHost, error) {
 	return s.agentHosts.ListAll(ctx)
 }
 
+// Search 分页查询探针节点并返回符合条件的总数。
+func (s *agentHostService) Search(ctx context.Context, filter repository.AgentHostSearchFilter) ([]*repository.AgentHost, int64, error) {
+	switch filter.State {
+	case "", repository.AgentHostStateOnline, repository.AgentHostStateWarning, repository.AgentHostStateOffline, repository.AgentHostStateMaintenance:
+	default:
+		return nil, 0, fmt.Errorf("%w: unknown state %q / 未知的节点状态", ErrBadRequest, filter.State)
+	}
+	if filter.Now == 0 {
+		filter.Now = time.Now().Unix()
+	}
+	hosts, err := s.agentHosts.Search(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search agent hosts: %v / 查询探针节点失败: %w", err, err)
+	}
+	total, err := s.agentHosts.CountFiltered(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count agent hosts: %v / 统计探针节点失败: %w", err, err)
+	}
+	return hosts, total, nil
+}
+
 func (s *agentHostService) FlushMetrics(ctx context.Context) error {
 	if s == nil || s.metricsBuffer == nil {
 		return nil
@@ -382,6 +482,11 @@ func (s *agentHostService) UpdateMetrics(ctx context.Context, token string, metr
 		LastRestartAt:         host.LastRestartAt,
 		AgentVersion:          host.AgentVersion,
 		CurrentCoreType:       host.CurrentCoreType,
+		ConnectionCount:       metrics.ConnectionCount,
+		ConnectionCountSource: metrics.ConnectionCountSource,
+	}
+	if repoMetrics.ConnectionCountSource == "" {
+		repoMetrics.ConnectionCount = 0
 	}
 	if metrics.UploadRateBps != nil {
 		repoMetrics.UploadRateBps = *metrics.UploadRateBps
@@ -416,9 +521,29 @@ func (s *agentHostService) UpdateMetrics(ctx context.Context, token string, metr
 	}
 
 	if s.metricsBuffer != nil {
-		return s.metricsBuffer.Enqueue(ctx, host.ID, repoMetrics)
+		err = s.metricsBuffer.Enqueue(ctx, host.ID, repoMetrics)
+	} else {
+		err = s.agentHosts.UpdateMetrics(ctx, host.ID, repoMetrics)
+	}
+	if err != nil {
+		return err
 	}
-	return s.agentHosts.UpdateMetrics(ctx, host.ID, repoMetrics)
+	s.dashboard.Publish(DashboardEvent{
+		Type:        DashboardEventHostMetrics,
+		AgentHostID: host.ID,
+		Timestamp:   reportAt,
+		Data: NodeWebhookMetrics{
+			CPUUsed:         repoMetrics.CPUUsed,
+			MemTotal:        repoMetrics.MemTotal,
+			MemUsed:         repoMetrics.MemUsed,
+			DiskTotal:       repoMetrics.DiskTotal,
+			DiskUsed:        repoMetrics.DiskUsed,
+			UploadRateBps:   repoMetrics.UploadRateBps,
+			DownloadRateBps: repoMetrics.DownloadRateBps,
+			ReportedAt:      reportAt,
+		},
+	})
+	return nil
 }
 
 func (s *agentHostService) UpdateHeartbeat(ctx context.Context, token string) error {
@@ -515,6 +640,8 @@ func (s *agentHostService) UpdateProtocols(ctx context.Context, token string, pr
 				Tags:         json.RawMessage("[]"),
 				Settings:     settingsJSON,
 				ObfsSettings: json.RawMessage("{}"),
+				Rate:         "1",
+				TrafficRatio: 1,
 			}
 			if p.Running {
 				newServer.LastHeartbeatAt = now
@@ -524,6 +651,8 @@ func (s *agentHostService) UpdateProtocols(ctx context.Context, token string, pr
 			}
 		}
 	}
+
+	s.detectConfigDrift(ctx, host, protocols)
 	return nil
 }
 
@@ -609,11 +738,18 @@ func (s *agentHostService) GenerateConfig(ctx context.Context, agentID int64) ([
 	if host.TemplateID == 0 {
 		return nil, nil // No template assigned, return nil config (agent keeps using local config)
 	}
+	if host.Maintenance {
+		return nil, nil // 维护模式下暂停下发，保留运维人员在节点上的手工修改
+	}
 
-	tpl, err := s.configTemplates.FindByID(ctx, host.TemplateID)
+	tpl, err := s.resolveHostTemplate(ctx, host)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find config template: %v / 获取配置模板失败: %w", err, err)
 	}
+	// 校验状态取自节点实际使用的版本，最新版本的编辑错误不影响固定在旧版本的节点
+	if !tpl.IsValid {
+		return nil, fmt.Errorf("template has validation errors: %s / 模板校验失败: %s", tpl.ValidationError, tpl.ValidationError)
+	}
 
 	// Build template context (hybrid mode: template defines structure, system injects users and inbounds)
 	templateCtx, err := s.buildTemplateContext(ctx, host, tpl)
@@ -651,14 +787,17 @@ func (s *agentHostService) GenerateConfig(ctx context.Context, agentID int64) ([
 
 	// Validate final config
 	validator := template.NewValidator()
-	result := validator.ValidateFinalConfig(configJSON, tpl.Type)
-	if !result.Valid {
-		return nil, fmt.Errorf("generated config validation failed: %v / 生成配置校验失败: %v", result.Errors, result.Errors)
+	issues := validator.ValidateFinalConfig(configJSON, tpl.Type)
+	if template.HasIssueErrors(issues) {
+		return nil, &ConfigValidationError{Issues: issues}
 	}
 
 	// Log any warnings from validation
-	for _, w := range result.Warnings {
-		slog.Warn("Config validation warning", "agent_id", agentID, "warning", w)
+	for _, issue := range issues {
+		if issue.Severity != template.SeverityWarning {
+			continue
+		}
+		slog.Warn("Config validation warning", "agent_id", agentID, "path", issue.Path, "warning", issue.Message)
 	}
 
 	return configJSON, nil
@@ -675,6 +814,7 @@ func (s *agentHostService) buildTemplateContext(ctx context.Context, host *repos
 	// Build inbounds from servers
 	inbounds := make([]template.InboundConfig, 0, len(servers))
 	groupSet := make(map[int64]struct{})
+	routeInbounds := make(map[int64][]string)
 
 	for _, srv := range servers {
 		if srv.Settings == nil || len(srv.Settings) == 0 {
@@ -693,9 +833,23 @@ func (s *agentHostService) buildTemplateContext(ctx context.Context, host *repos
 		}
 
 		// Convert each protocol detail to InboundConfig
+		routeIDs := extractRouteIDs(srv, nil)
 		for _, d := range details {
 			inbound := s.convertProtocolDetailsToInbound(d)
+			if inbound.ShadowTLS != nil {
+				// 加密方式与 PSK 按节点派生，订阅侧 deriveServerPassword 使用同一节点得出客户端密码
+				if inbound.ShadowTLS.Method == "" {
+					inbound.ShadowTLS.Method = strings.TrimSpace(srv.Cipher)
+				}
+				if inbound.ShadowTLS.Method == "" {
+					inbound.ShadowTLS.Method = template.DefaultShadowTLSMethod
+				}
+				inbound.ShadowTLS.ServerKey = template.Shadowsocks2022ServerKey(srv.CreatedAt, inbound.ShadowTLS.Method)
+			}
 			inbounds = append(inbounds, inbound)
+			for _, routeID := range routeIDs {
+				routeInbounds[routeID] = appendUniqueString(routeInbounds[routeID], inbound.Tag)
+			}
 		}
 	}
 
@@ -710,12 +864,19 @@ func (s *agentHostService) buildTemplateContext(ctx context.Context, host *repos
 		nodeUsers, err := s.users.ListActiveForGroups(ctx, groupIDs, time.Now().Unix())
 		if err == nil {
 			for _, u := range nodeUsers {
-				users = append(users, template.UserConfig{
+				user := template.UserConfig{
 					ID:      u.ID,
 					UUID:    u.UUID,
 					Email:   u.Email,
 					Enabled: true,
-				})
+				}
+				if u.SpeedLimit != nil && *u.SpeedLimit > 0 {
+					user.SpeedLimit = *u.SpeedLimit
+				}
+				if u.DeviceLimit != nil && *u.DeviceLimit > 0 {
+					user.DeviceLimit = *u.DeviceLimit
+				}
+				users = append(users, user)
 			}
 		}
 	}
@@ -726,10 +887,16 @@ func (s *agentHostService) buildTemplateContext(ctx context.Context, host *repos
 		{Type: "block", Tag: "block"},
 	}
 
+	route, err := s.buildTemplateRoute(ctx, routeInbounds)
+	if err != nil {
+		return nil, err
+	}
+
 	return &template.TemplateContext{
 		Inbounds:  inbounds,
 		Outbounds: outbounds,
 		Users:     users,
+		Route:     route,
 		Agent: template.AgentInfo{
 			ID:           host.ID,
 			Name:         host.Name,
@@ -775,6 +942,26 @@ func (s *agentHostService) convertProtocolDetailsToInbound(d ProtocolDetails) te
 		Tag:        d.Tag,
 		Listen:     d.Listen,
 		ListenPort: d.Port,
+		Encryption: d.Encryption,
+		Options:    d.Options,
+	}
+
+	switch d.Protocol {
+	case "anytls":
+		// AnyTLS 仅 sing-box >= 1.12 支持
+		inbound.RequiredCapabilities = append(inbound.RequiredCapabilities, string(template.CapAnyTLS))
+	case "shadowtls":
+		// ShadowTLS 仅 sing-box 提供，且多用户模式需要 v3 支持
+		inbound.ShadowTLS = shadowTLSConfigFromOptions(d.Options)
+		inbound.RequiredCapabilities = append(inbound.RequiredCapabilities, string(template.CapShadowTLS))
+	case "mieru":
+		// mieru 由独立的 mita 服务端承载，Agent 未探测到时过滤并告警
+		inbound.RequiredCapabilities = append(inbound.RequiredCapabilities, string(template.CapMieru))
+	case "naive":
+		// naive 的 QUIC (HTTP/3) 监听需要 with_quic 构建
+		if network := asString(d.Options["network"]); network == "udp" {
+			inbound.RequiredCapabilities = append(inbound.RequiredCapabilities, string(template.CapQUIC))
+		}
 	}
 
 	// Convert Transport
@@ -812,6 +999,12 @@ func (s *agentHostService) convertProtocolDetailsToInbound(d ProtocolDetails) te
 			// Mark Reality as required capability
 			inbound.RequiredCapabilities = append(inbound.RequiredCapabilities, "reality")
 		}
+
+		// Convert ECH
+		if d.TLS.ECHEnabled {
+			inbound.TLS.ECH = &template.ECHConfig{Enabled: true}
+			inbound.RequiredCapabilities = append(inbound.RequiredCapabilities, string(template.CapECH))
+		}
 	}
 
 	// Convert Multiplex
@@ -838,6 +1031,26 @@ func (s *agentHostService) convertProtocolDetailsToInbound(d ProtocolDetails) te
 	return inbound
 }
 
+// shadowTLSConfigFromOptions 从协议选项读取 ShadowTLS 配置，未指定版本时按 v3 处理。
+func shadowTLSConfigFromOptions(options map[string]any) *template.ShadowTLSConfig {
+	cfg := &template.ShadowTLSConfig{
+		Version:    toInt(options["version"]),
+		Password:   asString(options["password"]),
+		StrictMode: toBool(options["strict_mode"]),
+		Method:     asString(options["method"]),
+	}
+	if cfg.Version == 0 {
+		cfg.Version = 3
+	}
+	if server := asString(options["handshake_server"]); server != "" {
+		cfg.Handshake = &template.HandshakeConfig{
+			Server:     server,
+			ServerPort: toInt(options["handshake_port"]),
+		}
+	}
+	return cfg
+}
+
 func convertToSingBoxInbound(d ProtocolDetails) (map[string]interface{}, error) {
 	inbound := make(map[string]interface{})
 
@@ -851,9 +1064,11 @@ func convertToSingBoxInbound(d ProtocolDetails) (map[string]interface{}, error)
 	if d.Port > 0 {
 		inbound["listen_port"] = d.Port
 	}
+	// VLESS encryption 仅 Xray 支持（由模板的 xray 入站输出），sing-box 严格解析会拒绝该字段
 
-	// Users
-	if len(d.Users) > 0 {
+	// Users（socks/http 关闭认证时不输出用户）
+	authEnabled := (d.Protocol != "socks" && d.Protocol != "http") || template.ProxyAuthEnabled(d.Options)
+	if len(d.Users) > 0 && authEnabled {
 		var users []map[string]interface{}
 		for _, u := range d.Users {
 			user := make(map[string]interface{})
@@ -878,9 +1093,31 @@ func convertToSingBoxInbound(d ProtocolDetails) (map[string]interface{}, error)
 					user["password"] = u.UUID // UUID field reused as password
 				}
 			}
+			// AnyTLS 仅支持 password 认证
+			if d.Protocol == "anytls" && u.UUID != "" {
+				delete(user, "uuid")
+				user["password"] = u.UUID
+			}
+			// naive/socks/http 使用 basic-auth 的 username/password
+			if d.Protocol == "naive" || d.Protocol == "socks" || d.Protocol == "http" {
+				delete(user, "uuid")
+				delete(user, "name")
+				user["username"] = u.Email
+				user["password"] = u.UUID
+			}
 			users = append(users, user)
 		}
 		inbound["users"] = users
+	} else if (d.Protocol == "socks" || d.Protocol == "http") && authEnabled {
+		// 声明空 users 使 Agent 后续能注入用户；未声明表示关闭认证
+		inbound["users"] = []map[string]interface{}{}
+	}
+
+	// AnyTLS 自定义填充方案，与模板函数生成的入站保持一致
+	if d.Protocol == "anytls" {
+		if scheme := template.AnyTLSPaddingScheme(d.Options); len(scheme) > 0 {
+			inbound["padding_scheme"] = scheme
+		}
 	}
 
 	// Transport
@@ -941,6 +1178,11 @@ func convertToSingBoxInbound(d ProtocolDetails) (map[string]interface{}, error)
 			tls["reality"] = reality
 		}
 
+		// ECH
+		if d.TLS.ECHEnabled {
+			tls["ech"] = map[string]interface{}{"enabled": true}
+		}
+
 		inbound["tls"] = tls
 	}
 
@@ -974,23 +1216,38 @@ func hashContent(content string) string {
 }
 
 // AssignTemplate assigns a template to an agent host after checking compatibility.
-func (s *agentHostService) AssignTemplate(ctx context.Context, agentID, templateID int64) error {
+// version 为 0 时固定到模板当前最新版本，之后的模板编辑不会影响该节点，直到重新分配。
+// 返回实际固定的版本号。
+func (s *agentHostService) AssignTemplate(ctx context.Context, agentID, templateID, version int64) (int64, error) {
 	// Get the agent host
 	host, err := s.agentHosts.FindByID(ctx, agentID)
 	if err != nil {
-		return fmt.Errorf("find agent host: %v / 获取探针节点失败: %w", err, err)
+		return 0, fmt.Errorf("find agent host: %v / 获取探针节点失败: %w", err, err)
 	}
 
+	before := *host
+
 	// If templateID is 0, clear the template assignment
 	if templateID == 0 {
 		host.TemplateID = 0
-		return s.agentHosts.Update(ctx, host)
+		host.TemplateVersion = 0
+		if err := s.agentHosts.Update(ctx, host); err != nil {
+			return 0, err
+		}
+		recordAudit(ctx, s.audit, AuditEntry{Action: AuditActionUpdate, TargetType: AuditTargetAgentHost, TargetID: host.ID, Before: &before, After: host})
+		s.publishStreamCommand(ctx, agentID, AgentStreamCommand{Command: AgentStreamCommandResync})
+		return 0, nil
 	}
 
 	// Check template existence
 	_, err = s.configTemplates.FindByID(ctx, templateID)
 	if err != nil {
-		return fmt.Errorf("find config template: %v / 获取配置模板失败: %w", err, err)
+		return 0, fmt.Errorf("find config template: %v / 获取配置模板失败: %w", err, err)
+	}
+
+	pinned, err := s.pinTemplateVersion(ctx, templateID, version)
+	if err != nil {
+		return 0, err
 	}
 
 	// Check compatibility (log warnings but don't block assignment)
@@ -1013,7 +1270,77 @@ func (s *agentHostService) AssignTemplate(ctx context.Context, agentID, template
 
 	// Assign the template
 	host.TemplateID = templateID
-	return s.agentHosts.Update(ctx, host)
+	host.TemplateVersion = pinned
+	if err := s.agentHosts.Update(ctx, host); err != nil {
+		return 0, err
+	}
+	recordAudit(ctx, s.audit, AuditEntry{Action: AuditActionUpdate, TargetType: AuditTargetAgentHost, TargetID: host.ID, Before: &before, After: host})
+	// 通知在线 Agent 立即拉取新配置
+	s.publishStreamCommand(ctx, agentID, AgentStreamCommand{Command: AgentStreamCommandResync})
+	return pinned, nil
+}
+
+// pinTemplateVersion 校验指定版本存在；未指定时取最新版本。版本仓库不可用时返回 0（跟随最新）。
+func (s *agentHostService) pinTemplateVersion(ctx context.Context, templateID, version int64) (int64, error) {
+	if s.templateVersions == nil {
+		if version > 0 {
+			return 0, ErrNotImplemented
+		}
+		return 0, nil
+	}
+	if version < 0 {
+		return 0, ErrBadRequest
+	}
+	if version == 0 {
+		latest, err := s.templateVersions.GetMaxVersion(ctx, templateID)
+		if err != nil {
+			return 0, fmt.Errorf("get template version: %v / 获取模板版本失败: %w", err, err)
+		}
+		return latest, nil
+	}
+	if _, err := s.templateVersions.FindByTemplateAndVersion(ctx, templateID, version); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return 0, ErrNotFound
+		}
+		return 0, err
+	}
+	return version, nil
+}
+
+// resolveHostTemplate 返回节点实际使用的模板内容：固定了版本时用该版本快照覆盖当前模板。
+func (s *agentHostService) resolveHostTemplate(ctx context.Context, host *repository.AgentHost) (*repository.ConfigTemplate, error) {
+	tpl, err := s.configTemplates.FindByID(ctx, host.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+	if host.TemplateVersion <= 0 || s.templateVersions == nil {
+		return tpl, nil
+	}
+	version, err := s.templateVersions.FindByTemplateAndVersion(ctx, host.TemplateID, host.TemplateVersion)
+	if err != nil {
+		return nil, fmt.Errorf("find template version %d: %w", host.TemplateVersion, err)
+	}
+	return applyTemplateVersion(tpl, version), nil
+}
+
+// applyTemplateVersion 返回用版本快照覆盖内容后的模板副本，校验状态按快照内容重新计算，
+// 而不是沿用最新版本缓存的 IsValid。
+func applyTemplateVersion(tpl *repository.ConfigTemplate, version *repository.ConfigTemplateVersion) *repository.ConfigTemplate {
+	pinned := *tpl
+	pinned.Name = version.Name
+	pinned.Type = version.Type
+	pinned.Content = version.Content
+	pinned.Description = version.Description
+	pinned.MinVersion = version.MinVersion
+	pinned.Capabilities = version.Capabilities
+
+	validationResult := template.NewValidator().ValidateTemplate(version.Content, version.Type)
+	pinned.IsValid = validationResult.Valid
+	pinned.ValidationError = ""
+	if !validationResult.Valid && len(validationResult.Errors) > 0 {
+		pinned.ValidationError = validationResult.Errors[0]
+	}
+	return &pinned
 }
 
 func generateAgentHostToken() (string, error) {
@@ -1053,12 +1380,6 @@ func randomHex(n int) (string, error) {
 
 // CheckTemplateCompatibility checks if a template is compatible with an agent's capabilities.
 func (s *agentHostService) CheckTemplateCompatibility(ctx context.Context, agentID, templateID int64) (*TemplateCompatibilityResult, error) {
-	result := &TemplateCompatibilityResult{
-		Compatible: true,
-		Warnings:   []string{},
-		Errors:     []string{},
-	}
-
 	// Get the agent host
 	host, err := s.agentHosts.FindByID(ctx, agentID)
 	if err != nil {
@@ -1071,11 +1392,32 @@ func (s *agentHostService) CheckTemplateCompatibility(ctx context.Context, agent
 		return nil, fmt.Errorf("find config template: %w", err)
 	}
 
+	result, _ := s.evaluateTemplateCompatibility(host, tpl)
+	return result, nil
+}
+
+// templateCompatGaps records the specific requirements an agent fails to meet.
+type templateCompatGaps struct {
+	versionMismatch     bool
+	missingCapabilities []string
+}
+
+// evaluateTemplateCompatibility checks a loaded template against a loaded host without further lookups.
+func (s *agentHostService) evaluateTemplateCompatibility(host *repository.AgentHost, tpl *repository.ConfigTemplate) (*TemplateCompatibilityResult, templateCompatGaps) {
+	result := &TemplateCompatibilityResult{
+		Compatible: true,
+		Warnings:   []string{},
+		Errors:     []string{},
+	}
+	var gaps templateCompatGaps
+
 	// Check if template is valid
 	if !tpl.IsValid {
 		result.Errors = append(result.Errors, fmt.Sprintf("Template has validation errors: %s / 模板校验失败: %s", tpl.ValidationError, tpl.ValidationError))
+		// 重新校验一次以附带结构化的问题定位
+		result.Issues = template.NewValidator().ValidateTemplate(tpl.Content, tpl.Type).Issues
 		result.Compatible = false
-		return result, nil
+		return result, gaps
 	}
 
 	// Build agent capabilities
@@ -1096,6 +1438,7 @@ func (s *agentHostService) CheckTemplateCompatibility(ctx context.Context, agent
 					tpl.MinVersion, host.CoreVersion, tpl.MinVersion, host.CoreVersion,
 				))
 				result.Compatible = false
+				gaps.versionMismatch = true
 			}
 		}
 	}
@@ -1103,6 +1446,15 @@ func (s *agentHostService) CheckTemplateCompatibility(ctx context.Context, agent
 	// Check required capabilities
 	for _, reqCap := range tpl.Capabilities {
 		if !agentCaps.SupportsCapability(template.Capability(reqCap)) {
+			gaps.missingCapabilities = append(gaps.missingCapabilities, reqCap)
+			if template.Capability(reqCap) == template.CapECH {
+				hint := template.NewCapabilityFilter(agentCaps).VersionRequirement(template.CapECH)
+				result.Warnings = append(result.Warnings, fmt.Sprintf(
+					"Template declares ECH but agent version %s predates support (%s) / 模板声明了 ECH，但探针版本 %s 尚不支持（%s）",
+					host.CoreVersion, hint, host.CoreVersion, hint,
+				))
+				continue
+			}
 			result.Warnings = append(result.Warnings, fmt.Sprintf(
 				"Template requires capability '%s' which may not be supported by agent / 模板需要能力 '%s'，探针可能不支持",
 				reqCap, reqCap,
@@ -1115,5 +1467,5 @@ func (s *agentHostService) CheckTemplateCompatibility(ctx context.Context, agent
 		result.Warnings = append(result.Warnings, "Agent has not reported capabilities yet. Compatibility cannot be fully verified. / 探针尚未上报能力，无法完全校验兼容性。")
 	}
 
-	return result, nil
+	return result, gaps
 }
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+	"github.com/creamcroissant/xboard/internal/support/security"
+)
+
+// AgentHostClientConfig 是节点上报的一份原始客户端配置，供管理员排障时直接复制。
+// Masked 为 true 时 Content 中的凭据已被替换为占位符，ContentHash 仍对应原始内容。
+type AgentHostClientConfig struct {
+	ServerID    int64  `json:"server_id"`
+	ServerName  string `json:"server_name"`
+	Format      string `json:"format"`
+	Content     string `json:"content"`
+	ContentHash string `json:"content_hash"`
+	Masked      bool   `json:"masked"`
+	UpdatedAt   int64  `json:"updated_at"`
+}
+
+// clientConfigSecretQueryKeys 是分享链接查询参数中额外需要打码的字段。
+var clientConfigSecretQueryKeys = map[string]struct{}{
+	"sid":           {},
+	"obfs_password": {},
+}
+
+// clientConfigSecretPattern 匹配 YAML / 文本配置中的 `key: value` 与 `key=value`。
+var clientConfigSecretPattern = regexp.MustCompile(`(?m)([A-Za-z][A-Za-z0-9_-]*)(\s*[:=]\s*)("[^"]*"|'[^']*'|[^\s,}\]&]+)`)
+
+// ListClientConfigs 返回探针下各节点上报的原始客户端配置；format 为空时返回全部格式。
+// reveal 为 false 时对凭据打码。
+func (s *agentHostService) ListClientConfigs(ctx context.Context, agentHostID int64, format string, reveal bool) ([]AgentHostClientConfig, error) {
+	if s.servers == nil || s.serverClientConfigs == nil {
+		return nil, fmt.Errorf("server client config repository unavailable / 节点客户端配置仓库不可用")
+	}
+	if _, err := s.agentHosts.FindByID(ctx, agentHostID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	servers, err := s.servers.FindByAgentHostID(ctx, agentHostID)
+	if err != nil {
+		return nil, fmt.Errorf("list servers: %v / 获取节点列表失败: %w", err, err)
+	}
+
+	format = strings.ToLower(strings.TrimSpace(format))
+	configs := make([]AgentHostClientConfig, 0)
+	for _, server := range servers {
+		if server == nil {
+			continue
+		}
+		records, err := s.serverClientConfigs.FindByServerID(ctx, server.ID)
+		if err != nil {
+			return nil, fmt.Errorf("list client configs: %v / 获取客户端配置失败: %w", err, err)
+		}
+		for _, record := range records {
+			if record == nil || (format != "" && strings.ToLower(record.Format) != format) {
+				continue
+			}
+			content := record.Content
+			if !reveal {
+				content = MaskClientConfig(content)
+			}
+			configs = append(configs, AgentHostClientConfig{
+				ServerID:    server.ID,
+				ServerName:  server.Name,
+				Format:      record.Format,
+				Content:     content,
+				ContentHash: record.ContentHash,
+				Masked:      !reveal,
+				UpdatedAt:   record.UpdatedAt,
+			})
+		}
+	}
+	sort.SliceStable(configs, func(i, j int) bool {
+		if configs[i].ServerID != configs[j].ServerID {
+			return configs[i].ServerID < configs[j].ServerID
+		}
+		return configs[i].Format < configs[j].Format
+	})
+
+	if reveal && s.logger != nil {
+		s.logger.Info("client configs revealed", "agent_host_id", agentHostID, "format", format, "count", len(configs))
+	}
+	return configs, nil
+}
+
+// MaskClientConfig 对客户端配置中的凭据打码，支持 JSON、分享链接、Base64 订阅与 YAML 等文本格式。
+func MaskClientConfig(content string) string {
+	trimmed := strings.TrimSpace(content)
+	if trimmed == "" {
+		return content
+	}
+	if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		var parsed any
+		if err := json.Unmarshal([]byte(trimmed), &parsed); err == nil {
+			if masked, err := json.MarshalIndent(maskPreviewValue(parsed, false), "", "  "); err == nil {
+				return string(masked)
+			}
+		}
+	}
+	if !strings.ContainsAny(trimmed, " \n:") {
+		if decoded, ok := decodeClientConfigBase64(trimmed); ok && strings.Contains(decoded, "://") {
+			return base64.StdEncoding.EncodeToString([]byte(maskClientConfigText(decoded)))
+		}
+	}
+	return maskClientConfigText(content)
+}
+
+// maskClientConfigText 逐行处理：分享链接按 URL 结构打码，其余行按键值对打码。
+func maskClientConfigText(content string) string {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if scheme, _, ok := strings.Cut(trimmed, "://"); ok && !strings.ContainsAny(scheme, " \t:") && scheme != "" {
+			lines[i] = strings.Replace(line, trimmed, maskClientShareLink(trimmed), 1)
+			continue
+		}
+		lines[i] = maskClientConfigKeyValues(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// maskClientShareLink 打码分享链接的 userinfo 与敏感查询参数；vmess:// 链接解码其 JSON 负载后打码。
+func maskClientShareLink(link string) string {
+	if payload, ok := strings.CutPrefix(link, "vmess://"); ok {
+		if decoded, ok := decodeClientConfigBase64(payload); ok {
+			var parsed any
+			if err := json.Unmarshal([]byte(decoded), &parsed); err == nil {
+				if masked, err := json.Marshal(maskPreviewValue(parsed, false)); err == nil {
+					return "vmess://" + base64.StdEncoding.EncodeToString(masked)
+				}
+			}
+		}
+	}
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return maskClientConfigKeyValues(link)
+	}
+	if parsed.User != nil {
+		parsed.User = url.User(previewMaskValue)
+	}
+	if parsed.RawQuery != "" {
+		query := parsed.Query()
+		for key, values := range query {
+			normalized := strings.ReplaceAll(strings.ToLower(key), "-", "_")
+			_, extra := clientConfigSecretQueryKeys[normalized]
+			if !extra && !security.IsSecretConfigKey(normalized) {
+				continue
+			}
+			for i := range values {
+				values[i] = previewMaskValue
+			}
+		}
+		parsed.RawQuery = query.Encode()
+	}
+	return parsed.String()
+}
+
+// maskClientConfigKeyValues 打码 `key: value` / `key=value` 形式的敏感字段。
+func maskClientConfigKeyValues(text string) string {
+	return clientConfigSecretPattern.ReplaceAllStringFunc(text, func(match string) string {
+		parts := clientConfigSecretPattern.FindStringSubmatch(match)
+		key := strings.ReplaceAll(strings.ToLower(parts[1]), "-", "_")
+		_, extra := clientConfigSecretQueryKeys[key]
+		if !extra && !security.IsSecretConfigKey(key) {
+			return match
+		}
+		value := previewMaskValue
+		if quote := parts[3][0]; quote == '"' || quote == '\'' {
+			value = string(quote) + previewMaskValue + string(quote)
+		}
+		return parts[1] + parts[2] + value
+	})
+}
+
+// decodeClientConfigBase64 兼容标准与 URL 安全、带或不带填充的 Base64。
+func decodeClientConfigBase64(value string) (string, bool) {
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if decoded, err := encoding.DecodeString(value); err == nil {
+			return string(decoded), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestMaskClientConfigShareLinks(t *testing.T) {
+	links := "vless://11111111-2222-3333-4444-555555555555@example.com:443?security=reality&pbk=pub&sid=ab12&sni=example.com#node\n" +
+		"hysteria2://secretpass@example.com:8443?obfs=salamander&obfs-password=obfspass#hy2"
+	vmess := "vmess://" + base64.StdEncoding.EncodeToString([]byte(`{"add":"example.com","port":"443","id":"vmess-uuid","ps":"node"}`))
+
+	for _, content := range []string{links, vmess, base64.StdEncoding.EncodeToString([]byte(links))} {
+		masked := MaskClientConfig(content)
+		if decoded, ok := decodeClientConfigBase64(masked); ok && !strings.Contains(masked, "://") {
+			masked = decoded
+		}
+		if payload, ok := strings.CutPrefix(masked, "vmess://"); ok {
+			decoded, _ := decodeClientConfigBase64(payload)
+			masked = decoded
+		}
+		for _, secret := range []string{"11111111-2222", "ab12", "secretpass", "obfspass", "vmess-uuid"} {
+			if strings.Contains(masked, secret) {
+				t.Fatalf("masked config still contains %q: %s", secret, masked)
+			}
+		}
+		if !strings.Contains(masked, "example.com") {
+			t.Fatalf("masked config lost the server address: %s", masked)
+		}
+	}
+}
+
+func TestMaskClientConfigYAML(t *testing.T) {
+	content := "proxies:\n  - {name: node, server: example.com, port: 443, type: trojan, password: \"trojan-pass\"}\n  - name: vless\n    uuid: vless-uuid\n    private-key: pk\n"
+	masked := MaskClientConfig(content)
+	for _, secret := range []string{"trojan-pass", "vless-uuid", "private-key: pk"} {
+		if strings.Contains(masked, secret) {
+			t.Fatalf("masked config still contains %q: %s", secret, masked)
+		}
+	}
+	if !strings.Contains(masked, "server: example.com") || !strings.Contains(masked, `password: "******"`) {
+		t.Fatalf("unexpected masked config: %s", masked)
+	}
+}
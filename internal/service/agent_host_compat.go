@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+// AgentCompatInfo describes an agent that cannot cleanly run a template.
+type AgentCompatInfo struct {
+	AgentHostID         int64    `json:"agent_host_id"`
+	Name                string   `json:"name"`
+	Host                string   `json:"host"`
+	Status              int      `json:"status"`
+	CoreVersion         string   `json:"core_version"`
+	RequiredVersion     string   `json:"required_version,omitempty"`
+	VersionMismatch     bool     `json:"version_mismatch"`
+	MissingCapabilities []string `json:"missing_capabilities,omitempty"`
+	Compatible          bool     `json:"compatible"`
+	Warnings            []string `json:"warnings,omitempty"`
+	Errors              []string `json:"errors,omitempty"`
+}
+
+// ListIncompatibleAgents runs the template compatibility check against every agent host
+// and returns the hosts that produced errors or warnings. The template and host list are
+// loaded once, so the cost does not grow with per-host lookups.
+func (s *agentHostService) ListIncompatibleAgents(ctx context.Context, templateID int64) ([]AgentCompatInfo, error) {
+	if templateID <= 0 {
+		return nil, ErrBadRequest
+	}
+	tpl, err := s.configTemplates.FindByID(ctx, templateID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("find config template: %w", err)
+	}
+	hosts, err := s.agentHosts.ListAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list agent hosts: %w", err)
+	}
+
+	result := make([]AgentCompatInfo, 0)
+	for _, host := range hosts {
+		if host == nil {
+			continue
+		}
+		compat, gaps := s.evaluateTemplateCompatibility(host, tpl)
+		if compat.Compatible && len(compat.Warnings) == 0 && len(compat.Errors) == 0 {
+			continue
+		}
+		result = append(result, AgentCompatInfo{
+			AgentHostID:         host.ID,
+			Name:                host.Name,
+			Host:                host.Host,
+			Status:              host.Status,
+			CoreVersion:         host.CoreVersion,
+			RequiredVersion:     tpl.MinVersion,
+			VersionMismatch:     gaps.versionMismatch,
+			MissingCapabilities: gaps.missingCapabilities,
+			Compatible:          compat.Compatible,
+			Warnings:            compat.Warnings,
+			Errors:              compat.Errors,
+		})
+	}
+	return result, nil
+}
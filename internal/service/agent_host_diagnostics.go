@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+// AgentDiagnostics is the self-diagnostics bundle reported by an agent.
+type AgentDiagnostics struct {
+	CoreType              string `json:"core_type"`
+	CoreVersion           string `json:"core_version"`
+	AgentVersion          string `json:"agent_version"`
+	InitSystem            string `json:"init_system"`
+	ConfigValid           bool   `json:"config_valid"`
+	ConfigValidationError string `json:"config_validation_error,omitempty"`
+	LastApplyError        string `json:"last_apply_error,omitempty"`
+	LastApplyErrorAt      int64  `json:"last_apply_error_at,omitempty"`
+	ConfigDir             string `json:"config_dir"`
+	ConfigDirFreeBytes    uint64 `json:"config_dir_free_bytes"`
+	ConfigDirTotalBytes   uint64 `json:"config_dir_total_bytes"`
+	OpenFDs               int64  `json:"open_fds"`
+	MaxFDs                int64  `json:"max_fds"`
+	CollectedAt           int64  `json:"collected_at"`
+	ReceivedAt            int64  `json:"received_at,omitempty"`
+}
+
+// RecordDiagnostics stores the latest diagnostics bundle for an agent host.
+// Error texts are redacted again on the panel side in case an older agent sent them raw.
+func (s *agentHostService) RecordDiagnostics(ctx context.Context, agentHostID int64, diag *AgentDiagnostics) error {
+	if s.diagnostics == nil || diag == nil {
+		return nil
+	}
+	if agentHostID <= 0 {
+		return fmt.Errorf("%w: agent_host_id is required / 缺少 agent_host_id", ErrBadRequest)
+	}
+	stored := *diag
+	stored.ConfigValidationError = sanitizeSensitiveText(stored.ConfigValidationError)
+	stored.LastApplyError = sanitizeSensitiveText(stored.LastApplyError)
+	stored.ReceivedAt = 0
+	payload, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+	return s.diagnostics.Upsert(ctx, &repository.AgentHostDiagnostics{
+		AgentHostID: agentHostID,
+		Payload:     payload,
+		CollectedAt: stored.CollectedAt,
+		UpdatedAt:   time.Now().Unix(),
+	})
+}
+
+// GetDiagnostics returns the latest diagnostics bundle for an agent host.
+func (s *agentHostService) GetDiagnostics(ctx context.Context, agentHostID int64) (*AgentDiagnostics, error) {
+	if s.diagnostics == nil {
+		return nil, fmt.Errorf("agent diagnostics repository unavailable / 探针诊断仓库不可用")
+	}
+	if _, err := s.agentHosts.FindByID(ctx, agentHostID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	record, err := s.diagnostics.FindByAgentHostID(ctx, agentHostID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	var diag AgentDiagnostics
+	if err := json.Unmarshal(record.Payload, &diag); err != nil {
+		return nil, fmt.Errorf("decode agent diagnostics: %w", err)
+	}
+	diag.ReceivedAt = record.UpdatedAt
+	return &diag, nil
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+// 节点级同步/上报间隔的取值范围（秒），0 表示清除覆盖、沿用全局设置。
+const (
+	MinAgentHostIntervalSeconds = 5
+	MaxAgentHostIntervalSeconds = 3600
+)
+
+// SetIntervals 设置节点级同步/上报间隔，Agent 在下一次上报状态时收到新间隔并重置定时器。
+func (s *agentHostService) SetIntervals(ctx context.Context, agentID int64, syncSeconds, reportSeconds int) error {
+	if agentID <= 0 {
+		return fmt.Errorf("%w: agent_id is required / 缺少 agent_id", ErrBadRequest)
+	}
+	if !validAgentHostInterval(syncSeconds) || !validAgentHostInterval(reportSeconds) {
+		return fmt.Errorf("%w: interval must be 0 or between %d and %d seconds / 间隔须为 0 或 %d~%d 秒", ErrBadRequest, MinAgentHostIntervalSeconds, MaxAgentHostIntervalSeconds, MinAgentHostIntervalSeconds, MaxAgentHostIntervalSeconds)
+	}
+	host, err := s.agentHosts.FindByID(ctx, agentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to find agent host: %v / 获取探针节点失败: %w", err, err)
+	}
+	if host.SyncIntervalSeconds == syncSeconds && host.ReportIntervalSeconds == reportSeconds {
+		return nil
+	}
+	before := *host
+	if err := s.agentHosts.UpdateIntervals(ctx, agentID, syncSeconds, reportSeconds); err != nil {
+		return fmt.Errorf("failed to update intervals: %v / 更新上报间隔失败: %w", err, err)
+	}
+	host.SyncIntervalSeconds = syncSeconds
+	host.ReportIntervalSeconds = reportSeconds
+	recordAudit(ctx, s.audit, AuditEntry{Action: AuditActionUpdate, TargetType: AuditTargetAgentHost, TargetID: host.ID, Before: &before, After: host})
+	return nil
+}
+
+func validAgentHostInterval(seconds int) bool {
+	return seconds == 0 || (seconds >= MinAgentHostIntervalSeconds && seconds <= MaxAgentHostIntervalSeconds)
+}
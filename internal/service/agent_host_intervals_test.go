@@ -0,0 +1,16 @@
+package service
+
+import "testing"
+
+func TestValidAgentHostInterval(t *testing.T) {
+	for _, seconds := range []int{0, MinAgentHostIntervalSeconds, 60, MaxAgentHostIntervalSeconds} {
+		if !validAgentHostInterval(seconds) {
+			t.Fatalf("validAgentHostInterval(%d) = false, want true", seconds)
+		}
+	}
+	for _, seconds := range []int{-1, MinAgentHostIntervalSeconds - 1, MaxAgentHostIntervalSeconds + 1} {
+		if validAgentHostInterval(seconds) {
+			t.Fatalf("validAgentHostInterval(%d) = true, want false", seconds)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/notifier"
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+// Watched path alert settings. A threshold of 0 disables that check.
+const (
+	agentPathUsageAlertPercentSetting = "agent_path_usage_alert_percent"
+	agentPathUsageAlertMBSetting      = "agent_path_usage_alert_mb"
+
+	defaultAgentPathUsageAlertPercent = 90
+)
+
+// AgentPathUsage is the disk usage of a directory watched by an agent.
+type AgentPathUsage struct {
+	Path         string  `json:"path"`
+	Exists       bool    `json:"exists"`
+	UsedBytes    uint64  `json:"used_bytes"`
+	Truncated    bool    `json:"truncated"`
+	FSTotalBytes uint64  `json:"fs_total_bytes"`
+	FSUsedBytes  uint64  `json:"fs_used_bytes"`
+	FSFreeBytes  uint64  `json:"fs_free_bytes"`
+	FSUsedPct    float64 `json:"fs_used_percent"`
+	Alerting     bool    `json:"alerting"`
+	UpdatedAt    int64   `json:"updated_at,omitempty"`
+}
+
+// RecordPathUsage stores the latest watched path usage of an agent host and sends an
+// alert to the Telegram admin chat when a path first crosses a configured threshold.
+// The alert is not repeated until the path drops back below the threshold.
+func (s *agentHostService) RecordPathUsage(ctx context.Context, agentHostID int64, usages []AgentPathUsage) error {
+	if s.pathUsage == nil {
+		return nil
+	}
+	if agentHostID <= 0 {
+		return fmt.Errorf("%w: agent_host_id is required / 缺少 agent_host_id", ErrBadRequest)
+	}
+
+	previous := make(map[string]bool)
+	if existing, err := s.pathUsage.ListByAgentHostID(ctx, agentHostID); err == nil {
+		for _, item := range existing {
+			previous[item.Path] = item.Alerting
+		}
+	}
+
+	percentLimit := s.pathUsageIntSetting(ctx, agentPathUsageAlertPercentSetting, defaultAgentPathUsageAlertPercent)
+	sizeLimitMB := s.pathUsageIntSetting(ctx, agentPathUsageAlertMBSetting, 0)
+
+	now := time.Now().Unix()
+	records := make([]*repository.AgentHostPathUsage, 0, len(usages))
+	var crossed []string
+	for _, usage := range usages {
+		path := strings.TrimSpace(usage.Path)
+		if path == "" {
+			continue
+		}
+		reason := pathUsageAlertReason(usage, percentLimit, sizeLimitMB)
+		alerting := reason != ""
+		if alerting && !previous[path] {
+			crossed = append(crossed, fmt.Sprintf("%s: %s", path, reason))
+		}
+		records = append(records, &repository.AgentHostPathUsage{
+			AgentHostID:  agentHostID,
+			Path:         path,
+			Exists:       usage.Exists,
+			UsedBytes:    usage.UsedBytes,
+			Truncated:    usage.Truncated,
+			FSTotalBytes: usage.FSTotalBytes,
+			FSUsedBytes:  usage.FSUsedBytes,
+			FSFreeBytes:  usage.FSFreeBytes,
+			Alerting:     alerting,
+			UpdatedAt:    now,
+		})
+	}
+	if err := s.pathUsage.ReplaceForHost(ctx, agentHostID, records); err != nil {
+		return err
+	}
+	if len(crossed) > 0 {
+		s.notifyPathUsageAlert(ctx, agentHostID, crossed)
+	}
+	return nil
+}
+
+// GetPathUsage returns the latest watched path usage of an agent host.
+func (s *agentHostService) GetPathUsage(ctx context.Context, agentHostID int64) ([]AgentPathUsage, error) {
+	if s.pathUsage == nil {
+		return nil, fmt.Errorf("agent path usage repository unavailable / 探针目录占用仓库不可用")
+	}
+	if _, err := s.agentHosts.FindByID(ctx, agentHostID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	records, err := s.pathUsage.ListByAgentHostID(ctx, agentHostID)
+	if err != nil {
+		return nil, err
+	}
+	usages := make([]AgentPathUsage, 0, len(records))
+	for _, record := range records {
+		usages = append(usages, AgentPathUsage{
+			Path:         record.Path,
+			Exists:       record.Exists,
+			UsedBytes:    record.UsedBytes,
+			Truncated:    record.Truncated,
+			FSTotalBytes: record.FSTotalBytes,
+			FSUsedBytes:  record.FSUsedBytes,
+			FSFreeBytes:  record.FSFreeBytes,
+			FSUsedPct:    pathUsageFSPercent(record.FSUsedBytes, record.FSTotalBytes),
+			Alerting:     record.Alerting,
+			UpdatedAt:    record.UpdatedAt,
+		})
+	}
+	return usages, nil
+}
+
+// pathUsageAlertReason returns why a path is over threshold, or "" when it is not.
+// Missing paths never alert since they report zero usage.
+func pathUsageAlertReason(usage AgentPathUsage, percentLimit, sizeLimitMB int) string {
+	if !usage.Exists {
+		return ""
+	}
+	if percentLimit > 0 {
+		if pct := pathUsageFSPercent(usage.FSUsedBytes, usage.FSTotalBytes); pct >= float64(percentLimit) {
+			return fmt.Sprintf("filesystem %.1f%% used (threshold %d%%)", pct, percentLimit)
+		}
+	}
+	if sizeLimitMB > 0 && usage.UsedBytes >= uint64(sizeLimitMB)*1024*1024 {
+		return fmt.Sprintf("directory size %d MB (threshold %d MB)", usage.UsedBytes/(1024*1024), sizeLimitMB)
+	}
+	return ""
+}
+
+func pathUsageFSPercent(used, total uint64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(used) / float64(total) * 100
+}
+
+func (s *agentHostService) notifyPathUsageAlert(ctx context.Context, agentHostID int64, crossed []string) {
+	name := strconv.FormatInt(agentHostID, 10)
+	if host, err := s.agentHosts.FindByID(ctx, agentHostID); err == nil && host != nil {
+		if host.Name != "" {
+			name = host.Name
+		} else if host.Host != "" {
+			name = host.Host
+		}
+	}
+	message := fmt.Sprintf("Node %s (ID: %d) watched path usage over threshold:\n%s", name, agentHostID, strings.Join(crossed, "\n"))
+	if s.logger != nil {
+		s.logger.Warn("agent watched path over threshold", "agent_host_id", agentHostID, "paths", crossed)
+	}
+	if s.notifications == nil {
+		return
+	}
+	adminChat := ""
+	if setting, err := s.settings.Get(ctx, telegramAdminIDSetting); err == nil && setting != nil {
+		adminChat = setting.Value
+	}
+	for _, chatID := range strings.Split(adminChat, ",") {
+		if chatID = strings.TrimSpace(chatID); chatID != "" {
+			s.notifications.EnqueueTelegram(notifier.TelegramRequest{ChatID: chatID, Message: message})
+		}
+	}
+}
+
+func (s *agentHostService) pathUsageIntSetting(ctx context.Context, key string, def int) int {
+	if s.settings == nil {
+		return def
+	}
+	setting, err := s.settings.Get(ctx, key)
+	if err != nil || setting == nil {
+		return def
+	}
+	value, err := strconv.Atoi(strings.TrimSpace(setting.Value))
+	if err != nil || value < 0 {
+		return def
+	}
+	return value
+}
@@ -0,0 +1,151 @@
+// 文件路径: internal/service/agent_host_preview.go
+// 模块说明: 这是 internal 模块里的 agent_host_preview 逻辑，下面的注释会用非常通俗的中文帮你理解每一步。
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+	"github.com/creamcroissant/xboard/internal/support/security"
+	"github.com/creamcroissant/xboard/internal/template"
+)
+
+// previewMaskValue 是预览输出中替换敏感字段的占位符。
+const previewMaskValue = "******"
+
+// previewUserListKeys 是承载用户凭据的数组字段，数组内的字符串一律打码。
+var previewUserListKeys = map[string]struct{}{
+	"users":   {},
+	"clients": {},
+}
+
+// previewUserKeepKeys 是用户条目里不含凭据、预览时保留原值的字段。
+var previewUserKeepKeys = map[string]struct{}{
+	"flow": {},
+}
+
+// PreviewConfig 使用指定模板为探针渲染一份配置，但不做任何持久化。
+// 返回的兼容性结果会附带能力过滤、版本与校验产生的告警，方便管理员反复调整模板。
+func (s *agentHostService) PreviewConfig(ctx context.Context, agentID, templateID int64) ([]byte, *TemplateCompatibilityResult, error) {
+	if agentID <= 0 || templateID <= 0 {
+		return nil, nil, fmt.Errorf("%w: agent_id and template_id are required / 缺少 agent_id 或 template_id", ErrBadRequest)
+	}
+
+	host, err := s.agentHosts.FindByID(ctx, agentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to find agent host: %v / 获取探针节点失败: %w", err, err)
+	}
+	tpl, err := s.configTemplates.FindByID(ctx, templateID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to find config template: %v / 获取配置模板失败: %w", err, err)
+	}
+
+	// 兼容性检查本身是只读的，直接复用
+	result, err := s.CheckTemplateCompatibility(ctx, agentID, templateID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !tpl.IsValid {
+		return nil, result, nil
+	}
+
+	// 以下步骤与 GenerateConfig 一致，只是使用传入的模板，并把告警收集起来而不是写日志
+	templateCtx, err := s.buildTemplateContext(ctx, host, tpl)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build template context: %v / 构建模板上下文失败: %w", err, err)
+	}
+	filter := template.NewCapabilityFilter(s.parseAgentCapabilities(host))
+	filteredCtx, warnings := filter.FilterContext(templateCtx)
+	result.Warnings = append(result.Warnings, warnings...)
+
+	configJSON, err := template.NewEngine().Render(tpl.Content, filteredCtx)
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("Template render error: %v / 模板渲染失败: %v", err, err))
+		result.Compatible = false
+		return nil, result, nil
+	}
+
+	issues := template.NewValidator().ValidateFinalConfig(configJSON, tpl.Type)
+	result.Issues = append(result.Issues, issues...)
+	if template.HasIssueErrors(issues) {
+		result.Errors = append(result.Errors, template.FlattenIssues(issues, template.SeverityError)...)
+		result.Compatible = false
+	}
+	result.Warnings = append(result.Warnings, template.FlattenIssues(issues, template.SeverityWarning)...)
+
+	return configJSON, result, nil
+}
+
+// MaskPreviewConfig 对预览配置中的用户凭据与密钥字段打码，并输出缩进后的 JSON。
+func MaskPreviewConfig(configJSON []byte) ([]byte, error) {
+	var parsed any
+	if err := json.Unmarshal(configJSON, &parsed); err != nil {
+		return nil, fmt.Errorf("parse preview config: %v / 解析预览配置失败: %w", err, err)
+	}
+	return json.MarshalIndent(maskPreviewValue(parsed, false), "", "  ")
+}
+
+// maskPreviewValue 递归遍历配置；inUserList 表示当前位于用户数组内部。
+func maskPreviewValue(value any, inUserList bool) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			lower := strings.ToLower(key)
+			if security.IsSecretConfigKey(lower) && isPreviewSecretLeaf(child) {
+				v[key] = maskPreviewLeaf(child)
+				continue
+			}
+			if _, keep := previewUserKeepKeys[lower]; keep && inUserList {
+				continue
+			}
+			_, isUserList := previewUserListKeys[lower]
+			v[key] = maskPreviewValue(child, inUserList || isUserList)
+		}
+		return v
+	case []any:
+		for i, child := range v {
+			v[i] = maskPreviewValue(child, inUserList)
+		}
+		return v
+	case string:
+		if inUserList && v != "" {
+			return previewMaskValue
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// isPreviewSecretLeaf 判断敏感字段的值是否需要整体打码：字符串与数组打码，
+// 数值等其它类型（如路由规则的数值 id）保留，对象继续递归。
+func isPreviewSecretLeaf(value any) bool {
+	switch value.(type) {
+	case string, []any:
+		return true
+	default:
+		return false
+	}
+}
+
+// maskPreviewLeaf 将敏感字段整体替换，数组保留长度以便管理员核对数量。
+func maskPreviewLeaf(value any) any {
+	if list, ok := value.([]any); ok {
+		masked := make([]any, len(list))
+		for i := range list {
+			masked[i] = previewMaskValue
+		}
+		return masked
+	}
+	return previewMaskValue
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMaskPreviewConfigMasksXrayClientIDs(t *testing.T) {
+	raw := []byte(`{
+		"inbounds": [{
+			"protocol": "vless",
+			"settings": {"id": "11111111-2222-3333-4444-555555555555", "clients": [{"id": "aaaa", "flow": "xtls-rprx-vision"}]},
+			"streamSettings": {"realitySettings": {"privateKey": "pk", "shortIds": ["ab", "cd"]}}
+		}],
+		"routing": {"rules": [{"id": 3, "outboundTag": "direct"}]}
+	}`)
+	masked, err := MaskPreviewConfig(raw)
+	if err != nil {
+		t.Fatalf("MaskPreviewConfig() error = %v", err)
+	}
+	var got struct {
+		Inbounds []struct {
+			Settings struct {
+				ID      string `json:"id"`
+				Clients []struct {
+					ID   string `json:"id"`
+					Flow string `json:"flow"`
+				} `json:"clients"`
+			} `json:"settings"`
+			StreamSettings struct {
+				RealitySettings struct {
+					PrivateKey string   `json:"privateKey"`
+					ShortIDs   []string `json:"shortIds"`
+				} `json:"realitySettings"`
+			} `json:"streamSettings"`
+		} `json:"inbounds"`
+		Routing struct {
+			Rules []struct {
+				ID int `json:"id"`
+			} `json:"rules"`
+		} `json:"routing"`
+	}
+	if err := json.Unmarshal(masked, &got); err != nil {
+		t.Fatalf("unmarshal masked config: %v", err)
+	}
+	inbound := got.Inbounds[0]
+	if inbound.Settings.ID != previewMaskValue || inbound.Settings.Clients[0].ID != previewMaskValue {
+		t.Fatalf("xray ids not masked: %+v", inbound.Settings)
+	}
+	if inbound.Settings.Clients[0].Flow != "xtls-rprx-vision" {
+		t.Fatalf("flow = %q, want kept", inbound.Settings.Clients[0].Flow)
+	}
+	reality := inbound.StreamSettings.RealitySettings
+	if reality.PrivateKey != previewMaskValue || len(reality.ShortIDs) != 2 || reality.ShortIDs[0] != previewMaskValue {
+		t.Fatalf("reality secrets not masked: %+v", reality)
+	}
+	if got.Routing.Rules[0].ID != 3 {
+		t.Fatalf("numeric rule id = %d, want kept", got.Routing.Rules[0].ID)
+	}
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+const (
+	// agentHostRefreshDefaultConcurrency 为批量刷新默认并发数。
+	agentHostRefreshDefaultConcurrency = 8
+	// agentHostRefreshMaxConcurrency 限制单次批量刷新的最大并发数，避免压垮数据库。
+	agentHostRefreshMaxConcurrency = 64
+	// agentHostRefreshDefaultTimeout 为批量刷新默认总超时。
+	agentHostRefreshDefaultTimeout = 30 * time.Second
+	// agentHostRefreshMaxTimeout 限制单次批量刷新的最长耗时。
+	agentHostRefreshMaxTimeout = 5 * time.Minute
+)
+
+// AgentHostRefreshOptions 控制批量刷新的并发与总超时，零值使用默认值。
+type AgentHostRefreshOptions struct {
+	Concurrency int
+	Timeout     time.Duration
+	// Progress 在每个节点处理完成后串行调用，用于流式推送进度；可为 nil。
+	Progress func(AgentHostRefreshResult)
+}
+
+// AgentHostRefreshResult 是单个节点的刷新结果。
+// Success 表示 resync 指令已送达在线 Agent；离线节点的指令会暂存到重连后补发，但仍计为失败。
+type AgentHostRefreshResult struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	Status     int    `json:"status"`
+	State      string `json:"state"`
+	Success    bool   `json:"success"`
+	Skipped    bool   `json:"skipped,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// AgentHostRefreshSummary 汇总一次批量刷新，Results 顺序与节点列表一致。
+type AgentHostRefreshSummary struct {
+	Total      int                      `json:"total"`
+	Succeeded  int                      `json:"succeeded"`
+	Failed     int                      `json:"failed"`
+	Skipped    int                      `json:"skipped"`
+	TimedOut   bool                     `json:"timed_out"`
+	DurationMs int64                    `json:"duration_ms"`
+	Results    []AgentHostRefreshResult `json:"results"`
+}
+
+// RefreshAll 以有限并发向所有节点下发 resync 指令；单个节点失败不会中断整体，超时后未处理的节点记为失败。
+func (s *agentHostService) RefreshAll(ctx context.Context, opts AgentHostRefreshOptions) (*AgentHostRefreshSummary, error) {
+	hosts, err := s.agentHosts.ListAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list agent hosts: %v / 获取探针节点列表失败: %w", err, err)
+	}
+	return runAgentHostRefresh(ctx, hosts, opts, s.refreshHost), nil
+}
+
+// refreshHost 重新读取节点并下发 resync；维护中的节点暂停配置下发，直接跳过。
+func (s *agentHostService) refreshHost(ctx context.Context, host *repository.AgentHost) AgentHostRefreshResult {
+	result := AgentHostRefreshResult{ID: host.ID, Name: host.Name, Status: host.Status}
+	current, err := s.agentHosts.FindByID(ctx, host.ID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			result.Error = "agent host not found / 探针节点不存在"
+		} else {
+			result.Error = fmt.Sprintf("find agent host: %v / 获取探针节点失败", err)
+		}
+		return result
+	}
+	result.Name = current.Name
+	result.Status = current.Status
+	result.State = current.DisplayState(time.Now().Unix())
+	if current.Maintenance {
+		result.Skipped = true
+		return result
+	}
+	if !s.publishStreamCommand(ctx, current.ID, AgentStreamCommand{Command: AgentStreamCommandResync}) {
+		result.Error = "agent not connected, resync queued / Agent 未连接，resync 已排队"
+		return result
+	}
+	result.Success = true
+	return result
+}
+
+// runAgentHostRefresh 用固定大小的 worker 池执行 refresh，并汇总结果。
+func runAgentHostRefresh(ctx context.Context, hosts []*repository.AgentHost, opts AgentHostRefreshOptions, refresh func(context.Context, *repository.AgentHost) AgentHostRefreshResult) *AgentHostRefreshSummary {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = agentHostRefreshDefaultConcurrency
+	}
+	if concurrency > agentHostRefreshMaxConcurrency {
+		concurrency = agentHostRefreshMaxConcurrency
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = agentHostRefreshDefaultTimeout
+	}
+	if timeout > agentHostRefreshMaxTimeout {
+		timeout = agentHostRefreshMaxTimeout
+	}
+
+	started := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	summary := &AgentHostRefreshSummary{Total: len(hosts), Results: make([]AgentHostRefreshResult, len(hosts))}
+	var mu sync.Mutex
+	finish := func(index int, result AgentHostRefreshResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		summary.Results[index] = result
+		switch {
+		case result.Skipped:
+			summary.Skipped++
+		case result.Success:
+			summary.Succeeded++
+		default:
+			summary.Failed++
+		}
+		if opts.Progress != nil {
+			opts.Progress(result)
+		}
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency && i < len(hosts); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				host := hosts[index]
+				hostStarted := time.Now()
+				var result AgentHostRefreshResult
+				if ctx.Err() != nil {
+					result = AgentHostRefreshResult{ID: host.ID, Name: host.Name, Status: host.Status, Error: "refresh timed out / 刷新超时"}
+				} else {
+					result = refresh(ctx, host)
+				}
+				result.DurationMs = time.Since(hostStarted).Milliseconds()
+				finish(index, result)
+			}
+		}()
+	}
+	for index := range hosts {
+		jobs <- index
+	}
+	close(jobs)
+	wg.Wait()
+
+	summary.TimedOut = errors.Is(ctx.Err(), context.DeadlineExceeded)
+	summary.DurationMs = time.Since(started).Milliseconds()
+	return summary
+}
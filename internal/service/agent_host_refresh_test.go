@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+func TestRunAgentHostRefreshAggregatesFailures(t *testing.T) {
+	hosts := []*repository.AgentHost{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}}
+	progress := 0
+	summary := runAgentHostRefresh(context.Background(), hosts, AgentHostRefreshOptions{
+		Concurrency: 2,
+		Progress:    func(AgentHostRefreshResult) { progress++ },
+	}, func(_ context.Context, host *repository.AgentHost) AgentHostRefreshResult {
+		switch host.ID {
+		case 2:
+			return AgentHostRefreshResult{ID: host.ID, Error: "offline"}
+		case 3:
+			return AgentHostRefreshResult{ID: host.ID, Skipped: true}
+		}
+		return AgentHostRefreshResult{ID: host.ID, Success: true}
+	})
+	if summary.Total != 4 || summary.Succeeded != 2 || summary.Failed != 1 || summary.Skipped != 1 || progress != 4 {
+		t.Fatalf("summary = %+v, progress = %d", summary, progress)
+	}
+	for i, result := range summary.Results {
+		if result.ID != hosts[i].ID {
+			t.Fatalf("results[%d].ID = %d, want host order kept", i, result.ID)
+		}
+	}
+}
+
+func TestRunAgentHostRefreshTimeoutMarksRemainingFailed(t *testing.T) {
+	hosts := []*repository.AgentHost{{ID: 1}, {ID: 2}, {ID: 3}}
+	summary := runAgentHostRefresh(context.Background(), hosts, AgentHostRefreshOptions{
+		Concurrency: 1,
+		Timeout:     20 * time.Millisecond,
+	}, func(ctx context.Context, host *repository.AgentHost) AgentHostRefreshResult {
+		<-ctx.Done()
+		return AgentHostRefreshResult{ID: host.ID, Error: ctx.Err().Error()}
+	})
+	if !summary.TimedOut || summary.Failed != 3 {
+		t.Fatalf("summary = %+v, want all failed after timeout", summary)
+	}
+}
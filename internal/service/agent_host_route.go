@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+	"github.com/creamcroissant/xboard/internal/template"
+)
+
+// buildTemplateRoute renders the server routes bound to this host's servers into route.rules.
+// Each rule is scoped to the inbounds of the servers referencing it and ordered by priority.
+// Returns nil when no routes apply so templates can omit the section.
+func (s *agentHostService) buildTemplateRoute(ctx context.Context, routeInbounds map[int64][]string) (*template.RouteConfig, error) {
+	if s.routes == nil || len(routeInbounds) == 0 {
+		return nil, nil
+	}
+	ids := make([]int64, 0, len(routeInbounds))
+	for id := range routeInbounds {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	routes, err := s.routes.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("fetch server routes: %v / 获取路由规则失败: %w", err, err)
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Priority != routes[j].Priority {
+			return routes[i].Priority < routes[j].Priority
+		}
+		return routes[i].ID < routes[j].ID
+	})
+
+	rules := make([]template.RouteRule, 0, len(routes))
+	for _, route := range routes {
+		if route == nil {
+			continue
+		}
+		rule, ok := templateRouteRule(route, routeInbounds[route.ID])
+		if !ok {
+			slog.Warn("Skipping unsupported server route", "route_id", route.ID, "match_type", route.MatchType, "action", route.Action)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	return &template.RouteConfig{Rules: rules}, nil
+}
+
+// templateRouteRule maps a stored route to a sing-box style rule.
+// Legacy routes with actions outside block/direct/proxy are reported as unsupported.
+func templateRouteRule(route *repository.ServerRoute, inboundTags []string) (template.RouteRule, bool) {
+	var values []string
+	if err := json.Unmarshal(route.Match, &values); err != nil || len(values) == 0 {
+		return template.RouteRule{}, false
+	}
+	rule := template.RouteRule{Inbound: inboundTags}
+	switch route.Action {
+	case RouteActionBlock:
+		rule.Outbound = "block"
+	case RouteActionDirect:
+		rule.Outbound = "direct"
+	case RouteActionProxy:
+		if route.ActionValue == "" {
+			return template.RouteRule{}, false
+		}
+		rule.Outbound = route.ActionValue
+	default:
+		return template.RouteRule{}, false
+	}
+	switch route.MatchType {
+	case RouteMatchDomain, "":
+		rule.Domain = values
+	case RouteMatchDomainSuffix:
+		rule.DomainSuffix = values
+	case RouteMatchDomainKeyword:
+		rule.DomainKeyword = values
+	case RouteMatchDomainRegex:
+		rule.DomainRegex = values
+	case RouteMatchIP:
+		rule.IPCIDR = values
+	case RouteMatchGeosite:
+		rule.Geosite = values
+	case RouteMatchGeoIP:
+		rule.GeoIP = values
+	default:
+		return template.RouteRule{}, false
+	}
+	return rule, true
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+// AgentStreamCommandRotateToken 下发轮换后的新令牌，Agent 保存到配置文件后切换使用。
+const AgentStreamCommandRotateToken = "rotate_token"
+
+const (
+	// AgentTokenRotationGrace 为未指定宽限期时旧令牌仍可认证的时长，覆盖 Agent 短暂离线后手工更新令牌的窗口。
+	AgentTokenRotationGrace = 24 * time.Hour
+	// AgentTokenRotationMaxGrace 为可配置宽限期的上限。
+	AgentTokenRotationMaxGrace = 7 * 24 * time.Hour
+)
+
+// AgentTokenRotationInput 为令牌轮换参数。
+// GraceSeconds 为空时使用 AgentTokenRotationGrace，为 0 时旧令牌立即失效。
+type AgentTokenRotationInput struct {
+	GraceSeconds *int64 `json:"previous_token_grace_seconds"`
+}
+
+// AgentTokenRotation 是一次令牌轮换的结果。
+type AgentTokenRotation struct {
+	AgentHostID            int64  `json:"agent_host_id"`
+	Token                  string `json:"token"`
+	PreviousTokenExpiresAt int64  `json:"previous_token_expires_at"`
+	// Delivered 表示新令牌是否已推送给轮换前认证的在线连接；为 false 时需手工更新 Agent 配置。
+	Delivered bool `json:"delivered"`
+}
+
+// agentTokenRotationPayload 是 rotate_token 指令的载荷。
+type agentTokenRotationPayload struct {
+	HostToken              string `json:"host_token"`
+	PreviousTokenExpiresAt int64  `json:"previous_token_expires_at"`
+}
+
+// RotateToken 为节点生成新令牌，旧令牌在宽限期内仍可认证。
+// 新令牌只经 StatusStream 推送给轮换前以旧令牌认证的在线连接，不暂存补发：
+// 宽限期内用旧令牌新建的连接拿不到新令牌，避免泄露的旧令牌换取新令牌。
+func (s *agentHostService) RotateToken(ctx context.Context, agentID int64, input AgentTokenRotationInput) (*AgentTokenRotation, error) {
+	if agentID <= 0 {
+		return nil, fmt.Errorf("%w: agent_id is required / 缺少 agent_id", ErrBadRequest)
+	}
+	grace := AgentTokenRotationGrace
+	if input.GraceSeconds != nil {
+		grace = time.Duration(*input.GraceSeconds) * time.Second
+		if *input.GraceSeconds < 0 || grace > AgentTokenRotationMaxGrace {
+			return nil, fmt.Errorf("%w: previous_token_grace_seconds must be between 0 and %d / 旧令牌宽限期超出范围", ErrBadRequest, int64(AgentTokenRotationMaxGrace/time.Second))
+		}
+	}
+	host, err := s.agentHosts.FindByID(ctx, agentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to find agent host: %v / 获取探针节点失败: %w", err, err)
+	}
+	token, err := generateAgentHostToken()
+	if err != nil {
+		return nil, err
+	}
+	previousToken := host.Token
+	var expiresAt int64
+	if grace > 0 {
+		expiresAt = time.Now().Add(grace).Unix()
+	} else {
+		previousToken = ""
+	}
+	if err := s.agentHosts.RotateToken(ctx, host.ID, token, previousToken, expiresAt); err != nil {
+		return nil, fmt.Errorf("failed to rotate token: %v / 轮换令牌失败: %w", err, err)
+	}
+	before := *host
+	host.Token = token
+	recordAudit(ctx, s.audit, AuditEntry{Action: AuditActionRotateToken, TargetType: AuditTargetAgentHost, TargetID: host.ID, Before: &before, After: host})
+
+	delivered := s.publishRotatedToken(ctx, host.ID, before.Token, token, expiresAt)
+	return &AgentTokenRotation{AgentHostID: host.ID, Token: token, PreviousTokenExpiresAt: expiresAt, Delivered: delivered}, nil
+}
+
+// GetByToken 按当前令牌认证，未命中时接受宽限期内的旧令牌。
+// 返回的节点记录携带当前令牌，因此旧令牌建立的连接不会收到 rotate_token。
+func (s *agentHostService) GetByToken(ctx context.Context, token string) (*repository.AgentHost, error) {
+	host, err := s.agentHosts.FindByToken(ctx, token)
+	if err == nil || !errors.Is(err, repository.ErrNotFound) || token == "" {
+		return host, err
+	}
+	previous, prevErr := s.agentHosts.FindByPreviousToken(ctx, token, time.Now().Unix())
+	if prevErr != nil {
+		return nil, err
+	}
+	return previous, nil
+}
+
+// publishRotatedToken 把新令牌推送给以 previousToken 认证的在线连接，返回是否送达。
+func (s *agentHostService) publishRotatedToken(ctx context.Context, agentID int64, previousToken, token string, previousExpiresAt int64) bool {
+	if previousToken == "" {
+		return false
+	}
+	payload, err := json.Marshal(agentTokenRotationPayload{HostToken: token, PreviousTokenExpiresAt: previousExpiresAt})
+	if err != nil {
+		return false
+	}
+	return s.publishStreamCommand(ctx, agentID, AgentStreamCommand{Command: AgentStreamCommandRotateToken, Payload: payload, audience: previousToken})
+}
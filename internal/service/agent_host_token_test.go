@@ -0,0 +1,46 @@
+package service
+
+import "testing"
+
+func TestStreamCommandBrokerKeepsLatestPendingCommand(t *testing.T) {
+	broker := newAgentStreamCommandBroker()
+	broker.publish(1, AgentStreamCommand{Command: AgentStreamCommandResync, RequestID: "old"})
+	broker.publish(1, AgentStreamCommand{Command: AgentStreamCommandRedetectCapabilities})
+	broker.publish(1, AgentStreamCommand{Command: AgentStreamCommandResync, RequestID: "new"})
+
+	ch, closeFn := broker.subscribe(1, "token")
+	defer closeFn()
+	if got := len(ch); got != 2 {
+		t.Fatalf("replayed %d commands, want 2", got)
+	}
+	if cmd := <-ch; cmd.Command != AgentStreamCommandResync || cmd.RequestID != "new" {
+		t.Fatalf("first replayed command = %s %q, want resync from latest request", cmd.Command, cmd.RequestID)
+	}
+}
+
+func TestStreamCommandBrokerDeliversRotatedTokenOnlyToPreRotationStream(t *testing.T) {
+	broker := newAgentStreamCommandBroker()
+	rotate := AgentStreamCommand{Command: AgentStreamCommandRotateToken, Payload: []byte("new-token"), audience: "old-token"}
+
+	// 离线时不暂存：之后用旧令牌重连的连接拿不到新令牌
+	if broker.publish(1, rotate) {
+		t.Fatal("rotate_token reported delivered without subscribers")
+	}
+	late, closeLate := broker.subscribe(1, "new-token")
+	defer closeLate()
+	if got := len(late); got != 0 {
+		t.Fatalf("replayed %d commands to a later stream, want 0", got)
+	}
+
+	preRotation, closePre := broker.subscribe(1, "old-token")
+	defer closePre()
+	if !broker.publish(1, rotate) {
+		t.Fatal("rotate_token not delivered to the stream authenticated with the old token")
+	}
+	if got := len(preRotation); got != 1 {
+		t.Fatalf("pre-rotation stream received %d commands, want 1", got)
+	}
+	if got := len(late); got != 0 {
+		t.Fatalf("stream authenticated with another token received %d commands, want 0", got)
+	}
+}
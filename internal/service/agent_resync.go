@@ -0,0 +1,68 @@
+// 文件路径: internal/service/agent_resync.go
+// 模块说明: 这是 internal 模块里的 agent_resync 逻辑，下面的注释会用非常通俗的中文帮你理解每一步。
+package service
+
+import (
+	"context"
+	"log/slog"
+)
+
+// AgentResyncNotifier 在模板或用户变更后通知受影响的 Agent 立即同步，不必等待同步周期。
+// 通知是尽力而为的：查询失败只记日志，Agent 仍会在下一个同步周期拉到新配置。
+type AgentResyncNotifier interface {
+	// NotifyTemplateChanged 通知分配了该模板且跟随最新版本的 Agent。
+	NotifyTemplateChanged(ctx context.Context, templateID int64)
+	// NotifyGroupsChanged 通知承载这些权限组节点的 Agent，用于用户增删改。
+	NotifyGroupsChanged(ctx context.Context, groupIDs []int64)
+}
+
+func (s *agentHostService) NotifyTemplateChanged(ctx context.Context, templateID int64) {
+	if templateID <= 0 {
+		return
+	}
+	hosts, err := s.agentHosts.ListAll(ctx)
+	if err != nil {
+		slog.Warn("list agent hosts for template resync failed", "template_id", templateID, "error", err)
+		return
+	}
+	for _, host := range hosts {
+		// 固定了版本的节点不受模板编辑影响
+		if host == nil || host.TemplateID != templateID || host.TemplateVersion > 0 {
+			continue
+		}
+		s.publishStreamCommand(ctx, host.ID, AgentStreamCommand{Command: AgentStreamCommandResync})
+	}
+}
+
+func (s *agentHostService) NotifyGroupsChanged(ctx context.Context, groupIDs []int64) {
+	// 权限组为 0 的用户不会下发到任何节点
+	groups := make(map[int64]struct{}, len(groupIDs))
+	for _, id := range groupIDs {
+		if id > 0 {
+			groups[id] = struct{}{}
+		}
+	}
+	if len(groups) == 0 {
+		return
+	}
+	// 隐藏节点同样会下发到 Agent，因此不能用只返回可见节点的 FindByGroupIDs
+	servers, err := s.servers.ListAll(ctx)
+	if err != nil {
+		slog.Warn("list servers for user resync failed", "group_ids", groupIDs, "error", err)
+		return
+	}
+	notified := make(map[int64]struct{}, len(servers))
+	for _, srv := range servers {
+		if srv == nil || srv.AgentHostID <= 0 {
+			continue
+		}
+		if _, ok := groups[srv.GroupID]; !ok {
+			continue
+		}
+		if _, ok := notified[srv.AgentHostID]; ok {
+			continue
+		}
+		notified[srv.AgentHostID] = struct{}{}
+		s.publishStreamCommand(ctx, srv.AgentHostID, AgentStreamCommand{Command: AgentStreamCommandResync})
+	}
+}
@@ -0,0 +1,191 @@
+// 文件路径: internal/service/agent_stream_command.go
+// 模块说明: 这是 internal 模块里的 agent_stream_command 逻辑，下面的注释会用非常通俗的中文帮你理解每一步。
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+// AgentStreamCommandResync 要求 Agent 立即执行一次完整同步。
+const AgentStreamCommandResync = "resync"
+
+// AgentStreamCommandRedetectCapabilities 要求 Agent 在下次上报时重新检测核心能力。
+const AgentStreamCommandRedetectCapabilities = "redetect_capabilities"
+
+// AgentStreamCommandForceResync 要求 Agent 丢弃本地 ETag 与用户快照后完整同步，用于退出维护模式时覆盖手工修改。
+const AgentStreamCommandForceResync = "force_resync"
+
+// AgentStreamCommand 是通过 StatusStream 下发给 Agent 的即时指令。
+type AgentStreamCommand struct {
+	Command string
+	Payload []byte
+	// RequestID 为触发指令的 HTTP 请求 ID，仅用于面板侧日志关联，不下发给 Agent。
+	RequestID string
+	// audience 非空时只投递给以该令牌认证的在线订阅，且不暂存补发，用于携带敏感载荷的指令。
+	audience string
+}
+
+// agentStreamCommandBroker 维护每个 Agent 的流订阅；Agent 不在线时暂存指令，连上后补发。
+// 每个订阅记录建立时认证所用的令牌，供带 audience 的指令筛选接收方。
+type agentStreamCommandBroker struct {
+	mu          sync.Mutex
+	subscribers map[int64]map[chan AgentStreamCommand]string
+	pending     map[int64][]AgentStreamCommand
+}
+
+func newAgentStreamCommandBroker() *agentStreamCommandBroker {
+	return &agentStreamCommandBroker{
+		subscribers: make(map[int64]map[chan AgentStreamCommand]string),
+		pending:     make(map[int64][]AgentStreamCommand),
+	}
+}
+
+func (b *agentStreamCommandBroker) subscribe(agentID int64, token string) (<-chan AgentStreamCommand, func()) {
+	ch := make(chan AgentStreamCommand, 8)
+
+	b.mu.Lock()
+	if b.subscribers[agentID] == nil {
+		b.subscribers[agentID] = make(map[chan AgentStreamCommand]string)
+	}
+	b.subscribers[agentID][ch] = token
+	// 补发离线期间积压的指令
+	for _, cmd := range b.pending[agentID] {
+		select {
+		case ch <- cmd:
+		default:
+		}
+	}
+	delete(b.pending, agentID)
+	b.mu.Unlock()
+
+	var once sync.Once
+	closeFn := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			if subscribers := b.subscribers[agentID]; subscribers != nil {
+				delete(subscribers, ch)
+				if len(subscribers) == 0 {
+					delete(b.subscribers, agentID)
+				}
+			}
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+	return ch, closeFn
+}
+
+// publish 投递指令，返回是否有在线订阅者收到；无订阅者时按指令名去重后暂存，同名指令保留最新载荷。
+// 带 audience 的指令只发给令牌匹配的订阅，没有匹配的订阅时直接丢弃。
+func (b *agentStreamCommandBroker) publish(agentID int64, cmd AgentStreamCommand) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	subscribers := b.subscribers[agentID]
+	if cmd.audience != "" {
+		delivered := false
+		for ch, token := range subscribers {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(cmd.audience)) != 1 {
+				continue
+			}
+			select {
+			case ch <- cmd:
+				delivered = true
+			default:
+			}
+		}
+		return delivered
+	}
+	if len(subscribers) == 0 {
+		for i, existing := range b.pending[agentID] {
+			if existing.Command == cmd.Command {
+				b.pending[agentID][i] = cmd
+				return false
+			}
+		}
+		b.pending[agentID] = append(b.pending[agentID], cmd)
+		return false
+	}
+	for ch := range subscribers {
+		select {
+		case ch <- cmd:
+		default:
+		}
+	}
+	return true
+}
+
+// publishStreamCommand 附上触发请求的 ID 后投递指令，便于把 Agent 侧的下发日志与管理端请求对应起来。
+func (s *agentHostService) publishStreamCommand(ctx context.Context, agentID int64, cmd AgentStreamCommand) bool {
+	cmd.RequestID = chiMiddleware.GetReqID(ctx)
+	return s.streamCommands.publish(agentID, cmd)
+}
+
+// TriggerResync 为 Agent 排入一条 resync 指令，Agent 在线时会立即收到。
+func (s *agentHostService) TriggerResync(ctx context.Context, agentID int64) error {
+	if agentID <= 0 {
+		return fmt.Errorf("%w: agent_id is required / 缺少 agent_id", ErrBadRequest)
+	}
+	if _, err := s.agentHosts.FindByID(ctx, agentID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to find agent host: %v / 获取探针节点失败: %w", err, err)
+	}
+	s.publishStreamCommand(ctx, agentID, AgentStreamCommand{Command: AgentStreamCommandResync})
+	return nil
+}
+
+// TriggerCapabilityRedetect 通知 Agent 丢弃能力缓存，适用于推送核心升级之后。
+func (s *agentHostService) TriggerCapabilityRedetect(ctx context.Context, agentID int64) error {
+	if agentID <= 0 {
+		return fmt.Errorf("%w: agent_id is required / 缺少 agent_id", ErrBadRequest)
+	}
+	if _, err := s.agentHosts.FindByID(ctx, agentID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to find agent host: %v / 获取探针节点失败: %w", err, err)
+	}
+	s.publishStreamCommand(ctx, agentID, AgentStreamCommand{Command: AgentStreamCommandRedetectCapabilities})
+	return nil
+}
+
+// SetMaintenance 切换节点维护模式；退出维护时强制 Agent 重新拉取并应用面板配置。
+func (s *agentHostService) SetMaintenance(ctx context.Context, agentID int64, enabled bool) error {
+	if agentID <= 0 {
+		return fmt.Errorf("%w: agent_id is required / 缺少 agent_id", ErrBadRequest)
+	}
+	host, err := s.agentHosts.FindByID(ctx, agentID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to find agent host: %v / 获取探针节点失败: %w", err, err)
+	}
+	if host.Maintenance == enabled {
+		return nil
+	}
+	before := *host
+	host.Maintenance = enabled
+	if err := s.agentHosts.Update(ctx, host); err != nil {
+		return fmt.Errorf("failed to update maintenance mode: %v / 更新维护模式失败: %w", err, err)
+	}
+	recordAudit(ctx, s.audit, AuditEntry{Action: AuditActionUpdate, TargetType: AuditTargetAgentHost, TargetID: host.ID, Before: &before, After: host})
+	if !enabled {
+		s.publishStreamCommand(ctx, agentID, AgentStreamCommand{Command: AgentStreamCommandForceResync})
+	}
+	return nil
+}
+
+// SubscribeStreamCommands 订阅发给指定 Agent 的即时指令，token 为该连接认证时节点记录上的令牌；
+// 调用方负责执行返回的取消函数。
+func (s *agentHostService) SubscribeStreamCommands(agentID int64, token string) (<-chan AgentStreamCommand, func()) {
+	return s.streamCommands.subscribe(agentID, token)
+}
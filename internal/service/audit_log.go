@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/creamcroissant/xboard/internal/api/requestctx"
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+// 审计动作与目标类型。
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+	// AuditActionPreview 记录只读但涉及用户凭据的排障操作，例如订阅预览
+	AuditActionPreview = "preview"
+	// AuditActionRotateToken 记录节点令牌轮换，令牌值按敏感字段脱敏
+	AuditActionRotateToken = "rotate_token"
+	// AuditActionImpersonateStart/Stop 记录管理员模拟登录用户的开始与结束
+	AuditActionImpersonateStart = "impersonate_start"
+	AuditActionImpersonateStop  = "impersonate_stop"
+
+	AuditTargetUser        = "user"
+	AuditTargetPlan        = "plan"
+	AuditTargetServer      = "server"
+	AuditTargetServerRoute = "server_route"
+	AuditTargetAgentHost   = "agent_host"
+
+	auditLogDefaultLimit = 50
+	auditLogMaxLimit     = 200
+)
+
+// auditIgnoredFields 是每次保存都会变化、没有审计价值的字段。
+var auditIgnoredFields = map[string]struct{}{
+	"updated_at": {},
+}
+
+// AuditLogService 记录并查询管理员发起的变更。
+type AuditLogService interface {
+	// Record 追加一条审计记录，操作者取自上下文中的管理员身份；写入失败只记日志，不影响业务操作。
+	Record(ctx context.Context, entry AuditEntry)
+	List(ctx context.Context, input AuditLogListInput) ([]*repository.AuditLog, int64, error)
+}
+
+// AuditEntry 描述一次变更。Before 为 nil 表示创建，After 为 nil 表示删除。
+// ActorID 为 0 时取上下文中的管理员身份；模拟登录结束等以用户令牌发起的请求需显式指定。
+type AuditEntry struct {
+	ActorID    int64
+	Action     string
+	TargetType string
+	TargetID   int64
+	Before     any
+	After      any
+}
+
+// AuditLogListInput 控制审计日志查询条件，Since/Until 为 Unix 秒。
+type AuditLogListInput struct {
+	ActorID    int64
+	TargetType string
+	TargetID   int64
+	Since      int64
+	Until      int64
+	Limit      int
+	Offset     int
+}
+
+// AuditFieldChange 记录单个字段的新旧值。
+type AuditFieldChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+type auditLogService struct {
+	logs   repository.AuditLogRepository
+	logger *slog.Logger
+}
+
+// NewAuditLogService 创建审计日志服务。
+func NewAuditLogService(logs repository.AuditLogRepository, logger *slog.Logger) AuditLogService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &auditLogService{logs: logs, logger: logger}
+}
+
+func (s *auditLogService) Record(ctx context.Context, entry AuditEntry) {
+	if s == nil || s.logs == nil {
+		return
+	}
+	changes := AuditDiff(entry.Before, entry.After)
+	// 更新但没有任何有效字段变化时不落库
+	if entry.Action == AuditActionUpdate && len(changes) == 0 {
+		return
+	}
+	raw, err := json.Marshal(changes)
+	if err != nil {
+		s.logger.Warn("marshal audit changes failed", "error", err, "target_type", entry.TargetType, "target_id", entry.TargetID)
+		raw = []byte(`{}`)
+	}
+	actorID := entry.ActorID
+	if actorID <= 0 {
+		actorID = auditActorID(ctx)
+	}
+	log := &repository.AuditLog{
+		ActorID:    actorID,
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		Changes:    raw,
+		CreatedAt:  time.Now().Unix(),
+	}
+	if err := s.logs.Append(ctx, log); err != nil {
+		s.logger.Error("append audit log failed", "error", err, "action", entry.Action, "target_type", entry.TargetType, "target_id", entry.TargetID)
+	}
+}
+
+func (s *auditLogService) List(ctx context.Context, input AuditLogListInput) ([]*repository.AuditLog, int64, error) {
+	if s == nil || s.logs == nil {
+		return nil, 0, fmt.Errorf("audit log service not configured / 审计日志服务未配置")
+	}
+	if input.Until > 0 && input.Since > input.Until {
+		return nil, 0, fmt.Errorf("%w: since must not be after until / 起始时间不能晚于结束时间", ErrBadRequest)
+	}
+	filter := repository.AuditLogFilter{
+		Since:  input.Since,
+		Until:  input.Until,
+		Limit:  input.Limit,
+		Offset: input.Offset,
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = auditLogDefaultLimit
+	}
+	if filter.Limit > auditLogMaxLimit {
+		filter.Limit = auditLogMaxLimit
+	}
+	if input.ActorID > 0 {
+		actorID := input.ActorID
+		filter.ActorID = &actorID
+	}
+	if targetType := strings.TrimSpace(input.TargetType); targetType != "" {
+		filter.TargetType = &targetType
+	}
+	if input.TargetID > 0 {
+		targetID := input.TargetID
+		filter.TargetID = &targetID
+	}
+	logs, err := s.logs.List(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list audit logs: %v / 查询审计日志失败: %w", err, err)
+	}
+	total, err := s.logs.Count(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("count audit logs: %v / 统计审计日志失败: %w", err, err)
+	}
+	return logs, total, nil
+}
+
+// recordAudit 在审计服务已注入时追加记录，供各管理服务在变更成功后调用。
+func recordAudit(ctx context.Context, audit AuditLogService, entry AuditEntry) {
+	if audit == nil {
+		return
+	}
+	audit.Record(ctx, entry)
+}
+
+// auditActorID 从上下文取管理员 ID，非管理端请求返回 0。
+func auditActorID(ctx context.Context) int64 {
+	id, err := strconv.ParseInt(requestctx.AdminFromContext(ctx).ID, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
+// AuditDiff 逐字段比较两个结构体（或其指针），返回 snake_case 字段名到新旧值的映射。
+// before 为 nil 时列出 after 的全部字段，after 为 nil 时列出 before 的全部字段；敏感字段只记录发生了变化，不记录值。
+func AuditDiff(before, after any) map[string]AuditFieldChange {
+	oldFields := auditFields(before)
+	newFields := auditFields(after)
+	changes := make(map[string]AuditFieldChange)
+	for name, newValue := range newFields {
+		oldValue, existed := oldFields[name]
+		if existed && reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		change := AuditFieldChange{New: newValue}
+		if existed {
+			change.Old = oldValue
+		}
+		changes[name] = change
+	}
+	for name, oldValue := range oldFields {
+		if _, ok := newFields[name]; !ok {
+			changes[name] = AuditFieldChange{Old: oldValue}
+		}
+	}
+	for name, change := range changes {
+		if isSensitiveOperationLogKey(name) || name == "uuid" {
+			if change.Old != nil {
+				change.Old = "[REDACTED]"
+			}
+			if change.New != nil {
+				change.New = "[REDACTED]"
+			}
+			changes[name] = change
+		}
+	}
+	return changes
+}
+
+// auditFields 把结构体的导出字段展开为 snake_case 名称到值的映射，指针字段取其指向的值。
+func auditFields(value any) map[string]any {
+	fields := make(map[string]any)
+	v := reflect.ValueOf(value)
+	for v.IsValid() && v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return fields
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() || v.Kind() != reflect.Struct {
+		return fields
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := auditFieldName(field.Name)
+		if _, ignored := auditIgnoredFields[name]; ignored {
+			continue
+		}
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Pointer {
+			if fv.IsNil() {
+				fields[name] = nil
+				continue
+			}
+			fv = fv.Elem()
+		}
+		if raw, ok := fv.Interface().(json.RawMessage); ok {
+			// 原始 JSON 以字符串记录，避免在审计记录里被编码成 base64 字节数组
+			fields[name] = string(raw)
+			continue
+		}
+		fields[name] = fv.Interface()
+	}
+	return fields
+}
+
+// auditFieldName 把 Go 字段名转换为 snake_case，连续大写视为一个缩写，例如 GroupID -> group_id、CPUTotal -> cpu_total。
+func auditFieldName(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			prevLower := i > 0 && !unicode.IsUpper(runes[i-1])
+			nextLower := i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1])
+			if i > 0 && (prevLower || nextLower) {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+func TestAuditDiff(t *testing.T) {
+	before := repository.User{ID: 1, Email: "a@example.com", Password: "old-hash", GroupID: 2, TransferEnable: 10, UpdatedAt: 100}
+	after := before
+	after.Email = "b@example.com"
+	after.Password = "new-hash"
+	after.UpdatedAt = 200
+
+	changes := AuditDiff(&before, &after)
+	if len(changes) != 2 {
+		t.Fatalf("changes = %v, want only email and password", changes)
+	}
+	if got := changes["email"]; got.Old != "a@example.com" || got.New != "b@example.com" {
+		t.Fatalf("email change = %+v", got)
+	}
+	if got := changes["password"]; got.Old != "[REDACTED]" || got.New != "[REDACTED]" {
+		t.Fatalf("password change must be redacted, got %+v", got)
+	}
+
+	created := AuditDiff(nil, &after)
+	if got, ok := created["group_id"]; !ok || got.Old != nil || got.New != int64(2) {
+		t.Fatalf("create diff group_id = %+v, want new value only", got)
+	}
+	if _, ok := created["updated_at"]; ok {
+		t.Fatal("updated_at must be ignored")
+	}
+	deleted := AuditDiff(&before, nil)
+	if got := deleted["transfer_enable"]; got.Old != int64(10) || got.New != nil {
+		t.Fatalf("delete diff transfer_enable = %+v, want old value only", got)
+	}
+}
+
+func TestAuditFieldName(t *testing.T) {
+	for in, want := range map[string]string{
+		"ID":             "id",
+		"UUID":           "uuid",
+		"GroupID":        "group_id",
+		"TransferEnable": "transfer_enable",
+		"CPUTotal":       "cpu_total",
+	} {
+		if got := auditFieldName(in); got != want {
+			t.Fatalf("auditFieldName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"strconv"
 	"strings"
 	"time"
@@ -25,6 +26,12 @@ type AuthService interface {
 	Refresh(ctx context.Context, refreshToken string) (*LoginResult, error)
 	Logout(ctx context.Context, refreshToken string) error
 	IssueForUser(ctx context.Context, userID int64) (*LoginResult, error)
+	// Impersonate 为客服签发目标用户的短期模拟登录令牌，令牌带有 impersonation 标记且不可刷新。
+	Impersonate(ctx context.Context, adminID, userID int64, meta LoginInput) (*LoginResult, error)
+	// EndImpersonation 记录管理员主动结束模拟登录。
+	EndImpersonation(ctx context.Context, adminID, userID int64, meta LoginInput) error
+	// SetAuditLog 注入持久化审计日志，模拟登录的开始与结束会写入其中。
+	SetAuditLog(audit AuditLogService)
 }
 
 // LoginInput represents the payload required for user login.
@@ -45,35 +52,56 @@ type LoginResult struct {
 	IsAdmin          bool      `json:"is_admin"`
 	RefreshToken     string    `json:"refresh_token"`
 	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+	Impersonation    bool      `json:"impersonation,omitempty"` // 前端据此展示模拟登录横幅
 }
 
 // Claims describe authenticated user payload extracted from tokens.
 type Claims struct {
-	UserID  int64  `json:"user_id"`
-	Email   string `json:"email"`
-	IsAdmin bool   `json:"is_admin"`
+	UserID         int64  `json:"user_id"`
+	Email          string `json:"email"`
+	IsAdmin        bool   `json:"is_admin"`
+	ImpersonatorID int64  `json:"impersonator_id,omitempty"` // 非 0 表示管理员模拟登录
+}
+
+// Impersonating 表示该令牌由管理员模拟登录签发，敏感操作应拒绝。
+func (c *Claims) Impersonating() bool {
+	return c != nil && c.ImpersonatorID > 0
 }
 
 type authService struct {
 	users         repository.UserRepository
 	settings      repository.SettingRepository
 	loginLogs     repository.LoginLogRepository
+	lockouts      repository.LoginLockoutRepository
 	tokens        repository.TokenRepository
 	hasher        hash.Hasher
 	tokenMgr      *token.Manager
 	rate          *security.RateLimiter
 	audit         security.Recorder
+	auditLog      AuditLogService
 	loginFailures cache.Store
 }
 
+// auditImpersonation 是模拟登录审计记录的内容。
+type auditImpersonation struct {
+	AdminID   int64
+	ExpiresAt int64
+	IP        string
+	UserAgent string
+}
+
 const (
 	loginLimit      = 100
 	loginWindow     = time.Minute
 	refreshTokenTTL = 7 * 24 * time.Hour
+
+	// impersonationTokenType 区分模拟登录令牌与普通 access 令牌
+	impersonationTokenType = "impersonation"
+	impersonationTTL       = 15 * time.Minute
 )
 
 // NewAuthService wires repository + infrastructure helpers.
-func NewAuthService(users repository.UserRepository, settings repository.SettingRepository, loginLogs repository.LoginLogRepository, tokens repository.TokenRepository, hasher hash.Hasher, tokenMgr *token.Manager, rate *security.RateLimiter, audit security.Recorder, cacheStore cache.Store) AuthService {
+func NewAuthService(users repository.UserRepository, settings repository.SettingRepository, loginLogs repository.LoginLogRepository, lockouts repository.LoginLockoutRepository, tokens repository.TokenRepository, hasher hash.Hasher, tokenMgr *token.Manager, rate *security.RateLimiter, audit security.Recorder, cacheStore cache.Store) AuthService {
 	var loginFailures cache.Store
 	if cacheStore != nil {
 		namespace := cacheStore.Namespace("auth")
@@ -83,6 +111,7 @@ func NewAuthService(users repository.UserRepository, settings repository.Setting
 		users:         users,
 		settings:      settings,
 		loginLogs:     loginLogs,
+		lockouts:      lockouts,
 		tokens:        tokens,
 		hasher:        hasher,
 		tokenMgr:      tokenMgr,
@@ -92,6 +121,10 @@ func NewAuthService(users repository.UserRepository, settings repository.Setting
 	}
 }
 
+func (s *authService) SetAuditLog(audit AuditLogService) {
+	s.auditLog = audit
+}
+
 func (s *authService) Login(ctx context.Context, input LoginInput) (*LoginResult, error) {
 	if s == nil || s.users == nil || s.tokenMgr == nil || s.hasher == nil {
 		return nil, fmt.Errorf("auth service not fully configured / 认证服务未完整配置")
@@ -102,6 +135,12 @@ func (s *authService) Login(ctx context.Context, input LoginInput) (*LoginResult
 		return nil, fmt.Errorf("account and password required / 账号和密码不能为空")
 	}
 	limitKey := strings.ToLower(identifier)
+	// 锁定按账号生效，与来源 IP 无关：换 IP 登录也需等待冷却结束
+	if locked := s.activeLockout(ctx, limitKey); locked != nil {
+		s.recordLoginLog(ctx, nil, identifier, false, "account_locked", input)
+		s.recordAudit(ctx, "auth.login.locked", identifier, input, map[string]any{"locked_until": locked.LockedUntil.Unix()})
+		return nil, locked
+	}
 	if err := s.ensurePasswordLimit(ctx, limitKey); err != nil {
 		s.recordLoginLog(ctx, nil, identifier, false, "password_limit", input)
 		s.recordAudit(ctx, "auth.login.password_limit", identifier, input, map[string]any{"reason": "password_limit"})
@@ -133,8 +172,13 @@ func (s *authService) Login(ctx context.Context, input LoginInput) (*LoginResult
 	if err := compareUserPassword(user, password, s.hasher); err != nil {
 		if errors.Is(err, hash.ErrPasswordMismatch) {
 			s.bumpLoginFailure(ctx, limitKey)
+			locked := s.recordLockoutFailure(ctx, limitKey, input.IP)
 			s.recordLoginLog(ctx, user, identifier, false, "password_mismatch", input)
 			s.recordAudit(ctx, "auth.login.failure", identifier, input, map[string]any{"reason": "password"})
+			if locked != nil {
+				s.recordAudit(ctx, "auth.login.lockout", identifier, input, map[string]any{"locked_until": locked.LockedUntil.Unix()})
+				return nil, locked
+			}
 			return nil, ErrInvalidCredentials
 		}
 		s.recordLoginLog(ctx, user, identifier, false, "password_error", input)
@@ -157,6 +201,7 @@ func (s *authService) Login(ctx context.Context, input LoginInput) (*LoginResult
 	}
 	s.touchLogin(ctx, user, input)
 	s.clearLoginFailure(ctx, limitKey)
+	s.clearLockout(ctx, limitKey)
 	s.recordLoginLog(ctx, user, identifier, true, "success", input)
 	s.recordAudit(ctx, "auth.login.success", identifier, input, map[string]any{"user_id": user.ID})
 	return result, nil
@@ -216,11 +261,34 @@ func (s *authService) Verify(ctx context.Context, rawToken string) (*Claims, err
 	if user.Status != 1 || user.Banned {
 		return nil, ErrAccountDisabled
 	}
+	if parsed.TokenType == impersonationTokenType {
+		// 模拟登录永远不继承目标用户的管理员权限
+		impersonatorID := attributeInt64(parsed.Attributes, "impersonator_id")
+		if impersonatorID <= 0 {
+			return nil, ErrUnauthorized
+		}
+		return &Claims{UserID: user.ID, Email: user.Email, ImpersonatorID: impersonatorID}, nil
+	}
 	return &Claims{UserID: user.ID, Email: user.Email, IsAdmin: user.IsAdmin}, nil
 }
 
+// attributeInt64 读取 JWT 自定义属性中的整数，JSON 解码后数字为 float64。
+func attributeInt64(attrs map[string]any, key string) int64 {
+	switch v := attrs[key].(type) {
+	case float64:
+		return int64(v)
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	default:
+		return 0
+	}
+}
+
 func (s *authService) ensurePasswordLimit(ctx context.Context, identifier string) error {
-	if s == nil || s.loginFailures == nil || strings.TrimSpace(identifier) == "" {
+	// 配置了持久化锁定时由 activeLockout 接管，缓存计数仅作为回退
+	if s == nil || s.lockouts != nil || s.loginFailures == nil || strings.TrimSpace(identifier) == "" {
 		return nil
 	}
 	if !s.boolSetting(ctx, "password_limit_enable", true) {
@@ -268,7 +336,7 @@ func (s *authService) loginFailureCount(ctx context.Context, identifier string)
 }
 
 func (s *authService) bumpLoginFailure(ctx context.Context, identifier string) {
-	if s == nil || s.loginFailures == nil {
+	if s == nil || s.lockouts != nil || s.loginFailures == nil {
 		return
 	}
 	key := s.loginFailureKey(identifier)
@@ -296,6 +364,83 @@ func (s *authService) clearLoginFailure(ctx context.Context, identifier string)
 	s.loginFailures.Delete(ctx, key)
 }
 
+// activeLockout 返回账号当前生效的锁定，未锁定或未启用时返回 nil。
+func (s *authService) activeLockout(ctx context.Context, identifier string) *LoginLockedError {
+	if s == nil || s.lockouts == nil || identifier == "" {
+		return nil
+	}
+	if !s.boolSetting(ctx, "password_limit_enable", true) {
+		return nil
+	}
+	record, err := s.lockouts.FindActive(ctx, identifier, time.Now().Unix())
+	if err != nil {
+		if !errors.Is(err, repository.ErrNotFound) {
+			slog.Warn("failed to query login lockout", "identifier", identifier, "error", err)
+		}
+		return nil
+	}
+	return &LoginLockedError{Identifier: identifier, LockedUntil: time.Unix(record.LockedUntil, 0)}
+}
+
+// recordLockoutFailure 累加 identifier+IP 的连续失败次数，达到 password_limit_count 时按指数退避锁定账号。
+func (s *authService) recordLockoutFailure(ctx context.Context, identifier, ip string) *LoginLockedError {
+	if s == nil || s.lockouts == nil || identifier == "" {
+		return nil
+	}
+	if !s.boolSetting(ctx, "password_limit_enable", true) {
+		return nil
+	}
+	maxAttempts := s.intSetting(ctx, "password_limit_count", 5)
+	if maxAttempts <= 0 {
+		return nil
+	}
+	ip = strings.TrimSpace(ip)
+	record, err := s.lockouts.Find(ctx, identifier, ip)
+	if errors.Is(err, repository.ErrNotFound) {
+		record = &repository.LoginLockout{Identifier: identifier, IP: ip}
+	} else if err != nil {
+		slog.Warn("failed to load login lockout", "identifier", identifier, "error", err)
+		return nil
+	}
+
+	now := time.Now()
+	// 超过 password_limit_expire 分钟没有新的失败时，连续失败计数重新开始
+	expireMinutes := s.intSetting(ctx, "password_limit_expire", 60)
+	if expireMinutes <= 0 {
+		expireMinutes = 60
+	}
+	if record.LastFailedAt > 0 && now.Unix()-record.LastFailedAt > int64(expireMinutes)*60 {
+		record.FailedCount = 0
+	}
+	record.FailedCount++
+	record.LastFailedAt = now.Unix()
+
+	var locked *LoginLockedError
+	if record.FailedCount >= maxAttempts {
+		record.LockCount++
+		base := time.Duration(s.intSetting(ctx, "login_lockout_base_seconds", defaultLoginLockoutBaseSeconds)) * time.Second
+		maxCooldown := time.Duration(s.intSetting(ctx, "login_lockout_max_seconds", defaultLoginLockoutMaxSeconds)) * time.Second
+		until := now.Add(loginLockoutCooldown(base, maxCooldown, record.LockCount))
+		record.LockedUntil = until.Unix()
+		record.FailedCount = 0
+		locked = &LoginLockedError{Identifier: identifier, LockedUntil: until}
+	}
+	if err := s.lockouts.Upsert(ctx, record); err != nil {
+		slog.Warn("failed to persist login lockout", "identifier", identifier, "error", err)
+	}
+	return locked
+}
+
+// clearLockout 在登录成功后清除账号在所有 IP 上的失败计数与锁定次数。
+func (s *authService) clearLockout(ctx context.Context, identifier string) {
+	if s == nil || s.lockouts == nil || identifier == "" {
+		return
+	}
+	if _, err := s.lockouts.DeleteByIdentifier(ctx, identifier); err != nil {
+		slog.Warn("failed to clear login lockout", "identifier", identifier, "error", err)
+	}
+}
+
 func (s *authService) loginFailureKey(identifier string) string {
 	trimmed := strings.TrimSpace(strings.ToLower(identifier))
 	if trimmed == "" {
@@ -360,7 +505,19 @@ func (s *authService) Refresh(ctx context.Context, refreshToken string) (*LoginR
 		}
 		return nil, err
 	}
-	if record.Revoked || record.RefreshExpiresAt <= time.Now().Unix() {
+	familyID := record.FamilyID
+	if familyID == "" {
+		familyID = record.RefreshToken
+	}
+	if record.RotatedAt > 0 {
+		// 已轮换的令牌再次出现，说明令牌可能被盗用：吊销整个令牌族，迫使双方重新登录
+		s.revokeFamilyOnReuse(ctx, record, familyID)
+		return nil, ErrInvalidRefreshToken
+	}
+	if record.Revoked {
+		return nil, ErrInvalidRefreshToken
+	}
+	if record.RefreshExpiresAt <= time.Now().Unix() {
 		_ = s.tokens.DeleteByRefreshToken(ctx, trimmed)
 		return nil, ErrInvalidRefreshToken
 	}
@@ -375,10 +532,19 @@ func (s *authService) Refresh(ctx context.Context, refreshToken string) (*LoginR
 	if user.Status != 1 || user.Banned {
 		return nil, ErrAccountDisabled
 	}
-	_ = s.tokens.DeleteByRefreshToken(ctx, trimmed)
+	// 旧令牌只标记为已轮换而不删除，保留到过期以便识别重放
+	rotated, err := s.tokens.MarkRotated(ctx, record.ID, time.Now().Unix())
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		// 并发请求抢先使用了同一令牌，同样视为重放
+		s.revokeFamilyOnReuse(ctx, record, familyID)
+		return nil, ErrInvalidRefreshToken
+	}
 	identifier := preferredIdentifier(user)
 	meta := &LoginInput{Identifier: identifier, IP: record.IP, UserAgent: record.UserAgent}
-	result, err := s.issueTokens(ctx, user, meta)
+	result, err := s.issueTokensInFamily(ctx, user, meta, familyID)
 	if err != nil {
 		return nil, err
 	}
@@ -386,6 +552,11 @@ func (s *authService) Refresh(ctx context.Context, refreshToken string) (*LoginR
 	return result, nil
 }
 
+func (s *authService) revokeFamilyOnReuse(ctx context.Context, record *repository.AccessToken, familyID string) {
+	_ = s.tokens.RevokeFamily(ctx, familyID)
+	s.recordAudit(ctx, "auth.refresh.reuse_detected", strconv.FormatInt(record.UserID, 10), LoginInput{IP: record.IP, UserAgent: record.UserAgent}, map[string]any{"user_id": record.UserID, "token_id": record.ID})
+}
+
 func (s *authService) Logout(ctx context.Context, refreshToken string) error {
 	if s == nil || s.tokens == nil {
 		return nil
@@ -398,7 +569,97 @@ func (s *authService) Logout(ctx context.Context, refreshToken string) error {
 }
 
 func (s *authService) IssueForUser(ctx context.Context, userID int64) (*LoginResult, error) {
-	if s == nil || s.users == nil {
+	user, err := s.issuableUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.issueTokens(ctx, user, nil)
+}
+
+// Impersonate 复用 IssueForUser 的用户校验，签发带 impersonation 标记的短期令牌并写入审计日志。
+func (s *authService) Impersonate(ctx context.Context, adminID, userID int64, meta LoginInput) (*LoginResult, error) {
+	if adminID <= 0 {
+		return nil, ErrUnauthorized
+	}
+	user, err := s.issuableUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	tokenStr, claims, err := s.tokenMgr.Issue(token.IssueInput{
+		Subject:   strconv.FormatInt(user.ID, 10),
+		TokenType: impersonationTokenType,
+		TTL:       impersonationTTL,
+		Attributes: map[string]any{
+			"email":           user.Email,
+			"username":        user.Username,
+			"impersonation":   true,
+			"impersonator_id": adminID,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if s.audit != nil {
+		s.audit.Record(ctx, security.Event{
+			Kind:      "auth.impersonate",
+			ActorID:   strconv.FormatInt(adminID, 10),
+			IP:        meta.IP,
+			UserAgent: meta.UserAgent,
+			Metadata: map[string]any{
+				"admin_id":       adminID,
+				"target_user_id": user.ID,
+				"expires_at":     claims.ExpiresAt.Unix(),
+			},
+			Occurred: now,
+		})
+	}
+	recordAudit(ctx, s.auditLog, AuditEntry{
+		ActorID:    adminID,
+		Action:     AuditActionImpersonateStart,
+		TargetType: AuditTargetUser,
+		TargetID:   user.ID,
+		After:      auditImpersonation{AdminID: adminID, ExpiresAt: claims.ExpiresAt.Unix(), IP: meta.IP, UserAgent: meta.UserAgent},
+	})
+	// 不签发刷新令牌，过期后必须重新发起模拟登录
+	return &LoginResult{
+		Token:         tokenStr,
+		ExpiresAt:     claims.ExpiresAt.Time,
+		UserID:        user.ID,
+		Email:         user.Email,
+		Username:      user.Username,
+		Impersonation: true,
+	}, nil
+}
+
+// EndImpersonation 由模拟登录令牌调用，记录结束事件；令牌本身仍在 15 分钟内自然过期。
+func (s *authService) EndImpersonation(ctx context.Context, adminID, userID int64, meta LoginInput) error {
+	if adminID <= 0 || userID <= 0 {
+		return ErrUnauthorized
+	}
+	if s.audit != nil {
+		s.audit.Record(ctx, security.Event{
+			Kind:      "auth.impersonate.stop",
+			ActorID:   strconv.FormatInt(adminID, 10),
+			IP:        meta.IP,
+			UserAgent: meta.UserAgent,
+			Metadata:  map[string]any{"admin_id": adminID, "target_user_id": userID},
+			Occurred:  time.Now().UTC(),
+		})
+	}
+	recordAudit(ctx, s.auditLog, AuditEntry{
+		ActorID:    adminID,
+		Action:     AuditActionImpersonateStop,
+		TargetType: AuditTargetUser,
+		TargetID:   userID,
+		Before:     auditImpersonation{AdminID: adminID, IP: meta.IP, UserAgent: meta.UserAgent},
+	})
+	return nil
+}
+
+// issuableUser 加载可签发令牌的用户，不存在时返回 ErrUnauthorized，停用或封禁时返回 ErrAccountDisabled。
+func (s *authService) issuableUser(ctx context.Context, userID int64) (*repository.User, error) {
+	if s == nil || s.users == nil || s.tokenMgr == nil {
 		return nil, fmt.Errorf("auth service not fully configured / 认证服务未完整配置")
 	}
 	if userID <= 0 {
@@ -414,10 +675,15 @@ func (s *authService) IssueForUser(ctx context.Context, userID int64) (*LoginRes
 	if user.Status != 1 || user.Banned {
 		return nil, ErrAccountDisabled
 	}
-	return s.issueTokens(ctx, user, nil)
+	return user, nil
 }
 
 func (s *authService) issueTokens(ctx context.Context, user *repository.User, meta *LoginInput) (*LoginResult, error) {
+	return s.issueTokensInFamily(ctx, user, meta, "")
+}
+
+// issueTokensInFamily 签发访问令牌与刷新令牌；familyID 为空时开启新的令牌族（新登录）。
+func (s *authService) issueTokensInFamily(ctx context.Context, user *repository.User, meta *LoginInput, familyID string) (*LoginResult, error) {
 	subject := strconv.FormatInt(user.ID, 10)
 	tokenStr, claims, err := s.tokenMgr.Issue(token.IssueInput{
 		Subject:   subject,
@@ -440,6 +706,9 @@ func (s *authService) issueTokens(ctx context.Context, user *repository.User, me
 	}
 	if s.tokens != nil {
 		refreshToken := uuid.NewString()
+		if familyID == "" {
+			familyID = uuid.NewString()
+		}
 		expires := time.Now().UTC().Add(refreshTokenTTL)
 		var ip, ua string
 		if meta != nil {
@@ -454,6 +723,7 @@ func (s *authService) issueTokens(ctx context.Context, user *repository.User, me
 			RefreshExpiresAt: expires.Unix(),
 			IP:               ip,
 			UserAgent:        ua,
+			FamilyID:         familyID,
 		}
 		if _, err := s.tokens.Create(ctx, payload); err != nil {
 			return nil, fmt.Errorf("store refresh token: %v / 刷新令牌写入失败: %w", err, err)
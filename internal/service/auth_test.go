@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/creamcroissant/xboard/internal/auth/token"
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+type authUserRepoStub struct {
+	repository.UserRepository
+	users map[int64]*repository.User
+}
+
+func (s *authUserRepoStub) FindByID(ctx context.Context, id int64) (*repository.User, error) {
+	user, ok := s.users[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return user, nil
+}
+
+type tokenRepoStub struct {
+	mu     sync.Mutex
+	nextID int64
+	tokens map[string]*repository.AccessToken
+}
+
+func newTokenRepoStub() *tokenRepoStub {
+	return &tokenRepoStub{tokens: make(map[string]*repository.AccessToken)}
+}
+
+func (s *tokenRepoStub) Create(ctx context.Context, tok *repository.AccessToken) (*repository.AccessToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	tok.ID = s.nextID
+	if tok.FamilyID == "" {
+		tok.FamilyID = tok.RefreshToken
+	}
+	copied := *tok
+	s.tokens[tok.RefreshToken] = &copied
+	return tok, nil
+}
+
+func (s *tokenRepoStub) FindByRefreshToken(ctx context.Context, refreshToken string) (*repository.AccessToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tok, ok := s.tokens[refreshToken]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *tok
+	return &copied, nil
+}
+
+func (s *tokenRepoStub) DeleteByRefreshToken(ctx context.Context, refreshToken string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, refreshToken)
+	return nil
+}
+
+func (s *tokenRepoStub) DeleteByUser(ctx context.Context, userID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, tok := range s.tokens {
+		if tok.UserID == userID {
+			delete(s.tokens, key)
+		}
+	}
+	return nil
+}
+
+func (s *tokenRepoStub) MarkRotated(ctx context.Context, id int64, rotatedAt int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, tok := range s.tokens {
+		if tok.ID == id {
+			if tok.RotatedAt != 0 || tok.Revoked {
+				return false, nil
+			}
+			tok.RotatedAt = rotatedAt
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *tokenRepoStub) RevokeFamily(ctx context.Context, familyID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, tok := range s.tokens {
+		if tok.FamilyID == familyID {
+			tok.Revoked = true
+		}
+	}
+	return nil
+}
+
+func newRefreshTestAuthService(t *testing.T, tokens repository.TokenRepository) AuthService {
+	t.Helper()
+	mgr, err := token.NewManager(token.Options{SigningKey: []byte("test-signing-key")})
+	if err != nil {
+		t.Fatalf("new token manager: %v", err)
+	}
+	users := &authUserRepoStub{users: map[int64]*repository.User{
+		1: {ID: 1, Email: "victim@example.com", Status: 1},
+	}}
+	return NewAuthService(users, nil, nil, nil, tokens, nil, mgr, nil, nil, nil)
+}
+
+func TestAuthRefreshRotatesToken(t *testing.T) {
+	ctx := context.Background()
+	tokens := newTokenRepoStub()
+	auth := newRefreshTestAuthService(t, tokens)
+
+	login, err := auth.IssueForUser(ctx, 1)
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	refreshed, err := auth.Refresh(ctx, login.RefreshToken)
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if refreshed.RefreshToken == "" || refreshed.RefreshToken == login.RefreshToken {
+		t.Fatalf("expected a new refresh token, got %q", refreshed.RefreshToken)
+	}
+
+	oldRecord, _ := tokens.FindByRefreshToken(ctx, login.RefreshToken)
+	newRecord, _ := tokens.FindByRefreshToken(ctx, refreshed.RefreshToken)
+	if oldRecord.RotatedAt == 0 {
+		t.Fatalf("expected old token to be marked rotated")
+	}
+	if newRecord.FamilyID != oldRecord.FamilyID {
+		t.Fatalf("expected rotated token to stay in family %q, got %q", oldRecord.FamilyID, newRecord.FamilyID)
+	}
+}
+
+func TestAuthRefreshReuseRevokesFamily(t *testing.T) {
+	ctx := context.Background()
+	tokens := newTokenRepoStub()
+	auth := newRefreshTestAuthService(t, tokens)
+
+	login, err := auth.IssueForUser(ctx, 1)
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	stolen := login.RefreshToken
+
+	// 合法客户端先完成轮换
+	legit, err := auth.Refresh(ctx, stolen)
+	if err != nil {
+		t.Fatalf("legit refresh: %v", err)
+	}
+
+	// 攻击者重放被盗的旧令牌
+	if _, err := auth.Refresh(ctx, stolen); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("expected ErrInvalidRefreshToken on replay, got %v", err)
+	}
+
+	// 整个令牌族被吊销，合法客户端手上的新令牌也随之失效
+	if _, err := auth.Refresh(ctx, legit.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("expected family to be revoked, got %v", err)
+	}
+
+	// 其它登录会话（不同令牌族）不受影响
+	other, err := auth.IssueForUser(ctx, 1)
+	if err != nil {
+		t.Fatalf("issue other session: %v", err)
+	}
+	if _, err := auth.Refresh(ctx, other.RefreshToken); err != nil {
+		t.Fatalf("expected unrelated family to refresh, got %v", err)
+	}
+}
+
+type auditLogRecorderStub struct {
+	AuditLogService
+	entries []AuditEntry
+}
+
+func (s *auditLogRecorderStub) Record(ctx context.Context, entry AuditEntry) {
+	s.entries = append(s.entries, entry)
+}
+
+func TestImpersonationStartAndStopAreAudited(t *testing.T) {
+	ctx := context.Background()
+	auth := newRefreshTestAuthService(t, newTokenRepoStub())
+	audit := &auditLogRecorderStub{}
+	auth.SetAuditLog(audit)
+
+	result, err := auth.Impersonate(ctx, 9, 1, LoginInput{IP: "198.51.100.1"})
+	if err != nil {
+		t.Fatalf("impersonate: %v", err)
+	}
+	if result.RefreshToken != "" {
+		t.Fatal("impersonation must not issue a refresh token")
+	}
+	claims, err := auth.Verify(ctx, result.Token)
+	if err != nil || !claims.Impersonating() {
+		t.Fatalf("verify = %+v, %v; want impersonating claims", claims, err)
+	}
+	if err := auth.EndImpersonation(ctx, claims.ImpersonatorID, claims.UserID, LoginInput{}); err != nil {
+		t.Fatalf("end impersonation: %v", err)
+	}
+
+	if len(audit.entries) != 2 {
+		t.Fatalf("audit entries = %d, want 2", len(audit.entries))
+	}
+	for i, want := range []string{AuditActionImpersonateStart, AuditActionImpersonateStop} {
+		entry := audit.entries[i]
+		if entry.Action != want || entry.ActorID != 9 || entry.TargetType != AuditTargetUser || entry.TargetID != 1 {
+			t.Fatalf("entry %d = %+v, want %s by admin 9 on user 1", i, entry, want)
+		}
+	}
+}
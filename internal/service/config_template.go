@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/creamcroissant/xboard/internal/repository"
@@ -17,19 +18,34 @@ type ConfigTemplateService interface {
 	FindByID(ctx context.Context, id int64) (*repository.ConfigTemplate, error)
 	ListAll(ctx context.Context) ([]*repository.ConfigTemplate, error)
 
+	// Version history
+	ListVersions(ctx context.Context, templateID int64, limit, offset int) ([]*repository.ConfigTemplateVersion, error)
+	GetVersion(ctx context.Context, templateID, version int64) (*repository.ConfigTemplateVersion, error)
+	Rollback(ctx context.Context, req RollbackConfigTemplateRequest) (int64, error)
+
 	// Validation and preview
 	ValidateTemplate(ctx context.Context, content, templateType string) (*template.ValidationResult, error)
+	LintTemplate(ctx context.Context, content, templateType string) ([]template.ValidationIssue, error)
 	PreviewRender(ctx context.Context, templateID int64) ([]byte, error)
+
+	// Portable import/export
+	Export(ctx context.Context, templateID int64) (*ConfigTemplateBundle, error)
+	Import(ctx context.Context, req ImportConfigTemplateRequest) (*ConfigTemplateImportResult, error)
+
+	// SetResyncNotifier 注入 Agent 重同步通知，模板内容变更后推送给受影响的节点。
+	SetResyncNotifier(notifier AgentResyncNotifier)
 }
 
 // CreateConfigTemplateRequest contains data for creating a new config template.
 type CreateConfigTemplateRequest struct {
 	Name         string
-	Type         string   // sing-box, xray
-	Content      string   // Template content
+	Type         string // sing-box, xray, mieru
+	Content      string // Template content
 	Description  string
 	MinVersion   string   // Minimum core version required
 	Capabilities []string // Required capabilities
+	ChangeNote   string
+	OperatorID   int64
 }
 
 // UpdateConfigTemplateRequest contains data for updating a config template.
@@ -40,22 +56,54 @@ type UpdateConfigTemplateRequest struct {
 	Description  *string
 	MinVersion   *string
 	Capabilities []string // nil means no change, empty slice clears
+	ChangeNote   string
+	OperatorID   int64
+	// ExpectedUpdatedAt enables optimistic locking when > 0; a stale value returns ErrConflict.
+	ExpectedUpdatedAt int64
+}
+
+// RollbackConfigTemplateRequest restores a template to a previous version.
+// The rollback itself is recorded as a new version, history is never rewritten.
+type RollbackConfigTemplateRequest struct {
+	TemplateID int64
+	Version    int64
+	ChangeNote string
+	OperatorID int64
+	// ExpectedUpdatedAt enables optimistic locking when > 0; a stale value returns ErrConflict.
+	ExpectedUpdatedAt int64
 }
 
 type configTemplateService struct {
 	configTemplates repository.ConfigTemplateRepository
+	versions        repository.ConfigTemplateVersionRepository
 	engine          *template.Engine
 	validator       *template.Validator
+	linter          *template.Linter
+	resync          AgentResyncNotifier
 }
 
 // NewConfigTemplateService creates a new config template service.
 func NewConfigTemplateService(
 	configTemplates repository.ConfigTemplateRepository,
+	versions repository.ConfigTemplateVersionRepository,
 ) ConfigTemplateService {
 	return &configTemplateService{
 		configTemplates: configTemplates,
+		versions:        versions,
 		engine:          template.NewEngine(),
 		validator:       template.NewValidator(),
+		linter:          template.NewLinter(),
+	}
+}
+
+func (s *configTemplateService) SetResyncNotifier(notifier AgentResyncNotifier) {
+	s.resync = notifier
+}
+
+// notifyTemplateChanged 通知跟随该模板最新版本的 Agent 立即拉取配置。
+func (s *configTemplateService) notifyTemplateChanged(ctx context.Context, templateID int64) {
+	if s.resync != nil {
+		s.resync.NotifyTemplateChanged(ctx, templateID)
 	}
 }
 
@@ -88,6 +136,9 @@ func (s *configTemplateService) Create(ctx context.Context, req CreateConfigTemp
 	if err := s.configTemplates.Create(ctx, tpl); err != nil {
 		return nil, fmt.Errorf("create config template: %v / 创建配置模板失败: %w", err, err)
 	}
+	if _, err := s.recordVersion(ctx, tpl, firstNonEmpty(req.ChangeNote, "create"), req.OperatorID); err != nil {
+		return nil, err
+	}
 
 	return tpl, nil
 }
@@ -95,8 +146,15 @@ func (s *configTemplateService) Create(ctx context.Context, req CreateConfigTemp
 func (s *configTemplateService) Update(ctx context.Context, id int64, req UpdateConfigTemplateRequest) error {
 	tpl, err := s.configTemplates.FindByID(ctx, id)
 	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFound
+		}
 		return err
 	}
+	// 过期的编辑可能把错误配置推送到大量节点，写入前先做一次快速检查，仓储层再做条件更新兜底
+	if req.ExpectedUpdatedAt > 0 && tpl.UpdatedAt != req.ExpectedUpdatedAt {
+		return ErrConflict
+	}
 
 	// Apply updates
 	if req.Name != nil {
@@ -133,11 +191,129 @@ func (s *configTemplateService) Update(ctx context.Context, id int64, req Update
 		tpl.Capabilities = []string{}
 	}
 
-	return s.configTemplates.Update(ctx, tpl)
+	if err := s.configTemplates.Update(ctx, tpl, req.ExpectedUpdatedAt); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return ErrConflict
+		}
+		return err
+	}
+	if _, err := s.recordVersion(ctx, tpl, firstNonEmpty(req.ChangeNote, "update"), req.OperatorID); err != nil {
+		return err
+	}
+	s.notifyTemplateChanged(ctx, tpl.ID)
+	return nil
+}
+
+// recordVersion 把模板当前内容保存为下一个不可变版本，返回新版本号。
+func (s *configTemplateService) recordVersion(ctx context.Context, tpl *repository.ConfigTemplate, note string, operatorID int64) (int64, error) {
+	if s.versions == nil {
+		return 0, nil
+	}
+	maxVersion, err := s.versions.GetMaxVersion(ctx, tpl.ID)
+	if err != nil {
+		return 0, fmt.Errorf("get template version: %v / 获取模板版本失败: %w", err, err)
+	}
+	version := &repository.ConfigTemplateVersion{
+		TemplateID:   tpl.ID,
+		Version:      maxVersion + 1,
+		Name:         tpl.Name,
+		Type:         tpl.Type,
+		Content:      tpl.Content,
+		Description:  tpl.Description,
+		MinVersion:   tpl.MinVersion,
+		Capabilities: tpl.Capabilities,
+		ChangeNote:   note,
+		OperatorID:   operatorID,
+	}
+	if err := s.versions.Create(ctx, version); err != nil {
+		return 0, fmt.Errorf("create template version: %v / 保存模板版本失败: %w", err, err)
+	}
+	return version.Version, nil
+}
+
+func (s *configTemplateService) ListVersions(ctx context.Context, templateID int64, limit, offset int) ([]*repository.ConfigTemplateVersion, error) {
+	if templateID <= 0 {
+		return nil, ErrBadRequest
+	}
+	if s.versions == nil {
+		return nil, ErrNotImplemented
+	}
+	if _, err := s.configTemplates.FindByID(ctx, templateID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return s.versions.ListByTemplateID(ctx, templateID, normalizeLimit(limit, 50), normalizeOffset(offset))
+}
+
+func (s *configTemplateService) GetVersion(ctx context.Context, templateID, version int64) (*repository.ConfigTemplateVersion, error) {
+	if templateID <= 0 || version <= 0 {
+		return nil, ErrBadRequest
+	}
+	if s.versions == nil {
+		return nil, ErrNotImplemented
+	}
+	found, err := s.versions.FindByTemplateAndVersion(ctx, templateID, version)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return found, nil
+}
+
+func (s *configTemplateService) Rollback(ctx context.Context, req RollbackConfigTemplateRequest) (int64, error) {
+	target, err := s.GetVersion(ctx, req.TemplateID, req.Version)
+	if err != nil {
+		return 0, err
+	}
+	tpl, err := s.configTemplates.FindByID(ctx, req.TemplateID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return 0, ErrNotFound
+		}
+		return 0, err
+	}
+	if req.ExpectedUpdatedAt > 0 && tpl.UpdatedAt != req.ExpectedUpdatedAt {
+		return 0, ErrConflict
+	}
+
+	tpl.Name = target.Name
+	tpl.Type = target.Type
+	tpl.Content = target.Content
+	tpl.Description = target.Description
+	tpl.MinVersion = target.MinVersion
+	tpl.Capabilities = target.Capabilities
+	validationResult := s.validator.ValidateTemplate(tpl.Content, tpl.Type)
+	tpl.IsValid = validationResult.Valid
+	tpl.ValidationError = ""
+	if !validationResult.Valid && len(validationResult.Errors) > 0 {
+		tpl.ValidationError = validationResult.Errors[0]
+	}
+
+	if err := s.configTemplates.Update(ctx, tpl, req.ExpectedUpdatedAt); err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return 0, ErrConflict
+		}
+		return 0, err
+	}
+	note := firstNonEmpty(req.ChangeNote, fmt.Sprintf("rollback to v%d", target.Version))
+	version, err := s.recordVersion(ctx, tpl, note, req.OperatorID)
+	if err != nil {
+		return 0, err
+	}
+	s.notifyTemplateChanged(ctx, tpl.ID)
+	return version, nil
 }
 
 func (s *configTemplateService) Delete(ctx context.Context, id int64) error {
-	return s.configTemplates.Delete(ctx, id)
+	if err := s.configTemplates.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.notifyTemplateChanged(ctx, id)
+	return nil
 }
 
 func (s *configTemplateService) FindByID(ctx context.Context, id int64) (*repository.ConfigTemplate, error) {
@@ -153,6 +329,13 @@ func (s *configTemplateService) ValidateTemplate(ctx context.Context, content, t
 	return result, nil
 }
 
+func (s *configTemplateService) LintTemplate(ctx context.Context, content, templateType string) ([]template.ValidationIssue, error) {
+	if content == "" {
+		return nil, ErrBadRequest
+	}
+	return s.linter.Lint(content, templateType), nil
+}
+
 func (s *configTemplateService) PreviewRender(ctx context.Context, templateID int64) ([]byte, error) {
 	tpl, err := s.configTemplates.FindByID(ctx, templateID)
 	if err != nil {
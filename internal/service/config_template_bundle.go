@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+const (
+	// ConfigTemplateBundleFormat identifies exported template files.
+	ConfigTemplateBundleFormat = "xboard-config-template"
+	// ConfigTemplateBundleVersion is the current bundle format version.
+	// Bump it only for incompatible changes; new optional fields keep the version
+	// so older exports stay importable.
+	ConfigTemplateBundleVersion = 1
+)
+
+// Import conflict strategies applied when a template with the same name exists.
+const (
+	ConfigTemplateConflictReject    = "reject"
+	ConfigTemplateConflictRename    = "rename"
+	ConfigTemplateConflictOverwrite = "overwrite"
+)
+
+// ConfigTemplateBundle is the portable, self-contained export format of a config template.
+type ConfigTemplateBundle struct {
+	Format        string                      `json:"format"`
+	FormatVersion int                         `json:"format_version"`
+	ExportedAt    int64                       `json:"exported_at"`
+	Template      ConfigTemplateBundleContent `json:"template"`
+	// Checksum is the sha256 of Template.Content, used to detect truncated or edited files.
+	Checksum string `json:"checksum"`
+}
+
+// ConfigTemplateBundleContent carries the template itself and its declared requirements.
+type ConfigTemplateBundleContent struct {
+	Name          string   `json:"name"`
+	Type          string   `json:"type"`
+	Description   string   `json:"description,omitempty"`
+	MinVersion    string   `json:"min_version,omitempty"`
+	Capabilities  []string `json:"capabilities"`
+	SchemaVersion int      `json:"schema_version"`
+	Content       string   `json:"content"`
+}
+
+// ImportConfigTemplateRequest imports a bundle. Name overrides the bundled name when set;
+// OnConflict is one of reject (default), rename or overwrite.
+type ImportConfigTemplateRequest struct {
+	Bundle     ConfigTemplateBundle
+	Name       string
+	OnConflict string
+	OperatorID int64
+}
+
+// ConfigTemplateImportResult reports what the import did.
+type ConfigTemplateImportResult struct {
+	Template     *repository.ConfigTemplate `json:"template"`
+	Action       string                     `json:"action"` // created, renamed, overwritten
+	OriginalName string                     `json:"original_name,omitempty"`
+	Warnings     []string                   `json:"warnings,omitempty"`
+}
+
+// ConfigTemplateImportError is returned when a bundle is malformed, fails validation
+// or collides with an existing template under the reject strategy.
+type ConfigTemplateImportError struct {
+	Message  string   `json:"message"`
+	Errors   []string `json:"errors,omitempty"`
+	Conflict bool     `json:"conflict,omitempty"`
+}
+
+func (e *ConfigTemplateImportError) Error() string {
+	if len(e.Errors) == 0 {
+		return e.Message
+	}
+	return e.Message + ": " + strings.Join(e.Errors, "; ")
+}
+
+func (e *ConfigTemplateImportError) Unwrap() error {
+	return ErrBadRequest
+}
+
+// Export packs a template into a portable bundle.
+func (s *configTemplateService) Export(ctx context.Context, templateID int64) (*ConfigTemplateBundle, error) {
+	if templateID <= 0 {
+		return nil, ErrBadRequest
+	}
+	tpl, err := s.configTemplates.FindByID(ctx, templateID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	capabilities := tpl.Capabilities
+	if capabilities == nil {
+		capabilities = []string{}
+	}
+	return &ConfigTemplateBundle{
+		Format:        ConfigTemplateBundleFormat,
+		FormatVersion: ConfigTemplateBundleVersion,
+		ExportedAt:    time.Now().Unix(),
+		Template: ConfigTemplateBundleContent{
+			Name:          tpl.Name,
+			Type:          tpl.Type,
+			Description:   tpl.Description,
+			MinVersion:    tpl.MinVersion,
+			Capabilities:  capabilities,
+			SchemaVersion: tpl.SchemaVersion,
+			Content:       tpl.Content,
+		},
+		Checksum: configTemplateChecksum(tpl.Content),
+	}, nil
+}
+
+// Import validates a bundle and saves it as a new template, or overwrites a same-named
+// template when requested. Invalid templates are rejected rather than saved as invalid.
+func (s *configTemplateService) Import(ctx context.Context, req ImportConfigTemplateRequest) (*ConfigTemplateImportResult, error) {
+	bundle := req.Bundle
+	if err := checkConfigTemplateBundle(bundle); err != nil {
+		return nil, err
+	}
+
+	content := bundle.Template
+	validation := s.validator.ValidateTemplate(content.Content, content.Type)
+	if !validation.Valid {
+		return nil, &ConfigTemplateImportError{
+			Message: "template failed validation / 模板校验未通过",
+			Errors:  validation.Errors,
+		}
+	}
+
+	strategy := strings.ToLower(strings.TrimSpace(req.OnConflict))
+	if strategy == "" {
+		strategy = ConfigTemplateConflictReject
+	}
+	switch strategy {
+	case ConfigTemplateConflictReject, ConfigTemplateConflictRename, ConfigTemplateConflictOverwrite:
+	default:
+		return nil, &ConfigTemplateImportError{Message: fmt.Sprintf("unsupported on_conflict %q / 不支持的冲突处理方式", req.OnConflict)}
+	}
+
+	name := strings.TrimSpace(firstNonEmpty(req.Name, content.Name))
+	existing, err := s.configTemplates.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]*repository.ConfigTemplate, len(existing))
+	for _, tpl := range existing {
+		if tpl != nil {
+			names[strings.ToLower(tpl.Name)] = tpl
+		}
+	}
+
+	result := &ConfigTemplateImportResult{Action: "created", Warnings: validation.Warnings}
+	if current, ok := names[strings.ToLower(name)]; ok {
+		switch strategy {
+		case ConfigTemplateConflictReject:
+			return nil, &ConfigTemplateImportError{
+				Message:  fmt.Sprintf("template %q already exists / 模板名称已存在", name),
+				Conflict: true,
+			}
+		case ConfigTemplateConflictOverwrite:
+			capabilities := content.Capabilities
+			if capabilities == nil {
+				capabilities = []string{}
+			}
+			if err := s.Update(ctx, current.ID, UpdateConfigTemplateRequest{
+				Type:         &content.Type,
+				Content:      &content.Content,
+				Description:  &content.Description,
+				MinVersion:   &content.MinVersion,
+				Capabilities: capabilities,
+				ChangeNote:   "import (overwrite)",
+				OperatorID:   req.OperatorID,
+				// 防止列出模板后到写入前被他人修改
+				ExpectedUpdatedAt: current.UpdatedAt,
+			}); err != nil {
+				return nil, err
+			}
+			updated, err := s.configTemplates.FindByID(ctx, current.ID)
+			if err != nil {
+				return nil, err
+			}
+			result.Template = updated
+			result.Action = "overwritten"
+			return result, nil
+		case ConfigTemplateConflictRename:
+			result.OriginalName = name
+			result.Action = "renamed"
+			name = uniqueConfigTemplateName(name, names)
+		}
+	}
+
+	created, err := s.Create(ctx, CreateConfigTemplateRequest{
+		Name:         name,
+		Type:         content.Type,
+		Content:      content.Content,
+		Description:  content.Description,
+		MinVersion:   content.MinVersion,
+		Capabilities: content.Capabilities,
+		ChangeNote:   "import",
+		OperatorID:   req.OperatorID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Template = created
+	return result, nil
+}
+
+// checkConfigTemplateBundle verifies the envelope before the template is validated.
+func checkConfigTemplateBundle(bundle ConfigTemplateBundle) error {
+	if bundle.Format != ConfigTemplateBundleFormat {
+		return &ConfigTemplateImportError{Message: fmt.Sprintf("unrecognized bundle format %q / 无法识别的模板文件格式", bundle.Format)}
+	}
+	if bundle.FormatVersion <= 0 || bundle.FormatVersion > ConfigTemplateBundleVersion {
+		return &ConfigTemplateImportError{Message: fmt.Sprintf("unsupported bundle format version %d, max supported %d / 不支持的模板文件版本", bundle.FormatVersion, ConfigTemplateBundleVersion)}
+	}
+	tpl := bundle.Template
+	var problems []string
+	if strings.TrimSpace(tpl.Name) == "" {
+		problems = append(problems, "template.name is required / 模板名称不能为空")
+	}
+	if tpl.Type != "sing-box" && tpl.Type != "xray" && tpl.Type != "mieru" {
+		problems = append(problems, fmt.Sprintf("template.type %q must be sing-box, xray or mieru / 模板类型必须为 sing-box、xray 或 mieru", tpl.Type))
+	}
+	if strings.TrimSpace(tpl.Content) == "" {
+		problems = append(problems, "template.content is required / 模板内容不能为空")
+	}
+	if bundle.Checksum != "" && !strings.EqualFold(bundle.Checksum, configTemplateChecksum(tpl.Content)) {
+		problems = append(problems, "checksum mismatch, the file may be truncated or edited / 校验和不匹配，文件可能被截断或修改")
+	}
+	if len(problems) > 0 {
+		return &ConfigTemplateImportError{Message: "invalid template bundle / 模板文件无效", Errors: problems}
+	}
+	return nil
+}
+
+func uniqueConfigTemplateName(name string, taken map[string]*repository.ConfigTemplate) string {
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s (%d)", name, i)
+		if _, ok := taken[strings.ToLower(candidate)]; !ok {
+			return candidate
+		}
+	}
+}
+
+func configTemplateChecksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// 管理端实时面板事件类型；主机上下线沿用 webhook 的事件名与判定逻辑。
+const (
+	DashboardEventHostOffline = NodeWebhookEventOffline
+	DashboardEventHostOnline  = NodeWebhookEventOnline
+	DashboardEventHostMetrics = "host.metrics"
+	DashboardEventCoreSwitch  = "core.switch"
+)
+
+const (
+	// DefaultDashboardMaxClients 为同时在线的实时面板连接数上限。
+	DefaultDashboardMaxClients = 64
+	// dashboardSubscriberBuffer 为每个连接的待发送事件缓冲，写满后丢弃新事件，避免慢客户端拖住上报链路。
+	dashboardSubscriberBuffer = 64
+)
+
+// DashboardEvent 是推送给管理端实时面板的一条事件。
+type DashboardEvent struct {
+	Type        string `json:"type"`
+	AgentHostID int64  `json:"agent_host_id"`
+	Timestamp   int64  `json:"timestamp"`
+	Data        any    `json:"data,omitempty"`
+}
+
+// DashboardCoreSwitchEvent 描述一次核心切换操作的最终结果。
+type DashboardCoreSwitchEvent struct {
+	OperationID  string `json:"operation_id"`
+	CoreType     string `json:"core_type"`
+	Status       string `json:"status"`
+	ErrorMessage string `json:"error_message,omitempty"`
+	FinishedAt   int64  `json:"finished_at"`
+}
+
+// DashboardHub 把主机状态、指标与核心切换事件广播给在线的管理端连接。nil Hub 上的调用均为空操作。
+type DashboardHub struct {
+	mu          sync.RWMutex
+	subscribers map[*DashboardSubscription]struct{}
+	maxClients  int
+	now         func() time.Time
+}
+
+// NewDashboardHub 创建事件中心；maxClients <= 0 时使用 DefaultDashboardMaxClients。
+func NewDashboardHub(maxClients int) *DashboardHub {
+	if maxClients <= 0 {
+		maxClients = DefaultDashboardMaxClients
+	}
+	return &DashboardHub{
+		subscribers: make(map[*DashboardSubscription]struct{}),
+		maxClients:  maxClients,
+		now:         time.Now,
+	}
+}
+
+// DashboardSubscription 是单个连接的订阅，可按主机过滤；未设置主机时接收全部事件。
+type DashboardSubscription struct {
+	hub    *DashboardHub
+	events chan DashboardEvent
+	once   sync.Once
+
+	mu    sync.RWMutex
+	hosts map[int64]struct{}
+}
+
+// Subscribe 注册一个连接，超过连接数上限时返回 ErrTooManyDashboardClients。
+func (h *DashboardHub) Subscribe(hostIDs []int64) (*DashboardSubscription, error) {
+	if h == nil {
+		return nil, ErrTooManyDashboardClients
+	}
+	sub := &DashboardSubscription{hub: h, events: make(chan DashboardEvent, dashboardSubscriberBuffer)}
+	sub.SetHosts(hostIDs)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.subscribers) >= h.maxClients {
+		return nil, ErrTooManyDashboardClients
+	}
+	h.subscribers[sub] = struct{}{}
+	return sub, nil
+}
+
+// HasSubscribers 判断当前是否有在线连接，供事件源决定是否需要保留状态。
+func (h *DashboardHub) HasSubscribers() bool {
+	if h == nil {
+		return false
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subscribers) > 0
+}
+
+// Publish 非阻塞地投递事件；Timestamp 为空时取当前时间，缓冲已满的连接直接丢弃该事件。
+func (h *DashboardHub) Publish(event DashboardEvent) {
+	if h == nil {
+		return
+	}
+	if event.Timestamp == 0 {
+		event.Timestamp = h.now().Unix()
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for sub := range h.subscribers {
+		if !sub.wants(event.AgentHostID) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+		}
+	}
+}
+
+// Events 返回事件通道，连接关闭后通道被关闭。
+func (s *DashboardSubscription) Events() <-chan DashboardEvent {
+	return s.events
+}
+
+// SetHosts 替换关注的主机列表，空列表表示关注全部主机。
+func (s *DashboardSubscription) SetHosts(hostIDs []int64) {
+	var hosts map[int64]struct{}
+	if ids := uniquePositive(hostIDs); len(ids) > 0 {
+		hosts = make(map[int64]struct{}, len(ids))
+		for _, id := range ids {
+			hosts[id] = struct{}{}
+		}
+	}
+	s.mu.Lock()
+	s.hosts = hosts
+	s.mu.Unlock()
+}
+
+func (s *DashboardSubscription) wants(agentHostID int64) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.hosts) == 0 {
+		return true
+	}
+	_, ok := s.hosts[agentHostID]
+	return ok
+}
+
+// Close 注销订阅并关闭事件通道，可重复调用。
+func (s *DashboardSubscription) Close() {
+	s.once.Do(func() {
+		s.hub.mu.Lock()
+		delete(s.hub.subscribers, s)
+		s.hub.mu.Unlock()
+		close(s.events)
+	})
+}
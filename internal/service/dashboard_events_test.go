@@ -0,0 +1,55 @@
+package service
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDashboardHubFiltersAndCapsSubscribers(t *testing.T) {
+	hub := NewDashboardHub(2)
+
+	all, err := hub.Subscribe(nil)
+	if err != nil {
+		t.Fatalf("subscribe all: %v", err)
+	}
+	only, err := hub.Subscribe([]int64{7})
+	if err != nil {
+		t.Fatalf("subscribe host 7: %v", err)
+	}
+	if _, err := hub.Subscribe(nil); !errors.Is(err, ErrTooManyDashboardClients) {
+		t.Fatalf("third subscribe err = %v, want ErrTooManyDashboardClients", err)
+	}
+
+	hub.Publish(DashboardEvent{Type: DashboardEventHostMetrics, AgentHostID: 3})
+	hub.Publish(DashboardEvent{Type: DashboardEventHostOffline, AgentHostID: 7})
+
+	if got := len(all.Events()); got != 2 {
+		t.Fatalf("unfiltered subscriber got %d events, want 2", got)
+	}
+	if got := len(only.Events()); got != 1 {
+		t.Fatalf("filtered subscriber got %d events, want 1", got)
+	}
+	if event := <-only.Events(); event.AgentHostID != 7 || event.Timestamp == 0 {
+		t.Fatalf("filtered event = %+v, want host 7 with timestamp", event)
+	}
+
+	only.SetHosts(nil)
+	hub.Publish(DashboardEvent{Type: DashboardEventHostMetrics, AgentHostID: 3})
+	if got := len(only.Events()); got != 1 {
+		t.Fatalf("cleared filter got %d events, want 1", got)
+	}
+
+	only.Close()
+	only.Close()
+	if _, err := hub.Subscribe(nil); err != nil {
+		t.Fatalf("subscribe after close: %v", err)
+	}
+}
+
+func TestDashboardHubNilIsNoop(t *testing.T) {
+	var hub *DashboardHub
+	hub.Publish(DashboardEvent{Type: DashboardEventCoreSwitch, AgentHostID: 1})
+	if hub.HasSubscribers() {
+		t.Fatal("nil hub must report no subscribers")
+	}
+}
@@ -65,8 +65,16 @@ var (
 	ErrResetTrafficNotAllowed = errors.New("service: reset traffic not allowed / 不允许重置流量")
 	// ErrUserNotEligible indicates the user cannot access subscription data.
 	ErrUserNotEligible = errors.New("service: user not eligible for subscription / 用户不满足订阅条件")
+	// ErrNoStackNodes indicates no subscription node is reachable on the requested IP stack.
+	ErrNoStackNodes = errors.New("service: no nodes reachable on the requested IP stack / 没有可通过所请求 IP 协议栈访问的节点")
 	// ErrNotImplemented indicates functionality has not been ported yet.
 	ErrNotImplemented = errors.New("service: not implemented / 功能未实现")
 	// ErrAlreadyInitialized indicates the install wizard should not run again.
 	ErrAlreadyInitialized = errors.New("service: already initialized / 已完成初始化")
+	// ErrAccountLocked indicates the login identifier is locked after repeated failures.
+	ErrAccountLocked = errors.New("service: account locked / 账号已临时锁定")
+	// ErrTooManyDashboardClients indicates the live dashboard connection cap has been reached.
+	ErrTooManyDashboardClients = errors.New("service: too many live dashboard connections / 实时面板连接数已达上限")
+	// ErrConflict indicates the record was modified by someone else since it was loaded.
+	ErrConflict = errors.New("service: conflict, record modified by someone else / 数据已被他人修改，请刷新后重试")
 )
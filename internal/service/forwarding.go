@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net"
+	"strconv"
 
 	"github.com/creamcroissant/xboard/internal/repository"
 	chiMiddleware "github.com/go-chi/chi/v5/middleware"
@@ -51,6 +52,8 @@ type ForwardingService interface {
 
 	// Agent 应用结果上报
 	LogApplyResult(ctx context.Context, agentHostID int64, ruleID *int64, success bool, detail string) error
+	// Agent 目标健康状态变化上报
+	RecordTargetHealth(ctx context.Context, agentHostID int64, reports []ForwardingTargetHealthReport) error
 
 	// 审计日志查询
 	GetLogs(ctx context.Context, filter repository.ForwardingRuleLogFilter) ([]*repository.ForwardingRuleLog, error)
@@ -59,29 +62,41 @@ type ForwardingService interface {
 
 // CreateForwardingRuleRequest 创建转发规则请求
 type CreateForwardingRuleRequest struct {
-	AgentHostID   int64  // 关联的 Agent 主机 ID
-	Name          string // 规则名称
-	Protocol      string // tcp/udp/both
-	ListenPort    int    // 本地监听端口
-	TargetAddress string // 目标地址
-	TargetPort    int    // 目标端口
-	Enabled       bool   // 是否启用
-	Priority      int    // 优先级
-	Remark        string // 备注
-	OperatorID    *int64 // 操作人 ID（管理员）
+	AgentHostID         int64                         // 关联的 Agent 主机 ID
+	Name                string                        // 规则名称
+	Protocol            string                        // tcp/udp/both
+	ListenPort          int                           // 本地监听端口
+	TargetAddress       string                        // 目标地址
+	TargetPort          int                           // 目标端口
+	Targets             []repository.ForwardingTarget // 多目标加权轮询，非空时优先于单目标字段
+	HealthCheckInterval int                           // 健康检查间隔（秒），0 表示不检查
+	HealthCheckTimeout  int                           // 单次探测超时（秒），0 表示使用 Agent 默认值
+	Enabled             bool                          // 是否启用
+	Priority            int                           // 优先级
+	Remark              string                        // 备注
+	OperatorID          *int64                        // 操作人 ID（管理员）
 }
 
 // UpdateForwardingRuleRequest 更新转发规则请求
 type UpdateForwardingRuleRequest struct {
-	Name          *string // 规则名称
-	Protocol      *string // tcp/udp/both
-	ListenPort    *int    // 本地监听端口
-	TargetAddress *string // 目标地址
-	TargetPort    *int    // 目标端口
-	Enabled       *bool   // 是否启用
-	Priority      *int    // 优先级
-	Remark        *string // 备注
-	OperatorID    *int64  // 操作人 ID（管理员）
+	Name                *string                        // 规则名称
+	Protocol            *string                        // tcp/udp/both
+	ListenPort          *int                           // 本地监听端口
+	TargetAddress       *string                        // 目标地址
+	TargetPort          *int                           // 目标端口
+	Targets             *[]repository.ForwardingTarget // 多目标加权轮询，整体替换
+	HealthCheckInterval *int                           // 健康检查间隔（秒）
+	HealthCheckTimeout  *int                           // 单次探测超时（秒）
+	Enabled             *bool                          // 是否启用
+	Priority            *int                           // 优先级
+	Remark              *string                        // 备注
+	OperatorID          *int64                         // 操作人 ID（管理员）
+}
+
+// ForwardingTargetHealthReport 是 Agent 上报的单个目标健康状态变化
+type ForwardingTargetHealthReport struct {
+	RuleID int64
+	Health repository.ForwardingTargetHealth
 }
 
 // 校验错误
@@ -91,6 +106,9 @@ var (
 	ErrInvalidTargetPort    = errors.New("invalid target port: must be between 1 and 65535 / 目标端口无效：必须在 1-65535 之间")
 	ErrInvalidTargetAddress = errors.New("invalid target address: must be a valid IP / 目标地址无效：需为合法 IP")
 	ErrPortConflict         = errors.New("port conflict: another rule is already using this port/protocol combination / 端口冲突：该端口与协议组合已被占用")
+	ErrTargetRequired       = errors.New("at least one forwarding target is required / 至少需要一个转发目标")
+	ErrInvalidTargetWeight  = errors.New("invalid target weight: must be positive / 目标权重无效：必须为正数")
+	ErrInvalidHealthCheck   = errors.New("invalid health check: interval and timeout must not be negative, and timeout must not exceed interval / 健康检查参数无效：间隔与超时不能为负，且超时不能大于间隔")
 	ErrRuleNameRequired     = errors.New("rule name is required / 规则名称不能为空")
 	ErrAgentHostRequired    = errors.New("agent host ID is required / 必须指定节点 ID")
 )
@@ -156,16 +174,19 @@ func (s *forwardingService) CreateRule(ctx context.Context, req CreateForwarding
 
 	// 创建规则
 	rule := &repository.ForwardingRule{
-		AgentHostID:   req.AgentHostID,
-		Name:          req.Name,
-		Protocol:      req.Protocol,
-		ListenPort:    req.ListenPort,
-		TargetAddress: req.TargetAddress,
-		TargetPort:    req.TargetPort,
-		Enabled:       req.Enabled,
-		Priority:      req.Priority,
-		Remark:        req.Remark,
-	}
+		AgentHostID:         req.AgentHostID,
+		Name:                req.Name,
+		Protocol:            req.Protocol,
+		ListenPort:          req.ListenPort,
+		TargetAddress:       req.TargetAddress,
+		TargetPort:          req.TargetPort,
+		Enabled:             req.Enabled,
+		Priority:            req.Priority,
+		Remark:              req.Remark,
+		HealthCheckInterval: req.HealthCheckInterval,
+		HealthCheckTimeout:  req.HealthCheckTimeout,
+	}
+	applyForwardingTargets(rule, req.Targets)
 
 	if err := s.rules.Create(ctx, rule); err != nil {
 		return nil, fmt.Errorf("create rule: %w", err)
@@ -222,6 +243,24 @@ func (s *forwardingService) UpdateRule(ctx context.Context, id int64, req Update
 		}
 		rule.TargetPort = *req.TargetPort
 	}
+	if req.Targets != nil {
+		if err := validateForwardingTargets(*req.Targets); err != nil {
+			return nil, err
+		}
+		applyForwardingTargets(rule, *req.Targets)
+	} else if req.TargetAddress != nil || req.TargetPort != nil {
+		// 只改单目标字段时回到单目标模式，避免旧的多目标列表覆盖本次修改
+		rule.Targets = nil
+	}
+	if req.HealthCheckInterval != nil {
+		rule.HealthCheckInterval = *req.HealthCheckInterval
+	}
+	if req.HealthCheckTimeout != nil {
+		rule.HealthCheckTimeout = *req.HealthCheckTimeout
+	}
+	if !isValidHealthCheck(rule.HealthCheckInterval, rule.HealthCheckTimeout) {
+		return nil, ErrInvalidHealthCheck
+	}
 	if req.Enabled != nil {
 		rule.Enabled = *req.Enabled
 	}
@@ -319,6 +358,79 @@ func (s *forwardingService) LogApplyResult(ctx context.Context, agentHostID int6
 	return s.logs.Create(ctx, log)
 }
 
+// RecordTargetHealth 记录 Agent 上报的目标健康状态变化：逐条写审计日志，并合并到规则的当前健康状态
+func (s *forwardingService) RecordTargetHealth(ctx context.Context, agentHostID int64, reports []ForwardingTargetHealthReport) error {
+	byRule := make(map[int64][]repository.ForwardingTargetHealth)
+	order := make([]int64, 0)
+	for _, report := range reports {
+		if _, ok := byRule[report.RuleID]; !ok {
+			order = append(order, report.RuleID)
+		}
+		byRule[report.RuleID] = append(byRule[report.RuleID], report.Health)
+	}
+
+	for _, ruleID := range order {
+		rule, err := s.rules.FindByID(ctx, ruleID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				// 规则已被删除，Agent 下次同步后会停止探测
+				continue
+			}
+			return fmt.Errorf("find forwarding rule: %w", err)
+		}
+		if rule.AgentHostID != agentHostID {
+			continue
+		}
+
+		changes := byRule[ruleID]
+		for _, change := range changes {
+			detail, err := json.Marshal(map[string]any{
+				"event":      "target_health",
+				"address":    change.Address,
+				"port":       change.Port,
+				"healthy":    change.Healthy,
+				"error":      change.Error,
+				"checked_at": change.CheckedAt,
+			})
+			if err != nil {
+				detail = []byte("{}")
+			}
+			id := ruleID
+			if err := s.LogApplyResult(ctx, agentHostID, &id, change.Healthy, string(detail)); err != nil {
+				s.logger.Warn("failed to write forwarding health log", "error", err, "rule_id", ruleID, "agent_host_id", agentHostID)
+			}
+		}
+
+		if err := s.rules.UpdateTargetHealth(ctx, ruleID, mergeTargetHealth(rule, changes)); err != nil {
+			return fmt.Errorf("update forwarding target health: %w", err)
+		}
+	}
+	return nil
+}
+
+// mergeTargetHealth 用新上报的状态覆盖旧状态，并丢弃已不在规则目标列表中的条目
+func mergeTargetHealth(rule *repository.ForwardingRule, changes []repository.ForwardingTargetHealth) []repository.ForwardingTargetHealth {
+	key := func(address string, port int) string {
+		return net.JoinHostPort(address, strconv.Itoa(port))
+	}
+	current := make(map[string]repository.ForwardingTargetHealth, len(rule.TargetHealth)+len(changes))
+	for _, h := range rule.TargetHealth {
+		current[key(h.Address, h.Port)] = h
+	}
+	for _, h := range changes {
+		current[key(h.Address, h.Port)] = h
+	}
+
+	targets := rule.EffectiveTargets()
+	merged := make([]repository.ForwardingTargetHealth, 0, len(targets))
+	for _, target := range targets {
+		if h, ok := current[key(target.Address, target.Port)]; ok {
+			merged = append(merged, h)
+		}
+	}
+	return merged
+}
+
 // GetLogs 获取审计日志列表
 func (s *forwardingService) GetLogs(ctx context.Context, filter repository.ForwardingRuleLogFilter) ([]*repository.ForwardingRuleLog, error) {
 	return s.logs.List(ctx, filter)
@@ -343,6 +455,15 @@ func (s *forwardingService) validateCreateRequest(req CreateForwardingRuleReques
 	if !isValidPort(req.ListenPort) {
 		return ErrInvalidListenPort
 	}
+	if !isValidHealthCheck(req.HealthCheckInterval, req.HealthCheckTimeout) {
+		return ErrInvalidHealthCheck
+	}
+	if len(req.Targets) > 0 {
+		return validateForwardingTargets(req.Targets)
+	}
+	if req.TargetAddress == "" && req.TargetPort == 0 {
+		return ErrTargetRequired
+	}
 	if !isValidPort(req.TargetPort) {
 		return ErrInvalidTargetPort
 	}
@@ -352,6 +473,35 @@ func (s *forwardingService) validateCreateRequest(req CreateForwardingRuleReques
 	return nil
 }
 
+// validateForwardingTargets 校验多目标列表：至少一个目标，地址、端口合法且权重为正
+func validateForwardingTargets(targets []repository.ForwardingTarget) error {
+	if len(targets) == 0 {
+		return ErrTargetRequired
+	}
+	for _, target := range targets {
+		if !isValidAddress(target.Address) {
+			return ErrInvalidTargetAddress
+		}
+		if !isValidPort(target.Port) {
+			return ErrInvalidTargetPort
+		}
+		if target.Weight <= 0 {
+			return ErrInvalidTargetWeight
+		}
+	}
+	return nil
+}
+
+// applyForwardingTargets 写入多目标列表，并用第一个目标回填单目标字段以兼容旧版 Agent
+func applyForwardingTargets(rule *repository.ForwardingRule, targets []repository.ForwardingTarget) {
+	if len(targets) == 0 {
+		return
+	}
+	rule.Targets = append([]repository.ForwardingTarget(nil), targets...)
+	rule.TargetAddress = targets[0].Address
+	rule.TargetPort = targets[0].Port
+}
+
 // logAction 记录审计日志
 func (s *forwardingService) logAction(ctx context.Context, ruleID, agentHostID int64, action string, operatorID *int64, detail interface{}) error {
 	detailJSON, err := json.Marshal(detail)
@@ -385,6 +535,14 @@ func isValidProtocol(protocol string) bool {
 	return false
 }
 
+// isValidHealthCheck 校验健康检查参数：均不为负，启用时超时不超过间隔
+func isValidHealthCheck(interval, timeout int) bool {
+	if interval < 0 || timeout < 0 {
+		return false
+	}
+	return interval == 0 || timeout <= interval
+}
+
 // isValidPort 校验端口是否有效
 func isValidPort(port int) bool {
 	return port >= MinPort && port <= MaxPort
@@ -0,0 +1,317 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/async"
+	"github.com/creamcroissant/xboard/internal/notifier"
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+// NotificationService 监听节点主机上下线变化并推送 webhook 与 Telegram 告警。
+type NotificationService interface {
+	// CheckAgentHosts 依据心跳新鲜度判定主机状态，在 online↔offline 切换时投递通知。
+	CheckAgentHosts(ctx context.Context) error
+	// SetDashboardEvents 注入实时面板事件中心，上下线事件同时推送给在线的管理端连接。
+	SetDashboardEvents(hub *DashboardHub)
+}
+
+// Node webhook 相关系统设置（node 分类）。
+const (
+	nodeWebhookEnableSetting         = "node_webhook_enable"
+	nodeWebhookURLsSetting           = "node_webhook_urls"
+	nodeWebhookMinDownSecondsSetting = "node_webhook_min_down_seconds"
+
+	defaultNodeWebhookMinDownSeconds = 120
+	nodeWebhookDeliveryTimeout       = 2 * time.Minute
+)
+
+// Node webhook 事件类型。
+const (
+	NodeWebhookEventOffline = "node.offline"
+	NodeWebhookEventOnline  = "node.online"
+)
+
+// NodeWebhookPayload 是推送给 webhook 的 JSON 结构。
+// text/content 分别兼容 Slack 与 Discord 的入站 webhook，其余字段供自定义接收端解析。
+type NodeWebhookPayload struct {
+	Event           string             `json:"event"`
+	Text            string             `json:"text"`
+	Content         string             `json:"content"`
+	Host            NodeWebhookHost    `json:"host"`
+	Metrics         NodeWebhookMetrics `json:"metrics"`
+	LastHeartbeatAt int64              `json:"last_heartbeat_at"`
+	DowntimeSeconds int64              `json:"downtime_seconds"`
+	Timestamp       int64              `json:"timestamp"`
+}
+
+// NodeWebhookHost 描述触发事件的主机。
+type NodeWebhookHost struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Host string `json:"host"`
+}
+
+// NodeWebhookMetrics 是主机最近一次上报的指标。
+type NodeWebhookMetrics struct {
+	CPUUsed         float64 `json:"cpu_used"`
+	MemTotal        int64   `json:"mem_total"`
+	MemUsed         int64   `json:"mem_used"`
+	DiskTotal       int64   `json:"disk_total"`
+	DiskUsed        int64   `json:"disk_used"`
+	UploadRateBps   int64   `json:"upload_rate_bps"`
+	DownloadRateBps int64   `json:"download_rate_bps"`
+	ReportedAt      int64   `json:"reported_at"`
+}
+
+// agentHostWatchState 记录单台主机的判定结果，仅保存在内存中；面板重启后以首次观测为基线，不补发事件。
+type agentHostWatchState struct {
+	online    bool
+	offlineAt int64 // 首次判定离线的时间，用于防抖
+	downSince int64 // 离线前最后一次心跳时间
+	notified  bool  // 本次离线是否已发送离线通知
+}
+
+type notificationService struct {
+	agentHosts repository.AgentHostRepository
+	settings   repository.SettingRepository
+	webhook    *notifier.WebhookClient
+	queue      *async.NotificationQueue
+	logger     *slog.Logger
+	now        func() time.Time
+	dashboard  *DashboardHub
+
+	mu     sync.Mutex
+	states map[int64]*agentHostWatchState
+}
+
+// NewNotificationService 创建节点上下线通知服务；queue 非空时同时向 telegram_admin_id 推送 Telegram 告警。
+func NewNotificationService(agentHosts repository.AgentHostRepository, settings repository.SettingRepository, webhook *notifier.WebhookClient, queue *async.NotificationQueue, logger *slog.Logger) NotificationService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if webhook == nil {
+		webhook = notifier.NewWebhookClient(nil)
+	}
+	return &notificationService{
+		agentHosts: agentHosts,
+		settings:   settings,
+		webhook:    webhook,
+		queue:      queue,
+		logger:     logger,
+		now:        time.Now,
+		states:     make(map[int64]*agentHostWatchState),
+	}
+}
+
+func (s *notificationService) SetDashboardEvents(hub *DashboardHub) {
+	s.dashboard = hub
+}
+
+func (s *notificationService) CheckAgentHosts(ctx context.Context) error {
+	if s == nil || s.agentHosts == nil {
+		return fmt.Errorf("notification service not configured / 通知服务未配置")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	urls := s.webhookURLs(ctx)
+	if !s.boolSetting(ctx, nodeWebhookEnableSetting, false) {
+		urls = nil
+	}
+	adminChat := ""
+	if s.queue != nil {
+		adminChat = s.settingString(ctx, telegramAdminIDSetting, "")
+	}
+	if len(urls) == 0 && adminChat == "" && !s.dashboard.HasSubscribers() {
+		// 关闭期间不保留状态，重新开启时以当前状态为基线，避免补发过期事件
+		s.states = make(map[int64]*agentHostWatchState)
+		return nil
+	}
+	minDown := int64(s.intSetting(ctx, nodeWebhookMinDownSecondsSetting, defaultNodeWebhookMinDownSeconds))
+	if minDown < 0 {
+		minDown = 0
+	}
+
+	hosts, err := s.agentHosts.ListAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := s.now().Unix()
+	seen := make(map[int64]struct{}, len(hosts))
+	for _, host := range hosts {
+		if host == nil {
+			continue
+		}
+		seen[host.ID] = struct{}{}
+		// 告警态（心跳延迟但未超过离线阈值）仍视为在线
+		online := host.HeartbeatState(now) != repository.AgentHostStateOffline
+
+		state, ok := s.states[host.ID]
+		if !ok {
+			state = &agentHostWatchState{online: online}
+			if !online {
+				state.offlineAt = now
+				state.downSince = host.LastHeartbeatAt
+			}
+			s.states[host.ID] = state
+			continue
+		}
+
+		if online {
+			if !state.online && state.notified {
+				downtime := host.LastHeartbeatAt - state.downSince
+				s.dispatch(urls, adminChat, buildNodeWebhookPayload(NodeWebhookEventOnline, host, downtime, now))
+			}
+			*state = agentHostWatchState{online: true}
+			continue
+		}
+
+		if state.online {
+			state.online = false
+			state.offlineAt = now
+			state.downSince = host.LastHeartbeatAt
+			state.notified = false
+		}
+		// 离线持续时间未达到阈值前不通知，过滤短暂抖动
+		if !state.notified && now-state.offlineAt >= minDown {
+			state.notified = true
+			downtime := int64(0)
+			if state.downSince > 0 {
+				downtime = now - state.downSince
+			}
+			s.dispatch(urls, adminChat, buildNodeWebhookPayload(NodeWebhookEventOffline, host, downtime, now))
+		}
+	}
+
+	for id := range s.states {
+		if _, ok := seen[id]; !ok {
+			delete(s.states, id)
+		}
+	}
+	return nil
+}
+
+// dispatch 异步投递到所有 webhook，重试在 WebhookClient 内完成，不阻塞状态扫描；
+// Telegram 告警进入通知队列，由 notify.telegram 任务发送；实时面板连接直接收到同一份事件。
+func (s *notificationService) dispatch(urls []string, adminChat string, payload NodeWebhookPayload) {
+	s.dashboard.Publish(DashboardEvent{Type: payload.Event, AgentHostID: payload.Host.ID, Timestamp: payload.Timestamp, Data: payload})
+	if adminChat != "" {
+		for _, chatID := range strings.Split(adminChat, ",") {
+			if chatID = strings.TrimSpace(chatID); chatID != "" {
+				s.queue.EnqueueTelegram(notifier.TelegramRequest{ChatID: chatID, Message: payload.Text})
+			}
+		}
+	}
+	for _, url := range urls {
+		go func(url string) {
+			ctx, cancel := context.WithTimeout(context.Background(), nodeWebhookDeliveryTimeout)
+			defer cancel()
+			if err := s.webhook.PostJSON(ctx, url, payload); err != nil {
+				s.logger.Warn("node webhook delivery failed", "event", payload.Event, "host_id", payload.Host.ID, "error", err)
+				return
+			}
+			s.logger.Info("node webhook delivered", "event", payload.Event, "host_id", payload.Host.ID)
+		}(url)
+	}
+}
+
+func buildNodeWebhookPayload(event string, host *repository.AgentHost, downtime int64, now int64) NodeWebhookPayload {
+	if downtime < 0 {
+		downtime = 0
+	}
+	name := host.Name
+	if name == "" {
+		name = host.Host
+	}
+	var text string
+	switch event {
+	case NodeWebhookEventOffline:
+		text = fmt.Sprintf("Node %s (ID: %d) is offline, down for %s", name, host.ID, time.Duration(downtime)*time.Second)
+	default:
+		text = fmt.Sprintf("Node %s (ID: %d) is back online after %s", name, host.ID, time.Duration(downtime)*time.Second)
+	}
+	return NodeWebhookPayload{
+		Event:   event,
+		Text:    text,
+		Content: text,
+		Host: NodeWebhookHost{
+			ID:   host.ID,
+			Name: host.Name,
+			Host: host.Host,
+		},
+		Metrics: NodeWebhookMetrics{
+			CPUUsed:         host.CPUUsed,
+			MemTotal:        host.MemTotal,
+			MemUsed:         host.MemUsed,
+			DiskTotal:       host.DiskTotal,
+			DiskUsed:        host.DiskUsed,
+			UploadRateBps:   host.UploadRateBps,
+			DownloadRateBps: host.DownloadRateBps,
+			ReportedAt:      host.LastRealtimeReportAt,
+		},
+		LastHeartbeatAt: host.LastHeartbeatAt,
+		DowntimeSeconds: downtime,
+		Timestamp:       now,
+	}
+}
+
+// webhookURLs 解析 node_webhook_urls，支持换行或逗号分隔多个地址。
+func (s *notificationService) webhookURLs(ctx context.Context) []string {
+	raw := s.settingString(ctx, nodeWebhookURLsSetting, "")
+	if raw == "" {
+		return nil
+	}
+	fields := strings.FieldsFunc(raw, func(r rune) bool {
+		return r == ',' || r == '\n' || r == '\r'
+	})
+	urls := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if url := strings.TrimSpace(field); url != "" {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
+func (s *notificationService) settingString(ctx context.Context, key, def string) string {
+	if s == nil || s.settings == nil {
+		return def
+	}
+	setting, err := s.settings.Get(ctx, key)
+	if err != nil || setting == nil {
+		return def
+	}
+	value := strings.TrimSpace(setting.Value)
+	if value == "" {
+		return def
+	}
+	return value
+}
+
+func (s *notificationService) boolSetting(ctx context.Context, key string, def bool) bool {
+	switch strings.ToLower(s.settingString(ctx, key, "")) {
+	case "1", "true", "yes", "on":
+		return true
+	case "0", "false", "no", "off":
+		return false
+	default:
+		return def
+	}
+}
+
+func (s *notificationService) intSetting(ctx context.Context, key string, def int) int {
+	value, err := strconv.Atoi(s.settingString(ctx, key, ""))
+	if err != nil {
+		return def
+	}
+	return value
+}
@@ -0,0 +1,136 @@
+// 文件路径: internal/service/online_device.go
+// 模块说明: 这是 internal 模块里的 online_device 逻辑，下面的注释会用非常通俗的中文帮你理解每一步。
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+const (
+	// defaultOnlineDeviceWindowSeconds 为在线设备的默认有效窗口，超过窗口未上报即视为离线。
+	defaultOnlineDeviceWindowSeconds = 300
+	onlineDeviceWindowSettingKey     = "device_online_window_seconds"
+)
+
+// OnlineDeviceEntry 描述 Agent 上报的一条在线设备（用户 + 来源 IP）。
+type OnlineDeviceEntry struct {
+	UserID int64
+	IP     string
+}
+
+// OnlineDeviceService 负责记录 Agent 上报的在线设备，并按 device_limit 判断超限用户。
+type OnlineDeviceService interface {
+	// RecordAlive 写入在线设备并返回本次涉及用户中超出设备数限制的用户 ID。
+	RecordAlive(ctx context.Context, agentHostID int64, entries []OnlineDeviceEntry) ([]int64, error)
+	// OnlineCounts 返回窗口内每个用户的去重 IP 数量。
+	OnlineCounts(ctx context.Context, userIDs []int64) (map[int64]int, error)
+	// OverLimitUserIDs 返回给定用户中在线 IP 数超过 device_limit 的用户 ID（升序）。
+	OverLimitUserIDs(ctx context.Context, userIDs []int64) ([]int64, error)
+	// WindowSeconds 返回当前生效的在线窗口（秒），Agent 以此作为超限用户的暂停时长。
+	WindowSeconds(ctx context.Context) int64
+}
+
+type onlineDeviceService struct {
+	devices  repository.UserOnlineDeviceRepository
+	settings repository.SettingRepository
+	now      func() time.Time
+}
+
+// NewOnlineDeviceService 组装在线设备服务。
+func NewOnlineDeviceService(devices repository.UserOnlineDeviceRepository, settings repository.SettingRepository) OnlineDeviceService {
+	return &onlineDeviceService{
+		devices:  devices,
+		settings: settings,
+		now:      time.Now,
+	}
+}
+
+func (s *onlineDeviceService) RecordAlive(ctx context.Context, agentHostID int64, entries []OnlineDeviceEntry) ([]int64, error) {
+	if s == nil || s.devices == nil {
+		return nil, fmt.Errorf("online device service not configured / 在线设备服务未配置")
+	}
+	if agentHostID <= 0 {
+		return nil, fmt.Errorf("%w: agent_host_id is required / 缺少 agent_host_id", ErrBadRequest)
+	}
+	now := s.now().Unix()
+	// 同一批次内去重，避免重复的 (user, ip) 多次写库
+	seen := make(map[string]struct{}, len(entries))
+	devices := make([]*repository.UserOnlineDevice, 0, len(entries))
+	userIDs := make([]int64, 0, len(entries))
+	seenUsers := make(map[int64]struct{}, len(entries))
+	for _, entry := range entries {
+		ip := strings.TrimSpace(entry.IP)
+		if entry.UserID <= 0 || ip == "" {
+			continue
+		}
+		key := strconv.FormatInt(entry.UserID, 10) + "|" + ip
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		devices = append(devices, &repository.UserOnlineDevice{AgentHostID: agentHostID, UserID: entry.UserID, IP: ip, LastSeenAt: now})
+		if _, ok := seenUsers[entry.UserID]; !ok {
+			seenUsers[entry.UserID] = struct{}{}
+			userIDs = append(userIDs, entry.UserID)
+		}
+	}
+	// 先清理过期记录，保证表里只保留窗口内的数据
+	if _, err := s.devices.DeleteBefore(ctx, now-s.windowSeconds(ctx)); err != nil {
+		return nil, err
+	}
+	if len(devices) == 0 {
+		return []int64{}, nil
+	}
+	if err := s.devices.UpsertBatch(ctx, devices); err != nil {
+		return nil, err
+	}
+	return s.OverLimitUserIDs(ctx, userIDs)
+}
+
+func (s *onlineDeviceService) OnlineCounts(ctx context.Context, userIDs []int64) (map[int64]int, error) {
+	if s == nil || s.devices == nil {
+		return map[int64]int{}, nil
+	}
+	if len(userIDs) == 0 {
+		return map[int64]int{}, nil
+	}
+	since := s.now().Unix() - s.windowSeconds(ctx)
+	return s.devices.CountDistinctIPs(ctx, userIDs, since)
+}
+
+func (s *onlineDeviceService) OverLimitUserIDs(ctx context.Context, userIDs []int64) ([]int64, error) {
+	if s == nil || s.devices == nil || len(userIDs) == 0 {
+		return []int64{}, nil
+	}
+	// 单条查询按 device_limit 过滤，避免每次流量上报逐个加载用户
+	since := s.now().Unix() - s.windowSeconds(ctx)
+	return s.devices.ListOverDeviceLimit(ctx, userIDs, since)
+}
+
+func (s *onlineDeviceService) WindowSeconds(ctx context.Context) int64 {
+	if s == nil {
+		return defaultOnlineDeviceWindowSeconds
+	}
+	return s.windowSeconds(ctx)
+}
+
+// windowSeconds 读取在线窗口配置，非法值回退默认值。
+func (s *onlineDeviceService) windowSeconds(ctx context.Context) int64 {
+	if s.settings == nil {
+		return defaultOnlineDeviceWindowSeconds
+	}
+	setting, err := s.settings.Get(ctx, onlineDeviceWindowSettingKey)
+	if err != nil || setting == nil {
+		return defaultOnlineDeviceWindowSeconds
+	}
+	if value, err := strconv.Atoi(strings.TrimSpace(setting.Value)); err == nil && value > 0 {
+		return int64(value)
+	}
+	return defaultOnlineDeviceWindowSeconds
+}
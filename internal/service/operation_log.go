@@ -21,6 +21,8 @@ const (
 	OperationLogScopeAgentTraffic    = "agent_traffic"
 	OperationLogScopeTrafficReset    = "traffic_reset"
 	OperationLogScopeThresholdAction = "threshold_action"
+	OperationLogScopeConfigSync      = "config_sync"
+	OperationLogScopeCoreCapability  = "core_capability"
 
 	OperationLogLevelDebug = "debug"
 	OperationLogLevelInfo  = "info"
@@ -242,6 +244,10 @@ func normalizeOperationLogScope(scope string) (string, error) {
 		return OperationLogScopeTrafficReset, nil
 	case OperationLogScopeThresholdAction:
 		return OperationLogScopeThresholdAction, nil
+	case OperationLogScopeConfigSync:
+		return OperationLogScopeConfigSync, nil
+	case OperationLogScopeCoreCapability:
+		return OperationLogScopeCoreCapability, nil
 	default:
 		return "", ErrOperationLogInvalidRequest
 	}
@@ -46,6 +46,8 @@ type PlanView struct {
 	Sell               bool     `json:"sell"`
 	Renew              bool     `json:"renew"`
 	ResetTrafficMethod *int64   `json:"reset_traffic_method"`
+	ResetDay           *int64   `json:"reset_day"`
+	ResetCycle         string   `json:"reset_cycle"`
 	Sort               int64    `json:"sort"`
 	CreatedAt          int64    `json:"created_at"`
 	UpdatedAt          int64    `json:"updated_at"`
@@ -63,6 +65,8 @@ type AdminPlanView struct {
 	Group            *PlanGroupView `json:"group,omitempty"`
 	UsersCount       int64          `json:"users_count"`
 	ActiveUsersCount int64          `json:"active_users_count"`
+	// SubscriptionTemplateID 为套餐默认订阅模板，null 表示使用系统默认模板
+	SubscriptionTemplateID *int64 `json:"subscription_template_id"`
 }
 
 // PlanPurchaseInput 表示校验购买请求所需字段。
@@ -166,7 +170,7 @@ func (s *planService) AdminPlans(ctx context.Context) ([]AdminPlanView, error) {
 	}
 	result := make([]AdminPlanView, 0, len(plans))
 	for _, plan := range plans {
-		view := AdminPlanView{PlanView: s.buildPlanView(ctx, plan)}
+		view := AdminPlanView{PlanView: s.buildPlanView(ctx, plan), SubscriptionTemplateID: plan.SubscriptionTemplateID}
 		if plan.GroupID != nil {
 			if group, ok := groupMap[*plan.GroupID]; ok {
 				copy := *group
@@ -375,6 +379,8 @@ func (s *planService) buildPlanView(ctx context.Context, plan *repository.Plan)
 		Sell:               plan.Sell,
 		Renew:              plan.Renew,
 		ResetTrafficMethod: plan.ResetTrafficMethod,
+		ResetDay:           plan.ResetDay,
+		ResetCycle:         plan.ResetCycle,
 		Sort:               plan.Sort,
 		CreatedAt:          plan.CreatedAt,
 		UpdatedAt:          plan.UpdatedAt,
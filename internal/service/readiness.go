@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/protocol"
+	"github.com/creamcroissant/xboard/internal/repository"
+	"github.com/creamcroissant/xboard/internal/template"
+)
+
+// DefaultReadinessTimeout 为单项就绪检查的超时，探针本身不会因依赖卡住而挂起。
+const DefaultReadinessTimeout = 2 * time.Second
+
+const (
+	ReadinessStatusReady    = "ready"
+	ReadinessStatusNotReady = "not_ready"
+	ReadinessCheckOK        = "ok"
+	ReadinessCheckFailed    = "failed"
+)
+
+// ReadinessCheck 是一项命名的依赖检查，返回 nil 表示健康。
+type ReadinessCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// ReadinessCheckResult 为单项检查结果；Error 仅在鉴权后的管理接口中返回，公开探针只暴露状态。
+type ReadinessCheckResult struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// ReadinessReport 汇总所有检查；任一检查失败时 Status 为 not_ready。
+type ReadinessReport struct {
+	Status string                          `json:"status"`
+	Checks map[string]ReadinessCheckResult `json:"checks"`
+}
+
+// Ready 表示所有依赖均健康。
+func (r *ReadinessReport) Ready() bool {
+	return r != nil && r.Status == ReadinessStatusReady
+}
+
+// Redacted 返回去掉错误详情的副本，供未鉴权的 /_internal/ready 输出，避免泄露连接串、表名等内部信息。
+func (r *ReadinessReport) Redacted() *ReadinessReport {
+	if r == nil {
+		return nil
+	}
+	out := &ReadinessReport{Status: r.Status, Checks: make(map[string]ReadinessCheckResult, len(r.Checks))}
+	for name, result := range r.Checks {
+		result.Error = ""
+		out.Checks[name] = result
+	}
+	return out
+}
+
+// ReadinessService 并发执行依赖检查，供 /_internal/ready 使用。
+type ReadinessService interface {
+	Check(ctx context.Context) *ReadinessReport
+}
+
+type readinessService struct {
+	timeout time.Duration
+	checks  []ReadinessCheck
+}
+
+// NewReadinessService 创建就绪检查服务，timeout <= 0 时使用 DefaultReadinessTimeout。
+func NewReadinessService(timeout time.Duration, checks ...ReadinessCheck) ReadinessService {
+	if timeout <= 0 {
+		timeout = DefaultReadinessTimeout
+	}
+	return &readinessService{timeout: timeout, checks: checks}
+}
+
+func (s *readinessService) Check(ctx context.Context) *ReadinessReport {
+	report := &ReadinessReport{Status: ReadinessStatusReady, Checks: make(map[string]ReadinessCheckResult, len(s.checks))}
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, check := range s.checks {
+		wg.Add(1)
+		go func(check ReadinessCheck) {
+			defer wg.Done()
+			result := s.run(ctx, check)
+			mu.Lock()
+			report.Checks[check.Name] = result
+			if result.Status != ReadinessCheckOK {
+				report.Status = ReadinessStatusNotReady
+			}
+			mu.Unlock()
+		}(check)
+	}
+	wg.Wait()
+	return report
+}
+
+// run 在超时内执行单项检查；检查函数忽略 ctx 时也按超时判定失败，不等待其返回。
+func (s *readinessService) run(ctx context.Context, check ReadinessCheck) ReadinessCheckResult {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- check.Check(ctx)
+	}()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out after %s", s.timeout)
+	}
+	result := ReadinessCheckResult{Status: ReadinessCheckOK, DurationMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Status = ReadinessCheckFailed
+		result.Error = err.Error()
+		slog.WarnContext(ctx, "readiness check failed", "check", check.Name, "error", err, "duration_ms", result.DurationMs)
+	}
+	return result
+}
+
+// DatabaseReadinessCheck 检查数据库连接可用。
+func DatabaseReadinessCheck(db interface {
+	PingContext(ctx context.Context) error
+}) ReadinessCheck {
+	return ReadinessCheck{Name: "database", Check: func(ctx context.Context) error {
+		if db == nil {
+			return errors.New("database not configured")
+		}
+		return db.PingContext(ctx)
+	}}
+}
+
+// RepositoryReadinessCheck 对核心仓库各执行一次按主键查询，未命中视为正常；
+// 同时能发现迁移未执行导致的缺表、缺列。
+func RepositoryReadinessCheck(settings repository.SettingRepository, users repository.UserRepository, agentHosts repository.AgentHostRepository) ReadinessCheck {
+	return ReadinessCheck{Name: "repositories", Check: func(ctx context.Context) error {
+		if settings == nil || users == nil || agentHosts == nil {
+			return errors.New("repositories not configured")
+		}
+		if _, err := settings.Get(ctx, "readiness_probe"); err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("settings: %w", err)
+		}
+		if _, err := users.FindByID(ctx, 0); err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("users: %w", err)
+		}
+		if _, err := agentHosts.FindByID(ctx, 0); err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("agent_hosts: %w", err)
+		}
+		return nil
+	}}
+}
+
+// TemplateReadinessCheck 检查配置转换器与订阅协议构建器均已注册。
+func TemplateReadinessCheck(converters *template.ConverterRegistry, protocols *protocol.Manager, requiredCores ...string) ReadinessCheck {
+	return ReadinessCheck{Name: "template_engine", Check: func(context.Context) error {
+		for _, core := range requiredCores {
+			if !converters.Has(core) {
+				return fmt.Errorf("converter not registered: %s", core)
+			}
+		}
+		if protocols == nil || len(protocols.Flags()) == 0 {
+			return errors.New("no protocol builders registered")
+		}
+		return nil
+	}}
+}
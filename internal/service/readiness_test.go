@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReadinessServiceReportsFailuresAndTimeouts(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	readiness := NewReadinessService(50*time.Millisecond,
+		ReadinessCheck{Name: "ok", Check: func(context.Context) error { return nil }},
+		ReadinessCheck{Name: "broken", Check: func(context.Context) error { return errors.New("connection refused") }},
+		// 忽略 ctx 的检查也必须在超时后返回
+		ReadinessCheck{Name: "stuck", Check: func(context.Context) error { <-block; return nil }},
+	)
+
+	start := time.Now()
+	report := readiness.Check(context.Background())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Check took %s, want bounded by timeout", elapsed)
+	}
+	if report.Ready() || report.Status != ReadinessStatusNotReady {
+		t.Fatalf("status = %q, want not_ready", report.Status)
+	}
+	if got := report.Checks["ok"].Status; got != ReadinessCheckOK {
+		t.Fatalf("ok check status = %q", got)
+	}
+	if got := report.Checks["broken"]; got.Status != ReadinessCheckFailed || got.Error != "connection refused" {
+		t.Fatalf("broken check = %+v", got)
+	}
+	if got := report.Checks["stuck"].Status; got != ReadinessCheckFailed {
+		t.Fatalf("stuck check status = %q, want failed", got)
+	}
+
+	// 公开探针只输出状态，错误详情留给日志与管理接口
+	public := report.Redacted()
+	if got := public.Checks["broken"]; got.Status != ReadinessCheckFailed || got.Error != "" {
+		t.Fatalf("redacted broken check = %+v", got)
+	}
+	if report.Checks["broken"].Error == "" {
+		t.Fatal("Redacted must not modify the original report")
+	}
+
+	if !NewReadinessService(0, ReadinessCheck{Name: "ok", Check: func(context.Context) error { return nil }}).Check(context.Background()).Ready() {
+		t.Fatal("all-healthy report must be ready")
+	}
+}
@@ -17,6 +17,9 @@ import (
 type ServerService interface {
 	ListForUser(ctx context.Context, userID string) (*ServerListResult, error)
 	Heartbeat(ctx context.Context, nodeID int) error
+	ListTagsForUser(ctx context.Context, userID string) ([]ServerTagCount, error)
+	// SetTelemetry 注入节点遥测，节点列表据此返回最近延迟。
+	SetTelemetry(telemetry ServerTelemetryService)
 }
 
 // ServerListResult 表示用户节点列表的返回结果。
@@ -36,12 +39,14 @@ type ServerNode struct {
 	IsOnline    int      `json:"is_online"`
 	CacheKey    string   `json:"cache_key"`
 	LastCheckAt int64    `json:"last_check_at"`
+	LatencyMs   *int64   `json:"latency_ms"` // 最近延迟（毫秒），未知时为 null
 }
 
 type serverService struct {
-	users   repository.UserRepository
-	servers repository.ServerRepository
-	plans   repository.PlanRepository
+	users     repository.UserRepository
+	servers   repository.ServerRepository
+	plans     repository.PlanRepository
+	telemetry ServerTelemetryService
 }
 
 // NewServerService 组装基于 repository 的依赖。
@@ -49,6 +54,10 @@ func NewServerService(users repository.UserRepository, servers repository.Server
 	return &serverService{users: users, servers: servers, plans: plans}
 }
 
+func (s *serverService) SetTelemetry(telemetry ServerTelemetryService) {
+	s.telemetry = telemetry
+}
+
 func (s *serverService) ListForUser(ctx context.Context, userID string) (*ServerListResult, error) {
 	if s == nil || s.users == nil || s.servers == nil {
 		return nil, fmt.Errorf("server service not fully configured / 节点服务未完整配置")
@@ -84,12 +93,19 @@ func (s *serverService) ListForUser(ctx context.Context, userID string) (*Server
 	if err != nil {
 		return nil, err
 	}
+	latencies := nodeLatencies(ctx, s.telemetry, nodes)
 	views := make([]ServerNode, 0, len(nodes))
 	cacheKeys := make([]string, 0, len(nodes))
 	for _, node := range nodes {
 		view := transformServerNode(node)
+		cacheKey := view.CacheKey
+		if latency, ok := serverLatency(node, latencies); ok {
+			view.LatencyMs = &latency
+			// 延迟变化也要让 ETag 失效，但不改变对外的 cache_key
+			cacheKey = fmt.Sprintf("%s-%d", cacheKey, latency)
+		}
 		views = append(views, view)
-		cacheKeys = append(cacheKeys, view.CacheKey)
+		cacheKeys = append(cacheKeys, cacheKey)
 	}
 	return &ServerListResult{Nodes: views, ETag: computeETag(cacheKeys)}, nil
 }
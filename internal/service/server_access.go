@@ -64,3 +64,24 @@ func queryServersForUser(ctx context.Context, repo repository.ServerRepository,
 	}
 	return repo.FindAllVisible(ctx)
 }
+
+// effectiveGroupIDs 汇总用户可访问的节点分组：用户直属分组 + 套餐绑定分组，结果去重。
+// 订阅下发、订阅过滤与管理端分组视图共用这一规则，保证三处判定一致。
+func effectiveGroupIDs(ctx context.Context, plans repository.PlanRepository, user *repository.User) ([]int64, error) {
+	if user == nil {
+		return nil, nil
+	}
+	groupIDs := make([]int64, 0, 4)
+	if user.GroupID > 0 {
+		groupIDs = append(groupIDs, user.GroupID)
+	}
+	if user.PlanID > 0 && plans != nil {
+		planGroups, err := plans.GetGroups(ctx, user.PlanID)
+		if err != nil {
+			// 分组信息影响访问控制，查询失败时直接返回错误
+			return nil, err
+		}
+		groupIDs = append(groupIDs, planGroups...)
+	}
+	return uniquePositive(groupIDs), nil
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/protocol"
+	"github.com/creamcroissant/xboard/internal/repository"
+	"github.com/creamcroissant/xboard/internal/support/i18n"
+)
+
+// SubscriptionSortLatency 为订阅 sort 参数取值，按节点最近延迟升序排列。
+const SubscriptionSortLatency = "latency"
+
+const (
+	// nodeLatencyStaleAfter 为延迟数据的有效期，超过该时长未更新的延迟视为未知而不是 0。
+	nodeLatencyStaleAfter = 10 * time.Minute
+	// nodeLatencyMaxMs 为接受的延迟上限，超出视为异常上报。
+	nodeLatencyMaxMs = 60_000
+)
+
+// nodeLatencySample 是缓存中保存的一次延迟测量。
+type nodeLatencySample struct {
+	LatencyMs  int64 `json:"latency_ms"`
+	MeasuredAt int64 `json:"measured_at"`
+}
+
+// RecordLatency 保存节点最近一次测得的延迟（毫秒）。
+func (s *serverTelemetryService) RecordLatency(ctx context.Context, server *repository.Server, latencyMs int64) error {
+	if err := ensureServer(server); err != nil {
+		return err
+	}
+	if s.cache == nil {
+		return fmt.Errorf("server telemetry cache unavailable / 节点遥测缓存不可用")
+	}
+	if latencyMs <= 0 || latencyMs > nodeLatencyMaxMs {
+		return fmt.Errorf("%w: latency %dms out of range / 延迟超出范围", ErrBadRequest, latencyMs)
+	}
+	sample := nodeLatencySample{LatencyMs: latencyMs, MeasuredAt: time.Now().Unix()}
+	return s.cache.SetJSON(ctx, serverCacheKey(server, "LATENCY"), sample, nodeLatencyStaleAfter)
+}
+
+// RecordHostLatency 把 Agent 主机测得的延迟记录到该主机下的全部节点。
+func (s *serverTelemetryService) RecordHostLatency(ctx context.Context, agentHostID int64, latencyMs int64) error {
+	if s.servers == nil {
+		return fmt.Errorf("server repository unavailable / 节点仓库不可用")
+	}
+	servers, err := s.servers.FindByAgentHostID(ctx, agentHostID)
+	if err != nil {
+		return fmt.Errorf("find servers of agent host %d: %v / 获取主机节点失败: %w", agentHostID, err, err)
+	}
+	for _, server := range servers {
+		if server == nil {
+			continue
+		}
+		if err := s.RecordLatency(ctx, server, latencyMs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NodeLatency 返回节点的最近延迟；没有数据或数据已过期时第二个返回值为 false。
+func (s *serverTelemetryService) NodeLatency(ctx context.Context, server *repository.Server) (int64, bool) {
+	if s.cache == nil || server == nil || server.ID <= 0 {
+		return 0, false
+	}
+	var sample nodeLatencySample
+	ok, err := s.cache.GetJSON(ctx, serverCacheKey(server, "LATENCY"), &sample)
+	if err != nil || !ok {
+		return 0, false
+	}
+	return sample.fresh(time.Now())
+}
+
+func (n nodeLatencySample) fresh(now time.Time) (int64, bool) {
+	if n.LatencyMs <= 0 || now.Unix()-n.MeasuredAt > int64(nodeLatencyStaleAfter.Seconds()) {
+		return 0, false
+	}
+	return n.LatencyMs, true
+}
+
+// nodeLatencies 批量查询节点延迟，只返回有效数据；telemetry 未配置时返回空表。
+func nodeLatencies(ctx context.Context, telemetry ServerTelemetryService, servers []*repository.Server) map[int64]int64 {
+	result := make(map[int64]int64, len(servers))
+	if telemetry == nil {
+		return result
+	}
+	for _, server := range servers {
+		if server == nil {
+			continue
+		}
+		if latency, ok := telemetry.NodeLatency(ctx, server); ok {
+			result[server.ID] = latency
+		}
+	}
+	return result
+}
+
+// sortServersByLatency 按延迟升序稳定排序，延迟未知的节点保持原有顺序排在最后。
+func sortServersByLatency(servers []*repository.Server, latencies map[int64]int64) {
+	sort.SliceStable(servers, func(i, j int) bool {
+		li, okI := serverLatency(servers[i], latencies)
+		lj, okJ := serverLatency(servers[j], latencies)
+		if okI != okJ {
+			return okI
+		}
+		return okI && li < lj
+	})
+}
+
+func serverLatency(server *repository.Server, latencies map[int64]int64) (int64, bool) {
+	if server == nil {
+		return 0, false
+	}
+	latency, ok := latencies[server.ID]
+	return latency, ok
+}
+
+// appendLatencyHints 在节点名称后追加延迟提示，只处理有有效延迟的面板节点。
+func appendLatencyHints(nodes []protocol.Node, latencies map[int64]int64, lang string, i18nMgr *i18n.Manager) []protocol.Node {
+	if len(latencies) == 0 {
+		return nodes
+	}
+	result := make([]protocol.Node, len(nodes))
+	for i, node := range nodes {
+		result[i] = node
+		if latency, ok := latencies[node.ID]; ok && node.ID > 0 {
+			result[i].Name = node.Name + " | " + formatI18n(i18nMgr, lang, "subscription.node.latency", latency)
+		}
+	}
+	return result
+}
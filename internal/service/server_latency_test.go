@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/cache"
+	"github.com/creamcroissant/xboard/internal/protocol"
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+func TestNodeLatencyStaleIsUnknown(t *testing.T) {
+	store := cache.NewStore(cache.Options{})
+	svc := &serverTelemetryService{cache: store}
+	server := &repository.Server{ID: 7, Type: "vless"}
+	ctx := context.Background()
+
+	if _, ok := svc.NodeLatency(ctx, server); ok {
+		t.Fatal("expected unknown latency before any report")
+	}
+	if err := svc.RecordLatency(ctx, server, 0); err == nil {
+		t.Fatal("expected zero latency to be rejected")
+	}
+	if err := svc.RecordLatency(ctx, server, 42); err != nil {
+		t.Fatalf("RecordLatency: %v", err)
+	}
+	if latency, ok := svc.NodeLatency(ctx, server); !ok || latency != 42 {
+		t.Fatalf("NodeLatency() = (%d, %v), want (42, true)", latency, ok)
+	}
+
+	stale := nodeLatencySample{LatencyMs: 42, MeasuredAt: time.Now().Add(-nodeLatencyStaleAfter - time.Minute).Unix()}
+	if _, ok := stale.fresh(time.Now()); ok {
+		t.Fatal("expected stale latency to be unknown")
+	}
+}
+
+func TestSortServersByLatencyAndHints(t *testing.T) {
+	servers := []*repository.Server{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}, {ID: 3, Name: "c"}, {ID: 4, Name: "d"}}
+	latencies := map[int64]int64{2: 80, 3: 20}
+	sortServersByLatency(servers, latencies)
+	var order []int64
+	for _, server := range servers {
+		order = append(order, server.ID)
+	}
+	if want := []int64{3, 2, 1, 4}; !slices.Equal(order, want) {
+		t.Fatalf("sort order = %v, want %v", order, want)
+	}
+
+	nodes := appendLatencyHints([]protocol.Node{{ID: 3, Name: "c"}, {ID: 1, Name: "a"}}, latencies, "en-US", nil)
+	if nodes[0].Name == "c" || nodes[1].Name != "a" {
+		t.Fatalf("unexpected hinted names: %q, %q", nodes[0].Name, nodes[1].Name)
+	}
+}
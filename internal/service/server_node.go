@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/creamcroissant/xboard/internal/repository"
+	"github.com/creamcroissant/xboard/internal/template"
 )
 
 // ServerNodeService exposes node configuration and user listings consumed by agents.
@@ -232,12 +233,10 @@ func buildServerConfigPayload(server *repository.Server, settings map[string]any
 }
 
 func serverNodeKey(cipher string, server *repository.Server) string {
-	normalized := strings.TrimSpace(strings.ToLower(cipher))
-	cfg, ok := shadowCipherConfig[normalized]
-	if !ok || server == nil {
+	if server == nil {
 		return ""
 	}
-	return buildServerKey(server.CreatedAt, cfg.serverKeySize)
+	return template.Shadowsocks2022ServerKey(server.CreatedAt, cipher)
 }
 
 func serializeServerRoutes(routes []*repository.ServerRoute) []map[string]any {
@@ -249,9 +248,11 @@ func serializeServerRoutes(routes []*repository.ServerRoute) []map[string]any {
 		result = append(result, map[string]any{
 			"id":           route.ID,
 			"remarks":      route.Remarks,
+			"match_type":   route.MatchType,
 			"match":        cloneRawMessage(route.Match),
 			"action":       route.Action,
 			"action_value": route.ActionValue,
+			"priority":     route.Priority,
 		})
 	}
 	return result
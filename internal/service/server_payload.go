@@ -4,9 +4,7 @@ package service
 
 import (
 	"context"
-	"crypto/md5"
 	crand "crypto/rand"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"log/slog"
@@ -17,6 +15,7 @@ import (
 	"github.com/creamcroissant/xboard/internal/plugin/hook"
 	"github.com/creamcroissant/xboard/internal/protocol"
 	"github.com/creamcroissant/xboard/internal/repository"
+	"github.com/creamcroissant/xboard/internal/template"
 )
 
 func buildProtocolNodes(servers []*repository.Server, user *repository.User) []protocol.Node {
@@ -24,6 +23,10 @@ func buildProtocolNodes(servers []*repository.Server, user *repository.User) []p
 		return []protocol.Node{}
 	}
 	uuid := ensureUserUUID(user)
+	username := ""
+	if user != nil {
+		username = user.Email
+	}
 	nodes := make([]protocol.Node, 0, len(servers))
 	for _, server := range servers {
 		if server == nil {
@@ -44,6 +47,9 @@ func buildProtocolNodes(servers []*repository.Server, user *repository.User) []p
 			Settings:    settings,
 			RawSettings: cloneRawMessage(server.Settings),
 			Password:    deriveServerPassword(server, uuid, settings),
+			Username:    username,
+			// shadow-tls v3 服务端以用户 UUID 作为握手密码
+			ShadowTLSPassword: uuid,
 		})
 	}
 	return nodes
@@ -127,12 +133,12 @@ func deriveServerPassword(server *repository.Server, userUUID string, settings m
 	if cipher == "" {
 		cipher = strings.TrimSpace(asString(settings["cipher"]))
 	}
-	config, ok := shadowCipherConfig[cipher]
-	if !ok {
+	if !template.IsShadowsocks2022(cipher) {
 		return userUUID
 	}
-	serverKey := buildServerKey(server.CreatedAt, config.serverKeySize)
-	userKey := truncateAndBase64(userUUID, config.userKeySize)
+	// 与 Agent 配置生成共用同一套派生，服务端 PSK 与用户密钥才能对上
+	serverKey := template.Shadowsocks2022ServerKey(server.CreatedAt, cipher)
+	userKey := template.Shadowsocks2022UserKey(userUUID, cipher)
 	if serverKey == "" || userKey == "" {
 		return userUUID
 	}
@@ -153,31 +159,6 @@ func ensureUserUUID(user *repository.User) string {
 	return uuid
 }
 
-func buildServerKey(timestamp int64, length int) string {
-	if timestamp <= 0 || length <= 0 {
-		return ""
-	}
-	hash := md5.Sum([]byte(strconv.FormatInt(timestamp, 10)))
-	hexStr := fmt.Sprintf("%x", hash[:])
-	if length > len(hexStr) {
-		length = len(hexStr)
-	}
-	segment := hexStr[:length]
-	return base64.StdEncoding.EncodeToString([]byte(segment))
-}
-
-func truncateAndBase64(src string, length int) string {
-	if length <= 0 || src == "" {
-		return ""
-	}
-	runes := []rune(src)
-	if length > len(runes) {
-		length = len(runes)
-	}
-	slice := runes[:length]
-	return base64.StdEncoding.EncodeToString([]byte(string(slice)))
-}
-
 func asString(value any) string {
 	switch v := value.(type) {
 	case string:
@@ -199,15 +180,6 @@ func asString(value any) string {
 	}
 }
 
-var shadowCipherConfig = map[string]struct {
-	serverKeySize int
-	userKeySize   int
-}{
-	"2022-blake3-aes-128-gcm":       {serverKeySize: 16, userKeySize: 16},
-	"2022-blake3-aes-256-gcm":       {serverKeySize: 32, userKeySize: 32},
-	"2022-blake3-chacha20-poly1305": {serverKeySize: 32, userKeySize: 32},
-}
-
 type protocolServerHookPayload struct {
 	User    *repository.User
 	Servers []*repository.Server
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+// serverTagMaxLength 限制单个节点标签的字符数。
+const serverTagMaxLength = 32
+
+// ServerTagCount 是一个节点标签及携带该标签的节点数。
+type ServerTagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// AdminServerTagsInput 描述批量给节点添加或移除标签的请求。
+type AdminServerTagsInput struct {
+	ServerIDs []int64  `json:"server_ids"`
+	Tags      []string `json:"tags"`
+}
+
+// NormalizeServerTag 校验并规范化节点标签：去除首尾空白并转为小写，与订阅 tags 过滤参数的处理保持一致。
+// 标签只允许字母（含中文等 Unicode 字母）、数字、'-'、'_' 与 '.'，长度 1-32 个字符。
+func NormalizeServerTag(tag string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(tag))
+	if normalized == "" {
+		return "", fmt.Errorf("%w: tag is empty / 标签不能为空", ErrBadRequest)
+	}
+	if utf8.RuneCountInString(normalized) > serverTagMaxLength {
+		return "", fmt.Errorf("%w: tag %q exceeds %d characters / 标签长度不能超过 %d 个字符", ErrBadRequest, tag, serverTagMaxLength, serverTagMaxLength)
+	}
+	for _, r := range normalized {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '.' {
+			continue
+		}
+		return "", fmt.Errorf("%w: tag %q contains invalid character %q / 标签包含非法字符", ErrBadRequest, tag, r)
+	}
+	return normalized, nil
+}
+
+// normalizeServerTags 校验并去重一组标签，保持首次出现的顺序。
+func normalizeServerTags(tags []string) ([]string, error) {
+	seen := make(map[string]struct{}, len(tags))
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		normalized, err := NormalizeServerTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := seen[normalized]; ok {
+			continue
+		}
+		seen[normalized] = struct{}{}
+		result = append(result, normalized)
+	}
+	return result, nil
+}
+
+// normalizeServerTagsJSON 校验保存节点时提交的 tags 数组并规范化；为空时原样返回。
+func normalizeServerTagsJSON(raw json.RawMessage) (json.RawMessage, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return raw, nil
+	}
+	var tags []string
+	if err := json.Unmarshal(raw, &tags); err != nil {
+		return nil, fmt.Errorf("%w: tags must be an array of strings / tags 必须为字符串数组", ErrBadRequest)
+	}
+	normalized, err := normalizeServerTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(normalized)
+}
+
+// countServerTags 统计每个标签出现在多少个节点上；标签按小写合并，结果按节点数降序、标签名升序排列。
+func countServerTags(tagLists [][]string) []ServerTagCount {
+	counts := make(map[string]int)
+	for _, tags := range tagLists {
+		seen := make(map[string]struct{}, len(tags))
+		for _, tag := range tags {
+			tag = strings.ToLower(strings.TrimSpace(tag))
+			if tag == "" {
+				continue
+			}
+			if _, ok := seen[tag]; ok {
+				continue
+			}
+			seen[tag] = struct{}{}
+			counts[tag]++
+		}
+	}
+	result := make([]ServerTagCount, 0, len(counts))
+	for tag, count := range counts {
+		result = append(result, ServerTagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Tag < result[j].Tag
+	})
+	return result
+}
+
+// Tags 返回全部节点（含隐藏节点）使用的标签及节点数。
+func (s *adminServerService) Tags(ctx context.Context) ([]ServerTagCount, error) {
+	if s == nil || s.servers == nil {
+		return nil, fmt.Errorf("admin server service not configured / 管理节点服务未配置")
+	}
+	servers, err := s.servers.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tagLists := make([][]string, 0, len(servers))
+	for _, server := range servers {
+		if server != nil {
+			tagLists = append(tagLists, decodeStringArray(server.Tags))
+		}
+	}
+	return countServerTags(tagLists), nil
+}
+
+// AssignTags 给一批节点追加标签，返回实际发生变化的节点数。
+func (s *adminServerService) AssignTags(ctx context.Context, input AdminServerTagsInput) (int, error) {
+	return s.updateServerTags(ctx, input, func(current []string, tags []string) []string {
+		return append(current, tags...)
+	})
+}
+
+// RemoveTags 从一批节点上移除标签，返回实际发生变化的节点数。
+func (s *adminServerService) RemoveTags(ctx context.Context, input AdminServerTagsInput) (int, error) {
+	return s.updateServerTags(ctx, input, func(current []string, tags []string) []string {
+		remove := make(map[string]struct{}, len(tags))
+		for _, tag := range tags {
+			remove[tag] = struct{}{}
+		}
+		kept := current[:0]
+		for _, tag := range current {
+			if _, ok := remove[tag]; !ok {
+				kept = append(kept, tag)
+			}
+		}
+		return kept
+	})
+}
+
+// updateServerTags 校验输入后逐个节点改写标签；已有标签统一转为小写并去重，未变化的节点不写库。
+func (s *adminServerService) updateServerTags(ctx context.Context, input AdminServerTagsInput, apply func(current []string, tags []string) []string) (int, error) {
+	if s == nil || s.servers == nil {
+		return 0, fmt.Errorf("admin server service not configured / 管理节点服务未配置")
+	}
+	ids := uniquePositive(input.ServerIDs)
+	if len(ids) == 0 {
+		return 0, fmt.Errorf("%w: server_ids cannot be empty / server_ids 不能为空", ErrBadRequest)
+	}
+	tags, err := normalizeServerTags(input.Tags)
+	if err != nil {
+		return 0, err
+	}
+	if len(tags) == 0 {
+		return 0, fmt.Errorf("%w: tags cannot be empty / tags 不能为空", ErrBadRequest)
+	}
+
+	changed := 0
+	for _, id := range ids {
+		server, err := s.servers.FindByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return changed, fmt.Errorf("%w: server %d not found / 节点 %d 不存在", ErrNotFound, id, id)
+			}
+			return changed, fmt.Errorf("find server %d: %v / 获取节点失败: %w", id, err, err)
+		}
+		before := auditServerNode(server)
+		existing := decodeStringArray(server.Tags)
+		next := dedupeLowerTags(apply(dedupeLowerTags(existing), tags))
+		if slices.Equal(next, existing) {
+			continue
+		}
+		raw, err := json.Marshal(next)
+		if err != nil {
+			return changed, fmt.Errorf("encode server tags: %w", err)
+		}
+		server.Tags = raw
+		if err := s.servers.Update(ctx, server); err != nil {
+			return changed, err
+		}
+		changed++
+		recordAudit(ctx, s.audit, AuditEntry{Action: AuditActionUpdate, TargetType: AuditTargetServer, TargetID: server.ID, Before: before, After: auditServerNode(server)})
+	}
+	return changed, nil
+}
+
+// dedupeLowerTags 把标签转为小写并去重，保持原有顺序。
+func dedupeLowerTags(tags []string) []string {
+	seen := make(map[string]struct{}, len(tags))
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		result = append(result, tag)
+	}
+	return result
+}
+
+// ListTagsForUser 返回用户可见节点上的标签及节点数，供客户端提供标签选择器；结果可直接用于订阅的 tags 参数。
+func (s *serverService) ListTagsForUser(ctx context.Context, userID string) ([]ServerTagCount, error) {
+	result, err := s.ListForUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	tagLists := make([][]string, 0, len(result.Nodes))
+	for _, node := range result.Nodes {
+		tagLists = append(tagLists, node.Tags)
+	}
+	return countServerTags(tagLists), nil
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeServerTag(t *testing.T) {
+	for raw, want := range map[string]string{" HK ": "hk", "Netflix.解锁": "netflix.解锁", "low_ratio-1": "low_ratio-1"} {
+		got, err := NormalizeServerTag(raw)
+		if err != nil || got != want {
+			t.Fatalf("NormalizeServerTag(%q) = %q, %v; want %q", raw, got, err, want)
+		}
+	}
+	for _, raw := range []string{"", "hk,us", "a b", strings.Repeat("x", serverTagMaxLength+1)} {
+		if _, err := NormalizeServerTag(raw); !errors.Is(err, ErrBadRequest) {
+			t.Fatalf("NormalizeServerTag(%q) error = %v, want ErrBadRequest", raw, err)
+		}
+	}
+}
+
+func TestCountServerTagsMergesCase(t *testing.T) {
+	got := countServerTags([][]string{{"HK", "netflix"}, {"hk", "HK"}, {"us"}, nil})
+	want := []ServerTagCount{{Tag: "hk", Count: 2}, {Tag: "netflix", Count: 1}, {Tag: "us", Count: 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("countServerTags() = %+v, want %+v", got, want)
+	}
+}
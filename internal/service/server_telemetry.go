@@ -26,6 +26,9 @@ type ServerTelemetryService interface {
 	RecordStatus(ctx context.Context, server *repository.Server, status ServerStatusReport) error
 	IsNodeOnline(ctx context.Context, server *repository.Server) bool
 	RecordHeartbeat(ctx context.Context, server *repository.Server) error
+	RecordLatency(ctx context.Context, server *repository.Server, latencyMs int64) error
+	RecordHostLatency(ctx context.Context, agentHostID int64, latencyMs int64) error
+	NodeLatency(ctx context.Context, server *repository.Server) (int64, bool)
 }
 
 // UniProxyPushSample is an alias to async.UniProxyPushSample for backward compatibility.
@@ -40,6 +43,7 @@ type ServerStatusReport struct {
 	Disk            StatusCapacity
 	TrafficUpload   int64 // Node-level traffic delta since last report (bytes)
 	TrafficDownload int64 // Node-level traffic delta since last report (bytes)
+	LatencyMs       int64 // Node-measured probe latency (ms), 0 means not measured
 	Taken           time.Time
 }
 
@@ -212,6 +216,11 @@ func (s *serverTelemetryService) RecordStatus(ctx context.Context, server *repos
 	if err := s.cache.Set(ctx, lastKey, now, cacheTTL); err != nil {
 		return err
 	}
+	if status.LatencyMs > 0 {
+		if err := s.RecordLatency(ctx, server, status.LatencyMs); err != nil {
+			s.logger.Warn("failed to record server latency", "error", err, "server_id", server.ID)
+		}
+	}
 
 	// Persist traffic and resource stats to stat_servers (hourly + daily)
 	if s.statServers != nil && (status.TrafficUpload > 0 || status.TrafficDownload > 0) {
@@ -6,8 +6,6 @@ import (
 	"context"
 	"errors"
 	"math"
-	"strconv"
-	"strings"
 
 	"github.com/creamcroissant/xboard/internal/repository"
 )
@@ -33,7 +31,7 @@ func NewServerTrafficService(userRepo repository.UserRepository, collector Traff
 	return &serverTrafficService{users: userRepo, collector: collector}
 }
 
-// Apply 处理节点上报样本，并按倍率累加到用户流量。
+// Apply 处理节点上报样本，并按节点计费倍率累加到用户流量。
 func (s *serverTrafficService) Apply(ctx context.Context, server *repository.Server, samples []UniProxyPushSample) error {
 	if err := ensureServer(server); err != nil {
 		return err
@@ -44,8 +42,7 @@ func (s *serverTrafficService) Apply(ctx context.Context, server *repository.Ser
 	if s.users == nil {
 		return errors.New("server traffic: user repository unavailable / 节点流量用户仓储不可用")
 	}
-	rate := parseServerRate(server)
-	deltas := aggregateTraffic(samples, rate)
+	deltas := aggregateTraffic(samples, serverTrafficRatio(server))
 	for userID, delta := range deltas {
 		if delta.Upload == 0 && delta.Download == 0 {
 			continue
@@ -97,28 +94,28 @@ func aggregateTraffic(samples []UniProxyPushSample, rate float64) map[int64]traf
 	return totals
 }
 
-// scaleTraffic 根据倍率对流量进行四舍五入。
+// scaleTraffic 根据倍率对流量进行四舍五入；非零流量至少计 1 字节，避免小倍率下的小增量被整体丢弃。
 func scaleTraffic(value int64, rate float64) int64 {
 	if value <= 0 {
 		return 0
 	}
-	scaled := float64(value) * rate
-	return int64(math.Round(scaled))
-}
-
-// parseServerRate 读取并解析节点倍率，非法/空值则回退为 1。
-func parseServerRate(server *repository.Server) float64 {
-	if server == nil {
+	scaled := int64(math.Round(float64(value) * rate))
+	if scaled < 1 {
 		return 1
 	}
-	rate := strings.TrimSpace(server.Rate)
-	if rate == "" {
+	return scaled
+}
+
+// serverTrafficRatio 返回节点计费倍率（servers.traffic_ratio），未设置或非法时回退为 1。
+// Agent 与 UniProxy 两条上报路径都只使用这一个倍率，rate 文本列仅用于展示并与之同步。
+func serverTrafficRatio(server *repository.Server) float64 {
+	if server == nil || !validTrafficRatio(server.TrafficRatio) {
 		return 1
 	}
-	if parsed, err := strconv.ParseFloat(rate, 64); err == nil {
-		if parsed > 0 {
-			return parsed
-		}
-	}
-	return 1
+	return server.TrafficRatio
+}
+
+// validTrafficRatio 判断倍率是否为有限正数。
+func validTrafficRatio(ratio float64) bool {
+	return ratio > 0 && !math.IsInf(ratio, 0) && !math.IsNaN(ratio)
 }
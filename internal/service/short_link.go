@@ -4,21 +4,28 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/creamcroissant/xboard/internal/async"
+	"github.com/creamcroissant/xboard/internal/protocol"
 	"github.com/creamcroissant/xboard/internal/repository"
 )
 
 // ShortLinkService manages short URL generation for subscription links.
 type ShortLinkService interface {
-	// Create generates a new short link for a user
-	Create(ctx context.Context, userID int64, customCode string, expiresAt int64) (*repository.ShortLink, error)
+	// Create generates a new short link for a user.
+	// params are default subscription parameters (flag/types/...) encoded into the link.
+	Create(ctx context.Context, userID int64, customCode string, expiresAt int64, params map[string]string) (*repository.ShortLink, error)
 
-	// Resolve finds a short link by code and returns the full redirect URL
-	Resolve(ctx context.Context, code string) (*ShortLinkResolveResult, error)
+	// Resolve finds a short link by code and returns the full redirect URL.
+	// overrides (usually the incoming request query) take precedence over the link's default params.
+	Resolve(ctx context.Context, code string, overrides url.Values) (*ShortLinkResolveResult, error)
 
 	// List returns all short links for a user
 	List(ctx context.Context, userID int64) ([]*repository.ShortLink, error)
@@ -28,6 +35,9 @@ type ShortLinkService interface {
 
 	// GetByID returns a short link by ID
 	GetByID(ctx context.Context, id int64) (*repository.ShortLink, error)
+
+	// ListAll returns short links across all users for the admin view
+	ListAll(ctx context.Context, filter repository.ShortLinkFilter) ([]*repository.ShortLink, int64, error)
 }
 
 // ShortLinkResolveResult contains the resolution result for a short link.
@@ -36,28 +46,48 @@ type ShortLinkResolveResult struct {
 	RedirectTo string
 	UserToken  string
 	Expired    bool
+	// Params 为链接默认参数与请求参数合并后的订阅参数
+	Params map[string]string
 }
 
+// ShortLinkParamKeys 短链接允许携带的订阅参数白名单。
+var ShortLinkParamKeys = []string{"flag", "types", "filter", "tags", "show_info", "template_id", "stack", "sort"}
+
 type shortLinkService struct {
-	links    repository.ShortLinkRepository
-	users    repository.UserRepository
-	settings repository.SettingRepository
+	links     repository.ShortLinkRepository
+	users     repository.UserRepository
+	settings  repository.SettingRepository
+	hits      *async.ShortLinkHitQueue
+	protocols *protocol.Manager
 }
 
 // NewShortLinkService creates a new short link service.
-func NewShortLinkService(links repository.ShortLinkRepository, users repository.UserRepository, settings repository.SettingRepository) ShortLinkService {
+// hits may be nil, in which case access counts are written synchronously;
+// protocols supplies the known client flags used to validate the flag param.
+func NewShortLinkService(links repository.ShortLinkRepository, users repository.UserRepository, settings repository.SettingRepository, hits *async.ShortLinkHitQueue, protocols *protocol.Manager) ShortLinkService {
 	return &shortLinkService{
-		links:    links,
-		users:    users,
-		settings: settings,
+		links:     links,
+		users:     users,
+		settings:  settings,
+		hits:      hits,
+		protocols: protocols,
 	}
 }
 
-func (s *shortLinkService) Create(ctx context.Context, userID int64, customCode string, expiresAt int64) (*repository.ShortLink, error) {
+func (s *shortLinkService) Create(ctx context.Context, userID int64, customCode string, expiresAt int64, params map[string]string) (*repository.ShortLink, error) {
 	if s.links == nil {
 		return nil, errors.New("short link repository unavailable / 短链接仓库不可用")
 	}
 
+	normalized, err := s.normalizeParams(params)
+	if err != nil {
+		return nil, err
+	}
+	customParams, err := encodeShortLinkParams(normalized)
+	if err != nil {
+		return nil, err
+	}
+
 	// Verify user exists
 	user, err := s.users.FindByID(ctx, userID)
 	if err != nil {
@@ -91,12 +121,13 @@ func (s *shortLinkService) Create(ctx context.Context, userID int64, customCode
 
 	now := time.Now().Unix()
 	link := &repository.ShortLink{
-		Code:       code,
-		UserID:     userID,
-		TargetPath: "/api/v1/client/subscribe",
-		ExpiresAt:  expiresAt,
-		CreatedAt:  now,
-		UpdatedAt:  now,
+		Code:         code,
+		UserID:       userID,
+		TargetPath:   "/api/v1/client/subscribe",
+		CustomParams: customParams,
+		ExpiresAt:    expiresAt,
+		CreatedAt:    now,
+		UpdatedAt:    now,
 	}
 
 	if err := s.links.Create(ctx, link); err != nil {
@@ -106,7 +137,7 @@ func (s *shortLinkService) Create(ctx context.Context, userID int64, customCode
 	return link, nil
 }
 
-func (s *shortLinkService) Resolve(ctx context.Context, code string) (*ShortLinkResolveResult, error) {
+func (s *shortLinkService) Resolve(ctx context.Context, code string, overrides url.Values) (*ShortLinkResolveResult, error) {
 	if s.links == nil {
 		return nil, errors.New("short link repository unavailable / 短链接仓库不可用")
 	}
@@ -138,20 +169,31 @@ func (s *shortLinkService) Resolve(ctx context.Context, code string) (*ShortLink
 
 	result.UserToken = user.Token
 
+	// Merge default params with request overrides
+	params, err := s.mergeParams(link.CustomParams, overrides)
+	if err != nil {
+		return nil, err
+	}
+	result.Params = params
+
 	// Build redirect URL
 	baseURL := s.getBaseURL(ctx)
 	redirectPath := sanitizeRedirectPath(link.TargetPath)
 	if redirectPath == "" {
 		redirectPath = "/api/v1/client/subscribe"
 	}
-	redirectTo, err := buildRedirectURL(baseURL, redirectPath, user.Token)
+	redirectTo, err := buildRedirectURL(baseURL, redirectPath, user.Token, params)
 	if err != nil {
 		return nil, err
 	}
 	result.RedirectTo = redirectTo
 
-	// Increment access count
-	_ = s.links.IncrementAccessCount(ctx, link.ID, now)
+	// Increment access count; batched through the hit queue to keep redirects fast
+	if s.hits != nil {
+		s.hits.Record(link.ID, now)
+	} else {
+		_ = s.links.IncrementAccessCount(ctx, link.ID, now)
+	}
 
 	return result, nil
 }
@@ -187,6 +229,115 @@ func (s *shortLinkService) GetByID(ctx context.Context, id int64) (*repository.S
 	return s.links.FindByID(ctx, id)
 }
 
+func (s *shortLinkService) ListAll(ctx context.Context, filter repository.ShortLinkFilter) ([]*repository.ShortLink, int64, error) {
+	if s.links == nil {
+		return nil, 0, errors.New("short link repository unavailable / 短链接仓库不可用")
+	}
+	links, err := s.links.List(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.links.Count(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return links, total, nil
+}
+
+// mergeParams 解码链接默认参数，再用请求中的白名单参数覆盖；未知参数被忽略，非法取值返回 ErrBadRequest。
+func (s *shortLinkService) mergeParams(customParams string, overrides url.Values) (map[string]string, error) {
+	merged := make(map[string]string)
+	if strings.TrimSpace(customParams) != "" {
+		if err := json.Unmarshal([]byte(customParams), &merged); err != nil {
+			return nil, fmt.Errorf("decode short link params: %w", err)
+		}
+	}
+	requested := make(map[string]string)
+	for _, key := range ShortLinkParamKeys {
+		if value := strings.TrimSpace(overrides.Get(key)); value != "" {
+			requested[key] = value
+		}
+	}
+	normalized, err := s.normalizeParams(requested)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range normalized {
+		merged[key] = value
+	}
+	return merged, nil
+}
+
+// normalizeParams 校验参数是否在白名单内：types 必须是已知协议类型，flag 必须命中已知客户端标识。
+func (s *shortLinkService) normalizeParams(params map[string]string) (map[string]string, error) {
+	normalized := make(map[string]string, len(params))
+	for key, value := range params {
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		switch key {
+		case "flag":
+			var known []string
+			if s.protocols != nil {
+				known = s.protocols.Flags()
+			}
+			if detectClientInfo(value, "", known).Name == "" {
+				return nil, fmt.Errorf("%w: unknown client flag %q / 未知的客户端标识", ErrBadRequest, value)
+			}
+		case "types":
+			if !strings.EqualFold(value, "all") {
+				for _, token := range splitTokens(value) {
+					normalizedType := strings.ToLower(token)
+					if _, ok := validServerTypes[normalizedType]; ok {
+						continue
+					}
+					if _, ok := requestedTypeAliases[normalizedType]; ok {
+						continue
+					}
+					return nil, fmt.Errorf("%w: unknown server type %q / 未知的协议类型", ErrBadRequest, token)
+				}
+			}
+		case "show_info":
+			if value != "1" && value != "true" && value != "0" && value != "false" {
+				return nil, fmt.Errorf("%w: show_info must be a boolean / show_info 必须为布尔值", ErrBadRequest)
+			}
+		case "template_id":
+			if id, err := strconv.ParseInt(value, 10, 64); err != nil || id <= 0 {
+				return nil, fmt.Errorf("%w: invalid template_id / template_id 无效", ErrBadRequest)
+			}
+		case "stack":
+			stack, err := ParseSubscriptionStack(value)
+			if err != nil {
+				return nil, err
+			}
+			value = stack
+		case "sort":
+			if !strings.EqualFold(value, SubscriptionSortLatency) {
+				return nil, fmt.Errorf("%w: unsupported sort %q / 不支持的排序方式", ErrBadRequest, value)
+			}
+			value = SubscriptionSortLatency
+		case "filter", "tags":
+		default:
+			return nil, fmt.Errorf("%w: unsupported short link param %q / 不支持的短链接参数", ErrBadRequest, key)
+		}
+		normalized[key] = value
+	}
+	return normalized, nil
+}
+
+func encodeShortLinkParams(params map[string]string) (string, error) {
+	if len(params) == 0 {
+		return "", nil
+	}
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return "", err
+	}
+	return string(raw), nil
+}
+
 func (s *shortLinkService) generateUniqueCode(ctx context.Context) (string, error) {
 	const maxAttempts = 10
 	for i := 0; i < maxAttempts; i++ {
@@ -237,7 +388,7 @@ func generateRandomCode(length int) (string, error) {
 	return code, nil
 }
 
-func buildRedirectURL(baseURL, path, token string) (string, error) {
+func buildRedirectURL(baseURL, path, token string, params map[string]string) (string, error) {
 	sanitized := sanitizeRedirectPath(path)
 	if sanitized == "" {
 		return "", errors.New("invalid redirect path / 无效的跳转路径")
@@ -252,9 +403,14 @@ func buildRedirectURL(baseURL, path, token string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	if token != "" {
+	if token != "" || len(params) > 0 {
 		query := target.Query()
-		query.Set("token", token)
+		for key, value := range params {
+			query.Set(key, value)
+		}
+		if token != "" {
+			query.Set("token", token)
+		}
 		target.RawQuery = query.Encode()
 	}
 	if baseURL == "" {
@@ -10,6 +10,7 @@ import (
 	"net/url"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -23,6 +24,10 @@ import (
 // SubscriptionService 负责生成客户端订阅响应。
 type SubscriptionService interface {
 	Subscribe(ctx context.Context, userID string, params SubscriptionParams) (*SubscriptionResult, error)
+	// Preview 以管理员身份渲染指定用户的订阅用于排障，不记录订阅访问日志，并写入审计日志。
+	Preview(ctx context.Context, userID int64, params SubscriptionParams, reveal bool) (*SubscriptionPreview, error)
+	// SetAuditLog 注入审计日志，记录管理员的订阅预览。
+	SetAuditLog(audit AuditLogService)
 }
 
 // SubscriptionParams 用于承接客户端传入的过滤参数。
@@ -38,6 +43,10 @@ type SubscriptionParams struct {
 	Tags         string // 按标签过滤节点，逗号分隔
 	ShowUserInfo bool   // 是否在节点名称中显示用户信息
 	TemplateID   int64  // 用户指定的订阅模板ID
+	IP           string // 按可信代理规则解析出的客户端 IP，未知时为空
+	Preview      bool   // 管理端预览，不记录订阅访问日志
+	Stack        string // 按 IP 协议栈过滤节点：v4、v6 或 both（默认）
+	Sort         string // 节点排序方式：latency 按最近延迟升序，默认按权重
 }
 
 // SubscriptionResult 包含订阅内容与元数据。
@@ -46,6 +55,9 @@ type SubscriptionResult struct {
 	ContentType string
 	ETag        string
 	Headers     map[string]string
+	// ClientName/ClientVersion 为根据 Flag/UserAgent 识别出的客户端，未识别时为空
+	ClientName    string
+	ClientVersion string
 }
 
 // subscriptionService 负责订阅生成所需的仓储与依赖。
@@ -63,15 +75,18 @@ type subscriptionService struct {
 	obfuscate bool
 	selection UserServerSelectionService
 	i18n      *i18n.Manager
+	audit     AuditLogService
+	stacks    *nodeStackResolver
 }
 
 // protocolSettings 保存订阅模板与前端展示配置。
 type protocolSettings struct {
-	AppName         string
-	AppURL          string
-	ClashTemplate   string
-	SurgeTemplate   string
-	SingboxTemplate string
+	AppName           string
+	AppURL            string
+	ClashTemplate     string
+	ClashMetaTemplate string
+	SurgeTemplate     string
+	SingboxTemplate   string
 }
 
 // NewSubscriptionService 组装订阅服务依赖。
@@ -80,7 +95,7 @@ func NewSubscriptionService(users repository.UserRepository, servers repository.
 	if len(filters) > 0 {
 		filter = filters[0]
 	}
-	return &subscriptionService{users: users, servers: servers, settings: settings, plans: plans, templates: templates, sources: sources, filter: filter, protocols: manager, telemetry: telemetry, subLogs: subLogs, obfuscate: obfuscate, selection: selection, i18n: i18nMgr}
+	return &subscriptionService{users: users, servers: servers, settings: settings, plans: plans, templates: templates, sources: sources, filter: filter, protocols: manager, telemetry: telemetry, subLogs: subLogs, obfuscate: obfuscate, selection: selection, i18n: i18nMgr, stacks: newNodeStackResolver()}
 }
 
 // queryServers 根据用户显式选择、用户分组与套餐分组决定可用节点。
@@ -93,19 +108,9 @@ func (s *subscriptionService) queryServers(ctx context.Context, user *repository
 	}
 
 	// 0. 先收集用户与套餐关联分组（用于校验显式选择节点权限）
-	groupIDs := make([]int64, 0, 4)
-	if user.GroupID > 0 {
-		groupIDs = append(groupIDs, user.GroupID)
-	}
-	if user.PlanID > 0 && s.plans != nil {
-		planGroups, err := s.plans.GetGroups(ctx, user.PlanID)
-		if err != nil {
-			// 分组信息影响访问控制，查询失败时直接返回错误
-			return nil, err
-		}
-		if len(planGroups) > 0 {
-			groupIDs = append(groupIDs, planGroups...)
-		}
+	groupIDs, err := effectiveGroupIDs(ctx, s.plans, user)
+	if err != nil {
+		return nil, err
 	}
 
 	// 1. 优先处理用户显式选中的节点
@@ -198,34 +203,53 @@ func (s *subscriptionService) Subscribe(ctx context.Context, userID string, para
 	allowedTypes := parseRequestedTypes(params.Types)
 	keywords := parseFilterKeywords(params.Filter)
 	tagsFilter := parseTagsFilter(params.Tags)
+	stack, err := ParseSubscriptionStack(params.Stack)
+	if err != nil {
+		return nil, err
+	}
 	servers, sourceNodes, err := s.filterForSubscription(ctx, user, allowedTypes, keywords, tagsFilter, lang)
 	if err != nil {
 		return nil, err
 	}
+	if stack != SubscriptionStackBoth {
+		servers, sourceNodes = s.filterByStack(ctx, servers, sourceNodes, stack)
+		if len(servers) == 0 && len(sourceNodes) == 0 {
+			return nil, ErrNoStackNodes
+		}
+	}
+	sortServersByWeight(servers)
+	var latencies map[int64]int64
+	if params.ShowUserInfo || strings.EqualFold(strings.TrimSpace(params.Sort), SubscriptionSortLatency) {
+		latencies = nodeLatencies(ctx, s.telemetry, servers)
+	}
+	if strings.EqualFold(strings.TrimSpace(params.Sort), SubscriptionSortLatency) {
+		sortServersByLatency(servers, latencies)
+	}
 
 	hooked := applyProtocolServerHooks(ctx, servers, user)
 	clientInfo := detectClientInfo(params.Flag, params.UserAgent, s.protocols.Flags())
 	if s.obfuscate && clientInfo.Name == "" {
 		return nil, ErrNotFound
 	}
-	pl := s.loadProtocolSettings(ctx)
 
-	// 若用户指定模板，则覆盖默认模板
-	if params.TemplateID > 0 && s.templates != nil {
-		if tpl, err := s.templates.FindByID(ctx, params.TemplateID); err == nil && tpl != nil {
-			switch strings.ToLower(tpl.Type) {
-			case "clash":
-				pl.ClashTemplate = tpl.Content
-			case "singbox", "sing-box":
-				pl.SingboxTemplate = tpl.Content
-			case "surge":
-				pl.SurgeTemplate = tpl.Content
-			}
-		}
+	// 异步记录订阅访问日志；在构建与 ETag 比对之前记录，304 命中的拉取同样计入
+	if s.subLogs != nil && !params.Preview {
+		s.subLogs.Enqueue(&repository.SubscriptionLog{
+			UserID:    user.ID,
+			IP:        strings.TrimSpace(params.IP),
+			UserAgent: params.UserAgent,
+			Type:      clientInfo.Name,
+			URL:       params.URL,
+		})
 	}
 
+	pl := s.loadProtocolSettings(ctx)
+
 	// 构建节点列表并应用个性化显示
 	nodes := buildProtocolNodes(hooked, user)
+	if params.ShowUserInfo {
+		nodes = appendLatencyHints(nodes, latencies, lang, s.i18n)
+	}
 	nodes = append(nodes, sourceNodes...)
 	nodes = personalizeNodeNames(nodes, user, params.ShowUserInfo, lang, s.i18n)
 
@@ -242,13 +266,18 @@ func (s *subscriptionService) Subscribe(ctx context.Context, userID string, para
 		AppURL:        pl.AppURL,
 		SubscribeURL:  s.resolveSubscribeURL(params, user),
 		Templates: map[string]string{
-			"clash":    pl.ClashTemplate,
-			"surge":    pl.SurgeTemplate,
-			"sing-box": pl.SingboxTemplate,
+			"clash":      pl.ClashTemplate,
+			"clash-meta": pl.ClashMetaTemplate,
+			"surge":      pl.SurgeTemplate,
+			"sing-box":   pl.SingboxTemplate,
 		},
 		Lang: lang,
 		I18n: s.i18n,
 	}
+	// 按 请求参数 > 用户 > 套餐 的优先级覆盖系统默认模板
+	if key, content := s.resolveSubscriptionTemplate(ctx, user, params); key != "" {
+		request.Templates[key] = content
+	}
 	protoResult, err := s.protocols.Build(request)
 	if err != nil {
 		return nil, err
@@ -257,33 +286,56 @@ func (s *subscriptionService) Subscribe(ctx context.Context, userID string, para
 		return nil, s.translateError(lang, "subscription.error.build_empty", "protocol build result is empty / 协议构建结果为空")
 	}
 
-	// 异步记录订阅访问日志
-	if s.subLogs != nil {
-		s.subLogs.Enqueue(&repository.SubscriptionLog{
-			UserID:    user.ID,
-			IP:        "127.0.0.1", // TODO: Get real IP from context or params if available
-			UserAgent: params.UserAgent,
-			Type:      clientInfo.Name,
-			URL:       params.URL,
-		})
+	// ShowUserInfo 时节点名称随剩余流量/到期时间变化，ETag 需同时纳入用户状态
+	var etagExtras []string
+	if params.ShowUserInfo {
+		etagExtras = append(etagExtras, subscriptionUserStateKey(user))
 	}
+	etag := computeSubscriptionETag(protoResult.Payload, etagExtras...)
+	headers := make(map[string]string, len(protoResult.Headers)+2)
+	for key, value := range protoResult.Headers {
+		headers[key] = value
+	}
+	headers["etag"] = "\"" + etag + "\""
+	headers["cache-control"] = fmt.Sprintf("private, max-age=%d", s.subscribeCacheSeconds(ctx))
 
 	return &SubscriptionResult{
-		Payload:     protoResult.Payload,
-		ContentType: protoResult.ContentType,
-		ETag:        computeSubscriptionETag(protoResult.Payload),
-		Headers:     protoResult.Headers,
+		Payload:       protoResult.Payload,
+		ContentType:   protoResult.ContentType,
+		ETag:          etag,
+		Headers:       headers,
+		ClientName:    clientInfo.Name,
+		ClientVersion: clientInfo.Version,
 	}, nil
 }
 
+// subscribeCacheSeconds 读取订阅缓存时长（subscribe_cache_seconds），非法或负数时回退为 0。
+func (s *subscriptionService) subscribeCacheSeconds(ctx context.Context) int64 {
+	raw := strings.TrimSpace(s.settingString(ctx, "subscribe_cache_seconds", "0"))
+	seconds, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return seconds
+}
+
+// subscriptionUserStateKey 汇总影响节点名称后缀的用户状态（已用流量/总流量/到期时间）。
+func subscriptionUserStateKey(user *repository.User) string {
+	if user == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d:%d:%d", user.U+user.D, user.TransferEnable, user.ExpiredAt)
+}
+
 // loadProtocolSettings 读取订阅相关的系统配置。
 func (s *subscriptionService) loadProtocolSettings(ctx context.Context) protocolSettings {
 	return protocolSettings{
-		AppName:         s.settingString(ctx, "app_name", "XBoard"),
-		AppURL:          s.settingString(ctx, "app_url", ""),
-		ClashTemplate:   s.settingString(ctx, "subscribe_template_clash", ""),
-		SurgeTemplate:   s.settingString(ctx, "subscribe_template_surge", ""),
-		SingboxTemplate: s.settingString(ctx, "subscribe_template_singbox", ""),
+		AppName:           s.settingString(ctx, "app_name", "XBoard"),
+		AppURL:            s.settingString(ctx, "app_url", ""),
+		ClashTemplate:     s.settingString(ctx, "subscribe_template_clash", ""),
+		ClashMetaTemplate: s.settingString(ctx, "subscribe_template_clash_meta", ""),
+		SurgeTemplate:     s.settingString(ctx, "subscribe_template_surge", ""),
+		SingboxTemplate:   s.settingString(ctx, "subscribe_template_singbox", ""),
 	}
 }
 
@@ -353,6 +405,31 @@ func filterSubscriptionServers(servers []*repository.Server, allowedTypes map[st
 	return filtered, rejected
 }
 
+// defaultServerWeight 未设置权重（0）的节点按 100 参与排序，保持旧节点顺序不变。
+const defaultServerWeight = 100
+
+// effectiveServerWeight 返回节点参与排序的权重。
+func effectiveServerWeight(server *repository.Server) int {
+	if server == nil || server.Weight <= 0 {
+		return defaultServerWeight
+	}
+	return server.Weight
+}
+
+// sortServersByWeight 按权重降序、名称升序稳定排序，便于客户端“自动”选择时优先高权重节点。
+func sortServersByWeight(servers []*repository.Server) {
+	sort.SliceStable(servers, func(i, j int) bool {
+		wi, wj := effectiveServerWeight(servers[i]), effectiveServerWeight(servers[j])
+		if wi != wj {
+			return wi > wj
+		}
+		if servers[i] == nil || servers[j] == nil {
+			return servers[j] == nil && servers[i] != nil
+		}
+		return servers[i].Name < servers[j].Name
+	})
+}
+
 // typeAllowed 判断节点类型是否在允许列表中。
 func typeAllowed(serverType string, allowed map[string]struct{}) bool {
 	if len(allowed) == 0 {
@@ -393,6 +470,12 @@ func parseRequestedTypes(raw string) map[string]struct{} {
 	allowed := make(map[string]struct{})
 	for _, token := range tokens {
 		normalized := strings.ToLower(token)
+		if aliases, ok := requestedTypeAliases[normalized]; ok {
+			for _, alias := range aliases {
+				allowed[alias] = struct{}{}
+			}
+			continue
+		}
 		if _, ok := validServerTypes[normalized]; ok {
 			allowed[normalized] = struct{}{}
 		}
@@ -575,10 +658,15 @@ func containsGroupID(groupIDs []int64, target int64) bool {
 	return false
 }
 
-// computeSubscriptionETag 用于生成订阅内容的 ETag。
-func computeSubscriptionETag(payload []byte) string {
-	sum := sha1.Sum(payload)
-	return hex.EncodeToString(sum[:])
+// computeSubscriptionETag 用于生成订阅内容的 ETag，extras 用于混入额外的状态因子。
+func computeSubscriptionETag(payload []byte, extras ...string) string {
+	hasher := sha1.New()
+	hasher.Write(payload)
+	for _, extra := range extras {
+		hasher.Write([]byte{0})
+		hasher.Write([]byte(extra))
+	}
+	return hex.EncodeToString(hasher.Sum(nil))
 }
 
 // validServerTypes 允许的协议类型白名单。
@@ -596,12 +684,21 @@ var validServerTypes = map[string]struct{}{
 	"mieru":       {},
 }
 
+// requestedTypeAliases 将客户端（如 mihomo）常用的类型名映射为节点类型；
+// hysteria2 同时覆盖面板节点（hysteria）与导入节点（hysteria2）。
+var requestedTypeAliases = map[string][]string{
+	"hysteria2": {"hysteria", "hysteria2"},
+	"hy2":       {"hysteria", "hysteria2"},
+	"ss":        {"shadowsocks"},
+	"socks5":    {"socks"},
+}
+
 func (s *subscriptionService) translateError(lang, key, fallback string) error {
 	if s == nil || s.i18n == nil {
 		return fmt.Errorf("%s", fallback)
 	}
-	msg := s.i18n.Translate(lang, key)
-	if strings.TrimSpace(msg) == "" || msg == key {
+	msg, ok := s.i18n.Lookup(lang, key)
+	if !ok || strings.TrimSpace(msg) == "" {
 		return fmt.Errorf("%s", fallback)
 	}
 	return fmt.Errorf("%s", msg)
@@ -614,6 +711,13 @@ func formatI18n(i18nMgr *i18n.Manager, lang, key string, args ...any) string {
 	return i18nMgr.Translate(lang, key, args...)
 }
 
+func formatI18nPlural(i18nMgr *i18n.Manager, lang, key string, count int64) string {
+	if i18nMgr == nil {
+		return key
+	}
+	return i18nMgr.TranslatePlural(lang, key, count)
+}
+
 // personalizeNodeNames 为节点名称添加用户个性化信息（剩余时间/剩余流量）。
 func personalizeNodeNames(nodes []protocol.Node, user *repository.User, showUserInfo bool, lang string, i18nMgr *i18n.Manager) []protocol.Node {
 	if !showUserInfo || user == nil {
@@ -629,7 +733,7 @@ func personalizeNodeNames(nodes []protocol.Node, user *repository.User, showUser
 		if daysLeft <= 0 {
 			suffix = " | " + formatI18n(i18nMgr, lang, "subscription.node.expired")
 		} else if daysLeft <= 7 {
-			suffix = " | " + formatI18n(i18nMgr, lang, "subscription.node.days_left", daysLeft)
+			suffix = " | " + formatI18nPlural(i18nMgr, lang, "subscription.node.days_left", daysLeft)
 		}
 	}
 
@@ -381,21 +381,7 @@ func (s *subscriptionFilterService) loadExternalFilterReasons(ctx context.Contex
 }
 
 func (s *subscriptionFilterService) userGroupIDs(ctx context.Context, user *repository.User) ([]int64, error) {
-	if user == nil {
-		return nil, nil
-	}
-	groupIDs := make([]int64, 0, 4)
-	if user.GroupID > 0 {
-		groupIDs = append(groupIDs, user.GroupID)
-	}
-	if user.PlanID > 0 && s.plans != nil {
-		planGroups, err := s.plans.GetGroups(ctx, user.PlanID)
-		if err != nil {
-			return nil, err
-		}
-		groupIDs = append(groupIDs, planGroups...)
-	}
-	return groupIDs, nil
+	return effectiveGroupIDs(ctx, s.plans, user)
 }
 
 func (s *subscriptionFilterService) userSelectedServerIDs(ctx context.Context, user *repository.User) (map[int64]struct{}, bool) {
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+const (
+	defaultSubscriptionLogWindow = 24 * time.Hour
+	maxSubscriptionLogWindow     = 90 * 24 * time.Hour
+	defaultSubscriptionLogLimit  = 50
+	maxSubscriptionLogLimit      = 500
+
+	// subscriptionLogUnknownClient 标记未识别出客户端类型的拉取。
+	subscriptionLogUnknownClient = "unknown"
+)
+
+// SubscriptionLogService 基于订阅访问日志提供共享账号排查与客户端分布分析。
+type SubscriptionLogService interface {
+	// UniqueIPsByUser 返回窗口内按不同 IP 数降序的用户列表，minIPs 用于只看可疑用户。
+	UniqueIPsByUser(ctx context.Context, window time.Duration, minIPs int, limit int) ([]*repository.SubscriptionUserIPCount, error)
+	// ClientBreakdown 返回窗口内各客户端类型的拉取次数与用户数，userID 为 0 时统计全部用户。
+	ClientBreakdown(ctx context.Context, window time.Duration, userID int64) ([]*repository.SubscriptionClientCount, error)
+	// UserIPDetail 返回单个用户窗口内的不同 IP 及首末次出现时间。
+	UserIPDetail(ctx context.Context, userID int64, window time.Duration, limit int) (*SubscriptionUserIPDetail, error)
+}
+
+// SubscriptionUserIPDetail 是单个用户的订阅来源明细。
+type SubscriptionUserIPDetail struct {
+	UserID        int64                                 `json:"user_id"`
+	UserEmail     string                                `json:"user_email"`
+	WindowSeconds int64                                 `json:"window_seconds"`
+	UniqueIPs     int                                   `json:"unique_ips"`
+	IPs           []*repository.SubscriptionIPActivity  `json:"ips"`
+	Clients       []*repository.SubscriptionClientCount `json:"clients"`
+}
+
+type subscriptionLogService struct {
+	logs  repository.SubscriptionLogRepository
+	users repository.UserRepository
+	now   func() time.Time
+}
+
+// NewSubscriptionLogService 创建订阅日志分析服务。
+func NewSubscriptionLogService(logs repository.SubscriptionLogRepository, users repository.UserRepository) SubscriptionLogService {
+	return &subscriptionLogService{logs: logs, users: users, now: time.Now}
+}
+
+func (s *subscriptionLogService) UniqueIPsByUser(ctx context.Context, window time.Duration, minIPs int, limit int) ([]*repository.SubscriptionUserIPCount, error) {
+	filter := s.filter(window, limit)
+	if minIPs > 0 {
+		filter.MinUniqueIPs = minIPs
+	}
+	items, err := s.logs.CountUniqueIPsByUser(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []*repository.SubscriptionUserIPCount{}
+	}
+	return items, nil
+}
+
+func (s *subscriptionLogService) ClientBreakdown(ctx context.Context, window time.Duration, userID int64) ([]*repository.SubscriptionClientCount, error) {
+	filter := s.filter(window, 0)
+	if userID > 0 {
+		filter.UserID = &userID
+	}
+	items, err := s.logs.CountByClientType(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return normalizeSubscriptionClients(items), nil
+}
+
+func (s *subscriptionLogService) UserIPDetail(ctx context.Context, userID int64, window time.Duration, limit int) (*SubscriptionUserIPDetail, error) {
+	if userID <= 0 {
+		return nil, fmt.Errorf("%w: user_id is required / 缺少 user_id", ErrBadRequest)
+	}
+	detail := &SubscriptionUserIPDetail{UserID: userID}
+	if s.users != nil {
+		user, err := s.users.FindByID(ctx, userID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return nil, ErrNotFound
+			}
+			return nil, err
+		}
+		detail.UserEmail = user.Email
+	}
+
+	filter := s.filter(window, limit)
+	filter.UserID = &userID
+	detail.WindowSeconds = filter.Until - 1 - filter.Since
+
+	ips, err := s.logs.ListDistinctIPs(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, ip := range ips {
+		if ip.LastClient == "" {
+			ip.LastClient = subscriptionLogUnknownClient
+		}
+	}
+	if ips == nil {
+		ips = []*repository.SubscriptionIPActivity{}
+	}
+	detail.IPs = ips
+
+	// IP 列表受 limit 截断，总数单独统计
+	counts, err := s.logs.CountUniqueIPsByUser(ctx, repository.SubscriptionLogFilter{UserID: &userID, Since: filter.Since, Until: filter.Until, Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(counts) > 0 {
+		detail.UniqueIPs = int(counts[0].UniqueIPs)
+	}
+
+	clients, err := s.logs.CountByClientType(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	detail.Clients = normalizeSubscriptionClients(clients)
+	return detail, nil
+}
+
+// filter clamps window and limit to sane bounds and builds the repository filter.
+func (s *subscriptionLogService) filter(window time.Duration, limit int) repository.SubscriptionLogFilter {
+	if window <= 0 {
+		window = defaultSubscriptionLogWindow
+	}
+	if window > maxSubscriptionLogWindow {
+		window = maxSubscriptionLogWindow
+	}
+	if limit <= 0 {
+		limit = defaultSubscriptionLogLimit
+	}
+	if limit > maxSubscriptionLogLimit {
+		limit = maxSubscriptionLogLimit
+	}
+	now := s.now()
+	return repository.SubscriptionLogFilter{
+		Since: now.Add(-window).Unix(),
+		Until: now.Unix() + 1,
+		Limit: limit,
+	}
+}
+
+// normalizeSubscriptionClients 将未识别客户端的空类型标记为 unknown。
+func normalizeSubscriptionClients(items []*repository.SubscriptionClientCount) []*repository.SubscriptionClientCount {
+	out := make([]*repository.SubscriptionClientCount, 0, len(items))
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		if item.Client == "" {
+			item.Client = subscriptionLogUnknownClient
+		}
+		out = append(out, item)
+	}
+	return out
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+// SubscriptionPreview 是管理端预览的订阅渲染结果。Masked 为 true 时 Content 中的 UUID、令牌等凭据已打码。
+type SubscriptionPreview struct {
+	UserID        int64  `json:"user_id"`
+	ClientName    string `json:"client_name"`
+	ClientVersion string `json:"client_version"`
+	ContentType   string `json:"content_type"`
+	Content       string `json:"content"`
+	Masked        bool   `json:"masked"`
+}
+
+// subscriptionPreviewAudit 是写入审计日志的预览参数，不包含渲染内容。
+type subscriptionPreviewAudit struct {
+	Flag       string
+	Types      string
+	Filter     string
+	Tags       string
+	TemplateID int64
+	Stack      string
+	Sort       string
+	ClientName string
+	Revealed   bool
+}
+
+func (s *subscriptionService) SetAuditLog(audit AuditLogService) {
+	s.audit = audit
+}
+
+// Preview 以指定用户的身份调用 Subscribe，过滤与在线判断与用户真实拉取一致；reveal 为 false 时对凭据打码。
+func (s *subscriptionService) Preview(ctx context.Context, userID int64, params SubscriptionParams, reveal bool) (*SubscriptionPreview, error) {
+	if userID <= 0 {
+		return nil, fmt.Errorf("%w: user id is required / 缺少用户 ID", ErrBadRequest)
+	}
+	if s == nil || s.users == nil {
+		return nil, fmt.Errorf("subscription service not configured / 订阅服务未配置")
+	}
+	user, err := loadServerUser(ctx, s.users, strconv.FormatInt(userID, 10))
+	if err != nil {
+		return nil, err
+	}
+
+	params.Preview = true
+	result, err := s.Subscribe(ctx, strconv.FormatInt(user.ID, 10), params)
+	if err != nil {
+		return nil, err
+	}
+	content := string(result.Payload)
+	if !reveal {
+		content = maskSubscriptionPreview(content, user)
+	}
+
+	recordAudit(ctx, s.audit, AuditEntry{
+		Action:     AuditActionPreview,
+		TargetType: AuditTargetUser,
+		TargetID:   user.ID,
+		After: subscriptionPreviewAudit{
+			Flag:       params.Flag,
+			Types:      params.Types,
+			Filter:     params.Filter,
+			Tags:       params.Tags,
+			TemplateID: params.TemplateID,
+			Stack:      params.Stack,
+			Sort:       params.Sort,
+			ClientName: result.ClientName,
+			Revealed:   reveal,
+		},
+	})
+	return &SubscriptionPreview{
+		UserID:        user.ID,
+		ClientName:    result.ClientName,
+		ClientVersion: result.ClientVersion,
+		ContentType:   result.ContentType,
+		Content:       content,
+		Masked:        !reveal,
+	}, nil
+}
+
+// maskSubscriptionPreview 先按配置结构打码凭据字段，再替换残留的用户 UUID 与订阅令牌（如 Surge 的 username、订阅地址）。
+func maskSubscriptionPreview(content string, user *repository.User) string {
+	masked := MaskClientConfig(content)
+	for _, secret := range []string{strings.TrimSpace(user.UUID), strings.TrimSpace(user.Token)} {
+		if secret != "" {
+			masked = strings.ReplaceAll(masked, secret, previewMaskValue)
+		}
+	}
+	return masked
+}
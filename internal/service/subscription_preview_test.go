@@ -0,0 +1,22 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+func TestMaskSubscriptionPreviewHidesUserCredentials(t *testing.T) {
+	user := &repository.User{UUID: "8d3f2c1e-0000-4000-8000-1234567890ab", Token: "subtoken123"}
+	content := "#!MANAGED-CONFIG https://example.com/api/v1/client/subscribe?token=subtoken123\n" +
+		"[Proxy]\nnode = vmess, example.com, 443, username=8d3f2c1e-0000-4000-8000-1234567890ab, tls=true\n"
+
+	masked := maskSubscriptionPreview(content, user)
+	if strings.Contains(masked, user.UUID) || strings.Contains(masked, user.Token) {
+		t.Fatalf("preview still contains user credentials: %s", masked)
+	}
+	if !strings.Contains(masked, "example.com, 443") {
+		t.Fatalf("preview lost non-secret content: %s", masked)
+	}
+}
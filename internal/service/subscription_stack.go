@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/protocol"
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+// 订阅 stack 参数取值：只返回可通过 IPv4 / IPv6 访问的节点，both 表示不过滤。
+const (
+	SubscriptionStackBoth = "both"
+	SubscriptionStackV4   = "v4"
+	SubscriptionStackV6   = "v6"
+)
+
+const (
+	// nodeStackCacheTTL 为域名解析结果的缓存时长，避免每次拉取订阅都查询 DNS。
+	nodeStackCacheTTL = 5 * time.Minute
+	// nodeStackFailureTTL 为解析失败结果的缓存时长，较短以便 DNS 恢复后尽快生效。
+	nodeStackFailureTTL = 30 * time.Second
+	// nodeStackLookupTimeout 限制单个域名的解析耗时。
+	nodeStackLookupTimeout = 2 * time.Second
+	// nodeStackLookupConcurrency 为单次订阅并发解析的域名数上限。
+	nodeStackLookupConcurrency = 8
+)
+
+// ParseSubscriptionStack 规范化 stack 参数，空值视为 both，不支持的取值返回 ErrBadRequest。
+func ParseSubscriptionStack(raw string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", SubscriptionStackBoth, "all", "dual":
+		return SubscriptionStackBoth, nil
+	case SubscriptionStackV4, "ipv4", "4":
+		return SubscriptionStackV4, nil
+	case SubscriptionStackV6, "ipv6", "6":
+		return SubscriptionStackV6, nil
+	default:
+		return "", fmt.Errorf("%w: unsupported stack %q, expected v4, v6 or both / 不支持的 stack 参数", ErrBadRequest, raw)
+	}
+}
+
+// nodeStack 记录节点地址支持的 IP 协议族。
+type nodeStack struct {
+	v4 bool
+	v6 bool
+}
+
+func (n nodeStack) supports(stack string) bool {
+	switch stack {
+	case SubscriptionStackV4:
+		return n.v4
+	case SubscriptionStackV6:
+		return n.v6
+	default:
+		return true
+	}
+}
+
+type nodeStackEntry struct {
+	stack     nodeStack
+	expiresAt time.Time
+}
+
+// nodeStackResolver 判断节点 Host 支持的 IP 协议族：IP 字面量直接判断，域名解析后按 TTL 缓存。
+type nodeStackResolver struct {
+	mu      sync.Mutex
+	entries map[string]nodeStackEntry
+	now     func() time.Time
+	lookup  func(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+func newNodeStackResolver() *nodeStackResolver {
+	return &nodeStackResolver{
+		entries: make(map[string]nodeStackEntry),
+		now:     time.Now,
+		lookup:  net.DefaultResolver.LookupIPAddr,
+	}
+}
+
+// resolve 返回每个 host 的协议族；未命中缓存的域名并发解析，解析失败的域名视为两种协议族都不可用。
+func (r *nodeStackResolver) resolve(ctx context.Context, hosts []string) map[string]nodeStack {
+	result := make(map[string]nodeStack, len(hosts))
+	var pending []string
+	now := r.now()
+	r.mu.Lock()
+	for _, host := range hosts {
+		if _, done := result[host]; done {
+			continue
+		}
+		if stack, ok := literalNodeStack(host); ok {
+			result[host] = stack
+			continue
+		}
+		if entry, ok := r.entries[host]; ok && now.Before(entry.expiresAt) {
+			result[host] = entry.stack
+			continue
+		}
+		result[host] = nodeStack{}
+		pending = append(pending, host)
+	}
+	r.mu.Unlock()
+
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex
+		sem = make(chan struct{}, nodeStackLookupConcurrency)
+	)
+	for _, host := range pending {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(host string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			stack, err := r.lookupStack(ctx, host)
+			ttl := nodeStackCacheTTL
+			if err != nil {
+				ttl = nodeStackFailureTTL
+			}
+			r.mu.Lock()
+			r.entries[host] = nodeStackEntry{stack: stack, expiresAt: r.now().Add(ttl)}
+			r.mu.Unlock()
+			mu.Lock()
+			result[host] = stack
+			mu.Unlock()
+		}(host)
+	}
+	wg.Wait()
+	return result
+}
+
+func (r *nodeStackResolver) lookupStack(ctx context.Context, host string) (nodeStack, error) {
+	lookupCtx, cancel := context.WithTimeout(ctx, nodeStackLookupTimeout)
+	defer cancel()
+	addrs, err := r.lookup(lookupCtx, host)
+	if err != nil {
+		return nodeStack{}, err
+	}
+	var stack nodeStack
+	for _, addr := range addrs {
+		if addr.IP.To4() != nil {
+			stack.v4 = true
+		} else if addr.IP.To16() != nil {
+			stack.v6 = true
+		}
+	}
+	return stack, nil
+}
+
+// literalNodeStack 处理 IP 字面量（含 [v6] 写法），非 IP 返回 false。
+func literalNodeStack(host string) (nodeStack, bool) {
+	ip := net.ParseIP(strings.Trim(host, "[]"))
+	if ip == nil {
+		return nodeStack{}, false
+	}
+	if ip.To4() != nil {
+		return nodeStack{v4: true}, true
+	}
+	return nodeStack{v6: true}, true
+}
+
+// filterByStack 只保留 Host 支持所请求协议族的节点与外部订阅源节点。
+func (s *subscriptionService) filterByStack(ctx context.Context, servers []*repository.Server, nodes []protocol.Node, stack string) ([]*repository.Server, []protocol.Node) {
+	if stack == SubscriptionStackBoth || s.stacks == nil {
+		return servers, nodes
+	}
+	hosts := make([]string, 0, len(servers)+len(nodes))
+	for _, server := range servers {
+		if server != nil {
+			hosts = append(hosts, normalizeNodeHost(server.Host))
+		}
+	}
+	for _, node := range nodes {
+		hosts = append(hosts, normalizeNodeHost(node.Host))
+	}
+	stacks := s.stacks.resolve(ctx, hosts)
+
+	filteredServers := make([]*repository.Server, 0, len(servers))
+	for _, server := range servers {
+		if server != nil && stacks[normalizeNodeHost(server.Host)].supports(stack) {
+			filteredServers = append(filteredServers, server)
+		}
+	}
+	filteredNodes := make([]protocol.Node, 0, len(nodes))
+	for _, node := range nodes {
+		if stacks[normalizeNodeHost(node.Host)].supports(stack) {
+			filteredNodes = append(filteredNodes, node)
+		}
+	}
+	return filteredServers, filteredNodes
+}
+
+func normalizeNodeHost(host string) string {
+	return strings.ToLower(strings.TrimSpace(host))
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/protocol"
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+func TestParseSubscriptionStack(t *testing.T) {
+	cases := map[string]string{"": SubscriptionStackBoth, "Both": SubscriptionStackBoth, "ipv4": SubscriptionStackV4, "6": SubscriptionStackV6}
+	for raw, want := range cases {
+		if got, err := ParseSubscriptionStack(raw); err != nil || got != want {
+			t.Fatalf("ParseSubscriptionStack(%q) = (%q, %v), want %q", raw, got, err, want)
+		}
+	}
+	if _, err := ParseSubscriptionStack("v5"); !errors.Is(err, ErrBadRequest) {
+		t.Fatalf("expected ErrBadRequest, got %v", err)
+	}
+}
+
+func TestFilterByStackUsesCachedResolution(t *testing.T) {
+	var lookups atomic.Int32
+	resolver := newNodeStackResolver()
+	resolver.lookup = func(_ context.Context, host string) ([]net.IPAddr, error) {
+		lookups.Add(1)
+		switch host {
+		case "dual.example.com":
+			return []net.IPAddr{{IP: net.ParseIP("203.0.113.1")}, {IP: net.ParseIP("2001:db8::1")}}, nil
+		case "v4.example.com":
+			return []net.IPAddr{{IP: net.ParseIP("203.0.113.2")}}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+	now := time.Unix(1_800_000_000, 0)
+	resolver.now = func() time.Time { return now }
+	svc := &subscriptionService{stacks: resolver}
+
+	servers := []*repository.Server{
+		{ID: 1, Host: "dual.example.com"},
+		{ID: 2, Host: "V4.example.com"},
+		{ID: 3, Host: "2001:db8::2"},
+		{ID: 4, Host: "broken.example.com"},
+	}
+	nodes := []protocol.Node{{Host: "198.51.100.7"}, {Host: "[2001:db8::3]"}}
+
+	gotServers, gotNodes := svc.filterByStack(context.Background(), servers, nodes, SubscriptionStackV6)
+	if len(gotServers) != 2 || gotServers[0].ID != 1 || gotServers[1].ID != 3 {
+		t.Fatalf("unexpected v6 servers: %+v", gotServers)
+	}
+	if len(gotNodes) != 1 || gotNodes[0].Host != "[2001:db8::3]" {
+		t.Fatalf("unexpected v6 source nodes: %+v", gotNodes)
+	}
+
+	gotServers, gotNodes = svc.filterByStack(context.Background(), servers, nodes, SubscriptionStackV4)
+	if len(gotServers) != 2 || gotServers[0].ID != 1 || gotServers[1].ID != 2 || len(gotNodes) != 1 {
+		t.Fatalf("unexpected v4 result: %+v %+v", gotServers, gotNodes)
+	}
+	if got := lookups.Load(); got != 3 {
+		t.Fatalf("expected 3 lookups with cache hits on second pass, got %d", got)
+	}
+
+	now = now.Add(nodeStackCacheTTL + time.Second)
+	svc.filterByStack(context.Background(), servers, nodes, SubscriptionStackV4)
+	if got := lookups.Load(); got != 6 {
+		t.Fatalf("expected cache to expire after TTL, got %d lookups", got)
+	}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+// resolveSubscriptionTemplate 依次尝试请求参数、用户默认与套餐默认模板，返回第一个与当前客户端类型匹配的模板键和内容。
+// 都未设置、模板不存在或类型与客户端不匹配时返回空键，沿用系统默认模板。
+func (s *subscriptionService) resolveSubscriptionTemplate(ctx context.Context, user *repository.User, params SubscriptionParams) (string, string) {
+	if s.templates == nil || user == nil {
+		return "", ""
+	}
+	key := s.protocols.TemplateKey(resolveClientFlag(params), params.UserAgent)
+	if key == "" {
+		return "", ""
+	}
+
+	candidates := []func() int64{
+		func() int64 { return params.TemplateID },
+		func() int64 { return valueOrZero(user.SubscriptionTemplateID) },
+		func() int64 {
+			// 套餐模板仅在前两级都未命中时才查询套餐
+			if s.plans == nil || user.PlanID <= 0 {
+				return 0
+			}
+			plan, err := s.plans.FindByID(ctx, user.PlanID)
+			if err != nil || plan == nil {
+				return 0
+			}
+			return valueOrZero(plan.SubscriptionTemplateID)
+		},
+	}
+	for _, candidate := range candidates {
+		id := candidate()
+		if id <= 0 {
+			continue
+		}
+		tpl, err := s.templates.FindByID(ctx, id)
+		if err != nil || tpl == nil {
+			continue
+		}
+		if subscriptionTemplateKey(tpl.Type) != key {
+			continue
+		}
+		return key, tpl.Content
+	}
+	return "", ""
+}
+
+// subscriptionTemplateKey 把模板类型归一化为协议构建器读取的模板键，通用模板返回空串。
+func subscriptionTemplateKey(templateType string) string {
+	switch strings.ToLower(strings.TrimSpace(templateType)) {
+	case "clash":
+		return "clash"
+	case "clash-meta", "clashmeta", "mihomo":
+		return "clash-meta"
+	case "singbox", "sing-box":
+		return "sing-box"
+	case "surge":
+		return "surge"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/creamcroissant/xboard/internal/protocol"
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+type stubSubscriptionTemplateRepo struct {
+	repository.SubscriptionTemplateRepository
+	templates map[int64]*repository.SubscriptionTemplate
+}
+
+func (r *stubSubscriptionTemplateRepo) FindByID(_ context.Context, id int64) (*repository.SubscriptionTemplate, error) {
+	if tpl, ok := r.templates[id]; ok {
+		return tpl, nil
+	}
+	return nil, repository.ErrNotFound
+}
+
+type stubSubscriptionPlanRepo struct {
+	repository.PlanRepository
+	plan *repository.Plan
+}
+
+func (r *stubSubscriptionPlanRepo) FindByID(_ context.Context, id int64) (*repository.Plan, error) {
+	if r.plan != nil && r.plan.ID == id {
+		return r.plan, nil
+	}
+	return nil, repository.ErrNotFound
+}
+
+func TestResolveSubscriptionTemplatePriority(t *testing.T) {
+	planTemplate, userTemplate, paramTemplate, singboxTemplate := int64(1), int64(2), int64(3), int64(4)
+	svc := &subscriptionService{
+		protocols: protocol.NewManager(protocol.NewGeneralBuilder(), protocol.NewClashBuilder(), protocol.NewSingboxBuilder()),
+		templates: &stubSubscriptionTemplateRepo{templates: map[int64]*repository.SubscriptionTemplate{
+			planTemplate:    {ID: planTemplate, Type: "clash", Content: "plan"},
+			userTemplate:    {ID: userTemplate, Type: "clash", Content: "user"},
+			paramTemplate:   {ID: paramTemplate, Type: "clash", Content: "param"},
+			singboxTemplate: {ID: singboxTemplate, Type: "sing-box", Content: "singbox"},
+		}},
+		plans: &stubSubscriptionPlanRepo{plan: &repository.Plan{ID: 9, SubscriptionTemplateID: &planTemplate}},
+	}
+	user := &repository.User{ID: 1, PlanID: 9, SubscriptionTemplateID: &userTemplate}
+	clash := SubscriptionParams{Flag: "clash"}
+
+	cases := []struct {
+		name   string
+		user   *repository.User
+		params SubscriptionParams
+		key    string
+		want   string
+	}{
+		{name: "request param wins", user: user, params: SubscriptionParams{Flag: "clash", TemplateID: paramTemplate}, key: "clash", want: "param"},
+		{name: "user over plan", user: user, params: clash, key: "clash", want: "user"},
+		{name: "plan default", user: &repository.User{ID: 2, PlanID: 9}, params: clash, key: "clash", want: "plan"},
+		{name: "mismatched param falls through", user: user, params: SubscriptionParams{Flag: "clash", TemplateID: singboxTemplate}, key: "clash", want: "user"},
+		{name: "mismatched assignments keep system default", user: user, params: SubscriptionParams{Flag: "sing-box"}},
+		{name: "nothing assigned", user: &repository.User{ID: 3}, params: clash},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			key, content := svc.resolveSubscriptionTemplate(context.Background(), tc.user, tc.params)
+			if key != tc.key || content != tc.want {
+				t.Fatalf("resolveSubscriptionTemplate() = (%q, %q), want (%q, %q)", key, content, tc.key, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,568 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/cache"
+	"github.com/creamcroissant/xboard/internal/notifier"
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+// TelegramService 提供 Telegram 机器人：管理员告警与用户查询、用户绑定账号接收提醒。
+// 未配置机器人令牌或未启用时所有入口均为空操作。
+type TelegramService interface {
+	Enabled(ctx context.Context) bool
+	// SendMessage 通过机器人发送消息。
+	SendMessage(ctx context.Context, chatID, text, parseMode string) error
+	// HandleWebhook 处理 webhook 模式下 Telegram 推送的更新，secret 为请求头中的密钥。
+	HandleWebhook(ctx context.Context, secret string, update notifier.TelegramUpdate) error
+	// Run 在长轮询模式下持续拉取更新，webhook 模式下负责注册 webhook，直到 ctx 结束。
+	Run(ctx context.Context)
+	// CreateBindToken 为用户生成一次性绑定令牌。
+	CreateBindToken(ctx context.Context, userID int64) (*TelegramBindToken, error)
+	// Unbind 解除用户的 Telegram 绑定。
+	Unbind(ctx context.Context, userID int64) error
+	// BotInfo 返回机器人用户名及当前用户的绑定状态。
+	BotInfo(ctx context.Context, userID int64) (*TelegramBotInfo, error)
+}
+
+// TelegramBindToken 是返回给前端的绑定指令。
+type TelegramBindToken struct {
+	Token       string `json:"token"`
+	Command     string `json:"command"`
+	BotUsername string `json:"bot_username,omitempty"`
+	Link        string `json:"link,omitempty"`
+	ExpiresAt   int64  `json:"expires_at"`
+}
+
+// TelegramBotInfo 描述机器人及用户绑定状态。
+type TelegramBotInfo struct {
+	Enabled  bool   `json:"enabled"`
+	Username string `json:"username,omitempty"`
+	Bound    bool   `json:"bound"`
+}
+
+// Telegram 机器人相关系统设置（telegram_admin_id 与节点离线告警共用）。
+const (
+	telegramBotEnableSetting     = "telegram_bot_enable"
+	telegramBotTokenSetting      = "telegram_bot_token"
+	telegramBotModeSetting       = "telegram_bot_mode"
+	telegramWebhookURLSetting    = "telegram_webhook_url"
+	telegramWebhookSecretSetting = "telegram_webhook_secret"
+	telegramAdminIDSetting       = "telegram_admin_id"
+
+	TelegramBotModePolling = "polling"
+	TelegramBotModeWebhook = "webhook"
+
+	telegramBindTokenTTL    = 10 * time.Minute
+	telegramPollTimeout     = 30 * time.Second
+	telegramIdleInterval    = 30 * time.Second
+	telegramMaxRetryBackoff = 5 * time.Minute
+)
+
+type telegramBindRecord struct {
+	UserID int64 `json:"user_id"`
+}
+
+type telegramBotConfig struct {
+	enabled       bool
+	token         string
+	mode          string
+	webhookURL    string
+	webhookSecret string
+}
+
+type telegramService struct {
+	users    repository.UserRepository
+	settings repository.SettingRepository
+	tokens   cache.Store
+	logger   *slog.Logger
+
+	mu          sync.Mutex
+	bot         *notifier.TelegramBot
+	botToken    string
+	botUsername string
+	offset      int64
+	webhookSet  string // 已注册的 webhook 地址，空串表示已切换为长轮询
+	bindMu      sync.Mutex
+}
+
+// NewTelegramService 创建 Telegram 机器人服务，绑定令牌存放在缓存中。
+func NewTelegramService(users repository.UserRepository, settings repository.SettingRepository, store cache.Store, logger *slog.Logger) TelegramService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	var tokens cache.Store
+	if store != nil {
+		tokens = store.Namespace("telegram").Namespace("bind")
+	}
+	return &telegramService{
+		users:      users,
+		settings:   settings,
+		tokens:     tokens,
+		logger:     logger,
+		webhookSet: "-",
+	}
+}
+
+func (s *telegramService) Enabled(ctx context.Context) bool {
+	if s == nil {
+		return false
+	}
+	cfg := s.config(ctx)
+	return cfg.enabled
+}
+
+func (s *telegramService) SendMessage(ctx context.Context, chatID, text, parseMode string) error {
+	cfg := s.config(ctx)
+	if !cfg.enabled {
+		return ErrFeatureDisabled
+	}
+	if strings.TrimSpace(chatID) == "" {
+		return fmt.Errorf("%w: telegram chat_id is required / Telegram chat_id 不能为空", ErrBadRequest)
+	}
+	return s.botFor(cfg).SendMessage(ctx, chatID, text, parseMode)
+}
+
+func (s *telegramService) HandleWebhook(ctx context.Context, secret string, update notifier.TelegramUpdate) error {
+	cfg := s.config(ctx)
+	if !cfg.enabled || cfg.mode != TelegramBotModeWebhook {
+		return ErrFeatureDisabled
+	}
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(cfg.webhookSecret)) != 1 {
+		return ErrUnauthorized
+	}
+	s.handleUpdate(ctx, cfg, update)
+	return nil
+}
+
+func (s *telegramService) Run(ctx context.Context) {
+	if s == nil {
+		return
+	}
+	backoff := time.Second
+	for ctx.Err() == nil {
+		cfg := s.config(ctx)
+		if !cfg.enabled {
+			sleepContext(ctx, telegramIdleInterval)
+			continue
+		}
+
+		var err error
+		wait := time.Duration(0)
+		if cfg.mode == TelegramBotModeWebhook {
+			err = s.ensureWebhook(ctx, cfg)
+			wait = telegramIdleInterval
+		} else {
+			err = s.poll(ctx, cfg)
+		}
+		if err != nil && ctx.Err() == nil {
+			s.logger.Warn("telegram bot loop failed", "mode", cfg.mode, "error", err, "retry_in", backoff)
+			wait = backoff
+			backoff = min(backoff*2, telegramMaxRetryBackoff)
+		} else if err == nil {
+			backoff = time.Second
+		}
+		if wait > 0 {
+			sleepContext(ctx, wait)
+		}
+	}
+}
+
+func (s *telegramService) CreateBindToken(ctx context.Context, userID int64) (*TelegramBindToken, error) {
+	cfg := s.config(ctx)
+	if !cfg.enabled {
+		return nil, ErrFeatureDisabled
+	}
+	if s.tokens == nil {
+		return nil, fmt.Errorf("token store unavailable / token 存储不可用")
+	}
+	if _, err := s.users.FindByID(ctx, userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	token, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tokens.SetJSON(ctx, token, telegramBindRecord{UserID: userID}, telegramBindTokenTTL); err != nil {
+		return nil, fmt.Errorf("store telegram bind token: %w", err)
+	}
+	result := &TelegramBindToken{
+		Token:     token,
+		Command:   "/bind " + token,
+		ExpiresAt: time.Now().Add(telegramBindTokenTTL).Unix(),
+	}
+	if username := s.username(ctx, cfg); username != "" {
+		result.BotUsername = username
+		result.Link = fmt.Sprintf("https://t.me/%s?start=%s", username, token)
+	}
+	return result, nil
+}
+
+func (s *telegramService) Unbind(ctx context.Context, userID int64) error {
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	if user.TelegramID == 0 {
+		return nil
+	}
+	user.TelegramID = 0
+	return s.users.Save(ctx, user)
+}
+
+func (s *telegramService) BotInfo(ctx context.Context, userID int64) (*TelegramBotInfo, error) {
+	cfg := s.config(ctx)
+	info := &TelegramBotInfo{Enabled: cfg.enabled}
+	if !cfg.enabled {
+		return info, nil
+	}
+	info.Username = s.username(ctx, cfg)
+	if userID > 0 {
+		user, err := s.users.FindByID(ctx, userID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return nil, err
+		}
+		info.Bound = user != nil && user.TelegramID != 0
+	}
+	return info, nil
+}
+
+// consumeBindToken 取出并立即删除绑定令牌，保证一次性使用。
+func (s *telegramService) consumeBindToken(ctx context.Context, token string) (int64, error) {
+	token = strings.TrimSpace(token)
+	if s.tokens == nil || token == "" {
+		return 0, ErrInvalidToken
+	}
+	s.bindMu.Lock()
+	defer s.bindMu.Unlock()
+	var record telegramBindRecord
+	found, err := s.tokens.GetJSON(ctx, token, &record)
+	if err != nil {
+		return 0, err
+	}
+	if !found || record.UserID == 0 {
+		return 0, ErrInvalidToken
+	}
+	s.tokens.Delete(ctx, token)
+	return record.UserID, nil
+}
+
+func (s *telegramService) poll(ctx context.Context, cfg telegramBotConfig) error {
+	bot := s.botFor(cfg)
+	s.mu.Lock()
+	needDelete := s.webhookSet != ""
+	offset := s.offset
+	s.mu.Unlock()
+	if needDelete {
+		// 从 webhook 模式切回（或首次启动）时需先删除 webhook，否则 getUpdates 返回 409
+		if err := bot.DeleteWebhook(ctx); err != nil {
+			return err
+		}
+		s.mu.Lock()
+		s.webhookSet = ""
+		s.mu.Unlock()
+	}
+
+	updates, err := bot.GetUpdates(ctx, offset, telegramPollTimeout)
+	if err != nil {
+		if errors.Is(err, notifier.ErrTelegramConflict) {
+			s.mu.Lock()
+			s.webhookSet = "-"
+			s.mu.Unlock()
+		}
+		return err
+	}
+	for _, update := range updates {
+		s.handleUpdate(ctx, cfg, update)
+		s.mu.Lock()
+		if update.UpdateID >= s.offset {
+			s.offset = update.UpdateID + 1
+		}
+		s.mu.Unlock()
+	}
+	return nil
+}
+
+func (s *telegramService) ensureWebhook(ctx context.Context, cfg telegramBotConfig) error {
+	if cfg.webhookURL == "" {
+		return fmt.Errorf("%s is required in webhook mode / webhook 模式需要配置 %s", telegramWebhookURLSetting, telegramWebhookURLSetting)
+	}
+	s.mu.Lock()
+	registered := s.webhookSet == cfg.webhookURL
+	s.mu.Unlock()
+	if registered {
+		return nil
+	}
+	if err := s.botFor(cfg).SetWebhook(ctx, cfg.webhookURL, cfg.webhookSecret); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.webhookSet = cfg.webhookURL
+	s.mu.Unlock()
+	s.logger.Info("telegram webhook registered", "url", cfg.webhookURL)
+	return nil
+}
+
+func (s *telegramService) handleUpdate(ctx context.Context, cfg telegramBotConfig, update notifier.TelegramUpdate) {
+	msg := update.Message
+	if msg == nil || msg.From == nil || msg.From.IsBot {
+		return
+	}
+	reply := s.handleCommand(ctx, msg)
+	if reply == "" {
+		return
+	}
+	if err := s.botFor(cfg).SendMessage(ctx, strconv.FormatInt(msg.Chat.ID, 10), reply, ""); err != nil {
+		s.logger.Warn("telegram reply failed", "chat_id", msg.Chat.ID, "error", err)
+	}
+}
+
+// handleCommand 解析并执行机器人指令，返回回复文本。
+func (s *telegramService) handleCommand(ctx context.Context, msg *notifier.TelegramMessage) string {
+	fields := strings.Fields(strings.TrimSpace(msg.Text))
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return ""
+	}
+	// 群组中的指令形如 /cmd@bot_name
+	command := strings.ToLower(strings.SplitN(fields[0], "@", 2)[0])
+	args := fields[1:]
+	fromID := msg.From.ID
+
+	switch command {
+	case "/start", "/bind":
+		if len(args) == 0 {
+			return telegramHelpText
+		}
+		if msg.Chat.Type != "" && msg.Chat.Type != "private" {
+			return "Please send the bind command in a private chat with the bot."
+		}
+		return s.bind(ctx, args[0], fromID)
+	case "/unbind":
+		user, err := s.users.FindByTelegramID(ctx, fromID)
+		if err != nil {
+			return "This Telegram account is not bound."
+		}
+		user.TelegramID = 0
+		if err := s.users.Save(ctx, user); err != nil {
+			return "Unbind failed, please try again later."
+		}
+		return "Telegram account unbound."
+	case "/info", "/status":
+		user, err := s.users.FindByTelegramID(ctx, fromID)
+		if err != nil {
+			return "This Telegram account is not bound. Use the bind command from your dashboard."
+		}
+		return formatTelegramUserStatus(user)
+	case "/user":
+		if !s.isAdmin(ctx, fromID) {
+			return "Permission denied."
+		}
+		if len(args) == 0 {
+			return "Usage: /user <email|uuid>"
+		}
+		user, err := s.lookupUser(ctx, args[0])
+		if err != nil {
+			return "User not found."
+		}
+		return formatTelegramUserStatus(user)
+	case "/help":
+		return telegramHelpText
+	default:
+		return ""
+	}
+}
+
+const telegramHelpText = `Available commands:
+/bind <token> - bind this Telegram account to your panel account
+/unbind - remove the binding
+/info - show your subscription status
+/user <email|uuid> - (admin) look up a user`
+
+func (s *telegramService) bind(ctx context.Context, token string, telegramID int64) string {
+	userID, err := s.consumeBindToken(ctx, token)
+	if err != nil {
+		return "Bind token is invalid or expired. Please generate a new one."
+	}
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return "Bind failed: account not found."
+	}
+	// 同一 Telegram 账号只能绑定一个用户，转移绑定时清除旧用户
+	if previous, err := s.users.FindByTelegramID(ctx, telegramID); err == nil && previous.ID != user.ID {
+		previous.TelegramID = 0
+		if err := s.users.Save(ctx, previous); err != nil {
+			return "Bind failed, please try again later."
+		}
+	}
+	user.TelegramID = telegramID
+	if err := s.users.Save(ctx, user); err != nil {
+		return "Bind failed, please try again later."
+	}
+	return fmt.Sprintf("Bound to %s. You will receive expiry and traffic reminders here.", user.Email)
+}
+
+func (s *telegramService) lookupUser(ctx context.Context, query string) (*repository.User, error) {
+	query = strings.TrimSpace(query)
+	if strings.Contains(query, "@") {
+		return s.users.FindByEmail(ctx, query)
+	}
+	return s.users.FindByUUID(ctx, query)
+}
+
+// isAdmin 判断 Telegram 账号是否为管理员：匹配 telegram_admin_id，或绑定的面板账号为管理员。
+func (s *telegramService) isAdmin(ctx context.Context, telegramID int64) bool {
+	for _, id := range strings.Split(s.settingString(ctx, telegramAdminIDSetting, ""), ",") {
+		if strings.TrimSpace(id) == strconv.FormatInt(telegramID, 10) {
+			return true
+		}
+	}
+	user, err := s.users.FindByTelegramID(ctx, telegramID)
+	return err == nil && user.IsAdmin
+}
+
+func formatTelegramUserStatus(user *repository.User) string {
+	status := "active"
+	switch {
+	case user.Banned:
+		status = "banned"
+	case user.Status != 1:
+		status = "disabled"
+	case user.ExpiredAt > 0 && user.ExpiredAt < time.Now().Unix():
+		status = "expired"
+	case user.TrafficExceeded:
+		status = "traffic exceeded"
+	}
+	expiry := "never"
+	if user.ExpiredAt > 0 {
+		expiry = time.Unix(user.ExpiredAt, 0).UTC().Format("2006-01-02 15:04 UTC")
+	}
+	used := user.U + user.D
+	return fmt.Sprintf("User: %s\nUUID: %s\nStatus: %s\nPlan: %d\nExpires: %s\nTraffic: %.2f / %.2f GB",
+		user.Email, user.UUID, status, user.PlanID, expiry,
+		float64(used)/(1<<30), float64(user.TransferEnable)/(1<<30))
+}
+
+func (s *telegramService) config(ctx context.Context) telegramBotConfig {
+	cfg := telegramBotConfig{
+		token: s.settingString(ctx, telegramBotTokenSetting, ""),
+		mode:  strings.ToLower(s.settingString(ctx, telegramBotModeSetting, TelegramBotModePolling)),
+	}
+	cfg.enabled = cfg.token != "" && s.boolSetting(ctx, telegramBotEnableSetting, true)
+	if cfg.mode != TelegramBotModeWebhook {
+		cfg.mode = TelegramBotModePolling
+	}
+	cfg.webhookURL = s.settingString(ctx, telegramWebhookURLSetting, "")
+	cfg.webhookSecret = s.settingString(ctx, telegramWebhookSecretSetting, "")
+	if cfg.webhookSecret == "" && cfg.token != "" {
+		// 未单独配置时由令牌派生，Telegram 仅允许 [A-Za-z0-9_-]
+		sum := sha256.Sum256([]byte("xboard-telegram-webhook:" + cfg.token))
+		cfg.webhookSecret = hex.EncodeToString(sum[:16])
+	}
+	return cfg
+}
+
+// botFor 返回与当前令牌对应的客户端，令牌变更时重建并清空轮询偏移。
+func (s *telegramService) botFor(cfg telegramBotConfig) *notifier.TelegramBot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.bot == nil || s.botToken != cfg.token {
+		s.bot = notifier.NewTelegramBot(cfg.token, nil)
+		s.botToken = cfg.token
+		s.botUsername = ""
+		s.offset = 0
+		s.webhookSet = "-"
+	}
+	return s.bot
+}
+
+func (s *telegramService) username(ctx context.Context, cfg telegramBotConfig) string {
+	bot := s.botFor(cfg)
+	s.mu.Lock()
+	cached := s.botUsername
+	s.mu.Unlock()
+	if cached != "" {
+		return cached
+	}
+	me, err := bot.GetMe(ctx)
+	if err != nil {
+		s.logger.Warn("telegram getMe failed", "error", err)
+		return ""
+	}
+	s.mu.Lock()
+	s.botUsername = me.Username
+	s.mu.Unlock()
+	return me.Username
+}
+
+func (s *telegramService) settingString(ctx context.Context, key, def string) string {
+	if s == nil || s.settings == nil {
+		return def
+	}
+	setting, err := s.settings.Get(ctx, key)
+	if err != nil || setting == nil {
+		return def
+	}
+	value := strings.TrimSpace(setting.Value)
+	if value == "" {
+		return def
+	}
+	return value
+}
+
+func (s *telegramService) boolSetting(ctx context.Context, key string, def bool) bool {
+	switch strings.ToLower(s.settingString(ctx, key, "")) {
+	case "1", "true", "yes", "on":
+		return true
+	case "0", "false", "no", "off":
+		return false
+	default:
+		return def
+	}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+// telegramNotifier 在机器人启用时通过 Bot API 发送 Telegram 通知，其余通知委托给原通知服务。
+type telegramNotifier struct {
+	notifier.Service
+	telegram TelegramService
+}
+
+// NewTelegramNotifier 包装通知服务，使 SendTelegram 走 Telegram 机器人。
+func NewTelegramNotifier(base notifier.Service, telegram TelegramService) notifier.Service {
+	return &telegramNotifier{Service: base, telegram: telegram}
+}
+
+func (n *telegramNotifier) SendTelegram(ctx context.Context, req notifier.TelegramRequest) error {
+	if n.telegram == nil || !n.telegram.Enabled(ctx) {
+		if n.Service == nil {
+			return notifier.ErrNotImplemented
+		}
+		return n.Service.SendTelegram(ctx, req)
+	}
+	return n.telegram.SendMessage(ctx, req.ChatID, req.Message, req.ParseMode)
+}
@@ -0,0 +1,364 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/async"
+	"github.com/creamcroissant/xboard/internal/notifier"
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+// UserReminderService 发送到期与流量不足提醒邮件，并提供下一次运行的预览。
+type UserReminderService interface {
+	// Preview 返回下一次运行会提醒的用户，不发送、不记录。
+	Preview(ctx context.Context) (*UserReminderPreview, error)
+	// Run 发送提醒并记录提醒时间。
+	Run(ctx context.Context) (*UserReminderRunResult, error)
+	// SetSchedule lets the bootstrap report when the scheduled run happens next.
+	SetSchedule(next func() time.Time)
+}
+
+// 提醒类型，同时作为 user_reminders.kind。
+const (
+	UserReminderKindExpiry  = "expiry"
+	UserReminderKindTraffic = "traffic"
+)
+
+// 提醒相关系统设置（email 分类）。主题与正文支持占位符：
+// {app_name} {app_url} {email} {expired_at} {days_left} {remaining} {transfer_enable}
+const (
+	reminderEnableSetting           = "reminder_enable"
+	reminderExpiryDaysSetting       = "reminder_expiry_days"
+	reminderTrafficThresholdSetting = "reminder_traffic_threshold_mb"
+	reminderCooldownHoursSetting    = "reminder_cooldown_hours"
+	reminderExpirySubjectSetting    = "reminder_expiry_subject"
+	reminderExpiryBodySetting       = "reminder_expiry_body"
+	reminderTrafficSubjectSetting   = "reminder_traffic_subject"
+	reminderTrafficBodySetting      = "reminder_traffic_body"
+
+	defaultReminderExpiryDays       = 3
+	defaultReminderTrafficThreshold = 1024
+	defaultReminderCooldownHours    = 72
+
+	defaultReminderExpirySubject  = "Your {app_name} subscription expires soon"
+	defaultReminderExpiryBody     = "Hi {email},\n\nYour subscription expires on {expired_at} ({days_left} days left). Renew at {app_url} to avoid interruption."
+	defaultReminderTrafficSubject = "Your {app_name} traffic is running low"
+	defaultReminderTrafficBody    = "Hi {email},\n\nOnly {remaining} of your {transfer_enable} traffic remains. Visit {app_url} to top up or upgrade your plan."
+
+	reminderExpiryTemplate  = "remind_expire"
+	reminderTrafficTemplate = "remind_traffic"
+)
+
+// UserReminderCandidate 是一条待发送的提醒。
+type UserReminderCandidate struct {
+	UserID         int64  `json:"user_id"`
+	Email          string `json:"email"`
+	Kind           string `json:"kind"`
+	ExpiredAt      int64  `json:"expired_at,omitempty"`
+	RemainingBytes int64  `json:"remaining_bytes,omitempty"`
+	LastRemindedAt int64  `json:"last_reminded_at,omitempty"`
+	Telegram       bool   `json:"telegram"`
+}
+
+// UserReminderPreview 描述下一次运行的提醒对象。
+type UserReminderPreview struct {
+	Enabled            bool                    `json:"enabled"`
+	ExpiryDays         int                     `json:"expiry_days"`
+	TrafficThresholdMB int                     `json:"traffic_threshold_mb"`
+	CooldownHours      int                     `json:"cooldown_hours"`
+	NextRunAt          int64                   `json:"next_run_at,omitempty"`
+	Candidates         []UserReminderCandidate `json:"candidates"`
+}
+
+// UserReminderRunResult 汇总一次运行的结果。
+type UserReminderRunResult struct {
+	Expiry  int `json:"expiry"`
+	Traffic int `json:"traffic"`
+}
+
+type userReminderSettings struct {
+	enabled          bool
+	expiryDays       int
+	trafficThreshold int
+	cooldownHours    int
+}
+
+type userReminderService struct {
+	users     repository.UserRepository
+	reminders repository.UserReminderRepository
+	settings  repository.SettingRepository
+	queue     *async.NotificationQueue
+
+	mu      sync.Mutex
+	nextRun func() time.Time
+}
+
+// NewUserReminderService 创建用户提醒服务，邮件与 Telegram 消息进入通知队列由通知任务发送。
+func NewUserReminderService(users repository.UserRepository, reminders repository.UserReminderRepository, settings repository.SettingRepository, queue *async.NotificationQueue) UserReminderService {
+	return &userReminderService{
+		users:     users,
+		reminders: reminders,
+		settings:  settings,
+		queue:     queue,
+	}
+}
+
+func (s *userReminderService) SetSchedule(next func() time.Time) {
+	s.mu.Lock()
+	s.nextRun = next
+	s.mu.Unlock()
+}
+
+func (s *userReminderService) Preview(ctx context.Context) (*UserReminderPreview, error) {
+	cfg := s.loadSettings(ctx)
+	preview := &UserReminderPreview{
+		Enabled:            cfg.enabled,
+		ExpiryDays:         cfg.expiryDays,
+		TrafficThresholdMB: cfg.trafficThreshold,
+		CooldownHours:      cfg.cooldownHours,
+	}
+	s.mu.Lock()
+	nextRun := s.nextRun
+	s.mu.Unlock()
+	if nextRun != nil {
+		if next := nextRun(); !next.IsZero() {
+			preview.NextRunAt = next.Unix()
+		}
+	}
+	candidates, _, err := s.collect(ctx, cfg, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	preview.Candidates = candidates
+	return preview, nil
+}
+
+func (s *userReminderService) Run(ctx context.Context) (*UserReminderRunResult, error) {
+	result := &UserReminderRunResult{}
+	cfg := s.loadSettings(ctx)
+	if !cfg.enabled {
+		return result, nil
+	}
+	if s.queue == nil {
+		return nil, fmt.Errorf("notification queue not configured / 通知队列未配置")
+	}
+	now := time.Now()
+	candidates, users, err := s.collect(ctx, cfg, now)
+	if err != nil {
+		return nil, err
+	}
+
+	appName := s.settingString(ctx, "app_name", "XBoard")
+	appURL := s.settingString(ctx, "app_url", "")
+	for _, candidate := range candidates {
+		user := users[candidate.UserID]
+		vars := reminderVariables(user, appName, appURL, now)
+		var subject, body, template string
+		if candidate.Kind == UserReminderKindExpiry {
+			subject = s.settingString(ctx, reminderExpirySubjectSetting, defaultReminderExpirySubject)
+			body = s.settingString(ctx, reminderExpiryBodySetting, defaultReminderExpiryBody)
+			template = reminderExpiryTemplate
+		} else {
+			subject = s.settingString(ctx, reminderTrafficSubjectSetting, defaultReminderTrafficSubject)
+			body = s.settingString(ctx, reminderTrafficBodySetting, defaultReminderTrafficBody)
+			template = reminderTrafficTemplate
+		}
+		subject = renderReminderTemplate(subject, vars)
+		body = renderReminderTemplate(body, vars)
+
+		s.queue.EnqueueEmail(notifier.EmailRequest{
+			To:        user.Email,
+			Subject:   subject,
+			Template:  template,
+			Body:      body,
+			Variables: vars,
+		})
+		if user.TelegramID != 0 {
+			s.queue.EnqueueTelegram(notifier.TelegramRequest{
+				ChatID:  strconv.FormatInt(user.TelegramID, 10),
+				Message: subject + "\n\n" + body,
+			})
+		}
+		if err := s.reminders.MarkReminded(ctx, user.ID, candidate.Kind, now.Unix()); err != nil {
+			return result, err
+		}
+		if candidate.Kind == UserReminderKindExpiry {
+			result.Expiry++
+		} else {
+			result.Traffic++
+		}
+	}
+	return result, nil
+}
+
+// collect 找出符合条件且已过冷却期的用户，返回提醒列表与用户索引。
+func (s *userReminderService) collect(ctx context.Context, cfg userReminderSettings, now time.Time) ([]UserReminderCandidate, map[int64]*repository.User, error) {
+	if s == nil || s.users == nil || s.reminders == nil {
+		return nil, nil, fmt.Errorf("user reminder service not configured / 用户提醒服务未配置")
+	}
+	users := make(map[int64]*repository.User)
+	candidates := make([]UserReminderCandidate, 0)
+	cooldownSince := now.Add(-time.Duration(cfg.cooldownHours) * time.Hour).Unix()
+
+	appendKind := func(kind string, list []*repository.User) error {
+		eligible := make([]*repository.User, 0, len(list))
+		ids := make([]int64, 0, len(list))
+		for _, user := range list {
+			if user == nil || strings.TrimSpace(user.Email) == "" {
+				continue
+			}
+			eligible = append(eligible, user)
+			ids = append(ids, user.ID)
+		}
+		last, err := s.reminders.LastReminded(ctx, kind, ids)
+		if err != nil {
+			return err
+		}
+		for _, user := range eligible {
+			remindedAt := last[user.ID]
+			if remindedAt > cooldownSince {
+				continue
+			}
+			users[user.ID] = user
+			candidate := UserReminderCandidate{
+				UserID:         user.ID,
+				Email:          user.Email,
+				Kind:           kind,
+				LastRemindedAt: remindedAt,
+				Telegram:       user.TelegramID != 0,
+			}
+			if kind == UserReminderKindExpiry {
+				candidate.ExpiredAt = user.ExpiredAt
+			} else {
+				candidate.RemainingBytes = user.TransferEnable - user.U - user.D
+			}
+			candidates = append(candidates, candidate)
+		}
+		return nil
+	}
+
+	if cfg.expiryDays > 0 {
+		expiring, err := s.users.ListExpiringBetween(ctx, now.Unix(), now.AddDate(0, 0, cfg.expiryDays).Unix())
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := appendKind(UserReminderKindExpiry, expiring); err != nil {
+			return nil, nil, err
+		}
+	}
+	if cfg.trafficThreshold > 0 {
+		lowTraffic, err := s.users.ListTrafficRemainingBelow(ctx, int64(cfg.trafficThreshold)<<20, now.Unix())
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := appendKind(UserReminderKindTraffic, lowTraffic); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Kind != candidates[j].Kind {
+			return candidates[i].Kind < candidates[j].Kind
+		}
+		return candidates[i].UserID < candidates[j].UserID
+	})
+	return candidates, users, nil
+}
+
+func (s *userReminderService) loadSettings(ctx context.Context) userReminderSettings {
+	cfg := userReminderSettings{
+		enabled:          s.boolSetting(ctx, reminderEnableSetting, false),
+		expiryDays:       s.intSetting(ctx, reminderExpiryDaysSetting, defaultReminderExpiryDays),
+		trafficThreshold: s.intSetting(ctx, reminderTrafficThresholdSetting, defaultReminderTrafficThreshold),
+		cooldownHours:    s.intSetting(ctx, reminderCooldownHoursSetting, defaultReminderCooldownHours),
+	}
+	if cfg.cooldownHours < 0 {
+		cfg.cooldownHours = 0
+	}
+	return cfg
+}
+
+func reminderVariables(user *repository.User, appName, appURL string, now time.Time) map[string]any {
+	expiredAt := "never"
+	daysLeft := 0
+	if user.ExpiredAt > 0 {
+		expiredAt = time.Unix(user.ExpiredAt, 0).UTC().Format("2006-01-02 15:04 UTC")
+		daysLeft = int(time.Unix(user.ExpiredAt, 0).Sub(now).Hours() / 24)
+	}
+	remaining := user.TransferEnable - user.U - user.D
+	if remaining < 0 {
+		remaining = 0
+	}
+	return map[string]any{
+		"app_name":        appName,
+		"app_url":         appURL,
+		"email":           user.Email,
+		"expired_at":      expiredAt,
+		"days_left":       daysLeft,
+		"remaining":       formatReminderBytes(remaining),
+		"transfer_enable": formatReminderBytes(user.TransferEnable),
+	}
+}
+
+func renderReminderTemplate(tpl string, vars map[string]any) string {
+	pairs := make([]string, 0, len(vars)*2)
+	for key, value := range vars {
+		pairs = append(pairs, "{"+key+"}", fmt.Sprint(value))
+	}
+	// 模板可能以转义形式保存在设置中
+	tpl = strings.ReplaceAll(tpl, `\n`, "\n")
+	return strings.NewReplacer(pairs...).Replace(tpl)
+}
+
+func formatReminderBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.2f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func (s *userReminderService) settingString(ctx context.Context, key, def string) string {
+	if s == nil || s.settings == nil {
+		return def
+	}
+	setting, err := s.settings.Get(ctx, key)
+	if err != nil || setting == nil {
+		return def
+	}
+	value := strings.TrimSpace(setting.Value)
+	if value == "" {
+		return def
+	}
+	return value
+}
+
+func (s *userReminderService) boolSetting(ctx context.Context, key string, def bool) bool {
+	switch strings.ToLower(s.settingString(ctx, key, "")) {
+	case "1", "true", "yes", "on":
+		return true
+	case "0", "false", "no", "off":
+		return false
+	default:
+		return def
+	}
+}
+
+func (s *userReminderService) intSetting(ctx context.Context, key string, def int) int {
+	value, err := strconv.Atoi(s.settingString(ctx, key, ""))
+	if err != nil {
+		return def
+	}
+	return value
+}
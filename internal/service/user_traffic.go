@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"math"
 	"strconv"
 	"time"
 
@@ -23,14 +25,22 @@ type UserTrafficDelta = repository.UserTrafficDelta
 type TrafficProcessResult struct {
 	AcceptedCount   int32
 	ExceededUserIDs []int64
+	// WarningUserIDs 是本批次首次越过软限额的用户，仅用于提醒，不触发限速
+	WarningUserIDs []int64
+	// RawBytes/ChargedBytes 为本批次入账的原始字节与乘以节点倍率后的计费字节
+	RawBytes     int64
+	ChargedBytes int64
+	// Deduplicated 表示该 batch_id 已入账过，本次未重复计费
+	Deduplicated bool
 }
 
 // UserTrafficService manages user traffic statistics and periods.
 type UserTrafficService interface {
 	// ProcessTraffic updates user traffic usage and checks quotas (with agent host tracking)
 	ProcessTraffic(ctx context.Context, agentHostID int64, userID int64, upload, download int64) error
-	// ProcessTrafficBatch processes multiple user traffic deltas in batch
-	ProcessTrafficBatch(ctx context.Context, agentHostID int64, traffic []UserTrafficDelta) (*TrafficProcessResult, error)
+	// ProcessTrafficBatch processes multiple user traffic deltas in batch; a non-empty batchID
+	// makes the batch idempotent per agent host
+	ProcessTrafficBatch(ctx context.Context, agentHostID int64, batchID string, traffic []UserTrafficDelta) (*TrafficProcessResult, error)
 	// GetTrafficStats returns the current traffic statistics for a user
 	GetTrafficStats(ctx context.Context, userID int64) (*repository.UserTrafficStats, error)
 
@@ -43,6 +53,8 @@ type UserTrafficService interface {
 	// Traffic period management
 	EnsureCurrentPeriod(ctx context.Context, userID int64) (*repository.UserTrafficPeriod, error)
 	ResetExpiredPeriods(ctx context.Context) (int, error)
+	// ResetPlanCycles zeroes u/d for users whose plan reset_cycle boundary has passed and archives the prior totals
+	ResetPlanCycles(ctx context.Context) (int, error)
 	GetExceededUsers(ctx context.Context) ([]int64, error)
 	ResetUserExceededStatus(ctx context.Context, userID int64) error
 }
@@ -50,9 +62,13 @@ type UserTrafficService interface {
 type userTrafficService struct {
 	trafficRepo       repository.UserTrafficRepository
 	userRepo          repository.UserRepository
+	plans             repository.PlanRepository
+	servers           repository.ServerRepository
 	statCollector     TrafficStatCollectorWithHost
 	notificationQueue *async.NotificationQueue
 	settings          repository.SettingRepository
+	metrics           TrafficMetricsRecorder
+	planCache         *userPlanCache
 }
 
 // NewUserTrafficService creates a new UserTrafficService.
@@ -67,16 +83,23 @@ func NewUserTrafficService(trafficRepo repository.UserTrafficRepository, userRep
 func NewUserTrafficServiceWithCollector(
 	trafficRepo repository.UserTrafficRepository,
 	userRepo repository.UserRepository,
+	plans repository.PlanRepository,
+	servers repository.ServerRepository,
 	collector TrafficStatCollectorWithHost,
 	notificationQueue *async.NotificationQueue,
 	settings repository.SettingRepository,
+	metrics TrafficMetricsRecorder,
 ) UserTrafficService {
 	return &userTrafficService{
 		trafficRepo:       trafficRepo,
 		userRepo:          userRepo,
+		plans:             plans,
+		servers:           servers,
 		statCollector:     collector,
 		notificationQueue: notificationQueue,
 		settings:          settings,
+		metrics:           metrics,
+		planCache:         newUserPlanCache(),
 	}
 }
 
@@ -169,32 +192,144 @@ func (s *userTrafficService) sendExceededNotification(ctx context.Context, userI
 }
 
 // ProcessTrafficBatch processes multiple user traffic deltas in batch.
-func (s *userTrafficService) ProcessTrafficBatch(ctx context.Context, agentHostID int64, traffic []UserTrafficDelta) (*TrafficProcessResult, error) {
+// The raw bytes reported by the agent are multiplied by the traffic ratio of the node they
+// were served by, so quota, user u/d and stat_users hold charged bytes; the traffic period
+// also keeps the raw bytes so both can be told apart.
+func (s *userTrafficService) ProcessTrafficBatch(ctx context.Context, agentHostID int64, batchID string, traffic []UserTrafficDelta) (*TrafficProcessResult, error) {
 	nowUnix := time.Now().Unix()
-	accepted, exceededUserIDs, err := s.trafficRepo.ApplyTrafficBatchAtomic(ctx, traffic, nowUnix)
+	traffic = s.chargeTraffic(ctx, agentHostID, traffic)
+	var key *repository.TrafficBatchKey
+	if batchID != "" {
+		key = &repository.TrafficBatchKey{AgentHostID: agentHostID, BatchID: batchID}
+	}
+	batch, err := s.trafficRepo.ApplyTrafficBatchAtomic(ctx, key, traffic, nowUnix, s.trafficWarningPercent(ctx))
+	if s.metrics != nil {
+		s.metrics.ObserveBatch(err == nil)
+	}
 	if err != nil {
 		return nil, err
 	}
+	if batch.Duplicate {
+		return &TrafficProcessResult{Deduplicated: true}, nil
+	}
+	accepted := batch.Accepted
 
 	result := &TrafficProcessResult{
 		AcceptedCount: int32(len(accepted)),
 	}
-	if len(exceededUserIDs) > 0 {
-		result.ExceededUserIDs = append(result.ExceededUserIDs, exceededUserIDs...)
-		for _, userID := range exceededUserIDs {
+	for _, item := range accepted {
+		result.RawBytes += item.RawUpload + item.RawDownload
+		result.ChargedBytes += item.Upload + item.Download
+	}
+	if len(batch.ExceededUserIDs) > 0 {
+		result.ExceededUserIDs = append(result.ExceededUserIDs, batch.ExceededUserIDs...)
+		for _, userID := range batch.ExceededUserIDs {
 			s.sendExceededNotification(ctx, userID)
 		}
 	}
+	if len(batch.WarningUserIDs) > 0 {
+		result.WarningUserIDs = append(result.WarningUserIDs, batch.WarningUserIDs...)
+		for _, userID := range batch.WarningUserIDs {
+			s.sendWarningNotification(ctx, userID)
+		}
+	}
 
 	if s.statCollector != nil {
 		for _, item := range accepted {
 			s.statCollector.CollectWithHost(agentHostID, item.UserID, item.Upload, item.Download)
 		}
 	}
+	s.recordTrafficMetrics(ctx, accepted)
 
 	return result, nil
 }
 
+// chargeTraffic converts raw deltas to charged bytes using the ratio of each delta's node.
+func (s *userTrafficService) chargeTraffic(ctx context.Context, agentHostID int64, traffic []UserTrafficDelta) []UserTrafficDelta {
+	var servers []*repository.Server
+	if s.servers != nil && agentHostID > 0 {
+		if found, err := s.servers.FindByAgentHostID(ctx, agentHostID); err == nil {
+			servers = found
+		}
+	}
+	userGroups := make(map[int64]int64)
+	charged := make([]UserTrafficDelta, 0, len(traffic))
+	for _, item := range traffic {
+		charged = append(charged, applyTrafficRatio(item, s.trafficRatioFor(ctx, agentHostID, servers, item, userGroups)))
+	}
+	return charged
+}
+
+// trafficRatioFor picks the ratio of the node a delta was served by. Agents that cannot
+// attribute traffic leave ServerID at 0; the host's ratio is then used when all its nodes
+// agree, otherwise the nodes are narrowed to the user's group. If nodes in that group still
+// differ the lowest ratio is charged and a warning is logged, since the origin is unknown.
+func (s *userTrafficService) trafficRatioFor(ctx context.Context, agentHostID int64, servers []*repository.Server, item UserTrafficDelta, userGroups map[int64]int64) float64 {
+	if len(servers) == 0 {
+		return 1
+	}
+	if item.ServerID > 0 {
+		for _, server := range servers {
+			if server.ID == item.ServerID {
+				return serverTrafficRatio(server)
+			}
+		}
+	}
+	if ratio, ok := uniformTrafficRatio(servers); ok {
+		return ratio
+	}
+	groupID, ok := userGroups[item.UserID]
+	if !ok && s.userRepo != nil {
+		if user, err := s.userRepo.FindByID(ctx, item.UserID); err == nil && user != nil {
+			groupID = user.GroupID
+		}
+		userGroups[item.UserID] = groupID
+	}
+	candidates := make([]*repository.Server, 0, len(servers))
+	for _, server := range servers {
+		if server.GroupID == groupID {
+			candidates = append(candidates, server)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = servers
+	}
+	if ratio, ok := uniformTrafficRatio(candidates); ok {
+		return ratio
+	}
+	ratio := serverTrafficRatio(candidates[0])
+	for _, server := range candidates[1:] {
+		ratio = math.Min(ratio, serverTrafficRatio(server))
+	}
+	slog.WarnContext(ctx, "traffic node unknown and node ratios differ, charging lowest ratio",
+		"agent_host_id", agentHostID, "user_id", item.UserID, "traffic_ratio", ratio)
+	return ratio
+}
+
+// uniformTrafficRatio 返回节点共同的计费倍率；节点倍率不一致或没有节点时返回 false。
+func uniformTrafficRatio(servers []*repository.Server) (float64, bool) {
+	if len(servers) == 0 {
+		return 0, false
+	}
+	ratio := serverTrafficRatio(servers[0])
+	for _, server := range servers[1:] {
+		if serverTrafficRatio(server) != ratio {
+			return 0, false
+		}
+	}
+	return ratio, true
+}
+
+// applyTrafficRatio records the raw bytes of a delta and scales it to charged bytes.
+// Non-zero raw traffic is never scaled down to zero, see scaleTraffic.
+func applyTrafficRatio(item UserTrafficDelta, ratio float64) UserTrafficDelta {
+	item.RawUpload = item.Upload
+	item.RawDownload = item.Download
+	item.Upload = scaleTraffic(item.Upload, ratio)
+	item.Download = scaleTraffic(item.Download, ratio)
+	return item
+}
+
 // GetTrafficStats returns the current traffic statistics for a user.
 func (s *userTrafficService) GetTrafficStats(ctx context.Context, userID int64) (*repository.UserTrafficStats, error) {
 	return s.trafficRepo.GetUserTrafficStats(ctx, userID)
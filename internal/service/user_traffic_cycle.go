@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+// planCycleMonths 返回重置周期对应的月数，未知周期返回 0。
+func planCycleMonths(cycle string) int {
+	switch cycle {
+	case repository.PlanResetCycleMonthly:
+		return 1
+	case repository.PlanResetCycleQuarterly:
+		return 3
+	case repository.PlanResetCycleYearly:
+		return 12
+	default:
+		return 0
+	}
+}
+
+// ValidatePlanResetCycle 校验套餐的重置周期与重置日。
+func ValidatePlanResetCycle(cycle string, day *int64) error {
+	if cycle == "" {
+		return nil
+	}
+	if planCycleMonths(cycle) == 0 {
+		return fmt.Errorf("%w: unsupported reset_cycle %q / 不支持的重置周期", ErrBadRequest, cycle)
+	}
+	if day != nil && (*day < 1 || *day > 31) {
+		return fmt.Errorf("%w: reset_day must be between 1 and 31 / 重置日必须在 1-31 之间", ErrBadRequest)
+	}
+	return nil
+}
+
+// planCycleBoundary 返回 year/month 中的重置时刻，重置日超过当月天数时取月末（如 2 月的 31 日取 28/29 日）。
+func planCycleBoundary(year int, month time.Month, day int, loc *time.Location) time.Time {
+	lastDay := time.Date(year, month+1, 0, 0, 0, 0, 0, loc).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, loc)
+}
+
+// planCycleBounds 返回不晚于 now 的最近一次重置边界以及下一次边界。
+// 季度与年度周期从 1 月起算（1/4/7/10 月、1 月）。
+func planCycleBounds(cycle string, day int, now time.Time) (time.Time, time.Time, bool) {
+	step := planCycleMonths(cycle)
+	if step == 0 {
+		return time.Time{}, time.Time{}, false
+	}
+	if day < 1 {
+		day = 1
+	}
+	year, month := now.Year(), now.Month()
+	// 对齐到周期起始月
+	month -= time.Month((int(month) - 1) % step)
+	last := planCycleBoundary(year, month, day, now.Location())
+	if last.After(now) {
+		prev := time.Date(year, month-time.Month(step), 1, 0, 0, 0, 0, now.Location())
+		last = planCycleBoundary(prev.Year(), prev.Month(), day, now.Location())
+	}
+	nextMonth := time.Date(last.Year(), last.Month()+time.Month(step), 1, 0, 0, 0, 0, now.Location())
+	next := planCycleBoundary(nextMonth.Year(), nextMonth.Month(), day, now.Location())
+	return last, next, true
+}
+
+// ResetPlanCycles 按套餐的 reset_cycle/reset_day 重置已跨过周期边界的用户流量，返回重置的用户数。
+// 归档、清零与新流量周期在同一事务内完成，因此 ProcessTrafficBatch 的超额判断会从新周期重新累计。
+func (s *userTrafficService) ResetPlanCycles(ctx context.Context) (int, error) {
+	if s == nil || s.plans == nil || s.trafficRepo == nil {
+		return 0, fmt.Errorf("traffic cycle reset not configured / 流量周期重置未配置")
+	}
+	plans, err := s.plans.ListAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	processed := 0
+	for _, plan := range plans {
+		if plan == nil || plan.ResetCycle == "" {
+			continue
+		}
+		day := 1
+		if plan.ResetDay != nil {
+			day = int(*plan.ResetDay)
+		}
+		boundary, next, ok := planCycleBounds(plan.ResetCycle, day, now)
+		if !ok {
+			continue
+		}
+		states, err := s.trafficRepo.ListResetStates(ctx, plan.ID)
+		if err != nil {
+			return processed, err
+		}
+		for _, state := range states {
+			// 只重置起算时间之后跨过的边界：分配套餐或启用周期时视为刚重置过
+			since := max(state.LastResetAt, state.CycleAnchorAt)
+			if since == 0 {
+				since = state.CreatedAt
+			}
+			if since >= boundary.Unix() {
+				continue
+			}
+			// u/d 由仓储在事务内读取归档，这里的快照只用于判断是否到期
+			reset := &repository.UserTrafficReset{
+				UserID:      state.UserID,
+				PlanID:      plan.ID,
+				PeriodStart: since,
+				PeriodEnd:   boundary.Unix(),
+				ResetAt:     now.Unix(),
+			}
+			if err := s.trafficRepo.ResetCycleTraffic(ctx, reset, next.Unix()); err != nil {
+				return processed, fmt.Errorf("reset traffic for user %d: %w", state.UserID, err)
+			}
+			if reset.ID == 0 {
+				// 并发任务已归档过该边界
+				continue
+			}
+			processed++
+		}
+	}
+	return processed, nil
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// trafficMetricsOtherPlan 是未在白名单中的套餐统一使用的标签值，用于控制指标基数。
+const trafficMetricsOtherPlan = "other"
+
+// userPlanCacheTTL 控制用户套餐/分组映射的缓存时间，避免每个批次都逐个查询用户。
+const userPlanCacheTTL = 5 * time.Minute
+
+// userPlanCachePruneSize 是触发过期清理的缓存条目数。
+const userPlanCachePruneSize = 4096
+
+// TrafficMetricsRecorder 记录用户流量写入情况，未启用指标时传 nil 即为空操作。
+type TrafficMetricsRecorder interface {
+	ObserveBatch(success bool)
+	AddUserTraffic(planID, groupID int64, upload, download int64)
+}
+
+// PrometheusTrafficMetrics 按 plan_id/group_id 聚合用户流量，不使用用户 ID 作为标签。
+type PrometheusTrafficMetrics struct {
+	upload   *prometheus.CounterVec
+	download *prometheus.CounterVec
+	batches  *prometheus.CounterVec
+	plans    map[int64]struct{}
+}
+
+// NewPrometheusTrafficMetrics 创建流量指标；planAllowlist 中的套餐单独出标签，其余归入 "other"。
+func NewPrometheusTrafficMetrics(namespace string, planAllowlist []int64) *PrometheusTrafficMetrics {
+	if namespace == "" {
+		namespace = "xboard"
+	}
+	plans := make(map[int64]struct{}, len(planAllowlist))
+	for _, id := range planAllowlist {
+		plans[id] = struct{}{}
+	}
+	return &PrometheusTrafficMetrics{
+		upload: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "user_traffic_upload_bytes",
+			Help:      "User upload bytes accepted from agents, aggregated by plan and group.",
+		}, []string{"plan_id", "group_id"}),
+		download: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "user_traffic_download_bytes",
+			Help:      "User download bytes accepted from agents, aggregated by plan and group.",
+		}, []string{"plan_id", "group_id"}),
+		batches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "traffic_batches_total",
+			Help:      "Traffic batches processed, by result.",
+		}, []string{"result"}),
+		plans: plans,
+	}
+}
+
+// Register 将全部指标注册到给定的 Registerer。
+func (m *PrometheusTrafficMetrics) Register(reg prometheus.Registerer) error {
+	for _, c := range []prometheus.Collector{m.upload, m.download, m.batches} {
+		if err := reg.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ObserveBatch 记录一次批次处理结果。
+func (m *PrometheusTrafficMetrics) ObserveBatch(success bool) {
+	result := "success"
+	if !success {
+		result = "error"
+	}
+	m.batches.WithLabelValues(result).Inc()
+}
+
+// AddUserTraffic 累加一条用户流量。
+func (m *PrometheusTrafficMetrics) AddUserTraffic(planID, groupID int64, upload, download int64) {
+	plan := trafficMetricsOtherPlan
+	if _, ok := m.plans[planID]; ok {
+		plan = strconv.FormatInt(planID, 10)
+	}
+	group := strconv.FormatInt(groupID, 10)
+	if upload > 0 {
+		m.upload.WithLabelValues(plan, group).Add(float64(upload))
+	}
+	if download > 0 {
+		m.download.WithLabelValues(plan, group).Add(float64(download))
+	}
+}
+
+type userPlanEntry struct {
+	planID    int64
+	groupID   int64
+	expiresAt time.Time
+}
+
+// userPlanCache 缓存用户到套餐/分组的映射，仅在启用流量指标时使用。
+type userPlanCache struct {
+	mu      sync.Mutex
+	entries map[int64]userPlanEntry
+}
+
+func newUserPlanCache() *userPlanCache {
+	return &userPlanCache{entries: make(map[int64]userPlanEntry)}
+}
+
+// lookup 返回用户的套餐与分组；查询失败时返回 false，由调用方跳过该条记录。
+func (c *userPlanCache) lookup(ctx context.Context, users repository.UserRepository, userID int64, now time.Time) (int64, int64, bool) {
+	c.mu.Lock()
+	entry, ok := c.entries[userID]
+	c.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.planID, entry.groupID, true
+	}
+	user, err := users.FindByID(ctx, userID)
+	if err != nil || user == nil {
+		return 0, 0, false
+	}
+	c.mu.Lock()
+	// 缓存过大时顺带清理过期项，防止已删除用户一直占用内存
+	if len(c.entries) >= userPlanCachePruneSize {
+		for id, e := range c.entries {
+			if !now.Before(e.expiresAt) {
+				delete(c.entries, id)
+			}
+		}
+	}
+	c.entries[userID] = userPlanEntry{planID: user.PlanID, groupID: user.GroupID, expiresAt: now.Add(userPlanCacheTTL)}
+	c.mu.Unlock()
+	return user.PlanID, user.GroupID, true
+}
+
+// recordTrafficMetrics 将已接收的流量按套餐/分组写入指标。
+func (s *userTrafficService) recordTrafficMetrics(ctx context.Context, accepted []UserTrafficDelta) {
+	if s.metrics == nil {
+		return
+	}
+	now := time.Now()
+	for _, item := range accepted {
+		planID, groupID, ok := s.planCache.lookup(ctx, s.userRepo, item.UserID, now)
+		if !ok {
+			continue
+		}
+		s.metrics.AddUserTraffic(planID, groupID, item.Upload, item.Download)
+	}
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+func TestApplyTrafficRatio(t *testing.T) {
+	charged := applyTrafficRatio(UserTrafficDelta{UserID: 1, Upload: 100, Download: 1000}, 1.5)
+	if charged.Upload != 150 || charged.Download != 1500 || charged.RawUpload != 100 || charged.RawDownload != 1000 {
+		t.Fatalf("charged = %+v, want raw bytes kept and charged bytes scaled by 1.5", charged)
+	}
+
+	charged = applyTrafficRatio(UserTrafficDelta{UserID: 2, Upload: 1, Download: 0}, 0.1)
+	if charged.Upload != 1 || charged.Download != 0 || charged.RawUpload != 1 {
+		t.Fatalf("charged = %+v, want non-zero traffic charged at least one byte", charged)
+	}
+}
+
+func TestTrafficRatioForUsesOriginNode(t *testing.T) {
+	servers := []*repository.Server{
+		{ID: 1, TrafficRatio: 1},
+		{ID: 2, TrafficRatio: 3},
+	}
+	svc := &userTrafficService{}
+	groups := map[int64]int64{}
+
+	if ratio := svc.trafficRatioFor(context.Background(), 1, servers, UserTrafficDelta{UserID: 1, ServerID: 2}, groups); ratio != 3 {
+		t.Fatalf("ratio = %v, want origin node ratio 3", ratio)
+	}
+	if ratio := svc.trafficRatioFor(context.Background(), 1, servers[1:], UserTrafficDelta{UserID: 1}, groups); ratio != 3 {
+		t.Fatalf("ratio = %v, want shared ratio 3 when node is unknown", ratio)
+	}
+	if ratio := svc.trafficRatioFor(context.Background(), 1, nil, UserTrafficDelta{UserID: 1, ServerID: 2}, groups); ratio != 1 {
+		t.Fatalf("ratio = %v, want 1 without nodes", ratio)
+	}
+}
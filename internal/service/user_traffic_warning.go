@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/creamcroissant/xboard/internal/notifier"
+)
+
+// 流量软限额提醒相关系统设置（email 分类）。
+const (
+	trafficWarningPercentSetting     = "traffic_warning_percent"
+	trafficWarningWebhookURLsSetting = "traffic_warning_webhook_urls"
+
+	defaultTrafficWarningPercent = 80
+	trafficWarningEmailTemplate  = "traffic_warning"
+	trafficWarningWebhookEvent   = "user.traffic_warning"
+	trafficWarningWebhookTimeout = 2 * time.Minute
+)
+
+// TrafficWarningPayload 是软限额提醒推送给 webhook 的 JSON 结构，text/content 兼容 Slack 与 Discord。
+type TrafficWarningPayload struct {
+	Event      string `json:"event"`
+	Text       string `json:"text"`
+	Content    string `json:"content"`
+	UserID     int64  `json:"user_id"`
+	Email      string `json:"email"`
+	UsedBytes  int64  `json:"used_bytes"`
+	QuotaBytes int64  `json:"quota_bytes"`
+	Percent    int    `json:"percent"`
+	Timestamp  int64  `json:"timestamp"`
+}
+
+// trafficWarningPercent 读取软限额百分比，0 或 >=100 表示关闭。
+func (s *userTrafficService) trafficWarningPercent(ctx context.Context) int {
+	if s.settings == nil {
+		return 0
+	}
+	percent := defaultTrafficWarningPercent
+	if setting, err := s.settings.Get(ctx, trafficWarningPercentSetting); err == nil && setting != nil {
+		if value, err := strconv.Atoi(strings.TrimSpace(setting.Value)); err == nil {
+			percent = value
+		}
+	}
+	if percent <= 0 || percent >= 100 {
+		return 0
+	}
+	return percent
+}
+
+// sendWarningNotification 在用户首次越过软限额时发送邮件、Telegram 与 webhook 提醒。
+// 是否已提醒由流量周期的 warned 标记保证，每个周期只会调用一次。
+func (s *userTrafficService) sendWarningNotification(ctx context.Context, userID int64) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil || user == nil {
+		return
+	}
+	used := user.U + user.D
+	percent := 0
+	if user.TransferEnable > 0 {
+		percent = int(used * 100 / user.TransferEnable)
+	}
+	text := fmt.Sprintf("You have used %d%% of your traffic quota (%s of %s).", percent, formatReminderBytes(used), formatReminderBytes(user.TransferEnable))
+
+	if s.notificationQueue != nil {
+		if strings.TrimSpace(user.Email) != "" {
+			s.notificationQueue.EnqueueEmail(notifier.EmailRequest{
+				To:       user.Email,
+				Subject:  "Traffic usage warning",
+				Template: trafficWarningEmailTemplate,
+				Body:     text,
+				Variables: map[string]any{
+					"email":           user.Email,
+					"percent":         percent,
+					"used":            formatReminderBytes(used),
+					"transfer_enable": formatReminderBytes(user.TransferEnable),
+				},
+			})
+		}
+		if user.TelegramID != 0 {
+			s.notificationQueue.EnqueueTelegram(notifier.TelegramRequest{
+				ChatID:  strconv.FormatInt(user.TelegramID, 10),
+				Message: "⚠️ " + text,
+			})
+		}
+	}
+
+	urls := s.trafficWarningWebhookURLs(ctx)
+	if len(urls) == 0 {
+		return
+	}
+	payload := TrafficWarningPayload{
+		Event:      trafficWarningWebhookEvent,
+		Text:       fmt.Sprintf("User %s (ID: %d): %s", user.Email, user.ID, text),
+		UserID:     user.ID,
+		Email:      user.Email,
+		UsedBytes:  used,
+		QuotaBytes: user.TransferEnable,
+		Percent:    percent,
+		Timestamp:  time.Now().Unix(),
+	}
+	payload.Content = payload.Text
+	client := notifier.NewWebhookClient(nil)
+	for _, url := range urls {
+		go func(url string) {
+			ctx, cancel := context.WithTimeout(context.Background(), trafficWarningWebhookTimeout)
+			defer cancel()
+			if err := client.PostJSON(ctx, url, payload); err != nil {
+				slog.Warn("traffic warning webhook delivery failed", "user_id", payload.UserID, "error", err)
+			}
+		}(url)
+	}
+}
+
+// trafficWarningWebhookURLs 解析 traffic_warning_webhook_urls，支持换行或逗号分隔。
+func (s *userTrafficService) trafficWarningWebhookURLs(ctx context.Context) []string {
+	if s.settings == nil {
+		return nil
+	}
+	setting, err := s.settings.Get(ctx, trafficWarningWebhookURLsSetting)
+	if err != nil || setting == nil {
+		return nil
+	}
+	fields := strings.FieldsFunc(setting.Value, func(r rune) bool {
+		return r == ',' || r == '\n' || r == '\r'
+	})
+	urls := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if url := strings.TrimSpace(field); url != "" {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
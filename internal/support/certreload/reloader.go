@@ -0,0 +1,129 @@
+// Package certreload 提供 TLS 证书热更新：定期检查证书/私钥文件，变化时重新加载，
+// 通过 tls.Config 的回调返回最新证书，轮换证书无需重启进程。
+package certreload
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultCheckInterval 为证书文件的默认检查间隔。
+const DefaultCheckInterval = 30 * time.Second
+
+// Reloader 持有当前生效的证书，文件变化时重新加载；加载失败时保留上一份证书并记录错误。
+type Reloader struct {
+	certFile string
+	keyFile  string
+	logger   *slog.Logger
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	certMod fileStamp
+	keyMod  fileStamp
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// New 加载初始证书，失败时直接返回错误，避免以无效证书启动。
+func New(certFile, keyFile string, logger *slog.Logger) (*Reloader, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	r := &Reloader{certFile: certFile, keyFile: keyFile, logger: logger}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload 重新读取证书与私钥，失败时不替换当前证书。
+func (r *Reloader) Reload() error {
+	certMod, keyMod, err := r.stat()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("load TLS certificate: %w", err)
+	}
+	r.mu.Lock()
+	r.cert = &cert
+	r.certMod = certMod
+	r.keyMod = keyMod
+	r.mu.Unlock()
+	return nil
+}
+
+// Watch 按 interval 检查文件修改时间与大小，变化时重新加载，ctx 取消后返回。
+// 证书通常以原子替换或符号链接切换的方式更新，因此轮询文件状态而不依赖 inotify 事件。
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultCheckInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			if err := r.Reload(); err != nil {
+				r.logger.Error("TLS certificate reload failed, keeping previous certificate", "cert_file", r.certFile, "key_file", r.keyFile, "error", err)
+				continue
+			}
+			r.logger.Info("TLS certificate reloaded", "cert_file", r.certFile)
+		}
+	}
+}
+
+// GetCertificate 供服务端 tls.Config 使用。
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.current(), nil
+}
+
+// GetClientCertificate 供客户端 tls.Config 在双向 TLS 中使用。
+func (r *Reloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return r.current(), nil
+}
+
+func (r *Reloader) current() *tls.Certificate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert
+}
+
+// changed 判断文件状态是否与上次成功加载时不同。记录的状态只由 Reload 在加载成功后更新，
+// 因此证书与私钥分两次写入导致的加载失败会在下一次检查时重试，而不会被当作已处理。
+func (r *Reloader) changed() bool {
+	certMod, keyMod, err := r.stat()
+	if err != nil {
+		r.logger.Warn("TLS certificate stat failed", "cert_file", r.certFile, "key_file", r.keyFile, "error", err)
+		return false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return certMod != r.certMod || keyMod != r.keyMod
+}
+
+func (r *Reloader) stat() (fileStamp, fileStamp, error) {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return fileStamp{}, fileStamp{}, fmt.Errorf("stat TLS certificate: %w", err)
+	}
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return fileStamp{}, fileStamp{}, fmt.Errorf("stat TLS key: %w", err)
+	}
+	return fileStamp{modTime: certInfo.ModTime(), size: certInfo.Size()}, fileStamp{modTime: keyInfo.ModTime(), size: keyInfo.Size()}, nil
+}
@@ -0,0 +1,92 @@
+package certreload
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeSelfSigned(t *testing.T, certFile, keyFile, commonName string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write cert: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+}
+
+func commonName(t *testing.T, r *Reloader) string {
+	t.Helper()
+	cert, err := r.GetCertificate(nil)
+	if err != nil || cert == nil {
+		t.Fatalf("GetCertificate() = %v, %v", cert, err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	return leaf.Subject.CommonName
+}
+
+func TestReloaderKeepsPreviousCertificateOnBadReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	writeSelfSigned(t, certFile, keyFile, "first")
+
+	r, err := New(certFile, keyFile, nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if got := commonName(t, r); got != "first" {
+		t.Fatalf("initial certificate = %q, want first", got)
+	}
+
+	writeSelfSigned(t, certFile, keyFile, "second")
+	if err := r.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if got := commonName(t, r); got != "second" {
+		t.Fatalf("rotated certificate = %q, want second", got)
+	}
+
+	if err := os.WriteFile(certFile, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("write bad cert: %v", err)
+	}
+	if err := r.Reload(); err == nil {
+		t.Fatal("Reload with bad certificate must fail")
+	}
+	if got := commonName(t, r); got != "second" {
+		t.Fatalf("certificate after failed reload = %q, want second", got)
+	}
+	if !r.changed() {
+		t.Fatal("failed reload must be retried on the next check")
+	}
+}
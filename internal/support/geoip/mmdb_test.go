@@ -0,0 +1,323 @@
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// metadataMarker 标记 mmdb 文件末尾元数据段的起始位置。
+var metadataMarker = []byte("\xAB\xCD\xEFMaxMind.com")
+
+// dataSectionSeparator 是搜索树与数据段之间 16 字节全零分隔区的长度。
+const dataSectionSeparator = 16
+
+// fixtureDB 在测试中按 MaxMind DB 规范生成最小的 mmdb 文件，避免提交二进制夹具。
+type fixtureDB struct {
+	ipVersion  int
+	recordSize int
+	nodes      [][2]int // 子节点下标；fixtureEmpty 表示未收录，<= fixtureData 表示数据记录
+	data       bytes.Buffer
+}
+
+const (
+	fixtureEmpty = -1
+	fixtureData  = -2 // 数据偏移 off 编码为 fixtureData - off
+)
+
+func newFixtureDB(ipVersion, recordSize int) *fixtureDB {
+	return &fixtureDB{ipVersion: ipVersion, recordSize: recordSize, nodes: [][2]int{{fixtureEmpty, fixtureEmpty}}}
+}
+
+// insert 把 CIDR 映射到一段已编码的数据；IPv6 库中的 IPv4 网段放在 ::/96 下。
+func (f *fixtureDB) insert(t *testing.T, cidr string, record []byte) {
+	t.Helper()
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		t.Fatalf("parse cidr %s: %v", cidr, err)
+	}
+	ones, _ := network.Mask.Size()
+	ip := network.IP
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+		if f.ipVersion == 6 {
+			ip = append(make(net.IP, 12), ip4...)
+			ones += 96
+		}
+	}
+	offset := f.data.Len()
+	f.data.Write(record)
+
+	node := 0
+	for i := 0; i < ones; i++ {
+		bit := int(ip[i>>3]>>(7-uint(i&7))) & 1
+		if i == ones-1 {
+			f.nodes[node][bit] = fixtureData - offset
+			return
+		}
+		next := f.nodes[node][bit]
+		if next < 0 {
+			f.nodes = append(f.nodes, [2]int{fixtureEmpty, fixtureEmpty})
+			next = len(f.nodes) - 1
+			f.nodes[node][bit] = next
+		}
+		node = next
+	}
+}
+
+func (f *fixtureDB) bytes(t *testing.T, extraMeta map[string]any) []byte {
+	t.Helper()
+	nodeCount := len(f.nodes)
+	resolve := func(v int) uint64 {
+		switch {
+		case v == fixtureEmpty:
+			return uint64(nodeCount)
+		case v <= fixtureData:
+			return uint64(nodeCount + dataSectionSeparator + (fixtureData - v))
+		default:
+			return uint64(v)
+		}
+	}
+
+	var out bytes.Buffer
+	for _, node := range f.nodes {
+		left, right := resolve(node[0]), resolve(node[1])
+		switch f.recordSize {
+		case 24:
+			out.Write([]byte{byte(left >> 16), byte(left >> 8), byte(left), byte(right >> 16), byte(right >> 8), byte(right)})
+		case 28:
+			out.Write([]byte{byte(left >> 16), byte(left >> 8), byte(left),
+				byte((left>>24)&0x0F)<<4 | byte((right>>24)&0x0F),
+				byte(right >> 16), byte(right >> 8), byte(right)})
+		case 32:
+			var buf [8]byte
+			binary.BigEndian.PutUint32(buf[:4], uint32(left))
+			binary.BigEndian.PutUint32(buf[4:], uint32(right))
+			out.Write(buf[:])
+		default:
+			t.Fatalf("unsupported record size %d", f.recordSize)
+		}
+	}
+	out.Write(make([]byte, dataSectionSeparator))
+	out.Write(f.data.Bytes())
+	out.Write(metadataMarker)
+
+	meta := map[string]any{
+		"node_count":    uint32(nodeCount),
+		"record_size":   uint16(f.recordSize),
+		"ip_version":    uint16(f.ipVersion),
+		"database_type": "Fixture",
+		"languages":     []any{"en"},
+		"description":   map[string]any{"en": "fixture"},
+		"build_epoch":   uint64(1700000000),
+
+		"binary_format_major_version": uint16(2),
+		"binary_format_minor_version": uint16(0),
+	}
+	for k, v := range extraMeta {
+		meta[k] = v
+	}
+	out.Write(encodeValue(t, meta))
+	return out.Bytes()
+}
+
+// encodeValue 按 mmdb 数据段格式编码测试数据。
+func encodeValue(t *testing.T, v any) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	switch val := v.(type) {
+	case string:
+		buf.Write(controlBytes(2, len(val)))
+		buf.WriteString(val)
+	case float64:
+		buf.Write(controlBytes(3, 8))
+		var raw [8]byte
+		binary.BigEndian.PutUint64(raw[:], math.Float64bits(val))
+		buf.Write(raw[:])
+	case []byte:
+		buf.Write(controlBytes(4, len(val)))
+		buf.Write(val)
+	case uint16:
+		raw := trimLeadingZeros(uint64(val))
+		buf.Write(controlBytes(5, len(raw)))
+		buf.Write(raw)
+	case uint32:
+		raw := trimLeadingZeros(uint64(val))
+		buf.Write(controlBytes(6, len(raw)))
+		buf.Write(raw)
+	case uint64:
+		raw := trimLeadingZeros(val)
+		buf.Write(controlBytes(9, len(raw)))
+		buf.Write(raw)
+	case int32:
+		var raw [4]byte
+		binary.BigEndian.PutUint32(raw[:], uint32(val))
+		buf.Write(controlBytes(8, 4))
+		buf.Write(raw[:])
+	case bool:
+		size := 0
+		if val {
+			size = 1
+		}
+		buf.Write(controlBytes(14, size))
+	case float32:
+		buf.Write(controlBytes(15, 4))
+		var raw [4]byte
+		binary.BigEndian.PutUint32(raw[:], math.Float32bits(val))
+		buf.Write(raw[:])
+	case map[string]any:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		buf.Write(controlBytes(7, len(val)))
+		for _, k := range keys {
+			buf.Write(encodeValue(t, k))
+			buf.Write(encodeValue(t, val[k]))
+		}
+	case []any:
+		buf.Write(controlBytes(11, len(val)))
+		for _, item := range val {
+			buf.Write(encodeValue(t, item))
+		}
+	default:
+		t.Fatalf("unsupported fixture value %T", v)
+	}
+	return buf.Bytes()
+}
+
+func controlBytes(typeNum, size int) []byte {
+	var ctrl []byte
+	var ext []byte
+	first := byte(typeNum << 5)
+	if typeNum > 7 {
+		first = 0
+		ext = []byte{byte(typeNum - 7)}
+	}
+	switch {
+	case size < 29:
+		ctrl = []byte{first | byte(size)}
+	case size < 285:
+		ctrl = []byte{first | 29}
+		ext = append(ext, byte(size-29))
+	case size < 65821:
+		ctrl = []byte{first | 30}
+		ext = append(ext, byte((size-285)>>8), byte(size-285))
+	default:
+		ctrl = []byte{first | 31}
+		ext = append(ext, byte((size-65821)>>16), byte((size-65821)>>8), byte(size-65821))
+	}
+	return append(ctrl, ext...)
+}
+
+func trimLeadingZeros(v uint64) []byte {
+	var raw [8]byte
+	binary.BigEndian.PutUint64(raw[:], v)
+	i := 0
+	for i < len(raw) && raw[i] == 0 {
+		i++
+	}
+	return raw[i:]
+}
+
+func fixtureCountry(t *testing.T, code string) []byte {
+	return encodeValue(t, map[string]any{"country": map[string]any{"iso_code": code}})
+}
+
+// TestResolverRecordSizes 覆盖 24/28/32 位记录与纯 IPv4 库。
+func TestResolverRecordSizes(t *testing.T) {
+	for _, size := range []int{24, 28, 32} {
+		db := newFixtureDB(4, size)
+		db.insert(t, "1.2.3.0/24", fixtureCountry(t, "AU"))
+		db.insert(t, "8.8.8.8/32", fixtureCountry(t, "US"))
+		path := filepath.Join(t.TempDir(), "country.mmdb")
+		if err := os.WriteFile(path, db.bytes(t, nil), 0o600); err != nil {
+			t.Fatalf("record size %d: write db: %v", size, err)
+		}
+		resolver, err := NewResolver(path, "")
+		if err != nil {
+			t.Fatalf("record size %d: new resolver: %v", size, err)
+		}
+		for ip, want := range map[string]string{"1.2.3.255": "AU", "8.8.8.8": "US", "8.8.8.9": "", "2001:db8::1": ""} {
+			got, _, err := resolver.ResolveIP(ip)
+			if err != nil || got != want {
+				t.Fatalf("record size %d: resolve %s = (%q, %v), want %q", size, ip, got, err, want)
+			}
+		}
+		resolver.Close()
+	}
+}
+
+func TestResolverResolveIP(t *testing.T) {
+	dir := t.TempDir()
+
+	countryDB := newFixtureDB(6, 24)
+	countryDB.insert(t, "198.51.100.0/24", encodeValue(t, map[string]any{
+		"registered_country": map[string]any{"iso_code": "NL"},
+	}))
+	countryDB.insert(t, "192.0.2.0/24", fixtureCountry(t, "FR"))
+	countryPath := filepath.Join(dir, "country.mmdb")
+	if err := os.WriteFile(countryPath, countryDB.bytes(t, nil), 0o600); err != nil {
+		t.Fatalf("write country db: %v", err)
+	}
+
+	asnDB := newFixtureDB(6, 32)
+	asnDB.insert(t, "192.0.2.0/24", encodeValue(t, map[string]any{
+		"autonomous_system_number":       uint32(64500),
+		"autonomous_system_organization": "Example",
+	}))
+	asnPath := filepath.Join(dir, "asn.mmdb")
+	if err := os.WriteFile(asnPath, asnDB.bytes(t, nil), 0o600); err != nil {
+		t.Fatalf("write asn db: %v", err)
+	}
+
+	resolver, err := NewResolver(countryPath, asnPath)
+	if err != nil {
+		t.Fatalf("new resolver: %v", err)
+	}
+
+	tests := []struct {
+		ip      string
+		country string
+		asn     int64
+	}{
+		{ip: "192.0.2.10", country: "FR", asn: 64500},
+		{ip: "198.51.100.1", country: "NL"}, // 回退到 registered_country
+		{ip: "203.0.113.1"},                 // 未收录返回空值
+	}
+	for _, tt := range tests {
+		country, asn, err := resolver.ResolveIP(tt.ip)
+		if err != nil {
+			t.Fatalf("resolve %s: %v", tt.ip, err)
+		}
+		if country != tt.country || asn != tt.asn {
+			t.Fatalf("resolve %s = (%q, %d), want (%q, %d)", tt.ip, country, asn, tt.country, tt.asn)
+		}
+	}
+	if _, _, err := resolver.ResolveIP("not-an-ip"); err == nil {
+		t.Fatal("expected error for invalid ip")
+	}
+}
+
+func TestNewResolverDisabledWithoutPaths(t *testing.T) {
+	resolver, err := NewResolver(" ", "")
+	if err != nil || resolver != nil {
+		t.Fatalf("NewResolver() = (%v, %v), want (nil, nil)", resolver, err)
+	}
+	if _, err := NewResolver(filepath.Join(t.TempDir(), "missing.mmdb"), ""); err == nil {
+		t.Fatal("expected error for missing database file")
+	}
+	corrupt := filepath.Join(t.TempDir(), "corrupt.mmdb")
+	if err := os.WriteFile(corrupt, []byte("not a database"), 0o600); err != nil {
+		t.Fatalf("write corrupt db: %v", err)
+	}
+	if _, err := NewResolver("", corrupt); err == nil {
+		t.Fatal("expected error for invalid database file")
+	}
+}
@@ -0,0 +1,113 @@
+// Package geoip 提供基于 MaxMind DB (mmdb) 文件的 IP 归属地查询。
+package geoip
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// Resolver 组合国家库与 ASN 库（如 GeoLite2-Country 与 GeoLite2-ASN），任一可为空。
+type Resolver struct {
+	country *maxminddb.Reader
+	asn     *maxminddb.Reader
+}
+
+// countryRecord 只解码国家代码所需的字段。
+type countryRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	RegisteredCountry struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"registered_country"`
+}
+
+type asnRecord struct {
+	Number uint `maxminddb:"autonomous_system_number"`
+}
+
+// NewResolver 打开给定路径的 mmdb 文件；两个路径都为空时返回 nil, nil，表示不启用。
+func NewResolver(countryPath, asnPath string) (*Resolver, error) {
+	countryPath = strings.TrimSpace(countryPath)
+	asnPath = strings.TrimSpace(asnPath)
+	if countryPath == "" && asnPath == "" {
+		return nil, nil
+	}
+	r := &Resolver{}
+	if countryPath != "" {
+		reader, err := maxminddb.Open(countryPath)
+		if err != nil {
+			return nil, fmt.Errorf("geoip: open country database: %w", err)
+		}
+		r.country = reader
+	}
+	if asnPath != "" {
+		reader, err := maxminddb.Open(asnPath)
+		if err != nil {
+			r.Close()
+			return nil, fmt.Errorf("geoip: open asn database: %w", err)
+		}
+		r.asn = reader
+	}
+	return r, nil
+}
+
+// ResolveIP 返回 ISO 3166 国家代码与 ASN；未收录的地址返回空值而非错误。
+func (r *Resolver) ResolveIP(ip string) (string, int64, error) {
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if parsed == nil {
+		return "", 0, fmt.Errorf("geoip: invalid ip %q", ip)
+	}
+
+	var country string
+	var asn int64
+	if r.country != nil {
+		var record countryRecord
+		if err := lookup(r.country, parsed, &record); err != nil {
+			return "", 0, fmt.Errorf("geoip: country lookup: %w", err)
+		}
+		country = record.code()
+	}
+	if r.asn != nil {
+		var record asnRecord
+		if err := lookup(r.asn, parsed, &record); err != nil {
+			return country, 0, fmt.Errorf("geoip: asn lookup: %w", err)
+		}
+		asn = int64(record.Number)
+	}
+	return country, asn, nil
+}
+
+// lookup 在纯 IPv4 库中查询 IPv6 地址时按未收录处理，与 IPv6 库的行为一致。
+func lookup(reader *maxminddb.Reader, ip net.IP, result any) error {
+	if reader.Metadata.IPVersion == 4 && ip.To4() == nil {
+		return nil
+	}
+	return reader.Lookup(ip, result)
+}
+
+// Close 释放已打开的数据库文件。
+func (r *Resolver) Close() error {
+	if r == nil {
+		return nil
+	}
+	var errs []error
+	for _, reader := range []*maxminddb.Reader{r.country, r.asn} {
+		if reader != nil {
+			errs = append(errs, reader.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// code 读取 country.iso_code，缺失时回退到 registered_country。
+func (c countryRecord) code() string {
+	if c.Country.ISOCode != "" {
+		return c.Country.ISOCode
+	}
+	return c.RegisteredCountry.ISOCode
+}
@@ -0,0 +1,43 @@
+// 文件路径: internal/support/hash/config.go
+// 模块说明: 这是 internal 模块里的 config 逻辑，下面的注释会用非常通俗的中文帮你理解每一步。
+package hash
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// ConfigContentHash 计算核心配置的漂移比对哈希。
+// JSON 配置会先去掉入站里由 Agent 注入的用户列表（sing-box users / Xray settings.clients）并按键排序重新序列化，
+// 这样面板生成的配置与 Agent 注入用户后写盘的文件得到同一个哈希；非 JSON 内容直接对原始字节求 MD5。
+func ConfigContentHash(content []byte) string {
+	var config any
+	if err := json.Unmarshal(content, &config); err == nil {
+		stripInjectedUsers(config)
+		if canonical, err := json.Marshal(config); err == nil {
+			content = canonical
+		}
+	}
+	sum := md5.Sum(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// stripInjectedUsers 删除入站中随用户同步变化的字段，只保留结构性配置参与比对。
+func stripInjectedUsers(config any) {
+	root, ok := config.(map[string]any)
+	if !ok {
+		return
+	}
+	inbounds, _ := root["inbounds"].([]any)
+	for _, inbound := range inbounds {
+		inboundMap, ok := inbound.(map[string]any)
+		if !ok {
+			continue
+		}
+		delete(inboundMap, "users")
+		if settings, ok := inboundMap["settings"].(map[string]any); ok {
+			delete(settings, "clients")
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package hash
+
+import "testing"
+
+func TestConfigContentHashIgnoresInjectedUsers(t *testing.T) {
+	generated := []byte(`{
+  "inbounds": [{"type": "vless", "tag": "vless-in", "listen_port": 443, "users": [{"name": "a", "uuid": "1"}]}],
+  "outbounds": [{"type": "direct"}]
+}`)
+	injected := []byte(`{"outbounds":[{"type":"direct"}],"inbounds":[{"listen_port":443,"tag":"vless-in","type":"vless","users":[{"flow":"xtls-rprx-vision","name":"a","uuid":"1"},{"name":"b","uuid":"2"}]}]}`)
+	edited := []byte(`{"outbounds":[{"type":"direct"}],"inbounds":[{"listen_port":8443,"tag":"vless-in","type":"vless"}]}`)
+
+	if ConfigContentHash(generated) != ConfigContentHash(injected) {
+		t.Fatal("user injection and formatting must not change the drift hash")
+	}
+	if ConfigContentHash(generated) == ConfigContentHash(edited) {
+		t.Fatal("structural edits must change the drift hash")
+	}
+	if ConfigContentHash([]byte("not json")) == "" {
+		t.Fatal("non-JSON content should still hash")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 
@@ -28,8 +29,16 @@ var embeddedLocales embed.FS
 type Manager struct {
 	defaultLang  string
 	translations map[string]map[string]string
+	fallbacks    map[string][]string
 	logger       *slog.Logger
 	mu           sync.RWMutex
+
+	reportMissing bool
+	reported      sync.Map // "lang\x00key" -> struct{}，缺失翻译只记录一次
+
+	// matcher 按已加载语言构建，加载新的语言包后置空并在下次匹配时重建
+	matcher      language.Matcher
+	matcherLangs []string
 }
 
 // Option 用于配置 Manager。
@@ -45,7 +54,35 @@ func WithLogger(logger *slog.Logger) Option {
 // WithDefaultLang 设置默认语言。
 func WithDefaultLang(lang string) Option {
 	return func(m *Manager) {
-		m.defaultLang = lang
+		if lang = normalizeLang(lang); lang != "" {
+			m.defaultLang = lang
+		}
+	}
+}
+
+// WithFallbacks 设置语言回退链，例如 {"zh-TW": {"zh-CN"}}：zh-TW 缺少的键依次从 zh-CN、默认语言查找。
+// 语言标签会被规范化，因此配置文件中被转成小写的键（zh-tw）同样生效。
+func WithFallbacks(fallbacks map[string][]string) Option {
+	return func(m *Manager) {
+		m.fallbacks = make(map[string][]string, len(fallbacks))
+		for lang, chain := range fallbacks {
+			normalized := make([]string, 0, len(chain))
+			for _, next := range chain {
+				if next = normalizeLang(next); next != "" {
+					normalized = append(normalized, next)
+				}
+			}
+			if lang = normalizeLang(lang); lang != "" {
+				m.fallbacks[lang] = normalized
+			}
+		}
+	}
+}
+
+// WithMissingKeyReport 开启后，已加载的语言缺少某个键时记录一条告警（每个语言与键组合只记录一次），便于补全翻译。
+func WithMissingKeyReport(enabled bool) Option {
+	return func(m *Manager) {
+		m.reportMissing = enabled
 	}
 }
 
@@ -92,6 +129,7 @@ func (m *Manager) loadEmbeddedTranslations() error {
 
 		m.mu.Lock()
 		m.translations[lang] = content
+		m.matcher = nil
 		m.mu.Unlock()
 	}
 
@@ -134,46 +172,206 @@ func (m *Manager) LoadFromDir(dir string) error {
 		for k, v := range content {
 			m.translations[lang][k] = v
 		}
+		m.matcher = nil
 		m.mu.Unlock()
 	}
 	return nil
 }
 
 // Translate 按语言与键名返回翻译内容。
+// 依次查找请求语言、其回退链与默认语言；均缺失时，形如 "a.b_c" 的键返回可读文本（"B c"），
+// 其他内容（如动态错误信息）原样返回。
 func (m *Manager) Translate(lang, key string, args ...interface{}) string {
+	if msg, ok := m.Lookup(lang, key, args...); ok {
+		return msg
+	}
+	return humanizeKey(key)
+}
+
+// Lookup 与 Translate 使用相同的回退链，但未找到翻译时返回 false，供调用方使用自己的兜底文案。
+func (m *Manager) Lookup(lang, key string, args ...interface{}) (string, bool) {
+	val, ok := m.find(lang, key, func(string) []string { return []string{key} })
+	if !ok {
+		return "", false
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(val, args...), true
+	}
+	return val, true
+}
+
+// TranslatePlural 按 CLDR 复数类别选择 "<key>_<类别>" 形式的翻译（与前端 i18next 的后缀约定一致），
+// 依次尝试对应类别、"_other" 与不带后缀的键；类别按实际命中的语言计算。
+// 未传 args 时以 count 作为唯一的格式化参数。
+func (m *Manager) TranslatePlural(lang, key string, count int64, args ...interface{}) string {
+	if len(args) == 0 {
+		args = []interface{}{count}
+	}
+	val, ok := m.find(lang, key, func(candidate string) []string {
+		category := PluralCategoryOf(candidate, count)
+		keys := []string{key + "_" + string(category)}
+		if category != PluralOther {
+			keys = append(keys, key+"_"+string(PluralOther))
+		}
+		return append(keys, key)
+	})
+	if !ok {
+		return humanizeKey(key)
+	}
+	return fmt.Sprintf(val, args...)
+}
+
+// find 沿回退链查找翻译，candidates 返回在某个语言中依次尝试的键名。
+func (m *Manager) find(lang, key string, candidates func(lang string) []string) (string, bool) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
 	// 规范化语言标签
-	tag, err := language.Parse(lang)
-	if err == nil {
-		lang = tag.String()
+	if normalized := normalizeLang(lang); normalized != "" {
+		lang = normalized
 	}
 
-	// 先尝试精确匹配
-	if trans, ok := m.translations[lang]; ok {
-		if val, ok := trans[key]; ok {
-			if len(args) > 0 {
-				return fmt.Sprintf(val, args...)
+	for i, candidate := range m.chainLocked(lang) {
+		trans, ok := m.translations[candidate]
+		if !ok {
+			continue
+		}
+		for _, k := range candidates(candidate) {
+			val, ok := trans[k]
+			if !ok {
+				continue
 			}
-			return val
+			if i > 0 {
+				m.reportMissingLocked(lang, key)
+			}
+			return val, true
 		}
 	}
+	m.reportMissingLocked(lang, key)
+	return "", false
+}
 
-	// 回退到默认语言
-	if lang != m.defaultLang {
-		if trans, ok := m.translations[m.defaultLang]; ok {
-			if val, ok := trans[key]; ok {
-				if len(args) > 0 {
-					return fmt.Sprintf(val, args...)
-				}
-				return val
+// chainLocked 返回查找顺序：请求语言、配置的回退语言、默认语言（去重）。
+func (m *Manager) chainLocked(lang string) []string {
+	chain := make([]string, 0, 2+len(m.fallbacks[lang]))
+	seen := make(map[string]struct{}, cap(chain))
+	for _, candidate := range append(append([]string{lang}, m.fallbacks[lang]...), m.defaultLang) {
+		if _, ok := seen[candidate]; ok {
+			continue
+		}
+		seen[candidate] = struct{}{}
+		chain = append(chain, candidate)
+	}
+	return chain
+}
+
+// reportMissingLocked 仅针对已加载的语言与形如键名的字符串记录缺失，避免未支持的语言或动态文本刷屏。
+func (m *Manager) reportMissingLocked(lang, key string) {
+	if !m.reportMissing || !looksLikeKey(key) {
+		return
+	}
+	if _, ok := m.translations[lang]; !ok {
+		return
+	}
+	if _, loaded := m.reported.LoadOrStore(lang+"\x00"+key, struct{}{}); loaded {
+		return
+	}
+	m.logger.Warn("i18n translation missing", "lang", lang, "key", key)
+}
+
+func normalizeLang(lang string) string {
+	lang = strings.TrimSpace(lang)
+	if lang == "" {
+		return ""
+	}
+	if tag, err := language.Parse(lang); err == nil {
+		return tag.String()
+	}
+	return lang
+}
+
+// looksLikeKey 判断字符串是否为点分隔的翻译键（如 error.bad_request）。
+func looksLikeKey(s string) bool {
+	if !strings.Contains(s, ".") {
+		return false
+	}
+	for _, part := range strings.Split(s, ".") {
+		if part == "" {
+			return false
+		}
+		for _, r := range part {
+			if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+				return false
 			}
 		}
 	}
+	return true
+}
 
-	// 回退为原始 key
-	return key
+// humanizeKey 把缺失翻译的键转换为可读文本，取最后一段并把下划线换成空格，避免向用户暴露原始键名。
+func humanizeKey(key string) string {
+	if !looksLikeKey(key) {
+		return key
+	}
+	last := key[strings.LastIndex(key, ".")+1:]
+	text := strings.TrimSpace(strings.NewReplacer("_", " ", "-", " ").Replace(last))
+	if text == "" {
+		return key
+	}
+	return strings.ToUpper(text[:1]) + text[1:]
+}
+
+// MatchAcceptLanguage 解析 Accept-Language（含 q 权重），返回已加载语言中最合适的一个；
+// 无法解析或没有可接受的匹配时返回默认语言。
+func (m *Manager) MatchAcceptLanguage(header string) string {
+	tags, _, err := language.ParseAcceptLanguage(header)
+	if err != nil || len(tags) == 0 {
+		return m.defaultLang
+	}
+	matcher, langs := m.languageMatcher()
+	if len(langs) == 0 {
+		return m.defaultLang
+	}
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return m.defaultLang
+	}
+	return langs[index]
+}
+
+func (m *Manager) languageMatcher() (language.Matcher, []string) {
+	m.mu.RLock()
+	matcher, langs := m.matcher, m.matcherLangs
+	m.mu.RUnlock()
+	if matcher != nil {
+		return matcher, langs
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.matcher != nil {
+		return m.matcher, m.matcherLangs
+	}
+	// 默认语言排在首位，作为 Matcher 的兜底项
+	langs = make([]string, 0, len(m.translations))
+	if _, ok := m.translations[m.defaultLang]; ok {
+		langs = append(langs, m.defaultLang)
+	}
+	others := make([]string, 0, len(m.translations))
+	for lang := range m.translations {
+		if lang != m.defaultLang {
+			others = append(others, lang)
+		}
+	}
+	sort.Strings(others)
+	langs = append(langs, others...)
+	tags := make([]language.Tag, len(langs))
+	for i, lang := range langs {
+		tags[i] = language.Make(lang)
+	}
+	m.matcher = language.NewMatcher(tags)
+	m.matcherLangs = langs
+	return m.matcher, m.matcherLangs
 }
 
 // GetSupportedLanguages 返回支持的语言列表。
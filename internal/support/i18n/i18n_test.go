@@ -0,0 +1,133 @@
+package i18n
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func newTestManager(t *testing.T, opts ...Option) *Manager {
+	t.Helper()
+	m, err := NewManager(opts...)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	m.translations = map[string]map[string]string{
+		"en-US": {"greeting.hello": "Hello", "greeting.only_en": "English only"},
+		"zh-CN": {"greeting.hello": "你好", "greeting.only_cn": "仅中文"},
+		"zh-TW": {"greeting.hello": "妳好"},
+	}
+	return m
+}
+
+func TestTranslateFallbackChain(t *testing.T) {
+	var logs bytes.Buffer
+	m := newTestManager(t,
+		WithLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+		WithFallbacks(map[string][]string{"zh-tw": {"zh-cn"}}),
+		WithMissingKeyReport(true),
+	)
+
+	cases := []struct{ lang, key, want string }{
+		{"zh-TW", "greeting.hello", "妳好"},
+		{"zh-TW", "greeting.only_cn", "仅中文"},
+		{"zh-TW", "greeting.only_en", "English only"},
+		{"fr", "greeting.hello", "Hello"},
+		{"zh-TW", "error.too_many_requests", "Too many requests"},
+		{"zh-TW", "upstream failed: dial tcp", "upstream failed: dial tcp"},
+	}
+	for _, tc := range cases {
+		if got := m.Translate(tc.lang, tc.key); got != tc.want {
+			t.Errorf("Translate(%q, %q) = %q, want %q", tc.lang, tc.key, got, tc.want)
+		}
+	}
+	if _, ok := m.Lookup("zh-TW", "error.too_many_requests"); ok {
+		t.Error("Lookup of a missing key must report not found")
+	}
+
+	m.Translate("zh-TW", "greeting.only_cn")
+	if got := strings.Count(logs.String(), "key=greeting.only_cn"); got != 1 {
+		t.Errorf("missing key reported %d times, want 1\n%s", got, logs.String())
+	}
+	if strings.Contains(logs.String(), "lang=fr") {
+		t.Errorf("unloaded language must not be reported\n%s", logs.String())
+	}
+}
+
+func TestTranslatePlural(t *testing.T) {
+	m := newTestManager(t)
+	m.translations["en-US"]["days.left_one"] = "%d day left"
+	m.translations["en-US"]["days.left_other"] = "%d days left"
+	m.translations["ru"] = map[string]string{
+		"days.left_one":  "остался %d день",
+		"days.left_few":  "осталось %d дня",
+		"days.left_many": "осталось %d дней",
+	}
+	m.translations["zh-CN"]["days.left_other"] = "剩余 %d 天"
+
+	cases := []struct {
+		lang  string
+		count int64
+		want  string
+	}{
+		{"en-US", 1, "1 day left"},
+		{"en-US", 0, "0 days left"},
+		{"en-US", 5, "5 days left"},
+		{"ru", 1, "остался 1 день"},
+		{"ru", 3, "осталось 3 дня"},
+		{"ru", 5, "осталось 5 дней"},
+		{"ru", 11, "осталось 11 дней"},
+		{"ru", 21, "остался 21 день"},
+		{"ru", 22, "осталось 22 дня"},
+		{"zh-CN", 1, "剩余 1 天"},
+		{"ja", 1, "1 day left"},
+	}
+	for _, tc := range cases {
+		if got := m.TranslatePlural(tc.lang, "days.left", tc.count); got != tc.want {
+			t.Errorf("TranslatePlural(%q, %d) = %q, want %q", tc.lang, tc.count, got, tc.want)
+		}
+	}
+}
+
+func TestPluralCategoryOf(t *testing.T) {
+	cases := []struct {
+		lang  string
+		count int64
+		want  PluralCategory
+	}{
+		{"en-US", 1, PluralOne},
+		{"en", 2, PluralOther},
+		{"fr-FR", 0, PluralOne},
+		{"pl", 12, PluralMany},
+		{"pl", 24, PluralFew},
+		{"ar", 2, PluralTwo},
+		{"ar", 102, PluralOther},
+		{"zh-CN", 1, PluralOther},
+	}
+	for _, tc := range cases {
+		if got := PluralCategoryOf(tc.lang, tc.count); got != tc.want {
+			t.Errorf("PluralCategoryOf(%q, %d) = %q, want %q", tc.lang, tc.count, got, tc.want)
+		}
+	}
+}
+
+func TestMatchAcceptLanguage(t *testing.T) {
+	m := newTestManager(t)
+	m.translations["fr"] = nil
+
+	cases := map[string]string{
+		"zh-CN,zh;q=0.9,en;q=0.8":      "zh-CN",
+		"de;q=1.0, fr;q=0.9, en;q=0.5": "fr",
+		"en-GB,en;q=0.9":               "en-US",
+		"ja;q=0.9, en-US;q=0.1":        "en-US",
+		"ja, ko":                       "en-US",
+		"zh;q=0.1, fr;q=0":             "zh-CN",
+		";;;":                          "en-US",
+	}
+	for header, want := range cases {
+		if got := m.MatchAcceptLanguage(header); got != want {
+			t.Errorf("MatchAcceptLanguage(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package i18n
+
+import "strings"
+
+// PluralCategory 为 CLDR 复数类别。
+type PluralCategory string
+
+const (
+	PluralZero  PluralCategory = "zero"
+	PluralOne   PluralCategory = "one"
+	PluralTwo   PluralCategory = "two"
+	PluralFew   PluralCategory = "few"
+	PluralMany  PluralCategory = "many"
+	PluralOther PluralCategory = "other"
+)
+
+// pluralRules 按基础语言给出整数的 CLDR 复数规则，未列出的语言一律使用 other。
+var pluralRules = map[string]func(n int64) PluralCategory{
+	"en": pluralOneOther,
+	"de": pluralOneOther,
+	"nl": pluralOneOther,
+	"sv": pluralOneOther,
+	"da": pluralOneOther,
+	"no": pluralOneOther,
+	"nb": pluralOneOther,
+	"fi": pluralOneOther,
+	"it": pluralOneOther,
+	"es": pluralOneOther,
+	"el": pluralOneOther,
+	"hu": pluralOneOther,
+	"tr": pluralOneOther,
+	"fa": pluralZeroOneOther,
+	"hi": pluralZeroOneOther,
+	"fr": pluralZeroOneOther,
+	"pt": pluralZeroOneOther,
+	"ru": pluralEastSlavic,
+	"uk": pluralEastSlavic,
+	"be": pluralEastSlavic,
+	"pl": pluralPolish,
+	"cs": pluralCzech,
+	"sk": pluralCzech,
+	"ar": pluralArabic,
+}
+
+// PluralCategoryOf 返回 count 在指定语言中的复数类别；没有复数数据的语言（如中文、日文）返回 other。
+func PluralCategoryOf(lang string, count int64) PluralCategory {
+	base := strings.ToLower(lang)
+	if i := strings.IndexAny(base, "-_"); i >= 0 {
+		base = base[:i]
+	}
+	rule, ok := pluralRules[base]
+	if !ok {
+		return PluralOther
+	}
+	if count < 0 {
+		count = -count
+	}
+	return rule(count)
+}
+
+func pluralOneOther(n int64) PluralCategory {
+	if n == 1 {
+		return PluralOne
+	}
+	return PluralOther
+}
+
+// pluralZeroOneOther 适用于 0 和 1 都取 one 的语言（法语、葡萄牙语等）。
+func pluralZeroOneOther(n int64) PluralCategory {
+	if n <= 1 {
+		return PluralOne
+	}
+	return PluralOther
+}
+
+func pluralEastSlavic(n int64) PluralCategory {
+	mod10, mod100 := n%10, n%100
+	switch {
+	case mod10 == 1 && mod100 != 11:
+		return PluralOne
+	case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+		return PluralFew
+	default:
+		return PluralMany
+	}
+}
+
+func pluralPolish(n int64) PluralCategory {
+	mod10, mod100 := n%10, n%100
+	switch {
+	case n == 1:
+		return PluralOne
+	case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+		return PluralFew
+	default:
+		return PluralMany
+	}
+}
+
+func pluralCzech(n int64) PluralCategory {
+	switch {
+	case n == 1:
+		return PluralOne
+	case n >= 2 && n <= 4:
+		return PluralFew
+	default:
+		return PluralOther
+	}
+}
+
+func pluralArabic(n int64) PluralCategory {
+	mod100 := n % 100
+	switch {
+	case n == 0:
+		return PluralZero
+	case n == 1:
+		return PluralOne
+	case n == 2:
+		return PluralTwo
+	case mod100 >= 3 && mod100 <= 10:
+		return PluralFew
+	case mod100 >= 11:
+		return PluralMany
+	default:
+		return PluralOther
+	}
+}
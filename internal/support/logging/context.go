@@ -0,0 +1,39 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+// requestIDHandler adds the chi request id carried by ctx to every record logged with a context
+// (slog.InfoContext, Logger.LogAttrs, ...), unless the record already sets request_id itself.
+type requestIDHandler struct {
+	slog.Handler
+}
+
+func (h requestIDHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := chiMiddleware.GetReqID(ctx); requestID != "" && !hasRequestID(record) {
+		record = record.Clone()
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h requestIDHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return requestIDHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h requestIDHandler) WithGroup(name string) slog.Handler {
+	return requestIDHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+func hasRequestID(record slog.Record) bool {
+	found := false
+	record.Attrs(func(attr slog.Attr) bool {
+		found = attr.Key == "request_id"
+		return !found
+	})
+	return found
+}
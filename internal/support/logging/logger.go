@@ -24,6 +24,7 @@ type Options struct {
 
 // New returns a slog.Logger configured according to options.
 // When LogDir is set, logs are written to both stdout and daily-rotated files.
+// Records logged with a request context carry its request_id.
 func New(opts Options) *slog.Logger {
 	handlerOpts := &slog.HandlerOptions{Level: opts.Level, AddSource: opts.AddSource}
 
@@ -42,7 +43,7 @@ func New(opts Options) *slog.Logger {
 		handler = slog.NewJSONHandler(writer, handlerOpts)
 	}
 
-	return slog.New(handler)
+	return slog.New(requestIDHandler{Handler: handler})
 }
 
 // dailyWriter implements io.Writer with daily rotation.
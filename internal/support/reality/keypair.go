@@ -0,0 +1,94 @@
+// Package reality 生成 REALITY 所需的 x25519 密钥对与 short id。
+package reality
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+)
+
+const (
+	// DefaultShortIDCount 是未指定数量时生成的 short id 个数。
+	DefaultShortIDCount = 4
+	// MaxShortIDCount 限制单次生成的 short id 个数。
+	MaxShortIDCount = 16
+	// shortIDBytes 是每个 short id 的字节数，编码为 16 位十六进制（REALITY 允许的最大长度）。
+	shortIDBytes = 8
+)
+
+// KeyPair 是一组 REALITY 服务端参数。密钥使用无填充 URL-safe Base64 编码，
+// 与 xray x25519 / sing-box generate reality-keypair 的输出格式一致。
+type KeyPair struct {
+	PrivateKey string   `json:"private_key"`
+	PublicKey  string   `json:"public_key"`
+	ShortIDs   []string `json:"short_ids"`
+}
+
+// Generator 从给定随机源生成密钥，测试时可注入确定性的 io.Reader。
+type Generator struct {
+	rand io.Reader
+}
+
+// NewGenerator 创建生成器；r 为 nil 时使用 crypto/rand。
+func NewGenerator(r io.Reader) *Generator {
+	if r == nil {
+		r = rand.Reader
+	}
+	return &Generator{rand: r}
+}
+
+// Generate 使用 crypto/rand 生成密钥对与 shortIDCount 个 short id。
+func Generate(shortIDCount int) (*KeyPair, error) {
+	return NewGenerator(nil).Generate(shortIDCount)
+}
+
+// Generate 生成密钥对与 shortIDCount 个 short id；shortIDCount <= 0 时使用默认数量。
+func (g *Generator) Generate(shortIDCount int) (*KeyPair, error) {
+	if shortIDCount <= 0 {
+		shortIDCount = DefaultShortIDCount
+	}
+	if shortIDCount > MaxShortIDCount {
+		return nil, fmt.Errorf("reality: short id count %d exceeds %d", shortIDCount, MaxShortIDCount)
+	}
+
+	privateKey, publicKey, err := g.KeyPair()
+	if err != nil {
+		return nil, err
+	}
+	shortIDs, err := g.ShortIDs(shortIDCount)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyPair{PrivateKey: privateKey, PublicKey: publicKey, ShortIDs: shortIDs}, nil
+}
+
+// KeyPair 生成编码后的 x25519 私钥与公钥。
+// 私钥字节直接从随机源读取再交给 ecdh（内部完成 clamp），因此相同输入得到相同输出。
+func (g *Generator) KeyPair() (string, string, error) {
+	seed := make([]byte, 32)
+	if _, err := io.ReadFull(g.rand, seed); err != nil {
+		return "", "", fmt.Errorf("reality: read random: %w", err)
+	}
+	key, err := ecdh.X25519().NewPrivateKey(seed)
+	if err != nil {
+		return "", "", fmt.Errorf("reality: build x25519 key: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(key.Bytes()),
+		base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()), nil
+}
+
+// ShortIDs 生成 count 个十六进制 short id。
+func (g *Generator) ShortIDs(count int) ([]string, error) {
+	ids := make([]string, 0, count)
+	buf := make([]byte, shortIDBytes)
+	for i := 0; i < count; i++ {
+		if _, err := io.ReadFull(g.rand, buf); err != nil {
+			return nil, fmt.Errorf("reality: read random: %w", err)
+		}
+		ids = append(ids, hex.EncodeToString(buf))
+	}
+	return ids, nil
+}
@@ -0,0 +1,86 @@
+package reality
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// RFC 7748 第 6.1 节 Alice 的 x25519 测试向量。
+const (
+	rfc7748PrivateKey = "77076d0a7318a57d3c16c17251b26645df4c2f87ebc0992ab177fba51db92c2a"
+	rfc7748PublicKey  = "8520f0098930a754748b7ddcb43ef75a0dbf3a0d26381af4eba4a98eaa9b4e6a"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("decode hex: %v", err)
+	}
+	return b
+}
+
+func TestKeyPairDerivesPublicKeyFromFixedPrivateKey(t *testing.T) {
+	g := NewGenerator(bytes.NewReader(mustHex(t, rfc7748PrivateKey)))
+	privateKey, publicKey, err := g.KeyPair()
+	if err != nil {
+		t.Fatalf("KeyPair() error = %v", err)
+	}
+	if want := base64.RawURLEncoding.EncodeToString(mustHex(t, rfc7748PrivateKey)); privateKey != want {
+		t.Fatalf("private key = %q, want %q", privateKey, want)
+	}
+	if want := base64.RawURLEncoding.EncodeToString(mustHex(t, rfc7748PublicKey)); publicKey != want {
+		t.Fatalf("public key = %q, want %q", publicKey, want)
+	}
+}
+
+func TestGenerateIsDeterministicWithInjectedRandom(t *testing.T) {
+	source := append(mustHex(t, rfc7748PrivateKey), bytes.Repeat([]byte{0xab}, 2*shortIDBytes)...)
+	pair, err := NewGenerator(bytes.NewReader(source)).Generate(2)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if want := base64.RawURLEncoding.EncodeToString(mustHex(t, rfc7748PublicKey)); pair.PublicKey != want {
+		t.Fatalf("public key = %q, want %q", pair.PublicKey, want)
+	}
+	wantID := strings.Repeat("ab", shortIDBytes)
+	if len(pair.ShortIDs) != 2 || pair.ShortIDs[0] != wantID || pair.ShortIDs[1] != wantID {
+		t.Fatalf("short ids = %v, want two %q", pair.ShortIDs, wantID)
+	}
+}
+
+func TestGenerateDefaultsAndLimits(t *testing.T) {
+	pair, err := Generate(0)
+	if err != nil {
+		t.Fatalf("Generate(0) error = %v", err)
+	}
+	if len(pair.ShortIDs) != DefaultShortIDCount {
+		t.Fatalf("short id count = %d, want %d", len(pair.ShortIDs), DefaultShortIDCount)
+	}
+	shortID := regexp.MustCompile(`^[0-9a-f]{16}$`)
+	for _, id := range pair.ShortIDs {
+		if !shortID.MatchString(id) {
+			t.Fatalf("short id %q is not 16 hex chars", id)
+		}
+	}
+	for _, key := range []string{pair.PrivateKey, pair.PublicKey} {
+		raw, err := base64.RawURLEncoding.DecodeString(key)
+		if err != nil || len(raw) != 32 {
+			t.Fatalf("key %q is not 32-byte raw url base64: %v", key, err)
+		}
+	}
+
+	if _, err := Generate(MaxShortIDCount + 1); err == nil {
+		t.Fatal("expected error when short id count exceeds limit")
+	}
+}
+
+func TestGenerateFailsOnShortRandom(t *testing.T) {
+	if _, err := NewGenerator(bytes.NewReader(make([]byte, 16))).Generate(1); err == nil {
+		t.Fatal("expected error when random source is exhausted")
+	}
+}
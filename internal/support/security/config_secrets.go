@@ -0,0 +1,36 @@
+package security
+
+import "strings"
+
+// secretConfigKeys 列出核心配置中需要脱敏的字段（小写比较），覆盖 sing-box 与 xray 的用户凭据、密钥与令牌。
+// Agent 本地接口的脱敏与面板的配置预览共用这一份列表，新增字段只需改这里。
+var secretConfigKeys = map[string]struct{}{
+	"password":       {},
+	"passwd":         {},
+	"obfs_password":  {},
+	"uuid":           {},
+	"id":             {}, // xray clients[].id 即用户 UUID
+	"private_key":    {},
+	"privatekey":     {},
+	"psk":            {},
+	"pre_shared_key": {},
+	"presharedkey":   {},
+	"secret":         {},
+	"token":          {},
+	"access_token":   {},
+	"auth":           {},
+	"auth_str":       {},
+	"key":            {},
+	"api_key":        {},
+	"server_key":     {},
+	"short_id":       {},
+	"shortids":       {},
+	"short_ids":      {},
+}
+
+// IsSecretConfigKey 判断配置字段名是否承载凭据或密钥，大小写不敏感。
+// 调用方应只替换字符串及字符串数组，数值类型的同名字段（如路由规则的数值 id）保持原样。
+func IsSecretConfigKey(key string) bool {
+	_, ok := secretConfigKeys[strings.ToLower(key)]
+	return ok
+}
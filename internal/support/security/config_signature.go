@@ -0,0 +1,55 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ConfigSignatureScopeConfig 标记 GetConfig 下发的节点配置。
+	ConfigSignatureScopeConfig = "config"
+	// ConfigSignatureScopeCoreOperation 标记 core operation 的请求载荷。
+	ConfigSignatureScopeCoreOperation = "core_operation"
+)
+
+// ConfigSignatureMessage 拼出签名覆盖的完整消息：作用域、节点 ID、版本字段（ETag、版本号或操作 ID 等）与内容。
+// 每段都带长度前缀，内容相同但节点或版本不同的消息签名也不同，旧配置与其他节点的配置无法原样重放。
+func ConfigSignatureMessage(scope string, agentHostID int64, payload []byte, fields ...string) []byte {
+	var b strings.Builder
+	b.WriteString("xboard-config-signature-v2\n")
+	writeField := func(value string) {
+		b.WriteString(strconv.Itoa(len(value)))
+		b.WriteByte(':')
+		b.WriteString(value)
+		b.WriteByte('\n')
+	}
+	writeField(scope)
+	writeField(strconv.FormatInt(agentHostID, 10))
+	for _, field := range fields {
+		writeField(field)
+	}
+	writeField(string(payload))
+	return []byte(b.String())
+}
+
+// SignConfig 用共享密钥计算配置内容的 HMAC-SHA256，返回十六进制签名。
+// 面板下发配置与 Agent 校验共用这一实现，保证两端对同一份字节计算签名。
+func SignConfig(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyConfigSignature 以常量时间比较配置签名，签名为空或不是十六进制时视为不匹配。
+func VerifyConfigSignature(secret string, payload []byte, signature string) bool {
+	expected, err := hex.DecodeString(strings.TrimSpace(signature))
+	if err != nil || len(expected) == 0 {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
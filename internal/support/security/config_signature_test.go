@@ -0,0 +1,41 @@
+package security
+
+import "testing"
+
+func TestConfigSignature(t *testing.T) {
+	payload := []byte(`{"inbounds":[]}`)
+	signature := SignConfig("secret", payload)
+
+	if !VerifyConfigSignature("secret", payload, signature) {
+		t.Fatal("signature should verify with the same secret and payload")
+	}
+	if VerifyConfigSignature("other", payload, signature) {
+		t.Fatal("signature must not verify with a different secret")
+	}
+	if VerifyConfigSignature("secret", []byte(`{"inbounds":[{}]}`), signature) {
+		t.Fatal("signature must not verify a tampered payload")
+	}
+	if VerifyConfigSignature("secret", payload, "") || VerifyConfigSignature("secret", payload, "not-hex") {
+		t.Fatal("empty or malformed signature must not verify")
+	}
+}
+
+func TestConfigSignatureMessageBindsHostAndVersion(t *testing.T) {
+	payload := []byte(`{"inbounds":[]}`)
+	signature := SignConfig("secret", ConfigSignatureMessage(ConfigSignatureScopeConfig, 1, payload, "etag-a", "100"))
+
+	if !VerifyConfigSignature("secret", ConfigSignatureMessage(ConfigSignatureScopeConfig, 1, payload, "etag-a", "100"), signature) {
+		t.Fatal("signature should verify for the same host and version")
+	}
+	for name, message := range map[string][]byte{
+		"other host":    ConfigSignatureMessage(ConfigSignatureScopeConfig, 2, payload, "etag-a", "100"),
+		"other version": ConfigSignatureMessage(ConfigSignatureScopeConfig, 1, payload, "etag-a", "101"),
+		"other scope":   ConfigSignatureMessage(ConfigSignatureScopeCoreOperation, 1, payload, "etag-a", "100"),
+		"shifted field": ConfigSignatureMessage(ConfigSignatureScopeConfig, 1, payload, "etag-a1", "00"),
+		"raw payload":   payload,
+	} {
+		if VerifyConfigSignature("secret", message, signature) {
+			t.Fatalf("signature must not verify for %s", name)
+		}
+	}
+}
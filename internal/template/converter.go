@@ -59,6 +59,12 @@ func (r *ConverterRegistry) Parse(configJSON []byte, sourceCore string) ([]Unifi
 	return converter.ToUnified(configJSON)
 }
 
+// Has 判断是否已注册指定核心的转换器。
+func (r *ConverterRegistry) Has(coreType string) bool {
+	_, err := r.getConverter(coreType)
+	return err == nil
+}
+
 func (r *ConverterRegistry) getConverter(coreType string) (ConfigConverter, error) {
 	if r == nil || len(r.converters) == 0 {
 		return nil, fmt.Errorf("no converters registered / 未注册任何转换器")
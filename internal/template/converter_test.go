@@ -0,0 +1,171 @@
+package template
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConverterRoundTrip(t *testing.T) {
+	registry := NewConverterRegistry(&SingBoxConverter{}, &XrayConverter{})
+
+	tests := []struct {
+		name   string
+		source string
+		target string
+		config string
+	}{
+		{
+			name:   "xray vless reality tcp",
+			source: "xray",
+			target: "sing-box",
+			config: `{"inbounds":[{"protocol":"vless","tag":"vless-reality","listen":"::","port":443,
+				"settings":{"decryption":"none","clients":[{"id":"11111111-1111-1111-1111-111111111111","email":"a@example.com","flow":"xtls-rprx-vision"}]},
+				"streamSettings":{"network":"tcp","security":"reality","realitySettings":{
+					"dest":"www.example.com:443","serverNames":["www.example.com"],
+					"privateKey":"priv-key","shortIds":["0123abcd",""]}}}]}`,
+		},
+		{
+			name:   "xray vless reality grpc",
+			source: "xray",
+			target: "sing-box",
+			config: `{"inbounds":[{"protocol":"vless","tag":"vless-grpc","port":8443,
+				"settings":{"decryption":"none","clients":[{"id":"22222222-2222-2222-2222-222222222222","email":"b@example.com"}]},
+				"streamSettings":{"network":"grpc","grpcSettings":{"serviceName":"tunnel"},"security":"reality","realitySettings":{
+					"dest":"www.example.org:443","serverNames":["www.example.org"],
+					"privateKey":"priv-key","shortIds":["ff"]}}}]}`,
+		},
+		{
+			name:   "xray trojan grpc tls",
+			source: "xray",
+			target: "sing-box",
+			config: `{"inbounds":[{"protocol":"trojan","tag":"trojan-grpc","port":2053,
+				"settings":{"clients":[{"password":"secret","email":"c@example.com"}]},
+				"streamSettings":{"network":"grpc","grpcSettings":{"serviceName":"trojan-svc"},"security":"tls",
+					"tlsSettings":{"serverName":"node.example.com","alpn":["h2"]}}}]}`,
+		},
+		{
+			name:   "sing-box vless reality tcp",
+			source: "sing-box",
+			target: "xray",
+			config: `{"inbounds":[{"type":"vless","tag":"vless-reality","listen":"::","listen_port":443,
+				"users":[{"name":"a@example.com","uuid":"11111111-1111-1111-1111-111111111111","flow":"xtls-rprx-vision"}],
+				"tls":{"enabled":true,"server_name":"www.example.com","reality":{"enabled":true,
+					"handshake":{"server":"www.example.com","server_port":443},
+					"private_key":"priv-key","short_id":["0123abcd"]}}}]}`,
+		},
+		{
+			name:   "sing-box vless reality grpc",
+			source: "sing-box",
+			target: "xray",
+			config: `{"inbounds":[{"type":"vless","tag":"vless-grpc","listen_port":8443,
+				"users":[{"name":"b@example.com","uuid":"22222222-2222-2222-2222-222222222222"}],
+				"transport":{"type":"grpc","service_name":"tunnel"},
+				"tls":{"enabled":true,"server_name":"www.example.org","reality":{"enabled":true,
+					"handshake":{"server":"www.example.org","server_port":443},
+					"private_key":"priv-key","short_id":["ff"]}}}]}`,
+		},
+		{
+			name:   "sing-box multiplex brutal",
+			source: "sing-box",
+			target: "sing-box",
+			config: `{"inbounds":[{"type":"vless","tag":"vless-mux","listen_port":443,
+				"users":[{"name":"a@example.com","uuid":"11111111-1111-1111-1111-111111111111"}],
+				"multiplex":{"enabled":true,"padding":true,"brutal":{"enabled":true,"up_mbps":100,"down_mbps":200}}}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original, err := registry.Parse([]byte(tt.config), tt.source)
+			if err != nil {
+				t.Fatalf("parse source: %v", err)
+			}
+			converted, warnings, err := registry.ConvertWithWarnings(original, tt.target)
+			if err != nil {
+				t.Fatalf("convert to %s: %v", tt.target, err)
+			}
+			if len(warnings) > 0 {
+				t.Fatalf("unexpected warnings: %v", warnings)
+			}
+			intermediate, err := registry.Parse(converted, tt.target)
+			if err != nil {
+				t.Fatalf("parse %s: %v", tt.target, err)
+			}
+			back, err := registry.Convert(intermediate, tt.source)
+			if err != nil {
+				t.Fatalf("convert back to %s: %v", tt.source, err)
+			}
+			roundTripped, err := registry.Parse(back, tt.source)
+			if err != nil {
+				t.Fatalf("parse round trip: %v", err)
+			}
+			if !reflect.DeepEqual(original, roundTripped) {
+				want, _ := json.Marshal(original)
+				got, _ := json.Marshal(roundTripped)
+				t.Fatalf("round trip mismatch\nwant %s\ngot  %s", want, got)
+			}
+		})
+	}
+}
+
+func TestConverterWarnings(t *testing.T) {
+	registry := NewConverterRegistry(&SingBoxConverter{}, &XrayConverter{})
+
+	tests := []struct {
+		name     string
+		source   string
+		target   string
+		config   string
+		contains []string
+	}{
+		{
+			name:   "multiplex to xray",
+			source: "sing-box",
+			target: "xray",
+			config: `{"inbounds":[{"type":"vless","tag":"vless-mux","listen_port":443,
+				"users":[{"uuid":"11111111-1111-1111-1111-111111111111"}],
+				"multiplex":{"enabled":true,"brutal":{"enabled":true,"up_mbps":100,"down_mbps":100}}}]}`,
+			contains: []string{"multiplex", "tcp brutal"},
+		},
+		{
+			name:   "multiple reality server names to sing-box",
+			source: "xray",
+			target: "sing-box",
+			config: `{"inbounds":[{"protocol":"vless","tag":"vless-reality","port":443,
+				"settings":{"decryption":"none","clients":[{"id":"11111111-1111-1111-1111-111111111111"}]},
+				"streamSettings":{"network":"tcp","security":"reality","realitySettings":{
+					"dest":"a.example.com:443","serverNames":["a.example.com","b.example.com"],"privateKey":"k"}}}]}`,
+			contains: []string{"single server name"},
+		},
+		{
+			name:   "grpc multiMode to sing-box",
+			source: "xray",
+			target: "sing-box",
+			config: `{"inbounds":[{"protocol":"trojan","tag":"trojan-grpc","port":443,
+				"settings":{"clients":[{"password":"p"}]},
+				"streamSettings":{"network":"grpc","grpcSettings":{"serviceName":"svc","multiMode":true}}}]}`,
+			contains: []string{"multiMode"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inbounds, err := registry.Parse([]byte(tt.config), tt.source)
+			if err != nil {
+				t.Fatalf("parse source: %v", err)
+			}
+			_, warnings, err := registry.ConvertWithWarnings(inbounds, tt.target)
+			if err != nil {
+				t.Fatalf("convert: %v", err)
+			}
+			joined := strings.Join(warnings, "\n")
+			for _, want := range tt.contains {
+				if !strings.Contains(joined, want) {
+					t.Fatalf("warnings %q missing %q", warnings, want)
+				}
+			}
+		})
+	}
+}
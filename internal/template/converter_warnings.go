@@ -0,0 +1,53 @@
+package template
+
+import "fmt"
+
+// ConvertWithWarnings 将统一入站转换为目标核心配置，并返回目标核心无法表达而被丢弃的字段说明。
+func (r *ConverterRegistry) ConvertWithWarnings(inbounds []UnifiedInbound, targetCore string) ([]byte, []string, error) {
+	converted, err := r.Convert(inbounds, targetCore)
+	if err != nil {
+		return nil, nil, err
+	}
+	return converted, ConversionWarnings(inbounds, targetCore), nil
+}
+
+// ConversionWarnings 列出转换到 targetCore 时没有等价字段的配置。
+func ConversionWarnings(inbounds []UnifiedInbound, targetCore string) []string {
+	var warnings []string
+	for _, inbound := range inbounds {
+		tag := inbound.Tag
+		if tag == "" {
+			tag = fmt.Sprintf("%s:%d", inbound.Protocol, inbound.Port)
+		}
+		switch normalizeCoreType(targetCore) {
+		case "xray":
+			warnings = append(warnings, xrayConversionWarnings(tag, inbound)...)
+		case "sing-box":
+			warnings = append(warnings, singBoxConversionWarnings(tag, inbound)...)
+		}
+	}
+	return warnings
+}
+
+func xrayConversionWarnings(tag string, inbound UnifiedInbound) []string {
+	var warnings []string
+	if inbound.Multiplex != nil && inbound.Multiplex.Enabled {
+		warnings = append(warnings, fmt.Sprintf("inbound %s: multiplex has no xray inbound equivalent and was dropped / 入站 %s: Xray 入站不支持 multiplex，已丢弃", tag, tag))
+		if inbound.Multiplex.Brutal != nil && inbound.Multiplex.Brutal.Enabled {
+			warnings = append(warnings, fmt.Sprintf("inbound %s: tcp brutal has no xray equivalent and was dropped / 入站 %s: Xray 不支持 TCP Brutal，已丢弃", tag, tag))
+		}
+	}
+	return warnings
+}
+
+func singBoxConversionWarnings(tag string, inbound UnifiedInbound) []string {
+	var warnings []string
+	if inbound.Transport != nil && inbound.Transport.MultiMode {
+		warnings = append(warnings, fmt.Sprintf("inbound %s: grpc multiMode has no sing-box equivalent and was dropped / 入站 %s: sing-box 不支持 gRPC multiMode，已丢弃", tag, tag))
+	}
+	if inbound.TLS != nil && inbound.TLS.Reality != nil && inbound.TLS.Reality.Enabled && len(inbound.TLS.Reality.ServerNames) > 1 {
+		warnings = append(warnings, fmt.Sprintf("inbound %s: sing-box reality accepts a single server name, kept %q / 入站 %s: sing-box Reality 仅支持单个 SNI，保留 %q",
+			tag, inbound.TLS.Reality.ServerNames[0], tag, inbound.TLS.Reality.ServerNames[0]))
+	}
+	return warnings
+}
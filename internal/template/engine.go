@@ -123,6 +123,11 @@ func (e *Engine) createSampleContext() *TemplateContext {
 			{Type: "direct", Tag: "direct"},
 			{Type: "block", Tag: "block"},
 		},
+		Route: &RouteConfig{
+			Rules: []RouteRule{
+				{Inbound: []string{"vless-in"}, Geosite: []string{"category-ads-all"}, Outbound: "block"},
+			},
+		},
 		Users: []UserConfig{
 			{ID: 1, UUID: "00000000-0000-0000-0000-000000000001", Email: "user@example.com", Enabled: true},
 			{ID: 2, UUID: "00000000-0000-0000-0000-000000000002", Email: "user2@example.com", Enabled: true},
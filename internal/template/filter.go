@@ -46,14 +46,45 @@ func (f *CapabilityFilter) FilterContext(ctx *TemplateContext) (*TemplateContext
 	}
 	filtered.Inbounds = filteredInbounds
 
+	// 过滤路由中的 geosite/geoip 规则
+	if ctx.Route != nil {
+		filtered.Route = f.filterRoute(ctx.Route, &warnings)
+	}
+
 	// 过滤实验特性
 	if ctx.Experimental != nil {
 		filtered.Experimental = f.filterExperimental(ctx.Experimental, &warnings)
 	}
 
+	if warning := speedLimitWarning(ctx); warning != "" {
+		warnings = append(warnings, warning)
+	}
+
 	return &filtered, warnings
 }
 
+// speedLimitWarning 提示 Agent 节点上设置了限速的用户不会被限速：sing-box 与 xray 的入站用户
+// 都没有按用户限速的配置项，Agent 也不执行 speed_limit，该限制目前只由 UniProxy 对接的节点端执行。
+func speedLimitWarning(ctx *TemplateContext) string {
+	coreType := strings.ToLower(strings.TrimSpace(ctx.Agent.CoreType))
+	if coreType != "sing-box" && coreType != "xray" {
+		return ""
+	}
+	limited := 0
+	for _, u := range ctx.Users {
+		if u.Enabled && u.SpeedLimit > 0 {
+			limited++
+		}
+	}
+	if limited == 0 {
+		return ""
+	}
+	return fmt.Sprintf(
+		"%d user(s) have speed_limit set, but speed_limit is not supported on %s and will not be enforced on this node / %d 个用户设置了限速，但 %s 不支持 speed_limit，该节点不会执行限速",
+		limited, coreType, limited, coreType,
+	)
+}
+
 // filterInbound 过滤单个入站内的特性。
 func (f *CapabilityFilter) filterInbound(inbound *InboundConfig, warnings *[]string) *InboundConfig {
 	result := *inbound // 浅拷贝
@@ -72,6 +103,16 @@ func (f *CapabilityFilter) filterInbound(inbound *InboundConfig, warnings *[]str
 				result.TLS.Reality = nil
 			}
 		}
+
+		// 不支持 ECH 时过滤掉，避免旧版核心拒绝整个配置
+		if tlsCopy.ECH != nil && tlsCopy.ECH.Enabled {
+			if !f.agentCaps.SupportsCapability(CapECH) {
+				*warnings = append(*warnings, fmt.Sprintf(
+					"Removing ECH from inbound '%s' - not supported by agent (%s)",
+					inbound.Tag, f.getVersionRequirement(CapECH)))
+				result.TLS.ECH = nil
+			}
+		}
 	}
 
 	// 深拷贝 Multiplex，避免修改原对象
@@ -97,6 +138,54 @@ func (f *CapabilityFilter) filterInbound(inbound *InboundConfig, warnings *[]str
 	return &result
 }
 
+// filterRoute 移除核心不支持的 geosite/geoip 匹配。sing-box 需要 rule-set 支持，
+// Xray 需要 geoip/geosite 数据；移除后无剩余匹配条件的规则整体丢弃，避免变成全量匹配。
+func (f *CapabilityFilter) filterRoute(route *RouteConfig, warnings *[]string) *RouteConfig {
+	result := *route // 浅拷贝
+	result.Rules = make([]RouteRule, 0, len(route.Rules))
+
+	var geositeCap, geoipCap Capability
+	switch f.agentCaps.CoreType {
+	case "sing-box":
+		geositeCap, geoipCap = CapRuleSet, CapRuleSet
+	case "xray":
+		geositeCap, geoipCap = CapGeoSite, CapGeoIP
+	}
+
+	for _, rule := range route.Rules {
+		if !rule.HasGeoRules() {
+			result.Rules = append(result.Rules, rule)
+			continue
+		}
+		if len(rule.Geosite) > 0 && (geositeCap == "" || !f.agentCaps.SupportsCapability(geositeCap)) {
+			*warnings = append(*warnings, fmt.Sprintf(
+				"Removing geosite match %v from route rule to '%s' - not supported by agent (%s)",
+				rule.Geosite, rule.Outbound, f.getVersionRequirement(geositeCap)))
+			rule.Geosite = nil
+		}
+		if len(rule.GeoIP) > 0 && (geoipCap == "" || !f.agentCaps.SupportsCapability(geoipCap)) {
+			*warnings = append(*warnings, fmt.Sprintf(
+				"Removing geoip match %v from route rule to '%s' - not supported by agent (%s)",
+				rule.GeoIP, rule.Outbound, f.getVersionRequirement(geoipCap)))
+			rule.GeoIP = nil
+		}
+		if rule.isEmptyMatch() {
+			continue
+		}
+		result.Rules = append(result.Rules, rule)
+	}
+
+	if len(result.Rules) == 0 && result.Final == "" {
+		return nil
+	}
+	return &result
+}
+
+// VersionRequirement 返回能力对应的易读版本要求，供上层拼装提示。
+func (f *CapabilityFilter) VersionRequirement(cap Capability) string {
+	return f.getVersionRequirement(cap)
+}
+
 // getVersionRequirement 返回易读的版本要求字符串。
 func (f *CapabilityFilter) getVersionRequirement(cap Capability) string {
 	switch f.agentCaps.CoreType {
@@ -165,6 +254,12 @@ func (f *CapabilityFilter) CheckTemplateCompatibility(minVersion string, reqCaps
 	// 检查所需能力
 	for _, cap := range reqCaps {
 		if !f.agentCaps.SupportsCapability(Capability(cap)) {
+			if Capability(cap) == CapECH {
+				warnings = append(warnings, fmt.Sprintf(
+					"Template requires capability 'ech' but agent version %s predates support (%s)",
+					f.agentCaps.CoreVersion, f.getVersionRequirement(CapECH)))
+				continue
+			}
 			warnings = append(warnings, fmt.Sprintf(
 				"Template requires capability '%s' which may not be fully supported", cap))
 		}
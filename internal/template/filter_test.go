@@ -0,0 +1,32 @@
+package template
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFilterContextWarnsOnSpeedLimit(t *testing.T) {
+	filter := NewCapabilityFilter(&AgentCapabilities{CoreType: "sing-box"})
+	ctx := &TemplateContext{
+		Agent: AgentInfo{CoreType: "sing-box"},
+		Users: []UserConfig{
+			{UUID: "a", Enabled: true, SpeedLimit: 100},
+			{UUID: "b", Enabled: true},
+		},
+	}
+	_, warnings := filter.FilterContext(ctx)
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "speed_limit is not supported on sing-box") {
+		t.Fatalf("warnings = %v, want a single speed_limit warning", warnings)
+	}
+
+	ctx.Users[0].SpeedLimit = 0
+	if _, warnings := filter.FilterContext(ctx); len(warnings) != 0 {
+		t.Fatalf("warnings without limits = %v, want none", warnings)
+	}
+
+	ctx.Users[0].SpeedLimit = 100
+	ctx.Agent.CoreType = "xray"
+	if _, warnings := filter.FilterContext(ctx); len(warnings) != 1 || !strings.Contains(warnings[0], "not supported on xray") {
+		t.Fatalf("xray warnings = %v, want a single speed_limit warning", warnings)
+	}
+}
@@ -3,6 +3,7 @@ package template
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"strings"
 	"text/template"
 )
@@ -92,8 +93,13 @@ func DefaultFuncMap() template.FuncMap {
 				case "shadowsocks":
 					user.Password = u.UUID // 使用 UUID 作为密码
 					user.UUID = ""         // Shadowsocks 不使用 UUID
-				case "trojan", "hysteria2", "tuic":
+				case "trojan", "hysteria2", "tuic", "anytls":
 					user.Password = u.UUID // 使用 UUID 作为密码
+				case "naive", "socks", "http":
+					user.Username = u.Email // naive/socks/http 使用 basic-auth 用户名/密码
+					user.Password = u.UUID
+					user.Name = ""
+					user.UUID = ""
 				case "vless":
 					user.Flow = "xtls-rprx-vision" // VLESS 默认流控
 				case "vmess":
@@ -234,8 +240,8 @@ func DefaultFuncMap() template.FuncMap {
 				result["listen_port"] = inbound.ListenPort
 			}
 
-			// 按协议填充用户
-			if len(users) > 0 {
+			// 按协议填充用户；socks/http 关闭认证时不注入用户
+			if len(users) > 0 && (!isProxyAuthType(inbound.Type) || ProxyAuthEnabled(inbound.Options)) {
 				usersList := make([]map[string]interface{}, 0, len(users))
 				for _, u := range users {
 					if !u.Enabled {
@@ -252,12 +258,28 @@ func DefaultFuncMap() template.FuncMap {
 						user["uuid"] = u.UUID
 					case "shadowsocks":
 						user["password"] = u.UUID
-					case "trojan", "hysteria2", "tuic":
+					case "trojan", "hysteria2", "tuic", "anytls":
+						user["password"] = u.UUID
+					case "naive", "socks", "http":
+						delete(user, "name")
+						user["username"] = u.Email
 						user["password"] = u.UUID
 					}
+					// sing-box 用户条目没有限速字段且严格拒绝未知键，speed_limit 在 sing-box 上不受支持，
+					// 不写入配置；CapabilityFilter 会对设置了限速的用户给出兼容性告警
 					usersList = append(usersList, user)
 				}
 				result["users"] = usersList
+			} else if isProxyAuthType(inbound.Type) && ProxyAuthEnabled(inbound.Options) {
+				// 声明空 users 使 Agent 后续能注入用户；未声明表示关闭认证
+				result["users"] = []map[string]interface{}{}
+			}
+
+			// AnyTLS 填充方案
+			if inbound.Type == "anytls" {
+				if scheme := AnyTLSPaddingScheme(inbound.Options); len(scheme) > 0 {
+					result["padding_scheme"] = scheme
+				}
 			}
 
 			// 传输层配置
@@ -319,6 +341,20 @@ func DefaultFuncMap() template.FuncMap {
 					tls["reality"] = reality
 				}
 
+				// ECH 配置
+				if inbound.TLS.ECH != nil && inbound.TLS.ECH.Enabled {
+					ech := map[string]interface{}{
+						"enabled": true,
+					}
+					if len(inbound.TLS.ECH.Key) > 0 {
+						ech["key"] = inbound.TLS.ECH.Key
+					}
+					if inbound.TLS.ECH.PQSignatureSchemes {
+						ech["pq_signature_schemes_enabled"] = true
+					}
+					tls["ech"] = ech
+				}
+
 				result["tls"] = tls
 			}
 
@@ -343,6 +379,12 @@ func DefaultFuncMap() template.FuncMap {
 			return result
 		},
 
+		// 生成 mieru 服务端（mita）完整配置，仅包含 type=mieru 的入站
+		"mieruServerConfig": buildMieruServerConfig,
+
+		// 生成 sing-box ShadowTLS 入站：对外的 shadowtls 入站经 detour 转发到本地 shadowsocks 入站
+		"singboxShadowTLSInbounds": buildSingBoxShadowTLSInbounds,
+
 		// 生成 v2ray_api 实验配置
 		"v2rayAPIConfig": func(listenAddr string, inbounds []InboundConfig, users []UserConfig) map[string]interface{} {
 			if listenAddr == "" {
@@ -409,6 +451,11 @@ func DefaultFuncMap() template.FuncMap {
 			}
 		},
 
+		// 生成 sing-box 路由配置：包含服务端路由规则，geosite/geoip 以 rule_set 形式引用
+		"singboxRoute": func(route *RouteConfig, inbounds []InboundConfig) map[string]interface{} {
+			return buildSingBoxRoute(route, inbounds)
+		},
+
 		// 判断 capability 是否存在
 		"hasCap": func(capabilities []string, cap string) bool {
 			for _, c := range capabilities {
@@ -459,6 +506,9 @@ func DefaultFuncMap() template.FuncMap {
 			switch inbound.Type {
 			case "vless":
 				settings["decryption"] = "none"
+				if encryption := strings.TrimSpace(inbound.Encryption); encryption != "" {
+					settings["decryption"] = encryption
+				}
 				omitDefaultFlow := inbound.Transport != nil && IsXHTTPNetwork(inbound.Transport.Type)
 				clients := make([]map[string]interface{}, 0, len(users))
 				for _, u := range users {
@@ -527,6 +577,30 @@ func DefaultFuncMap() template.FuncMap {
 					clients = append(clients, client)
 				}
 				settings["clients"] = clients
+
+			case "socks", "http":
+				// Xray socks/http 使用 accounts（user/pass），与 sing-box 的 username/password 对应
+				authEnabled := ProxyAuthEnabled(inbound.Options)
+				if inbound.Type == "socks" {
+					settings["udp"] = true
+					settings["auth"] = "noauth"
+					if authEnabled {
+						settings["auth"] = "password"
+					}
+				}
+				if authEnabled {
+					accounts := make([]map[string]interface{}, 0, len(users))
+					for _, u := range users {
+						if !u.Enabled {
+							continue
+						}
+						accounts = append(accounts, map[string]interface{}{
+							"user": u.Email,
+							"pass": u.UUID,
+						})
+					}
+					settings["accounts"] = accounts
+				}
 			}
 			result["settings"] = settings
 
@@ -679,6 +753,11 @@ func DefaultFuncMap() template.FuncMap {
 			}
 		},
 
+		// 生成 Xray 路由配置：包含服务端路由规则，geosite/geoip 以 geosite:/geoip: 前缀引用
+		"xrayRoute": func(route *RouteConfig) map[string]interface{} {
+			return buildXrayRouting(route)
+		},
+
 		// 生成 Xray 默认路由配置
 		"xrayDefaultRouting": func(inbounds []InboundConfig) map[string]interface{} {
 			inboundTags := make([]string, 0, len(inbounds))
@@ -701,3 +780,195 @@ func DefaultFuncMap() template.FuncMap {
 		},
 	}
 }
+
+// isProxyAuthType 判断入站是否为可选认证的 socks/http 类型。
+func isProxyAuthType(inboundType string) bool {
+	return inboundType == "socks" || inboundType == "http"
+}
+
+// ProxyAuthEnabled 判断 socks/http 入站是否启用用户名密码认证。默认启用，
+// 仅当选项 auth 显式为 "none"/"noauth" 时关闭，供只监听回环地址的内部中转使用。
+func ProxyAuthEnabled(options map[string]interface{}) bool {
+	auth, _ := options["auth"].(string)
+	switch strings.ToLower(strings.TrimSpace(auth)) {
+	case "none", "noauth":
+		return false
+	}
+	return true
+}
+
+// IsLoopbackListen 判断监听地址是否仅限本机回环；空地址按 sing-box/Xray 默认的全部地址处理。
+func IsLoopbackListen(listen string) bool {
+	listen = strings.Trim(strings.TrimSpace(listen), "[]")
+	if strings.EqualFold(listen, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(listen)
+	return ip != nil && ip.IsLoopback()
+}
+
+// AnyTLSPaddingScheme 从入站选项中读取 AnyTLS padding_scheme，兼容字符串数组与多行字符串。
+func AnyTLSPaddingScheme(options map[string]interface{}) []string {
+	raw, ok := options["padding_scheme"]
+	if !ok || raw == nil {
+		return nil
+	}
+	var lines []string
+	switch v := raw.(type) {
+	case []string:
+		lines = v
+	case []interface{}:
+		for _, item := range v {
+			if str, ok := item.(string); ok {
+				lines = append(lines, str)
+			}
+		}
+	case string:
+		lines = strings.Split(v, "\n")
+	}
+	result := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			result = append(result, line)
+		}
+	}
+	return result
+}
+
+// buildSingBoxShadowTLSInbounds 生成 ShadowTLS 入站对：shadowtls 监听公网端口并完成握手伪装，
+// 随后经 detour 交给仅监听回环地址的 shadowsocks 入站处理。v3 为用户级密码（用户 UUID），v2 使用单一密码。
+// 2022-blake3 加密方式下 shadowsocks 使用面板派生的服务端 PSK 与 Base64 用户密钥，与订阅下发的密码一致。
+func buildSingBoxShadowTLSInbounds(inbound InboundConfig, users []UserConfig) []map[string]interface{} {
+	cfg := ShadowTLSConfig{Version: 3}
+	if inbound.ShadowTLS != nil {
+		cfg = *inbound.ShadowTLS
+		if cfg.Version == 0 {
+			cfg.Version = 3
+		}
+	}
+	method := cfg.Method
+	if method == "" {
+		method = DefaultShadowTLSMethod
+	}
+	handlerTag := inbound.Tag + "-ss"
+
+	shadowTLS := map[string]interface{}{
+		"type":    "shadowtls",
+		"tag":     inbound.Tag,
+		"listen":  "::",
+		"version": cfg.Version,
+		"detour":  handlerTag,
+	}
+	if inbound.Listen != "" {
+		shadowTLS["listen"] = inbound.Listen
+	}
+	if inbound.ListenPort > 0 {
+		shadowTLS["listen_port"] = inbound.ListenPort
+	}
+	if cfg.Handshake != nil && cfg.Handshake.Server != "" {
+		port := cfg.Handshake.ServerPort
+		if port == 0 {
+			port = 443
+		}
+		shadowTLS["handshake"] = map[string]interface{}{
+			"server":      cfg.Handshake.Server,
+			"server_port": port,
+		}
+	}
+	if cfg.StrictMode {
+		shadowTLS["strict_mode"] = true
+	}
+
+	tlsUsers := make([]map[string]interface{}, 0, len(users))
+	ssUsers := make([]map[string]interface{}, 0, len(users))
+	for _, u := range users {
+		if !u.Enabled {
+			continue
+		}
+		tlsUsers = append(tlsUsers, map[string]interface{}{"name": u.Email, "password": u.UUID})
+		ssPassword := u.UUID
+		if IsShadowsocks2022(method) {
+			ssPassword = Shadowsocks2022UserKey(u.UUID, method)
+		}
+		ssUsers = append(ssUsers, map[string]interface{}{"name": u.Email, "password": ssPassword})
+	}
+	if cfg.Version >= 3 {
+		shadowTLS["users"] = tlsUsers
+	} else if cfg.Password != "" {
+		shadowTLS["password"] = cfg.Password
+	}
+
+	handler := map[string]interface{}{
+		"type":    "shadowsocks",
+		"tag":     handlerTag,
+		"listen":  "127.0.0.1",
+		"network": "tcp",
+		"method":  method,
+		"users":   ssUsers,
+	}
+	if IsShadowsocks2022(method) && cfg.ServerKey != "" {
+		handler["password"] = cfg.ServerKey
+	}
+
+	return []map[string]interface{}{shadowTLS, handler}
+}
+
+// buildMieruServerConfig 生成 mita 服务端配置：每个 mieru 入站对应一个端口绑定，用户在所有绑定间共享。
+// 用户名与密码均使用 UUID，与订阅下发的客户端配置保持一致；多路复用由客户端配置决定，服务端无需设置。
+func buildMieruServerConfig(inbounds []InboundConfig, users []UserConfig) map[string]interface{} {
+	bindings := make([]map[string]interface{}, 0, len(inbounds))
+	mtu := 0
+	for _, inbound := range inbounds {
+		if inbound.Type != "mieru" {
+			continue
+		}
+		transport := "TCP"
+		if v, ok := inbound.Options["transport"].(string); ok && v != "" {
+			transport = strings.ToUpper(v)
+		}
+		binding := map[string]interface{}{"protocol": transport}
+		if portRange, ok := inbound.Options["port_range"].(string); ok && portRange != "" {
+			binding["portRange"] = portRange
+		} else {
+			binding["port"] = inbound.ListenPort
+		}
+		bindings = append(bindings, binding)
+		if mtu == 0 {
+			mtu = optionInt(inbound.Options, "mtu")
+		}
+	}
+	if mtu == 0 {
+		mtu = 1400
+	}
+
+	mieruUsers := make([]map[string]interface{}, 0, len(users))
+	for _, u := range users {
+		if !u.Enabled {
+			continue
+		}
+		mieruUsers = append(mieruUsers, map[string]interface{}{
+			"name":     u.UUID,
+			"password": u.UUID,
+		})
+	}
+
+	return map[string]interface{}{
+		"portBindings": bindings,
+		"users":        mieruUsers,
+		"loggingLevel": "INFO",
+		"mtu":          mtu,
+	}
+}
+
+// optionInt 读取入站选项中的整数值，兼容 JSON 解码得到的 float64。
+func optionInt(options map[string]interface{}, key string) int {
+	switch v := options[key].(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	}
+	return 0
+}
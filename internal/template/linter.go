@@ -0,0 +1,301 @@
+package template
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Linter 规则标识。
+const (
+	LintRuleTemplateSyntax    = "template-syntax"
+	LintRuleUndefinedVariable = "undefined-variable"
+	LintRuleTemplateExecution = "template-execution"
+	LintRuleInvalidJSON       = "invalid-json"
+	LintRuleUnknownType       = "unknown-type"
+	LintRuleDuplicateTag      = "duplicate-tag"
+	LintRuleMissingRouteFinal = "missing-route-final"
+	LintRuleUndefinedOutbound = "undefined-outbound"
+	LintRuleUndefinedInbound  = "undefined-inbound"
+	LintRuleUndefinedBalancer = "undefined-balancer"
+	LintRuleDefaultBlocks     = "default-outbound-blocks"
+)
+
+// Linter 检查能解析但语义有误的模板（重复 tag、缺少 route.final、引用未定义出站等）。
+// 与 Validator 不同，Linter 的每条问题都带规则标识与修复建议。
+type Linter struct {
+	engine *Engine
+}
+
+// NewLinter 创建模板 Linter。
+func NewLinter() *Linter {
+	return &Linter{engine: NewEngine()}
+}
+
+// Lint 使用示例上下文渲染原始模板并按核心类型执行规则，返回的切片不为 nil。
+func (l *Linter) Lint(content, templateType string) []ValidationIssue {
+	issues := []ValidationIssue{}
+
+	output, issue := l.render(content)
+	if issue != nil {
+		return append(issues, *issue)
+	}
+
+	var config map[string]interface{}
+	if err := json.Unmarshal(output, &config); err != nil {
+		return append(issues, lintError(LintRuleInvalidJSON, "",
+			fmt.Sprintf("Rendered template is not a JSON object: %v", err),
+			"Check commas between {{range}} items (use isLast) and emit injected values with the json helper"))
+	}
+
+	switch templateType {
+	case "sing-box":
+		issues = append(issues, lintSingBox(config)...)
+	case "xray":
+		issues = append(issues, lintXray(config)...)
+	default:
+		issues = append(issues, lintWarning(LintRuleUnknownType, "",
+			fmt.Sprintf("Unknown template type '%s', skipping core-specific rules", templateType),
+			"Set the template type to 'sing-box' or 'xray'"))
+	}
+	return issues
+}
+
+// render 以 missingkey=error 渲染模板，使未定义的字段与 map 键直接报错而不是输出 <no value>。
+func (l *Linter) render(content string) ([]byte, *ValidationIssue) {
+	tmpl, err := template.New("config").Funcs(l.engine.funcMap).Option("missingkey=error").Parse(content)
+	if err != nil {
+		issue := lintError(LintRuleTemplateSyntax, "", fmt.Sprintf("Template syntax error: %v", err),
+			"Check that every {{ has a matching }}, every {{range}}/{{if}} is closed with {{end}}, and that called functions exist")
+		return nil, &issue
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, l.engine.createSampleContext()); err != nil {
+		message := err.Error()
+		if isUndefinedReference(message) {
+			issue := lintError(LintRuleUndefinedVariable, "", fmt.Sprintf("Template references an undefined variable: %s", message),
+				"Use only fields of the template context (.Inbounds, .Outbounds, .Users, .Agent, .Server) and check their spelling")
+			return nil, &issue
+		}
+		issue := lintError(LintRuleTemplateExecution, "", fmt.Sprintf("Template execution error: %s", message),
+			"Check argument types passed to template functions")
+		return nil, &issue
+	}
+	return buf.Bytes(), nil
+}
+
+func isUndefinedReference(message string) bool {
+	for _, marker := range []string{"can't evaluate field", "map has no entry for key", "undefined variable", "nil pointer evaluating"} {
+		if strings.Contains(message, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// lintSingBox 检查 sing-box 配置：tag 唯一、route.final 存在、引用的入站/出站均已定义。
+func lintSingBox(config map[string]interface{}) []ValidationIssue {
+	var issues []ValidationIssue
+	inboundTags, dup := collectTags(config, "inbounds", "tag")
+	issues = append(issues, dup...)
+	outboundTags, dup := collectTags(config, "outbounds", "tag")
+	issues = append(issues, dup...)
+	endpointTags, dup := collectTags(config, "endpoints", "tag")
+	issues = append(issues, dup...)
+	// endpoints（如 wireguard）与 outbounds 共用出站命名空间
+	for tag := range endpointTags {
+		outboundTags[tag] = struct{}{}
+	}
+
+	for i, item := range objectArray(config["outbounds"]) {
+		for j, ref := range stringArray(item["outbounds"]) {
+			issues = appendUndefinedOutbound(issues, outboundTags, ref, jsonPointer("outbounds", i, "outbounds", j))
+		}
+		if ref, ok := item["detour"].(string); ok {
+			issues = appendUndefinedOutbound(issues, outboundTags, ref, jsonPointer("outbounds", i, "detour"))
+		}
+		if ref, ok := item["default"].(string); ok {
+			issues = appendUndefinedOutbound(issues, outboundTags, ref, jsonPointer("outbounds", i, "default"))
+		}
+	}
+
+	if dns, ok := config["dns"].(map[string]interface{}); ok {
+		for i, server := range objectArray(dns["servers"]) {
+			if ref, ok := server["detour"].(string); ok {
+				issues = appendUndefinedOutbound(issues, outboundTags, ref, jsonPointer("dns", "servers", i, "detour"))
+			}
+		}
+	}
+
+	route, ok := config["route"].(map[string]interface{})
+	if !ok {
+		issues = append(issues, lintWarning(LintRuleMissingRouteFinal, jsonPointer("route"),
+			"Missing 'route' section - traffic falls back to the first outbound",
+			`Add a "route" section with "final" set to an outbound tag such as "direct"`))
+		return issues
+	}
+	if final, ok := route["final"].(string); !ok || strings.TrimSpace(final) == "" {
+		issues = append(issues, lintWarning(LintRuleMissingRouteFinal, jsonPointer("route", "final"),
+			"Missing 'route.final' - unmatched traffic falls back to the first outbound",
+			`Set "route.final" to an outbound tag such as "direct"`))
+	} else {
+		issues = appendUndefinedOutbound(issues, outboundTags, final, jsonPointer("route", "final"))
+	}
+	for i, rule := range objectArray(route["rules"]) {
+		if ref, ok := rule["outbound"].(string); ok {
+			issues = appendUndefinedOutbound(issues, outboundTags, ref, jsonPointer("route", "rules", i, "outbound"))
+		}
+		for _, ref := range stringOrArray(rule["inbound"], jsonPointer("route", "rules", i, "inbound")) {
+			issues = appendUndefinedInbound(issues, inboundTags, ref.value, ref.path)
+		}
+	}
+	return issues
+}
+
+// lintXray 检查 Xray 配置：tag 唯一、路由规则引用的入站/出站/负载均衡器均已定义，默认出站不是 blackhole。
+func lintXray(config map[string]interface{}) []ValidationIssue {
+	var issues []ValidationIssue
+	inboundTags, dup := collectTags(config, "inbounds", "tag")
+	issues = append(issues, dup...)
+	outboundTags, dup := collectTags(config, "outbounds", "tag")
+	issues = append(issues, dup...)
+
+	outbounds := objectArray(config["outbounds"])
+	if len(outbounds) > 0 {
+		if protocol, _ := outbounds[0]["protocol"].(string); protocol == "blackhole" {
+			issues = append(issues, lintWarning(LintRuleDefaultBlocks, jsonPointer("outbounds", 0),
+				"The first outbound is 'blackhole' - Xray uses it as the default, so unmatched traffic is dropped",
+				"Move a 'freedom' outbound to the first position"))
+		}
+	}
+	for i, item := range outbounds {
+		if proxy, ok := item["proxySettings"].(map[string]interface{}); ok {
+			if ref, ok := proxy["tag"].(string); ok {
+				issues = appendUndefinedOutbound(issues, outboundTags, ref, jsonPointer("outbounds", i, "proxySettings", "tag"))
+			}
+		}
+	}
+
+	routing, ok := config["routing"].(map[string]interface{})
+	if !ok {
+		return issues
+	}
+	balancerTags, dup := collectTags(routing, "balancers", "tag")
+	for _, issue := range dup {
+		issue.Path = "/routing" + issue.Path
+		issues = append(issues, issue)
+	}
+	for i, rule := range objectArray(routing["rules"]) {
+		if ref, ok := rule["outboundTag"].(string); ok {
+			issues = appendUndefinedOutbound(issues, outboundTags, ref, jsonPointer("routing", "rules", i, "outboundTag"))
+		}
+		if ref, ok := rule["balancerTag"].(string); ok && ref != "" {
+			if _, defined := balancerTags[ref]; !defined {
+				issues = append(issues, lintError(LintRuleUndefinedBalancer, jsonPointer("routing", "rules", i, "balancerTag"),
+					fmt.Sprintf("Balancer '%s' is not defined", ref),
+					"Add the balancer to 'routing.balancers' or fix the tag"))
+			}
+		}
+		for _, ref := range stringOrArray(rule["inboundTag"], jsonPointer("routing", "rules", i, "inboundTag")) {
+			issues = appendUndefinedInbound(issues, inboundTags, ref.value, ref.path)
+		}
+	}
+	return issues
+}
+
+// collectTags 收集数组中各对象的 tag，并对重复项给出错误。
+func collectTags(config map[string]interface{}, section, field string) (map[string]struct{}, []ValidationIssue) {
+	tags := make(map[string]struct{})
+	var issues []ValidationIssue
+	for i, item := range objectArray(config[section]) {
+		tag, ok := item[field].(string)
+		if !ok || tag == "" {
+			continue
+		}
+		if _, exists := tags[tag]; exists {
+			issues = append(issues, lintError(LintRuleDuplicateTag, jsonPointer(section, i, field),
+				fmt.Sprintf("Duplicate %s tag '%s'", strings.TrimSuffix(section, "s"), tag),
+				"Give every entry in '"+section+"' a unique tag"))
+			continue
+		}
+		tags[tag] = struct{}{}
+	}
+	return tags, issues
+}
+
+func appendUndefinedOutbound(issues []ValidationIssue, outboundTags map[string]struct{}, ref, path string) []ValidationIssue {
+	if ref == "" {
+		return issues
+	}
+	if _, ok := outboundTags[ref]; ok {
+		return issues
+	}
+	return append(issues, lintError(LintRuleUndefinedOutbound, path,
+		fmt.Sprintf("Outbound '%s' is not defined", ref),
+		"Add an outbound with this tag to 'outbounds' or reference an existing one"))
+}
+
+func appendUndefinedInbound(issues []ValidationIssue, inboundTags map[string]struct{}, ref, path string) []ValidationIssue {
+	if ref == "" {
+		return issues
+	}
+	if _, ok := inboundTags[ref]; ok {
+		return issues
+	}
+	return append(issues, lintError(LintRuleUndefinedInbound, path,
+		fmt.Sprintf("Inbound '%s' is not defined", ref),
+		"Reference an inbound tag defined in 'inbounds'"))
+}
+
+func objectArray(v interface{}) []map[string]interface{} {
+	items, _ := v.([]interface{})
+	result := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			obj = map[string]interface{}{}
+		}
+		result = append(result, obj)
+	}
+	return result
+}
+
+func stringArray(v interface{}) []string {
+	items, _ := v.([]interface{})
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		s, _ := item.(string)
+		result = append(result, s)
+	}
+	return result
+}
+
+// tagRef 是一个 tag 引用及其 JSON Pointer。
+type tagRef struct {
+	path  string
+	value string
+}
+
+// stringOrArray 兼容字段既可写成字符串也可写成字符串数组的情况。
+func stringOrArray(v interface{}, path string) []tagRef {
+	if s, ok := v.(string); ok {
+		return []tagRef{{path: path, value: s}}
+	}
+	values := stringArray(v)
+	result := make([]tagRef, 0, len(values))
+	for i, s := range values {
+		result = append(result, tagRef{path: fmt.Sprintf("%s/%d", path, i), value: s})
+	}
+	return result
+}
+
+func lintError(rule, path, message, fix string) ValidationIssue {
+	return ValidationIssue{Path: path, Message: message, Severity: SeverityError, Rule: rule, Fix: fix}
+}
+
+func lintWarning(rule, path, message, fix string) ValidationIssue {
+	return ValidationIssue{Path: path, Message: message, Severity: SeverityWarning, Rule: rule, Fix: fix}
+}
@@ -0,0 +1,60 @@
+package template
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestBuildMieruServerConfig(t *testing.T) {
+	inbounds := []InboundConfig{
+		{Tag: "mieru-tcp", Type: "mieru", ListenPort: 2999, Options: map[string]interface{}{"mtu": 1380}},
+		{Tag: "mieru-udp", Type: "mieru", Options: map[string]interface{}{"transport": "udp", "port_range": "3000-3010"}},
+		{Tag: "vless-in", Type: "vless", ListenPort: 443},
+	}
+	users := []UserConfig{
+		{UUID: "uuid-a", Enabled: true},
+		{UUID: "uuid-b", Enabled: false},
+	}
+
+	config := buildMieruServerConfig(inbounds, users)
+
+	wantBindings := []map[string]interface{}{
+		{"protocol": "TCP", "port": 2999},
+		{"protocol": "UDP", "portRange": "3000-3010"},
+	}
+	if !reflect.DeepEqual(config["portBindings"], wantBindings) {
+		t.Fatalf("portBindings = %v, want %v", config["portBindings"], wantBindings)
+	}
+	wantUsers := []map[string]interface{}{{"name": "uuid-a", "password": "uuid-a"}}
+	if !reflect.DeepEqual(config["users"], wantUsers) {
+		t.Fatalf("users = %v, want %v", config["users"], wantUsers)
+	}
+	if config["mtu"] != 1380 {
+		t.Fatalf("mtu = %v, want 1380 from first binding", config["mtu"])
+	}
+
+	raw, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if issues := NewValidator().ValidateFinalConfig(raw, "mieru"); HasIssueErrors(issues) {
+		t.Fatalf("generated config fails validation: %v", issues)
+	}
+}
+
+func TestBuildMieruServerConfigDefaults(t *testing.T) {
+	config := buildMieruServerConfig([]InboundConfig{{Type: "mieru", ListenPort: 8964}}, nil)
+	if config["mtu"] != 1400 {
+		t.Fatalf("mtu = %v, want default 1400", config["mtu"])
+	}
+	if users, _ := config["users"].([]map[string]interface{}); len(users) != 0 {
+		t.Fatalf("users = %v, want empty", users)
+	}
+
+	empty := buildMieruServerConfig([]InboundConfig{{Type: "vless", ListenPort: 443}}, nil)
+	raw, _ := json.Marshal(empty)
+	if issues := NewValidator().ValidateFinalConfig(raw, "mieru"); !HasIssueErrors(issues) {
+		t.Fatal("config without mieru inbounds should fail validation")
+	}
+}
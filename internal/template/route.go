@@ -0,0 +1,170 @@
+package template
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// singBoxGeositeRuleSetURL / singBoxGeoIPRuleSetURL 为 SagerNet 官方维护的二进制 rule-set 地址。
+	singBoxGeositeRuleSetURL = "https://raw.githubusercontent.com/SagerNet/sing-geosite/rule-set/geosite-%s.srs"
+	singBoxGeoIPRuleSetURL   = "https://raw.githubusercontent.com/SagerNet/sing-geoip/rule-set/geoip-%s.srs"
+)
+
+// HasGeoRules 判断规则是否包含 geosite/geoip 匹配。
+func (r RouteRule) HasGeoRules() bool {
+	return len(r.Geosite) > 0 || len(r.GeoIP) > 0
+}
+
+// isEmptyMatch 判断规则是否已无任何匹配条件（仅剩入站/出站）。
+func (r RouteRule) isEmptyMatch() bool {
+	return len(r.Domain) == 0 && len(r.DomainSuffix) == 0 && len(r.DomainKeyword) == 0 &&
+		len(r.DomainRegex) == 0 && len(r.IPCIDR) == 0 && !r.HasGeoRules()
+}
+
+// routeInboundTags 收集入站标签，用作兜底直连规则。
+func routeInboundTags(inbounds []InboundConfig) []string {
+	tags := make([]string, 0, len(inbounds))
+	for _, in := range inbounds {
+		if in.Tag != "" {
+			tags = append(tags, in.Tag)
+		}
+	}
+	return tags
+}
+
+// buildSingBoxRoute 生成 sing-box route 段：geosite/geoip 转为 rule_set 引用并附带远程 rule-set 定义，
+// 其余规则按原顺序输出，最后追加入站直连兜底规则。
+func buildSingBoxRoute(route *RouteConfig, inbounds []InboundConfig) map[string]interface{} {
+	rules := make([]map[string]interface{}, 0)
+	ruleSets := make([]map[string]interface{}, 0)
+	seenSets := make(map[string]struct{})
+	addRuleSet := func(tag, url string) {
+		if _, ok := seenSets[tag]; ok {
+			return
+		}
+		seenSets[tag] = struct{}{}
+		ruleSets = append(ruleSets, map[string]interface{}{
+			"tag":             tag,
+			"type":            "remote",
+			"format":          "binary",
+			"url":             url,
+			"download_detour": "direct",
+		})
+	}
+
+	final := "direct"
+	if route != nil {
+		if route.Final != "" {
+			final = route.Final
+		}
+		for _, rule := range route.Rules {
+			if rule.Outbound == "" || rule.isEmptyMatch() {
+				continue
+			}
+			item := map[string]interface{}{"outbound": rule.Outbound}
+			if len(rule.Inbound) > 0 {
+				item["inbound"] = rule.Inbound
+			}
+			setIfNotEmpty(item, "domain", rule.Domain)
+			setIfNotEmpty(item, "domain_suffix", rule.DomainSuffix)
+			setIfNotEmpty(item, "domain_keyword", rule.DomainKeyword)
+			setIfNotEmpty(item, "domain_regex", rule.DomainRegex)
+			setIfNotEmpty(item, "ip_cidr", rule.IPCIDR)
+			tags := make([]string, 0, len(rule.Geosite)+len(rule.GeoIP))
+			for _, code := range rule.Geosite {
+				tag := "geosite-" + code
+				addRuleSet(tag, fmt.Sprintf(singBoxGeositeRuleSetURL, code))
+				tags = append(tags, tag)
+			}
+			for _, code := range rule.GeoIP {
+				tag := "geoip-" + code
+				addRuleSet(tag, fmt.Sprintf(singBoxGeoIPRuleSetURL, code))
+				tags = append(tags, tag)
+			}
+			setIfNotEmpty(item, "rule_set", tags)
+			rules = append(rules, item)
+		}
+	}
+
+	rules = append(rules, map[string]interface{}{
+		"inbound":  routeInboundTags(inbounds),
+		"outbound": "direct",
+	})
+	result := map[string]interface{}{
+		"rules": rules,
+		"final": final,
+	}
+	if len(ruleSets) > 0 {
+		result["rule_set"] = ruleSets
+	}
+	return result
+}
+
+// buildXrayRouting 生成 Xray routing 段。Xray 同一规则内字段为“与”关系，
+// 因此域名类与 IP 类匹配拆成两条规则，各自保留相同的入站与出站。
+func buildXrayRouting(route *RouteConfig) map[string]interface{} {
+	rules := []map[string]interface{}{
+		{
+			"type":        "field",
+			"inboundTag":  []string{"api"},
+			"outboundTag": "api",
+		},
+	}
+	if route != nil {
+		for _, rule := range route.Rules {
+			if rule.Outbound == "" || rule.isEmptyMatch() {
+				continue
+			}
+			domains := make([]string, 0, len(rule.Domain)+len(rule.DomainSuffix)+len(rule.DomainKeyword)+len(rule.DomainRegex)+len(rule.Geosite))
+			for _, v := range rule.Domain {
+				domains = append(domains, "full:"+v)
+			}
+			for _, v := range rule.DomainSuffix {
+				domains = append(domains, "domain:"+strings.TrimPrefix(v, "."))
+			}
+			for _, v := range rule.DomainKeyword {
+				domains = append(domains, "keyword:"+v)
+			}
+			for _, v := range rule.DomainRegex {
+				domains = append(domains, "regexp:"+v)
+			}
+			for _, v := range rule.Geosite {
+				domains = append(domains, "geosite:"+v)
+			}
+			ips := make([]string, 0, len(rule.IPCIDR)+len(rule.GeoIP))
+			ips = append(ips, rule.IPCIDR...)
+			for _, v := range rule.GeoIP {
+				ips = append(ips, "geoip:"+v)
+			}
+			if len(domains) > 0 {
+				rules = append(rules, xrayFieldRule(rule, "domain", domains))
+			}
+			if len(ips) > 0 {
+				rules = append(rules, xrayFieldRule(rule, "ip", ips))
+			}
+		}
+	}
+	return map[string]interface{}{
+		"domainStrategy": "AsIs",
+		"rules":          rules,
+	}
+}
+
+func xrayFieldRule(rule RouteRule, key string, values []string) map[string]interface{} {
+	item := map[string]interface{}{
+		"type":        "field",
+		key:           values,
+		"outboundTag": rule.Outbound,
+	}
+	if len(rule.Inbound) > 0 {
+		item["inboundTag"] = rule.Inbound
+	}
+	return item
+}
+
+func setIfNotEmpty(item map[string]interface{}, key string, values []string) {
+	if len(values) > 0 {
+		item[key] = values
+	}
+}
@@ -0,0 +1,57 @@
+package template
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultShadowTLSMethod 为 ShadowTLS 后端 shadowsocks 入站未指定加密方式时使用的默认值。
+const DefaultShadowTLSMethod = "2022-blake3-aes-128-gcm"
+
+// shadowsocks2022KeySizes 为 2022-blake3 系列加密方式的服务端与用户密钥长度。
+var shadowsocks2022KeySizes = map[string]struct {
+	serverKeySize int
+	userKeySize   int
+}{
+	"2022-blake3-aes-128-gcm":       {serverKeySize: 16, userKeySize: 16},
+	"2022-blake3-aes-256-gcm":       {serverKeySize: 32, userKeySize: 32},
+	"2022-blake3-chacha20-poly1305": {serverKeySize: 32, userKeySize: 32},
+}
+
+// IsShadowsocks2022 判断加密方式是否为需要服务端 PSK 的 2022-blake3 系列。
+func IsShadowsocks2022(method string) bool {
+	_, ok := shadowsocks2022KeySizes[strings.ToLower(strings.TrimSpace(method))]
+	return ok
+}
+
+// Shadowsocks2022ServerKey 由节点创建时间派生服务端 PSK：md5(created_at) 十六进制串取前 N 个字符后 Base64，
+// 与 Xboard 的 server_key 算法一致。面板下发的服务端配置与订阅中的客户端密码共用此函数。
+func Shadowsocks2022ServerKey(createdAt int64, method string) string {
+	sizes, ok := shadowsocks2022KeySizes[strings.ToLower(strings.TrimSpace(method))]
+	if !ok || createdAt <= 0 {
+		return ""
+	}
+	hexStr := fmt.Sprintf("%x", md5.Sum([]byte(strconv.FormatInt(createdAt, 10))))
+	length := sizes.serverKeySize
+	if length > len(hexStr) {
+		length = len(hexStr)
+	}
+	return base64.StdEncoding.EncodeToString([]byte(hexStr[:length]))
+}
+
+// Shadowsocks2022UserKey 取用户 UUID 的前 N 个字符后 Base64 作为用户密钥。
+func Shadowsocks2022UserKey(uuid, method string) string {
+	sizes, ok := shadowsocks2022KeySizes[strings.ToLower(strings.TrimSpace(method))]
+	if !ok || uuid == "" {
+		return ""
+	}
+	runes := []rune(uuid)
+	length := sizes.userKeySize
+	if length > len(runes) {
+		length = len(runes)
+	}
+	return base64.StdEncoding.EncodeToString([]byte(string(runes[:length])))
+}
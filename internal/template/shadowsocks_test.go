@@ -0,0 +1,70 @@
+package template
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestShadowsocks2022Keys(t *testing.T) {
+	const createdAt = 1700000000
+	serverKey := Shadowsocks2022ServerKey(createdAt, "2022-blake3-aes-256-gcm")
+	raw, err := base64.StdEncoding.DecodeString(serverKey)
+	if err != nil || len(raw) != 32 {
+		t.Fatalf("server key %q is not 32-byte base64: %v", serverKey, err)
+	}
+	if again := Shadowsocks2022ServerKey(createdAt, "2022-BLAKE3-AES-256-GCM"); again != serverKey {
+		t.Fatalf("server key not stable: %q != %q", again, serverKey)
+	}
+
+	uuid := "8f2b6c2e-1b7a-4c61-9d0e-3f5a7b9c1d2e"
+	userKey := Shadowsocks2022UserKey(uuid, "2022-blake3-aes-128-gcm")
+	if want := base64.StdEncoding.EncodeToString([]byte(uuid[:16])); userKey != want {
+		t.Fatalf("user key = %q, want %q", userKey, want)
+	}
+
+	if Shadowsocks2022ServerKey(createdAt, "aes-128-gcm") != "" || Shadowsocks2022UserKey(uuid, "aes-128-gcm") != "" {
+		t.Fatal("legacy methods must not derive 2022 keys")
+	}
+	if Shadowsocks2022ServerKey(0, "2022-blake3-aes-128-gcm") != "" {
+		t.Fatal("missing created_at must not derive a server key")
+	}
+}
+
+func TestShadowTLSInboundsUseDerivedCredentials(t *testing.T) {
+	method := "2022-blake3-aes-128-gcm"
+	serverKey := Shadowsocks2022ServerKey(1700000000, method)
+	uuid := "8f2b6c2e-1b7a-4c61-9d0e-3f5a7b9c1d2e"
+	inbound := InboundConfig{
+		Tag:        "stls-in",
+		Type:       "shadowtls",
+		ListenPort: 443,
+		ShadowTLS: &ShadowTLSConfig{
+			Version:   3,
+			Method:    method,
+			ServerKey: serverKey,
+			Handshake: &HandshakeConfig{Server: "www.example.com"},
+		},
+	}
+	users := []UserConfig{
+		{Email: "a@example.com", UUID: uuid, Enabled: true},
+		{Email: "b@example.com", UUID: "disabled", Enabled: false},
+	}
+
+	result := buildSingBoxShadowTLSInbounds(inbound, users)
+	if len(result) != 2 {
+		t.Fatalf("got %d inbounds, want shadowtls + shadowsocks", len(result))
+	}
+	shadowTLS, handler := result[0], result[1]
+
+	tlsUsers, _ := shadowTLS["users"].([]map[string]interface{})
+	if len(tlsUsers) != 1 || tlsUsers[0]["password"] != uuid {
+		t.Fatalf("shadowtls users = %v, want uuid password", tlsUsers)
+	}
+	if handler["password"] != serverKey {
+		t.Fatalf("handler password = %v, want server psk %q", handler["password"], serverKey)
+	}
+	ssUsers, _ := handler["users"].([]map[string]interface{})
+	if len(ssUsers) != 1 || ssUsers[0]["password"] != Shadowsocks2022UserKey(uuid, method) {
+		t.Fatalf("shadowsocks users = %v, want base64 user key", ssUsers)
+	}
+}
@@ -132,10 +132,11 @@ func buildSingBoxInbound(inbound UnifiedInbound) map[string]any {
 		result["listen_port"] = inbound.Port
 	}
 
-	// 判断是否启用 Reality（用于 Flow 语义清洗）
+	// 判断是否启用 Reality（用于 Flow 语义清洗），vision 仅适用于 TCP 传输
 	hasReality := inbound.TLS != nil &&
 		inbound.TLS.Reality != nil &&
-		inbound.TLS.Reality.Enabled
+		inbound.TLS.Reality.Enabled &&
+		isTCPTransport(inbound.Transport)
 
 	if len(inbound.Users) > 0 {
 		users := make([]map[string]any, 0, len(inbound.Users))
@@ -145,7 +146,8 @@ func buildSingBoxInbound(inbound UnifiedInbound) map[string]any {
 		result["users"] = users
 	}
 
-	if inbound.Transport != nil {
+	// sing-box 没有 tcp 传输类型，未配置 transport 即为原始 TCP
+	if inbound.Transport != nil && !isTCPTransport(inbound.Transport) {
 		transport := map[string]any{
 			"type": inbound.Transport.Type,
 		}
@@ -180,6 +182,10 @@ func buildSingBoxInbound(inbound UnifiedInbound) map[string]any {
 			tls["alpn"] = inbound.TLS.ALPN
 		}
 		if inbound.TLS.Reality != nil && inbound.TLS.Reality.Enabled {
+			// sing-box 入站 Reality 的 SNI 位于 tls.server_name，且只接受单个，取数组首个
+			if _, ok := tls["server_name"]; !ok && len(inbound.TLS.Reality.ServerNames) > 0 {
+				tls["server_name"] = inbound.TLS.Reality.ServerNames[0]
+			}
 			reality := map[string]any{
 				"enabled": true,
 			}
@@ -189,10 +195,6 @@ func buildSingBoxInbound(inbound UnifiedInbound) map[string]any {
 			if inbound.TLS.Reality.PublicKey != "" {
 				reality["public_key"] = inbound.TLS.Reality.PublicKey
 			}
-			// sing-box 只接受单个 server_name，取数组首个
-			if len(inbound.TLS.Reality.ServerNames) > 0 {
-				reality["server_name"] = inbound.TLS.Reality.ServerNames[0]
-			}
 			if inbound.TLS.Reality.Fingerprint != "" {
 				reality["fingerprint"] = inbound.TLS.Reality.Fingerprint
 			}
@@ -213,6 +215,23 @@ func buildSingBoxInbound(inbound UnifiedInbound) map[string]any {
 		result["tls"] = tls
 	}
 
+	if inbound.Multiplex != nil && inbound.Multiplex.Enabled {
+		multiplex := map[string]any{
+			"enabled": true,
+		}
+		if inbound.Multiplex.Padding {
+			multiplex["padding"] = true
+		}
+		if inbound.Multiplex.Brutal != nil && inbound.Multiplex.Brutal.Enabled {
+			multiplex["brutal"] = map[string]any{
+				"enabled":   true,
+				"up_mbps":   inbound.Multiplex.Brutal.UpMbps,
+				"down_mbps": inbound.Multiplex.Brutal.DownMbps,
+			}
+		}
+		result["multiplex"] = multiplex
+	}
+
 	if len(inbound.Options) > 0 {
 		if value, ok := inbound.Options["method"]; ok {
 			result["method"] = value
@@ -321,9 +340,11 @@ func parseSingBoxInbound(inbound singBoxInbound) UnifiedInbound {
 				PrivateKey:  inbound.TLS.Reality.PrivateKey,
 				Fingerprint: inbound.TLS.Reality.Fingerprint,
 			}
-			// sing-box 只支持单个 ServerName，转为数组
+			// sing-box 只支持单个 ServerName，转为数组；兼容旧版写在 reality 内的 server_name
 			if inbound.TLS.Reality.ServerName != "" {
 				reality.ServerNames = []string{inbound.TLS.Reality.ServerName}
+			} else if inbound.TLS.ServerName != "" {
+				reality.ServerNames = []string{inbound.TLS.ServerName}
 			}
 			if inbound.TLS.Reality.Handshake != nil {
 				reality.HandshakeServer = inbound.TLS.Reality.Handshake.Server
@@ -347,6 +368,20 @@ func parseSingBoxInbound(inbound singBoxInbound) UnifiedInbound {
 		result.Users = users
 	}
 
+	if inbound.Multiplex != nil && inbound.Multiplex.Enabled {
+		result.Multiplex = &UnifiedMultiplex{
+			Enabled: true,
+			Padding: inbound.Multiplex.Padding,
+		}
+		if inbound.Multiplex.Brutal != nil && inbound.Multiplex.Brutal.Enabled {
+			result.Multiplex.Brutal = &UnifiedBrutal{
+				Enabled:  true,
+				UpMbps:   inbound.Multiplex.Brutal.UpMbps,
+				DownMbps: inbound.Multiplex.Brutal.DownMbps,
+			}
+		}
+	}
+
 	options := map[string]any{}
 	if inbound.Method != "" {
 		options["method"] = inbound.Method
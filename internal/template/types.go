@@ -30,7 +30,7 @@ type TemplateContext struct {
 
 // InboundConfig 表示单个入站监听配置。
 type InboundConfig struct {
-	Type       string `json:"type"`        // vless, vmess, shadowsocks, trojan, hysteria2, tuic
+	Type       string `json:"type"`        // vless, vmess, shadowsocks, trojan, hysteria2, tuic, anytls, shadowtls, naive, mieru
 	Tag        string `json:"tag"`         // Inbound 标签
 	Listen     string `json:"listen"`      // 监听地址
 	ListenPort int    `json:"listen_port"` // 监听端口
@@ -47,9 +47,15 @@ type InboundConfig struct {
 	// Multiplex 复用配置
 	Multiplex *MultiplexConfig `json:"multiplex,omitempty"`
 
+	// ShadowTLS 配置（仅 type=shadowtls 时使用）
+	ShadowTLS *ShadowTLSConfig `json:"shadowtls,omitempty"`
+
 	// Options 协议相关选项
 	Options map[string]interface{} `json:"options,omitempty"`
 
+	// Encryption VLESS 加密参数（Xray decryption），为空时输出 none
+	Encryption string `json:"encryption,omitempty"`
+
 	// RequiredCapabilities 为该入站所需能力（不序列化，仅用于过滤）
 	RequiredCapabilities []string `json:"-"`
 }
@@ -59,6 +65,7 @@ type InboundUser struct {
 	UUID     string `json:"uuid,omitempty"`
 	Name     string `json:"name,omitempty"`     // 通常为邮箱
 	Password string `json:"password,omitempty"` // 用于 SS/Trojan/Hysteria2
+	Username string `json:"username,omitempty"` // 用于 naive basic-auth
 	Flow     string `json:"flow,omitempty"`     // 用于 VLESS（xtls-rprx-vision）
 	Method   string `json:"method,omitempty"`   // 用于 Shadowsocks 加密方式
 }
@@ -74,7 +81,7 @@ type UserConfig struct {
 	ID          int64  `json:"id"`
 	UUID        string `json:"uuid"`
 	Email       string `json:"email"`
-	SpeedLimit  int64  `json:"speed_limit,omitempty"`
+	SpeedLimit  int64  `json:"speed_limit,omitempty"` // 单位 Mbps，0 表示不限速。sing-box 与 xray 都不支持该限制（入站不输出、Agent 不执行），仅供自定义模板引用
 	DeviceLimit int64  `json:"device_limit,omitempty"`
 	Enabled     bool   `json:"enabled"`
 }
@@ -134,6 +141,14 @@ type TLSConfig struct {
 	Certificate string         `json:"certificate,omitempty"` // 证书路径
 	Key         string         `json:"key,omitempty"`         // 密钥路径
 	Reality     *RealityConfig `json:"reality,omitempty"`
+	ECH         *ECHConfig     `json:"ech,omitempty"`
+}
+
+// ECHConfig 表示 Encrypted Client Hello 配置（sing-box TLS 入站）。
+type ECHConfig struct {
+	Enabled            bool     `json:"enabled"`
+	Key                []string `json:"key,omitempty"`                          // ECH 密钥（PEM 行）
+	PQSignatureSchemes bool     `json:"pq_signature_schemes_enabled,omitempty"` // 启用后量子签名算法
 }
 
 // RealityConfig 表示 XTLS Reality 配置。
@@ -151,6 +166,18 @@ type HandshakeConfig struct {
 	ServerPort int    `json:"server_port"`
 }
 
+// ShadowTLSConfig 表示 ShadowTLS 入站配置。
+// v3 使用用户级密码，v2 使用单一 Password；内部 shadowsocks 处理入站的加密方式由 Method 指定。
+type ShadowTLSConfig struct {
+	Version    int              `json:"version"`
+	Password   string           `json:"password,omitempty"`
+	Handshake  *HandshakeConfig `json:"handshake,omitempty"`
+	StrictMode bool             `json:"strict_mode,omitempty"`
+	Method     string           `json:"method,omitempty"`
+	// ServerKey 为 2022-blake3 加密方式的服务端 PSK，由面板按节点派生，与订阅中的客户端密码一致
+	ServerKey string `json:"server_key,omitempty"`
+}
+
 // MultiplexConfig 表示复用配置。
 type MultiplexConfig struct {
 	Enabled bool          `json:"enabled"`
@@ -191,10 +218,17 @@ type RouteConfig struct {
 	Final string      `json:"final,omitempty"`
 }
 
-// RouteRule 表示路由规则。
+// RouteRule 表示路由规则，匹配字段与 sing-box route.rules 保持一致。
 type RouteRule struct {
-	Inbound  []string `json:"inbound,omitempty"`
-	Outbound string   `json:"outbound,omitempty"`
+	Inbound       []string `json:"inbound,omitempty"`
+	Domain        []string `json:"domain,omitempty"`
+	DomainSuffix  []string `json:"domain_suffix,omitempty"`
+	DomainKeyword []string `json:"domain_keyword,omitempty"`
+	DomainRegex   []string `json:"domain_regex,omitempty"`
+	IPCIDR        []string `json:"ip_cidr,omitempty"`
+	Geosite       []string `json:"geosite,omitempty"`
+	GeoIP         []string `json:"geoip,omitempty"`
+	Outbound      string   `json:"outbound,omitempty"`
 }
 
 // ExperimentalConfig 表示实验特性配置。
@@ -233,6 +267,11 @@ const (
 	CapDHCP      Capability = "dhcp"
 	CapGeoIP     Capability = "geoip"
 	CapGeoSite   Capability = "geosite"
+	CapRuleSet   Capability = "rule_set"  // sing-box 远程/本地 rule-set
+	CapAnyTLS    Capability = "anytls"    // sing-box AnyTLS 入站
+	CapShadowTLS Capability = "shadowtls" // sing-box ShadowTLS v3 入站
+	CapMieru     Capability = "mieru"     // 独立 mieru 服务端（mita），由 Agent 探测上报
+	CapNaive     Capability = "naive"     // sing-box naive（需要 with_naive_outbound build tag）
 
 	// Xray 专属能力
 	CapXTLS       Capability = "xtls"       // XTLS 流控
@@ -248,10 +287,13 @@ var SingBoxVersionRequirements = map[Capability]string{
 	CapReality:   "1.3.0",
 	CapMultiplex: "1.3.0",
 	CapBrutal:    "1.7.0",
-	CapECH:       "1.8.0",
+	CapECH:       "1.9.0", // TLS 入站 ECH 自 1.9.0 起可用
 	CapV2RayAPI:  "1.0.0", // 需要 build tag
 	CapQUIC:      "1.0.0",
 	CapHTTP3:     "1.8.0",
+	CapRuleSet:   "1.8.0",  // rule-set 自 1.8.0 起可用，取代 geosite/geoip 数据库
+	CapAnyTLS:    "1.12.0", // AnyTLS 入站自 1.12.0 起可用
+	CapShadowTLS: "1.2.0",  // ShadowTLS v3 自 1.2.0 起可用
 }
 
 // XrayVersionRequirements 记录能力所需的最低 Xray 版本。
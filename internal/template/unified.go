@@ -9,6 +9,7 @@ type UnifiedInbound struct {
 	Transport *UnifiedTransport `json:"transport,omitempty"`
 	TLS       *UnifiedTLS       `json:"tls,omitempty"`
 	Users     []UnifiedUser     `json:"users,omitempty"`
+	Multiplex *UnifiedMultiplex `json:"multiplex,omitempty"`
 	Options   map[string]any    `json:"options,omitempty"`
 }
 
@@ -20,6 +21,7 @@ type UnifiedTransport struct {
 	ServiceName string            `json:"service_name,omitempty"`
 	Headers     map[string]string `json:"headers,omitempty"`
 	Mode        string            `json:"mode,omitempty"`
+	MultiMode   bool              `json:"multi_mode,omitempty"` // 仅 Xray gRPC 支持
 	XHTTP       *XHTTPConfig      `json:"xhttp,omitempty"`
 }
 
@@ -45,6 +47,20 @@ type UnifiedReality struct {
 	Fingerprint     string   `json:"fingerprint,omitempty"`
 }
 
+// UnifiedMultiplex 描述入站多路复用配置（sing-box 专有，Xray 入站自动接受 mux.cool）。
+type UnifiedMultiplex struct {
+	Enabled bool           `json:"enabled"`
+	Padding bool           `json:"padding,omitempty"`
+	Brutal  *UnifiedBrutal `json:"brutal,omitempty"`
+}
+
+// UnifiedBrutal 描述 TCP Brutal 拥塞控制配置。
+type UnifiedBrutal struct {
+	Enabled  bool `json:"enabled"`
+	UpMbps   int  `json:"up_mbps,omitempty"`
+	DownMbps int  `json:"down_mbps,omitempty"`
+}
+
 // UnifiedUser 描述跨核心的用户配置。
 type UnifiedUser struct {
 	UUID     string `json:"uuid,omitempty"`
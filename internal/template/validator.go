@@ -3,24 +3,56 @@ package template
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 )
 
+// 校验问题严重级别。
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// ValidationIssue 描述一条可定位的校验问题，Path 为指向生成配置的 JSON Pointer（RFC 6901）。
+// Rule 与 Fix 仅由 Linter 填写，分别为规则标识和修复建议。
+type ValidationIssue struct {
+	Path     string `json:"path"`
+	Message  string `json:"message"`
+	Severity string `json:"severity"`
+	Rule     string `json:"rule,omitempty"`
+	Fix      string `json:"fix,omitempty"`
+}
+
 // ValidationResult 包含校验结果。
 type ValidationResult struct {
-	Valid    bool     `json:"valid"`
-	Errors   []string `json:"errors,omitempty"`
-	Warnings []string `json:"warnings,omitempty"`
+	Valid    bool              `json:"valid"`
+	Errors   []string          `json:"errors,omitempty"`
+	Warnings []string          `json:"warnings,omitempty"`
+	Issues   []ValidationIssue `json:"issues,omitempty"`
 }
 
 // AddError 添加错误并标记为无效。
 func (r *ValidationResult) AddError(format string, args ...interface{}) {
-	r.Valid = false
-	r.Errors = append(r.Errors, fmt.Sprintf(format, args...))
+	r.AddErrorAt("", format, args...)
 }
 
 // AddWarning 添加告警信息。
 func (r *ValidationResult) AddWarning(format string, args ...interface{}) {
-	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+	r.AddWarningAt("", format, args...)
+}
+
+// AddErrorAt 在指定 JSON Pointer 处添加错误并标记为无效。
+func (r *ValidationResult) AddErrorAt(path string, format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	r.Valid = false
+	r.Errors = append(r.Errors, message)
+	r.Issues = append(r.Issues, ValidationIssue{Path: path, Message: message, Severity: SeverityError})
+}
+
+// AddWarningAt 在指定 JSON Pointer 处添加告警。
+func (r *ValidationResult) AddWarningAt(path string, format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	r.Warnings = append(r.Warnings, message)
+	r.Issues = append(r.Issues, ValidationIssue{Path: path, Message: message, Severity: SeverityWarning})
 }
 
 // Merge 合并另一个结果。
@@ -30,6 +62,46 @@ func (r *ValidationResult) Merge(other *ValidationResult) {
 	}
 	r.Errors = append(r.Errors, other.Errors...)
 	r.Warnings = append(r.Warnings, other.Warnings...)
+	r.Issues = append(r.Issues, other.Issues...)
+}
+
+// HasIssueErrors 判断问题列表中是否包含错误级别的问题。
+func HasIssueErrors(issues []ValidationIssue) bool {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// FlattenIssues 将指定级别的问题展开为字符串，供只关心文本的旧调用方使用；severity 为空表示全部。
+func FlattenIssues(issues []ValidationIssue, severity string) []string {
+	result := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		if severity != "" && issue.Severity != severity {
+			continue
+		}
+		if issue.Path == "" {
+			result = append(result, issue.Message)
+			continue
+		}
+		result = append(result, fmt.Sprintf("%s: %s", issue.Path, issue.Message))
+	}
+	return result
+}
+
+// jsonPointer 按 RFC 6901 拼接 JSON Pointer，对 "~" 与 "/" 做转义。
+func jsonPointer(tokens ...interface{}) string {
+	var sb strings.Builder
+	for _, token := range tokens {
+		segment := fmt.Sprint(token)
+		segment = strings.ReplaceAll(segment, "~", "~0")
+		segment = strings.ReplaceAll(segment, "/", "~1")
+		sb.WriteString("/")
+		sb.WriteString(segment)
+	}
+	return sb.String()
 }
 
 // Validator 负责模板与配置校验。
@@ -72,6 +144,8 @@ func (v *Validator) ValidateTemplate(content string, templateType string) *Valid
 		v.validateSingBoxConfig(parsed, result)
 	case "xray":
 		v.validateXrayConfig(parsed, result)
+	case "mieru":
+		v.validateMieruConfig(parsed, result)
 	default:
 		result.AddWarning("Unknown template type '%s', skipping type-specific validation", templateType)
 	}
@@ -89,16 +163,16 @@ func (v *Validator) validateSingBoxConfig(parsed interface{}, result *Validation
 
 	// 检查必需部分
 	if _, ok := config["inbounds"]; !ok {
-		result.AddWarning("Missing 'inbounds' section - will be injected by system if using dynamic mode")
+		result.AddWarningAt(jsonPointer("inbounds"), "Missing 'inbounds' section - will be injected by system if using dynamic mode")
 	}
 
 	if _, ok := config["outbounds"]; !ok {
-		result.AddWarning("Missing 'outbounds' section - recommend adding at least 'direct' and 'block'")
+		result.AddWarningAt(jsonPointer("outbounds"), "Missing 'outbounds' section - recommend adding at least 'direct' and 'block'")
 	}
 
 	// 检查日志部分
 	if _, ok := config["log"]; !ok {
-		result.AddWarning("Missing 'log' section - recommend adding for debugging")
+		result.AddWarningAt(jsonPointer("log"), "Missing 'log' section - recommend adding for debugging")
 	}
 
 	// Validate inbounds structure
@@ -120,17 +194,17 @@ func (v *Validator) validateSingBoxConfig(parsed interface{}, result *Validation
 func (v *Validator) validateSingBoxInbound(inbound interface{}, index int, result *ValidationResult) {
 	ib, ok := inbound.(map[string]interface{})
 	if !ok {
-		result.AddError("Inbound %d: must be a JSON object", index)
+		result.AddErrorAt(jsonPointer("inbounds", index), "Inbound %d: must be a JSON object", index)
 		return
 	}
 
 	// Check required fields
 	if _, hasType := ib["type"]; !hasType {
-		result.AddError("Inbound %d: missing 'type' field", index)
+		result.AddErrorAt(jsonPointer("inbounds", index, "type"), "Inbound %d: missing 'type' field", index)
 	}
 
 	if _, hasTag := ib["tag"]; !hasTag {
-		result.AddError("Inbound %d: missing 'tag' field", index)
+		result.AddErrorAt(jsonPointer("inbounds", index, "tag"), "Inbound %d: missing 'tag' field", index)
 	}
 
 	// Validate type-specific requirements
@@ -139,7 +213,7 @@ func (v *Validator) validateSingBoxInbound(inbound interface{}, index int, resul
 		case "vless", "vmess", "trojan":
 			// These require users or fallback
 			if _, hasUsers := ib["users"]; !hasUsers {
-				result.AddWarning("Inbound %d (%s): no 'users' defined - ensure users are injected", index, ibType)
+				result.AddWarningAt(jsonPointer("inbounds", index, "users"), "Inbound %d (%s): no 'users' defined - ensure users are injected", index, ibType)
 			}
 		case "shadowsocks":
 			// Check for method
@@ -147,13 +221,43 @@ func (v *Validator) validateSingBoxInbound(inbound interface{}, index int, resul
 				if users, hasUsers := ib["users"].([]interface{}); hasUsers && len(users) > 0 {
 					// Check if users have method
 				} else {
-					result.AddWarning("Inbound %d (shadowsocks): consider specifying 'method' for cipher", index)
+					result.AddWarningAt(jsonPointer("inbounds", index, "method"), "Inbound %d (shadowsocks): consider specifying 'method' for cipher", index)
 				}
 			}
 		case "hysteria2", "tuic":
 			// Check for TLS
 			if _, hasTLS := ib["tls"]; !hasTLS {
-				result.AddWarning("Inbound %d (%s): typically requires 'tls' configuration", index, ibType)
+				result.AddWarningAt(jsonPointer("inbounds", index, "tls"), "Inbound %d (%s): typically requires 'tls' configuration", index, ibType)
+			}
+		case "shadowtls":
+			// ShadowTLS 仅负责握手伪装，需要 detour 到实际处理入站
+			if _, hasDetour := ib["detour"]; !hasDetour {
+				result.AddWarningAt(jsonPointer("inbounds", index, "detour"), "Inbound %d (shadowtls): missing 'detour' to the handling inbound", index)
+			}
+		case "anytls":
+			// AnyTLS 必须运行在 TLS 之上
+			tls, _ := ib["tls"].(map[string]interface{})
+			if enabled, _ := tls["enabled"].(bool); !enabled {
+				result.AddWarningAt(jsonPointer("inbounds", index, "tls"), "Inbound %d (anytls): requires an enabled 'tls' block", index)
+			}
+			if _, hasUsers := ib["users"]; !hasUsers {
+				result.AddWarningAt(jsonPointer("inbounds", index, "users"), "Inbound %d (anytls): no 'users' defined - ensure users are injected", index)
+			}
+		case "naive":
+			// naive 协议强制 TLS，缺失时 sing-box 拒绝启动
+			tls, _ := ib["tls"].(map[string]interface{})
+			if enabled, _ := tls["enabled"].(bool); !enabled {
+				result.AddErrorAt(jsonPointer("inbounds", index, "tls"), "Inbound %d (naive): requires an enabled 'tls' block", index)
+			}
+			if _, hasUsers := ib["users"]; !hasUsers {
+				result.AddWarningAt(jsonPointer("inbounds", index, "users"), "Inbound %d (naive): no 'users' defined - ensure users are injected", index)
+			}
+		case "socks", "http":
+			// 无认证的 socks/http 一旦监听公网即成为开放代理
+			users, _ := ib["users"].([]interface{})
+			listen, _ := ib["listen"].(string)
+			if len(users) == 0 && !IsLoopbackListen(listen) {
+				result.AddWarningAt(jsonPointer("inbounds", index, "users"), "SECURITY: Inbound %d (%s) has no authentication and listens on %q - this is an open proxy; add users or listen on 127.0.0.1", index, ibType, listenOrAny(listen))
 			}
 		}
 	}
@@ -163,11 +267,11 @@ func (v *Validator) validateSingBoxInbound(inbound interface{}, index int, resul
 		switch p := port.(type) {
 		case float64:
 			if p <= 0 || p > 65535 {
-				result.AddError("Inbound %d: invalid port %v", index, port)
+				result.AddErrorAt(jsonPointer("inbounds", index, "listen_port"), "Inbound %d: invalid port %v", index, port)
 			}
 		case int:
 			if p <= 0 || p > 65535 {
-				result.AddError("Inbound %d: invalid port %v", index, port)
+				result.AddErrorAt(jsonPointer("inbounds", index, "listen_port"), "Inbound %d: invalid port %v", index, port)
 			}
 		}
 	}
@@ -177,17 +281,17 @@ func (v *Validator) validateSingBoxInbound(inbound interface{}, index int, resul
 func (v *Validator) validateSingBoxOutbound(outbound interface{}, index int, result *ValidationResult) {
 	ob, ok := outbound.(map[string]interface{})
 	if !ok {
-		result.AddError("Outbound %d: must be a JSON object", index)
+		result.AddErrorAt(jsonPointer("outbounds", index), "Outbound %d: must be a JSON object", index)
 		return
 	}
 
 	// Check required fields
 	if _, hasType := ob["type"]; !hasType {
-		result.AddError("Outbound %d: missing 'type' field", index)
+		result.AddErrorAt(jsonPointer("outbounds", index, "type"), "Outbound %d: missing 'type' field", index)
 	}
 
 	if _, hasTag := ob["tag"]; !hasTag {
-		result.AddError("Outbound %d: missing 'tag' field", index)
+		result.AddErrorAt(jsonPointer("outbounds", index, "tag"), "Outbound %d: missing 'tag' field", index)
 	}
 }
 
@@ -201,11 +305,11 @@ func (v *Validator) validateXrayConfig(parsed interface{}, result *ValidationRes
 
 	// Check for required Xray sections
 	if _, ok := config["inbounds"]; !ok {
-		result.AddWarning("Missing 'inbounds' section - will be injected by system if using dynamic mode")
+		result.AddWarningAt(jsonPointer("inbounds"), "Missing 'inbounds' section - will be injected by system if using dynamic mode")
 	}
 
 	if _, ok := config["outbounds"]; !ok {
-		result.AddWarning("Missing 'outbounds' section - recommend adding at least 'freedom' and 'blackhole'")
+		result.AddWarningAt(jsonPointer("outbounds"), "Missing 'outbounds' section - recommend adding at least 'freedom' and 'blackhole'")
 	}
 
 	// Validate inbounds structure
@@ -220,34 +324,89 @@ func (v *Validator) validateXrayConfig(parsed interface{}, result *ValidationRes
 func (v *Validator) validateXrayInbound(inbound interface{}, index int, result *ValidationResult) {
 	ib, ok := inbound.(map[string]interface{})
 	if !ok {
-		result.AddError("Inbound %d: must be a JSON object", index)
+		result.AddErrorAt(jsonPointer("inbounds", index), "Inbound %d: must be a JSON object", index)
 		return
 	}
 
 	// Check Xray-specific required fields
 	if _, hasProtocol := ib["protocol"]; !hasProtocol {
-		result.AddError("Inbound %d: missing 'protocol' field", index)
+		result.AddErrorAt(jsonPointer("inbounds", index, "protocol"), "Inbound %d: missing 'protocol' field", index)
 	}
 
 	if _, hasTag := ib["tag"]; !hasTag {
-		result.AddError("Inbound %d: missing 'tag' field", index)
+		result.AddErrorAt(jsonPointer("inbounds", index, "tag"), "Inbound %d: missing 'tag' field", index)
 	}
 
 	// Check for settings
 	if _, hasSettings := ib["settings"]; !hasSettings {
-		result.AddWarning("Inbound %d: missing 'settings' - ensure it's injected or defined", index)
+		result.AddWarningAt(jsonPointer("inbounds", index, "settings"), "Inbound %d: missing 'settings' - ensure it's injected or defined", index)
+	}
+
+	// 无认证的 socks/http 一旦监听公网即成为开放代理（Xray 缺省 listen 为 0.0.0.0）
+	if protocol, _ := ib["protocol"].(string); protocol == "socks" || protocol == "http" {
+		settings, _ := ib["settings"].(map[string]interface{})
+		accounts, _ := settings["accounts"].([]interface{})
+		listen, _ := ib["listen"].(string)
+		if len(accounts) == 0 && !IsLoopbackListen(listen) {
+			result.AddWarningAt(jsonPointer("inbounds", index, "settings", "accounts"), "SECURITY: Inbound %d (%s) has no authentication and listens on %q - this is an open proxy; add accounts or listen on 127.0.0.1", index, protocol, listenOrAny(listen))
+		}
+	}
+}
+
+// listenOrAny 返回用于提示的监听地址，空值表示监听全部地址。
+func listenOrAny(listen string) string {
+	if listen == "" {
+		return "all addresses"
 	}
+	return listen
 }
 
-// ValidateFinalConfig validates a fully rendered configuration.
-func (v *Validator) ValidateFinalConfig(configJSON []byte, configType string) *ValidationResult {
+// validateMieruConfig 校验 mieru 服务端（mita）配置结构。
+func (v *Validator) validateMieruConfig(parsed interface{}, result *ValidationResult) {
+	config, ok := parsed.(map[string]interface{})
+	if !ok {
+		result.AddError("Config must be a JSON object")
+		return
+	}
+
+	bindings, _ := config["portBindings"].([]interface{})
+	if len(bindings) == 0 {
+		result.AddErrorAt(jsonPointer("portBindings"), "Missing 'portBindings' - mieru requires at least one port binding")
+	}
+	for i, raw := range bindings {
+		binding, ok := raw.(map[string]interface{})
+		if !ok {
+			result.AddErrorAt(jsonPointer("portBindings", i), "Port binding %d: must be a JSON object", i)
+			continue
+		}
+		_, hasPort := binding["port"]
+		_, hasRange := binding["portRange"]
+		if !hasPort && !hasRange {
+			result.AddErrorAt(jsonPointer("portBindings", i), "Port binding %d: 'port' or 'portRange' is required", i)
+		}
+		if protocol, _ := binding["protocol"].(string); protocol != "TCP" && protocol != "UDP" {
+			result.AddErrorAt(jsonPointer("portBindings", i, "protocol"), "Port binding %d: 'protocol' must be TCP or UDP", i)
+		}
+	}
+
+	if _, hasUsers := config["users"]; !hasUsers {
+		result.AddWarningAt(jsonPointer("users"), "Missing 'users' - ensure users are injected")
+	}
+	if mtu, ok := config["mtu"].(float64); ok && (mtu < 1280 || mtu > 1500) {
+		result.AddErrorAt(jsonPointer("mtu"), "Invalid mtu %v - must be between 1280 and 1500", mtu)
+	}
+}
+
+// ValidateFinalConfig validates a fully rendered configuration and returns issues
+// located by JSON pointer. Use HasIssueErrors / FlattenIssues for plain checks.
+func (v *Validator) ValidateFinalConfig(configJSON []byte, configType string) []ValidationIssue {
 	result := &ValidationResult{Valid: true}
 
 	// Parse JSON
 	var parsed interface{}
 	if err := json.Unmarshal(configJSON, &parsed); err != nil {
 		result.AddError("Invalid JSON: %v", err)
-		return result
+		return result.Issues
 	}
 
 	// Validate based on type
@@ -256,11 +415,13 @@ func (v *Validator) ValidateFinalConfig(configJSON []byte, configType string) *V
 		v.validateSingBoxConfig(parsed, result)
 	case "xray":
 		v.validateXrayConfig(parsed, result)
+	case "mieru":
+		v.validateMieruConfig(parsed, result)
 	default:
 		result.AddWarning("Unknown config type '%s', skipping type-specific validation", configType)
 	}
 
-	return result
+	return result.Issues
 }
 
 // ValidateJSON validates that the content is valid JSON.
@@ -0,0 +1,69 @@
+package template
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSocksInboundAuthAndOpenProxyWarning(t *testing.T) {
+	build := DefaultFuncMap()["singboxInbound"].(func(InboundConfig, []UserConfig) map[string]interface{})
+	users := []UserConfig{{Email: "a@example.com", UUID: "uuid-a", Enabled: true}}
+
+	authed := build(InboundConfig{Type: "socks", Tag: "socks-in", ListenPort: 1080}, users)
+	list, _ := authed["users"].([]map[string]interface{})
+	if len(list) != 1 || list[0]["username"] != "a@example.com" || list[0]["password"] != "uuid-a" {
+		t.Fatalf("socks users = %v, want username/password entry", authed["users"])
+	}
+	if _, hasName := list[0]["name"]; hasName {
+		t.Fatalf("socks user must not carry 'name': %v", list[0])
+	}
+
+	tests := []struct {
+		name        string
+		inbound     InboundConfig
+		wantWarning bool
+	}{
+		{name: "authenticated public", inbound: InboundConfig{Type: "http", Tag: "http-in", ListenPort: 8080}},
+		{name: "open loopback", inbound: InboundConfig{Type: "socks", Tag: "relay", Listen: "127.0.0.1", ListenPort: 1080, Options: map[string]interface{}{"auth": "none"}}},
+		{name: "open public", inbound: InboundConfig{Type: "socks", Tag: "relay", ListenPort: 1080, Options: map[string]interface{}{"auth": "none"}}, wantWarning: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := map[string]interface{}{
+				"inbounds":  []interface{}{build(tt.inbound, users)},
+				"outbounds": []interface{}{map[string]interface{}{"type": "direct", "tag": "direct"}},
+			}
+			raw, err := json.Marshal(config)
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			warned := false
+			for _, issue := range NewValidator().ValidateFinalConfig(raw, "sing-box") {
+				if strings.HasPrefix(issue.Message, "SECURITY:") {
+					warned = true
+				}
+			}
+			if warned != tt.wantWarning {
+				t.Fatalf("open proxy warning = %v, want %v", warned, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestIsLoopbackListen(t *testing.T) {
+	for listen, want := range map[string]bool{
+		"127.0.0.1": true,
+		"::1":       true,
+		"[::1]":     true,
+		"localhost": true,
+		"":          false,
+		"::":        false,
+		"0.0.0.0":   false,
+		"10.0.0.1":  false,
+	} {
+		if got := IsLoopbackListen(listen); got != want {
+			t.Fatalf("IsLoopbackListen(%q) = %v, want %v", listen, got, want)
+		}
+	}
+}
@@ -116,6 +116,7 @@ type xrayWSSettings struct {
 
 type xrayGRPCSettings struct {
 	ServiceName string `json:"serviceName"`
+	MultiMode   bool   `json:"multiMode"`
 }
 
 type xrayHTTPSettings struct {
@@ -154,7 +155,7 @@ func buildXraySettings(protocol string, users []UnifiedUser, options map[string]
 	switch protocol {
 	case "vless":
 		settings["decryption"] = "none"
-		if clients := buildXrayClients(protocol, users, isVLESSXHTTPTransport(transport)); len(clients) > 0 {
+		if clients := buildXrayClients(protocol, users, !isTCPTransport(transport)); len(clients) > 0 {
 			settings["clients"] = clients
 		}
 	case "vmess":
@@ -238,8 +239,13 @@ func buildXrayClients(protocol string, users []UnifiedUser, omitDefaultVLESSFlow
 	return clients
 }
 
-func isVLESSXHTTPTransport(transport *UnifiedTransport) bool {
-	return transport != nil && IsXHTTPNetwork(transport.Type)
+// isTCPTransport 判断是否为原始 TCP 传输；xtls-rprx-vision 仅适用于 TCP，其余传输不补默认 flow。
+func isTCPTransport(transport *UnifiedTransport) bool {
+	if transport == nil {
+		return true
+	}
+	network := strings.ToLower(strings.TrimSpace(transport.Type))
+	return network == "" || network == "tcp" || network == "raw"
 }
 
 // buildXrayTransportStreamSettings 构建传输层 stream settings（不含 TLS）。
@@ -276,6 +282,9 @@ func buildXrayTransportStreamSettings(transport *UnifiedTransport) (map[string]a
 		if transport.ServiceName != "" {
 			grpcSettings["serviceName"] = transport.ServiceName
 		}
+		if transport.MultiMode {
+			grpcSettings["multiMode"] = true
+		}
 		if len(grpcSettings) > 0 {
 			streamSettings["grpcSettings"] = grpcSettings
 		}
@@ -420,6 +429,7 @@ func parseXrayTransport(ss *xrayStreamSettings) *UnifiedTransport {
 	}
 	if ss.GRPCSettings != nil {
 		transport.ServiceName = ss.GRPCSettings.ServiceName
+		transport.MultiMode = ss.GRPCSettings.MultiMode
 	}
 	if ss.HTTPSettings != nil {
 		transport.Path = ss.HTTPSettings.Path
@@ -0,0 +1,36 @@
+package tui
+
+import (
+	"errors"
+	"os"
+
+	"github.com/atotto/clipboard"
+)
+
+// errNoClipboard 表示当前环境没有可用的系统剪贴板（如无图形界面的服务器）
+var errNoClipboard = errors.New("no clipboard available")
+
+// writeClipboard 写入系统剪贴板（macOS、Windows，以及 Linux 下的 wl-copy/xclip/xsel），
+// 不可用或写入失败时返回 errNoClipboard
+func writeClipboard(text string) error {
+	if clipboard.Unsupported {
+		return errNoClipboard
+	}
+	if err := clipboard.WriteAll(text); err != nil {
+		return errors.Join(errNoClipboard, err)
+	}
+	return nil
+}
+
+// writeClipboardFallback 将内容写入临时文件并返回路径，供无剪贴板环境使用
+func writeClipboardFallback(text string) (string, error) {
+	f, err := os.CreateTemp("", "xboard-tui-copy-*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.WriteString(text + "\n"); err != nil {
+		return "", err
+	}
+	return f.Name(), nil
+}
@@ -0,0 +1,154 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// copyStatusDuration 是复制结果提示的显示时长
+const copyStatusDuration = 3 * time.Second
+
+// copyField 是详情视图中可复制的字段
+type copyField struct {
+	label string
+	value string
+}
+
+// copyResultMsg 是复制命令完成后的结果
+type copyResultMsg struct {
+	label string
+	path  string // 无剪贴板时写入的临时文件路径
+	err   error
+}
+
+// clearCopyStatusMsg 用于清除过期的复制提示
+type clearCopyStatusMsg struct {
+	id int
+}
+
+// copyableFields 返回当前节点可复制的字段，首项为汇总块；UUID 使用未脱敏的原值
+func (m Model) copyableFields() []copyField {
+	if m.detailNode == nil || m.detailNode.Server == nil {
+		return nil
+	}
+	srv := m.detailNode.Server
+
+	var fields []copyField
+	add := func(label, value string) {
+		if strings.TrimSpace(value) != "" {
+			fields = append(fields, copyField{label: label, value: value})
+		}
+	}
+
+	host := srv.Host
+	port := srv.Port
+	var detail *ProtocolDetail
+	var details []ProtocolDetail
+	if len(srv.Settings) > 0 && json.Unmarshal(srv.Settings, &details) == nil && len(details) > 0 {
+		detail = &details[0]
+		if detail.Port > 0 {
+			port = detail.Port
+		}
+	}
+
+	add("Host", host)
+	if port > 0 {
+		add("Port", fmt.Sprintf("%d", port))
+	}
+	if detail != nil {
+		for _, user := range detail.Users {
+			if user.UUID != "" {
+				add("UUID", user.UUID)
+				break
+			}
+		}
+		if detail.TLS != nil && detail.TLS.Reality != nil && detail.TLS.Reality.Enabled {
+			reality := detail.TLS.Reality
+			add("Public Key", reality.PublicKey)
+			if reality.HandshakeAddr != "" {
+				handshake := reality.HandshakeAddr
+				if reality.HandshakePort > 0 {
+					handshake = fmt.Sprintf("%s:%d", reality.HandshakeAddr, reality.HandshakePort)
+				}
+				add("Handshake", handshake)
+			}
+			add("SNI", reality.ServerName)
+			add("Short IDs", strings.Join(reality.ShortIDs, ","))
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+	var summary []string
+	summary = append(summary, fmt.Sprintf("Protocol: %s", getProtocolDisplayName(srv)))
+	for _, f := range fields {
+		summary = append(summary, fmt.Sprintf("%s: %s", f.label, f.value))
+	}
+	return append([]copyField{{label: "Summary", value: strings.Join(summary, "\n")}}, fields...)
+}
+
+// selectedCopyField 返回当前高亮的可复制字段
+func (m Model) selectedCopyField() (copyField, bool) {
+	fields := m.copyableFields()
+	if len(fields) == 0 {
+		return copyField{}, false
+	}
+	return fields[clampIndex(m.copyFieldIndex, len(fields))], true
+}
+
+// nextCopyField 切换到下一个可复制字段
+func (m Model) nextCopyField() (tea.Model, tea.Cmd) {
+	if count := len(m.copyableFields()); count > 0 {
+		m.copyFieldIndex = (clampIndex(m.copyFieldIndex, count) + 1) % count
+	}
+	return m, nil
+}
+
+// copySelectedField 异步复制当前字段，剪贴板不可用时写入临时文件
+func (m Model) copySelectedField() (tea.Model, tea.Cmd) {
+	field, ok := m.selectedCopyField()
+	if !ok {
+		return m, nil
+	}
+	return m, func() tea.Msg {
+		if err := writeClipboard(field.value); err == nil {
+			return copyResultMsg{label: field.label}
+		}
+		path, err := writeClipboardFallback(field.value)
+		return copyResultMsg{label: field.label, path: path, err: err}
+	}
+}
+
+// handleCopyResult 显示复制结果并在一段时间后清除
+func (m Model) handleCopyResult(msg copyResultMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.err != nil:
+		m.copyStatus = fmt.Sprintf("Copy %s failed: %v", msg.label, msg.err)
+	case msg.path != "":
+		m.copyStatus = fmt.Sprintf("No clipboard available, %s written to %s", msg.label, msg.path)
+	default:
+		m.copyStatus = fmt.Sprintf("Copied %s to clipboard", msg.label)
+	}
+	m.copyStatusID++
+	id := m.copyStatusID
+	return m, tea.Tick(copyStatusDuration, func(time.Time) tea.Msg {
+		return clearCopyStatusMsg{id: id}
+	})
+}
+
+// renderCopyLine 渲染复制提示或当前可复制字段
+func (m Model) renderCopyLine() string {
+	if m.copyStatus != "" {
+		return styleOnline.Render("  " + m.copyStatus)
+	}
+	field, ok := m.selectedCopyField()
+	if !ok {
+		return ""
+	}
+	return styleMuted().Render("  Copy target: " + field.label + "  [Tab] Next  [c] Copy")
+}
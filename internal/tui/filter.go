@@ -0,0 +1,124 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// visibleHosts 返回按过滤关键字筛选后的服务器列表，匹配名称或地址（忽略大小写）
+func (m Model) visibleHosts() []HostInfo {
+	query := strings.ToLower(strings.TrimSpace(m.filterQuery))
+	if query == "" {
+		return m.hosts
+	}
+	result := make([]HostInfo, 0, len(m.hosts))
+	for _, h := range m.hosts {
+		if containsFold(h.Host.Name, query) || containsFold(h.Host.Host, query) {
+			result = append(result, h)
+		}
+	}
+	return result
+}
+
+// visibleNodes 返回按过滤关键字筛选后的节点列表，匹配展示名、配置名或地址
+func (m Model) visibleNodes() []NodeInfo {
+	query := strings.ToLower(strings.TrimSpace(m.filterQuery))
+	if query == "" {
+		return m.nodes
+	}
+	result := make([]NodeInfo, 0, len(m.nodes))
+	for _, n := range m.nodes {
+		if containsFold(getProtocolDisplayName(n.Server), query) ||
+			containsFold(n.Server.Name, query) ||
+			containsFold(n.Server.Host, query) {
+			result = append(result, n)
+		}
+	}
+	return result
+}
+
+func containsFold(s, lowerQuery string) bool {
+	return strings.Contains(strings.ToLower(s), lowerQuery)
+}
+
+// selectedNodeID 返回当前选中节点的 ID
+func (m Model) selectedNodeID() (int64, bool) {
+	nodes := m.visibleNodes()
+	if m.selectedNode < 0 || m.selectedNode >= len(nodes) {
+		return 0, false
+	}
+	return nodes[m.selectedNode].Server.ID, true
+}
+
+// selectNodeByID 在可见列表中定位指定 ID 的节点，刷新后保持光标停在同一节点
+func (m *Model) selectNodeByID(id int64) {
+	for i, n := range m.visibleNodes() {
+		if n.Server.ID == id {
+			m.selectedNode = i
+			return
+		}
+	}
+}
+
+// clampSelection 在列表变短（过滤或刷新）后把选中下标限制在有效范围内
+func (m *Model) clampSelection() {
+	m.selectedHost = clampIndex(m.selectedHost, len(m.visibleHosts()))
+	m.selectedNode = clampIndex(m.selectedNode, len(m.visibleNodes()))
+}
+
+func clampIndex(idx, length int) int {
+	if length == 0 || idx < 0 {
+		return 0
+	}
+	if idx >= length {
+		return length - 1
+	}
+	return idx
+}
+
+// clearFilter 关闭输入行并清空过滤关键字
+func (m *Model) clearFilter() {
+	m.filtering = false
+	m.filterQuery = ""
+	m.clampSelection()
+}
+
+// handleFilterInput 处理过滤输入行打开时的按键：Enter 保留过滤，Esc 清空
+func (m Model) handleFilterInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.clearFilter()
+	case tea.KeyEnter:
+		m.filtering = false
+	case tea.KeyBackspace:
+		if runes := []rune(m.filterQuery); len(runes) > 0 {
+			m.filterQuery = string(runes[:len(runes)-1])
+			m.clampSelection()
+		}
+	case tea.KeyUp:
+		return m.handleUp()
+	case tea.KeyDown:
+		return m.handleDown()
+	case tea.KeySpace:
+		m.filterQuery += " "
+		m.clampSelection()
+	case tea.KeyRunes:
+		m.filterQuery += string(msg.Runes)
+		m.clampSelection()
+	}
+	return m, nil
+}
+
+// renderFilterLine 渲染过滤输入行；未过滤且未打开输入时返回空串
+func (m Model) renderFilterLine() string {
+	if !m.filtering && m.filterQuery == "" {
+		return ""
+	}
+	if m.filtering {
+		return styleValue.Render("  / "+m.filterQuery+"█") + styleMuted().Render("  [Enter] Apply  [Esc] Clear") + "\n\n"
+	}
+	return styleMuted().Render("  Filter: "+m.filterQuery+"  [/] Edit  [Esc] Clear") + "\n\n"
+}
@@ -23,10 +23,12 @@ const (
 // HostStatus 表示服务器在线状态
 type HostStatus string
 
+// 与 repository.AgentHost.DisplayState 的取值保持一致，Prometheus 指标也使用同一套阈值
 const (
-	StatusOnline  HostStatus = "online"
-	StatusWarning HostStatus = "warning"
-	StatusOffline HostStatus = "offline"
+	StatusOnline      HostStatus = repository.AgentHostStateOnline
+	StatusWarning     HostStatus = repository.AgentHostStateWarning
+	StatusOffline     HostStatus = repository.AgentHostStateOffline
+	StatusMaintenance HostStatus = repository.AgentHostStateMaintenance
 )
 
 // HostInfo 封装服务器与计算后的状态
@@ -68,6 +70,27 @@ type Model struct {
 	detailScrollOffset int
 	detailContentLines int // 详情内容总行数，用于滚动
 
+	// 过滤状态：filterQuery 按名称/地址子串过滤当前列表，selectedHost/selectedNode 均为过滤后列表的下标
+	filtering   bool
+	filterQuery string
+
+	// 服务器列表排序方式
+	hostSort hostSortMode
+
+	// 自动刷新：tickID 用于丢弃开关切换前发出的 tick
+	autoRefresh     bool
+	refreshInterval time.Duration
+	tickID          int
+
+	// 按服务器 ID 保存的流量快照与计算出的实时速率
+	trafficSamples map[int64]trafficSample
+	trafficRates   map[int64]trafficRate
+
+	// 详情视图复制：copyFieldIndex 为高亮字段，copyStatus 为短暂显示的结果提示
+	copyFieldIndex int
+	copyStatus     string
+	copyStatusID   int
+
 	// 状态
 	loading bool
 	err     error
@@ -84,6 +107,11 @@ type keyMap struct {
 	Back    key.Binding
 	Quit    key.Binding
 	Refresh key.Binding
+	Filter  key.Binding
+	Sort    key.Binding
+	Auto    key.Binding
+	Copy    key.Binding
+	Next    key.Binding
 }
 
 func defaultKeyMap() keyMap {
@@ -112,26 +140,61 @@ func defaultKeyMap() keyMap {
 			key.WithKeys("r"),
 			key.WithHelp("r", "refresh"),
 		),
+		Filter: key.NewBinding(
+			key.WithKeys("/"),
+			key.WithHelp("/", "filter"),
+		),
+		Sort: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "sort"),
+		),
+		Auto: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "auto refresh"),
+		),
+		Copy: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "copy"),
+		),
+		Next: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("tab", "next field"),
+		),
 	}
 }
 
-// NewModel 创建新的 TUI 模型
+// NewModel 创建新的 TUI 模型，默认以 DefaultRefreshInterval 自动刷新
 func NewModel(store *sqlite.Store) Model {
+	return NewModelWithRefresh(store, DefaultRefreshInterval, true)
+}
+
+// NewModelWithRefresh 创建指定自动刷新间隔的 TUI 模型；interval 非正数时使用默认值
+func NewModelWithRefresh(store *sqlite.Store, interval time.Duration, autoRefresh bool) Model {
+	if interval <= 0 {
+		interval = DefaultRefreshInterval
+	}
 	return Model{
-		store:        store,
-		view:         ViewHostList,
-		selectedHost: 0,
-		selectedNode: 0,
-		keys:         defaultKeyMap(),
-		loading:      true,
+		store:           store,
+		view:            ViewHostList,
+		selectedHost:    0,
+		selectedNode:    0,
+		keys:            defaultKeyMap(),
+		loading:         true,
+		autoRefresh:     autoRefresh,
+		refreshInterval: interval,
+		trafficSamples:  make(map[int64]trafficSample),
+		trafficRates:    make(map[int64]trafficRate),
 	}
 }
 
 // Init 实现 tea.Model
 func (m Model) Init() tea.Cmd {
+	if !m.autoRefresh {
+		return m.loadHosts()
+	}
 	return tea.Batch(
 		m.loadHosts(),
-		tickCmd(),
+		tickCmd(m.refreshInterval, m.tickID),
 	)
 }
 
@@ -142,6 +205,7 @@ type hostsLoadedMsg struct {
 }
 
 type nodesLoadedMsg struct {
+	host  *repository.AgentHost // 重新读取的服务器信息，用于计算流量速率
 	nodes []NodeInfo
 }
 
@@ -149,8 +213,6 @@ type errorMsg struct {
 	err error
 }
 
-type tickMsg time.Time
-
 // 命令
 
 func (m Model) loadHosts() tea.Cmd {
@@ -170,7 +232,7 @@ func (m Model) loadHosts() tea.Cmd {
 
 			hosts[i] = HostInfo{
 				Host:   host,
-				Status: calcHostStatus(host.LastHeartbeatAt, now),
+				Status: HostStatus(host.DisplayState(now)),
 				Nodes:  nodes,
 			}
 		}
@@ -186,6 +248,7 @@ func (m Model) loadNodesForHost(hostID int64) tea.Cmd {
 		if err != nil {
 			return errorMsg{err: err}
 		}
+		host, _ := m.store.AgentHosts().FindByID(ctx, hostID)
 
 		nodes := make([]NodeInfo, len(servers))
 		now := time.Now().Unix()
@@ -197,34 +260,12 @@ func (m Model) loadNodesForHost(hostID int64) tea.Cmd {
 			}
 		}
 
-		return nodesLoadedMsg{nodes: nodes}
+		return nodesLoadedMsg{host: host, nodes: nodes}
 	}
 }
 
-func tickCmd() tea.Cmd {
-	return tea.Tick(5*time.Second, func(t time.Time) tea.Msg {
-		return tickMsg(t)
-	})
-}
-
 // 辅助函数
 
-func calcHostStatus(lastHeartbeat, now int64) HostStatus {
-	if lastHeartbeat == 0 {
-		return StatusOffline
-	}
-
-	diff := now - lastHeartbeat
-	switch {
-	case diff <= 120: // 2 分钟
-		return StatusOnline
-	case diff <= 300: // 5 分钟
-		return StatusWarning
-	default:
-		return StatusOffline
-	}
-}
-
 func calcNodeStatus(lastHeartbeat, now int64) HostStatus {
 	if lastHeartbeat == 0 {
 		return StatusOffline
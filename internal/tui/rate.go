@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/creamcroissant/xboard/internal/repository"
+)
+
+// trafficSample 是某台服务器一次指标快照中的累计流量与上报时间
+type trafficSample struct {
+	upload   int64
+	download int64
+	at       int64 // 快照时间（Unix 秒）
+}
+
+// trafficRate 是两次快照之间计算出的速率（bytes/s）
+type trafficRate struct {
+	upload   float64
+	download float64
+}
+
+// recordHostSample 记录服务器最新快照并与上一快照做差计算速率。
+// 快照时间未前进时（数据库里仍是同一次上报）保留上次速率；计数器回退（如 Agent 重启）时按 0 处理。
+func (m Model) recordHostSample(host *repository.AgentHost) {
+	if host == nil {
+		return
+	}
+	at := host.LastRealtimeReportAt
+	if at <= 0 {
+		at = host.LastHeartbeatAt
+	}
+	if at <= 0 {
+		return
+	}
+	sample := trafficSample{upload: host.UploadTotal, download: host.DownloadTotal, at: at}
+
+	prev, ok := m.trafficSamples[host.ID]
+	if ok && sample.at <= prev.at {
+		return
+	}
+	m.trafficSamples[host.ID] = sample
+	if !ok {
+		return
+	}
+
+	elapsed := float64(sample.at - prev.at)
+	m.trafficRates[host.ID] = trafficRate{
+		upload:   nonNegativeRate(sample.upload-prev.upload, elapsed),
+		download: nonNegativeRate(sample.download-prev.download, elapsed),
+	}
+}
+
+func nonNegativeRate(delta int64, elapsed float64) float64 {
+	if delta <= 0 || elapsed <= 0 {
+		return 0
+	}
+	return float64(delta) / elapsed
+}
+
+// formatHostRate 返回 "↑ X/s ↓ Y/s"，只有一个样本时返回 "—"
+func (m Model) formatHostRate(hostID int64) string {
+	rate, ok := m.trafficRates[hostID]
+	if !ok {
+		return "—"
+	}
+	return fmt.Sprintf("↑ %s/s ↓ %s/s", formatBytes(int64(rate.upload)), formatBytes(int64(rate.download)))
+}
@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// DefaultRefreshInterval 是自动刷新的默认间隔
+const DefaultRefreshInterval = 5 * time.Second
+
+// tickMsg 携带发出时的 tick 序号，自动刷新关闭或重新开启后旧序号的 tick 会被丢弃
+type tickMsg struct {
+	id int
+}
+
+func tickCmd(interval time.Duration, id int) tea.Cmd {
+	return tea.Tick(interval, func(time.Time) tea.Msg {
+		return tickMsg{id: id}
+	})
+}
+
+// handleTick 按当前视图重新加载数据；过滤输入行打开时跳过本次加载，避免打断输入
+func (m Model) handleTick(msg tickMsg) (tea.Model, tea.Cmd) {
+	if !m.autoRefresh || msg.id != m.tickID {
+		return m, nil
+	}
+	next := tickCmd(m.refreshInterval, m.tickID)
+	if m.filtering {
+		return m, next
+	}
+	switch m.view {
+	case ViewHostList:
+		return m, tea.Batch(m.loadHosts(), next)
+	case ViewNodeList, ViewNodeDetail:
+		if m.currentHost != nil {
+			return m, tea.Batch(m.loadNodesForHost(m.currentHost.Host.ID), next)
+		}
+	}
+	return m, next
+}
+
+// toggleAutoRefresh 开关自动刷新；每次切换都会更换 tick 序号，保证只有一条 tick 链在运行
+func (m Model) toggleAutoRefresh() (tea.Model, tea.Cmd) {
+	m.autoRefresh = !m.autoRefresh
+	m.tickID++
+	if !m.autoRefresh {
+		return m, nil
+	}
+	return m, tickCmd(m.refreshInterval, m.tickID)
+}
+
+// renderHeader 渲染视图标题，自动刷新开启时在右侧追加指示
+func (m Model) renderHeader(title string) string {
+	if m.autoRefresh {
+		title += fmt.Sprintf("  ⟳ auto %s", m.refreshInterval)
+		if m.filtering {
+			title += " (paused)"
+		}
+	}
+	return styleHeader.Width(m.width).Render(title)
+}
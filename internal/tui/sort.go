@@ -0,0 +1,118 @@
+package tui
+
+import (
+	"sort"
+	"strings"
+)
+
+// hostSortMode 表示服务器列表的排序方式
+type hostSortMode int
+
+const (
+	sortByID      hostSortMode = iota // 默认：按 ID（插入顺序）
+	sortByName                        // 按名称升序
+	sortByCPU                         // 按 CPU 使用率降序
+	sortByMemory                      // 按内存使用百分比降序
+	sortByTraffic                     // 按累计流量降序
+	sortByNodes                       // 按节点数降序
+	hostSortModeCount
+)
+
+// next 返回循环中的下一个排序方式
+func (s hostSortMode) next() hostSortMode {
+	return (s + 1) % hostSortModeCount
+}
+
+// column 返回排序方式对应的表头列名
+func (s hostSortMode) column() string {
+	switch s {
+	case sortByName:
+		return "Name"
+	case sortByCPU:
+		return "CPU"
+	case sortByMemory:
+		return "Memory"
+	case sortByTraffic:
+		return "Traffic"
+	case sortByNodes:
+		return "Nodes"
+	default:
+		return "ID"
+	}
+}
+
+// headerLabel 为当前排序列追加方向箭头
+func (s hostSortMode) headerLabel(column string) string {
+	if column != s.column() {
+		return column
+	}
+	if s == sortByID || s == sortByName {
+		return column + "↑"
+	}
+	return column + "↓"
+}
+
+// sortKey 返回数值类排序的比较值
+func (s hostSortMode) sortKey(h HostInfo) float64 {
+	switch s {
+	case sortByCPU:
+		return h.Host.CPUUsed
+	case sortByMemory:
+		if h.Host.MemTotal <= 0 {
+			return 0
+		}
+		return float64(h.Host.MemUsed) / float64(h.Host.MemTotal)
+	case sortByTraffic:
+		return float64(h.Host.UploadTotal + h.Host.DownloadTotal)
+	case sortByNodes:
+		return float64(len(h.Nodes))
+	default:
+		return 0
+	}
+}
+
+// sortHosts 按当前排序方式稳定排序 m.hosts，相同值按 ID 排列，并保持原选中服务器不变
+func (m *Model) sortHosts() {
+	selectedID, ok := m.selectedHostID()
+	mode := m.hostSort
+	sort.SliceStable(m.hosts, func(i, j int) bool {
+		a, b := m.hosts[i], m.hosts[j]
+		switch mode {
+		case sortByID:
+		case sortByName:
+			an, bn := strings.ToLower(a.Host.Name), strings.ToLower(b.Host.Name)
+			if an != bn {
+				return an < bn
+			}
+		default:
+			ak, bk := mode.sortKey(a), mode.sortKey(b)
+			if ak != bk {
+				return ak > bk
+			}
+		}
+		return a.Host.ID < b.Host.ID
+	})
+	if ok {
+		m.selectHostByID(selectedID)
+	}
+}
+
+// selectedHostID 返回当前选中服务器的 ID
+func (m Model) selectedHostID() (int64, bool) {
+	hosts := m.visibleHosts()
+	if m.selectedHost < 0 || m.selectedHost >= len(hosts) {
+		return 0, false
+	}
+	return hosts[m.selectedHost].Host.ID, true
+}
+
+// selectHostByID 在可见列表中定位指定 ID 的服务器；找不到时仅修正下标范围
+func (m *Model) selectHostByID(id int64) {
+	for i, h := range m.visibleHosts() {
+		if h.Host.ID == id {
+			m.selectedHost = i
+			return
+		}
+	}
+	m.clampSelection()
+}
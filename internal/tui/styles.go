@@ -41,6 +41,10 @@ var (
 			Foreground(colorDanger).
 			Bold(true)
 
+	styleMaintenance = lipgloss.NewStyle().
+				Foreground(colorSecondary).
+				Bold(true)
+
 	// Table styles
 	styleTableHeader = lipgloss.NewStyle().
 				Bold(true).
@@ -92,6 +96,8 @@ func StatusIcon(status string) string {
 		return styleWarning.Render("◐ Warning")
 	case "offline":
 		return styleOffline.Render("○ Offline")
+	case "maintenance":
+		return styleMaintenance.Render("◆ Maintenance")
 	default:
 		return styleMuted().Render("? Unknown")
 	}
@@ -106,6 +112,8 @@ func HostStatusIcon(status string) string {
 		return styleWarning.Render("◐")
 	case "offline":
 		return styleOffline.Render("○")
+	case "maintenance":
+		return styleMaintenance.Render("◆")
 	default:
 		return styleMuted().Render("?")
 	}
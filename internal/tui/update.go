@@ -19,14 +19,33 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case hostsLoadedMsg:
 		m.loading = false
+		selectedID, ok := m.selectedHostID()
 		m.hosts = msg.hosts
 		m.err = nil
+		for _, h := range m.hosts {
+			m.recordHostSample(h.Host)
+		}
+		m.sortHosts()
+		if ok {
+			m.selectHostByID(selectedID)
+		}
 		return m, nil
 
 	case nodesLoadedMsg:
 		m.loading = false
+		selectedID, ok := m.selectedNodeID()
 		m.nodes = msg.nodes
 		m.err = nil
+		if msg.host != nil {
+			m.recordHostSample(msg.host)
+			if m.currentHost != nil && m.currentHost.Host.ID == msg.host.ID {
+				m.currentHost.Host = msg.host
+			}
+		}
+		m.clampSelection()
+		if ok {
+			m.selectNodeByID(selectedID)
+		}
 
 		// Update detail node if viewing
 		if m.view == ViewNodeDetail && m.detailNode != nil {
@@ -45,22 +64,26 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tickMsg:
-		// Auto refresh based on current view
-		switch m.view {
-		case ViewHostList:
-			return m, tea.Batch(m.loadHosts(), tickCmd())
-		case ViewNodeList, ViewNodeDetail:
-			if m.currentHost != nil {
-				return m, tea.Batch(m.loadNodesForHost(m.currentHost.Host.ID), tickCmd())
-			}
-			return m, tickCmd()
+		return m.handleTick(msg)
+
+	case copyResultMsg:
+		return m.handleCopyResult(msg)
+
+	case clearCopyStatusMsg:
+		if msg.id == m.copyStatusID {
+			m.copyStatus = ""
 		}
+		return m, nil
 	}
 
 	return m, nil
 }
 
 func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.filtering {
+		return m.handleFilterInput(msg)
+	}
+
 	switch {
 	case key.Matches(msg, m.keys.Quit):
 		return m, tea.Quit
@@ -79,6 +102,34 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case key.Matches(msg, m.keys.Refresh):
 		return m.handleRefresh()
+
+	case key.Matches(msg, m.keys.Filter):
+		if m.view == ViewHostList || m.view == ViewNodeList {
+			m.filtering = true
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Sort):
+		if m.view == ViewHostList {
+			m.hostSort = m.hostSort.next()
+			m.sortHosts()
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Auto):
+		return m.toggleAutoRefresh()
+
+	case key.Matches(msg, m.keys.Copy):
+		if m.view == ViewNodeDetail {
+			return m.copySelectedField()
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Next):
+		if m.view == ViewNodeDetail {
+			return m.nextCopyField()
+		}
+		return m, nil
 	}
 
 	return m, nil
@@ -87,17 +138,17 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m Model) handleUp() (tea.Model, tea.Cmd) {
 	switch m.view {
 	case ViewHostList:
-		if len(m.hosts) > 0 {
+		if count := len(m.visibleHosts()); count > 0 {
 			m.selectedHost--
 			if m.selectedHost < 0 {
-				m.selectedHost = len(m.hosts) - 1
+				m.selectedHost = count - 1
 			}
 		}
 	case ViewNodeList:
-		if len(m.nodes) > 0 {
+		if count := len(m.visibleNodes()); count > 0 {
 			m.selectedNode--
 			if m.selectedNode < 0 {
-				m.selectedNode = len(m.nodes) - 1
+				m.selectedNode = count - 1
 			}
 		}
 	case ViewNodeDetail:
@@ -112,16 +163,16 @@ func (m Model) handleUp() (tea.Model, tea.Cmd) {
 func (m Model) handleDown() (tea.Model, tea.Cmd) {
 	switch m.view {
 	case ViewHostList:
-		if len(m.hosts) > 0 {
+		if count := len(m.visibleHosts()); count > 0 {
 			m.selectedHost++
-			if m.selectedHost >= len(m.hosts) {
+			if m.selectedHost >= count {
 				m.selectedHost = 0
 			}
 		}
 	case ViewNodeList:
-		if len(m.nodes) > 0 {
+		if count := len(m.visibleNodes()); count > 0 {
 			m.selectedNode++
-			if m.selectedNode >= len(m.nodes) {
+			if m.selectedNode >= count {
 				m.selectedNode = 0
 			}
 		}
@@ -149,24 +200,35 @@ func (m Model) handleDown() (tea.Model, tea.Cmd) {
 func (m Model) handleEnter() (tea.Model, tea.Cmd) {
 	switch m.view {
 	case ViewHostList:
-		if len(m.hosts) > 0 {
-			m.currentHost = &m.hosts[m.selectedHost]
+		if hosts := m.visibleHosts(); len(hosts) > 0 {
+			host := hosts[m.selectedHost]
+			m.currentHost = &host
+			m.clearFilter()
 			m.view = ViewNodeList
 			m.selectedNode = 0
 			m.loading = true
 			return m, m.loadNodesForHost(m.currentHost.Host.ID)
 		}
 	case ViewNodeList:
-		if len(m.nodes) > 0 {
-			m.detailNode = &m.nodes[m.selectedNode]
+		if nodes := m.visibleNodes(); len(nodes) > 0 {
+			node := nodes[m.selectedNode]
+			m.detailNode = &node
 			m.view = ViewNodeDetail
 			m.detailScrollOffset = 0 // Reset scroll position when entering detail view
+			m.copyFieldIndex = 0
+			m.copyStatus = ""
 		}
 	}
 	return m, nil
 }
 
 func (m Model) handleBack() (tea.Model, tea.Cmd) {
+	// 列表视图下 Esc 先清空过滤，再次按下才返回上一级
+	if m.filterQuery != "" && (m.view == ViewHostList || m.view == ViewNodeList) {
+		m.clearFilter()
+		return m, nil
+	}
+
 	switch m.view {
 	case ViewNodeDetail:
 		m.view = ViewNodeList
@@ -176,6 +238,7 @@ func (m Model) handleBack() (tea.Model, tea.Cmd) {
 		m.currentHost = nil
 		m.nodes = nil
 		m.selectedNode = 0
+		m.clampSelection()
 	}
 	return m, nil
 }
@@ -8,6 +8,7 @@ import (
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/creamcroissant/xboard/internal/repository"
+	"github.com/creamcroissant/xboard/internal/template"
 )
 
 // View 实现 tea.Model
@@ -34,9 +35,10 @@ func (m Model) renderHostListView() string {
 	var b strings.Builder
 
 	// 头部
-	header := styleHeader.Width(m.width).Render("  XBoard Server Monitor")
+	header := m.renderHeader("  XBoard Server Monitor")
 	b.WriteString(header)
 	b.WriteString("\n\n")
+	b.WriteString(m.renderFilterLine())
 
 	// 错误提示
 	if m.err != nil {
@@ -53,7 +55,14 @@ func (m Model) renderHostListView() string {
 	// 表头
 	tableHeader := fmt.Sprintf(
 		"  %-4s │ %-16s │ %-18s │ %-8s │ %-8s │ %-8s │ %-12s │ %s",
-		"ID", "Name", "Host", "CPU", "Memory", "Disk", "Traffic", "Nodes",
+		m.hostSort.headerLabel("ID"),
+		m.hostSort.headerLabel("Name"),
+		"Host",
+		m.hostSort.headerLabel("CPU"),
+		m.hostSort.headerLabel("Memory"),
+		"Disk",
+		m.hostSort.headerLabel("Traffic"),
+		m.hostSort.headerLabel("Nodes"),
 	)
 	b.WriteString(styleTableHeader.Width(m.width).Render(tableHeader))
 	b.WriteString("\n")
@@ -63,9 +72,13 @@ func (m Model) renderHostListView() string {
 	b.WriteString("\n")
 
 	// 表格行
+	hosts := m.visibleHosts()
 	if len(m.hosts) == 0 {
 		b.WriteString(styleMuted().Render("  No servers found. Add agent hosts first."))
 		b.WriteString("\n")
+	} else if len(hosts) == 0 {
+		b.WriteString(styleMuted().Render("  No servers match the filter."))
+		b.WriteString("\n")
 	} else {
 		// 按终端高度计算可见行数
 		visibleRows := m.height - 12
@@ -79,20 +92,20 @@ func (m Model) renderHostListView() string {
 		}
 
 		endIdx := startIdx + visibleRows
-		if endIdx > len(m.hosts) {
-			endIdx = len(m.hosts)
+		if endIdx > len(hosts) {
+			endIdx = len(hosts)
 		}
 
 		for i := startIdx; i < endIdx; i++ {
-			host := m.hosts[i]
+			host := hosts[i]
 			row := m.renderHostTableRow(host, i == m.selectedHost)
 			b.WriteString(row)
 			b.WriteString("\n")
 		}
 
 		// 滚动提示
-		if len(m.hosts) > visibleRows {
-			scrollInfo := fmt.Sprintf("  Showing %d-%d of %d servers", startIdx+1, endIdx, len(m.hosts))
+		if len(hosts) > visibleRows {
+			scrollInfo := fmt.Sprintf("  Showing %d-%d of %d servers", startIdx+1, endIdx, len(hosts))
 			b.WriteString(styleMuted().Render(scrollInfo))
 			b.WriteString("\n")
 		}
@@ -106,7 +119,7 @@ func (m Model) renderHostListView() string {
 	b.WriteString("\n\n")
 
 	// 帮助提示
-	help := styleHelp.Render("  [↑/↓] Navigate  [Enter] View Nodes  [r] Refresh  [q] Quit")
+	help := styleHelp.Render("  [↑/↓] Navigate  [Enter] View Nodes  [/] Filter  [s] Sort  [a] Auto  [r] Refresh  [q] Quit")
 	b.WriteString(help)
 
 	return b.String()
@@ -166,9 +179,10 @@ func (m Model) renderHostTableRow(host HostInfo, selected bool) string {
 }
 
 func (m Model) renderHostStatusSummary() string {
-	online, warning, offline := 0, 0, 0
+	online, warning, offline, maintenance := 0, 0, 0, 0
 
-	for _, h := range m.hosts {
+	hosts := m.visibleHosts()
+	for _, h := range hosts {
 		switch h.Status {
 		case StatusOnline:
 			online++
@@ -176,18 +190,22 @@ func (m Model) renderHostStatusSummary() string {
 			warning++
 		case StatusOffline:
 			offline++
+		case StatusMaintenance:
+			maintenance++
 		}
 	}
 
 	return fmt.Sprintf(
-		"  %s %d Online  %s %d Warning  %s %d Offline  │  Total: %d servers",
+		"  %s %d Online  %s %d Warning  %s %d Offline  %s %d Maintenance  │  %s",
 		styleOnline.Render("●"),
 		online,
 		styleWarning.Render("◐"),
 		warning,
 		styleOffline.Render("○"),
 		offline,
-		len(m.hosts),
+		styleMaintenance.Render("◆"),
+		maintenance,
+		formatTotal(len(hosts), len(m.hosts), "servers", m.filterQuery != ""),
 	)
 }
 
@@ -199,9 +217,10 @@ func (m Model) renderNodeListView() string {
 	if m.currentHost != nil {
 		hostName = m.currentHost.Host.Name
 	}
-	header := styleHeader.Width(m.width).Render(fmt.Sprintf("  Nodes on: %s", hostName))
+	header := m.renderHeader(fmt.Sprintf("  Nodes on: %s", hostName))
 	b.WriteString(header)
 	b.WriteString("\n\n")
+	b.WriteString(m.renderFilterLine())
 
 	// 错误提示
 	if m.err != nil {
@@ -228,9 +247,13 @@ func (m Model) renderNodeListView() string {
 	b.WriteString("\n")
 
 	// 表格行
+	nodes := m.visibleNodes()
 	if len(m.nodes) == 0 {
 		b.WriteString(styleMuted().Render("  No nodes deployed on this server"))
 		b.WriteString("\n")
+	} else if len(nodes) == 0 {
+		b.WriteString(styleMuted().Render("  No nodes match the filter."))
+		b.WriteString("\n")
 	} else {
 		// 按终端高度计算可见行数
 		visibleRows := m.height - 10
@@ -244,20 +267,20 @@ func (m Model) renderNodeListView() string {
 		}
 
 		endIdx := startIdx + visibleRows
-		if endIdx > len(m.nodes) {
-			endIdx = len(m.nodes)
+		if endIdx > len(nodes) {
+			endIdx = len(nodes)
 		}
 
 		for i := startIdx; i < endIdx; i++ {
-			node := m.nodes[i]
+			node := nodes[i]
 			row := m.renderNodeTableRow(node, i == m.selectedNode)
 			b.WriteString(row)
 			b.WriteString("\n")
 		}
 
 		// 滚动提示
-		if len(m.nodes) > visibleRows {
-			scrollInfo := fmt.Sprintf("  Showing %d-%d of %d nodes", startIdx+1, endIdx, len(m.nodes))
+		if len(nodes) > visibleRows {
+			scrollInfo := fmt.Sprintf("  Showing %d-%d of %d nodes", startIdx+1, endIdx, len(nodes))
 			b.WriteString(styleMuted().Render(scrollInfo))
 			b.WriteString("\n")
 		}
@@ -271,7 +294,7 @@ func (m Model) renderNodeListView() string {
 	b.WriteString("\n\n")
 
 	// 帮助提示
-	help := styleHelp.Render("  [↑/↓] Navigate  [Enter] Details  [Esc] Back  [r] Refresh  [q] Quit")
+	help := styleHelp.Render("  [↑/↓] Navigate  [Enter] Details  [/] Filter  [Esc] Back  [a] Auto  [r] Refresh  [q] Quit")
 	b.WriteString(help)
 
 	return b.String()
@@ -311,7 +334,8 @@ func (m Model) renderNodeTableRow(node NodeInfo, selected bool) string {
 func (m Model) renderNodeStatusSummary() string {
 	online, warning, offline := 0, 0, 0
 
-	for _, n := range m.nodes {
+	nodes := m.visibleNodes()
+	for _, n := range nodes {
 		switch n.Status {
 		case StatusOnline:
 			online++
@@ -323,17 +347,25 @@ func (m Model) renderNodeStatusSummary() string {
 	}
 
 	return fmt.Sprintf(
-		"  %s %d  %s %d  %s %d  │  Total: %d nodes",
+		"  %s %d  %s %d  %s %d  │  %s",
 		styleOnline.Render("●"),
 		online,
 		styleWarning.Render("◐"),
 		warning,
 		styleOffline.Render("○"),
 		offline,
-		len(m.nodes),
+		formatTotal(len(nodes), len(m.nodes), "nodes", m.filterQuery != ""),
 	)
 }
 
+// formatTotal 生成汇总中的总数；过滤时显示 "匹配数/总数"
+func formatTotal(shown, total int, noun string, filtered bool) string {
+	if filtered {
+		return fmt.Sprintf("Matched: %d/%d %s", shown, total, noun)
+	}
+	return fmt.Sprintf("Total: %d %s", total, noun)
+}
+
 func (m Model) renderNodeDetailView() string {
 	if m.detailNode == nil {
 		return "No node selected"
@@ -348,7 +380,7 @@ func (m Model) renderNodeDetailView() string {
 	// 头部：使用协议展示名
 	displayName := getProtocolDisplayName(srv)
 	title := fmt.Sprintf("  Protocol: %s (#%d)", displayName, srv.ID)
-	header := styleHeader.Width(m.width).Render(title)
+	header := m.renderHeader(title)
 	contentLines = append(contentLines, header)
 	contentLines = append(contentLines, "")
 
@@ -375,7 +407,7 @@ func (m Model) renderNodeDetailView() string {
 	totalLines := len(contentLines)
 
 	// 计算可视区域
-	viewportHeight := m.height - 4 // 为帮助提示与边框预留空间
+	viewportHeight := m.height - 5 // 为复制提示、帮助提示与边框预留空间
 	if viewportHeight < 5 {
 		viewportHeight = 5
 	}
@@ -424,9 +456,13 @@ func (m Model) renderNodeDetailView() string {
 	}
 
 	b.WriteString("\n")
+	if copyLine := m.renderCopyLine(); copyLine != "" {
+		b.WriteString(copyLine)
+		b.WriteString("\n")
+	}
 
 	// 帮助提示（始终在底部）
-	help := styleHelp.Render("  [↑/↓] Scroll  [Esc] Back  [r] Refresh  [q] Quit")
+	help := styleHelp.Render("  [↑/↓] Scroll  [Tab/c] Copy  [Esc] Back  [a] Auto  [r] Refresh  [q] Quit")
 	b.WriteString(help)
 
 	return b.String()
@@ -468,6 +504,14 @@ func (m Model) renderBasicInfo(node *NodeInfo) string {
 		styleValue.Render(formatLastSeen(srv.LastHeartbeatAt)),
 	))
 
+	// 所在服务器的实时流量速率
+	if m.currentHost != nil {
+		lines = append(lines, lipgloss.JoinHorizontal(lipgloss.Left,
+			styleLabel.Render("Host Traffic:"),
+			styleValue.Render(m.formatHostRate(m.currentHost.Host.ID)),
+		))
+	}
+
 	return lipgloss.JoinVertical(lipgloss.Left, lines...)
 }
 
@@ -631,6 +675,8 @@ type ProtocolDetail struct {
 	Users     []UserInfo      `json:"users,omitempty"`
 	Options   map[string]any  `json:"options,omitempty"` // Protocol-specific options
 	CoreType  string          `json:"core_type"`
+	// Encryption VLESS 加密参数，空值表示 none
+	Encryption string `json:"encryption,omitempty"`
 }
 
 type TransportInfo struct {
@@ -714,6 +760,14 @@ func (m Model) renderProtocolDetails(srv *repository.Server) string {
 		))
 	}
 
+	// VLESS 加密
+	if detail.Encryption != "" {
+		lines = append(lines, lipgloss.JoinHorizontal(lipgloss.Left,
+			styleLabel.Render("Encryption:"),
+			styleValue.Render(detail.Encryption),
+		))
+	}
+
 	// 监听地址
 	if detail.Listen != "" {
 		lines = append(lines, lipgloss.JoinHorizontal(lipgloss.Left,
@@ -750,6 +804,30 @@ func (m Model) renderProtocolDetails(srv *repository.Server) string {
 		}
 	}
 
+	// socks/http 认证状态：无认证且监听非回环地址即为开放代理
+	if strings.EqualFold(detail.Protocol, "socks") || strings.EqualFold(detail.Protocol, "http") {
+		auth := styleOnline.Render(fmt.Sprintf("Password (%d users)", len(detail.Users)))
+		if !template.ProxyAuthEnabled(detail.Options) || len(detail.Users) == 0 {
+			if template.IsLoopbackListen(detail.Listen) {
+				auth = styleMuted().Render("None (loopback only)")
+			} else {
+				auth = styleOffline.Render("None - open proxy!")
+			}
+		}
+		lines = append(lines, lipgloss.JoinHorizontal(lipgloss.Left,
+			styleLabel.Render("Auth:"),
+			auth,
+		))
+	}
+
+	// naive 强制要求 TLS，缺失时直接提示
+	if strings.EqualFold(detail.Protocol, "naive") && (detail.TLS == nil || !detail.TLS.Enabled) {
+		lines = append(lines, lipgloss.JoinHorizontal(lipgloss.Left,
+			styleLabel.Render("TLS:"),
+			styleOffline.Render("Missing (required by naive)"),
+		))
+	}
+
 	// TLS 配置
 	if detail.TLS != nil {
 		lines = append(lines, "")
@@ -798,6 +876,13 @@ func (m Model) renderProtocolDetails(srv *repository.Server) string {
 					styleValue.Render(handshakeStr),
 				))
 			}
+			// 公钥
+			if detail.TLS.Reality.PublicKey != "" {
+				lines = append(lines, lipgloss.JoinHorizontal(lipgloss.Left,
+					styleLabel.Render("Public Key:"),
+					styleValue.Render(detail.TLS.Reality.PublicKey),
+				))
+			}
 			// 指纹
 			if detail.TLS.Reality.Fingerprint != "" {
 				lines = append(lines, lipgloss.JoinHorizontal(lipgloss.Left,
@@ -903,10 +988,14 @@ func (m Model) renderProtocolDetails(srv *repository.Server) string {
 				))
 			}
 
-			// Email
+			// Email（naive 为 basic-auth 用户名）
 			if user.Email != "" {
+				label := "    Email: "
+				if strings.EqualFold(detail.Protocol, "naive") || strings.EqualFold(detail.Protocol, "socks") || strings.EqualFold(detail.Protocol, "http") {
+					label = "    Username: "
+				}
 				lines = append(lines, lipgloss.JoinHorizontal(lipgloss.Left,
-					styleMuted().Render("    Email: "),
+					styleMuted().Render(label),
 					styleValue.Render(user.Email),
 				))
 			}
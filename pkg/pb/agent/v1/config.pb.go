@@ -76,14 +76,18 @@ func (x *ConfigRequest) GetEtag() string {
 
 // ConfigResponse contains node configuration
 type ConfigResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	NotModified   bool                   `protobuf:"varint,2,opt,name=not_modified,json=notModified,proto3" json:"not_modified,omitempty"` // True if etag matched (304 equivalent)
-	ConfigJson    []byte                 `protobuf:"bytes,3,opt,name=config_json,json=configJson,proto3" json:"config_json,omitempty"`     // Node configuration as JSON
-	Etag          string                 `protobuf:"bytes,4,opt,name=etag,proto3" json:"etag,omitempty"`
-	Version       int64                  `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state            protoimpl.MessageState   `protogen:"open.v1"`
+	Success          bool                     `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	NotModified      bool                     `protobuf:"varint,2,opt,name=not_modified,json=notModified,proto3" json:"not_modified,omitempty"` // True if etag matched (304 equivalent)
+	ConfigJson       []byte                   `protobuf:"bytes,3,opt,name=config_json,json=configJson,proto3" json:"config_json,omitempty"`     // Node configuration as JSON
+	Etag             string                   `protobuf:"bytes,4,opt,name=etag,proto3" json:"etag,omitempty"`
+	Version          int64                    `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"`                                          // Issue time in unix milliseconds; signed configs must not go backwards
+	ValidationIssues []*ConfigValidationIssue `protobuf:"bytes,6,rep,name=validation_issues,json=validationIssues,proto3" json:"validation_issues,omitempty"` // Set when generated config failed validation
+	Signature        string                   `protobuf:"bytes,7,opt,name=signature,proto3" json:"signature,omitempty"`                                       // Hex HMAC-SHA256 over agent_host_id, etag, version and config_json; empty when signing is disabled
+	KeyId            string                   `protobuf:"bytes,8,opt,name=key_id,json=keyId,proto3" json:"key_id,omitempty"`                                  // Identifies the shared secret used for signature
+	AgentHostId      int64                    `protobuf:"varint,9,opt,name=agent_host_id,json=agentHostId,proto3" json:"agent_host_id,omitempty"`             // Host the config was generated for, covered by signature
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *ConfigResponse) Reset() {
@@ -151,19 +155,109 @@ func (x *ConfigResponse) GetVersion() int64 {
 	return 0
 }
 
+func (x *ConfigResponse) GetValidationIssues() []*ConfigValidationIssue {
+	if x != nil {
+		return x.ValidationIssues
+	}
+	return nil
+}
+
+func (x *ConfigResponse) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
+}
+
+func (x *ConfigResponse) GetKeyId() string {
+	if x != nil {
+		return x.KeyId
+	}
+	return ""
+}
+
+func (x *ConfigResponse) GetAgentHostId() int64 {
+	if x != nil {
+		return x.AgentHostId
+	}
+	return 0
+}
+
+// ConfigValidationIssue locates a validation problem by JSON pointer
+type ConfigValidationIssue struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"` // JSON pointer (RFC 6901) into the generated config
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Severity      string                 `protobuf:"bytes,3,opt,name=severity,proto3" json:"severity,omitempty"` // "error" or "warning"
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfigValidationIssue) Reset() {
+	*x = ConfigValidationIssue{}
+	mi := &file_agent_v1_config_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfigValidationIssue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfigValidationIssue) ProtoMessage() {}
+
+func (x *ConfigValidationIssue) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_v1_config_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfigValidationIssue.ProtoReflect.Descriptor instead.
+func (*ConfigValidationIssue) Descriptor() ([]byte, []int) {
+	return file_agent_v1_config_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ConfigValidationIssue) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ConfigValidationIssue) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ConfigValidationIssue) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
 // UsersRequest is sent by Agent to get user list
 type UsersRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	NodeId        int32                  `protobuf:"varint,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
 	Etag          string                 `protobuf:"bytes,2,opt,name=etag,proto3" json:"etag,omitempty"`                                      // For conditional requests
 	SinceVersion  int64                  `protobuf:"varint,3,opt,name=since_version,json=sinceVersion,proto3" json:"since_version,omitempty"` // For incremental updates
+	Full          bool                   `protobuf:"varint,4,opt,name=full,proto3" json:"full,omitempty"`                                     // Force full user list (e.g. freshly started agent)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *UsersRequest) Reset() {
 	*x = UsersRequest{}
-	mi := &file_agent_v1_config_proto_msgTypes[2]
+	mi := &file_agent_v1_config_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -175,7 +269,7 @@ func (x *UsersRequest) String() string {
 func (*UsersRequest) ProtoMessage() {}
 
 func (x *UsersRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_v1_config_proto_msgTypes[2]
+	mi := &file_agent_v1_config_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -188,7 +282,7 @@ func (x *UsersRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UsersRequest.ProtoReflect.Descriptor instead.
 func (*UsersRequest) Descriptor() ([]byte, []int) {
-	return file_agent_v1_config_proto_rawDescGZIP(), []int{2}
+	return file_agent_v1_config_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *UsersRequest) GetNodeId() int32 {
@@ -212,21 +306,32 @@ func (x *UsersRequest) GetSinceVersion() int64 {
 	return 0
 }
 
+func (x *UsersRequest) GetFull() bool {
+	if x != nil {
+		return x.Full
+	}
+	return false
+}
+
 // UsersResponse contains user list
 type UsersResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	NotModified   bool                   `protobuf:"varint,2,opt,name=not_modified,json=notModified,proto3" json:"not_modified,omitempty"`
-	Users         []*UserInfo            `protobuf:"bytes,3,rep,name=users,proto3" json:"users,omitempty"`
-	Etag          string                 `protobuf:"bytes,4,opt,name=etag,proto3" json:"etag,omitempty"`
-	Version       int64                  `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Success        bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	NotModified    bool                   `protobuf:"varint,2,opt,name=not_modified,json=notModified,proto3" json:"not_modified,omitempty"`
+	Users          []*UserInfo            `protobuf:"bytes,3,rep,name=users,proto3" json:"users,omitempty"` // Full list, set when incremental = false
+	Etag           string                 `protobuf:"bytes,4,opt,name=etag,proto3" json:"etag,omitempty"`
+	Version        int64                  `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"`
+	Incremental    bool                   `protobuf:"varint,6,opt,name=incremental,proto3" json:"incremental,omitempty"` // True if only the delta fields below are set
+	Added          []*UserInfo            `protobuf:"bytes,7,rep,name=added,proto3" json:"added,omitempty"`
+	RemovedUserIds []int64                `protobuf:"varint,8,rep,packed,name=removed_user_ids,json=removedUserIds,proto3" json:"removed_user_ids,omitempty"`
+	Changed        []*UserInfo            `protobuf:"bytes,9,rep,name=changed,proto3" json:"changed,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *UsersResponse) Reset() {
 	*x = UsersResponse{}
-	mi := &file_agent_v1_config_proto_msgTypes[3]
+	mi := &file_agent_v1_config_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -238,7 +343,7 @@ func (x *UsersResponse) String() string {
 func (*UsersResponse) ProtoMessage() {}
 
 func (x *UsersResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_v1_config_proto_msgTypes[3]
+	mi := &file_agent_v1_config_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -251,7 +356,7 @@ func (x *UsersResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UsersResponse.ProtoReflect.Descriptor instead.
 func (*UsersResponse) Descriptor() ([]byte, []int) {
-	return file_agent_v1_config_proto_rawDescGZIP(), []int{3}
+	return file_agent_v1_config_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *UsersResponse) GetSuccess() bool {
@@ -289,13 +394,41 @@ func (x *UsersResponse) GetVersion() int64 {
 	return 0
 }
 
+func (x *UsersResponse) GetIncremental() bool {
+	if x != nil {
+		return x.Incremental
+	}
+	return false
+}
+
+func (x *UsersResponse) GetAdded() []*UserInfo {
+	if x != nil {
+		return x.Added
+	}
+	return nil
+}
+
+func (x *UsersResponse) GetRemovedUserIds() []int64 {
+	if x != nil {
+		return x.RemovedUserIds
+	}
+	return nil
+}
+
+func (x *UsersResponse) GetChanged() []*UserInfo {
+	if x != nil {
+		return x.Changed
+	}
+	return nil
+}
+
 // UserInfo contains user details
 type UserInfo struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	Uuid          string                 `protobuf:"bytes,2,opt,name=uuid,proto3" json:"uuid,omitempty"`
 	Email         string                 `protobuf:"bytes,3,opt,name=email,proto3" json:"email,omitempty"`
-	SpeedLimit    int64                  `protobuf:"varint,4,opt,name=speed_limit,json=speedLimit,proto3" json:"speed_limit,omitempty"`
+	SpeedLimit    int64                  `protobuf:"varint,4,opt,name=speed_limit,json=speedLimit,proto3" json:"speed_limit,omitempty"` // Mbps, 0 = unlimited
 	DeviceLimit   int32                  `protobuf:"varint,5,opt,name=device_limit,json=deviceLimit,proto3" json:"device_limit,omitempty"`
 	Enabled       bool                   `protobuf:"varint,6,opt,name=enabled,proto3" json:"enabled,omitempty"`
 	unknownFields protoimpl.UnknownFields
@@ -304,7 +437,7 @@ type UserInfo struct {
 
 func (x *UserInfo) Reset() {
 	*x = UserInfo{}
-	mi := &file_agent_v1_config_proto_msgTypes[4]
+	mi := &file_agent_v1_config_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -316,7 +449,7 @@ func (x *UserInfo) String() string {
 func (*UserInfo) ProtoMessage() {}
 
 func (x *UserInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_v1_config_proto_msgTypes[4]
+	mi := &file_agent_v1_config_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -329,7 +462,7 @@ func (x *UserInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UserInfo.ProtoReflect.Descriptor instead.
 func (*UserInfo) Descriptor() ([]byte, []int) {
-	return file_agent_v1_config_proto_rawDescGZIP(), []int{4}
+	return file_agent_v1_config_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *UserInfo) GetUserId() int64 {
@@ -381,24 +514,37 @@ const file_agent_v1_config_proto_rawDesc = "" +
 	"\x15agent/v1/config.proto\x12\bagent.v1\"<\n" +
 	"\rConfigRequest\x12\x17\n" +
 	"\anode_id\x18\x01 \x01(\x05R\x06nodeId\x12\x12\n" +
-	"\x04etag\x18\x02 \x01(\tR\x04etag\"\x9c\x01\n" +
+	"\x04etag\x18\x02 \x01(\tR\x04etag\"\xc3\x02\n" +
 	"\x0eConfigResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12!\n" +
 	"\fnot_modified\x18\x02 \x01(\bR\vnotModified\x12\x1f\n" +
 	"\vconfig_json\x18\x03 \x01(\fR\n" +
 	"configJson\x12\x12\n" +
 	"\x04etag\x18\x04 \x01(\tR\x04etag\x12\x18\n" +
-	"\aversion\x18\x05 \x01(\x03R\aversion\"`\n" +
+	"\aversion\x18\x05 \x01(\x03R\aversion\x12L\n" +
+	"\x11validation_issues\x18\x06 \x03(\v2\x1f.agent.v1.ConfigValidationIssueR\x10validationIssues\x12\x1c\n" +
+	"\tsignature\x18\a \x01(\tR\tsignature\x12\x15\n" +
+	"\x06key_id\x18\b \x01(\tR\x05keyId\x12\"\n" +
+	"\ragent_host_id\x18\t \x01(\x03R\vagentHostId\"a\n" +
+	"\x15ConfigValidationIssue\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1a\n" +
+	"\bseverity\x18\x03 \x01(\tR\bseverity\"t\n" +
 	"\fUsersRequest\x12\x17\n" +
 	"\anode_id\x18\x01 \x01(\x05R\x06nodeId\x12\x12\n" +
 	"\x04etag\x18\x02 \x01(\tR\x04etag\x12#\n" +
-	"\rsince_version\x18\x03 \x01(\x03R\fsinceVersion\"\xa4\x01\n" +
+	"\rsince_version\x18\x03 \x01(\x03R\fsinceVersion\x12\x12\n" +
+	"\x04full\x18\x04 \x01(\bR\x04full\"\xc8\x02\n" +
 	"\rUsersResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12!\n" +
 	"\fnot_modified\x18\x02 \x01(\bR\vnotModified\x12(\n" +
 	"\x05users\x18\x03 \x03(\v2\x12.agent.v1.UserInfoR\x05users\x12\x12\n" +
 	"\x04etag\x18\x04 \x01(\tR\x04etag\x12\x18\n" +
-	"\aversion\x18\x05 \x01(\x03R\aversion\"\xab\x01\n" +
+	"\aversion\x18\x05 \x01(\x03R\aversion\x12 \n" +
+	"\vincremental\x18\x06 \x01(\bR\vincremental\x12(\n" +
+	"\x05added\x18\a \x03(\v2\x12.agent.v1.UserInfoR\x05added\x12(\n" +
+	"\x10removed_user_ids\x18\b \x03(\x03R\x0eremovedUserIds\x12,\n" +
+	"\achanged\x18\t \x03(\v2\x12.agent.v1.UserInfoR\achanged\"\xab\x01\n" +
 	"\bUserInfo\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x12\n" +
 	"\x04uuid\x18\x02 \x01(\tR\x04uuid\x12\x14\n" +
@@ -420,21 +566,25 @@ func file_agent_v1_config_proto_rawDescGZIP() []byte {
 	return file_agent_v1_config_proto_rawDescData
 }
 
-var file_agent_v1_config_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_agent_v1_config_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_agent_v1_config_proto_goTypes = []any{
-	(*ConfigRequest)(nil),  // 0: agent.v1.ConfigRequest
-	(*ConfigResponse)(nil), // 1: agent.v1.ConfigResponse
-	(*UsersRequest)(nil),   // 2: agent.v1.UsersRequest
-	(*UsersResponse)(nil),  // 3: agent.v1.UsersResponse
-	(*UserInfo)(nil),       // 4: agent.v1.UserInfo
+	(*ConfigRequest)(nil),         // 0: agent.v1.ConfigRequest
+	(*ConfigResponse)(nil),        // 1: agent.v1.ConfigResponse
+	(*ConfigValidationIssue)(nil), // 2: agent.v1.ConfigValidationIssue
+	(*UsersRequest)(nil),          // 3: agent.v1.UsersRequest
+	(*UsersResponse)(nil),         // 4: agent.v1.UsersResponse
+	(*UserInfo)(nil),              // 5: agent.v1.UserInfo
 }
 var file_agent_v1_config_proto_depIdxs = []int32{
-	4, // 0: agent.v1.UsersResponse.users:type_name -> agent.v1.UserInfo
-	1, // [1:1] is the sub-list for method output_type
-	1, // [1:1] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	2, // 0: agent.v1.ConfigResponse.validation_issues:type_name -> agent.v1.ConfigValidationIssue
+	5, // 1: agent.v1.UsersResponse.users:type_name -> agent.v1.UserInfo
+	5, // 2: agent.v1.UsersResponse.added:type_name -> agent.v1.UserInfo
+	5, // 3: agent.v1.UsersResponse.changed:type_name -> agent.v1.UserInfo
+	4, // [4:4] is the sub-list for method output_type
+	4, // [4:4] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
 }
 
 func init() { file_agent_v1_config_proto_init() }
@@ -448,7 +598,7 @@ func file_agent_v1_config_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_agent_v1_config_proto_rawDesc), len(file_agent_v1_config_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   5,
+			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -92,16 +92,23 @@ func (x *CoreInfo) GetCapabilities() []string {
 
 // CoreInstance describes a running core instance on agent.
 type CoreInstance struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	CoreType      string                 `protobuf:"bytes,2,opt,name=core_type,json=coreType,proto3" json:"core_type,omitempty"`
-	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
-	ListenPorts   []int32                `protobuf:"varint,4,rep,packed,name=listen_ports,json=listenPorts,proto3" json:"listen_ports,omitempty"`
-	ConfigPath    string                 `protobuf:"bytes,5,opt,name=config_path,json=configPath,proto3" json:"config_path,omitempty"`
-	ConfigHash    string                 `protobuf:"bytes,6,opt,name=config_hash,json=configHash,proto3" json:"config_hash,omitempty"`
-	Pid           int32                  `protobuf:"varint,7,opt,name=pid,proto3" json:"pid,omitempty"`
-	StartedAt     int64                  `protobuf:"varint,8,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
-	Error         string                 `protobuf:"bytes,9,opt,name=error,proto3" json:"error,omitempty"`
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CoreType    string                 `protobuf:"bytes,2,opt,name=core_type,json=coreType,proto3" json:"core_type,omitempty"`
+	Status      string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	ListenPorts []int32                `protobuf:"varint,4,rep,packed,name=listen_ports,json=listenPorts,proto3" json:"listen_ports,omitempty"`
+	ConfigPath  string                 `protobuf:"bytes,5,opt,name=config_path,json=configPath,proto3" json:"config_path,omitempty"`
+	ConfigHash  string                 `protobuf:"bytes,6,opt,name=config_hash,json=configHash,proto3" json:"config_hash,omitempty"`
+	Pid         int32                  `protobuf:"varint,7,opt,name=pid,proto3" json:"pid,omitempty"`
+	StartedAt   int64                  `protobuf:"varint,8,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	Error       string                 `protobuf:"bytes,9,opt,name=error,proto3" json:"error,omitempty"`
+	// Per-process resource usage, sampled on each status report
+	CpuPercent        float64 `protobuf:"fixed64,10,opt,name=cpu_percent,json=cpuPercent,proto3" json:"cpu_percent,omitempty"` // Percent of one CPU core since the previous sample
+	MemoryRssBytes    uint64  `protobuf:"varint,11,opt,name=memory_rss_bytes,json=memoryRssBytes,proto3" json:"memory_rss_bytes,omitempty"`
+	ResourceSampledAt int64   `protobuf:"varint,12,opt,name=resource_sampled_at,json=resourceSampledAt,proto3" json:"resource_sampled_at,omitempty"` // 0 when the process could not be sampled
+	// Restart history observed by the agent (unix seconds, oldest first, last 7 days at most)
+	RestartTimes  []int64 `protobuf:"varint,13,rep,packed,name=restart_times,json=restartTimes,proto3" json:"restart_times,omitempty"`
+	UptimeSeconds int64   `protobuf:"varint,14,opt,name=uptime_seconds,json=uptimeSeconds,proto3" json:"uptime_seconds,omitempty"` // 0 when the instance is not running
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -199,6 +206,41 @@ func (x *CoreInstance) GetError() string {
 	return ""
 }
 
+func (x *CoreInstance) GetCpuPercent() float64 {
+	if x != nil {
+		return x.CpuPercent
+	}
+	return 0
+}
+
+func (x *CoreInstance) GetMemoryRssBytes() uint64 {
+	if x != nil {
+		return x.MemoryRssBytes
+	}
+	return 0
+}
+
+func (x *CoreInstance) GetResourceSampledAt() int64 {
+	if x != nil {
+		return x.ResourceSampledAt
+	}
+	return 0
+}
+
+func (x *CoreInstance) GetRestartTimes() []int64 {
+	if x != nil {
+		return x.RestartTimes
+	}
+	return nil
+}
+
+func (x *CoreInstance) GetUptimeSeconds() int64 {
+	if x != nil {
+		return x.UptimeSeconds
+	}
+	return 0
+}
+
 // Legacy synchronous RPC payloads are kept temporarily so transition-period code can compile,
 // but they are no longer exposed as first-class RPCs on AgentService.
 type GetCoresRequest struct {
@@ -1005,6 +1047,51 @@ func (x *EnsureCorePayload) GetFlavor() string {
 	return ""
 }
 
+// UpgradeCorePayload asks the agent to download, verify and switch to a core release.
+type UpgradeCorePayload struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpgradeCorePayload) Reset() {
+	*x = UpgradeCorePayload{}
+	mi := &file_agent_v1_core_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpgradeCorePayload) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpgradeCorePayload) ProtoMessage() {}
+
+func (x *UpgradeCorePayload) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_v1_core_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpgradeCorePayload.ProtoReflect.Descriptor instead.
+func (*UpgradeCorePayload) Descriptor() ([]byte, []int) {
+	return file_agent_v1_core_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *UpgradeCorePayload) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
 type CoreOperation struct {
 	state          protoimpl.MessageState `protogen:"open.v1"`
 	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -1019,13 +1106,15 @@ type CoreOperation struct {
 	UpdatedAt      int64                  `protobuf:"varint,10,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
 	StartedAt      int64                  `protobuf:"varint,11,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
 	FinishedAt     int64                  `protobuf:"varint,12,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	Signature      string                 `protobuf:"bytes,13,opt,name=signature,proto3" json:"signature,omitempty"`      // Hex HMAC-SHA256 over id, agent_host_id, operation_type, core_type and request_payload
+	KeyId          string                 `protobuf:"bytes,14,opt,name=key_id,json=keyId,proto3" json:"key_id,omitempty"` // Identifies the shared secret used for signature
 	unknownFields  protoimpl.UnknownFields
 	sizeCache      protoimpl.SizeCache
 }
 
 func (x *CoreOperation) Reset() {
 	*x = CoreOperation{}
-	mi := &file_agent_v1_core_proto_msgTypes[13]
+	mi := &file_agent_v1_core_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1037,7 +1126,7 @@ func (x *CoreOperation) String() string {
 func (*CoreOperation) ProtoMessage() {}
 
 func (x *CoreOperation) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_v1_core_proto_msgTypes[13]
+	mi := &file_agent_v1_core_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1050,7 +1139,7 @@ func (x *CoreOperation) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CoreOperation.ProtoReflect.Descriptor instead.
 func (*CoreOperation) Descriptor() ([]byte, []int) {
-	return file_agent_v1_core_proto_rawDescGZIP(), []int{13}
+	return file_agent_v1_core_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *CoreOperation) GetId() string {
@@ -1137,6 +1226,20 @@ func (x *CoreOperation) GetFinishedAt() int64 {
 	return 0
 }
 
+func (x *CoreOperation) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
+}
+
+func (x *CoreOperation) GetKeyId() string {
+	if x != nil {
+		return x.KeyId
+	}
+	return ""
+}
+
 type GetCoreOperationsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Statuses      []string               `protobuf:"bytes,1,rep,name=statuses,proto3" json:"statuses,omitempty"`
@@ -1147,7 +1250,7 @@ type GetCoreOperationsRequest struct {
 
 func (x *GetCoreOperationsRequest) Reset() {
 	*x = GetCoreOperationsRequest{}
-	mi := &file_agent_v1_core_proto_msgTypes[14]
+	mi := &file_agent_v1_core_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1159,7 +1262,7 @@ func (x *GetCoreOperationsRequest) String() string {
 func (*GetCoreOperationsRequest) ProtoMessage() {}
 
 func (x *GetCoreOperationsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_v1_core_proto_msgTypes[14]
+	mi := &file_agent_v1_core_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1172,7 +1275,7 @@ func (x *GetCoreOperationsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetCoreOperationsRequest.ProtoReflect.Descriptor instead.
 func (*GetCoreOperationsRequest) Descriptor() ([]byte, []int) {
-	return file_agent_v1_core_proto_rawDescGZIP(), []int{14}
+	return file_agent_v1_core_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *GetCoreOperationsRequest) GetStatuses() []string {
@@ -1199,7 +1302,7 @@ type GetCoreOperationsResponse struct {
 
 func (x *GetCoreOperationsResponse) Reset() {
 	*x = GetCoreOperationsResponse{}
-	mi := &file_agent_v1_core_proto_msgTypes[15]
+	mi := &file_agent_v1_core_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1211,7 +1314,7 @@ func (x *GetCoreOperationsResponse) String() string {
 func (*GetCoreOperationsResponse) ProtoMessage() {}
 
 func (x *GetCoreOperationsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_v1_core_proto_msgTypes[15]
+	mi := &file_agent_v1_core_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1224,7 +1327,7 @@ func (x *GetCoreOperationsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetCoreOperationsResponse.ProtoReflect.Descriptor instead.
 func (*GetCoreOperationsResponse) Descriptor() ([]byte, []int) {
-	return file_agent_v1_core_proto_rawDescGZIP(), []int{15}
+	return file_agent_v1_core_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *GetCoreOperationsResponse) GetSuccess() bool {
@@ -1254,7 +1357,7 @@ type ReportCoreOperationRequest struct {
 
 func (x *ReportCoreOperationRequest) Reset() {
 	*x = ReportCoreOperationRequest{}
-	mi := &file_agent_v1_core_proto_msgTypes[16]
+	mi := &file_agent_v1_core_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1266,7 +1369,7 @@ func (x *ReportCoreOperationRequest) String() string {
 func (*ReportCoreOperationRequest) ProtoMessage() {}
 
 func (x *ReportCoreOperationRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_v1_core_proto_msgTypes[16]
+	mi := &file_agent_v1_core_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1279,7 +1382,7 @@ func (x *ReportCoreOperationRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ReportCoreOperationRequest.ProtoReflect.Descriptor instead.
 func (*ReportCoreOperationRequest) Descriptor() ([]byte, []int) {
-	return file_agent_v1_core_proto_rawDescGZIP(), []int{16}
+	return file_agent_v1_core_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *ReportCoreOperationRequest) GetOperationId() string {
@@ -1327,7 +1430,7 @@ type ReportCoreOperationResponse struct {
 
 func (x *ReportCoreOperationResponse) Reset() {
 	*x = ReportCoreOperationResponse{}
-	mi := &file_agent_v1_core_proto_msgTypes[17]
+	mi := &file_agent_v1_core_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1339,7 +1442,7 @@ func (x *ReportCoreOperationResponse) String() string {
 func (*ReportCoreOperationResponse) ProtoMessage() {}
 
 func (x *ReportCoreOperationResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_v1_core_proto_msgTypes[17]
+	mi := &file_agent_v1_core_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1352,7 +1455,7 @@ func (x *ReportCoreOperationResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ReportCoreOperationResponse.ProtoReflect.Descriptor instead.
 func (*ReportCoreOperationResponse) Descriptor() ([]byte, []int) {
-	return file_agent_v1_core_proto_rawDescGZIP(), []int{17}
+	return file_agent_v1_core_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *ReportCoreOperationResponse) GetSuccess() bool {
@@ -1378,7 +1481,7 @@ const file_agent_v1_core_proto_rawDesc = "" +
 	"\x04type\x18\x01 \x01(\tR\x04type\x12\x18\n" +
 	"\aversion\x18\x02 \x01(\tR\aversion\x12\x1c\n" +
 	"\tinstalled\x18\x03 \x01(\bR\tinstalled\x12\"\n" +
-	"\fcapabilities\x18\x04 \x03(\tR\fcapabilities\"\xff\x01\n" +
+	"\fcapabilities\x18\x04 \x03(\tR\fcapabilities\"\xc6\x03\n" +
 	"\fCoreInstance\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
 	"\tcore_type\x18\x02 \x01(\tR\bcoreType\x12\x16\n" +
@@ -1391,7 +1494,14 @@ const file_agent_v1_core_proto_rawDesc = "" +
 	"\x03pid\x18\a \x01(\x05R\x03pid\x12\x1d\n" +
 	"\n" +
 	"started_at\x18\b \x01(\x03R\tstartedAt\x12\x14\n" +
-	"\x05error\x18\t \x01(\tR\x05error\"\x11\n" +
+	"\x05error\x18\t \x01(\tR\x05error\x12\x1f\n" +
+	"\vcpu_percent\x18\n" +
+	" \x01(\x01R\n" +
+	"cpuPercent\x12(\n" +
+	"\x10memory_rss_bytes\x18\v \x01(\x04R\x0ememoryRssBytes\x12.\n" +
+	"\x13resource_sampled_at\x18\f \x01(\x03R\x11resourceSampledAt\x12#\n" +
+	"\rrestart_times\x18\r \x03(\x03R\frestartTimes\x12%\n" +
+	"\x0euptime_seconds\x18\x0e \x01(\x03R\ruptimeSeconds\"\x11\n" +
 	"\x0fGetCoresRequest\"r\n" +
 	"\x10GetCoresResponse\x12(\n" +
 	"\x05cores\x18\x01 \x03(\v2\x12.agent.v1.CoreInfoR\x05cores\x124\n" +
@@ -1462,7 +1572,9 @@ const file_agent_v1_core_proto_rawDesc = "" +
 	"\x11EnsureCorePayload\x12\x18\n" +
 	"\aversion\x18\x01 \x01(\tR\aversion\x12\x18\n" +
 	"\achannel\x18\x02 \x01(\tR\achannel\x12\x16\n" +
-	"\x06flavor\x18\x03 \x01(\tR\x06flavor\"\x92\x03\n" +
+	"\x06flavor\x18\x03 \x01(\tR\x06flavor\".\n" +
+	"\x12UpgradeCorePayload\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\"\xc7\x03\n" +
 	"\rCoreOperation\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\"\n" +
 	"\ragent_host_id\x18\x02 \x01(\x03R\vagentHostId\x12%\n" +
@@ -1480,7 +1592,9 @@ const file_agent_v1_core_proto_rawDesc = "" +
 	"\n" +
 	"started_at\x18\v \x01(\x03R\tstartedAt\x12\x1f\n" +
 	"\vfinished_at\x18\f \x01(\x03R\n" +
-	"finishedAt\"L\n" +
+	"finishedAt\x12\x1c\n" +
+	"\tsignature\x18\r \x01(\tR\tsignature\x12\x15\n" +
+	"\x06key_id\x18\x0e \x01(\tR\x05keyId\"L\n" +
 	"\x18GetCoreOperationsRequest\x12\x1a\n" +
 	"\bstatuses\x18\x01 \x03(\tR\bstatuses\x12\x14\n" +
 	"\x05limit\x18\x02 \x01(\x05R\x05limit\"n\n" +
@@ -1512,7 +1626,7 @@ func file_agent_v1_core_proto_rawDescGZIP() []byte {
 	return file_agent_v1_core_proto_rawDescData
 }
 
-var file_agent_v1_core_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
+var file_agent_v1_core_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
 var file_agent_v1_core_proto_goTypes = []any{
 	(*CoreInfo)(nil),                    // 0: agent.v1.CoreInfo
 	(*CoreInstance)(nil),                // 1: agent.v1.CoreInstance
@@ -1527,16 +1641,17 @@ var file_agent_v1_core_proto_goTypes = []any{
 	(*SwitchCorePayload)(nil),           // 10: agent.v1.SwitchCorePayload
 	(*InstallCorePayload)(nil),          // 11: agent.v1.InstallCorePayload
 	(*EnsureCorePayload)(nil),           // 12: agent.v1.EnsureCorePayload
-	(*CoreOperation)(nil),               // 13: agent.v1.CoreOperation
-	(*GetCoreOperationsRequest)(nil),    // 14: agent.v1.GetCoreOperationsRequest
-	(*GetCoreOperationsResponse)(nil),   // 15: agent.v1.GetCoreOperationsResponse
-	(*ReportCoreOperationRequest)(nil),  // 16: agent.v1.ReportCoreOperationRequest
-	(*ReportCoreOperationResponse)(nil), // 17: agent.v1.ReportCoreOperationResponse
+	(*UpgradeCorePayload)(nil),          // 13: agent.v1.UpgradeCorePayload
+	(*CoreOperation)(nil),               // 14: agent.v1.CoreOperation
+	(*GetCoreOperationsRequest)(nil),    // 15: agent.v1.GetCoreOperationsRequest
+	(*GetCoreOperationsResponse)(nil),   // 16: agent.v1.GetCoreOperationsResponse
+	(*ReportCoreOperationRequest)(nil),  // 17: agent.v1.ReportCoreOperationRequest
+	(*ReportCoreOperationResponse)(nil), // 18: agent.v1.ReportCoreOperationResponse
 }
 var file_agent_v1_core_proto_depIdxs = []int32{
 	0,  // 0: agent.v1.GetCoresResponse.cores:type_name -> agent.v1.CoreInfo
 	1,  // 1: agent.v1.GetCoresResponse.instances:type_name -> agent.v1.CoreInstance
-	13, // 2: agent.v1.GetCoreOperationsResponse.operations:type_name -> agent.v1.CoreOperation
+	14, // 2: agent.v1.GetCoreOperationsResponse.operations:type_name -> agent.v1.CoreOperation
 	3,  // [3:3] is the sub-list for method output_type
 	3,  // [3:3] is the sub-list for method input_type
 	3,  // [3:3] is the sub-list for extension type_name
@@ -1555,7 +1670,7 @@ func file_agent_v1_core_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_agent_v1_core_proto_rawDesc), len(file_agent_v1_core_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   18,
+			NumMessages:   19,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
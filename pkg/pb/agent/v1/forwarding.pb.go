@@ -154,8 +154,12 @@ type ForwardingRule struct {
 	TargetPort    int32                  `protobuf:"varint,6,opt,name=target_port,json=targetPort,proto3" json:"target_port,omitempty"`
 	Priority      int32                  `protobuf:"varint,7,opt,name=priority,proto3" json:"priority,omitempty"`
 	Enabled       bool                   `protobuf:"varint,8,opt,name=enabled,proto3" json:"enabled,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	// Weighted backends; when empty, target_address/target_port is the only target.
+	Targets             []*ForwardingTarget `protobuf:"bytes,9,rep,name=targets,proto3" json:"targets,omitempty"`
+	HealthCheckInterval int32               `protobuf:"varint,10,opt,name=health_check_interval,json=healthCheckInterval,proto3" json:"health_check_interval,omitempty"` // Seconds between target probes, 0 disables health checks.
+	HealthCheckTimeout  int32               `protobuf:"varint,11,opt,name=health_check_timeout,json=healthCheckTimeout,proto3" json:"health_check_timeout,omitempty"`    // Seconds per probe, 0 uses the agent default.
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
 }
 
 func (x *ForwardingRule) Reset() {
@@ -244,20 +248,104 @@ func (x *ForwardingRule) GetEnabled() bool {
 	return false
 }
 
+func (x *ForwardingRule) GetTargets() []*ForwardingTarget {
+	if x != nil {
+		return x.Targets
+	}
+	return nil
+}
+
+func (x *ForwardingRule) GetHealthCheckInterval() int32 {
+	if x != nil {
+		return x.HealthCheckInterval
+	}
+	return 0
+}
+
+func (x *ForwardingRule) GetHealthCheckTimeout() int32 {
+	if x != nil {
+		return x.HealthCheckTimeout
+	}
+	return 0
+}
+
+// ForwardingTarget is one weighted backend of a forwarding rule.
+type ForwardingTarget struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Address       string                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Port          int32                  `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"`
+	Weight        int32                  `protobuf:"varint,3,opt,name=weight,proto3" json:"weight,omitempty"` // Positive round-robin weight.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ForwardingTarget) Reset() {
+	*x = ForwardingTarget{}
+	mi := &file_agent_v1_forwarding_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ForwardingTarget) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ForwardingTarget) ProtoMessage() {}
+
+func (x *ForwardingTarget) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_v1_forwarding_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ForwardingTarget.ProtoReflect.Descriptor instead.
+func (*ForwardingTarget) Descriptor() ([]byte, []int) {
+	return file_agent_v1_forwarding_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ForwardingTarget) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *ForwardingTarget) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *ForwardingTarget) GetWeight() int32 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
 // ForwardingStatusReport reports apply result from agent.
 type ForwardingStatusReport struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Version       int64                  `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"` // Applied version.
-	Success       bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
-	ErrorMessage  string                 `protobuf:"bytes,3,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
-	AppliedAt     int64                  `protobuf:"varint,4,opt,name=applied_at,json=appliedAt,proto3" json:"applied_at,omitempty"`
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Version      int64                  `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"` // Applied version.
+	Success      bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+	ErrorMessage string                 `protobuf:"bytes,3,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	AppliedAt    int64                  `protobuf:"varint,4,opt,name=applied_at,json=appliedAt,proto3" json:"applied_at,omitempty"`
+	// Target health transitions; a report carrying these is not an apply result.
+	TargetHealth  []*ForwardingTargetHealth `protobuf:"bytes,5,rep,name=target_health,json=targetHealth,proto3" json:"target_health,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ForwardingStatusReport) Reset() {
 	*x = ForwardingStatusReport{}
-	mi := &file_agent_v1_forwarding_proto_msgTypes[3]
+	mi := &file_agent_v1_forwarding_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -269,7 +357,7 @@ func (x *ForwardingStatusReport) String() string {
 func (*ForwardingStatusReport) ProtoMessage() {}
 
 func (x *ForwardingStatusReport) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_v1_forwarding_proto_msgTypes[3]
+	mi := &file_agent_v1_forwarding_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -282,7 +370,7 @@ func (x *ForwardingStatusReport) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ForwardingStatusReport.ProtoReflect.Descriptor instead.
 func (*ForwardingStatusReport) Descriptor() ([]byte, []int) {
-	return file_agent_v1_forwarding_proto_rawDescGZIP(), []int{3}
+	return file_agent_v1_forwarding_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *ForwardingStatusReport) GetVersion() int64 {
@@ -313,6 +401,98 @@ func (x *ForwardingStatusReport) GetAppliedAt() int64 {
 	return 0
 }
 
+func (x *ForwardingStatusReport) GetTargetHealth() []*ForwardingTargetHealth {
+	if x != nil {
+		return x.TargetHealth
+	}
+	return nil
+}
+
+// ForwardingTargetHealth reports the probed health of one forwarding target.
+type ForwardingTargetHealth struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RuleId        int64                  `protobuf:"varint,1,opt,name=rule_id,json=ruleId,proto3" json:"rule_id,omitempty"`
+	Address       string                 `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	Port          int32                  `protobuf:"varint,3,opt,name=port,proto3" json:"port,omitempty"`
+	Healthy       bool                   `protobuf:"varint,4,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	Error         string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	CheckedAt     int64                  `protobuf:"varint,6,opt,name=checked_at,json=checkedAt,proto3" json:"checked_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ForwardingTargetHealth) Reset() {
+	*x = ForwardingTargetHealth{}
+	mi := &file_agent_v1_forwarding_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ForwardingTargetHealth) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ForwardingTargetHealth) ProtoMessage() {}
+
+func (x *ForwardingTargetHealth) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_v1_forwarding_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ForwardingTargetHealth.ProtoReflect.Descriptor instead.
+func (*ForwardingTargetHealth) Descriptor() ([]byte, []int) {
+	return file_agent_v1_forwarding_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ForwardingTargetHealth) GetRuleId() int64 {
+	if x != nil {
+		return x.RuleId
+	}
+	return 0
+}
+
+func (x *ForwardingTargetHealth) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *ForwardingTargetHealth) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *ForwardingTargetHealth) GetHealthy() bool {
+	if x != nil {
+		return x.Healthy
+	}
+	return false
+}
+
+func (x *ForwardingTargetHealth) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ForwardingTargetHealth) GetCheckedAt() int64 {
+	if x != nil {
+		return x.CheckedAt
+	}
+	return 0
+}
+
 var File_agent_v1_forwarding_proto protoreflect.FileDescriptor
 
 const file_agent_v1_forwarding_proto_rawDesc = "" +
@@ -325,7 +505,7 @@ const file_agent_v1_forwarding_proto_rawDesc = "" +
 	"\fnot_modified\x18\x02 \x01(\bR\vnotModified\x12.\n" +
 	"\x05rules\x18\x03 \x03(\v2\x18.agent.v1.ForwardingRuleR\x05rules\x12\x18\n" +
 	"\aversion\x18\x04 \x01(\x03R\aversion\x12#\n" +
-	"\rerror_message\x18\x05 \x01(\tR\ferrorMessage\"\xef\x01\n" +
+	"\rerror_message\x18\x05 \x01(\tR\ferrorMessage\"\x8b\x03\n" +
 	"\x0eForwardingRule\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1a\n" +
@@ -336,13 +516,30 @@ const file_agent_v1_forwarding_proto_rawDesc = "" +
 	"\vtarget_port\x18\x06 \x01(\x05R\n" +
 	"targetPort\x12\x1a\n" +
 	"\bpriority\x18\a \x01(\x05R\bpriority\x12\x18\n" +
-	"\aenabled\x18\b \x01(\bR\aenabled\"\x90\x01\n" +
+	"\aenabled\x18\b \x01(\bR\aenabled\x124\n" +
+	"\atargets\x18\t \x03(\v2\x1a.agent.v1.ForwardingTargetR\atargets\x122\n" +
+	"\x15health_check_interval\x18\n" +
+	" \x01(\x05R\x13healthCheckInterval\x120\n" +
+	"\x14health_check_timeout\x18\v \x01(\x05R\x12healthCheckTimeout\"X\n" +
+	"\x10ForwardingTarget\x12\x18\n" +
+	"\aaddress\x18\x01 \x01(\tR\aaddress\x12\x12\n" +
+	"\x04port\x18\x02 \x01(\x05R\x04port\x12\x16\n" +
+	"\x06weight\x18\x03 \x01(\x05R\x06weight\"\xd7\x01\n" +
 	"\x16ForwardingStatusReport\x12\x18\n" +
 	"\aversion\x18\x01 \x01(\x03R\aversion\x12\x18\n" +
 	"\asuccess\x18\x02 \x01(\bR\asuccess\x12#\n" +
 	"\rerror_message\x18\x03 \x01(\tR\ferrorMessage\x12\x1d\n" +
 	"\n" +
-	"applied_at\x18\x04 \x01(\x03R\tappliedAtB:Z8github.com/creamcroissant/xboard/pkg/pb/agent/v1;agentv1b\x06proto3"
+	"applied_at\x18\x04 \x01(\x03R\tappliedAt\x12E\n" +
+	"\rtarget_health\x18\x05 \x03(\v2 .agent.v1.ForwardingTargetHealthR\ftargetHealth\"\xae\x01\n" +
+	"\x16ForwardingTargetHealth\x12\x17\n" +
+	"\arule_id\x18\x01 \x01(\x03R\x06ruleId\x12\x18\n" +
+	"\aaddress\x18\x02 \x01(\tR\aaddress\x12\x12\n" +
+	"\x04port\x18\x03 \x01(\x05R\x04port\x12\x18\n" +
+	"\ahealthy\x18\x04 \x01(\bR\ahealthy\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\x12\x1d\n" +
+	"\n" +
+	"checked_at\x18\x06 \x01(\x03R\tcheckedAtB:Z8github.com/creamcroissant/xboard/pkg/pb/agent/v1;agentv1b\x06proto3"
 
 var (
 	file_agent_v1_forwarding_proto_rawDescOnce sync.Once
@@ -356,20 +553,24 @@ func file_agent_v1_forwarding_proto_rawDescGZIP() []byte {
 	return file_agent_v1_forwarding_proto_rawDescData
 }
 
-var file_agent_v1_forwarding_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_agent_v1_forwarding_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_agent_v1_forwarding_proto_goTypes = []any{
 	(*ForwardingRulesRequest)(nil),  // 0: agent.v1.ForwardingRulesRequest
 	(*ForwardingRulesResponse)(nil), // 1: agent.v1.ForwardingRulesResponse
 	(*ForwardingRule)(nil),          // 2: agent.v1.ForwardingRule
-	(*ForwardingStatusReport)(nil),  // 3: agent.v1.ForwardingStatusReport
+	(*ForwardingTarget)(nil),        // 3: agent.v1.ForwardingTarget
+	(*ForwardingStatusReport)(nil),  // 4: agent.v1.ForwardingStatusReport
+	(*ForwardingTargetHealth)(nil),  // 5: agent.v1.ForwardingTargetHealth
 }
 var file_agent_v1_forwarding_proto_depIdxs = []int32{
 	2, // 0: agent.v1.ForwardingRulesResponse.rules:type_name -> agent.v1.ForwardingRule
-	1, // [1:1] is the sub-list for method output_type
-	1, // [1:1] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	3, // 1: agent.v1.ForwardingRule.targets:type_name -> agent.v1.ForwardingTarget
+	5, // 2: agent.v1.ForwardingStatusReport.target_health:type_name -> agent.v1.ForwardingTargetHealth
+	3, // [3:3] is the sub-list for method output_type
+	3, // [3:3] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_agent_v1_forwarding_proto_init() }
@@ -383,7 +584,7 @@ func file_agent_v1_forwarding_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_agent_v1_forwarding_proto_rawDesc), len(file_agent_v1_forwarding_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
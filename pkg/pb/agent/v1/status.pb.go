@@ -121,20 +121,24 @@ func (x *HeartbeatResponse) GetServerTime() int64 {
 
 // StatusReport contains system and network metrics
 type StatusReport struct {
-	state         protoimpl.MessageState  `protogen:"open.v1"`
-	Timestamp     int64                   `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	System        *SystemMetrics          `protobuf:"bytes,2,opt,name=system,proto3" json:"system,omitempty"`
-	Network       *NetworkMetrics         `protobuf:"bytes,3,opt,name=network,proto3" json:"network,omitempty"`
-	Protocols     []*ProtocolState        `protobuf:"bytes,4,rep,name=protocols,proto3" json:"protocols,omitempty"`
-	ClientConfigs *ClientConfigReport     `protobuf:"bytes,5,opt,name=client_configs,json=clientConfigs,proto3" json:"client_configs,omitempty"` // Client configurations from subscribe directory
-	Instances     []*CoreInstance         `protobuf:"bytes,6,rep,name=instances,proto3" json:"instances,omitempty"`                              // Core instances on agent
-	Inventory     []*ConfigInventoryEntry `protobuf:"bytes,7,rep,name=inventory,proto3" json:"inventory,omitempty"`                              // Applied config file inventory
-	InboundIndex  []*InboundIndexEntry    `protobuf:"bytes,8,rep,name=inbound_index,json=inboundIndex,proto3" json:"inbound_index,omitempty"`    // Parsed inbound semantic index
-	ReportedAt    int64                   `protobuf:"varint,9,opt,name=reported_at,json=reportedAt,proto3" json:"reported_at,omitempty"`
-	CommandQueue  *AgentCommandQueueStats `protobuf:"bytes,10,opt,name=command_queue,json=commandQueue,proto3" json:"command_queue,omitempty"`
-	UpdateStatus  *AgentUpdateStatus      `protobuf:"bytes,11,opt,name=update_status,json=updateStatus,proto3" json:"update_status,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state             protoimpl.MessageState  `protogen:"open.v1"`
+	Timestamp         int64                   `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	System            *SystemMetrics          `protobuf:"bytes,2,opt,name=system,proto3" json:"system,omitempty"`
+	Network           *NetworkMetrics         `protobuf:"bytes,3,opt,name=network,proto3" json:"network,omitempty"`
+	Protocols         []*ProtocolState        `protobuf:"bytes,4,rep,name=protocols,proto3" json:"protocols,omitempty"`
+	ClientConfigs     *ClientConfigReport     `protobuf:"bytes,5,opt,name=client_configs,json=clientConfigs,proto3" json:"client_configs,omitempty"` // Client configurations from subscribe directory
+	Instances         []*CoreInstance         `protobuf:"bytes,6,rep,name=instances,proto3" json:"instances,omitempty"`                              // Core instances on agent
+	Inventory         []*ConfigInventoryEntry `protobuf:"bytes,7,rep,name=inventory,proto3" json:"inventory,omitempty"`                              // Applied config file inventory
+	InboundIndex      []*InboundIndexEntry    `protobuf:"bytes,8,rep,name=inbound_index,json=inboundIndex,proto3" json:"inbound_index,omitempty"`    // Parsed inbound semantic index
+	ReportedAt        int64                   `protobuf:"varint,9,opt,name=reported_at,json=reportedAt,proto3" json:"reported_at,omitempty"`
+	CommandQueue      *AgentCommandQueueStats `protobuf:"bytes,10,opt,name=command_queue,json=commandQueue,proto3" json:"command_queue,omitempty"`
+	UpdateStatus      *AgentUpdateStatus      `protobuf:"bytes,11,opt,name=update_status,json=updateStatus,proto3" json:"update_status,omitempty"`
+	AliveDevices      []*AliveDevice          `protobuf:"bytes,12,rep,name=alive_devices,json=aliveDevices,proto3" json:"alive_devices,omitempty"`                  // Online (user_id, ip) tuples
+	ReloadDrain       *ReloadDrainResult      `protobuf:"bytes,13,opt,name=reload_drain,json=reloadDrain,proto3" json:"reload_drain,omitempty"`                     // Last config reload drain outcome, sent once
+	CoreVersionChange *CoreVersionChange      `protobuf:"bytes,14,opt,name=core_version_change,json=coreVersionChange,proto3" json:"core_version_change,omitempty"` // Core binary version change detected since last report, sent once
+	Diagnostics       *AgentDiagnostics       `protobuf:"bytes,15,opt,name=diagnostics,proto3" json:"diagnostics,omitempty"`                                        // Self-diagnostics bundle, sent on a slower cadence than metrics
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
 }
 
 func (x *StatusReport) Reset() {
@@ -244,6 +248,345 @@ func (x *StatusReport) GetUpdateStatus() *AgentUpdateStatus {
 	return nil
 }
 
+func (x *StatusReport) GetAliveDevices() []*AliveDevice {
+	if x != nil {
+		return x.AliveDevices
+	}
+	return nil
+}
+
+func (x *StatusReport) GetReloadDrain() *ReloadDrainResult {
+	if x != nil {
+		return x.ReloadDrain
+	}
+	return nil
+}
+
+func (x *StatusReport) GetCoreVersionChange() *CoreVersionChange {
+	if x != nil {
+		return x.CoreVersionChange
+	}
+	return nil
+}
+
+func (x *StatusReport) GetDiagnostics() *AgentDiagnostics {
+	if x != nil {
+		return x.Diagnostics
+	}
+	return nil
+}
+
+// AgentDiagnostics is a redacted self-diagnostics bundle collected by the agent.
+type AgentDiagnostics struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	CoreType              string                 `protobuf:"bytes,1,opt,name=core_type,json=coreType,proto3" json:"core_type,omitempty"`
+	CoreVersion           string                 `protobuf:"bytes,2,opt,name=core_version,json=coreVersion,proto3" json:"core_version,omitempty"`
+	AgentVersion          string                 `protobuf:"bytes,3,opt,name=agent_version,json=agentVersion,proto3" json:"agent_version,omitempty"`
+	InitSystem            string                 `protobuf:"bytes,4,opt,name=init_system,json=initSystem,proto3" json:"init_system,omitempty"`
+	ConfigValid           bool                   `protobuf:"varint,5,opt,name=config_valid,json=configValid,proto3" json:"config_valid,omitempty"`
+	ConfigValidationError string                 `protobuf:"bytes,6,opt,name=config_validation_error,json=configValidationError,proto3" json:"config_validation_error,omitempty"`
+	LastApplyError        string                 `protobuf:"bytes,7,opt,name=last_apply_error,json=lastApplyError,proto3" json:"last_apply_error,omitempty"`
+	LastApplyErrorAt      int64                  `protobuf:"varint,8,opt,name=last_apply_error_at,json=lastApplyErrorAt,proto3" json:"last_apply_error_at,omitempty"`
+	ConfigDir             string                 `protobuf:"bytes,9,opt,name=config_dir,json=configDir,proto3" json:"config_dir,omitempty"`
+	ConfigDirFreeBytes    uint64                 `protobuf:"varint,10,opt,name=config_dir_free_bytes,json=configDirFreeBytes,proto3" json:"config_dir_free_bytes,omitempty"`
+	ConfigDirTotalBytes   uint64                 `protobuf:"varint,11,opt,name=config_dir_total_bytes,json=configDirTotalBytes,proto3" json:"config_dir_total_bytes,omitempty"`
+	OpenFds               int64                  `protobuf:"varint,12,opt,name=open_fds,json=openFds,proto3" json:"open_fds,omitempty"` // -1 when unavailable on this platform
+	MaxFds                int64                  `protobuf:"varint,13,opt,name=max_fds,json=maxFds,proto3" json:"max_fds,omitempty"`    // -1 when unavailable on this platform
+	CollectedAt           int64                  `protobuf:"varint,14,opt,name=collected_at,json=collectedAt,proto3" json:"collected_at,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *AgentDiagnostics) Reset() {
+	*x = AgentDiagnostics{}
+	mi := &file_agent_v1_status_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentDiagnostics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentDiagnostics) ProtoMessage() {}
+
+func (x *AgentDiagnostics) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_v1_status_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentDiagnostics.ProtoReflect.Descriptor instead.
+func (*AgentDiagnostics) Descriptor() ([]byte, []int) {
+	return file_agent_v1_status_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *AgentDiagnostics) GetCoreType() string {
+	if x != nil {
+		return x.CoreType
+	}
+	return ""
+}
+
+func (x *AgentDiagnostics) GetCoreVersion() string {
+	if x != nil {
+		return x.CoreVersion
+	}
+	return ""
+}
+
+func (x *AgentDiagnostics) GetAgentVersion() string {
+	if x != nil {
+		return x.AgentVersion
+	}
+	return ""
+}
+
+func (x *AgentDiagnostics) GetInitSystem() string {
+	if x != nil {
+		return x.InitSystem
+	}
+	return ""
+}
+
+func (x *AgentDiagnostics) GetConfigValid() bool {
+	if x != nil {
+		return x.ConfigValid
+	}
+	return false
+}
+
+func (x *AgentDiagnostics) GetConfigValidationError() string {
+	if x != nil {
+		return x.ConfigValidationError
+	}
+	return ""
+}
+
+func (x *AgentDiagnostics) GetLastApplyError() string {
+	if x != nil {
+		return x.LastApplyError
+	}
+	return ""
+}
+
+func (x *AgentDiagnostics) GetLastApplyErrorAt() int64 {
+	if x != nil {
+		return x.LastApplyErrorAt
+	}
+	return 0
+}
+
+func (x *AgentDiagnostics) GetConfigDir() string {
+	if x != nil {
+		return x.ConfigDir
+	}
+	return ""
+}
+
+func (x *AgentDiagnostics) GetConfigDirFreeBytes() uint64 {
+	if x != nil {
+		return x.ConfigDirFreeBytes
+	}
+	return 0
+}
+
+func (x *AgentDiagnostics) GetConfigDirTotalBytes() uint64 {
+	if x != nil {
+		return x.ConfigDirTotalBytes
+	}
+	return 0
+}
+
+func (x *AgentDiagnostics) GetOpenFds() int64 {
+	if x != nil {
+		return x.OpenFds
+	}
+	return 0
+}
+
+func (x *AgentDiagnostics) GetMaxFds() int64 {
+	if x != nil {
+		return x.MaxFds
+	}
+	return 0
+}
+
+func (x *AgentDiagnostics) GetCollectedAt() int64 {
+	if x != nil {
+		return x.CollectedAt
+	}
+	return 0
+}
+
+// CoreVersionChange reports that capability re-detection found a different core.
+type CoreVersionChange struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	PreviousCoreType string                 `protobuf:"bytes,1,opt,name=previous_core_type,json=previousCoreType,proto3" json:"previous_core_type,omitempty"`
+	PreviousVersion  string                 `protobuf:"bytes,2,opt,name=previous_version,json=previousVersion,proto3" json:"previous_version,omitempty"`
+	CoreType         string                 `protobuf:"bytes,3,opt,name=core_type,json=coreType,proto3" json:"core_type,omitempty"`
+	Version          string                 `protobuf:"bytes,4,opt,name=version,proto3" json:"version,omitempty"`
+	Reason           string                 `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"` // binary_changed, forced
+	DetectedAt       int64                  `protobuf:"varint,6,opt,name=detected_at,json=detectedAt,proto3" json:"detected_at,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *CoreVersionChange) Reset() {
+	*x = CoreVersionChange{}
+	mi := &file_agent_v1_status_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CoreVersionChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CoreVersionChange) ProtoMessage() {}
+
+func (x *CoreVersionChange) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_v1_status_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CoreVersionChange.ProtoReflect.Descriptor instead.
+func (*CoreVersionChange) Descriptor() ([]byte, []int) {
+	return file_agent_v1_status_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CoreVersionChange) GetPreviousCoreType() string {
+	if x != nil {
+		return x.PreviousCoreType
+	}
+	return ""
+}
+
+func (x *CoreVersionChange) GetPreviousVersion() string {
+	if x != nil {
+		return x.PreviousVersion
+	}
+	return ""
+}
+
+func (x *CoreVersionChange) GetCoreType() string {
+	if x != nil {
+		return x.CoreType
+	}
+	return ""
+}
+
+func (x *CoreVersionChange) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *CoreVersionChange) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *CoreVersionChange) GetDetectedAt() int64 {
+	if x != nil {
+		return x.DetectedAt
+	}
+	return 0
+}
+
+// ReloadDrainResult reports how a config reload treated existing connections.
+type ReloadDrainResult struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Mode              string                 `protobuf:"bytes,1,opt,name=mode,proto3" json:"mode,omitempty"`                                                     // reload, restart, switcher
+	ConnectionsBefore int32                  `protobuf:"varint,2,opt,name=connections_before,json=connectionsBefore,proto3" json:"connections_before,omitempty"` // Active connections when the reload started
+	Drained           int32                  `protobuf:"varint,3,opt,name=drained,proto3" json:"drained,omitempty"`                                              // Connections that finished or stayed on the old instance
+	Remaining         int32                  `protobuf:"varint,4,opt,name=remaining,proto3" json:"remaining,omitempty"`                                          // Connections cut by the restart after drain_timeout
+	FinishedAt        int64                  `protobuf:"varint,5,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *ReloadDrainResult) Reset() {
+	*x = ReloadDrainResult{}
+	mi := &file_agent_v1_status_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReloadDrainResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReloadDrainResult) ProtoMessage() {}
+
+func (x *ReloadDrainResult) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_v1_status_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReloadDrainResult.ProtoReflect.Descriptor instead.
+func (*ReloadDrainResult) Descriptor() ([]byte, []int) {
+	return file_agent_v1_status_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ReloadDrainResult) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *ReloadDrainResult) GetConnectionsBefore() int32 {
+	if x != nil {
+		return x.ConnectionsBefore
+	}
+	return 0
+}
+
+func (x *ReloadDrainResult) GetDrained() int32 {
+	if x != nil {
+		return x.Drained
+	}
+	return 0
+}
+
+func (x *ReloadDrainResult) GetRemaining() int32 {
+	if x != nil {
+		return x.Remaining
+	}
+	return 0
+}
+
+func (x *ReloadDrainResult) GetFinishedAt() int64 {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return 0
+}
+
 type AgentCommandQueueStats struct {
 	state            protoimpl.MessageState `protogen:"open.v1"`
 	Capacity         int32                  `protobuf:"varint,1,opt,name=capacity,proto3" json:"capacity,omitempty"`
@@ -259,7 +602,7 @@ type AgentCommandQueueStats struct {
 
 func (x *AgentCommandQueueStats) Reset() {
 	*x = AgentCommandQueueStats{}
-	mi := &file_agent_v1_status_proto_msgTypes[3]
+	mi := &file_agent_v1_status_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -271,7 +614,7 @@ func (x *AgentCommandQueueStats) String() string {
 func (*AgentCommandQueueStats) ProtoMessage() {}
 
 func (x *AgentCommandQueueStats) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_v1_status_proto_msgTypes[3]
+	mi := &file_agent_v1_status_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -284,7 +627,7 @@ func (x *AgentCommandQueueStats) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AgentCommandQueueStats.ProtoReflect.Descriptor instead.
 func (*AgentCommandQueueStats) Descriptor() ([]byte, []int) {
-	return file_agent_v1_status_proto_rawDescGZIP(), []int{3}
+	return file_agent_v1_status_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *AgentCommandQueueStats) GetCapacity() int32 {
@@ -359,7 +702,7 @@ type AgentUpdateStatus struct {
 
 func (x *AgentUpdateStatus) Reset() {
 	*x = AgentUpdateStatus{}
-	mi := &file_agent_v1_status_proto_msgTypes[4]
+	mi := &file_agent_v1_status_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -371,7 +714,7 @@ func (x *AgentUpdateStatus) String() string {
 func (*AgentUpdateStatus) ProtoMessage() {}
 
 func (x *AgentUpdateStatus) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_v1_status_proto_msgTypes[4]
+	mi := &file_agent_v1_status_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -384,7 +727,7 @@ func (x *AgentUpdateStatus) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AgentUpdateStatus.ProtoReflect.Descriptor instead.
 func (*AgentUpdateStatus) Descriptor() ([]byte, []int) {
-	return file_agent_v1_status_proto_rawDescGZIP(), []int{4}
+	return file_agent_v1_status_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *AgentUpdateStatus) GetCurrentVersion() string {
@@ -497,7 +840,7 @@ type ProtocolState struct {
 	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`                                  // Config filename (e.g. "11_vless-reality_inbounds.json")
 	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`                                  // Core type (e.g. "sing-box", "xray")
 	Running       bool                   `protobuf:"varint,3,opt,name=running,proto3" json:"running,omitempty"`                           // Service status
-	ContentHash   string                 `protobuf:"bytes,4,opt,name=content_hash,json=contentHash,proto3" json:"content_hash,omitempty"` // Content hash for change detection
+	ContentHash   string                 `protobuf:"bytes,4,opt,name=content_hash,json=contentHash,proto3" json:"content_hash,omitempty"` // Drift hash of the config, ignoring injected users
 	Details       []*ProtocolDetails     `protobuf:"bytes,5,rep,name=details,proto3" json:"details,omitempty"`                            // Parsed protocol details
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -505,7 +848,7 @@ type ProtocolState struct {
 
 func (x *ProtocolState) Reset() {
 	*x = ProtocolState{}
-	mi := &file_agent_v1_status_proto_msgTypes[5]
+	mi := &file_agent_v1_status_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -517,7 +860,7 @@ func (x *ProtocolState) String() string {
 func (*ProtocolState) ProtoMessage() {}
 
 func (x *ProtocolState) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_v1_status_proto_msgTypes[5]
+	mi := &file_agent_v1_status_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -530,7 +873,7 @@ func (x *ProtocolState) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ProtocolState.ProtoReflect.Descriptor instead.
 func (*ProtocolState) Descriptor() ([]byte, []int) {
-	return file_agent_v1_status_proto_rawDescGZIP(), []int{5}
+	return file_agent_v1_status_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *ProtocolState) GetName() string {
@@ -580,14 +923,15 @@ type ProtocolDetails struct {
 	Users         []*ProtocolUserInfo    `protobuf:"bytes,7,rep,name=users,proto3" json:"users,omitempty"`
 	SourceFile    string                 `protobuf:"bytes,8,opt,name=source_file,json=sourceFile,proto3" json:"source_file,omitempty"`
 	CoreType      string                 `protobuf:"bytes,9,opt,name=core_type,json=coreType,proto3" json:"core_type,omitempty"`
-	Multiplex     *MultiplexConfig       `protobuf:"bytes,10,opt,name=multiplex,proto3" json:"multiplex,omitempty"` // Multiplex configuration
+	Multiplex     *MultiplexConfig       `protobuf:"bytes,10,opt,name=multiplex,proto3" json:"multiplex,omitempty"`   // Multiplex configuration
+	Encryption    string                 `protobuf:"bytes,11,opt,name=encryption,proto3" json:"encryption,omitempty"` // VLESS encryption (xray "decryption"), empty means none
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ProtocolDetails) Reset() {
 	*x = ProtocolDetails{}
-	mi := &file_agent_v1_status_proto_msgTypes[6]
+	mi := &file_agent_v1_status_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -599,7 +943,7 @@ func (x *ProtocolDetails) String() string {
 func (*ProtocolDetails) ProtoMessage() {}
 
 func (x *ProtocolDetails) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_v1_status_proto_msgTypes[6]
+	mi := &file_agent_v1_status_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -612,7 +956,7 @@ func (x *ProtocolDetails) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ProtocolDetails.ProtoReflect.Descriptor instead.
 func (*ProtocolDetails) Descriptor() ([]byte, []int) {
-	return file_agent_v1_status_proto_rawDescGZIP(), []int{6}
+	return file_agent_v1_status_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *ProtocolDetails) GetProtocol() string {
@@ -685,6 +1029,13 @@ func (x *ProtocolDetails) GetMultiplex() *MultiplexConfig {
 	return nil
 }
 
+func (x *ProtocolDetails) GetEncryption() string {
+	if x != nil {
+		return x.Encryption
+	}
+	return ""
+}
+
 // TransportConfig describes transport layer settings
 type TransportConfig struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -698,7 +1049,7 @@ type TransportConfig struct {
 
 func (x *TransportConfig) Reset() {
 	*x = TransportConfig{}
-	mi := &file_agent_v1_status_proto_msgTypes[7]
+	mi := &file_agent_v1_status_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -710,7 +1061,7 @@ func (x *TransportConfig) String() string {
 func (*TransportConfig) ProtoMessage() {}
 
 func (x *TransportConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_v1_status_proto_msgTypes[7]
+	mi := &file_agent_v1_status_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -723,7 +1074,7 @@ func (x *TransportConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TransportConfig.ProtoReflect.Descriptor instead.
 func (*TransportConfig) Descriptor() ([]byte, []int) {
-	return file_agent_v1_status_proto_rawDescGZIP(), []int{7}
+	return file_agent_v1_status_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *TransportConfig) GetType() string {
@@ -761,13 +1112,14 @@ type TLSConfig struct {
 	ServerName    string                 `protobuf:"bytes,2,opt,name=server_name,json=serverName,proto3" json:"server_name,omitempty"`
 	Alpn          []string               `protobuf:"bytes,3,rep,name=alpn,proto3" json:"alpn,omitempty"`
 	Reality       *RealityConfig         `protobuf:"bytes,4,opt,name=reality,proto3" json:"reality,omitempty"`
+	EchEnabled    bool                   `protobuf:"varint,5,opt,name=ech_enabled,json=echEnabled,proto3" json:"ech_enabled,omitempty"` // Encrypted Client Hello enabled
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *TLSConfig) Reset() {
 	*x = TLSConfig{}
-	mi := &file_agent_v1_status_proto_msgTypes[8]
+	mi := &file_agent_v1_status_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -779,7 +1131,7 @@ func (x *TLSConfig) String() string {
 func (*TLSConfig) ProtoMessage() {}
 
 func (x *TLSConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_v1_status_proto_msgTypes[8]
+	mi := &file_agent_v1_status_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -792,7 +1144,7 @@ func (x *TLSConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TLSConfig.ProtoReflect.Descriptor instead.
 func (*TLSConfig) Descriptor() ([]byte, []int) {
-	return file_agent_v1_status_proto_rawDescGZIP(), []int{8}
+	return file_agent_v1_status_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *TLSConfig) GetEnabled() bool {
@@ -823,6 +1175,13 @@ func (x *TLSConfig) GetReality() *RealityConfig {
 	return nil
 }
 
+func (x *TLSConfig) GetEchEnabled() bool {
+	if x != nil {
+		return x.EchEnabled
+	}
+	return false
+}
+
 // RealityConfig describes XTLS Reality settings
 type RealityConfig struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -839,7 +1198,7 @@ type RealityConfig struct {
 
 func (x *RealityConfig) Reset() {
 	*x = RealityConfig{}
-	mi := &file_agent_v1_status_proto_msgTypes[9]
+	mi := &file_agent_v1_status_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -851,7 +1210,7 @@ func (x *RealityConfig) String() string {
 func (*RealityConfig) ProtoMessage() {}
 
 func (x *RealityConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_v1_status_proto_msgTypes[9]
+	mi := &file_agent_v1_status_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -864,7 +1223,7 @@ func (x *RealityConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RealityConfig.ProtoReflect.Descriptor instead.
 func (*RealityConfig) Descriptor() ([]byte, []int) {
-	return file_agent_v1_status_proto_rawDescGZIP(), []int{9}
+	return file_agent_v1_status_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *RealityConfig) GetEnabled() bool {
@@ -928,7 +1287,7 @@ type MultiplexConfig struct {
 
 func (x *MultiplexConfig) Reset() {
 	*x = MultiplexConfig{}
-	mi := &file_agent_v1_status_proto_msgTypes[10]
+	mi := &file_agent_v1_status_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -940,7 +1299,7 @@ func (x *MultiplexConfig) String() string {
 func (*MultiplexConfig) ProtoMessage() {}
 
 func (x *MultiplexConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_v1_status_proto_msgTypes[10]
+	mi := &file_agent_v1_status_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -953,7 +1312,7 @@ func (x *MultiplexConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MultiplexConfig.ProtoReflect.Descriptor instead.
 func (*MultiplexConfig) Descriptor() ([]byte, []int) {
-	return file_agent_v1_status_proto_rawDescGZIP(), []int{10}
+	return file_agent_v1_status_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *MultiplexConfig) GetEnabled() bool {
@@ -989,7 +1348,7 @@ type BrutalConfig struct {
 
 func (x *BrutalConfig) Reset() {
 	*x = BrutalConfig{}
-	mi := &file_agent_v1_status_proto_msgTypes[11]
+	mi := &file_agent_v1_status_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1001,7 +1360,7 @@ func (x *BrutalConfig) String() string {
 func (*BrutalConfig) ProtoMessage() {}
 
 func (x *BrutalConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_v1_status_proto_msgTypes[11]
+	mi := &file_agent_v1_status_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1014,7 +1373,7 @@ func (x *BrutalConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BrutalConfig.ProtoReflect.Descriptor instead.
 func (*BrutalConfig) Descriptor() ([]byte, []int) {
-	return file_agent_v1_status_proto_rawDescGZIP(), []int{11}
+	return file_agent_v1_status_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *BrutalConfig) GetEnabled() bool {
@@ -1051,7 +1410,7 @@ type ProtocolUserInfo struct {
 
 func (x *ProtocolUserInfo) Reset() {
 	*x = ProtocolUserInfo{}
-	mi := &file_agent_v1_status_proto_msgTypes[12]
+	mi := &file_agent_v1_status_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1063,7 +1422,7 @@ func (x *ProtocolUserInfo) String() string {
 func (*ProtocolUserInfo) ProtoMessage() {}
 
 func (x *ProtocolUserInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_v1_status_proto_msgTypes[12]
+	mi := &file_agent_v1_status_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1076,7 +1435,7 @@ func (x *ProtocolUserInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ProtocolUserInfo.ProtoReflect.Descriptor instead.
 func (*ProtocolUserInfo) Descriptor() ([]byte, []int) {
-	return file_agent_v1_status_proto_rawDescGZIP(), []int{12}
+	return file_agent_v1_status_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *ProtocolUserInfo) GetUuid() string {
@@ -1132,13 +1491,19 @@ type SystemMetrics struct {
 	BootId          string   `protobuf:"bytes,23,opt,name=boot_id,json=bootId,proto3" json:"boot_id,omitempty"`
 	AgentVersion    string   `protobuf:"bytes,24,opt,name=agent_version,json=agentVersion,proto3" json:"agent_version,omitempty"`
 	CurrentCoreType string   `protobuf:"bytes,25,opt,name=current_core_type,json=currentCoreType,proto3" json:"current_core_type,omitempty"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+	// Disk usage of watched directories (config/log dirs)
+	PathUsage []*PathUsage `protobuf:"bytes,26,rep,name=path_usage,json=pathUsage,proto3" json:"path_usage,omitempty"`
+	// Where connection_count came from: "clash_api" or "proc_net"; empty means unavailable
+	ConnectionCountSource string `protobuf:"bytes,27,opt,name=connection_count_source,json=connectionCountSource,proto3" json:"connection_count_source,omitempty"`
+	// TCP connect latency to the agent's probe target in milliseconds; 0 means not measured
+	ProbeLatencyMs int32 `protobuf:"varint,28,opt,name=probe_latency_ms,json=probeLatencyMs,proto3" json:"probe_latency_ms,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *SystemMetrics) Reset() {
 	*x = SystemMetrics{}
-	mi := &file_agent_v1_status_proto_msgTypes[13]
+	mi := &file_agent_v1_status_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1150,7 +1515,7 @@ func (x *SystemMetrics) String() string {
 func (*SystemMetrics) ProtoMessage() {}
 
 func (x *SystemMetrics) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_v1_status_proto_msgTypes[13]
+	mi := &file_agent_v1_status_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1163,7 +1528,7 @@ func (x *SystemMetrics) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SystemMetrics.ProtoReflect.Descriptor instead.
 func (*SystemMetrics) Descriptor() ([]byte, []int) {
-	return file_agent_v1_status_proto_rawDescGZIP(), []int{13}
+	return file_agent_v1_status_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *SystemMetrics) GetCpuUsage() float64 {
@@ -1313,6 +1678,121 @@ func (x *SystemMetrics) GetCurrentCoreType() string {
 	return ""
 }
 
+func (x *SystemMetrics) GetPathUsage() []*PathUsage {
+	if x != nil {
+		return x.PathUsage
+	}
+	return nil
+}
+
+func (x *SystemMetrics) GetConnectionCountSource() string {
+	if x != nil {
+		return x.ConnectionCountSource
+	}
+	return ""
+}
+
+func (x *SystemMetrics) GetProbeLatencyMs() int32 {
+	if x != nil {
+		return x.ProbeLatencyMs
+	}
+	return 0
+}
+
+// PathUsage reports disk usage of a watched directory.
+// A missing path is reported with exists=false and zero sizes.
+type PathUsage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Path          string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Exists        bool                   `protobuf:"varint,2,opt,name=exists,proto3" json:"exists,omitempty"`
+	UsedBytes     uint64                 `protobuf:"varint,3,opt,name=used_bytes,json=usedBytes,proto3" json:"used_bytes,omitempty"`            // Total size of regular files under the path
+	Truncated     bool                   `protobuf:"varint,4,opt,name=truncated,proto3" json:"truncated,omitempty"`                             // used_bytes is partial because the walk hit the entry limit
+	FsTotalBytes  uint64                 `protobuf:"varint,5,opt,name=fs_total_bytes,json=fsTotalBytes,proto3" json:"fs_total_bytes,omitempty"` // Filesystem containing the path
+	FsUsedBytes   uint64                 `protobuf:"varint,6,opt,name=fs_used_bytes,json=fsUsedBytes,proto3" json:"fs_used_bytes,omitempty"`
+	FsFreeBytes   uint64                 `protobuf:"varint,7,opt,name=fs_free_bytes,json=fsFreeBytes,proto3" json:"fs_free_bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PathUsage) Reset() {
+	*x = PathUsage{}
+	mi := &file_agent_v1_status_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PathUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PathUsage) ProtoMessage() {}
+
+func (x *PathUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_v1_status_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PathUsage.ProtoReflect.Descriptor instead.
+func (*PathUsage) Descriptor() ([]byte, []int) {
+	return file_agent_v1_status_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *PathUsage) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *PathUsage) GetExists() bool {
+	if x != nil {
+		return x.Exists
+	}
+	return false
+}
+
+func (x *PathUsage) GetUsedBytes() uint64 {
+	if x != nil {
+		return x.UsedBytes
+	}
+	return 0
+}
+
+func (x *PathUsage) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
+func (x *PathUsage) GetFsTotalBytes() uint64 {
+	if x != nil {
+		return x.FsTotalBytes
+	}
+	return 0
+}
+
+func (x *PathUsage) GetFsUsedBytes() uint64 {
+	if x != nil {
+		return x.FsUsedBytes
+	}
+	return 0
+}
+
+func (x *PathUsage) GetFsFreeBytes() uint64 {
+	if x != nil {
+		return x.FsFreeBytes
+	}
+	return 0
+}
+
 type MetricInt64Value struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Value         int64                  `protobuf:"varint,1,opt,name=value,proto3" json:"value,omitempty"`
@@ -1322,7 +1802,7 @@ type MetricInt64Value struct {
 
 func (x *MetricInt64Value) Reset() {
 	*x = MetricInt64Value{}
-	mi := &file_agent_v1_status_proto_msgTypes[14]
+	mi := &file_agent_v1_status_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1334,7 +1814,7 @@ func (x *MetricInt64Value) String() string {
 func (*MetricInt64Value) ProtoMessage() {}
 
 func (x *MetricInt64Value) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_v1_status_proto_msgTypes[14]
+	mi := &file_agent_v1_status_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1347,7 +1827,7 @@ func (x *MetricInt64Value) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MetricInt64Value.ProtoReflect.Descriptor instead.
 func (*MetricInt64Value) Descriptor() ([]byte, []int) {
-	return file_agent_v1_status_proto_rawDescGZIP(), []int{14}
+	return file_agent_v1_status_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *MetricInt64Value) GetValue() int64 {
@@ -1366,7 +1846,7 @@ type MetricUInt64Value struct {
 
 func (x *MetricUInt64Value) Reset() {
 	*x = MetricUInt64Value{}
-	mi := &file_agent_v1_status_proto_msgTypes[15]
+	mi := &file_agent_v1_status_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1378,7 +1858,7 @@ func (x *MetricUInt64Value) String() string {
 func (*MetricUInt64Value) ProtoMessage() {}
 
 func (x *MetricUInt64Value) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_v1_status_proto_msgTypes[15]
+	mi := &file_agent_v1_status_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1391,7 +1871,7 @@ func (x *MetricUInt64Value) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MetricUInt64Value.ProtoReflect.Descriptor instead.
 func (*MetricUInt64Value) Descriptor() ([]byte, []int) {
-	return file_agent_v1_status_proto_rawDescGZIP(), []int{15}
+	return file_agent_v1_status_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *MetricUInt64Value) GetValue() uint64 {
@@ -1419,7 +1899,7 @@ type NetworkMetrics struct {
 
 func (x *NetworkMetrics) Reset() {
 	*x = NetworkMetrics{}
-	mi := &file_agent_v1_status_proto_msgTypes[16]
+	mi := &file_agent_v1_status_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1431,7 +1911,7 @@ func (x *NetworkMetrics) String() string {
 func (*NetworkMetrics) ProtoMessage() {}
 
 func (x *NetworkMetrics) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_v1_status_proto_msgTypes[16]
+	mi := &file_agent_v1_status_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1444,7 +1924,7 @@ func (x *NetworkMetrics) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NetworkMetrics.ProtoReflect.Descriptor instead.
 func (*NetworkMetrics) Descriptor() ([]byte, []int) {
-	return file_agent_v1_status_proto_rawDescGZIP(), []int{16}
+	return file_agent_v1_status_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *NetworkMetrics) GetUploadBytes() uint64 {
@@ -1523,7 +2003,7 @@ type StatusResponse struct {
 
 func (x *StatusResponse) Reset() {
 	*x = StatusResponse{}
-	mi := &file_agent_v1_status_proto_msgTypes[17]
+	mi := &file_agent_v1_status_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1535,7 +2015,7 @@ func (x *StatusResponse) String() string {
 func (*StatusResponse) ProtoMessage() {}
 
 func (x *StatusResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_v1_status_proto_msgTypes[17]
+	mi := &file_agent_v1_status_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1548,7 +2028,7 @@ func (x *StatusResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StatusResponse.ProtoReflect.Descriptor instead.
 func (*StatusResponse) Descriptor() ([]byte, []int) {
-	return file_agent_v1_status_proto_rawDescGZIP(), []int{17}
+	return file_agent_v1_status_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *StatusResponse) GetSuccess() bool {
@@ -1590,7 +2070,7 @@ type StatusCommand struct {
 
 func (x *StatusCommand) Reset() {
 	*x = StatusCommand{}
-	mi := &file_agent_v1_status_proto_msgTypes[18]
+	mi := &file_agent_v1_status_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1602,7 +2082,7 @@ func (x *StatusCommand) String() string {
 func (*StatusCommand) ProtoMessage() {}
 
 func (x *StatusCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_v1_status_proto_msgTypes[18]
+	mi := &file_agent_v1_status_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1615,7 +2095,7 @@ func (x *StatusCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StatusCommand.ProtoReflect.Descriptor instead.
 func (*StatusCommand) Descriptor() ([]byte, []int) {
-	return file_agent_v1_status_proto_rawDescGZIP(), []int{18}
+	return file_agent_v1_status_proto_rawDescGZIP(), []int{22}
 }
 
 func (x *StatusCommand) GetCommand() string {
@@ -1648,7 +2128,7 @@ type ConfigInventoryEntry struct {
 
 func (x *ConfigInventoryEntry) Reset() {
 	*x = ConfigInventoryEntry{}
-	mi := &file_agent_v1_status_proto_msgTypes[19]
+	mi := &file_agent_v1_status_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1660,7 +2140,7 @@ func (x *ConfigInventoryEntry) String() string {
 func (*ConfigInventoryEntry) ProtoMessage() {}
 
 func (x *ConfigInventoryEntry) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_v1_status_proto_msgTypes[19]
+	mi := &file_agent_v1_status_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1673,7 +2153,7 @@ func (x *ConfigInventoryEntry) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ConfigInventoryEntry.ProtoReflect.Descriptor instead.
 func (*ConfigInventoryEntry) Descriptor() ([]byte, []int) {
-	return file_agent_v1_status_proto_rawDescGZIP(), []int{19}
+	return file_agent_v1_status_proto_rawDescGZIP(), []int{23}
 }
 
 func (x *ConfigInventoryEntry) GetSource() string {
@@ -1745,7 +2225,7 @@ type InboundIndexEntry struct {
 
 func (x *InboundIndexEntry) Reset() {
 	*x = InboundIndexEntry{}
-	mi := &file_agent_v1_status_proto_msgTypes[20]
+	mi := &file_agent_v1_status_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1757,7 +2237,7 @@ func (x *InboundIndexEntry) String() string {
 func (*InboundIndexEntry) ProtoMessage() {}
 
 func (x *InboundIndexEntry) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_v1_status_proto_msgTypes[20]
+	mi := &file_agent_v1_status_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1770,7 +2250,7 @@ func (x *InboundIndexEntry) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use InboundIndexEntry.ProtoReflect.Descriptor instead.
 func (*InboundIndexEntry) Descriptor() ([]byte, []int) {
-	return file_agent_v1_status_proto_rawDescGZIP(), []int{20}
+	return file_agent_v1_status_proto_rawDescGZIP(), []int{24}
 }
 
 func (x *InboundIndexEntry) GetSource() string {
@@ -1861,7 +2341,7 @@ type ClientConfigReport struct {
 
 func (x *ClientConfigReport) Reset() {
 	*x = ClientConfigReport{}
-	mi := &file_agent_v1_status_proto_msgTypes[21]
+	mi := &file_agent_v1_status_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1873,7 +2353,7 @@ func (x *ClientConfigReport) String() string {
 func (*ClientConfigReport) ProtoMessage() {}
 
 func (x *ClientConfigReport) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_v1_status_proto_msgTypes[21]
+	mi := &file_agent_v1_status_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1886,7 +2366,7 @@ func (x *ClientConfigReport) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ClientConfigReport.ProtoReflect.Descriptor instead.
 func (*ClientConfigReport) Descriptor() ([]byte, []int) {
-	return file_agent_v1_status_proto_rawDescGZIP(), []int{21}
+	return file_agent_v1_status_proto_rawDescGZIP(), []int{25}
 }
 
 func (x *ClientConfigReport) GetConfigs() []*ClientConfig {
@@ -1952,7 +2432,7 @@ type ClientConfig struct {
 
 func (x *ClientConfig) Reset() {
 	*x = ClientConfig{}
-	mi := &file_agent_v1_status_proto_msgTypes[22]
+	mi := &file_agent_v1_status_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1964,7 +2444,7 @@ func (x *ClientConfig) String() string {
 func (*ClientConfig) ProtoMessage() {}
 
 func (x *ClientConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_v1_status_proto_msgTypes[22]
+	mi := &file_agent_v1_status_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1977,7 +2457,7 @@ func (x *ClientConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ClientConfig.ProtoReflect.Descriptor instead.
 func (*ClientConfig) Descriptor() ([]byte, []int) {
-	return file_agent_v1_status_proto_rawDescGZIP(), []int{22}
+	return file_agent_v1_status_proto_rawDescGZIP(), []int{26}
 }
 
 func (x *ClientConfig) GetName() string {
@@ -2187,13 +2667,13 @@ var File_agent_v1_status_proto protoreflect.FileDescriptor
 
 const file_agent_v1_status_proto_rawDesc = "" +
 	"\n" +
-	"\x15agent/v1/status.proto\x12\bagent.v1\x1a\x13agent/v1/core.proto\"0\n" +
+	"\x15agent/v1/status.proto\x12\bagent.v1\x1a\x13agent/v1/core.proto\x1a\x16agent/v1/traffic.proto\"0\n" +
 	"\x10HeartbeatRequest\x12\x1c\n" +
 	"\ttimestamp\x18\x01 \x01(\x03R\ttimestamp\"N\n" +
 	"\x11HeartbeatResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x1f\n" +
 	"\vserver_time\x18\x02 \x01(\x03R\n" +
-	"serverTime\"\xed\x04\n" +
+	"serverTime\"\xf4\x06\n" +
 	"\fStatusReport\x12\x1c\n" +
 	"\ttimestamp\x18\x01 \x01(\x03R\ttimestamp\x12/\n" +
 	"\x06system\x18\x02 \x01(\v2\x17.agent.v1.SystemMetricsR\x06system\x122\n" +
@@ -2207,7 +2687,44 @@ const file_agent_v1_status_proto_rawDesc = "" +
 	"reportedAt\x12E\n" +
 	"\rcommand_queue\x18\n" +
 	" \x01(\v2 .agent.v1.AgentCommandQueueStatsR\fcommandQueue\x12@\n" +
-	"\rupdate_status\x18\v \x01(\v2\x1b.agent.v1.AgentUpdateStatusR\fupdateStatus\"\xed\x01\n" +
+	"\rupdate_status\x18\v \x01(\v2\x1b.agent.v1.AgentUpdateStatusR\fupdateStatus\x12:\n" +
+	"\ralive_devices\x18\f \x03(\v2\x15.agent.v1.AliveDeviceR\faliveDevices\x12>\n" +
+	"\freload_drain\x18\r \x01(\v2\x1b.agent.v1.ReloadDrainResultR\vreloadDrain\x12K\n" +
+	"\x13core_version_change\x18\x0e \x01(\v2\x1b.agent.v1.CoreVersionChangeR\x11coreVersionChange\x12<\n" +
+	"\vdiagnostics\x18\x0f \x01(\v2\x1a.agent.v1.AgentDiagnosticsR\vdiagnostics\"\xaa\x04\n" +
+	"\x10AgentDiagnostics\x12\x1b\n" +
+	"\tcore_type\x18\x01 \x01(\tR\bcoreType\x12!\n" +
+	"\fcore_version\x18\x02 \x01(\tR\vcoreVersion\x12#\n" +
+	"\ragent_version\x18\x03 \x01(\tR\fagentVersion\x12\x1f\n" +
+	"\vinit_system\x18\x04 \x01(\tR\n" +
+	"initSystem\x12!\n" +
+	"\fconfig_valid\x18\x05 \x01(\bR\vconfigValid\x126\n" +
+	"\x17config_validation_error\x18\x06 \x01(\tR\x15configValidationError\x12(\n" +
+	"\x10last_apply_error\x18\a \x01(\tR\x0elastApplyError\x12-\n" +
+	"\x13last_apply_error_at\x18\b \x01(\x03R\x10lastApplyErrorAt\x12\x1d\n" +
+	"\n" +
+	"config_dir\x18\t \x01(\tR\tconfigDir\x121\n" +
+	"\x15config_dir_free_bytes\x18\n" +
+	" \x01(\x04R\x12configDirFreeBytes\x123\n" +
+	"\x16config_dir_total_bytes\x18\v \x01(\x04R\x13configDirTotalBytes\x12\x19\n" +
+	"\bopen_fds\x18\f \x01(\x03R\aopenFds\x12\x17\n" +
+	"\amax_fds\x18\r \x01(\x03R\x06maxFds\x12!\n" +
+	"\fcollected_at\x18\x0e \x01(\x03R\vcollectedAt\"\xdc\x01\n" +
+	"\x11CoreVersionChange\x12,\n" +
+	"\x12previous_core_type\x18\x01 \x01(\tR\x10previousCoreType\x12)\n" +
+	"\x10previous_version\x18\x02 \x01(\tR\x0fpreviousVersion\x12\x1b\n" +
+	"\tcore_type\x18\x03 \x01(\tR\bcoreType\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\tR\aversion\x12\x16\n" +
+	"\x06reason\x18\x05 \x01(\tR\x06reason\x12\x1f\n" +
+	"\vdetected_at\x18\x06 \x01(\x03R\n" +
+	"detectedAt\"\xaf\x01\n" +
+	"\x11ReloadDrainResult\x12\x12\n" +
+	"\x04mode\x18\x01 \x01(\tR\x04mode\x12-\n" +
+	"\x12connections_before\x18\x02 \x01(\x05R\x11connectionsBefore\x12\x18\n" +
+	"\adrained\x18\x03 \x01(\x05R\adrained\x12\x1c\n" +
+	"\tremaining\x18\x04 \x01(\x05R\tremaining\x12\x1f\n" +
+	"\vfinished_at\x18\x05 \x01(\x03R\n" +
+	"finishedAt\"\xed\x01\n" +
 	"\x16AgentCommandQueueStats\x12\x1a\n" +
 	"\bcapacity\x18\x01 \x01(\x05R\bcapacity\x12\x16\n" +
 	"\x06queued\x18\x02 \x01(\x05R\x06queued\x12\x1a\n" +
@@ -2243,7 +2760,7 @@ const file_agent_v1_status_proto_rawDesc = "" +
 	"\x04type\x18\x02 \x01(\tR\x04type\x12\x18\n" +
 	"\arunning\x18\x03 \x01(\bR\arunning\x12!\n" +
 	"\fcontent_hash\x18\x04 \x01(\tR\vcontentHash\x123\n" +
-	"\adetails\x18\x05 \x03(\v2\x19.agent.v1.ProtocolDetailsR\adetails\"\xf4\x02\n" +
+	"\adetails\x18\x05 \x03(\v2\x19.agent.v1.ProtocolDetailsR\adetails\"\x94\x03\n" +
 	"\x0fProtocolDetails\x12\x1a\n" +
 	"\bprotocol\x18\x01 \x01(\tR\bprotocol\x12\x10\n" +
 	"\x03tag\x18\x02 \x01(\tR\x03tag\x12\x16\n" +
@@ -2256,18 +2773,23 @@ const file_agent_v1_status_proto_rawDesc = "" +
 	"sourceFile\x12\x1b\n" +
 	"\tcore_type\x18\t \x01(\tR\bcoreType\x127\n" +
 	"\tmultiplex\x18\n" +
-	" \x01(\v2\x19.agent.v1.MultiplexConfigR\tmultiplex\"p\n" +
+	" \x01(\v2\x19.agent.v1.MultiplexConfigR\tmultiplex\x12\x1e\n" +
+	"\n" +
+	"encryption\x18\v \x01(\tR\n" +
+	"encryption\"p\n" +
 	"\x0fTransportConfig\x12\x12\n" +
 	"\x04type\x18\x01 \x01(\tR\x04type\x12\x12\n" +
 	"\x04path\x18\x02 \x01(\tR\x04path\x12\x12\n" +
 	"\x04host\x18\x03 \x01(\tR\x04host\x12!\n" +
-	"\fservice_name\x18\x04 \x01(\tR\vserviceName\"\x8d\x01\n" +
+	"\fservice_name\x18\x04 \x01(\tR\vserviceName\"\xae\x01\n" +
 	"\tTLSConfig\x12\x18\n" +
 	"\aenabled\x18\x01 \x01(\bR\aenabled\x12\x1f\n" +
 	"\vserver_name\x18\x02 \x01(\tR\n" +
 	"serverName\x12\x12\n" +
 	"\x04alpn\x18\x03 \x03(\tR\x04alpn\x121\n" +
-	"\areality\x18\x04 \x01(\v2\x17.agent.v1.RealityConfigR\areality\"\xf6\x01\n" +
+	"\areality\x18\x04 \x01(\v2\x17.agent.v1.RealityConfigR\areality\x12\x1f\n" +
+	"\vech_enabled\x18\x05 \x01(\bR\n" +
+	"echEnabled\"\xf6\x01\n" +
 	"\rRealityConfig\x12\x18\n" +
 	"\aenabled\x18\x01 \x01(\bR\aenabled\x12\x1b\n" +
 	"\tshort_ids\x18\x02 \x03(\tR\bshortIds\x12\x1f\n" +
@@ -2290,7 +2812,7 @@ const file_agent_v1_status_proto_rawDesc = "" +
 	"\x04uuid\x18\x01 \x01(\tR\x04uuid\x12\x12\n" +
 	"\x04flow\x18\x02 \x01(\tR\x04flow\x12\x14\n" +
 	"\x05email\x18\x03 \x01(\tR\x05email\x12\x16\n" +
-	"\x06method\x18\x04 \x01(\tR\x06method\"\xb3\x05\n" +
+	"\x06method\x18\x04 \x01(\tR\x06method\"\xc9\x06\n" +
 	"\rSystemMetrics\x12\x1b\n" +
 	"\tcpu_usage\x18\x01 \x01(\x01R\bcpuUsage\x12!\n" +
 	"\fmemory_usage\x18\x02 \x01(\x01R\vmemoryUsage\x12!\n" +
@@ -2317,7 +2839,20 @@ const file_agent_v1_status_proto_rawDesc = "" +
 	"build_tags\x18\x16 \x03(\tR\tbuildTags\x12\x17\n" +
 	"\aboot_id\x18\x17 \x01(\tR\x06bootId\x12#\n" +
 	"\ragent_version\x18\x18 \x01(\tR\fagentVersion\x12*\n" +
-	"\x11current_core_type\x18\x19 \x01(\tR\x0fcurrentCoreType\"(\n" +
+	"\x11current_core_type\x18\x19 \x01(\tR\x0fcurrentCoreType\x122\n" +
+	"\n" +
+	"path_usage\x18\x1a \x03(\v2\x13.agent.v1.PathUsageR\tpathUsage\x126\n" +
+	"\x17connection_count_source\x18\x1b \x01(\tR\x15connectionCountSource\x12(\n" +
+	"\x10probe_latency_ms\x18\x1c \x01(\x05R\x0eprobeLatencyMs\"\xe2\x01\n" +
+	"\tPathUsage\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x16\n" +
+	"\x06exists\x18\x02 \x01(\bR\x06exists\x12\x1d\n" +
+	"\n" +
+	"used_bytes\x18\x03 \x01(\x04R\tusedBytes\x12\x1c\n" +
+	"\ttruncated\x18\x04 \x01(\bR\ttruncated\x12$\n" +
+	"\x0efs_total_bytes\x18\x05 \x01(\x04R\ffsTotalBytes\x12\"\n" +
+	"\rfs_used_bytes\x18\x06 \x01(\x04R\vfsUsedBytes\x12\"\n" +
+	"\rfs_free_bytes\x18\a \x01(\x04R\vfsFreeBytes\"(\n" +
 	"\x10MetricInt64Value\x12\x14\n" +
 	"\x05value\x18\x01 \x01(\x03R\x05value\")\n" +
 	"\x11MetricUInt64Value\x12\x14\n" +
@@ -2417,62 +2952,72 @@ func file_agent_v1_status_proto_rawDescGZIP() []byte {
 	return file_agent_v1_status_proto_rawDescData
 }
 
-var file_agent_v1_status_proto_msgTypes = make([]protoimpl.MessageInfo, 24)
+var file_agent_v1_status_proto_msgTypes = make([]protoimpl.MessageInfo, 28)
 var file_agent_v1_status_proto_goTypes = []any{
 	(*HeartbeatRequest)(nil),       // 0: agent.v1.HeartbeatRequest
 	(*HeartbeatResponse)(nil),      // 1: agent.v1.HeartbeatResponse
 	(*StatusReport)(nil),           // 2: agent.v1.StatusReport
-	(*AgentCommandQueueStats)(nil), // 3: agent.v1.AgentCommandQueueStats
-	(*AgentUpdateStatus)(nil),      // 4: agent.v1.AgentUpdateStatus
-	(*ProtocolState)(nil),          // 5: agent.v1.ProtocolState
-	(*ProtocolDetails)(nil),        // 6: agent.v1.ProtocolDetails
-	(*TransportConfig)(nil),        // 7: agent.v1.TransportConfig
-	(*TLSConfig)(nil),              // 8: agent.v1.TLSConfig
-	(*RealityConfig)(nil),          // 9: agent.v1.RealityConfig
-	(*MultiplexConfig)(nil),        // 10: agent.v1.MultiplexConfig
-	(*BrutalConfig)(nil),           // 11: agent.v1.BrutalConfig
-	(*ProtocolUserInfo)(nil),       // 12: agent.v1.ProtocolUserInfo
-	(*SystemMetrics)(nil),          // 13: agent.v1.SystemMetrics
-	(*MetricInt64Value)(nil),       // 14: agent.v1.MetricInt64Value
-	(*MetricUInt64Value)(nil),      // 15: agent.v1.MetricUInt64Value
-	(*NetworkMetrics)(nil),         // 16: agent.v1.NetworkMetrics
-	(*StatusResponse)(nil),         // 17: agent.v1.StatusResponse
-	(*StatusCommand)(nil),          // 18: agent.v1.StatusCommand
-	(*ConfigInventoryEntry)(nil),   // 19: agent.v1.ConfigInventoryEntry
-	(*InboundIndexEntry)(nil),      // 20: agent.v1.InboundIndexEntry
-	(*ClientConfigReport)(nil),     // 21: agent.v1.ClientConfigReport
-	(*ClientConfig)(nil),           // 22: agent.v1.ClientConfig
-	nil,                            // 23: agent.v1.ClientConfig.RawConfigsEntry
-	(*CoreInstance)(nil),           // 24: agent.v1.CoreInstance
+	(*AgentDiagnostics)(nil),       // 3: agent.v1.AgentDiagnostics
+	(*CoreVersionChange)(nil),      // 4: agent.v1.CoreVersionChange
+	(*ReloadDrainResult)(nil),      // 5: agent.v1.ReloadDrainResult
+	(*AgentCommandQueueStats)(nil), // 6: agent.v1.AgentCommandQueueStats
+	(*AgentUpdateStatus)(nil),      // 7: agent.v1.AgentUpdateStatus
+	(*ProtocolState)(nil),          // 8: agent.v1.ProtocolState
+	(*ProtocolDetails)(nil),        // 9: agent.v1.ProtocolDetails
+	(*TransportConfig)(nil),        // 10: agent.v1.TransportConfig
+	(*TLSConfig)(nil),              // 11: agent.v1.TLSConfig
+	(*RealityConfig)(nil),          // 12: agent.v1.RealityConfig
+	(*MultiplexConfig)(nil),        // 13: agent.v1.MultiplexConfig
+	(*BrutalConfig)(nil),           // 14: agent.v1.BrutalConfig
+	(*ProtocolUserInfo)(nil),       // 15: agent.v1.ProtocolUserInfo
+	(*SystemMetrics)(nil),          // 16: agent.v1.SystemMetrics
+	(*PathUsage)(nil),              // 17: agent.v1.PathUsage
+	(*MetricInt64Value)(nil),       // 18: agent.v1.MetricInt64Value
+	(*MetricUInt64Value)(nil),      // 19: agent.v1.MetricUInt64Value
+	(*NetworkMetrics)(nil),         // 20: agent.v1.NetworkMetrics
+	(*StatusResponse)(nil),         // 21: agent.v1.StatusResponse
+	(*StatusCommand)(nil),          // 22: agent.v1.StatusCommand
+	(*ConfigInventoryEntry)(nil),   // 23: agent.v1.ConfigInventoryEntry
+	(*InboundIndexEntry)(nil),      // 24: agent.v1.InboundIndexEntry
+	(*ClientConfigReport)(nil),     // 25: agent.v1.ClientConfigReport
+	(*ClientConfig)(nil),           // 26: agent.v1.ClientConfig
+	nil,                            // 27: agent.v1.ClientConfig.RawConfigsEntry
+	(*CoreInstance)(nil),           // 28: agent.v1.CoreInstance
+	(*AliveDevice)(nil),            // 29: agent.v1.AliveDevice
 }
 var file_agent_v1_status_proto_depIdxs = []int32{
-	13, // 0: agent.v1.StatusReport.system:type_name -> agent.v1.SystemMetrics
-	16, // 1: agent.v1.StatusReport.network:type_name -> agent.v1.NetworkMetrics
-	5,  // 2: agent.v1.StatusReport.protocols:type_name -> agent.v1.ProtocolState
-	21, // 3: agent.v1.StatusReport.client_configs:type_name -> agent.v1.ClientConfigReport
-	24, // 4: agent.v1.StatusReport.instances:type_name -> agent.v1.CoreInstance
-	19, // 5: agent.v1.StatusReport.inventory:type_name -> agent.v1.ConfigInventoryEntry
-	20, // 6: agent.v1.StatusReport.inbound_index:type_name -> agent.v1.InboundIndexEntry
-	3,  // 7: agent.v1.StatusReport.command_queue:type_name -> agent.v1.AgentCommandQueueStats
-	4,  // 8: agent.v1.StatusReport.update_status:type_name -> agent.v1.AgentUpdateStatus
-	6,  // 9: agent.v1.ProtocolState.details:type_name -> agent.v1.ProtocolDetails
-	7,  // 10: agent.v1.ProtocolDetails.transport:type_name -> agent.v1.TransportConfig
-	8,  // 11: agent.v1.ProtocolDetails.tls:type_name -> agent.v1.TLSConfig
-	12, // 12: agent.v1.ProtocolDetails.users:type_name -> agent.v1.ProtocolUserInfo
-	10, // 13: agent.v1.ProtocolDetails.multiplex:type_name -> agent.v1.MultiplexConfig
-	9,  // 14: agent.v1.TLSConfig.reality:type_name -> agent.v1.RealityConfig
-	11, // 15: agent.v1.MultiplexConfig.brutal:type_name -> agent.v1.BrutalConfig
-	14, // 16: agent.v1.NetworkMetrics.upload_rate_bps:type_name -> agent.v1.MetricInt64Value
-	14, // 17: agent.v1.NetworkMetrics.download_rate_bps:type_name -> agent.v1.MetricInt64Value
-	15, // 18: agent.v1.NetworkMetrics.raw_upload_total_bytes:type_name -> agent.v1.MetricUInt64Value
-	15, // 19: agent.v1.NetworkMetrics.raw_download_total_bytes:type_name -> agent.v1.MetricUInt64Value
-	22, // 20: agent.v1.ClientConfigReport.configs:type_name -> agent.v1.ClientConfig
-	23, // 21: agent.v1.ClientConfig.raw_configs:type_name -> agent.v1.ClientConfig.RawConfigsEntry
-	22, // [22:22] is the sub-list for method output_type
-	22, // [22:22] is the sub-list for method input_type
-	22, // [22:22] is the sub-list for extension type_name
-	22, // [22:22] is the sub-list for extension extendee
-	0,  // [0:22] is the sub-list for field type_name
+	16, // 0: agent.v1.StatusReport.system:type_name -> agent.v1.SystemMetrics
+	20, // 1: agent.v1.StatusReport.network:type_name -> agent.v1.NetworkMetrics
+	8,  // 2: agent.v1.StatusReport.protocols:type_name -> agent.v1.ProtocolState
+	25, // 3: agent.v1.StatusReport.client_configs:type_name -> agent.v1.ClientConfigReport
+	28, // 4: agent.v1.StatusReport.instances:type_name -> agent.v1.CoreInstance
+	23, // 5: agent.v1.StatusReport.inventory:type_name -> agent.v1.ConfigInventoryEntry
+	24, // 6: agent.v1.StatusReport.inbound_index:type_name -> agent.v1.InboundIndexEntry
+	6,  // 7: agent.v1.StatusReport.command_queue:type_name -> agent.v1.AgentCommandQueueStats
+	7,  // 8: agent.v1.StatusReport.update_status:type_name -> agent.v1.AgentUpdateStatus
+	29, // 9: agent.v1.StatusReport.alive_devices:type_name -> agent.v1.AliveDevice
+	5,  // 10: agent.v1.StatusReport.reload_drain:type_name -> agent.v1.ReloadDrainResult
+	4,  // 11: agent.v1.StatusReport.core_version_change:type_name -> agent.v1.CoreVersionChange
+	3,  // 12: agent.v1.StatusReport.diagnostics:type_name -> agent.v1.AgentDiagnostics
+	9,  // 13: agent.v1.ProtocolState.details:type_name -> agent.v1.ProtocolDetails
+	10, // 14: agent.v1.ProtocolDetails.transport:type_name -> agent.v1.TransportConfig
+	11, // 15: agent.v1.ProtocolDetails.tls:type_name -> agent.v1.TLSConfig
+	15, // 16: agent.v1.ProtocolDetails.users:type_name -> agent.v1.ProtocolUserInfo
+	13, // 17: agent.v1.ProtocolDetails.multiplex:type_name -> agent.v1.MultiplexConfig
+	12, // 18: agent.v1.TLSConfig.reality:type_name -> agent.v1.RealityConfig
+	14, // 19: agent.v1.MultiplexConfig.brutal:type_name -> agent.v1.BrutalConfig
+	17, // 20: agent.v1.SystemMetrics.path_usage:type_name -> agent.v1.PathUsage
+	18, // 21: agent.v1.NetworkMetrics.upload_rate_bps:type_name -> agent.v1.MetricInt64Value
+	18, // 22: agent.v1.NetworkMetrics.download_rate_bps:type_name -> agent.v1.MetricInt64Value
+	19, // 23: agent.v1.NetworkMetrics.raw_upload_total_bytes:type_name -> agent.v1.MetricUInt64Value
+	19, // 24: agent.v1.NetworkMetrics.raw_download_total_bytes:type_name -> agent.v1.MetricUInt64Value
+	26, // 25: agent.v1.ClientConfigReport.configs:type_name -> agent.v1.ClientConfig
+	27, // 26: agent.v1.ClientConfig.raw_configs:type_name -> agent.v1.ClientConfig.RawConfigsEntry
+	27, // [27:27] is the sub-list for method output_type
+	27, // [27:27] is the sub-list for method input_type
+	27, // [27:27] is the sub-list for extension type_name
+	27, // [27:27] is the sub-list for extension extendee
+	0,  // [0:27] is the sub-list for field type_name
 }
 
 func init() { file_agent_v1_status_proto_init() }
@@ -2481,13 +3026,14 @@ func file_agent_v1_status_proto_init() {
 		return
 	}
 	file_agent_v1_core_proto_init()
+	file_agent_v1_traffic_proto_init()
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_agent_v1_status_proto_rawDesc), len(file_agent_v1_status_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   24,
+			NumMessages:   28,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -26,7 +26,8 @@ type TrafficReport struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Timestamp     int64                  `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
 	UserTraffic   []*UserTraffic         `protobuf:"bytes,2,rep,name=user_traffic,json=userTraffic,proto3" json:"user_traffic,omitempty"`
-	ReportId      string                 `protobuf:"bytes,3,opt,name=report_id,json=reportId,proto3" json:"report_id,omitempty"`
+	ReportId      string                 `protobuf:"bytes,3,opt,name=report_id,json=reportId,proto3" json:"report_id,omitempty"` // Legacy idempotency key, sent with the same value as batch_id for older panels
+	BatchId       string                 `protobuf:"bytes,4,opt,name=batch_id,json=batchId,proto3" json:"batch_id,omitempty"`    // Per-batch idempotency key generated by the agent and reused across retries
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -82,12 +83,20 @@ func (x *TrafficReport) GetReportId() string {
 	return ""
 }
 
+func (x *TrafficReport) GetBatchId() string {
+	if x != nil {
+		return x.BatchId
+	}
+	return ""
+}
+
 // UserTraffic contains traffic for a single user
 type UserTraffic struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	UploadBytes   int64                  `protobuf:"varint,2,opt,name=upload_bytes,json=uploadBytes,proto3" json:"upload_bytes,omitempty"`
 	DownloadBytes int64                  `protobuf:"varint,3,opt,name=download_bytes,json=downloadBytes,proto3" json:"download_bytes,omitempty"`
+	ServerId      int64                  `protobuf:"varint,4,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"` // Node the bytes were served by; 0 when the collector cannot attribute traffic to a node
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -143,14 +152,24 @@ func (x *UserTraffic) GetDownloadBytes() int64 {
 	return 0
 }
 
+func (x *UserTraffic) GetServerId() int64 {
+	if x != nil {
+		return x.ServerId
+	}
+	return 0
+}
+
 // TrafficResponse is returned after traffic report
 type TrafficResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	AcceptedCount int32                  `protobuf:"varint,2,opt,name=accepted_count,json=acceptedCount,proto3" json:"accepted_count,omitempty"`
-	Message       string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Success          bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	AcceptedCount    int32                  `protobuf:"varint,2,opt,name=accepted_count,json=acceptedCount,proto3" json:"accepted_count,omitempty"`
+	Message          string                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	OverLimitUserIds []int64                `protobuf:"varint,4,rep,packed,name=over_limit_user_ids,json=overLimitUserIds,proto3" json:"over_limit_user_ids,omitempty"` // Users whose online IP count exceeds device_limit
+	ExceededUserIds  []int64                `protobuf:"varint,5,rep,packed,name=exceeded_user_ids,json=exceededUserIds,proto3" json:"exceeded_user_ids,omitempty"`      // Users who crossed the hard traffic quota in this report
+	WarningUserIds   []int64                `protobuf:"varint,6,rep,packed,name=warning_user_ids,json=warningUserIds,proto3" json:"warning_user_ids,omitempty"`         // Users who first crossed the soft-limit percentage this period (informational only)
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
 func (x *TrafficResponse) Reset() {
@@ -204,11 +223,33 @@ func (x *TrafficResponse) GetMessage() string {
 	return ""
 }
 
+func (x *TrafficResponse) GetOverLimitUserIds() []int64 {
+	if x != nil {
+		return x.OverLimitUserIds
+	}
+	return nil
+}
+
+func (x *TrafficResponse) GetExceededUserIds() []int64 {
+	if x != nil {
+		return x.ExceededUserIds
+	}
+	return nil
+}
+
+func (x *TrafficResponse) GetWarningUserIds() []int64 {
+	if x != nil {
+		return x.WarningUserIds
+	}
+	return nil
+}
+
 // AliveReport contains active user IDs
 type AliveReport struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Timestamp     int64                  `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
 	UserIds       []int64                `protobuf:"varint,2,rep,packed,name=user_ids,json=userIds,proto3" json:"user_ids,omitempty"`
+	Devices       []*AliveDevice         `protobuf:"bytes,3,rep,name=devices,proto3" json:"devices,omitempty"` // Online (user_id, ip) tuples
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -257,17 +298,79 @@ func (x *AliveReport) GetUserIds() []int64 {
 	return nil
 }
 
-// AliveResponse is returned after alive report
-type AliveResponse struct {
+func (x *AliveReport) GetDevices() []*AliveDevice {
+	if x != nil {
+		return x.Devices
+	}
+	return nil
+}
+
+// AliveDevice is a single online device observed by the agent
+type AliveDevice struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Ip            string                 `protobuf:"bytes,2,opt,name=ip,proto3" json:"ip,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
+func (x *AliveDevice) Reset() {
+	*x = AliveDevice{}
+	mi := &file_agent_v1_traffic_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AliveDevice) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AliveDevice) ProtoMessage() {}
+
+func (x *AliveDevice) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_v1_traffic_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AliveDevice.ProtoReflect.Descriptor instead.
+func (*AliveDevice) Descriptor() ([]byte, []int) {
+	return file_agent_v1_traffic_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *AliveDevice) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *AliveDevice) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+// AliveResponse is returned after alive report
+type AliveResponse struct {
+	state                     protoimpl.MessageState `protogen:"open.v1"`
+	Success                   bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	OverLimitUserIds          []int64                `protobuf:"varint,2,rep,packed,name=over_limit_user_ids,json=overLimitUserIds,proto3" json:"over_limit_user_ids,omitempty"`                     // Users whose online IP count exceeds device_limit
+	DeviceOnlineWindowSeconds int64                  `protobuf:"varint,3,opt,name=device_online_window_seconds,json=deviceOnlineWindowSeconds,proto3" json:"device_online_window_seconds,omitempty"` // Panel online-device window; agent suspends over-limit users for this long
+	unknownFields             protoimpl.UnknownFields
+	sizeCache                 protoimpl.SizeCache
+}
+
 func (x *AliveResponse) Reset() {
 	*x = AliveResponse{}
-	mi := &file_agent_v1_traffic_proto_msgTypes[4]
+	mi := &file_agent_v1_traffic_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -279,7 +382,7 @@ func (x *AliveResponse) String() string {
 func (*AliveResponse) ProtoMessage() {}
 
 func (x *AliveResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_agent_v1_traffic_proto_msgTypes[4]
+	mi := &file_agent_v1_traffic_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -292,7 +395,7 @@ func (x *AliveResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AliveResponse.ProtoReflect.Descriptor instead.
 func (*AliveResponse) Descriptor() ([]byte, []int) {
-	return file_agent_v1_traffic_proto_rawDescGZIP(), []int{4}
+	return file_agent_v1_traffic_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *AliveResponse) GetSuccess() bool {
@@ -302,28 +405,53 @@ func (x *AliveResponse) GetSuccess() bool {
 	return false
 }
 
+func (x *AliveResponse) GetOverLimitUserIds() []int64 {
+	if x != nil {
+		return x.OverLimitUserIds
+	}
+	return nil
+}
+
+func (x *AliveResponse) GetDeviceOnlineWindowSeconds() int64 {
+	if x != nil {
+		return x.DeviceOnlineWindowSeconds
+	}
+	return 0
+}
+
 var File_agent_v1_traffic_proto protoreflect.FileDescriptor
 
 const file_agent_v1_traffic_proto_rawDesc = "" +
 	"\n" +
-	"\x16agent/v1/traffic.proto\x12\bagent.v1\"\x84\x01\n" +
+	"\x16agent/v1/traffic.proto\x12\bagent.v1\"\x9f\x01\n" +
 	"\rTrafficReport\x12\x1c\n" +
 	"\ttimestamp\x18\x01 \x01(\x03R\ttimestamp\x128\n" +
 	"\fuser_traffic\x18\x02 \x03(\v2\x15.agent.v1.UserTrafficR\vuserTraffic\x12\x1b\n" +
-	"\treport_id\x18\x03 \x01(\tR\breportId\"p\n" +
+	"\treport_id\x18\x03 \x01(\tR\breportId\x12\x19\n" +
+	"\bbatch_id\x18\x04 \x01(\tR\abatchId\"\x8d\x01\n" +
 	"\vUserTraffic\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12!\n" +
 	"\fupload_bytes\x18\x02 \x01(\x03R\vuploadBytes\x12%\n" +
-	"\x0edownload_bytes\x18\x03 \x01(\x03R\rdownloadBytes\"l\n" +
+	"\x0edownload_bytes\x18\x03 \x01(\x03R\rdownloadBytes\x12\x1b\n" +
+	"\tserver_id\x18\x04 \x01(\x03R\bserverId\"\xf1\x01\n" +
 	"\x0fTrafficResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12%\n" +
 	"\x0eaccepted_count\x18\x02 \x01(\x05R\racceptedCount\x12\x18\n" +
-	"\amessage\x18\x03 \x01(\tR\amessage\"F\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12-\n" +
+	"\x13over_limit_user_ids\x18\x04 \x03(\x03R\x10overLimitUserIds\x12*\n" +
+	"\x11exceeded_user_ids\x18\x05 \x03(\x03R\x0fexceededUserIds\x12(\n" +
+	"\x10warning_user_ids\x18\x06 \x03(\x03R\x0ewarningUserIds\"w\n" +
 	"\vAliveReport\x12\x1c\n" +
 	"\ttimestamp\x18\x01 \x01(\x03R\ttimestamp\x12\x19\n" +
-	"\buser_ids\x18\x02 \x03(\x03R\auserIds\")\n" +
+	"\buser_ids\x18\x02 \x03(\x03R\auserIds\x12/\n" +
+	"\adevices\x18\x03 \x03(\v2\x15.agent.v1.AliveDeviceR\adevices\"6\n" +
+	"\vAliveDevice\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x0e\n" +
+	"\x02ip\x18\x02 \x01(\tR\x02ip\"\x99\x01\n" +
 	"\rAliveResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccessB:Z8github.com/creamcroissant/xboard/pkg/pb/agent/v1;agentv1b\x06proto3"
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12-\n" +
+	"\x13over_limit_user_ids\x18\x02 \x03(\x03R\x10overLimitUserIds\x12?\n" +
+	"\x1cdevice_online_window_seconds\x18\x03 \x01(\x03R\x19deviceOnlineWindowSecondsB:Z8github.com/creamcroissant/xboard/pkg/pb/agent/v1;agentv1b\x06proto3"
 
 var (
 	file_agent_v1_traffic_proto_rawDescOnce sync.Once
@@ -337,21 +465,23 @@ func file_agent_v1_traffic_proto_rawDescGZIP() []byte {
 	return file_agent_v1_traffic_proto_rawDescData
 }
 
-var file_agent_v1_traffic_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_agent_v1_traffic_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_agent_v1_traffic_proto_goTypes = []any{
 	(*TrafficReport)(nil),   // 0: agent.v1.TrafficReport
 	(*UserTraffic)(nil),     // 1: agent.v1.UserTraffic
 	(*TrafficResponse)(nil), // 2: agent.v1.TrafficResponse
 	(*AliveReport)(nil),     // 3: agent.v1.AliveReport
-	(*AliveResponse)(nil),   // 4: agent.v1.AliveResponse
+	(*AliveDevice)(nil),     // 4: agent.v1.AliveDevice
+	(*AliveResponse)(nil),   // 5: agent.v1.AliveResponse
 }
 var file_agent_v1_traffic_proto_depIdxs = []int32{
 	1, // 0: agent.v1.TrafficReport.user_traffic:type_name -> agent.v1.UserTraffic
-	1, // [1:1] is the sub-list for method output_type
-	1, // [1:1] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	4, // 1: agent.v1.AliveReport.devices:type_name -> agent.v1.AliveDevice
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
 }
 
 func init() { file_agent_v1_traffic_proto_init() }
@@ -365,7 +495,7 @@ func file_agent_v1_traffic_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_agent_v1_traffic_proto_rawDesc), len(file_agent_v1_traffic_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   5,
+			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   0,
 		},